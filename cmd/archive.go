@@ -0,0 +1,222 @@
+// Package cmd 命令行入口模块
+// archive 命令：按修改时间归档旧文件，可按分类打包为带日期的压缩包
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/llm"
+	"filo/internal/organizer"
+	"filo/internal/scanner"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// archiveCmd 归档命令定义
+var archiveCmd = &cobra.Command{
+	Use:   "archive <目录>",
+	Short: "归档长时间未修改的文件",
+	Long: `把修改时间早于 --older-than 的文件按分类移入归档目录，分类方式与整理相同
+（学习记忆、规则和 AI 分类）。
+
+归档目录默认为 <目录>/已归档，可用 --to 或配置 archive_dir 指定，
+可以包含占位符 {home} {source_dir} {source_dir_name} {year} {month} {day} {date} {env:NAME}。
+使用 --compress 时每个分类文件夹打包为 分类_日期.tar.gz。
+归档是一个普通批次，可以用 filo undo 撤销（压缩包中的文件会被解出移回原处）。
+
+时长单位: d（天）、w（周）、m（月，按 30 天）、y（年，按 365 天）
+
+示例:
+  filo archive ~/Documents --older-than 1y -n       # 预览一年未修改的文件如何归档
+  filo archive ~/Documents --older-than 6m -r       # 递归归档半年未修改的文件
+  filo archive ~/Downloads --to /Volumes/备份/归档 --compress  # 归档到外部磁盘并打包`,
+	Args: cobra.ExactArgs(1),
+	Run:  runArchive,
+}
+
+// archive 命令行参数
+var (
+	archiveAge      string // 归档的文件年龄下限
+	archiveTo       string // 归档根目录
+	archiveCompress bool   // 按分类打包为压缩包
+)
+
+func init() {
+	archiveCmd.Flags().StringVar(&archiveAge, "older-than", "1y", "只归档早于该时长未修改的文件（如 90d、6m、1y）")
+	archiveCmd.Flags().StringVar(&archiveTo, "to", "", "归档根目录（默认 <目录>/已归档）")
+	archiveCmd.Flags().BoolVar(&archiveCompress, "compress", false, "每个分类文件夹打包为带日期的 tar.gz")
+	archiveCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "预览模式")
+	archiveCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "递归扫描子目录")
+	addScanFlags(archiveCmd)
+	archiveCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "详细输出")
+	archiveCmd.Flags().StringVarP(&model, "model", "m", "", "使用的模型")
+	archiveCmd.Flags().BoolVar(&offline, "offline", false, "离线模式，只用学习记忆和扩展名分类")
+	archiveCmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "允许使用配置的远程 LLM 提供方")
+	archiveCmd.Flags().BoolVar(&force, "force", false, "允许归档受保护的目录（系统目录、主目录等）")
+
+	archiveCmd.RegisterFlagCompletionFunc("model", completeModels)
+
+	// 注册 archive 子命令
+	rootCmd.AddCommand(archiveCmd)
+}
+
+// runArchive 执行归档
+func runArchive(cmd *cobra.Command, args []string) {
+	sourceDir := args[0]
+	age, err := parseAge(archiveAge)
+	if err != nil {
+		ui.Error("%v", err)
+		return
+	}
+	cutoff := time.Now().Add(-age)
+
+	ui.Banner()
+
+	if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
+		ui.Error("目录不存在: %s", sourceDir)
+		return
+	}
+
+	cfg := config.Get()
+	root := archiveTo
+	if root == "" {
+		root = cfg.ArchiveDir
+	}
+	if root == "" {
+		root = filepath.Join(sourceDir, "已归档")
+	}
+	root, err = organizer.ExpandTarget(root, sourceDir, time.Now())
+	if err != nil {
+		ui.Error("%v", err)
+		return
+	}
+
+	if !checkPathsSafe(sourceDir, root) {
+		return
+	}
+
+	l, err := acquireLock()
+	if err != nil {
+		return
+	}
+	defer l.Release()
+
+	applyModel(cfg, sourceDir)
+	if offline {
+		cfg.Offline = true
+		ui.Warning("离线模式: 只使用学习记忆和扩展名分类，置信度较低")
+	} else if !checkLLMReady(llm.NewClient()) {
+		return
+	}
+
+	// ========== 步骤1: 扫描并筛选旧文件 ==========
+	if !applyScanFlags(cmd) {
+		return
+	}
+	ui.Title("📂", fmt.Sprintf("%s: %s", scanScope(), sourceDir))
+	files, err := scanner.ScanDirectory(sourceDir, recursive)
+	if err != nil {
+		ui.Error("扫描失败: %v", err)
+		return
+	}
+	var old []scanner.FileInfo
+	for _, f := range files {
+		if !f.IsDir && f.ModifiedTime.Before(cutoff) {
+			old = append(old, f)
+		}
+	}
+	ui.Success("找到 %d 个 %s 前修改的文件", len(old), cutoff.Format("2006-01-02"))
+	if len(old) == 0 {
+		ui.Warning("没有文件需要归档")
+		return
+	}
+
+	// ========== 步骤2: 分类并生成归档计划 ==========
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		ui.Error("初始化分类器失败: %v", err)
+		return
+	}
+	defer clf.Close()
+	defer watchInterrupt(clf)()
+
+	results, err := clf.Classify(old, verbose)
+	if err != nil {
+		ui.Error("分类失败: %v", err)
+		return
+	}
+	if clf.Interrupted() {
+		printClassifyInterrupted(sourceDir, len(results), len(old))
+		return
+	}
+	plan := organizer.GeneratePlan(results, root)
+	plan.SourceDir, _ = filepath.Abs(sourceDir)
+	organizer.ParkForReview(plan, cfg.LowConfidenceAction, cfg.ConfidenceThresholdFor)
+	organizer.PrintPlan(plan)
+	ui.Info("归档到: %s", ui.Bold(root))
+
+	if dryRun {
+		ui.Warning("预览模式 - 未执行实际操作")
+		ui.Dim("去掉 -n 参数执行实际归档")
+		return
+	}
+	if !organizer.Confirm("\n确认执行归档?") {
+		ui.Warning("已取消")
+		return
+	}
+
+	// ========== 步骤3: 执行归档 ==========
+	result := organizer.Execute(plan, clf, verbose)
+	if result.Interrupted > 0 {
+		printExecuteInterrupted(sourceDir, result)
+		return // 中断后不打包
+	}
+	if !archiveCompress || result.Success == 0 {
+		return
+	}
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	ui.Title("📦", "打包归档")
+	packed, err := organizer.Compress(db, result.BatchID)
+	for _, tarball := range packed.Tarballs {
+		ui.Success("%s", tarball)
+	}
+	if err != nil {
+		ui.Error("%v", err)
+	}
+	if packed.Files > 0 {
+		ui.Info("已打包 %d 个文件，撤销时自动解出: filo undo %s", packed.Files, result.BatchID)
+	}
+}
+
+// parseAge 解析 90d、6w、6m、1y 形式的时长
+func parseAge(s string) (time.Duration, error) {
+	units := map[byte]int{'d': 1, 'w': 7, 'm': 30, 'y': 365}
+	if len(s) < 2 {
+		return 0, fmt.Errorf("无效的时长: %q（示例: 90d、6m、1y）", s)
+	}
+	days, ok := units[s[len(s)-1]]
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if !ok || err != nil || n <= 0 {
+		return 0, fmt.Errorf("无效的时长: %q（示例: 90d、6m、1y）", s)
+	}
+	return time.Duration(n*days) * 24 * time.Hour, nil
+}
@@ -0,0 +1,259 @@
+// Package cmd 命令行入口模块
+// bench 命令：在同一批样本文件上对比多个模型的分类效果
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/llm"
+	"filo/internal/scanner"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// benchCmd 模型对比评测命令定义
+var benchCmd = &cobra.Command{
+	Use:   "bench <目录>",
+	Short: "对比多个模型的分类效果",
+	Long: `从目录中抽样文件，分别用多个模型分类（不移动文件、不学习），
+对比一致率、速度和置信度，结果计入模型性能统计。
+
+第一个模型作为基准，其余模型与之比较一致率。
+
+示例:
+  filo bench ~/Downloads --models qwen3:8b,llama3.2:3b
+  filo bench ~/Downloads --models qwen3:8b,qwen2.5:7b --sample 50`,
+	Args: cobra.ExactArgs(1),
+	Run:  runBench,
+}
+
+// bench 命令行参数
+var (
+	benchModels []string // 参与对比的模型
+	benchSample int      // 抽样文件数
+)
+
+// init 注册 bench 子命令
+func init() {
+	benchCmd.Flags().StringSliceVar(&benchModels, "models", nil, "参与对比的模型（逗号分隔，至少两个）")
+	benchCmd.Flags().IntVar(&benchSample, "sample", 30, "抽样文件数")
+	benchCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "递归扫描子目录")
+	addScanFlags(benchCmd)
+	benchCmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "允许使用配置的远程 LLM 提供方")
+	benchCmd.MarkFlagRequired("models")
+	benchCmd.RegisterFlagCompletionFunc("models", completeModels)
+	rootCmd.AddCommand(benchCmd)
+}
+
+// benchRun 单个模型的评测结果
+type benchRun struct {
+	model   string
+	results map[string]classifier.Result // 文件路径 -> 分类结果
+	elapsed time.Duration
+	avgConf float64
+	failed  int
+}
+
+// runBench 执行模型对比评测
+func runBench(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	if len(benchModels) < 2 {
+		ui.Error("至少需要两个模型进行对比")
+		return
+	}
+
+	// 检查模型是否可用
+	cfg := config.Get()
+	cfg.SetModel(benchModels[0])
+	client := llm.NewClient()
+	if !checkLLMReady(client) {
+		return
+	}
+	for _, m := range benchModels {
+		if !client.HasModel(m) {
+			ui.Error("模型 %s 未安装", m)
+			ui.Info("运行: ollama pull %s", m)
+			return
+		}
+	}
+
+	// 扫描并抽样
+	if !applyScanFlags(cmd) {
+		return
+	}
+	ui.Title("📂", fmt.Sprintf("%s: %s", scanScope(), args[0]))
+	files, err := scanner.ScanDirectory(args[0], recursive)
+	if err != nil {
+		ui.Error("扫描失败: %v", err)
+		return
+	}
+	sample := sampleFiles(files, benchSample)
+	if len(sample) == 0 {
+		ui.Warning("没有可用于评测的文件")
+		return
+	}
+	ui.Success("抽样 %d 个文件", len(sample))
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	// 依次用每个模型分类
+	batchID := "bench_" + time.Now().Format("20060102_150405")
+	var runs []benchRun
+	for _, m := range benchModels {
+		ui.Title("🤖", fmt.Sprintf("模型: %s", m))
+		cfg.SetModel(m)
+		clf, err := classifier.NewClassifier()
+		if err != nil {
+			ui.Error("初始化分类器失败: %v", err)
+			return
+		}
+		results, elapsed := clf.ClassifyWithLLMOnly(sample)
+		plan := clf.GetBatchPlan()
+		clf.Close()
+
+		run := benchRun{model: m, results: make(map[string]classifier.Result), elapsed: elapsed}
+		var totalConf float64
+		for _, r := range results {
+			run.results[r.FileInfo.Path] = r
+			if r.Source == "error" {
+				run.failed++
+			}
+			totalConf += r.Confidence
+		}
+		if len(results) > 0 {
+			run.avgConf = totalConf / float64(len(results))
+		}
+		runs = append(runs, run)
+
+		// 计入模型性能统计，供推荐使用
+		db.AddModelStats(m, batchID, len(results), elapsed.Milliseconds(), run.avgConf, plan.Size, plan.Parallel)
+	}
+
+	printBenchTable(runs, sample)
+	printBenchDiffs(runs, sample)
+}
+
+// sampleFiles 随机抽取最多 n 个正常文件
+func sampleFiles(files []scanner.FileInfo, n int) []scanner.FileInfo {
+	var candidates []scanner.FileInfo
+	for _, f := range files {
+		if !f.IsDir && f.Suspicious == "" {
+			candidates = append(candidates, f)
+		}
+	}
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+// printBenchTable 输出模型对比表
+func printBenchTable(runs []benchRun, sample []scanner.FileInfo) {
+	ui.Title("📊", "对比结果")
+	ui.Divider()
+	fmt.Printf("  %-20s %8s %10s %10s %6s %10s %10s\n",
+		"模型", "耗时", "速度", "置信度", "失败", "主分类一致", "完全一致")
+	ui.Divider()
+
+	base := runs[0]
+	for i, run := range runs {
+		perFile := float64(run.elapsed.Milliseconds()) / float64(len(sample))
+		catAgree, fullAgree := "基准", "基准"
+		if i > 0 {
+			cat, full := agreement(base, run, sample)
+			catAgree = fmt.Sprintf("%.0f%%", cat*100)
+			fullAgree = fmt.Sprintf("%.0f%%", full*100)
+		}
+		fmt.Printf("  %-20s %7.1fs %8.0fms %9.0f%% %6d %10s %10s\n",
+			truncateModelName(run.model, 20),
+			run.elapsed.Seconds(),
+			perFile,
+			run.avgConf*100,
+			run.failed,
+			catAgree,
+			fullAgree,
+		)
+	}
+	fmt.Println()
+	ui.Dim("结果已计入模型性能统计，运行 'filo models --stats' 查看")
+}
+
+// agreement 计算两个模型结果的一致率（主分类一致率、主分类和子分类都一致的比例）
+func agreement(a, b benchRun, sample []scanner.FileInfo) (float64, float64) {
+	cat, full := 0, 0
+	for _, f := range sample {
+		ra, okA := a.results[f.Path]
+		rb, okB := b.results[f.Path]
+		if !okA || !okB {
+			continue
+		}
+		if ra.Category == rb.Category {
+			cat++
+			if ra.Subcategory == rb.Subcategory {
+				full++
+			}
+		}
+	}
+	n := float64(len(sample))
+	return float64(cat) / n, float64(full) / n
+}
+
+// printBenchDiffs 并排显示主分类不一致的文件
+func printBenchDiffs(runs []benchRun, sample []scanner.FileInfo) {
+	const maxDiffs = 10
+	shown := 0
+	for _, f := range sample {
+		base, ok := runs[0].results[f.Path]
+		if !ok {
+			continue
+		}
+		differs := false
+		for _, run := range runs[1:] {
+			if r, ok := run.results[f.Path]; !ok || r.Category != base.Category {
+				differs = true
+				break
+			}
+		}
+		if !differs {
+			continue
+		}
+
+		if shown == 0 {
+			fmt.Println()
+			ui.Info("分类不一致的文件:")
+		}
+		if shown >= maxDiffs {
+			ui.Dim("  ... 更多不一致的文件未显示")
+			break
+		}
+		fmt.Printf("\n  %s\n", ui.Bold(f.Name))
+		for _, run := range runs {
+			if r, ok := run.results[f.Path]; ok {
+				fmt.Printf("    %-20s %s/%s %s\n", truncateModelName(run.model, 20), r.Category, r.Subcategory,
+					ui.Gray(fmt.Sprintf("(%.0f%%)", r.Confidence*100)))
+			} else {
+				fmt.Printf("    %-20s %s\n", truncateModelName(run.model, 20), ui.Gray("(无结果)"))
+			}
+		}
+		shown++
+	}
+}
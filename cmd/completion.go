@@ -0,0 +1,144 @@
+// Package cmd 命令行入口模块
+// completion 命令：生成 Shell 自动补全脚本，并提供参数的动态补全
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/config"
+	"filo/internal/llm"
+	"filo/internal/storage"
+)
+
+// completionCmd 自动补全命令定义
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "生成 Shell 自动补全脚本",
+	Long: `生成 Shell 自动补全脚本，支持子命令、参数，以及模型名、批次ID、分类名的动态补全。
+
+加载方式:
+
+  Bash:
+    source <(filo completion bash)
+    # 永久生效（Linux）
+    filo completion bash > /etc/bash_completion.d/filo
+    # 永久生效（macOS）
+    filo completion bash > $(brew --prefix)/etc/bash_completion.d/filo
+
+  Zsh:
+    # 需先启用补全: echo "autoload -U compinit; compinit" >> ~/.zshrc
+    filo completion zsh > "${fpath[1]}/_filo"
+
+  Fish:
+    filo completion fish > ~/.config/fish/completions/filo.fish
+
+  PowerShell:
+    filo completion powershell | Out-String | Invoke-Expression`,
+	DisableFlagsInUseLine: true,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	Run:                   runCompletion,
+}
+
+// init 注册 completion 子命令
+// 使用自定义命令替代 cobra 默认生成的 completion 命令
+func init() {
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.AddCommand(completionCmd)
+}
+
+// runCompletion 输出指定 Shell 的补全脚本
+func runCompletion(cmd *cobra.Command, args []string) {
+	var err error
+	switch args[0] {
+	case "bash":
+		err = rootCmd.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		err = rootCmd.GenZshCompletion(os.Stdout)
+	case "fish":
+		err = rootCmd.GenFishCompletion(os.Stdout, true)
+	case "powershell":
+		err = rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// ==================== 动态补全函数 ====================
+// 补全在用户按下 Tab 时执行，出错时静默返回空结果，不输出任何提示
+
+// completeModels 补全已安装的 Ollama 模型名
+func completeModels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	models, err := llm.NewClient().ListModels()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return models, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBatchIDs 补全可撤销的批次 ID
+// 附带时间和文件数作为说明
+func completeBatchIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer db.Close()
+
+	batches, err := db.GetRecentBatches(20)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ids := make([]string, 0, len(batches))
+	for _, b := range batches {
+		ids = append(ids, fmt.Sprintf("%s\t%s · %d 个文件", b["batch_id"], b["created_at"], b["file_count"]))
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCategories 补全历史记录中出现过的分类名
+func completeCategories(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	db, err := storage.NewDatabase()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer db.Close()
+
+	return db.GetCategoryNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigKeys 补全配置键名（第一个参数）
+// 结构体和按分类名索引的配置项展开到下级
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	keys, values := config.Get().Flatten()
+	for _, key := range config.Keys() {
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
+
+// fixedCompletion 返回固定取值的补全函数
+func fixedCompletion(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
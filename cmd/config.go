@@ -8,10 +8,15 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"filo/internal/config"
+	"filo/internal/embedding"
+	"filo/internal/memory"
+	"filo/internal/scanner"
 	"filo/internal/ui"
 )
 
@@ -19,7 +24,8 @@ import (
 var (
 	setModel       string  // 设置默认模型
 	setThreshold   float64 // 设置置信度阈值
-	setBatchSize   int     // 设置批处理大小
+	setBatchSize   int     // 设置批处理大小（固定后关闭自动调整）
+	autoBatch      bool    // 恢复按模型耗时自动调整批大小
 	toggleLearning bool    // 切换学习功能开关
 )
 
@@ -27,24 +33,40 @@ var (
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "配置管理",
-	Long:  "查看或修改 Filo 配置",
-	Run:   runConfig,
+	Long: `查看或修改 Filo 配置。
+
+不带参数时按类别显示主要配置；用子命令读写任意配置项:
+  filo config list                       # 逐项列出全部配置，修改过的项以 * 标出
+  filo config get ollama_url             # 读取配置项
+  filo config set temperature 0.2        # 修改配置项（检查类型和取值范围）
+  filo config unset temperature          # 恢复默认值
+  filo config --json                     # 以 JSON 输出全部配置
+
+常用配置项也可以用标志修改，如 --model、--threshold、--batch。`,
+	Args: cobra.NoArgs,
+	Run:  runConfig,
 }
 
 // init 注册 config 子命令及其标志
 func init() {
 	configCmd.Flags().StringVar(&setModel, "model", "", "设置默认模型")
 	configCmd.Flags().Float64Var(&setThreshold, "threshold", 0, "设置置信度阈值 (0.5-1.0)")
-	configCmd.Flags().IntVar(&setBatchSize, "batch", 0, "设置批处理大小 (5-50)")
+	configCmd.Flags().IntVar(&setBatchSize, "batch", 0, "固定批处理大小 (5-50)，不再自动调整")
+	configCmd.Flags().BoolVar(&autoBatch, "auto-batch", false, "按模型的历史耗时自动调整批处理大小")
 	configCmd.Flags().BoolVar(&toggleLearning, "toggle-learning", false, "切换学习功能开关")
+	configCmd.RegisterFlagCompletionFunc("model", completeModels)
 	rootCmd.AddCommand(configCmd)
 }
 
 // runConfig 执行配置命令
 // 如果没有设置选项，显示当前配置；否则修改配置
 func runConfig(cmd *cobra.Command, args []string) {
-	ui.Banner()
 	cfg := config.Get()
+	if configJSON {
+		dumpConfig(cfg)
+		return
+	}
+	ui.Banner()
 
 	// 检查是否有设置选项
 	hasChanges := false
@@ -74,7 +96,15 @@ func runConfig(cmd *cobra.Command, args []string) {
 			return
 		}
 		cfg.BatchSize = setBatchSize
-		ui.Success("批处理大小已设置为: %d", setBatchSize)
+		cfg.AutoBatch = false
+		ui.Success("批处理大小已固定为: %d", setBatchSize)
+		hasChanges = true
+	}
+
+	// 恢复自动调整批大小
+	if autoBatch {
+		cfg.AutoBatch = true
+		ui.Success("批处理大小将按模型的历史耗时自动调整（无历史数据时为 %d）", cfg.BatchSize)
 		hasChanges = true
 	}
 
@@ -110,8 +140,16 @@ func showConfig(cfg *config.Config) {
 
 	fmt.Println()
 	ui.Info("模型配置:")
-	ui.Info("  LLM 模型:      %s", cfg.LLMModel)
-	ui.Info("  嵌入模型:      %s", cfg.EmbeddingModel)
+	ui.Info("  提供方:        %s", cfg.LLMProvider)
+	ui.Info("  LLM 模型:      %s", cfg.ActiveModel())
+	switch cfg.Embedder {
+	case embedding.EmbedderOllama, embedding.EmbedderLocal:
+		ui.Info("  嵌入模型:      %s (%s)", cfg.EmbeddingModel, cfg.Embedder)
+	case embedding.EmbedderExec:
+		ui.Info("  嵌入程序:      %s", strings.Join(cfg.EmbedderCommand, " "))
+	default:
+		ui.Info("  嵌入器:        %s", cfg.Embedder)
+	}
 	ui.Info("  Ollama 地址:   %s", cfg.OllamaURL)
 	ui.Info("  温度参数:      %.2f", cfg.Temperature)
 
@@ -125,20 +163,96 @@ func showConfig(cfg *config.Config) {
 	ui.Info("  相似度阈值:    %.2f", cfg.SimilarityThreshold)
 	ui.Info("  置信度阈值:    %.2f", cfg.ConfidenceThreshold)
 	ui.Info("  最小样本数:    %d", cfg.MinSamplesForRule)
+	if len(cfg.StopWords) > 0 {
+		ui.Info("  追加停用词:    %s", strings.Join(cfg.StopWords, ", "))
+	}
+	if cfg.MemoryScoring == memory.ScoringEnsemble {
+		w := cfg.MemoryWeights
+		ui.Info("  记忆打分:      综合（规则 %.2f  向量 %.2f  历史 %.2f  一致加分 %.2f）", w.Rule, w.Vector, w.History, w.Agreement)
+	} else {
+		ui.Info("  记忆打分:      按优先级")
+	}
+	if len(cfg.CategoryThresholds) > 0 {
+		ui.Info("  分类阈值:")
+		names := make([]string, 0, len(cfg.CategoryThresholds))
+		for name := range cfg.CategoryThresholds {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ui.Info("    %s: 相似度 %.2f  置信度 %.2f", name,
+				cfg.SimilarityThresholdFor(name), cfg.ConfidenceThresholdFor(name))
+		}
+	}
 
 	fmt.Println()
 	ui.Info("处理配置:")
-	ui.Info("  批处理大小:    %d", cfg.BatchSize)
+	if cfg.AutoBatch {
+		ui.Info("  批处理大小:    自动（无历史数据时 %d，慢模型最多 %d 路并行）", cfg.BatchSize, cfg.LLMParallel)
+	} else {
+		ui.Info("  批处理大小:    %d（固定）", cfg.BatchSize)
+	}
+	switch {
+	case cfg.Fast:
+		ui.Info("  快速模式:      开启（记忆只做内容和规则匹配）")
+	case cfg.FastModeFiles > 0:
+		ui.Info("  快速模式:      超过 %d 个文件时自动开启", cfg.FastModeFiles)
+	}
+	if cfg.ETAWarnMinutes > 0 {
+		ui.Info("  耗时提醒:      预计超过 %d 分钟时询问", cfg.ETAWarnMinutes)
+	} else {
+		ui.Info("  耗时提醒:      关闭")
+	}
+	readContent := "关闭"
+	if cfg.ReadContent {
+		readContent = "开启"
+	}
+	ui.Info("  读取内容:      %s", readContent)
+	ui.Info("  重名处理:      %s", cfg.ConflictStrategy)
+	if cfg.SoftDeleteDays > 0 {
+		ui.Info("  软删除:        重复文件移入回收站，保留 %d 天", cfg.SoftDeleteDays)
+	}
+	if cfg.Atomic {
+		ui.Info("  原子执行:      开启（移动失败时全部移回原处）")
+	}
+	if !cfg.CrossDeviceCopy {
+		ui.Info("  跨磁盘移动:    关闭（目标在另一个磁盘上的文件移动失败）")
+	}
+	if cfg.ExistingFolders {
+		ui.Info("  已有文件夹:    只归入目标目录中已有的文件夹")
+	}
+	cloud := "只按文件名分类"
+	if cfg.CloudFiles == scanner.CloudFilesSkip {
+		cloud = "保持原位"
+	}
+	if cfg.SkipUnsynced {
+		cloud += "，不移出同步目录"
+	}
+	ui.Info("  云端文件:      %s", cloud)
+	ui.Info("  符号链接:      %s", map[string]string{
+		scanner.SymlinksSkip:   "不整理",
+		scanner.SymlinksFollow: "按指向的文件分类",
+		scanner.SymlinksLink:   "整理链接本身",
+	}[cfg.Symlinks])
+	if cfg.FolderInfo != "" {
+		ui.Info("  文件夹说明:    %s", cfg.FolderInfo)
+	}
+	if cfg.Sidecar != "" {
+		ui.Info("  整理信息:      %s（随文件保存）", cfg.Sidecar)
+	}
 
 	fmt.Println()
 	ui.Info("数据路径:")
 	ui.Info("  数据目录:      %s", cfg.DataDir)
 	ui.Info("  数据库文件:    %s", cfg.DBPath)
+	if cfg.SharedDB != "" {
+		ui.Info("  共享学习记录:  %s（用户 %s）", cfg.SharedDB, cfg.User())
+	}
 
 	fmt.Println()
 	ui.Dim("修改配置示例:")
 	ui.Dim("  filo config --model qwen3:8b")
 	ui.Dim("  filo config --threshold 0.8")
-	ui.Dim("  filo config --batch 20")
-	ui.Dim("  filo config --toggle-learning")
+	ui.Dim("  filo config set ollama_url http://192.168.1.10:11434")
+	ui.Dim("  filo config list                  # 全部配置项")
 }
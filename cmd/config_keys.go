@@ -0,0 +1,203 @@
+// Package cmd 命令行入口模块
+// config get/set/unset/list 命令：按键名读写任意配置项，写入前检查类型和取值范围
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/config"
+	"filo/internal/doctor"
+	"filo/internal/ui"
+)
+
+// configJSON 以 JSON 输出全部配置
+var configJSON bool
+
+// configGetCmd 读取配置项子命令
+var configGetCmd = &cobra.Command{
+	Use:   "get <键名>",
+	Short: "读取配置项",
+	Long: `输出配置项的值，便于在脚本中使用。
+
+键名与 config.json 中的名称相同，下级用 . 连接。
+
+示例:
+  filo config get ollama_url
+  filo config get memory_weights.rule
+  filo config get category_thresholds          # 整个配置项以 JSON 输出`,
+	Args:              cobra.ExactArgs(1),
+	Run:               runConfigGet,
+	ValidArgsFunction: completeConfigKeys,
+}
+
+// configSetCmd 修改配置项子命令
+var configSetCmd = &cobra.Command{
+	Use:   "set <键名> <值>",
+	Short: "修改配置项",
+	Long: `修改配置项并保存，值的类型或取值范围不对时不保存。
+
+数字和 true/false 按配置项的类型解析；字符串列表用逗号分隔；
+分类阈值、插件列表等结构用 JSON。按分类名索引的配置项可以直接写新的分类名。
+
+示例:
+  filo config set ollama_url http://192.168.1.10:11434
+  filo config set temperature 0.2
+  filo config set similarity_threshold 0.9
+  filo config set embedding_model bge-m3
+  filo config set stop_words 扫描件,scan
+  filo config set category_thresholds.图片.similarity 0.75
+  filo config set memory_weights '{"rule":0.6,"vector":0.3,"history":0.1,"agreement":0.05}'`,
+	Args:              cobra.ExactArgs(2),
+	Run:               runConfigSet,
+	ValidArgsFunction: completeConfigKeys,
+}
+
+// configUnsetCmd 恢复配置项子命令
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <键名>",
+	Short: "恢复配置项的默认值",
+	Long: `把配置项恢复为默认值；默认配置中没有的项（如某个分类的阈值）直接删除。
+
+示例:
+  filo config unset temperature
+  filo config unset category_thresholds.图片`,
+	Args:              cobra.ExactArgs(1),
+	Run:               runConfigUnset,
+	ValidArgsFunction: completeConfigKeys,
+}
+
+// configListCmd 列出配置项子命令
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "逐项列出全部配置",
+	Long: `逐项列出全部配置的键名和值，修改过（与默认值不同）的项以 * 标出。
+API 密钥只显示是否已设置。`,
+	Args: cobra.NoArgs,
+	Run:  runConfigList,
+}
+
+// init 注册 config 的子命令
+func init() {
+	configCmd.Flags().BoolVar(&configJSON, "json", false, "以 JSON 输出全部配置（API 密钥已隐去）")
+	configListCmd.Flags().BoolVar(&configJSON, "json", false, "以 JSON 输出全部配置（API 密钥已隐去）")
+	configCmd.AddCommand(configGetCmd, configSetCmd, configUnsetCmd, configListCmd)
+}
+
+// runConfigGet 执行 config get 命令
+func runConfigGet(cmd *cobra.Command, args []string) {
+	v, err := config.Get().Value(args[0])
+	if err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	if s, ok := v.(string); ok {
+		fmt.Println(s)
+		return
+	}
+	data, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(data))
+}
+
+// runConfigSet 执行 config set 命令
+func runConfigSet(cmd *cobra.Command, args []string) {
+	cfg := config.Get()
+	next, err := cfg.With(args[0], args[1])
+	if err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	saveConfig(cfg, next, args[0])
+}
+
+// runConfigUnset 执行 config unset 命令
+func runConfigUnset(cmd *cobra.Command, args []string) {
+	cfg := config.Get()
+	next, err := cfg.Without(args[0])
+	if err != nil {
+		ui.Error("%v", err)
+		os.Exit(1)
+	}
+	saveConfig(cfg, next, args[0])
+}
+
+// saveConfig 检查修改后的配置并保存
+// 修改引入了新的取值问题时不保存；修改前已存在的问题（其他配置项）不影响保存
+func saveConfig(cfg, next *config.Config, key string) {
+	existing := make(map[string]bool)
+	for _, p := range doctor.ConfigProblems(cfg) {
+		existing[p.Message] = true
+	}
+	invalid := false
+	for _, p := range doctor.ConfigProblems(next) {
+		if !existing[p.Message] {
+			ui.Error("%s", p.Message)
+			invalid = true
+		}
+	}
+	if invalid {
+		ui.Dim("配置未修改")
+		os.Exit(1)
+	}
+
+	*cfg = *next
+	if err := cfg.Save(); err != nil {
+		ui.Error("保存配置失败: %v", err)
+		os.Exit(1)
+	}
+	v, err := cfg.Value(key)
+	if err != nil {
+		ui.Success("%s 已删除", key)
+		return
+	}
+	ui.Success("%s = %s", key, formatConfigValue(key, v))
+}
+
+// runConfigList 执行 config list 命令
+func runConfigList(cmd *cobra.Command, args []string) {
+	cfg := config.Get()
+	if configJSON {
+		dumpConfig(cfg)
+		return
+	}
+	keys, values := cfg.Flatten()
+	for _, key := range keys {
+		mark := " "
+		if !cfg.IsDefault(key) {
+			mark = "*"
+		}
+		fmt.Printf("%s %s = %s\n", mark, key, formatConfigValue(key, values[key]))
+	}
+}
+
+// dumpConfig 以 JSON 输出全部配置，API 密钥只保留是否已设置
+func dumpConfig(cfg *config.Config) {
+	masked := *cfg
+	for _, key := range []*string{&masked.AnthropicAPIKey, &masked.GeminiAPIKey} {
+		if *key != "" {
+			*key = "******"
+		}
+	}
+	data, _ := json.MarshalIndent(&masked, "", "  ")
+	fmt.Println(string(data))
+}
+
+// formatConfigValue 格式化配置项的值，字符串加引号以区分空字符串，API 密钥只显示是否已设置
+func formatConfigValue(key string, v interface{}) string {
+	if s, ok := v.(string); ok && strings.HasSuffix(key, "_api_key") {
+		if s == "" {
+			return "（未设置）"
+		}
+		return "（已设置）"
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
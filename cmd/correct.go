@@ -0,0 +1,144 @@
+// Package cmd 命令行入口模块
+// correct 命令：事后纠正已执行批次中的分类
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/organizer"
+	"filo/internal/scanner"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// correctCmd 事后纠正命令定义
+var correctCmd = &cobra.Command{
+	Use:   "correct <批次ID>",
+	Short: "纠正已整理文件的分类",
+	Long: `列出已执行批次中的文件，逐个选择分错的文件并指定新分类。
+
+文件会被移到新分类的文件夹（撤销该批次时从新位置移回），
+纠正结果记录为用户反馈，以纠正优先级更新规则，并计入该批次的模型准确度。
+
+示例:
+  filo undo --list                # 查看最近的批次 ID
+  filo correct 20240115_143022    # 纠正指定批次中的文件`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeBatchIDs,
+	Run:               runCorrect,
+}
+
+func init() {
+	// 注册 correct 子命令
+	rootCmd.AddCommand(correctCmd)
+}
+
+// runCorrect 执行事后纠正
+func runCorrect(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	l, err := acquireLock()
+	if err != nil {
+		return
+	}
+	defer l.Release()
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	batchID := args[0]
+	logs, err := db.GetBatchLogs(batchID)
+	if err != nil {
+		ui.Error("读取批次失败: %v", err)
+		return
+	}
+	// 移入待确认文件夹的文件由 filo review 处理
+	filtered := logs[:0]
+	for _, log := range logs {
+		if log.Category != organizer.ReviewFolder {
+			filtered = append(filtered, log)
+		}
+	}
+	logs = filtered
+	if len(logs) == 0 {
+		ui.Error("找不到批次 %s 中已整理的文件", batchID)
+		return
+	}
+
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		ui.Error("初始化分类器失败: %v", err)
+		return
+	}
+	defer clf.Close()
+
+	ui.Title("✏️", fmt.Sprintf("纠正批次: %s", batchID))
+	listBatchFiles(logs)
+
+	corrected := 0
+	for {
+		fmt.Println()
+		input := ui.Input("  要纠正的文件编号（回车结束）", "")
+		if input == "" || input == "q" {
+			break
+		}
+		n, err := strconv.Atoi(input)
+		if err != nil || n < 1 || n > len(logs) {
+			ui.Warning("无效的编号: %s", input)
+			continue
+		}
+		log := &logs[n-1]
+
+		ui.Info("%s %s", ui.Bold(log.Filename), ui.Gray(fmt.Sprintf("(%s/%s)", log.Category, log.Subcategory)))
+		newCat := ui.Input("  新主分类", log.Category)
+		newSub := ui.Input("  新子分类（多级用 / 分隔）", log.Subcategory)
+		newCat, newSub = classifier.Normalize(newCat, newSub)
+		if newCat == log.Category && newSub == log.Subcategory {
+			ui.Dim("分类未改变")
+			continue
+		}
+
+		dst, err := organizer.Recategorize(db, *log, newCat, newSub)
+		if err != nil {
+			ui.Error("移动失败: %v", err)
+			continue
+		}
+
+		// 按文件的原始位置学习（来源目录规则）
+		r := classifier.Result{
+			FileInfo:    scanner.FileInfo{Path: log.SourcePath, Name: log.Filename, ContentHash: scanner.HashFile(dst)},
+			Category:    log.Category,
+			Subcategory: log.Subcategory,
+			Source:      log.Source,
+		}
+		clf.CorrectPast(batchID, r, newCat, newSub)
+		ui.Success("%s → %s", log.Filename, dst)
+
+		log.DestPath, log.Category, log.Subcategory, log.Source = dst, newCat, newSub, "user"
+		corrected++
+	}
+
+	if corrected > 0 {
+		ui.Success("已纠正 %d 个文件，规则已更新", corrected)
+	}
+}
+
+// listBatchFiles 列出批次中的文件及其分类
+func listBatchFiles(logs []storage.OperationLog) {
+	for i, log := range logs {
+		fmt.Printf("  %s %s %s %s\n", ui.Green(fmt.Sprintf("[%d]", i+1)), ui.SourceIcon(log.Source),
+			log.Filename, ui.Gray(fmt.Sprintf("(%s/%s)", log.Category, log.Subcategory)))
+	}
+}
@@ -0,0 +1,192 @@
+// Package cmd 命令行入口模块
+// diff 命令：对比本次预览计划与上一次的差异
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/llm"
+	"filo/internal/organizer"
+	"filo/internal/scanner"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// diffCmd 计划对比命令定义
+var diffCmd = &cobra.Command{
+	Use:   "diff <目录>",
+	Short: "对比本次预览与上一次的分类差异",
+	Long: `重新生成目录的整理计划（不移动文件），与上一次预览（filo -n）保存的计划对比，
+只显示新增的文件和分类发生变化的文件。没有预览记录时与最近一次整理批次对比。
+
+适合在更换模型、修改规则或配置后检查分类结果的变化。
+
+示例:
+  filo diff ~/Downloads              # 对比上一次的计划
+  filo diff ~/Downloads -m qwen3:14b # 换模型后对比
+  filo diff ~/Downloads --offline    # 只看记忆和规则的变化`,
+	Args: cobra.ExactArgs(1),
+	Run:  runDiff,
+}
+
+func init() {
+	// 注册 diff 子命令
+	rootCmd.AddCommand(diffCmd)
+
+	// 注册命令行标志
+	diffCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "递归扫描子目录")
+	addScanFlags(diffCmd)
+	diffCmd.Flags().StringVarP(&model, "model", "m", "", "使用的模型")
+	diffCmd.Flags().BoolVar(&offline, "offline", false, "离线模式，只用学习记忆和扩展名分类")
+	diffCmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "允许使用配置的远程 LLM 提供方")
+	diffCmd.RegisterFlagCompletionFunc("model", completeModels)
+}
+
+// runDiff 执行计划对比
+func runDiff(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	sourceDir, err := filepath.Abs(args[0])
+	if err != nil {
+		ui.Error("无效的目录: %v", err)
+		return
+	}
+	if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
+		ui.Error("目录不存在: %s", sourceDir)
+		return
+	}
+
+	cfg := config.Get()
+	applyModel(cfg, sourceDir)
+	if offline {
+		cfg.Offline = true
+	} else if !checkLLMReady(llm.NewClient()) {
+		return
+	}
+
+	l, err := acquireLock()
+	if err != nil {
+		return
+	}
+	defer l.Release()
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	// 先读取对比基准，避免与本次保存的快照混淆
+	base, err := db.GetLatestPlanSnapshot(sourceDir)
+	if err == nil && base == nil {
+		base, err = db.GetLatestBatchSnapshot(sourceDir)
+	}
+	if err != nil {
+		ui.Error("读取历史计划失败: %v", err)
+		return
+	}
+
+	// 生成本次计划
+	if !applyScanFlags(cmd) {
+		return
+	}
+	ui.Title("📂", fmt.Sprintf("%s: %s", scanScope(), sourceDir))
+	files, err := scanner.ScanDirectory(sourceDir, recursive)
+	if err != nil {
+		ui.Error("扫描失败: %v", err)
+		return
+	}
+
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		ui.Error("初始化分类器失败: %v", err)
+		return
+	}
+	defer clf.Close()
+
+	results, err := clf.Classify(files, false)
+	if err != nil {
+		ui.Error("分类失败: %v", err)
+		return
+	}
+	plan := organizer.GeneratePlan(results, filepath.Join(sourceDir, "已整理"))
+	current := plan.Entries(sourceDir)
+	db.SavePlanSnapshot(sourceDir, current)
+
+	if base == nil {
+		ui.Warning("没有可对比的历史计划，已保存本次计划，下次运行 filo diff 时对比")
+		return
+	}
+	printPlanDiff(base, current)
+}
+
+// printPlanDiff 输出新增和分类变化的文件
+func printPlanDiff(base *storage.PlanSnapshot, current []storage.PlanEntry) {
+	previous := make(map[string]storage.PlanEntry, len(base.Entries))
+	for _, e := range base.Entries {
+		previous[e.Path] = e
+	}
+
+	var added []storage.PlanEntry
+	var changed [][2]storage.PlanEntry // 旧分类, 新分类
+	unchanged := 0
+	for _, e := range current {
+		old, ok := previous[e.Path]
+		switch {
+		case !ok:
+			added = append(added, e)
+		case old.Category != e.Category || old.Subcategory != e.Subcategory:
+			changed = append(changed, [2]storage.PlanEntry{old, e})
+		default:
+			unchanged++
+		}
+		delete(previous, e.Path)
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].Path < added[j].Path })
+	sort.Slice(changed, func(i, j int) bool { return changed[i][1].Path < changed[j][1].Path })
+
+	if base.FromBatch {
+		ui.Title("🔀", fmt.Sprintf("对比整理批次 %s", base.ID))
+	} else {
+		ui.Title("🔀", fmt.Sprintf("对比预览计划 %s", base.ID))
+	}
+
+	if len(changed) > 0 {
+		fmt.Println()
+		ui.Info("%s 分类变化 (%d):", ui.Yellow("~"), len(changed))
+		for _, c := range changed {
+			old, e := c[0], c[1]
+			fmt.Printf("    %s %s\n", ui.Yellow("~"), e.Path)
+			fmt.Printf("      %s → %s %s\n", ui.Gray(old.Category+"/"+old.Subcategory),
+				ui.Bold(e.Category+"/"+e.Subcategory), ui.Gray(fmt.Sprintf("(%.0f%% %s)", e.Confidence*100, e.Source)))
+		}
+	}
+
+	if len(added) > 0 {
+		fmt.Println()
+		ui.Info("%s 新文件 (%d):", ui.Green("+"), len(added))
+		for _, e := range added {
+			fmt.Printf("    %s %s → %s %s\n", ui.Green("+"), e.Path,
+				e.Category+"/"+e.Subcategory, ui.Gray(fmt.Sprintf("(%.0f%%)", e.Confidence*100)))
+		}
+	}
+
+	fmt.Println()
+	if len(changed) == 0 && len(added) == 0 {
+		ui.Success("分类结果没有变化")
+	}
+	ui.Dim("未变化: %d 个 | 变化: %d 个 | 新增: %d 个 | 已不在目录中: %d 个",
+		unchanged, len(changed), len(added), len(previous))
+}
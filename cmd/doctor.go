@@ -0,0 +1,152 @@
+// Package cmd 命令行入口模块
+// doctor.go - 环境诊断命令，检查配置、模型、数据库和磁盘空间并给出修复建议
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/config"
+	"filo/internal/doctor"
+	"filo/internal/guard"
+	"filo/internal/organizer"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// doctorCmd 诊断命令定义
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [目录]",
+	Short: "诊断运行环境",
+	Long: `检查 filo 的运行环境，列出问题和修复方法:
+
+  配置    config.json / taxonomy.json 格式和各项取值范围
+  模型    Ollama 服务、分类/嵌入/多模态模型是否已安装，远程提供方的 API 密钥
+  记忆    当前嵌入模型的向量维度与已存学习记录是否一致
+  数据库  完整性检查（PRAGMA integrity_check）和 WAL 文件大小
+  磁盘    数据目录和目标目录的剩余空间
+
+发现错误时以退出码 1 结束，便于在脚本中使用。
+
+示例:
+  filo doctor                  # 诊断运行环境
+  filo doctor ~/Downloads      # 同时检查整理 ~/Downloads 的目标目录的剩余空间
+  filo doctor -t /mnt/归档      # 检查指定目标目录的剩余空间
+  filo doctor --fix            # 将 WAL 文件写回数据库`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runDoctor,
+}
+
+// doctor 命令行参数
+var (
+	doctorFix    bool   // 执行可自动完成的修复
+	doctorTarget string // 要检查剩余空间的目标目录
+)
+
+// init 注册 doctor 子命令
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "执行可自动完成的修复（WAL 检查点）")
+	doctorCmd.Flags().StringVarP(&doctorTarget, "target", "t", "", "目标目录，默认为 <目录>/已整理")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// runDoctor 执行诊断命令
+func runDoctor(cmd *cobra.Command, args []string) {
+	ui.Banner()
+	cfg := config.Get()
+
+	db, dbErr := storage.NewDatabase()
+	if db != nil {
+		defer db.Close()
+	}
+
+	if doctorFix && db != nil {
+		if err := db.Checkpoint(); err != nil {
+			ui.Warning("WAL 检查点未完成: %v", err)
+		} else {
+			ui.Success("已将 WAL 写回数据库")
+		}
+	}
+
+	disks := []doctor.Check{doctor.CheckDisk("数据目录", cfg.DataDir)}
+	if target := doctorTargetDir(args); target != "" {
+		disks = append(disks, doctor.CheckDisk("目标目录", target))
+	}
+
+	sections := []struct {
+		icon, title string
+		checks      []doctor.Check
+	}{
+		{"⚙️", "配置", doctor.CheckConfig()},
+		{"🤖", "模型", doctor.CheckModels(db)},
+		{"🗄️", "数据库", doctor.CheckDatabase(db, dbErr)},
+		{"💾", "磁盘", disks},
+	}
+
+	warns, fails := 0, 0
+	for _, s := range sections {
+		ui.Title(s.icon, s.title)
+		for _, c := range s.checks {
+			printCheck(c)
+			switch c.Status {
+			case doctor.StatusWarn:
+				warns++
+			case doctor.StatusFail:
+				fails++
+			}
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case fails > 0:
+		ui.Error("发现 %d 个错误、%d 个警告", fails, warns)
+		os.Exit(1)
+	case warns > 0:
+		ui.Warning("发现 %d 个警告", warns)
+	default:
+		ui.Success("一切正常")
+	}
+}
+
+// doctorTargetDir 返回要检查剩余空间的目标目录（与整理时相同，按 target_dir 和占位符确定），未指定目录时为空
+func doctorTargetDir(args []string) string {
+	dir := ""
+	if len(args) > 0 {
+		abs, err := filepath.Abs(guard.ExpandHome(args[0]))
+		if err != nil {
+			return ""
+		}
+		dir = abs
+	} else if doctorTarget == "" {
+		return ""
+	}
+	target, err := organizer.ResolveTarget(doctorTarget, dir)
+	if err != nil {
+		ui.Warning("%v", err)
+		return ""
+	}
+	return target
+}
+
+// printCheck 显示单项检查结果和修复建议
+func printCheck(c doctor.Check) {
+	switch c.Status {
+	case doctor.StatusOK:
+		ui.Success("%s: %s", c.Name, c.Detail)
+	case doctor.StatusWarn:
+		ui.Warning("%s: %s", c.Name, c.Detail)
+	default:
+		ui.Error("%s: %s", c.Name, c.Detail)
+	}
+	if c.Fix != "" {
+		ui.Dim("  修复: %s", c.Fix)
+	}
+}
@@ -0,0 +1,185 @@
+// Package cmd 命令行入口模块
+// explain.go - 解释命令，显示单个文件的分类过程
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/llm"
+	"filo/internal/memory"
+	"filo/internal/ocr"
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// explainCmd 解释命令定义
+var explainCmd = &cobra.Command{
+	Use:   "explain <文件>",
+	Short: "解释分类原因",
+	Long: `显示 filo 对单个文件的完整分类过程，不移动任何文件。
+
+依次展示规则匹配、向量匹配、历史匹配的得分，以及最终采用的结果。
+
+示例:
+  filo explain ~/Downloads/合同_v2.pdf         # 只查看记忆匹配
+  filo explain ~/Downloads/合同_v2.pdf --llm   # 同时查看 AI 分类结果`,
+	Args: cobra.ExactArgs(1),
+	Run:  runExplain,
+}
+
+// explain 命令行参数
+var (
+	explainWithLLM bool // 同时调用 LLM 分类
+)
+
+// init 注册 explain 子命令
+func init() {
+	explainCmd.Flags().BoolVar(&explainWithLLM, "llm", false, "同时调用 AI 分类")
+	explainCmd.Flags().StringVarP(&model, "model", "m", "", "使用的模型")
+	explainCmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "允许使用配置的远程 LLM 提供方")
+	explainCmd.RegisterFlagCompletionFunc("model", completeModels)
+	rootCmd.AddCommand(explainCmd)
+}
+
+// runExplain 执行解释命令
+func runExplain(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	f, err := scanner.StatFile(args[0])
+	if err != nil {
+		ui.Error("无法读取文件: %v", err)
+		return
+	}
+	if f.IsDir {
+		ui.Error("%s 是目录，请指定单个文件", f.Path)
+		return
+	}
+
+	cfg := config.Get()
+	applyModel(cfg, filepath.Dir(f.Path))
+
+	// 检查 LLM 服务状态（仅在需要 LLM 时）
+	if explainWithLLM && !checkLLMReady(llm.NewClient()) {
+		ui.Warning("跳过 AI 分类")
+		explainWithLLM = false
+	}
+	if explainWithLLM {
+		checkOCRReady()
+	}
+
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		ui.Error("初始化分类器失败: %v", err)
+		return
+	}
+	defer clf.Close()
+
+	exp, llmResult, err := clf.Explain(f, explainWithLLM)
+
+	ui.Title("🔍", fmt.Sprintf("分类解释: %s", f.Name))
+	ui.Divider()
+	ui.Info("扩展名:   %s", f.Extension)
+	ui.Info("来源目录: %s", f.ParentDir())
+	if f.Origin != "" {
+		ui.Info("下载来源: %s", f.Origin)
+		if hit, ok := clf.MatchOrigin(f); ok {
+			ui.Success("来源规则: → %s/%s（优先于记忆匹配）", hit.Category, hit.Subcategory)
+		}
+	}
+	ui.Info("大小:     %s", ui.FormatSize(f.Size))
+	if f.Media != nil {
+		fields := f.Media.Fields()
+		for _, k := range []string{"title", "artist", "album", "duration", "resolution"} {
+			if v, ok := fields[k]; ok {
+				ui.Info("媒体信息: %s = %s", k, v)
+			}
+		}
+	}
+	if explainWithLLM && cfg.OCRAllowed() && ocr.IsScan(f) {
+		if text, err := ocr.Extract(f); err != nil {
+			ui.Warning("OCR 失败: %v", err)
+		} else {
+			ui.Info("OCR 文字: %s", text)
+		}
+	}
+	if len(exp.Keywords) > 0 {
+		ui.Info("关键词:   %s", strings.Join(exp.Keywords, ", "))
+	} else {
+		ui.Info("关键词:   (无)")
+	}
+	if lang := scanner.DetectLanguage(f.Name); lang != "" {
+		ui.Info("语言:     %s (%s)", scanner.LanguageName(lang), lang)
+	}
+	ui.Info("阈值:     %.0f%%", exp.Threshold*100)
+
+	// 显示各记忆来源的匹配结果
+	ui.Title("🧠", "记忆匹配")
+	printExplainMatch("内容匹配", exp.Content, cfg)
+	printExplainMatch("规则匹配", exp.Rule, cfg)
+	printExplainMatch("向量匹配", exp.Vector, cfg)
+	printExplainMatch("历史匹配", exp.History, cfg)
+	label := "综合打分"
+	if cfg.MemoryScoring != memory.ScoringEnsemble {
+		label += ui.Gray("（未启用）")
+	}
+	printExplainMatch(label, exp.Ensemble.Match, cfg)
+
+	// 显示 LLM 结果
+	if explainWithLLM {
+		ui.Title("🤖", fmt.Sprintf("AI分类 (%s)", cfg.ActiveModel()))
+		if err != nil {
+			ui.Error("AI 分类失败: %v", err)
+		} else if llmResult != nil {
+			ui.Info("%s %s/%s  %.0f%%", ui.ConfidenceIcon(llmResult.Confidence),
+				llmResult.Category, llmResult.Subcategory, llmResult.Confidence*100)
+			if llmResult.Reasoning != "" {
+				ui.Dim("   └─ %s", llmResult.Reasoning)
+			}
+		}
+	}
+
+	// 显示最终结论
+	ui.Title("📌", "结论")
+	switch {
+	case exp.Final != nil:
+		ui.Success("采用记忆结果 (%s): %s/%s", exp.Final.Source, exp.Final.Category, exp.Final.Subcategory)
+	case llmResult != nil:
+		ui.Success("记忆未命中，采用 AI 结果: %s/%s", llmResult.Category, llmResult.Subcategory)
+	default:
+		ui.Warning("记忆未命中，整理时将交给 AI 分类")
+		if !explainWithLLM {
+			ui.Dim("使用 --llm 查看 AI 分类结果")
+		}
+	}
+}
+
+// printExplainMatch 打印单个记忆来源的匹配结果
+// 匹配到的主分类设置了单独阈值时一并显示
+func printExplainMatch(label string, match *memory.Match, cfg *config.Config) {
+	if match == nil {
+		fmt.Printf("  %s %s  %s\n", ui.Gray("○"), label, ui.Gray("无匹配"))
+		return
+	}
+
+	threshold := cfg.SimilarityThresholdFor(match.Category)
+	status := ui.Red("未达阈值")
+	if match.Confidence >= threshold {
+		status = ui.Green("命中")
+	}
+	if threshold != cfg.SimilarityThreshold {
+		status += ui.Gray(fmt.Sprintf(" (分类阈值 %.0f%%)", threshold*100))
+	}
+	fmt.Printf("  %s %s  %s/%s  %.0f%%  %s\n", ui.ConfidenceIcon(match.Confidence), label,
+		match.Category, match.Subcategory, match.Confidence*100, status)
+	ui.Dim("   └─ %s", match.Reasoning)
+}
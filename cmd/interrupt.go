@@ -0,0 +1,74 @@
+// Package cmd 命令行入口模块
+// interrupt.go - Ctrl-C 取消整理：中断进行中的 AI 请求，保存已完成的部分
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"filo/internal/classifier"
+	"filo/internal/organizer"
+	"filo/internal/ui"
+)
+
+// errInterrupted 整理被取消时静默模式通知中的错误
+var errInterrupted = errors.New("整理已中断，只处理了部分文件")
+
+// interruptContext 创建收到 Ctrl-C（或 SIGTERM）时取消的上下文
+// 第一次 Ctrl-C 取消上下文，等待进行中的操作结束并保存已完成的部分；
+// 之后恢复默认处理，再按一次 Ctrl-C 直接退出
+//
+// 返回值:
+//   - context.Context: 取消整理用的上下文
+//   - func(): 结束时调用，停止监听信号
+func interruptContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			fmt.Println()
+			ui.Warning("正在中断: 等待进行中的操作结束，保存已完成的部分（再按 Ctrl-C 强制退出）")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// watchInterrupt 让分类器响应 Ctrl-C
+// 返回的函数在命令结束时调用
+func watchInterrupt(clf *classifier.Classifier) func() {
+	ctx, stop := interruptContext()
+	clf.SetContext(ctx)
+	return stop
+}
+
+// printClassifyInterrupted 分类阶段被取消时的提示
+// 已分类的文件不移动；AI 分类的结果已学习，重新运行时直接从记忆命中
+func printClassifyInterrupted(sourceDir string, classified, total int) {
+	ui.Warning("已中断: 分类了 %d/%d 个文件，没有移动任何文件", classified, total)
+	ui.Dim("重新运行 filo %s 继续，已完成的 AI 分类会直接从记忆命中", sourceDir)
+}
+
+// printExecuteInterrupted 执行阶段被取消时的提示：如何继续整理或撤销已移动的文件
+func printExecuteInterrupted(sourceDir string, result organizer.ExecuteResult) {
+	ui.Dim("重新运行 filo %s 继续整理剩余文件", sourceDir)
+	if result.Success > 0 {
+		ui.Dim("或 filo undo %s 撤销已移动的 %d 个文件", result.BatchID, result.Success)
+	}
+}
@@ -0,0 +1,423 @@
+// Package cmd 命令行入口模块
+// last 命令：查看最近一次整理，--fix 用简短的命令逐个修正
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/llm"
+	"filo/internal/organizer"
+	"filo/internal/scanner"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// lastCmd 最近一次整理命令定义
+var lastCmd = &cobra.Command{
+	Use:   "last",
+	Short: "查看并快速修正最近一次整理",
+	Long: `显示最近一次整理的摘要（目录、模型、耗时、各来源的文件数、新建的分类、主要分类和失败的文件），
+并列出整理的文件（带编号）。关闭终端后也可以用它查看整理结果。
+
+加 --fix 进入快速修正模式，每行输入一条命令:
+  3 -> 工作/报销      把 3 号文件移到 工作/报销，并学习这次纠正
+  3,5-7 -> 工作/报销  多个文件一起移动
+  7 undo             把 7 号文件移回原位置（也可以写 7 u）
+  l                  重新列出文件
+  q 或回车           结束
+
+修正会立即移动文件、学习纠正结果并更新操作记录，之后 filo undo 从新位置撤销。
+
+示例:
+  filo last          # 查看最近一次整理
+  filo last --fix    # 快速修正`,
+	Args: cobra.NoArgs,
+	Run:  runLast,
+}
+
+// lastTopCategories 摘要中显示的主要分类数
+const lastTopCategories = 5
+
+// last 命令行参数
+var lastFix bool // 进入快速修正模式
+
+func init() {
+	// 注册 last 子命令
+	rootCmd.AddCommand(lastCmd)
+
+	// 注册命令行标志
+	lastCmd.Flags().BoolVar(&lastFix, "fix", false, "快速修正模式")
+}
+
+// runLast 执行 last 命令
+func runLast(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	if lastFix {
+		l, err := acquireLock()
+		if err != nil {
+			return
+		}
+		defer l.Release()
+	}
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	// 之后的批次没有运行摘要时（如 filo review 的整理）只列出文件
+	batchID := db.GetLatestBatch()
+	run, err := db.GetLatestRun()
+	if err != nil {
+		ui.Warning("读取运行摘要失败: %v", err)
+	}
+	if run != nil && run.BatchID >= batchID {
+		batchID = run.BatchID
+	} else {
+		run = nil
+	}
+	if batchID == "" {
+		ui.Warning("没有整理记录")
+		return
+	}
+	logs, err := db.GetBatchLogs(batchID)
+	if err != nil {
+		ui.Error("读取批次失败: %v", err)
+		return
+	}
+	// 移入待确认文件夹的文件由 filo review 处理
+	filtered := logs[:0]
+	for _, log := range logs {
+		if log.Category != organizer.ReviewFolder {
+			filtered = append(filtered, log)
+		}
+	}
+	logs = filtered
+	if run != nil {
+		ui.Title("🕘", fmt.Sprintf("最近一次整理: %s", batchID))
+		printRunSummary(run, logs)
+		fmt.Println()
+	}
+	if len(logs) == 0 {
+		if run == nil {
+			ui.Warning("批次 %s 中没有已整理的文件，待确认的文件请用 filo review 处理", batchID)
+		}
+		return
+	}
+
+	if run == nil {
+		ui.Title("🕘", fmt.Sprintf("最近一次整理: %s（%d 个文件）", batchID, len(logs)))
+	} else {
+		ui.Info("整理的文件（%d 个）:", len(logs))
+	}
+	undone := make(map[int]bool)
+	listLastFiles(logs, undone)
+
+	if !lastFix {
+		fmt.Println()
+		ui.Dim("用 filo last --fix 修正分错的文件")
+		return
+	}
+
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		ui.Error("初始化分类器失败: %v", err)
+		return
+	}
+	defer clf.Close()
+
+	fmt.Println()
+	ui.Dim("命令: 3 -> 工作/报销 | 7 undo | l 列出 | q 结束")
+	moved, restored := 0, 0
+	reader := bufio.NewReader(os.Stdin) // 同一个 reader 读取每行命令，可以一次粘贴多行
+loop:
+	for {
+		fmt.Print("  修正: ")
+		line, err := reader.ReadString('\n')
+		input := strings.TrimSpace(line)
+		if err != nil && input == "" {
+			break // 输入结束
+		}
+		switch strings.ToLower(input) {
+		case "", "q":
+			break loop
+		case "l":
+			listLastFiles(logs, undone)
+			continue
+		}
+
+		nums, target, undo, err := parseFixCommand(input, len(logs))
+		if err != nil {
+			ui.Warning("%v", err)
+			continue
+		}
+		for _, n := range nums {
+			log := &logs[n-1]
+			if undone[n] {
+				ui.Warning("[%d] %s 已撤销", n, log.Filename)
+				continue
+			}
+			if undo {
+				path, notes, err := organizer.UndoFile(db, *log)
+				for _, note := range notes {
+					ui.Warning("%s", note)
+				}
+				if err != nil {
+					ui.Error("[%d] %s: %v", n, log.Filename, err)
+					continue
+				}
+				undone[n] = true
+				restored++
+				ui.Success("[%d] %s ↩ %s", n, log.Filename, path)
+				continue
+			}
+			if fixFile(db, clf, batchID, log, target) {
+				moved++
+				ui.Success("[%d] %s → %s", n, log.Filename, categoryLabel(*log))
+			}
+		}
+	}
+
+	if moved > 0 || restored > 0 {
+		ui.Success("已修正 %d 个文件，撤销 %d 个文件", moved, restored)
+	}
+}
+
+// fixFile 将文件移到新分类并学习纠正结果，成功后更新 log
+func fixFile(db *storage.Database, clf *classifier.Classifier, batchID string, log *storage.OperationLog, target string) bool {
+	newCat, newSub := classifier.Normalize(target, "")
+	if newCat == log.Category && newSub == log.Subcategory {
+		ui.Dim("%s 已在 %s", log.Filename, categoryLabel(*log))
+		return false
+	}
+
+	dst, err := organizer.Recategorize(db, *log, newCat, newSub)
+	if err != nil {
+		ui.Error("%s: %v", log.Filename, err)
+		return false
+	}
+
+	// 按文件的原始位置学习（来源目录规则）
+	r := classifier.Result{
+		FileInfo:    scanner.FileInfo{Path: log.SourcePath, Name: log.Filename, ContentHash: scanner.HashFile(dst)},
+		Category:    log.Category,
+		Subcategory: log.Subcategory,
+		Source:      log.Source,
+	}
+	clf.CorrectPast(batchID, r, newCat, newSub)
+
+	log.DestPath, log.Category, log.Subcategory, log.Source = dst, newCat, newSub, "user"
+	return true
+}
+
+// parseFixCommand 解析快速修正命令
+// 支持 "3 -> 工作/报销"、"3,5-7 → 工作"、"7 undo"、"7 u"
+//
+// 参数:
+//   - input: 用户输入
+//   - total: 文件数，编号范围为 1-total
+//
+// 返回值:
+//   - []int: 文件编号
+//   - string: 目标分类路径（撤销时为空）
+//   - bool: 是否撤销
+//   - error: 命令格式错误或编号超出范围时返回错误
+func parseFixCommand(input string, total int) ([]int, string, bool, error) {
+	var left, target string
+	undo := false
+	if i := strings.Index(input, "->"); i >= 0 {
+		left, target = input[:i], strings.TrimSpace(input[i+2:])
+	} else if i := strings.Index(input, "→"); i >= 0 {
+		left, target = input[:i], strings.TrimSpace(input[i+len("→"):])
+	} else {
+		fields := strings.Fields(input)
+		if len(fields) < 2 {
+			return nil, "", false, fmt.Errorf("无法识别的命令: %s（示例: 3 -> 工作/报销，7 undo）", input)
+		}
+		last := strings.ToLower(fields[len(fields)-1])
+		if last != "undo" && last != "u" {
+			return nil, "", false, fmt.Errorf("无法识别的命令: %s（示例: 3 -> 工作/报销，7 undo）", input)
+		}
+		left, undo = strings.Join(fields[:len(fields)-1], " "), true
+	}
+	if !undo && target == "" {
+		return nil, "", false, fmt.Errorf("缺少目标分类（示例: 3 -> 工作/报销）")
+	}
+
+	nums, err := parseNumbers(left, total)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return nums, target, undo, nil
+}
+
+// parseNumbers 解析文件编号列表，如 "3"、"3,5"、"5-7"、"3 5-7"
+func parseNumbers(s string, total int) ([]int, error) {
+	var nums []int
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '，' || r == ' ' })
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("缺少文件编号")
+	}
+	for _, f := range fields {
+		from, to := f, f
+		if i := strings.Index(f, "-"); i > 0 {
+			from, to = f[:i], f[i+1:]
+		}
+		a, err1 := strconv.Atoi(from)
+		b, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil || a > b {
+			return nil, fmt.Errorf("无效的编号: %s", f)
+		}
+		if a < 1 || b > total {
+			return nil, fmt.Errorf("编号超出范围: %s（共 %d 个文件）", f, total)
+		}
+		for n := a; n <= b; n++ {
+			nums = append(nums, n)
+		}
+	}
+	return nums, nil
+}
+
+// printRunSummary 显示运行摘要：时间、目录、模型、执行结果、各来源的文件数、新建的分类、主要分类和失败的文件
+func printRunSummary(run *storage.RunStats, logs []storage.OperationLog) {
+	ui.Info("时间:      %s（耗时 %s）", run.CreatedAt.Local().Format("2006-01-02 15:04"), run.Duration.Round(100*time.Millisecond))
+	if run.SourceDir != "" {
+		if run.TargetDir != "" && run.TargetDir != run.SourceDir {
+			ui.Info("目录:      %s → %s", run.SourceDir, run.TargetDir)
+		} else {
+			ui.Info("目录:      %s", run.SourceDir)
+		}
+	}
+	if run.Model != "" {
+		ui.Info("模型:      %s", run.Model)
+	} else {
+		ui.Info("模型:      未调用 AI（离线或仅规则模式）")
+	}
+	dir := llm.TranscriptDir(run.BatchID)
+	if _, err := os.Stat(dir); err == nil {
+		ui.Info("调用记录:  %s", dir)
+	}
+
+	parts := []string{fmt.Sprintf("移动 %d", run.Moved)}
+	for _, p := range []struct {
+		n     int
+		label string
+	}{{run.Review, "待确认"}, {run.Skipped, "跳过"}, {run.Failed, "失败"}, {run.CorrectedCount, "已纠正"}} {
+		if p.n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", p.label, p.n))
+		}
+	}
+	ui.Info("结果:      %s（共分类 %d 个文件）", strings.Join(parts, " · "), run.FileCount)
+	if run.Partial {
+		ui.Warning("整理被中断，只处理了部分文件")
+	}
+	if run.Aborted != "" {
+		ui.Warning("未执行: %s", run.Aborted)
+	}
+
+	if len(run.Sources) > 0 {
+		ui.Info("来源:      %s", formatCounts(run.Sources, 0, ui.SourceIcon))
+	}
+	if len(run.CategoriesCreated) > 0 {
+		ui.Info("新建分类:  %s", strings.Join(run.CategoriesCreated, "、"))
+	}
+	if len(logs) > 0 {
+		categories := make(map[string]int)
+		for _, log := range logs {
+			categories[categoryLabel(log)]++
+		}
+		ui.Info("主要分类:  %s", formatCounts(categories, lastTopCategories, nil))
+	}
+
+	if len(run.Failures) > 0 {
+		ui.Error("失败的文件:")
+		for _, f := range run.Failures {
+			ui.Dim("  %s", f)
+		}
+		if run.Failed > len(run.Failures) {
+			ui.Dim("  ... 还有 %d 个文件", run.Failed-len(run.Failures))
+		}
+		if run.Aborted == "" {
+			ui.Dim("用 filo retry %s 重试失败的文件", run.BatchID)
+		}
+	}
+}
+
+// formatCounts 按数量从多到少格式化计数，如「文档 12 · 图片 8」
+// limit 大于 0 时只显示前 limit 项；label 不为空时用它显示名称（如来源图标）
+func formatCounts(counts map[string]int, limit int, label func(string) string) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	more := 0
+	if limit > 0 && len(names) > limit {
+		more = len(names) - limit
+		names = names[:limit]
+	}
+	parts := make([]string, len(names))
+	for i, name := range names {
+		display := name
+		if label != nil {
+			display = label(name) + " " + name
+		}
+		parts[i] = fmt.Sprintf("%s %d", display, counts[name])
+	}
+	s := strings.Join(parts, " · ")
+	if more > 0 {
+		s += fmt.Sprintf(" · 另有 %d 个分类", more)
+	}
+	return s
+}
+
+// listLastFiles 列出批次中的文件及其分类，已撤销的文件标记出来
+func listLastFiles(logs []storage.OperationLog, undone map[int]bool) {
+	for i, log := range logs {
+		n := i + 1
+		if undone[n] {
+			fmt.Printf("  %s %s %s\n", ui.Gray(fmt.Sprintf("[%d]", n)), ui.Gray(log.Filename), ui.Gray("(已撤销)"))
+			continue
+		}
+		fmt.Printf("  %s %s %s %s\n", ui.Green(fmt.Sprintf("[%d]", n)), ui.SourceIcon(log.Source),
+			log.Filename, ui.Gray("("+categoryLabel(log)+")"))
+		// 分类操作的执行结果
+		if organizer.PostActionFailed(log.PostActions) {
+			fmt.Printf("      %s\n", ui.Yellow(log.PostActions))
+		} else if log.PostActions != "" {
+			fmt.Printf("      %s\n", ui.Gray(log.PostActions))
+		}
+		if label := organizer.TransferLabel(log.Transfer); label != "" {
+			fmt.Printf("      %s\n", ui.Gray(label))
+		}
+	}
+}
+
+// categoryLabel 显示用的分类路径，没有子分类时只显示主分类
+func categoryLabel(log storage.OperationLog) string {
+	if log.Subcategory == "" {
+		return log.Category
+	}
+	return log.Category + "/" + log.Subcategory
+}
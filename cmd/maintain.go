@@ -0,0 +1,207 @@
+// Package cmd 命令行入口模块
+// maintain.go - 维护命令：查看学习记录的向量分布，更换嵌入模型后按当前嵌入器重新生成旧向量
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cobra"
+
+	"filo/internal/embedding"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// reembedChunk 重新生成向量时每批处理的记录数，每批单独提交，中断后再次运行从剩余的记录继续
+const reembedChunk = 64
+
+// maintainReembed 按当前嵌入器重新生成向量
+var maintainReembed bool
+
+// errReembedUnavailable 重新生成过程中嵌入器不可用
+var errReembedUnavailable = errors.New("嵌入器中途不可用（已回退到本地嵌入），停止重新生成")
+
+// maintainCmd 维护命令定义
+var maintainCmd = &cobra.Command{
+	Use:   "maintain",
+	Short: "维护学习数据",
+	Long: `查看学习记录的向量由哪个嵌入器生成，以及有多少条不是当前嵌入器生成的。
+
+检索相似文件时只比较同一嵌入器、同一维度的向量。更换嵌入模型后，
+旧向量不再参与相似度匹配；--reembed 按记录的文件名用当前嵌入器重新生成这些向量，
+学习记录和规则保持不变。中断后再次运行会从剩余的记录继续。
+
+示例:
+  filo maintain              # 查看向量分布
+  filo maintain --reembed    # 按当前嵌入器重新生成旧向量`,
+	Args: cobra.NoArgs,
+	Run:  runMaintain,
+}
+
+// init 注册 maintain 子命令及其标志
+func init() {
+	maintainCmd.Flags().BoolVar(&maintainReembed, "reembed", false, "按当前嵌入器重新生成不是它生成的向量")
+	rootCmd.AddCommand(maintainCmd)
+}
+
+// runMaintain 执行维护命令
+func runMaintain(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	if maintainReembed {
+		l, err := acquireLock()
+		if err != nil {
+			return
+		}
+		defer l.Release()
+	}
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("数据库连接失败: %v", err)
+		return
+	}
+	defer db.Close()
+
+	e := embedding.NewEmbedder()
+	if c, ok := e.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	// 用一段探测文本确定当前嵌入器的向量空间
+	probe := e.Embed("filo")
+	current := storage.VectorSpace{Embedder: embedding.NameOf(e, probe), Dim: len(probe)}
+	unavailable := current.Embedder == embedding.EmbedderLocal && embedding.ConfiguredName() != embedding.EmbedderLocal
+	if unavailable {
+		ui.Warning("嵌入器 %s 不可用，当前回退到本地嵌入", embedding.ConfiguredName())
+	}
+
+	if !printVectorSpaces(db, current) {
+		return
+	}
+	stale, err := db.CountStaleVectors(current)
+	if err != nil {
+		ui.Error("统计向量失败: %v", err)
+		return
+	}
+
+	if !maintainReembed {
+		if stale > 0 {
+			ui.Info("%d 条向量不是当前嵌入器生成的，运行 filo maintain --reembed 重新生成", stale)
+		}
+		return
+	}
+	if unavailable {
+		ui.Error("嵌入器不可用，未重新生成向量；用 filo doctor 检查嵌入模型")
+		return
+	}
+	if stale == 0 {
+		ui.Success("全部向量均由当前嵌入器生成，无需重新生成")
+		return
+	}
+
+	done, err := reembedVectors(db, e, current, stale)
+	if err != nil {
+		ui.Error("%v", err)
+		ui.Info("已重新生成 %d/%d 条，修复后再次运行可继续", done, stale)
+		return
+	}
+	ui.Success("已按 %s 重新生成 %d 条向量", current.Embedder, done)
+}
+
+// printVectorSpaces 显示已存向量按嵌入器和维度的分布，失败时返回 false
+func printVectorSpaces(db *storage.Database, current storage.VectorSpace) bool {
+	spaces, err := db.VectorSpaces()
+	if err != nil {
+		ui.Error("统计向量失败: %v", err)
+		return false
+	}
+
+	ui.Info("当前嵌入器: %s（%d 维）", current.Embedder, current.Dim)
+	if len(spaces) == 0 {
+		ui.Dim("尚无学习记录")
+		return true
+	}
+
+	keys := make([]storage.VectorSpace, 0, len(spaces))
+	for s := range spaces {
+		keys = append(keys, s)
+	}
+	sort.Slice(keys, func(i, j int) bool { return spaces[keys[i]] > spaces[keys[j]] })
+
+	lines := make([]string, len(keys))
+	for i, s := range keys {
+		name, mark := s.Embedder, "✓"
+		if name == "" {
+			name = "未记录嵌入器"
+		}
+		switch {
+		case !current.Accepts(s):
+			mark = "✗"
+		case s.Embedder == "":
+			mark = "?"
+		}
+		lines[i] = fmt.Sprintf("%s %s（%d 维）: %d 条", mark, name, s.Dim, spaces[s])
+	}
+	ui.Box("学习记录的向量", lines)
+	return true
+}
+
+// reembedVectors 按当前嵌入器分批重新生成向量
+// 嵌入器中途失败（回退到本地嵌入）时停止，已提交的批次保留
+//
+// 返回值:
+//   - int: 已重新生成的向量数
+//   - error: 查询、嵌入或写入失败时返回错误
+func reembedVectors(db *storage.Database, e embedding.Embedder, current storage.VectorSpace, total int) (int, error) {
+	bar := progressbar.NewOptions(total,
+		progressbar.OptionSetDescription("重新生成向量"),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetVisibility(!ui.IsQuiet()),
+	)
+	defer func() {
+		bar.Finish()
+		if !ui.IsQuiet() {
+			fmt.Println()
+		}
+	}()
+
+	var afterID int64
+	done := 0
+	for {
+		batch, err := db.StaleVectors(current, afterID, reembedChunk)
+		if err != nil {
+			return done, err
+		}
+		if len(batch) == 0 {
+			return done, nil
+		}
+
+		ids := make([]int64, len(batch))
+		names := make([]string, len(batch))
+		for i, v := range batch {
+			ids[i], names[i] = v.ID, v.Filename
+		}
+		vecs := e.EmbedBatch(names)
+		for _, vec := range vecs {
+			if len(vec) != current.Dim || embedding.NameOf(e, vec) != current.Embedder {
+				return done, errReembedUnavailable
+			}
+		}
+		if err := db.ReplaceVectors(current, ids, vecs); err != nil {
+			return done, err
+		}
+
+		afterID = ids[len(ids)-1]
+		done += len(batch)
+		bar.Add(len(batch))
+	}
+}
@@ -0,0 +1,87 @@
+// Package cmd 命令行入口模块
+// mcp 命令：以 MCP（Model Context Protocol）服务运行，供 AI 助手调用
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"filo/internal/config"
+	"filo/internal/llm"
+	"filo/internal/mcp"
+	"filo/internal/ui"
+)
+
+// mcpCmd MCP 服务命令定义
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "以 MCP 服务运行，供 AI 助手调用",
+	Long: `通过标准输入输出提供 MCP（Model Context Protocol）服务，
+让 Claude Desktop 等 AI 助手使用 filo 的学习记忆整理文件。
+
+提供的工具:
+  classify_directory  分类目录并返回整理计划，execute=true 时执行
+  find_file           按文件名查找整理过的文件
+  get_statistics      查看学习统计和最近的批次
+  undo_batch          撤销一次整理
+
+整理沿用命令行的安全检查：受保护目录拒绝整理，与其他 filo 进程互斥。
+日志输出到标准错误，标准输出只用于协议消息。
+加 --metrics 时在指定地址提供 Prometheus 指标（/metrics）。
+
+在 Claude Desktop 的配置文件中添加:
+  {"mcpServers": {"filo": {"command": "filo", "args": ["mcp"]}}}`,
+	Args: cobra.NoArgs,
+	Run:  runMCP,
+}
+
+func init() {
+	// 注册 mcp 子命令
+	rootCmd.AddCommand(mcpCmd)
+
+	// 注册命令行标志
+	mcpCmd.Flags().BoolVar(&offline, "offline", false, "离线模式，只用学习记忆和扩展名分类")
+	mcpCmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "允许使用配置的远程 LLM 提供方")
+	mcpCmd.Flags().StringVar(&metricsAddr, "metrics", "", "在指定地址提供 Prometheus 指标（/metrics），如 127.0.0.1:9464")
+}
+
+// runMCP 运行 MCP 服务，直到标准输入关闭
+func runMCP(cmd *cobra.Command, args []string) {
+	// 标准输出只留给协议消息，其他输出（进度条、提示）改写到标准错误
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	color.NoColor = true
+	ui.SetQuiet(true)
+
+	// LLM 不可用时仍可查找文件、查看统计和撤销，整理降级为离线模式
+	cfg := config.Get()
+	if offline {
+		cfg.Offline = true
+	} else if !checkLLMReady(llm.NewClient()) {
+		ui.Warning("AI 分类不可用，整理将使用离线模式")
+		cfg.Offline = true
+	}
+
+	srv, err := mcp.NewServer(out)
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer srv.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	serveMetrics(ctx)
+
+	if err := srv.Serve(ctx, os.Stdin); err != nil {
+		ui.Error("读取请求失败: %v", err)
+	}
+}
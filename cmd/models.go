@@ -160,8 +160,8 @@ func showModelStats() {
 	fmt.Println()
 
 	// 表头
-	fmt.Printf("  %-20s %8s %10s %10s %8s %8s\n",
-		"模型", "文件数", "速度", "置信度", "准确率", "评分")
+	fmt.Printf("  %-20s %8s %10s %6s %10s %8s %8s\n",
+		"模型", "文件数", "速度", "批大小", "置信度", "准确率", "评分")
 	ui.Divider()
 
 	// 显示每个模型的统计
@@ -180,12 +180,17 @@ func showModelStats() {
 
 		// 格式化速度
 		speedStr := fmt.Sprintf("%.0fms", s.AvgTimePerFileMs)
+		batchStr := "-"
+		if s.AvgBatchSize > 0 {
+			batchStr = fmt.Sprintf("%.0f", s.AvgBatchSize)
+		}
 
 		// 显示一行统计
-		fmt.Printf("  %-20s %8d %10s %9.0f%% %7.0f%% %7.0f%%%s\n",
+		fmt.Printf("  %-20s %8d %10s %6s %9.0f%% %7.0f%% %7.0f%%%s\n",
 			truncateModelName(s.ModelName, 20),
 			s.TotalFiles,
 			speedStr,
+			batchStr,
 			s.AvgConfidence*100,
 			s.AccuracyRate*100,
 			s.Score*100,
@@ -0,0 +1,169 @@
+// Package cmd 命令行入口模块
+// pick 命令：按一句话指令挑选文件移到指定分类，其余文件不动
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/llm"
+	"filo/internal/organizer"
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// pickCmd 按指令挑选文件命令定义
+var pickCmd = &cobra.Command{
+	Use:   "pick <目录> <指令>",
+	Short: "按一句话指令挑选文件归入指定分类",
+	Long: `用自然语言说明要整理哪些文件、放到哪里，由 AI 从目录中挑出符合的文件，
+其余文件不分类、不移动。
+
+目标分类从指令中提取（如「放到 财务/报销」），也可以用 --to 明确指定。
+执行前显示挑选结果并确认；整理是一个普通批次，可以用 filo undo 撤销。
+
+示例:
+  filo pick ~/Downloads "把所有和报销相关的文件放到 财务/报销"
+  filo pick ~/Documents "客户A 的合同" --to 工作/客户A/合同 -r
+  filo pick ~/Desktop "去年的旅行照片" --to 图片/旅行 -n   # 只预览`,
+	Args: cobra.ExactArgs(2),
+	Run:  runPick,
+}
+
+// pick 命令行参数
+var pickTo string // 目标分类路径
+
+func init() {
+	pickCmd.Flags().StringVar(&pickTo, "to", "", "目标分类，格式: 主分类/子分类（默认从指令中提取）")
+	pickCmd.Flags().StringVarP(&targetDir, "target", "t", "", "目标目录（默认 <目录>/已整理）")
+	pickCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "预览模式")
+	pickCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "递归扫描子目录")
+	addScanFlags(pickCmd)
+	pickCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "详细输出")
+	pickCmd.Flags().StringVarP(&model, "model", "m", "", "使用的模型")
+	pickCmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "允许使用配置的远程 LLM 提供方")
+	pickCmd.Flags().BoolVar(&showRedactions, "show-redactions", false, "列出发送给远程提供方前从文件名中隐去的个人信息")
+	pickCmd.Flags().BoolVar(&debugLLM, "debug-llm", false, "把每次模型调用的提示词和原始响应保存到 ~/.filo/debug/<批次ID>/")
+	pickCmd.Flags().BoolVar(&force, "force", false, "允许整理受保护的目录（系统目录、主目录等）")
+
+	pickCmd.RegisterFlagCompletionFunc("to", completeCategories)
+	pickCmd.RegisterFlagCompletionFunc("model", completeModels)
+
+	// 注册 pick 子命令
+	rootCmd.AddCommand(pickCmd)
+}
+
+// runPick 执行按指令挑选
+func runPick(cmd *cobra.Command, args []string) {
+	sourceDir, instruction := args[0], strings.TrimSpace(args[1])
+	if instruction == "" {
+		ui.Error("指令不能为空")
+		return
+	}
+
+	ui.Banner()
+
+	if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
+		ui.Error("目录不存在: %s", sourceDir)
+		return
+	}
+	if targetDir == "" {
+		targetDir = filepath.Join(sourceDir, "已整理")
+	}
+	if !checkPathsSafe(sourceDir, targetDir) {
+		return
+	}
+
+	l, err := acquireLock()
+	if err != nil {
+		return
+	}
+	defer l.Release()
+
+	cfg := config.Get()
+	applyModel(cfg, sourceDir)
+	if !checkLLMReady(llm.NewClient()) {
+		return
+	}
+
+	// ========== 步骤1: 扫描目录 ==========
+	if !applyScanFlags(cmd) {
+		return
+	}
+	ui.Title("📂", fmt.Sprintf("%s: %s", scanScope(), sourceDir))
+	files, err := scanner.ScanDirectory(sourceDir, recursive)
+	if err != nil {
+		ui.Error("扫描失败: %v", err)
+		return
+	}
+	fileCount := 0
+	for _, f := range files {
+		if !f.IsDir {
+			fileCount++
+		}
+	}
+	ui.Success("找到 %d 个文件", fileCount)
+	if fileCount == 0 {
+		ui.Warning("没有文件可以挑选")
+		return
+	}
+
+	// ========== 步骤2: 按指令挑选 ==========
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		ui.Error("初始化分类器失败: %v", err)
+		return
+	}
+	defer clf.Close()
+	defer watchInterrupt(clf)()
+	defer startTranscript(clf)()
+
+	ui.Title("🎯", "按指令挑选: "+instruction)
+	results, err := clf.Pick(files, instruction, pickTo, verbose)
+	if err != nil {
+		ui.Error("挑选失败: %v", err)
+		return
+	}
+	if showRedactions {
+		printRedactions(clf.Redactions())
+	}
+	if clf.Interrupted() {
+		ui.Warning("已中断，没有移动任何文件")
+		return
+	}
+	if len(results) == 0 {
+		ui.Warning("没有符合指令的文件")
+		return
+	}
+	ui.Success("挑选出 %d 个文件（共 %d 个），其余文件不动", len(results), fileCount)
+
+	plan := organizer.GeneratePlan(results, targetDir)
+	plan.SourceDir, _ = filepath.Abs(sourceDir)
+	organizer.PrintPlan(plan)
+
+	if dryRun {
+		ui.Warning("预览模式 - 未执行实际操作")
+		ui.Dim("去掉 -n 参数执行实际整理")
+		return
+	}
+	if !organizer.Confirm("\n确认移动这些文件?") {
+		ui.Warning("已取消")
+		return
+	}
+
+	// ========== 步骤3: 执行 ==========
+	result := organizer.Execute(plan, clf, verbose)
+	if result.Interrupted > 0 {
+		printExecuteInterrupted(sourceDir, result)
+	}
+}
@@ -0,0 +1,172 @@
+// Package cmd 命令行入口模块
+// pin-model 命令：把模型固定到目录，整理该目录时不受默认模型和推荐模型影响
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/config"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// pinModelCmd 目录固定模型命令定义
+var pinModelCmd = &cobra.Command{
+	Use:   "pin-model [目录] [模型]",
+	Short: "为目录固定使用的模型",
+	Long: `把模型固定到目录。整理、归档、对比该目录及其子目录时始终使用固定的模型，
+不受默认模型（filo config --model）和推荐模型影响，适合需要更高准确率的文件夹。
+
+命令行的 -m 参数仍然优先；子目录固定了其他模型时，以最近的固定为准。
+
+示例:
+  filo pin-model ~/Work qwen3:14b    # 整理 ~/Work 时始终使用 qwen3:14b
+  filo pin-model                     # 列出所有固定的模型
+  filo pin-model ~/Work              # 查看 ~/Work 使用的固定模型
+  filo pin-model ~/Work --rm         # 取消固定`,
+	Args: cobra.MaximumNArgs(2),
+	Run:  runPinModel,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		case 1:
+			return completeModels(cmd, args, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+// pin-model 命令行参数
+var unpinModel bool // 取消目录的固定模型
+
+func init() {
+	rootCmd.AddCommand(pinModelCmd)
+	pinModelCmd.Flags().BoolVar(&unpinModel, "rm", false, "取消目录的固定模型")
+}
+
+// runPinModel 执行 pin-model 命令
+func runPinModel(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	if unpinModel && len(args) != 1 {
+		ui.Error("--rm 需要且只需要指定目录")
+		return
+	}
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	if len(args) == 0 {
+		listModelPins(db)
+		return
+	}
+
+	dir, err := filepath.Abs(args[0])
+	if err != nil {
+		ui.Error("无效的目录: %v", err)
+		return
+	}
+
+	switch {
+	case unpinModel:
+		removed, err := db.UnpinModel(dir)
+		if err != nil {
+			ui.Error("取消失败: %v", err)
+			return
+		}
+		if !removed {
+			ui.Warning("%s 没有固定模型", dir)
+			return
+		}
+		ui.Success("已取消 %s 的固定模型", dir)
+
+	case len(args) == 1:
+		pin := db.GetPinnedModel(dir)
+		if pin == nil {
+			ui.Info("%s 没有固定模型，使用默认模型 %s", dir, config.Get().ActiveModel())
+			return
+		}
+		ui.Info("%s 使用固定模型: %s", dir, ui.Bold(pin.Model))
+		if pin.Directory != dir {
+			ui.Dim("继承自 %s", pin.Directory)
+		}
+
+	default:
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			ui.Error("目录不存在: %s", dir)
+			return
+		}
+		if err := db.PinModel(dir, args[1]); err != nil {
+			ui.Error("固定失败: %v", err)
+			return
+		}
+		ui.Success("整理 %s 时将始终使用 %s", dir, ui.Bold(args[1]))
+		ui.Dim("使用 filo pin-model %s --rm 取消", args[0])
+	}
+}
+
+// listModelPins 列出所有固定的模型
+func listModelPins(db *storage.Database) {
+	pins, err := db.GetModelPins()
+	if err != nil {
+		ui.Error("读取固定模型失败: %v", err)
+		return
+	}
+	if len(pins) == 0 {
+		ui.Info("没有固定模型的目录，所有目录使用默认模型 %s", config.Get().ActiveModel())
+		ui.Dim("使用 filo pin-model <目录> <模型> 固定")
+		return
+	}
+
+	ui.Title("📌", fmt.Sprintf("固定模型 (%d 个目录)", len(pins)))
+	ui.Divider()
+	for _, p := range pins {
+		fmt.Printf("  %s  %s %s\n", ui.Bold(p.Model), p.Directory, ui.Gray(p.CreatedAt.Local().Format("2006-01-02")))
+	}
+}
+
+// applyModel 确定本次运行使用的模型
+// -m 指定的模型优先，其次是固定到该目录（或上级目录）的模型，都没有时使用默认模型
+//
+// 参数:
+//   - cfg: 全局配置
+//   - dir: 要整理的目录
+//
+// 返回值:
+//   - bool: 是否使用了指定或固定的模型
+func applyModel(cfg *config.Config, dir string) bool {
+	if model != "" {
+		cfg.SetModel(model)
+		return true
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	db, err := storage.NewDatabase()
+	if err != nil {
+		return false
+	}
+	defer db.Close()
+
+	pin := db.GetPinnedModel(abs)
+	if pin == nil {
+		return false
+	}
+	cfg.SetModel(pin.Model)
+	ui.Info("使用固定模型: %s（filo pin-model %s）", ui.Bold(pin.Model), pin.Directory)
+	return true
+}
@@ -0,0 +1,124 @@
+// Package cmd 命令行入口模块
+// purge 命令：永久删除回收站中已到期的软删除文件
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/config"
+	"filo/internal/organizer"
+	"filo/internal/ui"
+)
+
+// purgeAll 不论是否到期，清空回收站
+var purgeAll bool
+
+// purgeCmd 清理回收站命令定义
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "永久删除回收站中已到期的文件",
+	Long: `启用软删除（配置 soft_delete_days）后，整理中要删除的文件（conflict_strategy 为
+overwrite-identical 时与已有文件内容相同的重复文件）先移入 ~/.filo/trash/<批次ID>/，
+保留 soft_delete_days 天。purge 永久删除已到期的批次，未到期的保留并列出。
+
+到期前撤销整理会把文件从回收站放回原处；删除后撤销改为从保留的已有文件复制一份回去。
+可以和整理一起放进 cron 定期运行。
+
+示例:
+  filo purge                 # 删除已到期的批次
+  filo purge --dry-run       # 只列出回收站中的批次，不删除
+  filo purge --all           # 清空回收站，包括未到期的批次`,
+	Args: cobra.NoArgs,
+	Run:  runPurge,
+}
+
+// init 注册 purge 子命令及其标志
+func init() {
+	purgeCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "只列出回收站中的批次，不删除")
+	purgeCmd.Flags().BoolVar(&purgeAll, "all", false, "清空回收站，包括未到期的批次")
+	rootCmd.AddCommand(purgeCmd)
+}
+
+// runPurge 执行清理回收站命令
+func runPurge(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	// 等待正在进行的整理和撤销结束，它们可能正在向回收站移入或从中移回文件
+	if !dryRun {
+		l, err := acquireLock()
+		if err != nil {
+			return
+		}
+		defer l.Release()
+	}
+
+	batches, err := organizer.TrashBatches()
+	if err != nil {
+		ui.Error("读取回收站失败: %v", err)
+		return
+	}
+	if len(batches) == 0 {
+		ui.Info("回收站是空的")
+		if config.Get().SoftDeleteDays <= 0 {
+			ui.Dim("软删除未启用，重复文件直接删除；启用: filo config set soft_delete_days 30")
+		}
+		return
+	}
+
+	now := time.Now()
+	var expired []organizer.TrashBatch
+	var files int
+	var size int64
+	ui.Title("🗑️", fmt.Sprintf("回收站: %s", organizer.TrashDir()))
+	ui.Divider()
+	fmt.Printf("  %s %4s %8s  %s\n", padRight("批次", 24), "文件", "大小", "到期")
+	ui.Divider()
+	for _, b := range batches {
+		due := b.Expires.Local().Format("2006-01-02 15:04")
+		if b.Expired(now) {
+			due = ui.Yellow(due + " 已到期")
+		} else {
+			due += ui.Gray(fmt.Sprintf("（%d 天后）", int(b.Expires.Sub(now).Hours()/24)+1))
+		}
+		fmt.Printf("  %s %6d %10s  %s\n", padRight(b.BatchID, 24), b.Files, ui.FormatSize(b.Size), due)
+		if purgeAll || b.Expired(now) {
+			expired = append(expired, b)
+			files += b.Files
+			size += b.Size
+		}
+	}
+	fmt.Println()
+
+	if len(expired) == 0 {
+		ui.Info("没有到期的批次")
+		return
+	}
+	if dryRun {
+		ui.Info("预览模式: 将永久删除 %d 个批次的 %d 个文件（%s）", len(expired), files, ui.FormatSize(size))
+		return
+	}
+	if purgeAll && !ui.ConfirmDanger(fmt.Sprintf("永久删除回收站中的 %d 个文件（%s），包括未到期的?", files, ui.FormatSize(size))) {
+		return
+	}
+
+	var purged int
+	for _, b := range expired {
+		if err := organizer.PurgeTrash(b); err != nil {
+			ui.Error("删除 %s 失败: %v", b.BatchID, err)
+			continue
+		}
+		purged++
+	}
+	if purged == len(expired) {
+		ui.Success("已永久删除 %d 个批次的 %d 个文件（%s）", purged, files, ui.FormatSize(size))
+	} else {
+		ui.Warning("已永久删除 %d/%d 个批次", purged, len(expired))
+	}
+}
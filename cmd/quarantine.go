@@ -0,0 +1,94 @@
+// Package cmd 命令行入口模块
+// quarantine 命令：查看隔离记录，把可信文件的哈希加入白名单
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/quarantine"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// quarantineCmd 隔离记录命令定义
+var quarantineCmd = &cobra.Command{
+	Use:   "quarantine",
+	Short: "查看隔离记录和管理哈希白名单",
+	Long: `隔离模式（--quarantine 或配置 quarantine: true）下，可执行文件、脚本和安装包
+（.exe .dmg .pkg .msi .sh 等）归入 隔离区/，并记录 SHA-256。
+
+哈希在白名单中的文件视为可信，照常分类。白名单默认为 ~/.filo/allowlist.txt，
+每行一个 SHA-256，也可以直接使用 sha256sum 的输出。
+
+示例:
+  filo quarantine                          # 查看最近的隔离记录
+  filo quarantine --allow 隔离区/安装包/app.dmg  # 信任该文件，之后同样的文件照常分类`,
+	Args: cobra.NoArgs,
+	Run:  runQuarantine,
+}
+
+// quarantine 命令行参数
+var (
+	allowFile string // 加入白名单的文件
+)
+
+func init() {
+	rootCmd.AddCommand(quarantineCmd)
+	quarantineCmd.Flags().StringVar(&allowFile, "allow", "", "将文件的 SHA-256 加入白名单")
+}
+
+// runQuarantine 执行隔离记录命令
+func runQuarantine(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	if allowFile != "" {
+		hash, err := quarantine.Hash(allowFile)
+		if err != nil {
+			ui.Error("无法计算哈希: %v", err)
+			return
+		}
+		if err := quarantine.Allow(hash, filepath.Base(allowFile)); err != nil {
+			ui.Error("写入白名单失败: %v", err)
+			return
+		}
+		ui.Success("已加入白名单: %s", filepath.Base(allowFile))
+		ui.Dim("SHA-256 %s", hash)
+		ui.Dim("白名单: %s", quarantine.AllowlistPath())
+		return
+	}
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	records, err := db.GetQuarantineRecords(50)
+	if err != nil {
+		ui.Error("读取隔离记录失败: %v", err)
+		return
+	}
+	if len(records) == 0 {
+		ui.Success("没有隔离记录")
+		return
+	}
+
+	ui.Title("🔒", fmt.Sprintf("隔离记录（最近 %d 个）", len(records)))
+	for _, r := range records {
+		fmt.Println()
+		fmt.Printf("  %s %s %s\n", ui.Yellow("🔒"), ui.Bold(r.Filename), ui.Gray(fmt.Sprintf("(%s · %s)", r.Kind, ui.FormatSize(r.Size))))
+		ui.Dim("   SHA-256 %s", r.SHA256)
+		ui.Dim("   %s → %s", r.SourcePath, r.DestPath)
+		ui.Dim("   %s · 批次 %s", r.CreatedAt.Local().Format("2006-01-02 15:04"), r.BatchID)
+	}
+	fmt.Println()
+	ui.Dim("确认可信的文件可运行 'filo quarantine --allow <文件>' 加入白名单")
+}
@@ -41,6 +41,12 @@ func init() {
 func runReset(cmd *cobra.Command, args []string) {
 	ui.Banner()
 
+	l, err := acquireLock()
+	if err != nil {
+		return
+	}
+	defer l.Release()
+
 	// 连接数据库
 	db, err := storage.NewDatabase()
 	if err != nil {
@@ -48,6 +54,9 @@ func runReset(cmd *cobra.Command, args []string) {
 		return
 	}
 	defer db.Close()
+	if db.Shared() {
+		ui.Warning("学习记录保存在团队共享数据库中，重置会影响所有成员")
+	}
 
 	// 重置所有数据
 	if resetAll {
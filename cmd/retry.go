@@ -0,0 +1,164 @@
+// Package cmd 命令行入口模块
+// retry 命令：重试批次中移动失败的文件
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/organizer"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// retryCmd 重试命令定义
+var retryCmd = &cobra.Command{
+	Use:   "retry [批次ID]",
+	Short: "重试移动失败的文件",
+	Long: `重新移动批次中失败的文件（文件被占用、没有权限等），其余文件不受影响。
+
+整理时移动失败的文件会连同失败原因记录在操作日志中。解除占用或修正权限后，
+retry 按当时的分类把这些文件移入原来的分类文件夹，不重新分类；
+成功的文件与批次中的其他文件一起，可以用 filo undo 撤销。
+
+不指定批次ID时，重试最近一个有失败文件的批次。
+关闭了 cross_device_copy 时，目标在另一个磁盘上的文件无法直接移动，
+加 --copy 改为复制校验后删除源文件。
+
+示例:
+  filo retry                       # 重试最近一次失败的文件
+  filo retry 20240115_143022       # 重试指定批次
+  filo retry --copy                # 跨磁盘的文件复制校验后删除源文件`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeBatchIDs,
+	Run:               runRetry,
+}
+
+// retry 命令行参数
+var retryCopy bool // 跨设备时复制后删除源文件
+
+func init() {
+	// 注册 retry 子命令
+	rootCmd.AddCommand(retryCmd)
+
+	// 注册命令行标志
+	retryCmd.Flags().BoolVar(&retryCopy, "copy", false, "无法直接移动到另一个磁盘时，复制校验后删除源文件（未开启 cross_device_copy 时）")
+	retryCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "详细输出")
+}
+
+// runRetry 执行重试命令
+func runRetry(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	l, err := acquireLock()
+	if err != nil {
+		return
+	}
+	defer l.Release()
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	// 确定要重试的批次
+	var batchID string
+	if len(args) > 0 {
+		batchID = args[0]
+	} else if batchID = db.GetLatestFailedBatch(); batchID == "" {
+		ui.Success("没有移动失败的文件")
+		return
+	}
+
+	logs, err := db.GetFailedLogs(batchID)
+	if err != nil {
+		ui.Error("读取批次失败: %v", err)
+		return
+	}
+	if len(logs) == 0 {
+		ui.Warning("批次 %s 中没有移动失败的文件（可能已重试成功或已撤销）", batchID)
+		return
+	}
+
+	// 批次的目标目录和整理的目录，没有运行摘要时按记录的目标路径移动
+	var targetDir, sourceDir string
+	if run, err := db.GetRun(batchID); err == nil && run != nil {
+		// 原子执行失败的批次已整体回滚，只重试失败的文件会破坏整批的一致性
+		if run.Aborted != "" {
+			ui.Warning("批次 %s 未执行或已回滚（%s），请重新整理", batchID, run.Aborted)
+			return
+		}
+		targetDir, sourceDir = run.TargetDir, run.SourceDir
+	}
+
+	ui.Title("🔁", fmt.Sprintf("重试失败的文件: %s", batchID))
+	fmt.Println()
+	ui.Info("将重试 %d 个文件:", len(logs))
+	fmt.Println()
+	previewRetry(logs)
+
+	if !ui.Confirm("确认重试?", true) {
+		ui.Warning("已取消")
+		return
+	}
+
+	ui.Title("🚀", "执行重试")
+	result := organizer.Retry(db, logs, targetDir, sourceDir, retryCopy, verbose)
+	printRetryResult(result)
+}
+
+// previewRetry 显示将要重试的文件及上次失败的原因
+func previewRetry(logs []storage.OperationLog) {
+	for i, log := range logs {
+		if i >= organizer.MaxDisplayFiles {
+			ui.Dim("  ... 还有 %d 个文件", len(logs)-organizer.MaxDisplayFiles)
+			break
+		}
+		fmt.Printf("  %s %s\n", ui.Green("→"), log.Filename)
+		ui.Dim("    从: %s", log.SourcePath)
+		ui.Dim("    到: %s", log.DestPath)
+		if log.Error != "" {
+			ui.Dim("    上次失败: %s", log.Error)
+		}
+	}
+	fmt.Println()
+}
+
+// printRetryResult 显示重试结果
+func printRetryResult(result organizer.RetryResult) {
+	fmt.Println()
+	ui.Success("成功: %d 个文件", result.Success)
+	if len(result.Skipped) > 0 {
+		ui.Warning("跳过: %d 个文件（目标已有同名文件，留在原处）", len(result.Skipped))
+	}
+	if len(result.Missing) > 0 {
+		ui.Warning("源文件已不存在: %d 个文件", len(result.Missing))
+		for i, path := range result.Missing {
+			if i >= organizer.MaxDisplayFiles {
+				ui.Dim("  ... 还有 %d 个文件", len(result.Missing)-organizer.MaxDisplayFiles)
+				break
+			}
+			ui.Dim("  %s", path)
+		}
+	}
+	if result.Errors > 0 {
+		ui.Error("仍然失败: %d 个文件", result.Errors)
+		for i, msg := range result.Failures {
+			if i >= organizer.MaxDisplayFiles {
+				ui.Dim("  ... 还有 %d 个文件", len(result.Failures)-organizer.MaxDisplayFiles)
+				break
+			}
+			ui.Dim("  - %s", msg)
+		}
+		if result.CrossDevice > 0 {
+			ui.Info("%d 个文件的目标在另一个磁盘上，可用 'filo retry --copy' 复制校验后删除源文件", result.CrossDevice)
+		}
+	}
+}
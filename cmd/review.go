@@ -0,0 +1,230 @@
+// Package cmd 命令行入口模块
+// review 命令：处理待确认队列中的低置信度文件
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/organizer"
+	"filo/internal/preview"
+	"filo/internal/scanner"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// reviewCmd 待确认队列命令定义
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "处理待确认的低置信度文件",
+	Long: `逐个确认低置信度的分类建议，确认后归档到目标目录。
+
+整理时使用 --low-confidence review 或 keep，低置信度的文件，以及记忆与 AI
+给出不同主分类（分歧）的文件会进入待确认队列，队列保存在数据库中，可以在之后任意一次运行时处理。
+每个决定都会反馈给学习：接受计为 AI 分类正确，修改分类会学习纠正结果。
+
+操作:
+  y  接受建议的分类
+  a  采用记忆的建议（仅分歧的文件）
+  c  修改分类
+  s  跳过，下次再处理
+  d  移出队列，文件保持原位
+  q  结束审查
+
+示例:
+  filo review           # 处理待确认的文件
+  filo review --list    # 只查看队列
+  filo review -c 文档   # 只处理建议归入「文档」的文件`,
+	Run: runReview,
+}
+
+// review 命令行参数
+var (
+	reviewList     bool   // 只列出待确认队列
+	reviewCategory string // 只处理指定主分类的建议
+)
+
+func init() {
+	// 注册 review 子命令
+	rootCmd.AddCommand(reviewCmd)
+
+	// 注册命令行标志
+	reviewCmd.Flags().BoolVarP(&reviewList, "list", "l", false, "列出待确认的文件")
+	reviewCmd.Flags().StringVarP(&reviewCategory, "category", "c", "", "只处理建议归入该分类的文件")
+	reviewCmd.RegisterFlagCompletionFunc("category", completeCategories)
+}
+
+// runReview 执行待确认队列审查
+func runReview(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	l, err := acquireLock()
+	if err != nil {
+		return
+	}
+	defer l.Release()
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	items, err := db.GetPendingReviews(1000)
+	if err != nil {
+		ui.Error("读取待确认队列失败: %v", err)
+		return
+	}
+	if reviewCategory != "" {
+		filtered := items[:0]
+		for _, item := range items {
+			if item.Category == reviewCategory {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+	if len(items) == 0 {
+		ui.Success("没有待确认的文件")
+		return
+	}
+
+	if reviewList {
+		listReviewItems(items)
+		return
+	}
+
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		ui.Error("初始化分类器失败: %v", err)
+		return
+	}
+	defer clf.Close()
+
+	ui.Title("❓", fmt.Sprintf("待确认: %d 个文件", len(items)))
+	ui.Warning("逐个审查 (y:接受 a:采用记忆的建议 c:修改 s:跳过 d:移出队列 q:结束)")
+
+	// 按目标目录分组收集已确认的文件
+	accepted := make(map[string][]classifier.Result)
+	pending := make(map[string][]storage.ReviewItem)
+
+	for _, item := range items {
+		// 文件已被用户手动处理
+		f, err := scanner.StatFile(item.FilePath)
+		if err != nil {
+			ui.Dim("已不存在，移出队列: %s", item.Filename)
+			db.ResolveReview(item.ID, "resolved")
+			continue
+		}
+
+		fmt.Println()
+		ui.Info("%s %s", ui.ConfidenceIcon(item.Confidence), ui.Bold(item.Filename))
+		ui.Info("   建议: %s/%s (%.0f%%)", item.Category, item.Subcategory, item.Confidence*100)
+		if item.Reasoning != "" {
+			ui.Dim("   理由: %s", item.Reasoning)
+		}
+		conflict := item.Reason == storage.ReviewConflict
+		if conflict {
+			ui.Info("   %s", ui.Yellow(fmt.Sprintf("分歧: 记忆建议 %s/%s (%.0f%%，%s)",
+				item.AltCategory, item.AltSubcategory, item.AltConfidence*100, item.AltSource)))
+		}
+		ui.Dim("   位置: %s", item.FilePath)
+		preview.Print(f, config.Get().ReviewPreview)
+
+		prompt := "  操作 [y/c/s/d/q]"
+		if conflict {
+			prompt = "  操作 [y/a/c/s/d/q]"
+		}
+		input := strings.ToLower(ui.Input(prompt, "s"))
+		r := classifier.Result{
+			FileInfo:    f,
+			Category:    item.Category,
+			Subcategory: item.Subcategory,
+			Confidence:  item.Confidence,
+			Reasoning:   item.Reasoning,
+			Source:      item.Source,
+		}
+
+		switch input {
+		case "q":
+			goto done
+		case "y":
+			clf.ResolveReview(item.BatchID, r, r.Category, r.Subcategory)
+			r.Confidence = 1.0
+			r.Source = "user"
+		case "a":
+			if !conflict {
+				continue
+			}
+			clf.ResolveReview(item.BatchID, r, item.AltCategory, item.AltSubcategory) // 学习纠正结果
+			r.Category, r.Subcategory = classifier.Normalize(item.AltCategory, item.AltSubcategory)
+			r.Confidence = 1.0
+			r.Source = "user"
+		case "c":
+			newCat := ui.Input("  新主分类", item.Category)
+			newSub := ui.Input("  新子分类（多级用 / 分隔）", item.Subcategory)
+			newCat, newSub = classifier.Normalize(newCat, newSub)
+			clf.ResolveReview(item.BatchID, r, newCat, newSub) // 学习纠正结果
+			r.Category = newCat
+			r.Subcategory = newSub
+			r.Confidence = 1.0
+			r.Source = "user"
+		case "d":
+			db.ResolveReview(item.ID, "dismissed")
+			continue
+		default:
+			continue
+		}
+		accepted[item.TargetDir] = append(accepted[item.TargetDir], r)
+		pending[item.TargetDir] = append(pending[item.TargetDir], item)
+	}
+
+done:
+	if len(accepted) == 0 {
+		fmt.Println()
+		ui.Dim("没有需要归档的文件")
+		return
+	}
+
+	// 按目标目录分别执行整理
+	for dir, results := range accepted {
+		plan := organizer.GeneratePlan(results, dir)
+		organizer.PrintPlan(plan)
+		if !organizer.Confirm("\n确认归档?") {
+			ui.Warning("已取消")
+			continue
+		}
+		organizer.Execute(plan, clf, verbose)
+
+		// 成功移走的文件标记为已处理
+		for _, item := range pending[dir] {
+			if _, err := os.Stat(item.FilePath); os.IsNotExist(err) {
+				db.ResolveReview(item.ID, "resolved")
+			}
+		}
+	}
+}
+
+// listReviewItems 列出待确认队列
+func listReviewItems(items []storage.ReviewItem) {
+	ui.Title("❓", fmt.Sprintf("待确认: %d 个文件", len(items)))
+	for _, item := range items {
+		suggestion := fmt.Sprintf("→ %s/%s? (%.0f%%)", item.Category, item.Subcategory, item.Confidence*100)
+		if item.Reason == storage.ReviewConflict {
+			suggestion += fmt.Sprintf(" 分歧: 记忆建议 %s/%s", item.AltCategory, item.AltSubcategory)
+		}
+		fmt.Printf("  %s %s %s\n", ui.ConfidenceIcon(item.Confidence), item.Filename, ui.Gray(suggestion))
+	}
+	fmt.Println()
+	ui.Dim("运行 'filo review' 逐个确认")
+}
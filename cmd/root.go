@@ -7,16 +7,28 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"filo/internal/audit"
 	"filo/internal/classifier"
 	"filo/internal/config"
+	"filo/internal/guard"
 	"filo/internal/llm"
+	"filo/internal/lock"
+	"filo/internal/metrics"
+	"filo/internal/notify"
+	"filo/internal/ocr"
 	"filo/internal/organizer"
+	"filo/internal/privacy"
 	"filo/internal/scanner"
 	"filo/internal/storage"
 	"filo/internal/ui"
@@ -31,8 +43,41 @@ var (
 	interactive bool   // 交互式审查模式
 	noLearning  bool   // 禁用学习功能
 	recursive   bool   // 递归扫描子目录
+	allowRemote bool   // 允许使用远程 LLM 提供方
+	editPlan    bool   // 使用外部编辑器编辑计划
+	lowConf     string // 低置信度文件处理方式
+	onConflict  string // 重名文件处理策略
+	offline     bool   // 离线模式，不调用 LLM
+	rulesOnly   bool   // 仅规则模式，不做向量检索、不调用 LLM
+	fastRun     bool   // 快速模式，记忆只做内容和规则匹配
+	profileTime bool   // 输出各阶段耗时分析
+	force       bool   // 跳过受保护目录检查
+	quietRun    bool   // 静默模式，无人值守运行
+	groupBy     string // 计划显示的分组方式
+	visionRun   bool   // 看图分类文件名不含信息的图片
+	simulate    bool   // 模拟执行，不修改磁盘
+	showTree    bool   // 显示模拟执行后的目录树
+	pipeline    bool   // 流水线模式，边分类边执行
+	quarantined bool   // 隔离可执行文件、脚本和安装包
+	auditRun    bool   // 只读审计模式，只生成报告
+	auditReport string // 审计报告的输出文件
+	indexFile   string // 索引模式：索引文件的输出路径
+	indexFormat string // 索引格式，为空时按扩展名推断
+	linkTree    string // 索引模式：符号链接目录
+	atomicRun   bool   // 原子执行，失败时全部移回原处
+	existingRun bool   // 按已有文件夹整理，只归入目标目录中已有的文件夹
+	metricsAddr string // 常驻运行时单独提供 /metrics 的监听地址
 )
 
+// autoThreshold 只自动执行置信度不低于该值的结果（--auto-threshold），为 0 时不启用
+var autoThreshold float64
+
+// showRedactions 分类后列出发送给远程提供方前隐去的个人信息（--show-redactions）
+var showRedactions bool
+
+// debugLLM 保存每次模型调用的提示词和原始响应（--debug-llm）
+var debugLLM bool
+
 // rootCmd 根命令定义
 // 用于整理指定目录中的文件
 var rootCmd = &cobra.Command{
@@ -55,8 +100,22 @@ var rootCmd = &cobra.Command{
 示例:
   filo ~/Downloads              # 整理下载文件夹
   filo ~/Downloads -n           # 预览模式
+  filo /Volumes/团队盘 -r --audit --report 审计.md  # 只读审计共享目录
+  filo /Volumes/NAS/资料 -r --index 索引.csv --link-tree ~/资料分类  # 只写索引和符号链接，不移动文件
+  filo ~/Downloads --simulate --tree  # 模拟执行，查看整理后的目录树
   filo ~/Downloads -r           # 递归整理子目录
+  filo ~/Projects -r -n --group-by source  # 按原所在目录核对计划
   filo ~/Downloads -i           # 交互式审查
+  filo ~/Downloads -e           # 在编辑器中修改计划
+  filo ~/Downloads --offline    # 离线模式（不需要 Ollama）
+  filo ~/Downloads --rules-only # 只按已有规则整理，结果可复现
+  filo /Volumes/NAS -r --fast   # 文件很多时跳过向量和历史匹配，加大批大小
+  filo ~/Downloads --low-confidence review  # 低置信度文件待确认
+  filo ~/Downloads --auto-threshold 0.9     # 只自动执行有把握的结果，其余留在原处待确认
+  filo ~/Downloads --on-conflict keep-newest  # 重名时保留较新的文件
+  filo ~/Downloads -q           # 静默执行，结束后发送通知（适合定时任务）
+  filo ~/Inbox -t '~/Archive/{source_dir_name}/{year}'  # 目标目录按源目录名和年份展开
+  filo review                   # 处理待确认的文件
   filo setup                    # 安装向导
   filo stats                    # 查看学习统计
   filo config                   # 查看/修改配置
@@ -68,13 +127,279 @@ var rootCmd = &cobra.Command{
 // init 初始化命令行参数
 func init() {
 	// 注册命令行标志
-	rootCmd.Flags().StringVarP(&targetDir, "target", "t", "", "目标目录")
+	rootCmd.Flags().StringVarP(&targetDir, "target", "t", "", "目标目录（默认为配置 target_dir 或 <目录>/已整理，可以包含 {source_dir_name}、{year} 等占位符）")
 	rootCmd.Flags().StringVarP(&model, "model", "m", "", "使用的模型")
 	rootCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "预览模式")
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "详细输出")
 	rootCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "交互式审查")
 	rootCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "递归扫描子目录")
+	addScanFlags(rootCmd)
 	rootCmd.Flags().BoolVar(&noLearning, "no-learning", false, "禁用学习")
+	rootCmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "允许使用配置的远程 LLM 提供方")
+	rootCmd.Flags().BoolVar(&showRedactions, "show-redactions", false, "列出发送给远程提供方前从文件名中隐去的个人信息")
+	rootCmd.Flags().BoolVar(&debugLLM, "debug-llm", false, "把每次模型调用的提示词和原始响应保存到 ~/.filo/debug/<批次ID>/")
+	rootCmd.Flags().BoolVarP(&editPlan, "edit", "e", false, "在编辑器中修改整理计划")
+	rootCmd.Flags().StringVar(&lowConf, "low-confidence", "", "低置信度文件处理方式: file/review/keep")
+	rootCmd.Flags().Float64Var(&autoThreshold, "auto-threshold", 0, "只自动执行置信度不低于该值（如 0.9）的结果，其余留在原处等待确认")
+	rootCmd.Flags().StringVar(&onConflict, "on-conflict", "", "重名文件处理策略: suffix/overwrite-identical/keep-newest/timestamp/skip")
+	rootCmd.Flags().BoolVar(&atomicRun, "atomic", false, "原子执行：任何文件移动失败时把已移动的文件全部移回原处")
+	rootCmd.Flags().BoolVar(&existingRun, "existing-folders", false, "按已有文件夹整理：只归入目标目录中已有的文件夹，不新建分类")
+	rootCmd.Flags().BoolVar(&offline, "offline", false, "离线模式，只用学习记忆和扩展名分类")
+	rootCmd.Flags().BoolVar(&rulesOnly, "rules-only", false, "仅规则模式：只按关键词、扩展名和手动规则分类，结果可复现，未匹配的文件保持原位")
+	rootCmd.Flags().BoolVar(&fastRun, "fast", false, "快速模式：记忆只做内容和规则匹配，不检索向量和历史，加大批大小（--fast=false 不自动启用）")
+	rootCmd.Flags().BoolVar(&profileTime, "profile-timing", false, "输出扫描、记忆查询、AI 分类各阶段耗时")
+	rootCmd.Flags().BoolVar(&force, "force", false, "允许整理受保护的目录（系统目录、主目录等）")
+	rootCmd.Flags().StringVar(&groupBy, "group-by", organizer.GroupByCategory, "计划显示的分组方式: category/source")
+	rootCmd.Flags().BoolVar(&visionRun, "vision", false, "用本地多模态模型看图分类 IMG_xxxx、截图等文件名不含信息的图片")
+	rootCmd.Flags().BoolVar(&simulate, "simulate", false, "模拟执行：在内存中执行计划，显示新建文件夹和重名改名，不修改磁盘")
+	rootCmd.Flags().BoolVar(&showTree, "tree", false, "显示模拟执行后的目录树（隐含 --simulate）")
+	rootCmd.Flags().BoolVar(&quarantined, "quarantine", false, "隔离模式：可执行文件、脚本和安装包归入 隔离区/ 并记录 SHA-256")
+	rootCmd.Flags().BoolVar(&pipeline, "pipeline", false, "流水线模式：记忆命中的文件在 AI 分类进行时就开始移动，不显示整理计划")
+	rootCmd.Flags().BoolVar(&auditRun, "audit", false, "只读审计：显示整理计划和异常文件（重复、大文件、陈旧、扩展名不符），不执行任何操作")
+	rootCmd.Flags().StringVar(&auditReport, "report", "", "将审计报告写入 Markdown 文件（配合 --audit）")
+	rootCmd.Flags().StringVar(&indexFile, "index", "", "索引模式：不移动文件，将每个文件的建议分类写入索引（.csv/.json/.db）")
+	rootCmd.Flags().StringVar(&indexFormat, "index-format", "", "索引格式: csv/json/sqlite（默认按 --index 的扩展名）")
+	rootCmd.Flags().StringVar(&linkTree, "link-tree", "", "索引模式：在该目录下按分类建立指向原文件的符号链接")
+	rootCmd.Flags().BoolVarP(&quietRun, "quiet", "q", false, "静默模式：不确认直接执行，只输出警告和错误，结束后发送通知")
+
+	// 参数动态补全
+	rootCmd.RegisterFlagCompletionFunc("model", completeModels)
+	rootCmd.RegisterFlagCompletionFunc("low-confidence", fixedCompletion("file", "review", "keep"))
+	rootCmd.RegisterFlagCompletionFunc("group-by", fixedCompletion(organizer.GroupByCategory, organizer.GroupBySource))
+	rootCmd.RegisterFlagCompletionFunc("on-conflict", fixedCompletion(organizer.ConflictStrategies...))
+	rootCmd.RegisterFlagCompletionFunc("index-format", fixedCompletion(organizer.IndexFormats...))
+}
+
+// checkPathsSafe 检查源目录和目标目录是否允许整理
+// 受保护目录需要 --force 才能继续；位于云同步目录时给出提示
+func checkPathsSafe(dirs ...string) bool {
+	for _, dir := range dirs {
+		if err := guard.Check(dir); err != nil {
+			if !force {
+				ui.Error("拒绝整理: %v", err)
+				ui.Info("确认无误请添加 --force 参数运行")
+				return false
+			}
+			ui.Warning("已使用 --force: %v", err)
+		}
+		if warning := guard.CloudSyncWarning(dir); warning != "" {
+			ui.Warning("%s", warning)
+		}
+	}
+	return true
+}
+
+// acquireLock 获取进程锁，防止多个 filo 同时整理文件或修改批次
+// 其他进程持有锁时按 lock_timeout 排队等待，超时后输出错误并返回
+func acquireLock() (*lock.Lock, error) {
+	timeout := time.Duration(config.Get().LockTimeout) * time.Second
+	l, err := lock.Acquire(timeout, func(err *lock.LockedError) {
+		ui.Warning("%v，最多等待 %s...", err, timeout)
+	})
+	if err != nil {
+		ui.Error("%v", err)
+		if _, ok := err.(*lock.LockedError); ok {
+			ui.Info("请等待其结束后重试，或在配置中调大 lock_timeout")
+		}
+		return nil, err
+	}
+	return l, nil
+}
+
+// savePlanSnapshot 保存整理计划快照
+func savePlanSnapshot(sourceDir string, plan *organizer.Plan) {
+	absDir, err := filepath.Abs(sourceDir)
+	if err != nil {
+		return
+	}
+	db, err := storage.NewDatabase()
+	if err != nil {
+		return
+	}
+	defer db.Close()
+	db.SavePlanSnapshot(absDir, plan.Entries(absDir))
+}
+
+// writeIndexOutputs 索引模式：写入索引文件、建立符号链接目录，不移动任何文件
+func writeIndexOutputs(plan *organizer.Plan) {
+	if indexFile != "" {
+		if n, err := organizer.WriteIndex(plan, indexFile, indexFormat); err != nil {
+			ui.Error("保存索引失败: %v", err)
+		} else {
+			ui.Success("索引已保存: %s（%d 个文件）", indexFile, n)
+		}
+	}
+	if linkTree != "" {
+		n, errs := organizer.LinkTree(plan, linkTree)
+		ui.Success("已在 %s 建立 %d 个符号链接", linkTree, n)
+		for _, err := range errs {
+			ui.Error("建立符号链接失败: %v", err)
+		}
+		if len(plan.Review) > 0 {
+			ui.Dim("%d 个待确认的文件未建立链接，索引中 review 列为 true", len(plan.Review))
+		}
+	}
+	ui.Warning("索引模式 - 只读，未移动任何文件")
+}
+
+// runPipeline 流水线模式：分类器把结果逐批送入通道，执行端同时移动文件
+// 没有完整的整理计划，执行前确认一次；静默模式不确认
+func runPipeline(sourceDir string, files []scanner.FileInfo, clf *classifier.Classifier) {
+	if !quietRun && !organizer.Confirm("\n流水线模式将边分类边移动文件，确认开始?") {
+		ui.Warning("已取消")
+		return
+	}
+
+	cfg := config.Get()
+	plan := organizer.NewPlan(targetDir)
+	plan.SourceDir, _ = filepath.Abs(sourceDir)
+
+	results := make(chan classifier.Result, organizer.PipelineBuffer)
+	done := make(chan organizer.ExecuteResult)
+	go func() {
+		done <- organizer.ExecuteStream(plan, results, clf, cfg.LowConfidenceAction, confidenceThreshold(cfg), verbose)
+	}()
+
+	err := clf.ClassifyStream(files, verbose, results)
+	result := <-done
+	if autoThreshold > 0 {
+		organizer.PrintAutoSummary(plan, autoThreshold)
+	}
+	if err != nil {
+		ui.Error("分类失败: %v", err)
+	} else if clf.Interrupted() {
+		err = errInterrupted
+		printExecuteInterrupted(sourceDir, result)
+	} else if result.Interrupted > 0 {
+		err = errInterrupted // 执行前检查未通过，部分文件留在原处
+	}
+
+	sendNotification(notify.Summary{
+		Dir:     sourceDir,
+		Success: result.Success,
+		Errors:  result.Errors,
+		Skipped: len(result.Skipped),
+		Review:  len(plan.Review),
+		BatchID: result.BatchID,
+		Err:     err,
+	})
+}
+
+// confidenceThreshold 返回主分类的置信度阈值
+// 指定了 --auto-threshold 时取它与配置阈值中较高的一个，只有足够有把握的结果自动执行
+func confidenceThreshold(cfg *config.Config) func(category string) float64 {
+	if autoThreshold == 0 {
+		return cfg.ConfidenceThresholdFor
+	}
+	return func(category string) float64 {
+		return math.Max(autoThreshold, cfg.ConfidenceThresholdFor(category))
+	}
+}
+
+// sendNotification 静默模式下发送整理摘要
+// 通知失败只给出警告，不影响整理结果
+func sendNotification(summary notify.Summary) {
+	if !quietRun || !notify.Enabled() {
+		return
+	}
+	for _, err := range notify.Send(summary) {
+		ui.Warning("%v", err)
+	}
+}
+
+// printTiming 输出各阶段耗时分析
+func printTiming(scan time.Duration, t classifier.Timing, fileCount int) {
+	total := scan + t.Memory + t.Vision + t.LLM
+	lines := []string{
+		fmt.Sprintf("扫描:     %6.2fs", scan.Seconds()),
+		fmt.Sprintf("记忆查询: %6.2fs", t.Memory.Seconds()),
+		fmt.Sprintf("  规则:   %6.2fs", t.Stages.Rules.Seconds()),
+		fmt.Sprintf("  向量:   %6.2fs", t.Stages.Vectors.Seconds()),
+		fmt.Sprintf("  历史:   %6.2fs", t.Stages.History.Seconds()),
+	}
+	if t.Vision > 0 {
+		lines = append(lines, fmt.Sprintf("看图分类: %6.2fs", t.Vision.Seconds()))
+	}
+	lines = append(lines,
+		fmt.Sprintf("AI 分类:  %6.2fs", t.LLM.Seconds()),
+		fmt.Sprintf("合计:     %6.2fs", total.Seconds()),
+	)
+	if fileCount > 0 {
+		lines = append(lines, fmt.Sprintf("平均:     %6.0fms/文件", float64(total.Milliseconds())/float64(fileCount)))
+	}
+	ui.Box("⏱️ 耗时分析", lines)
+}
+
+// startTranscript 开启 --debug-llm 时保存本次每次模型调用的提示词和原始响应
+// 返回的函数在命令结束时调用，显示记录所在的目录；没有调用模型时删除空目录
+func startTranscript(clf *classifier.Classifier) func() {
+	if !debugLLM {
+		return func() {}
+	}
+	t, err := clf.DebugLLM()
+	if err != nil {
+		ui.Warning("无法保存模型调用记录: %v", err)
+		return func() {}
+	}
+	return func() {
+		if t.Count() == 0 {
+			os.Remove(t.Dir())
+			ui.Dim("没有调用模型，未保存调用记录")
+			return
+		}
+		ui.Info("模型调用记录: %s（%d 次调用）", t.Dir(), t.Count())
+	}
+}
+
+// printRedactions 列出发送给远程提供方前从文件名中隐去的个人信息
+// 同一文件拆分批次重试时会再次脱敏，相同的记录只列一次
+func printRedactions(redactions []privacy.Redaction) {
+	cfg := config.Get()
+	switch {
+	case !cfg.IsRemoteProvider():
+		ui.Dim("使用本地模型，文件名未发送到第三方，无需脱敏")
+		return
+	case !cfg.RedactFilenames:
+		ui.Warning("已关闭文件名脱敏（redact_filenames: false），文件名原样发送给 %s", cfg.LLMProvider)
+		return
+	case len(redactions) == 0:
+		ui.Dim("文件名中没有发现需要隐去的个人信息")
+		return
+	}
+
+	ui.Title("🕶", "发送前隐去的个人信息")
+	seen := make(map[string]bool)
+	for _, r := range redactions {
+		key := r.File + "\x00" + r.Original
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ui.Info("  %s", r.File)
+		ui.Dim("    %s %s → %s", r.Kind, r.Original, r.Placeholder)
+	}
+	fmt.Println()
+}
+
+// promptETA 预计耗时超过 eta_warn_minutes 时询问：继续、换用更快的模型、开启并行或跳过 AI 分类
+func promptETA(est classifier.Estimate, faster []classifier.Estimate, parallel int) classifier.ETAChoice {
+	ui.Warning("预计耗时%s，超过 %d 分钟（eta_warn_minutes）", classifier.FormatETA(est.Duration), config.Get().ETAWarnMinutes)
+
+	options := []string{fmt.Sprintf("继续使用 %s", est.Model)}
+	choices := []classifier.ETAChoice{{}}
+	for _, f := range faster {
+		options = append(options, fmt.Sprintf("换用 %s（%s，%.0fms/文件）", f.Model, classifier.FormatETA(f.Duration), float64(f.PerFile.Milliseconds())))
+		choices = append(choices, classifier.ETAChoice{Model: f.Model})
+	}
+	if parallel > 0 {
+		d := est.Duration * time.Duration(est.Parallel) / time.Duration(parallel)
+		options = append(options, fmt.Sprintf("同时发送 %d 批（%s，需要模型服务能并行处理）", parallel, classifier.FormatETA(d)))
+		choices = append(choices, classifier.ETAChoice{Parallel: parallel})
+	}
+	options = append(options, "跳过 AI 分类（只整理记忆命中的文件，其余留在原处）")
+	choices = append(choices, classifier.ETAChoice{Skip: true})
+
+	return choices[ui.Choose("  如何继续?", options, 0)]
 }
 
 // Execute 执行根命令
@@ -86,6 +411,124 @@ func Execute() {
 	}
 }
 
+// checkLLMReady 检查 LLM 是否可用
+// 本地模式检查 Ollama 服务和模型；远程模式要求 --allow-remote 显式确认
+func checkLLMReady(client *llm.Client) bool {
+	cfg := config.Get()
+
+	if client.IsRemote() {
+		if !allowRemote {
+			ui.Error("已配置远程提供方 %s，文件名将被发送到第三方服务", client.Provider())
+			ui.Info("确认后请添加 --allow-remote 参数运行")
+			return false
+		}
+		if !client.IsAvailable() {
+			ui.Error("未配置 %s API 密钥", client.Provider())
+			ui.Info("在 ~/.filo/config.json 中设置，或使用环境变量")
+			return false
+		}
+		if cfg.ContentAllowed() {
+			ui.Warning("远程模式: %s/%s（将发送文件名和文本内容片段）", client.Provider(), cfg.ActiveModel())
+		} else {
+			ui.Warning("远程模式: %s/%s（仅发送文件名）", client.Provider(), cfg.ActiveModel())
+		}
+		return true
+	}
+
+	if !client.IsAvailable() {
+		ui.Error("Ollama 服务未运行")
+		ui.Info("请先启动: ollama serve")
+		ui.Info("或运行: filo setup")
+		return false
+	}
+
+	// 检查模型是否已安装
+	if !client.HasModel(cfg.LLMModel) {
+		ui.Error("模型 %s 未安装", cfg.LLMModel)
+		ui.Info("运行 'filo setup' 安装模型")
+		return false
+	}
+	return checkModelFit(client, cfg.LLMModel)
+}
+
+// checkModelFit 检查模型的能力和上下文窗口（/api/show）
+// 模型不能对话（如嵌入模型）时返回 false；一批文件的提示词和 max_tokens 放不进上下文窗口时提示调小，
+// 超出的部分会被 Ollama 截断。旧版本 Ollama 无法读取模型信息时跳过
+func checkModelFit(client *llm.Client, model string) bool {
+	info, err := client.ShowModel(model)
+	if err != nil {
+		return true
+	}
+	if !info.Has(llm.CapCompletion) {
+		ui.Error("模型 %s 不能用于分类（能力: %s）", model, strings.Join(info.Capabilities, ", "))
+		ui.Info("用 -m 换用对话模型，或运行 'filo setup' 重新选择")
+		return false
+	}
+
+	cfg := config.Get()
+	fit := client.EstimateFit(info, cfg.BatchSize)
+	if fit.Fits() {
+		return true
+	}
+	ui.Warning("模型 %s 的上下文窗口为 %d tokens，每批 %d 个文件的提示词约 %d tokens，加上 max_tokens %d 放不下",
+		model, fit.Window, fit.BatchSize, fit.PromptTokens, fit.OutputTokens)
+	if fit.MaxBatch > 0 {
+		ui.Info("超出的部分会被截断，建议将 batch_size 调小到 %d 以内，或调小 max_tokens", fit.MaxBatch)
+	} else {
+		ui.Info("超出的部分会被截断，建议调小 max_tokens，或换用上下文窗口更大的模型")
+	}
+	return true
+}
+
+// checkOCRReady 检查 OCR 引擎是否可用
+// 不可用时给出提示并关闭 OCR，分类照常进行
+func checkOCRReady() {
+	cfg := config.Get()
+	if cfg.OCR == "" || cfg.Offline || cfg.RulesOnly {
+		return
+	}
+	if err := ocr.Check(); err != nil {
+		ui.Warning("OCR 不可用，本次跳过: %v", err)
+		cfg.OCR = ""
+		return
+	}
+	if !cfg.OCRAllowed() {
+		ui.Dim("远程模式未允许发送内容，不识别扫描件文字")
+		return
+	}
+	ui.Dim("OCR: %s（识别扫描件和截图中的文字）", cfg.OCR)
+}
+
+// checkVisionReady 检查看图分类的多模态模型是否可用
+// 不可用时给出提示并关闭看图分类，图片照常按文件名分类
+func checkVisionReady() {
+	cfg := config.Get()
+	if !cfg.VisionClassify || cfg.Offline || cfg.RulesOnly {
+		return
+	}
+	if !llm.NewClient().HasLocalModel(cfg.VisionModel) {
+		ui.Warning("看图分类不可用: 本机 Ollama 未安装 %s，本次按文件名分类", cfg.VisionModel)
+		ui.Info("安装模型: ollama pull %s", cfg.VisionModel)
+		cfg.VisionClassify = false
+		return
+	}
+	ui.Dim("看图分类: %s（图片只发送给本机 Ollama）", cfg.VisionModel)
+}
+
+// serveMetrics 指定了 --metrics 时在后台提供 Prometheus 指标，ctx 取消时关闭
+// 监听失败只提示，不影响主服务
+func serveMetrics(ctx context.Context) {
+	if metricsAddr == "" {
+		return
+	}
+	go func() {
+		if err := metrics.ListenAndServe(ctx, metricsAddr); err != nil {
+			ui.Error("指标服务启动失败: %v", err)
+		}
+	}()
+	ui.Dim("运行指标: http://%s/metrics", metricsAddr)
+}
+
 // runOrganize 执行文件整理的核心逻辑
 // 整体流程：扫描 -> 分类 -> 生成计划 -> 审查（可选）-> 执行
 func runOrganize(cmd *cobra.Command, args []string) {
@@ -97,14 +540,83 @@ func runOrganize(cmd *cobra.Command, args []string) {
 
 	sourceDir := args[0]
 
+	// 模拟执行与预览一样不修改磁盘
+	if showTree {
+		simulate = true
+	}
+	if simulate {
+		dryRun = true
+	}
+
+	// 静默模式：无人值守运行，不能进行交互
+	if quietRun {
+		if interactive || editPlan || simulate {
+			ui.Error("--quiet 不能与 -i / -e / --simulate 同时使用")
+			return
+		}
+		ui.SetQuiet(true)
+	}
+
+	// 审计模式只读，不提供执行
+	if auditRun && (interactive || editPlan || quietRun || pipeline || simulate) {
+		ui.Error("--audit 不能与 -i / -e / -q / --pipeline / --simulate 同时使用")
+		return
+	}
+	if auditReport != "" && !auditRun {
+		ui.Error("--report 需要与 --audit 一起使用")
+		return
+	}
+
+	// 索引模式只读，不提供执行
+	indexRun := indexFile != "" || linkTree != ""
+	if indexRun && (interactive || editPlan || pipeline || simulate || auditRun) {
+		ui.Error("--index / --link-tree 不能与 -i / -e / --pipeline / --simulate / --audit 同时使用")
+		return
+	}
+	if linkTree != "" && targetDir != "" {
+		ui.Error("--link-tree 即目标目录，不能与 -t 同时使用")
+		return
+	}
+	if indexFormat != "" && indexFile == "" {
+		ui.Error("--index-format 需要与 --index 一起使用")
+		return
+	}
+	if indexFile != "" {
+		if indexFormat == "" {
+			indexFormat = organizer.IndexFormatFor(indexFile)
+		}
+		if !organizer.ValidIndexFormat(indexFormat) {
+			ui.Error("无法确定索引格式: %s（用 .csv/.json/.db 扩展名，或 --index-format %s）",
+				indexFile, strings.Join(organizer.IndexFormats, "/"))
+			return
+		}
+	}
+
+	// 流水线模式边分类边执行，没有完整的计划可供审查或预览
+	if pipeline && (interactive || editPlan || dryRun) {
+		ui.Error("--pipeline 不能与 -i / -e / -n / --simulate 同时使用")
+		return
+	}
+	// 流水线模式边分类边移动，无法在移动前检查整批文件，也无法整批回滚
+	if pipeline && atomicRun {
+		ui.Error("--atomic 不能与 --pipeline 同时使用")
+		return
+	}
+
 	// 显示启动横幅
 	ui.Banner()
 
+	// 同一时间只允许一个进程整理，避免批次和学习记录交错
+	l, err := acquireLock()
+	if err != nil {
+		sendNotification(notify.Summary{Dir: sourceDir, Err: err})
+		return
+	}
+	defer l.Release()
+
 	// 更新配置：应用命令行参数
 	cfg := config.Get()
-	if model != "" {
-		cfg.SetModel(model) // 使用指定模型
-	} else {
+	if !applyModel(cfg, sourceDir) { // -m 指定或目录固定的模型
 		// 自适应模型选择：基于历史性能推荐最优模型
 		db, err := storage.NewDatabase()
 		if err == nil {
@@ -115,13 +627,98 @@ func runOrganize(cmd *cobra.Command, args []string) {
 			db.Close()
 		}
 	}
-	if noLearning {
-		cfg.EnableLearning = false // 禁用学习功能
+	if noLearning || auditRun || indexRun {
+		cfg.EnableLearning = false // 禁用学习功能（审计、索引共享目录不影响自己的学习记录）
+	}
+	if offline {
+		cfg.Offline = true // 不调用 LLM
+	}
+	if rulesOnly {
+		cfg.RulesOnly = true // 只按规则分类
+	}
+	if cmd.Flags().Changed("fast") {
+		cfg.Fast = fastRun
+		if !fastRun {
+			cfg.FastModeFiles = 0 // --fast=false: 文件再多也不自动启用
+		}
+	}
+	if visionRun {
+		cfg.VisionClassify = true
+	}
+	if quarantined {
+		cfg.Quarantine = true
+	}
+	if lowConf != "" {
+		switch lowConf {
+		case organizer.LowConfidenceFile, organizer.LowConfidenceReview, organizer.LowConfidenceKeep:
+			cfg.LowConfidenceAction = lowConf
+		default:
+			ui.Error("无效的 --low-confidence 取值: %s（可选 file/review/keep）", lowConf)
+			return
+		}
+	}
+	if autoThreshold != 0 {
+		if autoThreshold < 0 || autoThreshold > 1 {
+			ui.Error("无效的 --auto-threshold 取值: %g（应在 0 到 1 之间，如 0.9）", autoThreshold)
+			return
+		}
+		if lowConf == organizer.LowConfidenceFile {
+			ui.Error("--auto-threshold 不能与 --low-confidence file 同用")
+			return
+		}
+		// 未达到阈值的文件默认留在原处；--low-confidence review 时移入待确认文件夹
+		if cfg.LowConfidenceAction == organizer.LowConfidenceFile {
+			cfg.LowConfidenceAction = organizer.LowConfidenceKeep
+		}
+	}
+
+	if onConflict != "" {
+		if !organizer.ValidConflictStrategy(onConflict) {
+			ui.Error("无效的 --on-conflict 取值: %s（可选 %s）", onConflict, strings.Join(organizer.ConflictStrategies, "/"))
+			return
+		}
+		cfg.ConflictStrategy = onConflict
+	}
+	if atomicRun {
+		cfg.Atomic = true
+	}
+	if existingRun {
+		cfg.ExistingFolders = true
+	}
+	if !applyScanFlags(cmd) {
+		return
+	}
+	if cfg.ExistingFolders && cfg.LowConfidenceAction == organizer.LowConfidenceFile {
+		cfg.LowConfidenceAction = organizer.LowConfidenceReview // 没有对应文件夹的文件不新建文件夹
 	}
 
-	// 设置默认目标目录
-	if targetDir == "" {
-		targetDir = filepath.Join(sourceDir, "已整理")
+	if groupBy != organizer.GroupByCategory && groupBy != organizer.GroupBySource {
+		ui.Error("无效的 --group-by 取值: %s（可选 category/source）", groupBy)
+		return
+	}
+
+	// 设置默认目标目录（索引模式下为符号链接目录）
+	if linkTree != "" {
+		targetDir = linkTree
+	}
+	target, err := organizer.ResolveTarget(targetDir, sourceDir)
+	if err != nil {
+		ui.Error("%v", err)
+		return
+	}
+	if target != targetDir && targetDir != "" {
+		ui.Dim("目标目录: %s", target)
+	}
+	targetDir = target
+	if linkTree != "" {
+		linkTree = targetDir
+		// 符号链接目录在源目录内时，下次扫描会把链接当作文件
+		absSrc, _ := filepath.Abs(sourceDir)
+		absLink, _ := filepath.Abs(linkTree)
+		if rel, err := filepath.Rel(absSrc, absLink); err == nil && !strings.HasPrefix(rel, "..") {
+			ui.Error("--link-tree 不能位于源目录内: %s", linkTree)
+			return
+		}
 	}
 
 	// 检查源目录是否存在
@@ -130,31 +727,31 @@ func runOrganize(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	// 检查 Ollama 服务状态
-	client := llm.NewClient()
-	if !client.IsAvailable() {
-		ui.Error("Ollama 服务未运行")
-		ui.Info("请先启动: ollama serve")
-		ui.Info("或运行: filo setup")
+	// 安全检查：拒绝整理系统目录等危险路径
+	if !checkPathsSafe(sourceDir, targetDir) {
 		return
 	}
 
-	// 检查模型是否已安装
-	if !client.HasModel(cfg.LLMModel) {
-		ui.Error("模型 %s 未安装", cfg.LLMModel)
-		ui.Info("运行 'filo setup' 安装模型")
+	// 检查 LLM 服务状态（离线和仅规则模式不需要）
+	if cfg.RulesOnly {
+		ui.Info("仅规则模式: 只按已有规则分类，没有匹配规则的文件保持原位")
+	} else if cfg.Offline {
+		ui.Warning("离线模式: 只使用学习记忆和扩展名分类，置信度较低")
+	} else if !checkLLMReady(llm.NewClient()) {
+		sendNotification(notify.Summary{Dir: sourceDir, Err: fmt.Errorf("LLM 服务不可用")})
 		return
 	}
+	checkOCRReady()
+	checkVisionReady()
 
 	// ========== 步骤1: 扫描目录 ==========
-	scanMode := "扫描"
-	if recursive {
-		scanMode = "递归扫描"
-	}
-	ui.Title("📂", fmt.Sprintf("%s: %s", scanMode, sourceDir))
+	ui.Title("📂", fmt.Sprintf("%s: %s", scanScope(), sourceDir))
+	scanStart := time.Now()
 	files, err := scanner.ScanDirectory(sourceDir, recursive)
+	scanTime := time.Since(scanStart)
 	if err != nil {
 		ui.Error("扫描失败: %v", err)
+		sendNotification(notify.Summary{Dir: sourceDir, Err: err})
 		return
 	}
 
@@ -179,34 +776,162 @@ func runOrganize(cmd *cobra.Command, args []string) {
 		ui.Error("初始化分类器失败: %v", err)
 		return
 	}
-	defer clf.Close() // 确保分类器资源被释放
+	defer clf.Close()           // 确保分类器资源被释放
+	defer watchInterrupt(clf)() // Ctrl-C 时保存已完成的部分
+	defer startTranscript(clf)()
+	if !quietRun {
+		clf.SetETAPrompt(promptETA) // 预计耗时过长时询问
+	}
+
+	// 按已有文件夹整理：目标目录中的文件夹作为唯一可选的分类
+	if cfg.ExistingFolders {
+		folders, err := organizer.ExistingFolders(targetDir)
+		if err == nil && len(folders) == 0 {
+			err = errors.New("没有文件夹")
+		}
+		if err != nil {
+			ui.Error("无法按已有文件夹整理 %s: %v", targetDir, err)
+			return
+		}
+		ui.Info("按已有文件夹整理: %d 个文件夹", len(folders))
+		clf.UseExistingFolders(folders)
+	}
+
+	// 流水线模式：分类与执行同时进行
+	if pipeline {
+		runPipeline(sourceDir, files, clf)
+		if profileTime {
+			printTiming(scanTime, clf.GetTiming(), fileCount)
+		}
+		if showRedactions {
+			printRedactions(clf.Redactions())
+		}
+		return
+	}
 
 	// 执行分类
 	results, err := clf.Classify(files, verbose)
 	if err != nil {
 		ui.Error("分类失败: %v", err)
+		sendNotification(notify.Summary{Dir: sourceDir, Err: err})
+		return
+	}
+	if profileTime {
+		printTiming(scanTime, clf.GetTiming(), fileCount)
+	}
+	if showRedactions {
+		printRedactions(clf.Redactions())
+	}
+	if clf.Interrupted() {
+		printClassifyInterrupted(sourceDir, len(results), fileCount)
+		sendNotification(notify.Summary{Dir: sourceDir, Err: errInterrupted})
 		return
 	}
 
 	// ========== 步骤3: 生成整理计划 ==========
 	plan := organizer.GeneratePlan(results, targetDir)
-	organizer.PrintPlan(plan)
+	plan.SourceDir, _ = filepath.Abs(sourceDir)
+
+	action := cfg.LowConfidenceAction
+	// 不审查时直接分出低置信度文件；审查时留给用户先确认
+	if !interactive && !editPlan {
+		organizer.ParkForReview(plan, action, confidenceThreshold(cfg))
+	}
+	organizer.PrintPlanBy(plan, groupBy)
+
+	// 审计模式：只输出报告，不提供执行
+	if auditRun {
+		report := audit.Analyze(files, plan)
+		audit.Print(report)
+		if auditReport != "" {
+			if err := audit.WriteMarkdown(report, auditReport); err != nil {
+				ui.Error("保存审计报告失败: %v", err)
+			} else {
+				ui.Success("审计报告已保存: %s", auditReport)
+			}
+		}
+		ui.Warning("审计模式 - 只读，未执行任何操作")
+		return
+	}
+
+	// 索引模式：只写索引和符号链接，不移动任何文件
+	if indexRun {
+		writeIndexOutputs(plan)
+		return
+	}
 
 	// ========== 步骤4: 交互式审查（可选）==========
 	if interactive {
 		plan = organizer.InteractiveReview(plan, clf)
-		organizer.PrintPlan(plan) // 显示修改后的计划
+		organizer.PrintPlanBy(plan, groupBy) // 显示修改后的计划
+	}
+
+	// 在外部编辑器中编辑计划（可选）
+	if editPlan {
+		edited, err := organizer.EditPlan(plan, clf)
+		if err != nil {
+			ui.Error("编辑计划失败: %v", err)
+			ui.Warning("已取消")
+			return
+		}
+		plan = edited
+		organizer.PrintPlanBy(plan, groupBy)
+	}
+
+	// 审查后仍未确认的低置信度文件进入待确认队列
+	if interactive || editPlan {
+		organizer.ParkForReview(plan, action, confidenceThreshold(cfg))
+		if len(plan.Review) > 0 {
+			organizer.PrintPlanBy(plan, groupBy)
+		}
+	}
+	// 逐个选择重名文件的处理方式
+	if interactive {
+		organizer.ReviewCollisions(plan)
+	}
+	if autoThreshold > 0 {
+		organizer.PrintAutoSummary(plan, autoThreshold)
 	}
 
 	// ========== 步骤5: 执行整理 ==========
-	if dryRun {
-		// 预览模式：只显示计划，不执行
+	if simulate {
+		// 模拟模式：在虚拟文件系统上执行，显示最终结构
+		sim := organizer.Simulate(plan)
+		if showTree {
+			organizer.PrintTree(sim)
+		}
+		organizer.PrintSimulation(sim)
+		savePlanSnapshot(sourceDir, plan)
+		ui.Warning("模拟模式 - 未执行实际操作")
+		ui.Dim("去掉 --simulate 参数执行实际整理")
+	} else if dryRun {
+		// 预览模式：只显示计划，不执行；保存快照供 filo diff 对比
+		savePlanSnapshot(sourceDir, plan)
 		ui.Warning("预览模式 - 未执行实际操作")
 		ui.Dim("去掉 -n 参数执行实际整理")
+	} else if quietRun {
+		// 静默模式：直接执行并发送摘要
+		result := organizer.Execute(plan, clf, verbose)
+		summary := notify.Summary{
+			Dir:     sourceDir,
+			Success: result.Success,
+			Errors:  result.Errors,
+			Skipped: len(result.Skipped),
+			Review:  len(plan.Review),
+			BatchID: result.BatchID,
+		}
+		if result.Aborted != "" {
+			summary.Err = errors.New(result.Aborted)
+		} else if result.Interrupted > 0 {
+			summary.Err = errInterrupted
+		}
+		sendNotification(summary)
 	} else {
 		// 确认后执行
 		if organizer.Confirm("\n确认执行整理?") {
-			organizer.Execute(plan, clf, verbose)
+			if result := organizer.Execute(plan, clf, verbose); result.Interrupted > 0 {
+				printExecuteInterrupted(sourceDir, result)
+			}
 		} else {
 			ui.Warning("已取消")
 		}
@@ -0,0 +1,379 @@
+// Package cmd 命令行入口模块
+// rules 命令：查看、手动添加和删除分类规则，复查因撤销和纠正被降级或停用的规则
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/memory"
+	"filo/internal/scanner"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// rulesCmd 规则管理命令定义
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "规则管理",
+	Long: `查看学习到的分类规则，或手动添加精确的规则。
+
+规则类型:
+  keyword     文件名包含关键词
+  extension   扩展名
+  parent_dir  来源目录名
+  regex       正则表达式（匹配完整文件名，不区分大小写）
+  glob        通配符 * ? [...]（匹配完整文件名，不区分大小写）
+  language    文件名的主要语言: zh 中文、en 英文、ja 日文、ko 韩文、cyrillic 西里尔文，
+              可用 --ext 限定扩展名
+  origin      下载来源的域名（浏览器下载的网址、邮件附件的发件人），同时匹配子域名，
+              匹配时优先于其他规则和记忆
+
+过于宽泛的关键词规则（停用词、包含该词的文件分散在多个分类）会匹配大量无关文件，
+可用 --suspicious 列出后删除；配置 stop_words 可追加不再学习的停用词。
+
+撤销整理和纠正分类视为对规则的否定：把文件分错的规则记一次负反馈，
+学习到的规则多次出错后先降低优先级，再出错则停用；手动添加的规则只记录不调整。
+用 --review 查看这些规则，确认规则没问题时用 restore 恢复。
+
+示例:
+  filo rules                                          # 列出所有规则
+  filo rules --type regex                             # 只看正则规则
+  filo rules --suspicious                             # 列出过于宽泛的关键词规则
+  filo rules --review                                 # 复查被撤销、纠正过的规则
+  filo rules add --regex '^IMG_\d+' --category 图片/照片
+  filo rules add --glob '*发票*.pdf' --category 财务/发票
+  filo rules add --keyword 周报 --category 工作/周报
+  filo rules add --lang ja --ext .mkv,.mp4 --category 视频/动漫  # 日文命名的视频
+  filo rules add --origin github.com --category 代码/下载       # 从 GitHub 下载的文件
+  filo rules rm 42                                    # 删除规则
+  filo rules restore 42                               # 恢复被降级或停用的规则`,
+	Run: runRulesList,
+}
+
+// rulesAddCmd 添加规则子命令
+var rulesAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "手动添加规则",
+	Run:   runRulesAdd,
+}
+
+// rulesRmCmd 删除规则子命令
+var rulesRmCmd = &cobra.Command{
+	Use:   "rm <规则ID>",
+	Short: "删除规则",
+	Args:  cobra.ExactArgs(1),
+	Run:   runRulesRm,
+}
+
+// rulesRestoreCmd 恢复规则子命令
+var rulesRestoreCmd = &cobra.Command{
+	Use:   "restore <规则ID>",
+	Short: "恢复被降级或停用的规则，清除其负反馈",
+	Args:  cobra.ExactArgs(1),
+	Run:   runRulesRestore,
+}
+
+// rules 命令行参数
+var (
+	rulesType       string // 列表过滤的规则类型
+	rulesSuspicious bool   // 只列出过于宽泛的关键词规则
+	rulesReview     bool   // 只列出有负反馈的规则
+	ruleRegex       string // 正则模式
+	ruleGlob        string // 通配符模式
+	ruleKeyword     string // 关键词模式
+	ruleExt         string // 扩展名模式（与 --lang 同用时为限定的扩展名列表）
+	ruleLang        string // 文件名语言
+	ruleOrigin      string // 下载来源的域名
+	ruleCategory    string // 目标分类（主分类/子分类）
+	rulePriority    int    // 规则优先级
+)
+
+// init 注册 rules 子命令
+func init() {
+	rulesCmd.Flags().StringVar(&rulesType, "type", "", "只显示指定类型的规则")
+	rulesCmd.RegisterFlagCompletionFunc("type", fixedCompletion("keyword", "extension", "parent_dir", "regex", "glob", storage.PatternLanguage, storage.PatternOrigin))
+	rulesCmd.Flags().BoolVar(&rulesSuspicious, "suspicious", false, "列出过于宽泛的关键词规则")
+	rulesCmd.Flags().BoolVar(&rulesReview, "review", false, "列出被撤销、纠正过的规则及降级、停用状态")
+
+	rulesAddCmd.Flags().StringVar(&ruleRegex, "regex", "", "正则表达式")
+	rulesAddCmd.Flags().StringVar(&ruleGlob, "glob", "", "通配符模式")
+	rulesAddCmd.Flags().StringVar(&ruleKeyword, "keyword", "", "关键词")
+	rulesAddCmd.Flags().StringVar(&ruleExt, "ext", "", "扩展名（如 .pdf）；与 --lang 同用时限定扩展名，多个用逗号分隔")
+	rulesAddCmd.Flags().StringVar(&ruleLang, "lang", "", "文件名语言（zh/en/ja/ko/cyrillic）")
+	rulesAddCmd.RegisterFlagCompletionFunc("lang", fixedCompletion(scanner.Languages...))
+	rulesAddCmd.Flags().StringVar(&ruleOrigin, "origin", "", "下载来源的域名（如 github.com，同时匹配子域名）")
+	rulesAddCmd.Flags().StringVar(&ruleCategory, "category", "", "目标分类，格式: 主分类/子分类，可有多级（如 工作/客户A/合同）")
+	rulesAddCmd.Flags().IntVar(&rulePriority, "priority", 30, "规则优先级（学习规则为 10-20）")
+	rulesAddCmd.MarkFlagRequired("category")
+	rulesAddCmd.RegisterFlagCompletionFunc("category", completeCategories)
+
+	rulesCmd.AddCommand(rulesAddCmd)
+	rulesCmd.AddCommand(rulesRmCmd)
+	rulesCmd.AddCommand(rulesRestoreCmd)
+	rootCmd.AddCommand(rulesCmd)
+}
+
+// runRulesList 列出规则
+func runRulesList(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	if rulesSuspicious {
+		listSuspiciousRules(db)
+		return
+	}
+	if rulesReview {
+		listPenalizedRules(db)
+		return
+	}
+
+	rules, err := db.GetRules(rulesType)
+	if err != nil {
+		ui.Error("读取规则失败: %v", err)
+		return
+	}
+	if len(rules) == 0 {
+		ui.Warning("暂无规则")
+		ui.Info("整理并确认文件后会自动学习规则，或使用 'filo rules add' 手动添加")
+		return
+	}
+
+	// 已停用的规则在列表中标出
+	penalized, _ := db.GetPenalizedRules()
+	disabled := make(map[int64]bool)
+	for _, r := range penalized {
+		disabled[r.ID] = r.Disabled
+	}
+
+	ui.Title("📋", fmt.Sprintf("分类规则 (%d 条)", len(rules)))
+	ui.Divider()
+	fmt.Printf("  %-6s %-10s %-24s %-20s %6s %6s\n", "ID", "类型", "模式", "分类", "优先级", "命中")
+	ui.Divider()
+	for _, r := range rules {
+		line := fmt.Sprintf("  %-6d %-10s %-24s %-20s %6d %6d",
+			r.ID, r.PatternType, r.Pattern, r.Category+"/"+r.Subcategory, r.Priority, r.HitCount)
+		if disabled[r.ID] {
+			line = ui.Gray(line + "  已停用")
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+	if len(penalized) > 0 {
+		ui.Dim("%d 条规则被撤销或纠正过，使用 'filo rules --review' 查看", len(penalized))
+	}
+}
+
+// listPenalizedRules 列出有负反馈（被撤销、纠正过）的规则
+func listPenalizedRules(db *storage.Database) {
+	rules, err := db.GetPenalizedRules()
+	if err != nil {
+		ui.Error("读取规则失败: %v", err)
+		return
+	}
+	if len(rules) == 0 {
+		ui.Success("没有被撤销或纠正过的规则")
+		return
+	}
+
+	ui.Title("🔍", fmt.Sprintf("被撤销、纠正过的规则 (%d 条)", len(rules)))
+	ui.Divider()
+	fmt.Printf("  %-6s %-10s %-24s %-20s %6s %6s %6s  %s\n", "ID", "类型", "模式", "分类", "命中", "撤销", "纠正", "状态")
+	ui.Divider()
+	for _, r := range rules {
+		fmt.Printf("  %-6d %-10s %-24s %-20s %6d %6d %6d  %s\n",
+			r.ID, r.PatternType, r.Pattern, r.Category+"/"+r.Subcategory, r.HitCount, r.Undos, r.Corrections, penaltyStatus(r))
+	}
+	fmt.Println()
+	ui.Info("规则有误时用 'filo rules rm <ID>' 删除，没问题时用 'filo rules restore <ID>' 恢复")
+}
+
+// penaltyStatus 规则的降级、停用状态
+func penaltyStatus(r storage.PenalizedRule) string {
+	switch {
+	case r.Disabled:
+		return ui.Red("已停用")
+	case r.DemotedFrom > 0:
+		return ui.Yellow(fmt.Sprintf("已降级 (原优先级 %d)", r.DemotedFrom))
+	case !r.Adjustable():
+		return ui.Gray("手动规则，不自动调整")
+	}
+	return ui.Gray("观察中")
+}
+
+// listSuspiciousRules 列出过于宽泛的关键词规则
+func listSuspiciousRules(db *storage.Database) {
+	rules, err := db.SuspiciousRules(memory.StopWords(config.Get().StopWords))
+	if err != nil {
+		ui.Error("读取规则失败: %v", err)
+		return
+	}
+	if len(rules) == 0 {
+		ui.Success("没有发现过于宽泛的关键词规则")
+		return
+	}
+
+	ui.Title("⚠️", fmt.Sprintf("过于宽泛的关键词规则 (%d 条)", len(rules)))
+	ui.Divider()
+	fmt.Printf("  %-6s %-24s %-20s %6s  %s\n", "ID", "关键词", "分类", "命中", "原因")
+	ui.Divider()
+	for _, r := range rules {
+		fmt.Printf("  %-6d %-24s %-20s %6d  %s\n",
+			r.ID, r.Pattern, r.Category+"/"+r.Subcategory, r.HitCount, strings.Join(r.Reasons, "；"))
+	}
+	fmt.Println()
+	ui.Info("使用 'filo rules rm <ID>' 删除规则，在配置 stop_words 中追加不应学习的词")
+}
+
+// runRulesAdd 手动添加规则
+func runRulesAdd(cmd *cobra.Command, args []string) {
+	// 确定规则类型（只能指定一种）
+	var pattern, patternType string
+	count := 0
+	for _, p := range []struct{ value, ptype string }{
+		{ruleRegex, storage.PatternRegex},
+		{ruleGlob, storage.PatternGlob},
+		{ruleKeyword, "keyword"},
+		{ruleExt, "extension"},
+		{ruleOrigin, storage.PatternOrigin},
+	} {
+		if p.value != "" {
+			pattern, patternType = p.value, p.ptype
+			count++
+		}
+	}
+	// 语言规则可以用 --ext 限定扩展名
+	if ruleLang != "" {
+		if count > 1 || (count == 1 && patternType != "extension") {
+			ui.Error("--lang 只能与 --ext 同用")
+			return
+		}
+		lang := strings.ToLower(ruleLang)
+		if scanner.LanguageName(lang) == "" {
+			ui.Error("不支持的语言: %s（可选: %s）", ruleLang, strings.Join(scanner.Languages, ", "))
+			return
+		}
+		var exts []string
+		if ruleExt != "" {
+			exts = strings.Split(ruleExt, ",")
+		}
+		pattern, patternType, count = storage.LanguagePattern(lang, exts), storage.PatternLanguage, 1
+	}
+	if count != 1 {
+		ui.Error("请指定且只指定一种模式: --regex / --glob / --keyword / --ext / --lang / --origin")
+		return
+	}
+
+	// 校验模式
+	if storage.IsPatternRule(patternType) {
+		if _, err := storage.CompilePattern(patternType, pattern); err != nil {
+			ui.Error("无效的%s: %v", patternType, err)
+			return
+		}
+	}
+	if patternType == "extension" && !strings.HasPrefix(pattern, ".") {
+		pattern = "." + pattern
+	}
+	if patternType == storage.PatternOrigin {
+		if pattern = storage.OriginPattern(pattern); pattern == "" {
+			ui.Error("无效的域名: %s", ruleOrigin)
+			return
+		}
+	}
+	if patternType == "keyword" && memory.StopWords(config.Get().StopWords)[strings.ToLower(pattern)] {
+		ui.Error("「%s」是停用词，关键词规则不会生效，请改用 --regex 或 --glob", pattern)
+		return
+	}
+
+	category, subcategory := ruleCategory, ""
+	if i := strings.Index(ruleCategory, "/"); i >= 0 {
+		category, subcategory = ruleCategory[:i], ruleCategory[i+1:]
+	}
+	if category == "" {
+		ui.Error("分类不能为空")
+		return
+	}
+	category, subcategory = classifier.Normalize(category, subcategory)
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	if err := db.AddOrUpdateRule(pattern, patternType, category, subcategory, rulePriority); err != nil {
+		ui.Error("添加规则失败: %v", err)
+		return
+	}
+	ui.Success("已添加规则: %s「%s」→ %s/%s", patternType, pattern, category, subcategory)
+}
+
+// runRulesRm 删除规则
+func runRulesRm(cmd *cobra.Command, args []string) {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		ui.Error("无效的规则ID: %s", args[0])
+		return
+	}
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	deleted, err := db.DeleteRule(id)
+	if err != nil {
+		ui.Error("删除失败: %v", err)
+		return
+	}
+	if !deleted {
+		ui.Warning("规则不存在: %d", id)
+		return
+	}
+	ui.Success("已删除规则 %d", id)
+}
+
+// runRulesRestore 恢复被降级或停用的规则
+func runRulesRestore(cmd *cobra.Command, args []string) {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		ui.Error("无效的规则ID: %s", args[0])
+		return
+	}
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	restored, err := db.RestoreRule(id)
+	if err != nil {
+		ui.Error("恢复失败: %v", err)
+		return
+	}
+	if !restored {
+		ui.Warning("规则不存在: %d", id)
+		return
+	}
+	ui.Success("已恢复规则 %d", id)
+}
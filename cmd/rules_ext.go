@@ -0,0 +1,200 @@
+// Package cmd 命令行入口模块
+// rules ext 命令：查看和修改扩展名默认分类表
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// rulesExtCmd 扩展名默认分类命令定义
+var rulesExtCmd = &cobra.Command{
+	Use:   "ext",
+	Short: "扩展名默认分类",
+	Long: `查看和修改扩展名默认分类表。
+
+记忆未命中、AI 分类失败或判断不出分类时（包括离线模式），文件按扩展名归入默认分类，
+而不是「未分类」。内置了常见文件类型，首次使用时写入数据库。
+
+示例:
+  filo rules ext                       # 列出扩展名默认分类
+  filo rules ext set .epub 图书         # 电子书归入 图书
+  filo rules ext set dwg 设计/图纸      # 可省略点，可有多级
+  filo rules ext rm .json              # 删除，该扩展名无法推断时归入 未分类
+  filo rules ext reset                 # 恢复内置的默认分类表`,
+	Args: cobra.NoArgs,
+	Run:  runRulesExtList,
+}
+
+// rulesExtSetCmd 设置扩展名默认分类子命令
+var rulesExtSetCmd = &cobra.Command{
+	Use:   "set <扩展名> <分类>",
+	Short: "设置扩展名的默认分类",
+	Args:  cobra.ExactArgs(2),
+	Run:   runRulesExtSet,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return completeCategories(cmd, args, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+// rulesExtRmCmd 删除扩展名默认分类子命令
+var rulesExtRmCmd = &cobra.Command{
+	Use:   "rm <扩展名>",
+	Short: "删除扩展名的默认分类",
+	Args:  cobra.ExactArgs(1),
+	Run:   runRulesExtRm,
+}
+
+// rulesExtResetCmd 恢复内置默认分类子命令
+var rulesExtResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "恢复内置的扩展名默认分类（丢弃所有修改）",
+	Args:  cobra.NoArgs,
+	Run:   runRulesExtReset,
+}
+
+// init 注册 rules ext 子命令
+func init() {
+	rulesExtCmd.AddCommand(rulesExtSetCmd)
+	rulesExtCmd.AddCommand(rulesExtRmCmd)
+	rulesExtCmd.AddCommand(rulesExtResetCmd)
+	rulesCmd.AddCommand(rulesExtCmd)
+}
+
+// openExtensionDefaults 打开数据库，表为空时先写入内置项
+func openExtensionDefaults() (*storage.Database, bool) {
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return nil, false
+	}
+	if err := db.SeedExtensionDefaults(classifier.BuiltinExtensions()); err != nil {
+		ui.Error("写入内置默认分类失败: %v", err)
+		db.Close()
+		return nil, false
+	}
+	return db, true
+}
+
+// runRulesExtList 列出扩展名默认分类
+func runRulesExtList(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	db, ok := openExtensionDefaults()
+	if !ok {
+		return
+	}
+	defer db.Close()
+
+	list, err := db.GetExtensionDefaults()
+	if err != nil {
+		ui.Error("读取默认分类失败: %v", err)
+		return
+	}
+	if len(list) == 0 {
+		ui.Warning("扩展名默认分类表为空")
+		ui.Info("使用 'filo rules ext set .pdf 文档/PDF' 添加，或 'filo rules ext reset' 恢复内置项")
+		return
+	}
+
+	ui.Title("🏷️", fmt.Sprintf("扩展名默认分类 (%d 个)", len(list)))
+	ui.Divider()
+	custom := 0
+	for _, e := range list {
+		mark := ""
+		if !e.Builtin {
+			mark = ui.Cyan(" (自定义)")
+			custom++
+		}
+		label := e.Category
+		if e.Subcategory != "" {
+			label += "/" + e.Subcategory
+		}
+		fmt.Printf("  %-10s %s%s\n", e.Extension, label, mark)
+	}
+	fmt.Println()
+	if custom > 0 {
+		ui.Dim("%d 个自定义项，filo rules ext reset 可恢复内置默认分类", custom)
+	}
+}
+
+// runRulesExtSet 设置扩展名默认分类
+func runRulesExtSet(cmd *cobra.Command, args []string) {
+	ext := storage.NormalizeExtension(args[0])
+	if len(ext) < 2 {
+		ui.Error("无效的扩展名: %s", args[0])
+		return
+	}
+	category, subcategory := classifier.Normalize(args[1], "")
+
+	db, ok := openExtensionDefaults()
+	if !ok {
+		return
+	}
+	defer db.Close()
+
+	if err := db.SetExtensionDefault(ext, category, subcategory); err != nil {
+		ui.Error("设置失败: %v", err)
+		return
+	}
+	label := category
+	if subcategory != "" {
+		label += "/" + subcategory
+	}
+	ui.Success("%s 的默认分类: %s", ext, label)
+}
+
+// runRulesExtRm 删除扩展名默认分类
+func runRulesExtRm(cmd *cobra.Command, args []string) {
+	ext := storage.NormalizeExtension(args[0])
+
+	db, ok := openExtensionDefaults()
+	if !ok {
+		return
+	}
+	defer db.Close()
+
+	deleted, err := db.DeleteExtensionDefault(ext)
+	if err != nil {
+		ui.Error("删除失败: %v", err)
+		return
+	}
+	if !deleted {
+		ui.Warning("没有 %s 的默认分类", ext)
+		return
+	}
+	ui.Success("已删除 %s 的默认分类", ext)
+}
+
+// runRulesExtReset 恢复内置的扩展名默认分类
+func runRulesExtReset(cmd *cobra.Command, args []string) {
+	if !ui.Confirm("丢弃所有自定义的扩展名默认分类并恢复内置项？", false) {
+		ui.Info("已取消")
+		return
+	}
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	if err := db.ResetExtensionDefaults(classifier.BuiltinExtensions()); err != nil {
+		ui.Error("恢复失败: %v", err)
+		return
+	}
+	ui.Success("已恢复内置的扩展名默认分类")
+}
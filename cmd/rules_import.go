@@ -0,0 +1,118 @@
+// Package cmd 命令行入口模块
+// rules import 命令：从 Hazel、organize-tool 导入规则
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/ruleimport"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// rulesImportCmd 导入规则子命令
+var rulesImportCmd = &cobra.Command{
+	Use:   "import <文件>",
+	Short: "从其他整理工具导入规则",
+	Long: `将其他文件整理工具的规则转换为 filo 规则，方便迁移已有的自动化规则。
+
+支持的格式:
+  hazel          Hazel 导出的规则，需先转换为 XML:
+                 plutil -convert xml1 -o rules.xml 下载.hazelrules
+  organize-yaml  organize-tool 的 config.yaml
+
+只转换按文件名、扩展名、正则匹配并移动到文件夹的规则，目标文件夹相对 --base
+（默认主目录）的路径作为分类，如 ~/Documents/Invoices → Documents/Invoices。
+按内容、大小、日期等条件匹配的规则会被跳过并说明原因。
+
+示例:
+  filo rules import --format organize-yaml ~/.config/organize/config.yaml -n  # 只预览
+  filo rules import --format hazel rules.xml
+  filo rules import --format organize-yaml config.yaml --base ~/Documents`,
+	Args: cobra.ExactArgs(1),
+	Run:  runRulesImport,
+}
+
+// rules import 命令行参数
+var (
+	importFormat string // 规则格式
+	importBase   string // 目标文件夹的基准目录
+	importDryRun bool   // 只预览不写入
+)
+
+// init 注册 rules import 子命令
+func init() {
+	rulesImportCmd.Flags().StringVar(&importFormat, "format", "", "规则格式: "+strings.Join(ruleimport.Formats, " / "))
+	rulesImportCmd.Flags().StringVar(&importBase, "base", "", "目标文件夹的基准目录（默认主目录）")
+	rulesImportCmd.Flags().BoolVarP(&importDryRun, "dry-run", "n", false, "只显示转换结果，不写入规则")
+	rulesImportCmd.Flags().IntVar(&rulePriority, "priority", 30, "导入规则的优先级（学习规则为 10-20）")
+	rulesImportCmd.MarkFlagRequired("format")
+	rulesImportCmd.RegisterFlagCompletionFunc("format", fixedCompletion(ruleimport.Formats...))
+	rulesCmd.AddCommand(rulesImportCmd)
+}
+
+// runRulesImport 执行规则导入
+func runRulesImport(cmd *cobra.Command, args []string) {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		ui.Error("无法读取文件: %v", err)
+		return
+	}
+	result, err := ruleimport.Parse(importFormat, data, importBase)
+	if err != nil {
+		ui.Error("%v", err)
+		return
+	}
+
+	inputs := make([]storage.RuleInput, 0, len(result.Rules))
+	for _, r := range result.Rules {
+		category, subcategory := classifier.Normalize(classifier.JoinPath(r.Path))
+		inputs = append(inputs, storage.RuleInput{
+			Pattern:     r.Pattern,
+			PatternType: r.PatternType,
+			Category:    category,
+			Subcategory: subcategory,
+			Priority:    rulePriority,
+		})
+		ui.Success("%s: %s「%s」→ %s/%s", r.Source, r.PatternType, r.Pattern, category, subcategory)
+	}
+	for _, s := range result.Skipped {
+		ui.Warning("%s: %s", s.Source, s.Reason)
+	}
+
+	if len(inputs) == 0 {
+		ui.Warning("没有可以导入的规则")
+		return
+	}
+	if importDryRun {
+		ui.Warning("预览模式 - 未写入规则，共 %d 条", len(inputs))
+		return
+	}
+
+	l, err := acquireLock()
+	if err != nil {
+		return
+	}
+	defer l.Release()
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	if err := db.AddOrUpdateRules(inputs); err != nil {
+		ui.Error("导入规则失败: %v", err)
+		return
+	}
+	ui.Success("已导入 %d 条规则，用 filo rules 查看", len(inputs))
+}
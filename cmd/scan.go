@@ -7,8 +7,12 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
 
+	"filo/internal/config"
 	"filo/internal/scanner"
 	"filo/internal/ui"
 )
@@ -17,25 +21,90 @@ import (
 var scanCmd = &cobra.Command{
 	Use:   "scan [目录]",
 	Short: "扫描统计",
-	Long:  "扫描目录并显示文件统计信息",
-	Args:  cobra.ExactArgs(1), // 必须提供一个目录参数
-	Run:   runScan,
+	Long: `扫描目录并显示文件统计信息
+
+示例:
+  filo scan ~/Downloads                         # 只统计第一层
+  filo scan ~/Projects --max-depth 2            # 统计两层
+  filo scan ~/Projects -r --prune node_modules --prune 'build*'`,
+	Args: cobra.ExactArgs(1), // 必须提供一个目录参数
+	Run:  runScan,
 }
 
+// 扫描深度和剪枝的命令行参数，整理、扫描、归档、挑选、对比和评测命令共用
+var (
+	maxDepth  int      // 递归扫描的最大层数
+	pruneDirs []string // 不进入的目录模式
+)
+
 // init 注册 scan 子命令
 func init() {
+	scanCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "递归扫描子目录")
+	addScanFlags(scanCmd)
 	rootCmd.AddCommand(scanCmd)
 }
 
+// addScanFlags 为扫描目录的命令注册 --max-depth 和 --prune
+func addScanFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&maxDepth, "max-depth", 0, "递归扫描的最大层数（1 只扫描第一层），隐含 -r")
+	cmd.Flags().StringArrayVar(&pruneDirs, "prune", nil, "不进入名称或相对路径匹配该模式的目录（如 node_modules、'build*'），可重复指定")
+}
+
+// applyScanFlags 把 --max-depth 和 --prune 写入本次运行的配置，--max-depth 隐含递归扫描
+// 参数有误时显示错误并返回 false
+func applyScanFlags(cmd *cobra.Command) bool {
+	cfg := config.Get()
+	if cmd.Flags().Changed("max-depth") {
+		if maxDepth < 1 {
+			ui.Error("--max-depth 至少为 1")
+			return false
+		}
+		cfg.MaxDepth = maxDepth
+		recursive = true
+	}
+	for _, p := range pruneDirs {
+		if !scanner.ValidPrunePattern(p) {
+			ui.Error("无效的 --prune 模式: %s", p)
+			return false
+		}
+	}
+	cfg.Prune = append(cfg.Prune, pruneDirs...)
+	return true
+}
+
+// scanScope 描述扫描范围，如「递归扫描（2 层，跳过 node_modules）」
+func scanScope() string {
+	cfg := config.Get()
+	scope := "扫描"
+	if recursive {
+		scope = "递归扫描"
+	}
+	var limits []string
+	if recursive && cfg.MaxDepth > 0 {
+		limits = append(limits, fmt.Sprintf("%d 层", cfg.MaxDepth))
+	}
+	if len(cfg.Prune) > 0 {
+		limits = append(limits, "跳过 "+strings.Join(cfg.Prune, "、"))
+	}
+	if len(limits) > 0 {
+		scope += "（" + strings.Join(limits, "，") + "）"
+	}
+	return scope
+}
+
 // runScan 执行扫描命令
 // 扫描指定目录，统计文件类型、数量和大小
 func runScan(cmd *cobra.Command, args []string) {
 	ui.Banner()
 
 	dir := args[0]
+	if !applyScanFlags(cmd) {
+		return
+	}
 
 	// 扫描目录
-	files, err := scanner.ScanDirectory(dir, false)
+	ui.Title("📂", fmt.Sprintf("%s: %s", scanScope(), dir))
+	files, err := scanner.ScanDirectory(dir, recursive)
 	if err != nil {
 		ui.Error("扫描失败: %v", err)
 		return
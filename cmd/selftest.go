@@ -0,0 +1,306 @@
+// Package cmd 命令行入口模块
+// selftest 命令：用内置的基准样本检验当前模型和规则的分类准确率
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/llm"
+	"filo/internal/storage"
+	"filo/internal/taxonomy"
+	"filo/internal/ui"
+)
+
+// selftestCmd 准确率自测命令定义
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "用内置样本检验分类准确率",
+	Long: `用内置的基准样本（覆盖各个内置分类的常见文件名及其正确分类）检验当前模型和规则，
+按主分类报告准确率，并与上一次自测的结果对比。
+更换模型、修改提示词模板或分类体系前后各运行一次，即可客观比较效果。
+
+先用规则（手动添加的和学习到的规则）分类，其余交给模型；
+样本只有文件名，不移动文件、不学习，也不计入模型性能统计。
+
+示例:
+  filo selftest                          # 当前模型和规则
+  filo selftest -m qwen2.5:7b            # 换一个模型
+  filo selftest --no-rules               # 只看模型本身的准确率
+  filo selftest --note "新提示词模板"     # 备注本次改动，便于之后对照
+  filo selftest -v                       # 列出分错的样本
+  filo selftest --history                # 查看历次自测结果`,
+	Args: cobra.NoArgs,
+	Run:  runSelftest,
+}
+
+// selftest 命令行参数
+var (
+	selftestNoRules bool   // 不使用规则，只用模型
+	selftestNote    string // 本次自测的备注
+	selftestHistory bool   // 查看历次自测结果
+)
+
+// init 注册 selftest 子命令
+func init() {
+	selftestCmd.Flags().StringVarP(&model, "model", "m", "", "使用的模型")
+	selftestCmd.Flags().BoolVar(&selftestNoRules, "no-rules", false, "不使用规则，所有样本都交给模型")
+	selftestCmd.Flags().StringVar(&selftestNote, "note", "", "本次自测的备注（如修改了什么），显示在历史记录中")
+	selftestCmd.Flags().BoolVar(&selftestHistory, "history", false, "查看历次自测结果")
+	selftestCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "列出分错的样本")
+	selftestCmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "允许使用配置的远程 LLM 提供方")
+	selftestCmd.Flags().BoolVar(&debugLLM, "debug-llm", false, "把每次模型调用的提示词和原始响应保存到 ~/.filo/debug/<批次ID>/")
+	selftestCmd.RegisterFlagCompletionFunc("model", completeModels)
+	rootCmd.AddCommand(selftestCmd)
+}
+
+// runSelftest 执行准确率自测
+func runSelftest(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	if selftestHistory {
+		printSelftestHistory(db)
+		return
+	}
+
+	samples, err := classifier.SelftestSamples()
+	if err != nil {
+		ui.Error("读取基准样本失败: %v", err)
+		return
+	}
+
+	cfg := config.Get()
+	if model != "" {
+		cfg.SetModel(model)
+	}
+	client := llm.NewClient()
+	if !checkLLMReady(client) {
+		return
+	}
+	if !client.IsRemote() && !client.HasModel(cfg.ActiveModel()) {
+		ui.Error("模型 %s 未安装", cfg.ActiveModel())
+		ui.Info("运行: ollama pull %s", cfg.ActiveModel())
+		return
+	}
+
+	// 分类体系中缺少的分类，模型无从选择，这些样本必然分错
+	var missing []string
+	seen := make(map[string]bool)
+	for _, s := range samples {
+		if !seen[s.Category] && taxonomy.Get().Find(s.Category) == nil {
+			missing = append(missing, s.Category)
+		}
+		seen[s.Category] = true
+	}
+
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		ui.Error("初始化分类器失败: %v", err)
+		return
+	}
+	defer clf.Close()
+	defer watchInterrupt(clf)()
+	defer startTranscript(clf)()
+
+	// 上一次自测，用于对比
+	var previous *storage.SelftestRun
+	if runs, _ := db.GetSelftestRuns(1); len(runs) > 0 {
+		previous = &runs[0]
+	}
+
+	mode := "模型 + 规则"
+	if selftestNoRules {
+		mode = "仅模型"
+	}
+	ui.Title("🧪", fmt.Sprintf("准确率自测: %d 个样本", len(samples)))
+	ui.Info("模型: %s（%s）", cfg.ActiveModel(), mode)
+	if len(missing) > 0 {
+		ui.Warning("分类体系中没有 %s，这些样本无法分对", strings.Join(missing, "、"))
+	}
+
+	outcomes, elapsed := clf.SelfTest(samples, !selftestNoRules)
+	if clf.Interrupted() {
+		ui.Warning("已中断，结果不完整，不记录")
+		return
+	}
+	scores, total := classifier.ScoreSelftest(outcomes)
+
+	printSelftestTable(scores, total, classifier.SelftestConfusions(outcomes), previous)
+	printSelftestMistakes(outcomes)
+
+	// 记录本次结果
+	run := storage.SelftestRun{
+		Model:           cfg.ActiveModel(),
+		Rules:           !selftestNoRules,
+		Note:            selftestNote,
+		Samples:         total.Total,
+		CategoryCorrect: total.CategoryOK,
+		PathCorrect:     total.PathOK,
+		DurationMs:      elapsed.Milliseconds(),
+		PerCategory:     make(map[string]int, len(scores)),
+	}
+	for _, s := range scores {
+		run.PerCategory[s.Category] = s.CategoryOK
+	}
+	if err := db.AddSelftestRun(run); err != nil {
+		ui.Warning("记录自测结果失败: %v", err)
+	}
+
+	if llmCount := total.Total - total.ByRule; llmCount > 0 {
+		ui.Dim("模型分类 %d 个样本，耗时 %.1fs（%.0fms/文件）", llmCount, elapsed.Seconds(),
+			float64(elapsed.Milliseconds())/float64(llmCount))
+	}
+	if previous != nil {
+		printSelftestDelta(total, previous)
+	}
+}
+
+// printSelftestTable 输出各主分类的准确率
+// 「上次」为上一次自测中该分类的主分类准确率，「常误判为」为分错的样本中最常见的结果
+func printSelftestTable(scores []classifier.SelftestScore, total classifier.SelftestScore, confusions map[string]string, previous *storage.SelftestRun) {
+	ui.Title("📊", "准确率（按正确分类）")
+	ui.Divider()
+	fmt.Printf("  %s %4s %5s %4s %4s %6s  %s\n", padRight("分类", 10), "样本", "主分类", "完整路径", "规则", "上次", "常误判为")
+	ui.Divider()
+	row := func(name string, s classifier.SelftestScore, prev, confused string) {
+		rate := fmt.Sprintf("%.0f%%", s.Rate()*100)
+		switch {
+		case s.Rate() >= 0.8:
+			rate = ui.Green(fmt.Sprintf("%8s", rate))
+		case s.Rate() >= 0.5:
+			rate = ui.Yellow(fmt.Sprintf("%8s", rate))
+		default:
+			rate = ui.Red(fmt.Sprintf("%8s", rate))
+		}
+		fmt.Printf("  %s %6d %s %7.0f%% %6d %8s  %s\n", padRight(name, 10), s.Total, rate, s.PathRate()*100, s.ByRule, prev, ui.Gray(confused))
+	}
+	for _, s := range scores {
+		prev := "-"
+		if previous != nil {
+			if n, ok := previous.PerCategory[s.Category]; ok {
+				prev = fmt.Sprintf("%.0f%%", float64(n)/float64(s.Total)*100)
+			}
+		}
+		row(s.Category, s, prev, confusions[s.Category])
+	}
+	ui.Divider()
+	prev := "-"
+	if previous != nil {
+		prev = fmt.Sprintf("%.0f%%", previous.Rate()*100)
+	}
+	row("合计", total, prev, "")
+	fmt.Println()
+}
+
+// printSelftestMistakes 列出分错的样本（-v 时全部列出，否则只提示数量）
+func printSelftestMistakes(outcomes []classifier.SelftestOutcome) {
+	var wrong []classifier.SelftestOutcome
+	for _, o := range outcomes {
+		if !o.PathOK {
+			wrong = append(wrong, o)
+		}
+	}
+	if len(wrong) == 0 {
+		ui.Success("全部样本分类正确")
+		return
+	}
+	if !verbose {
+		ui.Dim("加 -v 列出分错的 %d 个样本", len(wrong))
+		return
+	}
+
+	ui.Info("分错的样本:")
+	for _, o := range wrong {
+		got := ui.Gray("(无结果)")
+		if o.Result.Source != "error" {
+			got = o.Result.Category + "/" + o.Result.Subcategory
+			if !o.CategoryOK {
+				got = ui.Red(got)
+			} else {
+				got = ui.Yellow(got)
+			}
+			got += " " + ui.Gray(fmt.Sprintf("(%s %.0f%%)", o.Result.Source, o.Result.Confidence*100))
+		}
+		fmt.Printf("    %s\n", ui.Bold(o.Sample.Name))
+		fmt.Printf("      正确: %s  结果: %s\n", o.Sample.Path(), got)
+	}
+	fmt.Println()
+}
+
+// printSelftestDelta 与上一次自测的整体准确率对比
+func printSelftestDelta(total classifier.SelftestScore, previous *storage.SelftestRun) {
+	label := previous.Model
+	if !previous.Rules {
+		label += "，仅模型"
+	}
+	if previous.Note != "" {
+		label += "，" + previous.Note
+	}
+	if previous.Samples != total.Total {
+		ui.Dim("上次自测（%s）的样本数为 %d，基准样本已更新，对比仅供参考", label, previous.Samples)
+	}
+	diff := (total.Rate() - previous.Rate()) * 100
+	msg := fmt.Sprintf("与上次（%s，%s）相比: %.0f%% → %.0f%%", label, previous.CreatedAt.Local().Format("01-02 15:04"),
+		previous.Rate()*100, total.Rate()*100)
+	switch {
+	case diff >= 0.5:
+		ui.Success("%s（+%.0f%%）", msg, diff)
+	case diff <= -0.5:
+		ui.Warning("%s（%.0f%%）", msg, diff)
+	default:
+		ui.Info("%s（持平）", msg)
+	}
+}
+
+// printSelftestHistory 列出历次自测结果
+func printSelftestHistory(db *storage.Database) {
+	runs, err := db.GetSelftestRuns(20)
+	if err != nil {
+		ui.Error("读取自测记录失败: %v", err)
+		return
+	}
+	if len(runs) == 0 {
+		ui.Info("还没有自测记录，运行 'filo selftest' 开始")
+		return
+	}
+
+	ui.Title("🧪", "自测记录")
+	ui.Divider()
+	fmt.Printf("  %s %s %s %5s %4s %6s  %s\n", padRight("时间", 12), padRight("模型", 20), padRight("规则", 4),
+		"主分类", "完整路径", "耗时", "备注")
+	ui.Divider()
+	for _, r := range runs {
+		rules := "是"
+		if !r.Rules {
+			rules = "否"
+		}
+		pathRate := 0.0
+		if r.Samples > 0 {
+			pathRate = float64(r.PathCorrect) / float64(r.Samples) * 100
+		}
+		fmt.Printf("  %-12s %-20s %s %7.0f%% %7.0f%% %7.1fs  %s\n",
+			r.CreatedAt.Local().Format("01-02 15:04"),
+			truncateModelName(r.Model, 20),
+			padRight(rules, 4),
+			r.Rate()*100,
+			pathRate,
+			float64(r.DurationMs)/1000,
+			ui.Gray(r.Note),
+		)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -17,7 +18,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"filo/internal/config"
+	"filo/internal/embedding"
 	"filo/internal/llm"
+	"filo/internal/taxonomy"
 	"filo/internal/ui"
 )
 
@@ -25,19 +28,35 @@ import (
 var setupCmd = &cobra.Command{
 	Use:   "setup",
 	Short: "安装向导",
-	Long:  "安装和配置 Ollama 及推荐模型",
-	Run:   runSetup,
+	Long: `安装和配置 Ollama 及推荐模型，并引导设置整理偏好。
+
+示例:
+  filo setup            # 完整安装向导
+  filo setup --prefs    # 只重新设置整理偏好`,
+	Run: runSetup,
 }
 
+// setup 命令行参数
+var (
+	prefsOnly bool // 只运行偏好设置向导
+)
+
 // init 注册 setup 子命令
 func init() {
+	setupCmd.Flags().BoolVar(&prefsOnly, "prefs", false, "只设置整理偏好")
 	rootCmd.AddCommand(setupCmd)
 }
 
 // runSetup 执行安装向导
-// 流程：检查 Ollama -> 启动服务 -> 检查模型 -> 下载推荐模型
+// 流程：检查 Ollama -> 启动服务 -> 检查模型 -> 下载推荐模型 -> 整理偏好
 func runSetup(cmd *cobra.Command, args []string) {
 	ui.Banner()
+
+	if prefsOnly {
+		runPreferenceWizard()
+		return
+	}
+
 	ui.Title("🚀", "安装向导")
 	ui.Divider()
 
@@ -109,7 +128,25 @@ func runSetup(cmd *cobra.Command, args []string) {
 		ui.Success("推荐模型已安装")
 	}
 
-	// ========== 步骤5: 显示完成信息 ==========
+	// 嵌入模型用于相似文件匹配，未安装时回退到本地哈希嵌入
+	if cfg.Embedder == embedding.EmbedderOllama && !client.HasLocalModel(cfg.EmbeddingModel) &&
+		!client.HasLocalModel(cfg.EmbeddingModel+":latest") {
+		fmt.Println()
+		ui.Warning("嵌入模型 %s 未安装（未安装时使用本地哈希嵌入，相似文件匹配较弱）", cfg.EmbeddingModel)
+		if ui.Confirm("是否下载?", true) {
+			downloadModel(cfg.EmbeddingModel)
+		}
+	}
+
+	// ========== 步骤5: 整理偏好 ==========
+	fmt.Println()
+	if ui.Confirm("是否现在设置整理偏好?", true) {
+		runPreferenceWizard()
+	} else {
+		ui.Dim("稍后可运行 'filo setup --prefs' 设置")
+	}
+
+	// ========== 步骤6: 显示完成信息 ==========
 	fmt.Println()
 	ui.Divider()
 	ui.Success("设置完成！")
@@ -121,6 +158,120 @@ func runSetup(cmd *cobra.Command, args []string) {
 	fmt.Println()
 }
 
+// runPreferenceWizard 运行整理偏好向导
+// 询问媒体、文档、安装包、压缩包的处理方式和顶层分类，生成 taxonomy.json 和 config.json
+func runPreferenceWizard() {
+	ui.Title("🧭", "整理偏好")
+	ui.Divider()
+	ui.Dim("回答几个问题，让整理结果符合你的习惯（直接回车使用默认值）")
+
+	tax := taxonomy.Default()
+	cfg := config.Get()
+
+	// ========== 1. 媒体文件 ==========
+	fmt.Println()
+	mediaOpts := []string{"图片、视频、音频各自独立分类", "统一放入「媒体」下按类型区分", "照片和视频按年月归档"}
+	mediaVals := []string{taxonomy.MediaByType, taxonomy.MediaMerged, taxonomy.MediaByDate}
+	tax.Preferences.Media = mediaVals[ui.Choose("📷 媒体文件如何整理?", mediaOpts, 0)]
+
+	// ========== 2. 文档 ==========
+	fmt.Println()
+	docOpts := []string{"按用途（合同、报告、笔记…）", "按项目或客户"}
+	docVals := []string{taxonomy.DocsByPurpose, taxonomy.DocsByProject}
+	tax.Preferences.Documents = docVals[ui.Choose("📄 文档如何整理?", docOpts, 0)]
+
+	// ========== 3. 安装包 ==========
+	fmt.Println()
+	installerOpts := []string{"归入「安装包」分类", "不整理，留在原处"}
+	installerVals := []string{taxonomy.HandleFile, taxonomy.HandleSkip}
+	tax.Preferences.Installers = installerVals[ui.Choose("💿 安装包（.dmg/.exe/.pkg…）如何处理?", installerOpts, 0)]
+
+	// ========== 4. 压缩包 ==========
+	fmt.Println()
+	archiveOpts := []string{"归入「压缩包」分类", "按内容主题归类", "不整理，留在原处"}
+	archiveVals := []string{taxonomy.HandleFile, taxonomy.HandleTopic, taxonomy.HandleSkip}
+	tax.Preferences.Archives = archiveVals[ui.Choose("🗜  压缩包（.zip/.rar/.7z…）如何处理?", archiveOpts, 0)]
+
+	// ========== 5. 顶层分类 ==========
+	fmt.Println()
+	applyMediaPreference(tax)
+	names := make([]string, len(tax.Categories))
+	for i, c := range tax.Categories {
+		names[i] = c.Name
+	}
+	ui.Info("📁 默认顶层分类: %s", strings.Join(names, "、"))
+	input := ui.Input("  需要的顶层分类（逗号分隔，可添加新分类）", strings.Join(names, ","))
+	tax.Categories = selectCategories(tax, input)
+
+	// ========== 6. 内容读取 ==========
+	fmt.Println()
+	ui.Dim("读取文本文件开头内容可提高准确率，内容仅发送给本地模型")
+	cfg.ReadContent = ui.Confirm("📖 是否读取文件内容辅助分类?", cfg.ReadContent)
+
+	// ========== 保存 ==========
+	fmt.Println()
+	if err := tax.Save(); err != nil {
+		ui.Error("保存分类体系失败: %v", err)
+		return
+	}
+	if err := cfg.Save(); err != nil {
+		ui.Error("保存配置失败: %v", err)
+		return
+	}
+	ui.Success("分类体系已保存: %s", filepath.Join(cfg.DataDir, "taxonomy.json"))
+	ui.Success("配置已保存")
+}
+
+// applyMediaPreference 根据媒体偏好调整默认分类
+// 选择合并时用「媒体」替换图片/视频/音频三个主分类
+func applyMediaPreference(tax *taxonomy.Taxonomy) {
+	if tax.Preferences.Media != taxonomy.MediaMerged {
+		return
+	}
+
+	var cats []taxonomy.Category
+	inserted := false
+	for _, c := range tax.Categories {
+		if c.Name == "图片" || c.Name == "视频" || c.Name == "音频" {
+			if !inserted {
+				cats = append(cats, taxonomy.Category{
+					Name:          "媒体",
+					Description:   "图片、视频和音频",
+					Subcategories: []string{"图片", "视频", "音频"},
+				})
+				inserted = true
+			}
+			continue
+		}
+		cats = append(cats, c)
+	}
+	tax.Categories = cats
+}
+
+// selectCategories 根据用户输入筛选顶层分类
+// 已知分类保留其子分类定义，新名称创建为空分类
+func selectCategories(tax *taxonomy.Taxonomy, input string) []taxonomy.Category {
+	var cats []taxonomy.Category
+	seen := make(map[string]bool)
+	for _, name := range strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、' || r == ' '
+	}) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if c := tax.Find(name); c != nil {
+			cats = append(cats, *c)
+		} else {
+			cats = append(cats, taxonomy.Category{Name: name})
+		}
+	}
+	if len(cats) == 0 {
+		return tax.Categories
+	}
+	return cats
+}
+
 // printInstallInstructions 打印 Ollama 安装指引
 // 根据不同操作系统显示对应的安装命令
 func printInstallInstructions() {
@@ -0,0 +1,175 @@
+// Package cmd 命令行入口模块
+// sidecar 命令：导入随文件保存的整理信息
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/scanner"
+	"filo/internal/sidecar"
+	"filo/internal/ui"
+)
+
+// sidecarCmd 整理信息命令定义
+var sidecarCmd = &cobra.Command{
+	Use:   "sidecar",
+	Short: "随文件保存的整理信息",
+	Long: `配置 sidecar 后，filo 整理文件时把分类、置信度、批次和原来的位置随文件保存:
+  json   文件旁的隐藏文件 .<文件名>.filo.json，复制文件夹时一起带走
+  xattr  文件的扩展属性（macOS、Linux），复制时需要保留扩展属性（如 cp -a、rsync -X）
+
+文件复制到其他机器、或重装后数据库为空时，用 filo sidecar import 把这些信息导入学习记录。
+
+示例:
+  filo config set sidecar json
+  filo sidecar import ~/备份/已整理 -n   # 只预览
+  filo sidecar import ~/备份/已整理`,
+}
+
+// sidecarImportCmd 导入整理信息子命令
+var sidecarImportCmd = &cobra.Command{
+	Use:   "import <目录>",
+	Short: "把目录中随文件保存的整理信息导入学习记录",
+	Long: `递归读取目录中文件的整理信息（扩展属性或 .filo.json），作为确认过的分类加入学习记录，
+之后整理同名或内容相同的文件、来自同一来源目录的文件时按这些分类归入。
+
+只导入学习记录，不移动文件，也不生成操作日志（导入的文件不能用 filo undo 撤销）。`,
+	Args: cobra.ExactArgs(1),
+	Run:  runSidecarImport,
+}
+
+// sidecar import 命令行参数
+var sidecarDryRun bool // 只预览不导入
+
+// init 注册 sidecar 命令
+func init() {
+	sidecarImportCmd.Flags().BoolVarP(&sidecarDryRun, "dry-run", "n", false, "只显示找到的整理信息，不导入")
+	sidecarCmd.AddCommand(sidecarImportCmd)
+	rootCmd.AddCommand(sidecarCmd)
+}
+
+// runSidecarImport 执行整理信息导入
+func runSidecarImport(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	dir, _ := filepath.Abs(args[0])
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		ui.Error("目录不存在: %s", args[0])
+		return
+	}
+
+	results, invalid := readSidecars(dir)
+	for _, msg := range invalid {
+		ui.Warning("%s", msg)
+	}
+	if len(results) == 0 {
+		ui.Warning("没有找到整理信息")
+		return
+	}
+
+	// 按分类汇总
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[strings.Join(r.Path(), classifier.PathSep)]++
+	}
+	paths := make([]string, 0, len(counts))
+	for p := range counts {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	ui.Title("🏷", fmt.Sprintf("找到 %d 个文件的整理信息", len(results)))
+	for _, p := range paths {
+		ui.Info("  %s %d", padRight(p, 24), counts[p])
+	}
+	fmt.Println()
+
+	if sidecarDryRun {
+		ui.Warning("预览模式 - 未导入")
+		return
+	}
+	if !ui.Confirm("导入学习记录?", true) {
+		ui.Warning("已取消")
+		return
+	}
+
+	l, err := acquireLock()
+	if err != nil {
+		return
+	}
+	defer l.Release()
+
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		ui.Error("初始化失败: %v", err)
+		return
+	}
+	defer clf.Close()
+
+	clf.ConfirmAll(results)
+	ui.Success("已导入 %d 条学习记录，用 filo stats 查看", len(results))
+}
+
+// readSidecars 递归读取目录中文件的整理信息
+// 学习记录中的文件名和来源目录取整理前的路径，与当初整理时学到的一致
+//
+// 返回值:
+//   - []classifier.Result: 有整理信息的文件
+//   - []string: 整理信息无法解析的文件
+func readSidecars(dir string) ([]classifier.Result, []string) {
+	var results []classifier.Result
+	var invalid []string
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		m, err := sidecar.Read(path)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", path, err))
+			return nil
+		}
+		if m == nil {
+			return nil
+		}
+		f, err := scanner.StatFile(path)
+		if err != nil {
+			return nil
+		}
+		f.ContentHash = scanner.ContentHash(f)
+		if m.SourcePath != "" {
+			f.Path, f.Name = m.SourcePath, filepath.Base(m.SourcePath)
+		}
+		category, subcategory := classifier.Normalize(m.Category, m.Subcategory)
+		results = append(results, classifier.Result{
+			FileInfo:    f,
+			Category:    category,
+			Subcategory: subcategory,
+			Confidence:  m.Confidence,
+			Source:      sidecar.Source,
+		})
+		return nil
+	})
+	return results, invalid
+}
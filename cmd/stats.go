@@ -8,11 +8,17 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"filo/internal/classifier"
 	"filo/internal/config"
+	"filo/internal/doctor"
+	"filo/internal/organizer"
+	"filo/internal/storage"
 	"filo/internal/ui"
 )
 
@@ -20,12 +26,46 @@ import (
 var statsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "学习统计",
-	Long:  "显示学习记录和统计信息",
-	Run:   runStats,
+	Long: `显示学习记录和统计信息
+
+示例:
+  filo stats                   # 学习统计和分类分布
+  filo stats --trend           # 最近 20 次运行的记忆命中率、置信度和纠正率趋势
+  filo stats --trend --runs 50 # 查看最近 50 次运行
+  filo stats --db              # 数据库文件、WAL 大小和各表占用空间
+  filo stats --llm             # 各模型的 token 用量、累计耗时和失败率
+  filo stats --llm --days 7    # 只统计最近 7 天的模型调用
+  filo stats --tree            # 最近一次整理的目标目录的分类目录树
+  filo stats --tree ~/归档 --depth 0 --stale 3  # 指定目录，展开全部层级，3 个月未收到新文件即提示`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runStats,
 }
 
+// stats 命令行参数
+var (
+	statsTrend bool // 显示运行趋势
+	statsRuns  int  // 趋势包含的运行次数
+	statsDB    bool // 显示数据库空间统计
+	statsLLM   bool // 显示模型调用统计
+	statsDays  int  // 模型调用统计的天数
+	statsTree  bool // 显示分类目录树
+	statsDepth int  // 目录树显示的层数
+	statsStale int  // 多少个月没有收到新文件视为可以合并
+)
+
+// trendChartHeight 趋势柱状图的行数
+const trendChartHeight = 6
+
 // init 注册 stats 子命令
 func init() {
+	statsCmd.Flags().BoolVar(&statsTrend, "trend", false, "显示最近几次运行的学习趋势")
+	statsCmd.Flags().IntVar(&statsRuns, "runs", 20, "趋势包含的运行次数")
+	statsCmd.Flags().BoolVar(&statsDB, "db", false, "显示数据库文件、WAL 大小和各表占用空间")
+	statsCmd.Flags().BoolVar(&statsLLM, "llm", false, "显示各模型的 token 用量、累计耗时和失败率")
+	statsCmd.Flags().IntVar(&statsDays, "days", 0, "模型调用统计的天数（0 表示全部）")
+	statsCmd.Flags().BoolVar(&statsTree, "tree", false, "以目录树显示目标目录中各分类的文件数、大小和最近移入时间")
+	statsCmd.Flags().IntVar(&statsDepth, "depth", 2, "目录树显示的层数（0 表示全部）")
+	statsCmd.Flags().IntVar(&statsStale, "stale", 6, "超过多少个月没有收到新文件的分类提示合并")
 	rootCmd.AddCommand(statsCmd)
 }
 
@@ -33,6 +73,23 @@ func init() {
 // 显示系统状态、学习记录数量、分类分布等信息
 func runStats(cmd *cobra.Command, args []string) {
 	ui.Banner()
+
+	if statsTrend {
+		showTrend()
+		return
+	}
+	if statsDB {
+		showDBStats()
+		return
+	}
+	if statsLLM {
+		showLLMStats()
+		return
+	}
+	if statsTree {
+		showCategoryTree(args)
+		return
+	}
 	ui.Title("📊", "学习统计")
 	ui.Divider()
 
@@ -61,6 +118,10 @@ func runStats(cmd *cobra.Command, args []string) {
 	ui.Info("  学习规则:  %v 条", stats["learned_rules"])     // 已学习的规则数
 	ui.Info("  向量记录:  %v 条", stats["vector_count"])      // 向量嵌入记录数
 	ui.Info("  用户反馈:  %v 条", stats["feedback_count"])    // 用户纠正反馈数
+	if n, _ := stats["rule_candidates"].(int); n > 0 {
+		// 样本数不足、尚未生效的规则
+		ui.Info("  候选规则:  %d 条（同一分类确认 %d 次后生效）", n, cfg.MinSamplesForRule)
+	}
 
 	// 显示学习功能状态
 	learning := "开启"
@@ -78,4 +139,290 @@ func runStats(cmd *cobra.Command, args []string) {
 			ui.Info("  %-12s %d", cat, cnt)
 		}
 	}
+
+	if cfg.SharedDB != "" {
+		showContributors()
+	}
+}
+
+// showContributors 显示共享数据库中各用户确认和纠正的分类数
+func showContributors() {
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	contributors, err := db.GetContributors()
+	if err != nil {
+		ui.Error("读取共享记录失败: %v", err)
+		return
+	}
+	fmt.Println()
+	ui.Info("团队共享: %s（当前用户 %s）", config.Get().SharedDB, db.User())
+	for _, c := range contributors {
+		name := c.User
+		if name == "" {
+			name = "（未记录）"
+		}
+		ui.Info("  %s 确认 %d · 纠正 %d", padRight(name, 24), c.Confirmed, c.Corrections)
+	}
+}
+
+// showTrend 显示最近几次运行的学习趋势
+// 记忆命中率上升、纠正率下降说明 filo 正在学会用户的分类习惯
+func showTrend() {
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	runs, err := db.GetRecentRuns(statsRuns)
+	if err != nil {
+		ui.Error("读取运行记录失败: %v", err)
+		return
+	}
+	if len(runs) < 2 {
+		ui.Warning("运行记录不足（%d 次），多整理几次后再查看趋势", len(runs))
+		return
+	}
+
+	ui.Title("📈", fmt.Sprintf("学习趋势（最近 %d 次运行）", len(runs)))
+	partial := 0
+	for _, r := range runs {
+		if r.Partial {
+			partial++
+		}
+	}
+	if partial > 0 {
+		ui.Dim("其中 %d 次运行被中断，只统计了已分类的文件", partial)
+	}
+
+	hitRate := make([]float64, len(runs))
+	confidence := make([]float64, len(runs))
+	correction := make([]float64, len(runs))
+	files := make([]float64, len(runs))
+	maxFiles := 0.0
+	for i, r := range runs {
+		hitRate[i] = r.MemoryHitRate()
+		confidence[i] = r.AvgConfidence
+		correction[i] = r.CorrectionRate()
+		files[i] = float64(r.FileCount)
+		if files[i] > maxFiles {
+			maxFiles = files[i]
+		}
+	}
+
+	fmt.Println()
+	printTrendLine("记忆命中率", hitRate, 1, percent)
+	printTrendLine("平均置信度", confidence, 1, percent)
+	printTrendLine("纠正率", correction, 1, percent)
+	printTrendLine("文件数", files, maxFiles, func(v float64) string { return fmt.Sprintf("%.0f", v) })
+
+	// 记忆命中率柱状图
+	fmt.Println()
+	ui.Info("记忆命中率:")
+	for i, line := range ui.BarChart(hitRate, 1, trendChartHeight) {
+		label := "    "
+		if i == 0 {
+			label = "100%"
+		}
+		fmt.Printf("  %s %s %s\n", ui.Gray(label), ui.Gray("┤"), ui.Green(line))
+	}
+	fmt.Printf("  %s %s%s\n", ui.Gray("  0%"), ui.Gray("┼"), ui.Gray(strings.Repeat("─", len(runs)+1)))
+	ui.Dim("       %s  →  %s", runs[0].CreatedAt.Local().Format("01-02 15:04"), runs[len(runs)-1].CreatedAt.Local().Format("01-02 15:04"))
+
+	// 前后两半的平均命中率对比
+	fmt.Println()
+	half := len(runs) / 2
+	delta := (average(hitRate[half:]) - average(hitRate[:half])) * 100
+	switch {
+	case delta >= 5:
+		ui.Success("记忆命中率提升 %.0f 个百分点，越用越懂你", delta)
+	case delta <= -5:
+		ui.Warning("记忆命中率下降 %.0f 个百分点，可能整理了较多新类型的文件", -delta)
+	default:
+		ui.Dim("记忆命中率基本持平")
+	}
+}
+
+// showDBStats 显示数据库空间统计
+// 用于判断 WAL 是否及时截断、哪些表占用空间最多
+func showDBStats() {
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	s, err := db.Stats()
+	if err != nil {
+		ui.Error("读取数据库统计失败: %v", err)
+		return
+	}
+	cfg := config.Get()
+
+	ui.Title("🗄️", "数据库")
+	ui.Info("  路径:      %s", s.Path)
+	ui.Info("  文件大小:  %s（%d 页 × %s）", ui.FormatSize(s.FileSize), s.PageCount, ui.FormatSize(s.PageSize))
+	if s.FreePages > 0 {
+		ui.Info("  空闲页:    %d 页（%s，VACUUM 后可回收）", s.FreePages, ui.FormatSize(s.FreePages*s.PageSize))
+	}
+	wal := ui.FormatSize(s.WALSize)
+	if s.WALSize >= doctor.WALWarnSize {
+		ui.Warning("  WAL 大小:  %s（偏大，运行 filo doctor --fix 截断）", wal)
+	} else {
+		ui.Info("  WAL 大小:  %s", wal)
+	}
+	ui.Info("  忙等待:    %d 毫秒", cfg.DBBusyTimeout)
+	if cfg.WALCheckpointInterval > 0 {
+		ui.Info("  检查点:    每 %d 分钟（长时间运行时）及退出时", cfg.WALCheckpointInterval)
+	} else {
+		ui.Info("  检查点:    仅退出时")
+	}
+
+	fmt.Println()
+	ui.Info("数据表:")
+	for _, t := range s.Tables {
+		ui.Info("  %s %8d 行  %s", padRight(t.Name, 24), t.Rows, ui.FormatSize(t.Bytes))
+	}
+	if len(s.Indexes) > 0 {
+		fmt.Println()
+		ui.Info("索引:")
+		for _, idx := range s.Indexes {
+			ui.Info("  %s %s  %s", padRight(idx.Name, 32), padRight(idx.Table, 24), ui.FormatSize(idx.Bytes))
+		}
+	}
+}
+
+// showLLMStats 显示各模型的调用统计：每个文件的 token 数、累计耗时和失败率
+// 用于比较模型、调整 batch_size（每个文件 token 数少、单次调用耗时短时可以加大批次）
+func showLLMStats() {
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	usage, err := db.GetLLMUsage(statsDays)
+	if err != nil {
+		ui.Error("读取模型调用统计失败: %v", err)
+		return
+	}
+	if statsDays > 0 {
+		ui.Title("🤖", fmt.Sprintf("模型调用（最近 %d 天）", statsDays))
+	} else {
+		ui.Title("🤖", "模型调用")
+	}
+	if len(usage) == 0 {
+		ui.Info("暂无记录，整理文件后再查看")
+		return
+	}
+
+	ui.Info("  %s %s %s %s %s %s %s %s %s", padRight("模型", 24), padRight("调用", 6), padRight("失败率", 8),
+		padRight("文件", 7), padRight("提示词", 10), padRight("输出", 9), padRight("token/文件", 11), padRight("累计耗时", 10), "ms/文件")
+	noTokens := false
+	for _, u := range usage {
+		perFile := 0.0
+		if u.Files > 0 {
+			perFile = float64(u.TotalMs) / float64(u.Files)
+		}
+		failure := padRight(percent(u.FailureRate()), 8)
+		if u.FailureRate() >= llmFailureWarnRate {
+			failure = ui.Yellow(failure)
+		}
+		ui.Info("  %s %-6d %s %-7d %-10d %-9d %-11.0f %s %.0f",
+			padRight(u.Model, 24), u.Calls, failure, u.Files, u.PromptTokens, u.ResponseTokens, u.TokensPerFile(),
+			padRight((time.Duration(u.TotalMs)*time.Millisecond).Round(time.Second).String(), 10), perFile)
+		if u.PromptTokens+u.ResponseTokens == 0 {
+			noTokens = true
+		}
+	}
+
+	fmt.Println()
+	ui.Dim("累计耗时按每次调用分别计入，并行的批次会重复计算；重试的每一次都算一次调用")
+	if noTokens {
+		ui.Dim("token 数为 0 的模型未在响应中返回用量")
+	}
+}
+
+// showCategoryTree 显示目标目录的分类目录树
+// 不指定目录时使用最近一次整理的目标目录
+func showCategoryTree(args []string) {
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer db.Close()
+
+	var dir string
+	if len(args) > 0 {
+		dir = args[0]
+	} else if run, err := db.GetLatestRun(); err == nil && run != nil && run.TargetDir != "" {
+		dir = run.TargetDir
+	} else {
+		ui.Error("还没有整理记录，请指定目标目录: filo stats --tree <目录>")
+		return
+	}
+	dir, _ = filepath.Abs(dir)
+
+	activity, err := db.GetFolderActivity(dir)
+	if err != nil {
+		ui.Error("读取整理记录失败: %v", err)
+		return
+	}
+	root, err := organizer.CategoryTree(dir, activity)
+	if err != nil {
+		ui.Error("无法读取目录: %v", err)
+		return
+	}
+	organizer.PrintCategoryTree(root, statsDepth, statsStale)
+}
+
+// llmFailureWarnRate 失败率达到该值时以黄色显示
+const llmFailureWarnRate = 0.1
+
+// printTrendLine 打印一项指标的迷你图和首尾数值
+func printTrendLine(name string, values []float64, max float64, format func(float64) string) {
+	fmt.Printf("  %s %s  %s → %s\n", padRight(name, 10), ui.Cyan(ui.Sparkline(values, max)),
+		format(values[0]), ui.Bold(format(values[len(values)-1])))
+}
+
+// percent 格式化为百分比
+func percent(v float64) string {
+	return fmt.Sprintf("%.0f%%", v*100)
+}
+
+// average 计算平均值
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// padRight 按显示宽度补齐空格（中文占两个宽度）
+func padRight(s string, width int) string {
+	w := 0
+	for _, r := range s {
+		if r > 127 {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	if w >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-w)
 }
@@ -0,0 +1,255 @@
+// Package cmd 命令行入口模块
+// suggest 命令：为归入「未分类」或置信度偏低的文件建议新的分类
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/llm"
+	"filo/internal/storage"
+	"filo/internal/taxonomy"
+	"filo/internal/ui"
+)
+
+// suggestCmd 建议新分类命令定义
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "根据未分类的文件建议新的分类",
+	Long: `把整理记录中归入「未分类」或置信度低于 confidence_threshold 的文件按文件名聚类，
+由 AI 为每一组相似的文件起一个分类名，逐个确认后加入分类体系（taxonomy.json），
+并为每个分类生成关键词规则，之后的同类文件直接按规则归类。
+
+已经确认或纠正过的文件不参与聚类。
+
+示例:
+  filo suggest                      # 逐个确认建议的分类
+  filo suggest -n                   # 只查看建议，不修改分类体系和规则
+  filo suggest --min-size 5         # 至少 5 个相似文件才建议新分类
+  filo suggest --similarity 0.7     # 提高相似度要求，分组更细`,
+	Args: cobra.NoArgs,
+	Run:  runSuggest,
+}
+
+// suggest 命令行参数
+var (
+	suggestSimilarity float64 // 归入同一组的最低相似度
+	suggestMinSize    int     // 一组至少包含的文件数
+	suggestLimit      int     // 参与聚类的最大文件数
+	suggestYes        bool    // 采用所有建议，不逐个确认
+	suggestPriority   int     // 生成的关键词规则的优先级
+)
+
+// init 注册 suggest 子命令
+func init() {
+	suggestCmd.Flags().Float64Var(&suggestSimilarity, "similarity", 0.6, "归入同一组的最低文件名相似度（0-1）")
+	suggestCmd.Flags().IntVar(&suggestMinSize, "min-size", 3, "一组至少包含的文件数")
+	suggestCmd.Flags().IntVar(&suggestLimit, "limit", 500, "参与聚类的最大文件数（最近整理的优先）")
+	suggestCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "只查看建议，不修改分类体系和规则")
+	suggestCmd.Flags().BoolVarP(&suggestYes, "yes", "y", false, "采用所有建议，不逐个确认")
+	suggestCmd.Flags().IntVar(&suggestPriority, "priority", 10, "生成的关键词规则的优先级（与学习到的关键词规则相同）")
+	suggestCmd.Flags().StringVarP(&model, "model", "m", "", "使用的模型")
+	suggestCmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "允许使用配置的远程 LLM 提供方")
+	suggestCmd.Flags().BoolVar(&showRedactions, "show-redactions", false, "列出发送给远程提供方前从文件名中隐去的个人信息")
+	suggestCmd.Flags().BoolVar(&debugLLM, "debug-llm", false, "把每次模型调用的提示词和原始响应保存到 ~/.filo/debug/<批次ID>/")
+	suggestCmd.RegisterFlagCompletionFunc("model", completeModels)
+	rootCmd.AddCommand(suggestCmd)
+}
+
+// runSuggest 执行建议新分类
+func runSuggest(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	if suggestSimilarity <= 0 || suggestSimilarity > 1 {
+		ui.Error("--similarity 必须在 0 到 1 之间")
+		return
+	}
+	if suggestMinSize < 2 {
+		suggestMinSize = 2
+	}
+
+	cfg := config.Get()
+	if model != "" {
+		cfg.SetModel(model)
+	}
+
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		ui.Error("初始化分类器失败: %v", err)
+		return
+	}
+	defer clf.Close()
+	defer watchInterrupt(clf)()
+	defer startTranscript(clf)()
+
+	// ========== 步骤1: 聚类 ==========
+	total, clusters, err := clf.UnsortedClusters(cfg.ConfidenceThreshold, suggestSimilarity, suggestMinSize, suggestLimit)
+	if err != nil {
+		ui.Error("读取整理记录失败: %v", err)
+		return
+	}
+	if total == 0 {
+		ui.Success("没有未分类或低置信度的文件，现有分类已经够用")
+		return
+	}
+	ui.Info("未分类或低置信度的文件: %d 个", total)
+	if len(clusters) == 0 {
+		ui.Warning("没有 %d 个以上相似的文件，暂时无法建议新分类", suggestMinSize)
+		ui.Dim("可以用 --min-size 或 --similarity 放宽要求")
+		return
+	}
+	grouped := 0
+	for _, c := range clusters {
+		grouped += len(c.Files)
+	}
+	ui.Success("聚成 %d 组相似的文件（共 %d 个）", len(clusters), grouped)
+
+	// ========== 步骤2: 由 AI 命名 ==========
+	if !checkLLMReady(llm.NewClient()) {
+		return
+	}
+	ui.Title("💡", "建议新分类")
+	suggestions, err := clf.NameClusters(clusters)
+	if showRedactions {
+		printRedactions(clf.Redactions())
+	}
+	if err != nil {
+		ui.Error("AI 命名失败: %v", err)
+		if len(suggestions) == 0 {
+			return
+		}
+	}
+	if len(suggestions) == 0 {
+		ui.Warning("AI 没有给出合适的分类名")
+		return
+	}
+
+	// ========== 步骤3: 逐个确认 ==========
+	tax := taxonomy.Get()
+	var accepted []classifier.CategorySuggestion
+	for i, s := range suggestions {
+		printSuggestion(i+1, len(suggestions), s, tax)
+		if dryRun {
+			continue
+		}
+		if !suggestYes {
+			s, ok := confirmSuggestion(s)
+			if !ok {
+				continue
+			}
+			suggestions[i] = s
+		}
+		accepted = append(accepted, suggestions[i])
+	}
+
+	if dryRun {
+		fmt.Println()
+		ui.Warning("预览模式 - 未修改分类体系和规则")
+		return
+	}
+	if len(accepted) == 0 {
+		ui.Warning("没有采用任何建议")
+		return
+	}
+	applySuggestions(accepted, tax)
+}
+
+// printSuggestion 显示一个建议的分类、说明、示例文件和关键词
+func printSuggestion(n, total int, s classifier.CategorySuggestion, tax *taxonomy.Taxonomy) {
+	fmt.Println()
+	label := ui.Green("新主分类")
+	if tax.Find(s.Category) != nil {
+		label = ui.Cyan("新子分类")
+	}
+	fmt.Printf("  [%d/%d] %s  %s\n", n, total, ui.Bold(s.Path()), label)
+	if s.Description != "" {
+		fmt.Printf("        %s\n", ui.Gray(s.Description))
+	}
+	samples := s.Files
+	if len(samples) > 5 {
+		samples = samples[:5]
+	}
+	more := ""
+	if len(s.Files) > len(samples) {
+		more = fmt.Sprintf(" 等 %d 个文件", len(s.Files))
+	}
+	fmt.Printf("        文件: %s%s\n", strings.Join(samples, "、"), more)
+	if len(s.Keywords) > 0 {
+		fmt.Printf("        规则: 关键词「%s」\n", strings.Join(s.Keywords, "」「"))
+	} else {
+		fmt.Printf("        规则: %s\n", ui.Gray("（没有可用的关键词，只加入分类体系）"))
+	}
+}
+
+// confirmSuggestion 询问是否采用建议，可以修改分类名
+// 返回（可能修改过的）建议以及是否采用
+func confirmSuggestion(s classifier.CategorySuggestion) (classifier.CategorySuggestion, bool) {
+	switch strings.ToLower(ui.Input("        采用? [Y/n/e 修改名称]", "")) {
+	case "", "y", "yes":
+		return s, true
+	case "e":
+		path := ui.Input("        分类（主分类/子分类）", s.Path())
+		category, subcategory := path, ""
+		if i := strings.Index(path, "/"); i >= 0 {
+			category, subcategory = path[:i], path[i+1:]
+		}
+		s.Category, s.Subcategory = classifier.Normalize(category, subcategory)
+		if s.Category == "" || s.Category == "未分类" {
+			ui.Warning("分类名无效，已跳过")
+			return s, false
+		}
+		return s, true
+	}
+	return s, false
+}
+
+// applySuggestions 把采用的建议加入分类体系并生成关键词规则
+func applySuggestions(accepted []classifier.CategorySuggestion, tax *taxonomy.Taxonomy) {
+	fmt.Println()
+	added := 0
+	var rules []storage.RuleInput
+	for _, s := range accepted {
+		if tax.Add(s.Category, s.Subcategory, s.Description) {
+			added++
+		}
+		for _, kw := range s.Keywords {
+			rules = append(rules, storage.RuleInput{
+				Pattern:     kw,
+				PatternType: "keyword",
+				Category:    s.Category,
+				Subcategory: s.Subcategory,
+				Priority:    suggestPriority,
+			})
+		}
+	}
+
+	if added > 0 {
+		if err := tax.Save(); err != nil {
+			ui.Error("保存分类体系失败: %v", err)
+			return
+		}
+		ui.Success("已加入分类体系: %d 个分类", added)
+	}
+	if len(rules) > 0 {
+		db, err := storage.NewDatabase()
+		if err != nil {
+			ui.Error("无法连接数据库: %v", err)
+			return
+		}
+		defer db.Close()
+		if err := db.AddOrUpdateRules(rules); err != nil {
+			ui.Error("添加规则失败: %v", err)
+			return
+		}
+		ui.Success("已添加 %d 条关键词规则，用 filo rules 查看", len(rules))
+	}
+	ui.Dim("下次整理时同类文件会归入新的分类；已经整理过的文件可以用 filo correct 调整")
+}
@@ -8,11 +8,15 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"filo/internal/organizer"
 	"filo/internal/storage"
 	"filo/internal/ui"
 )
@@ -24,25 +28,48 @@ var undoCmd = &cobra.Command{
 	Long: `撤销之前的文件整理操作，将文件移回原位置。
 
 不指定批次ID时，默认撤销最近一次操作。
+--since、--all-today 一次撤销该时间之后的全部批次，按从新到旧的顺序撤销，
+用于配置有误、连续整理了多次之后整体恢复。
+--category、--file 只撤销批次中符合条件的文件，其余文件保持整理后的位置，
+之后仍可撤销批次中剩下的文件。
 
 示例:
-  filo undo                    # 撤销最近一次整理
-  filo undo 20240115_143022    # 撤销指定批次
-  filo undo --list             # 查看可撤销的操作列表`,
-	Run: runUndo,
+  filo undo                        # 撤销最近一次整理
+  filo undo 20240115_143022        # 撤销指定批次
+  filo undo --since "2 hours ago"  # 撤销两小时内的全部整理
+  filo undo --since 14:00          # 撤销今天 14:00 之后的全部整理
+  filo undo --all-today            # 撤销今天的全部整理
+  filo undo --category 图片         # 只撤销最近一次整理中归入「图片」的文件
+  filo undo 20240115_143022 --category 图片/截图 --file 'Screenshot*'
+  filo undo --list                 # 查看可撤销的操作列表`,
+	ValidArgsFunction: completeBatchIDs,
+	Run:               runUndo,
 }
 
 // undo 命令行参数
 var (
-	listBatches bool // 是否列出可撤销的批次
+	listBatches bool   // 是否列出可撤销的批次
+	undoSince   string // 撤销该时间之后的全部批次
+	undoToday   bool   // 撤销今天的全部批次
+
+	undoCategories []string // 只撤销归入这些分类的文件
+	undoFiles      []string // 只撤销文件名匹配这些通配符的文件
 )
 
+// sinceRe 相对时间：2 hours ago、30 min ago、2小时前、3天前
+var sinceRe = regexp.MustCompile(`^(\d+)\s*(m|mins?|minutes?|分钟|h|hrs?|hours?|个?小时|d|days?|天)\s*(ago|前|之前)?$`)
+
 func init() {
 	// 注册 undo 子命令
 	rootCmd.AddCommand(undoCmd)
 
 	// 注册命令行标志
 	undoCmd.Flags().BoolVarP(&listBatches, "list", "l", false, "列出可撤销的操作")
+	undoCmd.Flags().StringVar(&undoSince, "since", "", `撤销该时间之后的全部批次，如 "2 hours ago"、"30m"、14:00、2024-05-01`)
+	undoCmd.Flags().BoolVar(&undoToday, "all-today", false, "撤销今天的全部批次")
+	undoCmd.Flags().StringArrayVar(&undoCategories, "category", nil, "只撤销归入该分类的文件（主分类或 主分类/子分类），可重复指定")
+	undoCmd.Flags().StringArrayVar(&undoFiles, "file", nil, "只撤销文件名匹配该通配符的文件（如 'Screenshot*'），可重复指定")
+	undoCmd.RegisterFlagCompletionFunc("category", completeCategories)
 }
 
 // runUndo 执行撤销操作
@@ -63,6 +90,37 @@ func runUndo(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// 等待正在进行的整理结束，再确定要撤销的批次
+	l, err := acquireLock()
+	if err != nil {
+		return
+	}
+	defer l.Release()
+
+	for _, p := range undoFiles {
+		if _, err := filepath.Match(p, ""); err != nil {
+			ui.Error("无效的通配符: %s", p)
+			return
+		}
+	}
+
+	// 按时间撤销多个批次
+	if undoSince != "" || undoToday {
+		if len(args) > 0 || (undoSince != "" && undoToday) {
+			ui.Error("批次 ID、--since 和 --all-today 只能指定一个")
+			return
+		}
+		since := startOfDay(time.Now())
+		if undoSince != "" {
+			if since, err = parseSince(undoSince, time.Now()); err != nil {
+				ui.Error("%v", err)
+				return
+			}
+		}
+		undoBatchesSince(db, since)
+		return
+	}
+
 	// 确定要撤销的批次
 	var batchID string
 	if len(args) > 0 {
@@ -96,9 +154,13 @@ func listUndoBatches(db *storage.Database) {
 		fileCount := batch["file_count"].(int)
 		createdAt := batch["created_at"].(string)
 		categories := batch["categories"].(string)
+		mark := ""
+		if batch["partial"].(bool) {
+			mark = ui.Gray(" (已中断，只整理了部分文件)")
+		}
 
 		// 格式化显示
-		fmt.Printf("  %s %s\n", ui.Green(fmt.Sprintf("[%d]", i+1)), ui.Bold(batchID))
+		fmt.Printf("  %s %s%s\n", ui.Green(fmt.Sprintf("[%d]", i+1)), ui.Bold(batchID), mark)
 		fmt.Printf("      📄 %d 个文件  📅 %s\n", fileCount, createdAt)
 		fmt.Printf("      📁 %s\n", ui.Gray(truncateString(categories, 50)))
 		fmt.Println()
@@ -117,23 +179,21 @@ func undoBatch(db *storage.Database, batchID string) {
 		ui.Error("找不到批次 %s 的操作记录", batchID)
 		return
 	}
+	selected := selectUndoLogs(logs)
+	if len(selected) == 0 {
+		ui.Warning("批次 %s 的 %d 个文件中没有符合条件的文件", batchID, len(logs))
+		return
+	}
 
 	// 显示将要撤销的操作
 	fmt.Println()
-	ui.Info("将撤销 %d 个文件的移动操作:", len(logs))
-	fmt.Println()
-
-	// 最多显示 5 个文件
-	for i, log := range logs {
-		if i >= 5 {
-			ui.Dim("  ... 还有 %d 个文件", len(logs)-5)
-			break
-		}
-		fmt.Printf("  %s %s\n", ui.Green("←"), log.Filename)
-		ui.Dim("    从: %s", log.DestPath)
-		ui.Dim("    到: %s", log.SourcePath)
+	if len(selected) < len(logs) {
+		ui.Info("将撤销 %d 个文件的移动操作（批次共 %d 个文件，其余保持不变）:", len(selected), len(logs))
+	} else {
+		ui.Info("将撤销 %d 个文件的移动操作:", len(logs))
 	}
 	fmt.Println()
+	previewUndo(selected)
 
 	// 确认撤销
 	if !ui.ConfirmDanger("确认撤销这些操作?") {
@@ -143,95 +203,216 @@ func undoBatch(db *storage.Database, batchID string) {
 
 	// 执行撤销
 	ui.Title("🔄", "执行撤销")
+	result := undoLogs(db, batchID, selected, len(logs))
+	printUndoResult(result)
+}
 
-	success := 0
-	errors := 0
-	var errorMsgs []string
+// undoBatchesSince 撤销指定时间之后开始的全部批次
+// 合并显示各批次后只确认一次，按从新到旧的顺序撤销：后面的整理可能移动过前面整理的文件
+func undoBatchesSince(db *storage.Database, since time.Time) {
+	ui.Title("⏪", fmt.Sprintf("撤销 %s 之后的整理", since.Format("2006-01-02 15:04")))
 
-	for _, log := range logs {
-		// 检查目标文件是否存在
-		if _, err := os.Stat(log.DestPath); os.IsNotExist(err) {
-			errors++
-			errorMsgs = append(errorMsgs, fmt.Sprintf("%s: 文件不存在", log.Filename))
+	ids, err := db.GetBatchesSince(since)
+	if err != nil {
+		ui.Error("查询批次失败: %v", err)
+		return
+	}
+	type batchLogs struct {
+		id    string
+		logs  []storage.OperationLog // 要撤销的文件
+		total int                    // 批次中可撤销的文件数
+	}
+	var batches []batchLogs
+	var all []storage.OperationLog
+	for _, id := range ids {
+		logs, err := db.GetBatchLogs(id)
+		if err != nil || len(logs) == 0 {
 			continue
 		}
-
-		// 确保源目录存在
-		sourceDir := filepath.Dir(log.SourcePath)
-		if err := os.MkdirAll(sourceDir, 0755); err != nil {
-			errors++
-			errorMsgs = append(errorMsgs, fmt.Sprintf("%s: 无法创建目录", log.Filename))
+		selected := selectUndoLogs(logs)
+		if len(selected) == 0 {
 			continue
 		}
+		batches = append(batches, batchLogs{id: id, logs: selected, total: len(logs)})
+		all = append(all, selected...)
+	}
+	if len(batches) == 0 {
+		ui.Warning("该时间之后没有可撤销的操作")
+		return
+	}
 
-		// 处理源路径可能已有同名文件的情况
-		destPath := log.SourcePath
-		if _, err := os.Stat(destPath); err == nil {
-			// 源位置已有文件，添加后缀
-			ext := filepath.Ext(destPath)
-			base := destPath[:len(destPath)-len(ext)]
-			for i := 1; ; i++ {
-				newPath := fmt.Sprintf("%s_restored_%d%s", base, i, ext)
-				if _, err := os.Stat(newPath); os.IsNotExist(err) {
-					destPath = newPath
-					break
-				}
+	// 显示将要撤销的批次和文件
+	fmt.Println()
+	ui.Info("将按从新到旧的顺序撤销 %d 个批次、共 %d 个文件:", len(batches), len(all))
+	fmt.Println()
+	for _, b := range batches {
+		var categories []string
+		seen := make(map[string]bool)
+		for _, log := range b.logs {
+			if !seen[log.Category] {
+				seen[log.Category] = true
+				categories = append(categories, log.Category)
 			}
 		}
+		fmt.Printf("  %s  📄 %d 个文件  📅 %s\n", ui.Bold(b.id), len(b.logs), b.logs[0].CreatedAt.Local().Format("2006-01-02 15:04:05"))
+		fmt.Printf("      📁 %s\n", ui.Gray(truncateString(strings.Join(categories, ","), 50)))
+	}
+	fmt.Println()
+	previewUndo(all)
+
+	if !ui.ConfirmDanger(fmt.Sprintf("确认撤销这 %d 个批次?", len(batches))) {
+		ui.Warning("已取消")
+		return
+	}
+
+	// 逐个批次撤销，合并结果
+	ui.Title("🔄", "执行撤销")
+	var total organizer.UndoResult
+	for _, b := range batches {
+		result := undoLogs(db, b.id, b.logs, b.total)
+		ui.Dim("  %s: 撤销 %d 个文件", b.id, result.Success)
+		total.Success += result.Success
+		total.Errors += result.Errors
+		total.Details = append(total.Details, result.Details...)
+		total.DemotedRules += result.DemotedRules
+	}
+	printUndoResult(total)
+}
+
+// selectUndoLogs 按 --category、--file 选出要撤销的文件，未指定时返回全部
+// 同一参数指定多个值时符合其一即可，同时指定两种参数时都要符合
+func selectUndoLogs(logs []storage.OperationLog) []storage.OperationLog {
+	if len(undoCategories) == 0 && len(undoFiles) == 0 {
+		return logs
+	}
+	var selected []storage.OperationLog
+	for _, log := range logs {
+		if matchUndoCategory(log) && matchUndoFile(log) {
+			selected = append(selected, log)
+		}
+	}
+	return selected
+}
 
-		// 移动文件回原位置
-		if err := os.Rename(log.DestPath, destPath); err != nil {
-			errors++
-			errorMsgs = append(errorMsgs, fmt.Sprintf("%s: %v", log.Filename, err))
-		} else {
-			success++
+// matchUndoCategory 文件的分类是否为 --category 指定的分类或其下级（不区分大小写）
+func matchUndoCategory(log storage.OperationLog) bool {
+	if len(undoCategories) == 0 {
+		return true
+	}
+	path := strings.ToLower(log.Category)
+	if log.Subcategory != "" {
+		path += "/" + strings.ToLower(log.Subcategory)
+	}
+	for _, c := range undoCategories {
+		c = strings.ToLower(strings.Trim(c, "/"))
+		if path == c || strings.HasPrefix(path, c+"/") {
+			return true
 		}
 	}
+	return false
+}
 
-	// 标记批次为已撤销
-	if success > 0 {
-		db.MarkBatchUndone(batchID)
+// matchUndoFile 文件名是否匹配 --file 指定的通配符
+func matchUndoFile(log storage.OperationLog) bool {
+	if len(undoFiles) == 0 {
+		return true
+	}
+	for _, p := range undoFiles {
+		if ok, _ := filepath.Match(p, log.Filename); ok {
+			return true
+		}
 	}
+	return false
+}
 
-	// 清理空目录
-	cleanEmptyDirs(logs)
+// undoLogs 撤销批次中的文件：选出了批次中的全部文件时撤销整个批次，否则只撤销选出的文件
+func undoLogs(db *storage.Database, batchID string, logs []storage.OperationLog, total int) organizer.UndoResult {
+	if len(logs) < total {
+		return organizer.UndoSelected(db, logs)
+	}
+	return organizer.Undo(db, logs, batchID)
+}
+
+// previewUndo 显示将要移回原位置的文件，最多显示 5 个
+func previewUndo(logs []storage.OperationLog) {
+	for i, log := range logs {
+		if i >= 5 {
+			ui.Dim("  ... 还有 %d 个文件", len(logs)-5)
+			break
+		}
+		fmt.Printf("  %s %s\n", ui.Green("←"), log.Filename)
+		ui.Dim("    从: %s", log.DestPath)
+		ui.Dim("    到: %s", log.SourcePath)
+	}
+	fmt.Println()
+}
 
-	// 显示结果
+// printUndoResult 显示撤销结果
+func printUndoResult(result organizer.UndoResult) {
 	fmt.Println()
-	ui.Success("成功撤销: %d 个文件", success)
-	if errors > 0 {
-		ui.Error("失败: %d 个文件", errors)
-		if len(errorMsgs) <= 3 {
-			for _, msg := range errorMsgs {
+	ui.Success("成功撤销: %d 个文件", result.Success)
+	if result.Errors > 0 {
+		ui.Error("失败: %d 个文件", result.Errors)
+		if len(result.Details) <= 3 {
+			for _, msg := range result.Details {
 				ui.Dim("  - %s", msg)
 			}
 		}
 	}
+	if result.DemotedRules > 0 {
+		ui.Warning("%d 条规则多次被撤销，已降级或停用，使用 'filo rules --review' 查看", result.DemotedRules)
+	}
 }
 
-// cleanEmptyDirs 清理空目录
-func cleanEmptyDirs(logs []storage.OperationLog) {
-	// 收集所有涉及的目录
-	dirs := make(map[string]bool)
-	for _, log := range logs {
-		dir := filepath.Dir(log.DestPath)
-		dirs[dir] = true
-	}
-
-	// 尝试删除空目录
-	for dir := range dirs {
-		// 检查目录是否为空
-		entries, err := os.ReadDir(dir)
-		if err == nil && len(entries) == 0 {
-			os.Remove(dir)
-			// 尝试删除上级目录（如果也为空）
-			parentDir := filepath.Dir(dir)
-			parentEntries, _ := os.ReadDir(parentDir)
-			if len(parentEntries) == 0 {
-				os.Remove(parentDir)
-			}
+// parseSince 解析 --since 指定的时间
+// 支持相对时间（2 hours ago、30m、1h30m、2小时前、3天前）、today / yesterday、
+// 今天的时刻（14:00）和日期时间（2024-05-01、2024-05-01 14:00）
+//
+// 参数:
+//   - s: 用户输入的时间
+//   - now: 当前时间
+//
+// 返回值:
+//   - time.Time: 解析出的时间
+//   - error: 无法识别时返回错误
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "today", "今天":
+		return startOfDay(now), nil
+	case "yesterday", "昨天":
+		return startOfDay(now).AddDate(0, 0, -1), nil
+	}
+
+	if m := sinceRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch unit := m[2]; {
+		case strings.HasPrefix(unit, "d") || unit == "天":
+			return now.AddDate(0, 0, -n), nil
+		case strings.HasPrefix(unit, "h") || strings.HasSuffix(unit, "小时"):
+			return now.Add(-time.Duration(n) * time.Hour), nil
+		default:
+			return now.Add(-time.Duration(n) * time.Minute), nil
 		}
 	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("15:04", s, now.Location()); err == nil {
+		return startOfDay(now).Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute), nil
+	}
+	return time.Time{}, fmt.Errorf("无法识别的时间: %s（可用 \"2 hours ago\"、30m、14:00、2024-05-01 等格式）", s)
+}
+
+// startOfDay 当天零点（本地时间）
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
 }
 
 // truncateString 截断字符串
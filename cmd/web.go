@@ -0,0 +1,91 @@
+// Package cmd 命令行入口模块
+// web 命令：在本机启动网页控制台
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"filo/internal/config"
+	"filo/internal/llm"
+	"filo/internal/ui"
+	"filo/internal/web"
+)
+
+// webCmd 网页控制台命令定义
+var webCmd = &cobra.Command{
+	Use:   "web",
+	Short: "启动本地网页控制台",
+	Long: `在本机启动网页控制台，在浏览器中查看统计、撤销批次、编辑规则和整理目录。
+
+控制台只监听 127.0.0.1，不接受来自其他网站或其他机器的请求。
+Prometheus 指标在控制台的 /metrics 提供；需要从其他机器抓取时，
+用 --metrics 在单独的地址上只提供 /metrics。
+
+示例:
+  filo web                          # 在 http://127.0.0.1:8765 启动
+  filo web -p 9000                  # 指定端口
+  filo web --offline                # 网页中的整理不调用 LLM
+  filo web --metrics :9464          # 在所有网卡的 9464 端口提供 /metrics`,
+	Run: runWeb,
+}
+
+// web 命令行参数
+var (
+	webPort int // 监听端口
+)
+
+func init() {
+	// 注册 web 子命令
+	rootCmd.AddCommand(webCmd)
+
+	// 注册命令行标志
+	webCmd.Flags().IntVarP(&webPort, "port", "p", 8765, "监听端口")
+	webCmd.Flags().BoolVar(&offline, "offline", false, "离线模式，只用学习记忆和扩展名分类")
+	webCmd.Flags().BoolVar(&allowRemote, "allow-remote", false, "允许使用配置的远程 LLM 提供方")
+	webCmd.Flags().StringVar(&metricsAddr, "metrics", "", "在指定地址单独提供 Prometheus 指标（/metrics），如 :9464")
+}
+
+// runWeb 启动网页控制台，Ctrl+C 退出
+func runWeb(cmd *cobra.Command, args []string) {
+	ui.Banner()
+
+	// LLM 不可用时仍可查看统计和管理规则，整理降级为离线模式
+	cfg := config.Get()
+	if offline {
+		cfg.Offline = true
+	} else if !checkLLMReady(llm.NewClient()) {
+		ui.Warning("AI 分类不可用，网页中的整理将使用离线模式")
+		cfg.Offline = true
+	}
+
+	srv, err := web.NewServer()
+	if err != nil {
+		ui.Error("无法连接数据库: %v", err)
+		return
+	}
+	defer srv.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	addr := fmt.Sprintf("127.0.0.1:%d", webPort)
+	ui.Success("控制台已启动: %s", ui.Bold("http://"+addr))
+	serveMetrics(ctx)
+	ui.Dim("按 Ctrl+C 退出")
+
+	if err := srv.ListenAndServe(ctx, addr); err != nil {
+		ui.Error("启动失败: %v", err)
+		return
+	}
+	fmt.Println()
+	ui.Info("控制台已关闭")
+}
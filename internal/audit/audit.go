@@ -0,0 +1,317 @@
+// Package audit 审计模块
+// 只读地检查目录：给出 filo 会怎样整理的计划，并找出重复文件、大文件、
+// 陈旧文件和扩展名与实际格式不符的文件；不移动任何文件，
+// 适合在不允许移动文件的共享盘、团队盘上运行
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/organizer"
+	"filo/internal/quarantine"
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// ==================== 类型定义 ====================
+
+// Misnamed 扩展名与实际格式不符的文件
+type Misnamed struct {
+	File   scanner.FileInfo // 文件信息
+	Actual string           // 按文件头识别出的实际扩展名
+}
+
+// Report 审计报告
+type Report struct {
+	Plan        *organizer.Plan      // filo 会执行的整理计划
+	Files       int                  // 文件数
+	TotalSize   int64                // 文件总大小
+	Duplicates  [][]scanner.FileInfo // 内容相同的文件组
+	Huge        []scanner.FileInfo   // 大文件（按大小降序）
+	Stale       []scanner.FileInfo   // 陈旧文件（按修改时间升序）
+	Misnamed    []Misnamed           // 扩展名与实际格式不符的文件
+	Suspicious  []scanner.FileInfo   // 未完成下载、空文件、可能损坏的文件
+	HugeSize    int64                // 大文件阈值（字节），0 表示不检查
+	StaleBefore time.Time            // 早于该时间修改的文件为陈旧文件，零值表示不检查
+	CreatedAt   time.Time            // 审计时间
+}
+
+// ==================== 分析函数 ====================
+
+// Analyze 分析扫描结果，生成审计报告
+// 阈值取自配置 audit_huge_mb 和 audit_stale_days
+func Analyze(files []scanner.FileInfo, plan *organizer.Plan) *Report {
+	cfg := config.Get()
+	r := &Report{Plan: plan, CreatedAt: time.Now()}
+	if cfg.AuditHugeMB > 0 {
+		r.HugeSize = int64(cfg.AuditHugeMB) << 20
+	}
+	if cfg.AuditStaleDays > 0 {
+		r.StaleBefore = r.CreatedAt.AddDate(0, 0, -cfg.AuditStaleDays)
+	}
+
+	var regular []scanner.FileInfo
+	for _, f := range files {
+		if f.IsDir {
+			continue
+		}
+		if f.Cloud == "" {
+			regular = append(regular, f) // 仅在云端的文件计算哈希会触发下载
+		}
+		r.Files++
+		r.TotalSize += f.Size
+
+		if r.HugeSize > 0 && f.Size >= r.HugeSize {
+			r.Huge = append(r.Huge, f)
+		}
+		if !r.StaleBefore.IsZero() && f.ModifiedTime.Before(r.StaleBefore) {
+			r.Stale = append(r.Stale, f)
+		}
+		if f.Cloud != "" {
+			continue // 读取内容会触发云盘下载，不检查扩展名
+		}
+		if actual := scanner.Misnamed(f.Path, f.Name); actual != "" {
+			r.Misnamed = append(r.Misnamed, Misnamed{File: f, Actual: actual})
+		} else if f.Suspicious != "" {
+			r.Suspicious = append(r.Suspicious, f)
+		}
+	}
+
+	sort.Slice(r.Huge, func(i, j int) bool { return r.Huge[i].Size > r.Huge[j].Size })
+	sort.Slice(r.Stale, func(i, j int) bool { return r.Stale[i].ModifiedTime.Before(r.Stale[j].ModifiedTime) })
+	r.Duplicates = findDuplicates(regular)
+	return r
+}
+
+// findDuplicates 查找内容相同的文件
+// 先按大小分组，只对大小相同的文件计算 SHA-256
+func findDuplicates(files []scanner.FileInfo) [][]scanner.FileInfo {
+	bySize := make(map[int64][]scanner.FileInfo)
+	for _, f := range files {
+		if f.Size > 0 && f.SameAs == "" { // 硬链接不占用额外空间，不算重复
+			bySize[f.Size] = append(bySize[f.Size], f)
+		}
+	}
+
+	var groups [][]scanner.FileInfo
+	for _, same := range bySize {
+		if len(same) < 2 {
+			continue
+		}
+		byHash := make(map[string][]scanner.FileInfo)
+		for _, f := range same {
+			if hash, err := quarantine.Hash(f.Path); err == nil {
+				byHash[hash] = append(byHash[hash], f)
+			}
+		}
+		for _, group := range byHash {
+			if len(group) > 1 {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	// 浪费空间多的组排在前面
+	sort.Slice(groups, func(i, j int) bool {
+		return wasted(groups[i]) > wasted(groups[j])
+	})
+	return groups
+}
+
+// wasted 重复文件组多占用的空间（保留一份）
+func wasted(group []scanner.FileInfo) int64 {
+	return group[0].Size * int64(len(group)-1)
+}
+
+// Anomalies 异常文件总数（重复文件按组计）
+func (r *Report) Anomalies() int {
+	return len(r.Duplicates) + len(r.Huge) + len(r.Stale) + len(r.Misnamed) + len(r.Suspicious)
+}
+
+// WastedSize 重复文件多占用的总空间
+func (r *Report) WastedSize() int64 {
+	var total int64
+	for _, g := range r.Duplicates {
+		total += wasted(g)
+	}
+	return total
+}
+
+// rel 文件相对源目录的路径
+func (r *Report) rel(f scanner.FileInfo) string {
+	return r.Plan.RelPath(classifier.Result{FileInfo: f})
+}
+
+// ==================== 输出函数 ====================
+
+// Print 在终端显示审计报告（每类异常最多显示 organizer.MaxDisplayFiles 个）
+func Print(r *Report) {
+	lines := []string{
+		fmt.Sprintf("📄 文件: %d 个 (%s)", r.Files, ui.FormatSize(r.TotalSize)),
+		fmt.Sprintf("📁 将整理: %d 个文件到 %d 个分类", r.Plan.TotalFiles(), r.Plan.TotalFolders()),
+	}
+	if len(r.Plan.Review) > 0 {
+		lines = append(lines, fmt.Sprintf("❓ 待确认: %d 个", len(r.Plan.Review)))
+	}
+	lines = append(lines, fmt.Sprintf("⚠️  异常: %d 项", r.Anomalies()))
+	ui.Box("🔍 审计报告", lines)
+
+	if len(r.Duplicates) > 0 {
+		printSection("📑", fmt.Sprintf("重复文件 %d 组，多占用 %s", len(r.Duplicates), ui.FormatSize(r.WastedSize())))
+		for i, g := range r.Duplicates {
+			if i >= organizer.MaxDisplayFiles {
+				ui.Dim("      ... 还有 %d 组", len(r.Duplicates)-organizer.MaxDisplayFiles)
+				break
+			}
+			names := make([]string, len(g))
+			for j, f := range g {
+				names[j] = r.rel(f)
+			}
+			fmt.Printf("      %s %s\n", strings.Join(names, ui.Gray(" = ")), ui.Gray(ui.FormatSize(g[0].Size)))
+		}
+	}
+	if len(r.Huge) > 0 {
+		printSection("🐘", fmt.Sprintf("大文件 %d 个（≥ %s）", len(r.Huge), ui.FormatSize(r.HugeSize)))
+		printFiles(r, r.Huge, func(f scanner.FileInfo) string { return ui.FormatSize(f.Size) })
+	}
+	if len(r.Stale) > 0 {
+		printSection("🕸", fmt.Sprintf("陈旧文件 %d 个（%s 之前修改）", len(r.Stale), r.StaleBefore.Format("2006-01-02")))
+		printFiles(r, r.Stale, func(f scanner.FileInfo) string { return f.ModifiedTime.Format("2006-01-02") })
+	}
+	if len(r.Misnamed) > 0 {
+		printSection("🏷", fmt.Sprintf("扩展名与实际格式不符 %d 个", len(r.Misnamed)))
+		for i, m := range r.Misnamed {
+			if i >= organizer.MaxDisplayFiles {
+				ui.Dim("      ... 还有 %d 个文件", len(r.Misnamed)-organizer.MaxDisplayFiles)
+				break
+			}
+			fmt.Printf("      %s %s\n", r.rel(m.File), ui.Gray("实际为 "+m.Actual))
+		}
+	}
+	if len(r.Suspicious) > 0 {
+		printSection("🔎", fmt.Sprintf("可疑文件 %d 个", len(r.Suspicious)))
+		printFiles(r, r.Suspicious, func(f scanner.FileInfo) string { return f.Suspicious })
+	}
+	fmt.Println()
+}
+
+// printSection 显示异常类别标题
+func printSection(icon, text string) {
+	fmt.Printf("\n  %s %s\n", icon, ui.Bold(text))
+}
+
+// printFiles 显示文件列表，detail 给出每个文件的说明
+func printFiles(r *Report, files []scanner.FileInfo, detail func(scanner.FileInfo) string) {
+	for i, f := range files {
+		if i >= organizer.MaxDisplayFiles {
+			ui.Dim("      ... 还有 %d 个文件", len(files)-organizer.MaxDisplayFiles)
+			break
+		}
+		fmt.Printf("      %s %s\n", r.rel(f), ui.Gray(detail(f)))
+	}
+}
+
+// WriteMarkdown 将完整的审计报告（含整理计划）写入 Markdown 文件
+func WriteMarkdown(r *Report, path string) error {
+	var sb strings.Builder
+	w := func(format string, args ...interface{}) {
+		fmt.Fprintf(&sb, format+"\n", args...)
+	}
+
+	w("# filo 审计报告")
+	w("")
+	w("- 目录: `%s`", r.Plan.SourceDir)
+	w("- 时间: %s", r.CreatedAt.Format("2006-01-02 15:04"))
+	w("- 文件: %d 个 (%s)", r.Files, ui.FormatSize(r.TotalSize))
+	w("- 异常: %d 项", r.Anomalies())
+	w("")
+	w("本报告为只读审计结果，没有移动任何文件。")
+
+	w("")
+	w("## 整理计划")
+	w("")
+	w("filo 会将 %d 个文件整理到 %d 个分类：", r.Plan.TotalFiles(), r.Plan.TotalFolders())
+	w("")
+	w("| 文件 | 目标文件夹 | 置信度 | 来源 |")
+	w("|------|------------|--------|------|")
+	folders := make([]string, 0, len(r.Plan.Actions))
+	for folder := range r.Plan.Actions {
+		folders = append(folders, folder)
+	}
+	sort.Strings(folders)
+	for _, folder := range folders {
+		for _, res := range r.Plan.Actions[folder] {
+			w("| %s | %s/ | %.0f%% | %s |", mdEscape(r.Plan.RelPath(res)), mdEscape(filepath.ToSlash(folder)), res.Confidence*100, res.Source)
+		}
+	}
+	if len(r.Plan.Review) > 0 {
+		w("")
+		w("### 待确认（%d 个）", len(r.Plan.Review))
+		w("")
+		for _, res := range r.Plan.Review {
+			w("- %s → %s/%s？（%.0f%%）", mdEscape(r.Plan.RelPath(res)), res.Category, res.Subcategory, res.Confidence*100)
+		}
+	}
+
+	if len(r.Duplicates) > 0 {
+		w("")
+		w("## 重复文件（%d 组，多占用 %s）", len(r.Duplicates), ui.FormatSize(r.WastedSize()))
+		for _, g := range r.Duplicates {
+			w("")
+			w("- %s × %d", ui.FormatSize(g[0].Size), len(g))
+			for _, f := range g {
+				w("  - %s", mdEscape(r.rel(f)))
+			}
+		}
+	}
+	if len(r.Huge) > 0 {
+		w("")
+		w("## 大文件（≥ %s）", ui.FormatSize(r.HugeSize))
+		w("")
+		for _, f := range r.Huge {
+			w("- %s（%s）", mdEscape(r.rel(f)), ui.FormatSize(f.Size))
+		}
+	}
+	if len(r.Stale) > 0 {
+		w("")
+		w("## 陈旧文件（%s 之前修改）", r.StaleBefore.Format("2006-01-02"))
+		w("")
+		for _, f := range r.Stale {
+			w("- %s（%s）", mdEscape(r.rel(f)), f.ModifiedTime.Format("2006-01-02"))
+		}
+	}
+	if len(r.Misnamed) > 0 {
+		w("")
+		w("## 扩展名与实际格式不符")
+		w("")
+		for _, m := range r.Misnamed {
+			w("- %s（实际为 %s）", mdEscape(r.rel(m.File)), m.Actual)
+		}
+	}
+	if len(r.Suspicious) > 0 {
+		w("")
+		w("## 可疑文件")
+		w("")
+		for _, f := range r.Suspicious {
+			w("- %s（%s）", mdEscape(r.rel(f)), f.Suspicious)
+		}
+	}
+
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
+
+// mdEscape 转义 Markdown 表格和列表中有特殊含义的字符
+func mdEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,135 @@
+// Package classifier 智能分类模块
+// batching.go - 按模型的历史耗时自动选择批大小
+//
+// 固定的 batch_size 很难兼顾所有模型：小模型一批 15 个文件只要几秒，
+// 请求次数成了瓶颈；大模型一批 15 个文件可能接近超时。根据 model_stats 中
+// 该模型每个文件的平均耗时，让每批的耗时接近目标值，慢模型再同时发送多批
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"fmt"
+	"time"
+)
+
+// ==================== 常量定义 ====================
+
+const (
+	MinBatchSize       = 5                // 自动调整的最小批大小
+	MaxBatchSize       = 50               // 自动调整的最大批大小
+	SlowBatchSize      = 10               // 批大小不超过此值的模型视为慢模型，同时发送多批
+	TargetBatchLatency = 20 * time.Second // 单批的目标耗时
+	TuneSamples        = 10               // 参考最近的统计记录数
+)
+
+// BatchPlan 本次分类使用的批大小和并行批次数
+type BatchPlan struct {
+	Size     int    // 每批文件数
+	Parallel int    // 同时发送的批次数
+	Auto     bool   // 是否由历史耗时自动选择
+	Reason   string // 选择理由（显示给用户）
+}
+
+// ==================== 批大小选择 ====================
+
+// batchPlan 选择本次分类的批大小
+// 用户固定了批大小（auto_batch 关闭）或模型没有历史数据时使用 batch_size，不并行；
+// 快速模式下批大小至少为 FastBatchSize
+func (c *Classifier) batchPlan() BatchPlan {
+	plan := BatchPlan{Size: c.cfg.BatchSize, Parallel: 1}
+	if plan.Size < 1 {
+		plan.Size = 1
+	}
+	perFile, samples := c.db.GetModelLatency(c.cfg.ActiveModel(), TuneSamples, MinBatchSize)
+	if c.cfg.AutoBatch && samples > 0 {
+		plan = c.latencyPlan(perFile, samples)
+	}
+	if c.fast && plan.Size < FastBatchSize {
+		c.raiseForFast(&plan, perFile)
+	}
+	return plan
+}
+
+// latencyPlan 按模型每个文件的平均耗时选择批大小，让每批的耗时接近目标值
+func (c *Classifier) latencyPlan(perFile float64, samples int) BatchPlan {
+	plan := BatchPlan{Parallel: 1}
+	target := c.batchLatencyLimit()
+
+	size := int(float64(target.Milliseconds()) / perFile)
+	if size < MinBatchSize {
+		size = MinBatchSize
+	}
+	if size > MaxBatchSize {
+		size = MaxBatchSize
+	}
+
+	plan.Reason = fmt.Sprintf("按最近 %d 次的耗时 %.0fms/文件", samples, perFile)
+	if limit := c.contextBatchLimit(); limit > 0 && size > limit {
+		size = limit
+		plan.Reason += "，受上下文窗口限制"
+	}
+
+	plan.Size, plan.Auto = size, true
+	if size <= SlowBatchSize && c.cfg.LLMParallel > 1 {
+		plan.Parallel = c.cfg.LLMParallel
+	}
+	return plan
+}
+
+// raiseForFast 快速模式下把批大小提高到 FastBatchSize
+// 不超过上下文窗口放得下的文件数；模型有历史耗时的，每批的耗时不超过单批超时的 1/3
+func (c *Classifier) raiseForFast(plan *BatchPlan, perFile float64) {
+	size := FastBatchSize
+	reason := "快速模式"
+	if perFile > 0 {
+		if limit := int(float64(c.batchTimeoutLimit().Milliseconds()) / perFile); limit < size {
+			size, reason = limit, "快速模式，受单批超时限制"
+		}
+	}
+	if limit := c.contextBatchLimit(); limit > 0 && size > limit {
+		size, reason = limit, "快速模式，受上下文窗口限制"
+	}
+	if size > plan.Size {
+		plan.Size, plan.Auto, plan.Reason = size, true, reason
+	}
+}
+
+// batchLatencyLimit 单批的目标耗时：TargetBatchLatency，且不超过单批超时的 1/3，留出重试余地
+func (c *Classifier) batchLatencyLimit() time.Duration {
+	if timeout := c.batchTimeoutLimit(); timeout > 0 && timeout < TargetBatchLatency {
+		return timeout
+	}
+	return TargetBatchLatency
+}
+
+// batchTimeoutLimit 单批超时的 1/3，未设置超时时为 0
+func (c *Classifier) batchTimeoutLimit() time.Duration {
+	return time.Duration(c.cfg.LLMTimeout) * time.Second / 3
+}
+
+// contextBatchLimit 当前模型的上下文窗口放得下的最大批大小（至少为 1），未知时为 0
+// 每个模型只读取一次模型信息，远程提供方和旧版本 Ollama 无法读取
+func (c *Classifier) contextBatchLimit() int {
+	model := c.cfg.ActiveModel()
+	if limit, ok := c.fit[model]; ok {
+		return limit
+	}
+	if c.fit == nil {
+		c.fit = make(map[string]int)
+	}
+
+	limit := 0
+	if info, err := c.llm.ShowModel(model); err == nil {
+		if fit := c.llm.EstimateFit(info, c.cfg.BatchSize); fit.Window > 0 {
+			limit = fit.MaxBatch
+			if limit < 1 {
+				limit = 1
+			}
+		}
+	}
+	c.fit[model] = limit
+	return limit
+}
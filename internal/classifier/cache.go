@@ -0,0 +1,197 @@
+// Package classifier 智能分类模块
+// cache.go - 精确匹配：同名且内容相同的文件最近分类过时直接沿用上次的分类，不做记忆打分和向量检索，
+// 重新整理部分已整理过的目录时几乎不用等待；进程内的 LRU 缓存让网页控制台、MCP 服务
+// 反复整理同一批文件时连分类历史也不必查询
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"filo/internal/scanner"
+	"filo/internal/taxonomy"
+)
+
+// cacheKey 精确匹配的键：文件名和内容哈希（哈希包含文件大小）
+type cacheKey struct {
+	name string
+	hash string
+}
+
+// cacheEntry 缓存的分类结果
+type cacheEntry struct {
+	key         cacheKey
+	category    string
+	subcategory string
+	confidence  float64
+	reasoning   string
+	at          time.Time // 分类时间，超过 exact_match_days 后失效
+}
+
+// resultCache 分类结果的 LRU 缓存，同一进程中的分类器共享
+type resultCache struct {
+	mu    sync.Mutex
+	order *list.List // 最近使用的在前
+	items map[cacheKey]*list.Element
+}
+
+// exactCache 进程内的分类结果缓存
+var exactCache = &resultCache{order: list.New(), items: make(map[cacheKey]*list.Element)}
+
+// get 读取未过期的缓存结果
+func (rc *resultCache) get(key cacheKey, maxAge time.Duration) (cacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	el, ok := rc.items[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	entry := el.Value.(cacheEntry)
+	if time.Since(entry.at) > maxAge {
+		rc.order.Remove(el)
+		delete(rc.items, key)
+		return cacheEntry{}, false
+	}
+	rc.order.MoveToFront(el)
+	return entry, true
+}
+
+// put 写入缓存，超出容量时淘汰最久未使用的结果；容量为 0 时不缓存
+func (rc *resultCache) put(entry cacheEntry, capacity int) {
+	if capacity <= 0 {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if el, ok := rc.items[entry.key]; ok {
+		el.Value = entry
+		rc.order.MoveToFront(el)
+	} else {
+		rc.items[entry.key] = rc.order.PushFront(entry)
+	}
+	for rc.order.Len() > capacity {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.items, oldest.Value.(cacheEntry).key)
+	}
+}
+
+// forget 删除文件的缓存结果（分类被纠正后调用）
+func (rc *resultCache) forget(key cacheKey) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if el, ok := rc.items[key]; ok {
+		rc.order.Remove(el)
+		delete(rc.items, key)
+	}
+}
+
+// exactKey 文件的精确匹配键，没有内容哈希时返回 false
+func exactKey(f scanner.FileInfo) (cacheKey, bool) {
+	return cacheKey{name: f.Name, hash: f.ContentHash}, f.ContentHash != ""
+}
+
+// exactMatches 查找最近分类过的同名同内容文件，返回 文件路径 -> 沿用的分类结果
+// 先查进程内缓存，再查分类历史；用户确认过的分类直接沿用，未确认的需要达到置信度阈值。
+// 计算出的内容哈希写回 files，查询记忆和学习时不再重复计算
+func (c *Classifier) exactMatches(files []scanner.FileInfo) map[string]Result {
+	days := c.cfg.ExactMatchDays
+	if days <= 0 || c.cfg.RulesOnly {
+		return nil
+	}
+	maxAge := time.Duration(days) * 24 * time.Hour
+	tax := taxonomy.Get()
+	hits := make(map[string]Result)
+	for i := range files {
+		f := &files[i]
+		if c.Interrupted() {
+			break
+		}
+		if f.IsDir || f.SameAs != "" || f.Suspicious != "" || tax.ShouldSkip(f.Extension) {
+			continue
+		}
+		if f.ContentHash == "" {
+			f.ContentHash = scanner.ContentHash(*f)
+		}
+		key, ok := exactKey(*f)
+		if !ok {
+			continue
+		}
+
+		entry, ok := exactCache.get(key, maxAge)
+		if !ok {
+			rec := c.db.FindRecentClassification(f.Name, f.ContentHash, days)
+			if rec == nil || (!rec.UserConfirmed && rec.Confidence < c.cfg.ConfidenceThresholdFor(rec.Category)) {
+				continue
+			}
+			entry = cacheEntry{
+				key:         key,
+				category:    rec.Category,
+				subcategory: rec.Subcategory,
+				confidence:  rec.Confidence,
+				reasoning:   "同一文件 " + rec.CreatedAt.Local().Format("2006-01-02") + " 分类过",
+				at:          rec.CreatedAt,
+			}
+			if rec.UserConfirmed {
+				entry.reasoning += "（已确认）"
+			}
+			exactCache.put(entry, c.cfg.ResultCacheSize)
+		}
+
+		category, subcategory := Normalize(entry.category, entry.subcategory)
+		r := Result{
+			FileInfo:    *f,
+			Category:    category,
+			Subcategory: subcategory,
+			Confidence:  entry.confidence,
+			Reasoning:   entry.reasoning,
+			Source:      "memory",
+		}
+		// 按已有文件夹整理时，沿用的分类没有对应的文件夹则照常分类
+		if c.folders != nil && !c.fitFolder(&r) {
+			continue
+		}
+		hits[f.Path] = r
+	}
+	return hits
+}
+
+// rememberResults 把本次达到阈值、没有分歧的分类结果写入进程内缓存
+// 不学习的来源（扩展名推断、隔离等）和关闭学习时不缓存，与分类历史保持一致；
+// 已缓存的结果（包括本次沿用的）保留原来的分类时间
+func (c *Classifier) rememberResults(results []Result) {
+	if c.cfg.ExactMatchDays <= 0 || c.cfg.ResultCacheSize <= 0 || !c.cfg.EnableLearning || c.cfg.RulesOnly {
+		return
+	}
+	maxAge := time.Duration(c.cfg.ExactMatchDays) * 24 * time.Hour
+	now := time.Now()
+	for _, r := range results {
+		key, ok := exactKey(r.FileInfo)
+		if !ok || !learnable(r.Source) || r.Conflict != nil || r.Confidence < c.cfg.ConfidenceThresholdFor(r.Category) {
+			continue
+		}
+		if _, cached := exactCache.get(key, maxAge); cached {
+			continue
+		}
+		exactCache.put(cacheEntry{
+			key:         key,
+			category:    r.Category,
+			subcategory: r.Subcategory,
+			confidence:  r.Confidence,
+			reasoning:   "同一文件 " + now.Format("2006-01-02") + " 分类过",
+			at:          now,
+		}, c.cfg.ResultCacheSize)
+	}
+}
+
+// forgetResult 分类被纠正后删除缓存的结果，之后按分类历史中纠正后的分类沿用
+func forgetResult(r Result) {
+	if key, ok := exactKey(r.FileInfo); ok {
+		exactCache.forget(key)
+	}
+}
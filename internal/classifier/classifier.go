@@ -11,6 +11,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
@@ -18,8 +19,13 @@ import (
 	"filo/internal/config"
 	"filo/internal/llm"
 	"filo/internal/memory"
+	"filo/internal/metrics"
+	"filo/internal/ocr"
+	"filo/internal/privacy"
+	"filo/internal/quarantine"
 	"filo/internal/scanner"
 	"filo/internal/storage"
+	"filo/internal/taxonomy"
 	"filo/internal/ui"
 )
 
@@ -30,11 +36,20 @@ import (
 type Result struct {
 	FileInfo    scanner.FileInfo // 文件信息
 	Category    string           // 主分类
-	Subcategory string           // 子分类
+	Subcategory string           // 子分类，多级分类时为第二级及以下的路径（如 客户A/合同/2024）
 	Confidence  float64          // 置信度（0-1）
 	Reasoning   string           // 分类理由
-	Source      string           // 来源: memory（记忆）, llm（AI推理）
+	Source      string           // 来源: memory（记忆）, llm（AI推理）, vision（看图分类）, screenshot（截图按应用归类）, quarantine（隔离）, rule（仅规则模式）, sidecar（导入的整理信息）
 	Keywords    []string         // 提取的关键词
+	Conflict    *Alternative     // 与结果矛盾的记忆建议，没有分歧时为 nil
+}
+
+// Timing 分类各阶段耗时
+type Timing struct {
+	Memory time.Duration      // 记忆查询总耗时
+	Stages memory.StageTiming // 记忆查询各阶段耗时
+	LLM    time.Duration      // LLM 分类耗时
+	Vision time.Duration      // 看图分类耗时
 }
 
 // Classifier 分类器
@@ -45,11 +60,27 @@ type Classifier struct {
 	cfg        *config.Config   // 配置
 	db         *storage.Database // 数据库（用于记录模型性能）
 	batchID    string           // 当前批次 ID
+	timing     Timing           // 各阶段耗时
+	plan       BatchPlan        // 最近一次 AI 分类使用的批大小
+	sink       chan<- Result    // 流水线模式下接收已完成的分类结果
+	ctx        context.Context  // 取消后不再发送新的请求，进行中的 AI 请求立即中断
+	learnMu    sync.Mutex       // 流水线模式下分类与执行同时学习，串行化写入
+	hints      map[string]*memory.Match // 未达到阈值的记忆建议（文件路径 -> 建议），用于发现分歧
+	plugins    map[string]Result        // 分类插件的结果（文件路径 -> 置信度最高的结果），AI 分类后据此比较
+	folders    map[string]string        // 按已有文件夹整理时可用的文件夹（比较键 -> 文件夹路径），nil 表示不限制
+	redactor   *privacy.Redactor        // 远程提供方的文件名脱敏，nil 表示不脱敏
+	etaPrompt  ETAPrompt                // 预计耗时过长时的询问方式，nil 表示不询问
+	parallel   int                      // 用户开启的并行批次数，0 表示按 batchPlan 选择
+	fit        map[string]int           // 各模型放得下上下文窗口的最大批大小（见 contextBatchLimit），0 表示未知
+	fast       bool                     // 本次分类使用快速模式（见 fast.go）
 	modelStats struct {         // 模型性能统计
 		StartTime     time.Time
 		TotalTimeMs   int64
 		FileCount     int
 		TotalConfidence float64
+		Saved         bool // 统计记录是否已写入数据库
+		Confirmed     int  // 写入前累计的确认数
+		Corrected     int  // 写入前累计的纠正数
 	}
 }
 
@@ -58,6 +89,12 @@ type Classifier struct {
 // NewClassifier 创建分类器
 // 初始化记忆系统和 LLM 客户端
 func NewClassifier() (*Classifier, error) {
+	// 向量只能以 JSON 存储，配置了其他后端时提示一次并照常使用 JSON
+	if cfg := config.Get(); cfg.VectorBackend != storage.VectorBackendJSON {
+		ui.Warning("向量存储后端 %s 不可用（内置的 SQLite 驱动无法加载扩展），使用 %s", cfg.VectorBackend, storage.VectorBackendJSON)
+		cfg.VectorBackend = storage.VectorBackendJSON
+	}
+
 	mem, err := memory.NewMemory()
 	if err != nil {
 		return nil, err
@@ -72,24 +109,48 @@ func NewClassifier() (*Classifier, error) {
 	// 生成批次 ID
 	batchID := time.Now().Format("20060102_150405")
 
+	// 首次使用时写入内置的扩展名默认分类
+	db.SeedExtensionDefaults(BuiltinExtensions())
+
+	// 记录每次模型调用的 token 数和耗时，供 filo stats --llm 查看
+	client := llm.NewClient()
+	client.SetObserver(func(call llm.CallRecord) {
+		rec := storage.LLMCall{
+			BatchID:        batchID,
+			Model:          call.Model,
+			Kind:           call.Kind,
+			FileCount:      call.Files,
+			PromptTokens:   call.PromptTokens,
+			ResponseTokens: call.ResponseTokens,
+			LatencyMs:      call.Latency.Milliseconds(),
+		}
+		if call.Err != nil {
+			rec.Error = call.Err.Error()
+		}
+		db.AddLLMCall(rec)
+		metrics.ObserveLLM(call.Model, call.Kind, call.Latency, call.Err != nil)
+	})
+
+	// 远程提供方：文件名中的个人信息在发送前替换为占位符
+	var redactor *privacy.Redactor
+	if client.IsRemote() && config.Get().RedactFilenames {
+		redactor = privacy.NewRedactor()
+	}
+
 	return &Classifier{
-		memory:  mem,
-		llm:     llm.NewClient(),
-		cfg:     config.Get(),
-		db:      db,
-		batchID: batchID,
+		memory:   mem,
+		llm:      client,
+		cfg:      config.Get(),
+		db:       db,
+		batchID:  batchID,
+		ctx:      context.Background(),
+		redactor: redactor,
 	}, nil
 }
 
 // Close 关闭分类器
 // 释放记忆系统和数据库资源
 func (c *Classifier) Close() error {
-	// 保存模型性能统计
-	if c.modelStats.FileCount > 0 {
-		avgConfidence := c.modelStats.TotalConfidence / float64(c.modelStats.FileCount)
-		c.db.AddModelStats(c.cfg.LLMModel, c.batchID, c.modelStats.FileCount, c.modelStats.TotalTimeMs, avgConfidence)
-	}
-
 	c.db.Close()
 	return c.memory.Close()
 }
@@ -99,6 +160,28 @@ func (c *Classifier) GetBatchID() string {
 	return c.batchID
 }
 
+// DebugLLM 保存本次整理中每次模型调用的提示词和原始响应（--debug-llm）
+// 文件位于 ~/.filo/debug/<批次ID>/，批次 ID 与操作日志相同，便于对照分类结果
+func (c *Classifier) DebugLLM() (*llm.Transcript, error) {
+	t, err := llm.NewTranscript(c.batchID)
+	if err != nil {
+		return nil, err
+	}
+	c.llm.SetTranscript(t)
+	return t, nil
+}
+
+// SetContext 设置取消整理用的上下文（如 Ctrl-C）
+// 取消后分类立即结束并返回已完成的结果，未分类的文件不在结果中；执行整理时据此停止移动文件
+func (c *Classifier) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// Interrupted 整理是否已被取消
+func (c *Classifier) Interrupted() bool {
+	return c.ctx.Err() != nil
+}
+
 // ==================== 核心分类方法 ====================
 
 // Classify 分类文件列表
@@ -109,48 +192,263 @@ func (c *Classifier) GetBatchID() string {
 func (c *Classifier) Classify(files []scanner.FileInfo, verbose bool) ([]Result, error) {
 	var memoryResults []Result  // 记忆命中的结果
 	var llmNeeded []scanner.FileInfo // 需要 LLM 分类的文件
+	var screenshots []scanner.FileInfo // 按应用归类的截图
 
 	ui.Title("🧠", "检查学习记忆")
+	c.useFast(files)
 
 	// ========== 阶段1: 记忆查询 ==========
+	memStart := time.Now()
+	var bar *progressbar.ProgressBar
+	if !verbose && !ui.IsQuiet() {
+		// 详细模式逐行输出结果，静默模式不输出，都不显示进度条
+		bar = newProgressBar(len(files), "  查询中")
+	}
+
+	// 同名同内容的文件最近分类过时直接沿用，这些文件不再生成向量
+	exact := c.exactMatches(files)
+
+	// 先批量生成所有文件名的向量（Ollama 嵌入器一次请求处理几十个文件名），快速模式不做向量匹配
+	if !c.cfg.RulesOnly && !c.fast {
+		names := make([]string, 0, len(files))
+		for _, f := range files {
+			if _, ok := exact[f.Path]; !ok && !f.IsDir && !c.isScreenshot(f) {
+				names = append(names, f.Name)
+			}
+		}
+		c.memory.Prefetch(names)
+	}
+
+	files = c.markBursts(files) // 标记批量下载，作为上下文提示并随分类记录保存
+	tax := taxonomy.Get()
+	skipped, suspicious, quarantined, unmatched, cloud, same, reused := 0, 0, 0, 0, 0, 0, 0
 	for _, f := range files {
+		if c.Interrupted() {
+			break // 已取消，剩余文件不分类
+		}
+		if bar != nil {
+			bar.Add(1)
+		}
 		if f.IsDir {
 			continue // 跳过目录
 		}
 
-		// 查询记忆系统
-		match := c.memory.Query(f.Name)
-		if match != nil && match.Confidence >= c.cfg.SimilarityThreshold {
-			// 记忆命中，添加到结果
+		// 与已扫描的文件是同一个文件（硬链接）：只整理第一个，避免重复移动
+		if f.SameAs != "" {
+			same++
+			continue
+		}
+
+		// 仅在云端的占位文件：cloud_files 为 skip 时保持原位
+		if f.Cloud != "" && c.cfg.CloudFiles == scanner.CloudFilesSkip {
+			cloud++
+			continue
+		}
+
+		// 隔离模式：可执行文件、脚本和安装包归入隔离区（白名单中的照常分类）
+		if c.cfg.Quarantine {
+			if r, ok := c.quarantineFile(f, verbose); ok {
+				quarantined++
+				memoryResults = append(memoryResults, r)
+				continue
+			}
+		}
+
+		// 按整理偏好留在原处的文件（如安装包、压缩包）
+		if tax.ShouldSkip(f.Extension) {
+			skipped++
+			continue
+		}
+
+		// 可疑文件（未完成下载、空文件、损坏）不交给 AI 猜测
+		if f.Suspicious != "" {
+			if c.cfg.SuspiciousFiles == "skip" {
+				skipped++
+				continue
+			}
+			suspicious++
 			memoryResults = append(memoryResults, Result{
 				FileInfo:    f,
-				Category:    match.Category,
-				Subcategory: match.Subcategory,
+				Category:    scanner.SuspiciousCategory,
+				Subcategory: scanner.SuspiciousSubcategory,
+				Confidence:  1.0,
+				Reasoning:   f.Suspicious,
+				Source:      "scanner",
+			})
+			if verbose {
+				ui.Warning("%s → %s/%s (%s)", f.Name, scanner.SuspiciousCategory, scanner.SuspiciousSubcategory, f.Suspicious)
+			}
+			continue
+		}
+
+		// 下载来源匹配来源规则：按用户指定的分类整理，优先于文件名的规则和记忆
+		if hit, ok := c.MatchOrigin(f); ok {
+			memoryResults = append(memoryResults, hit)
+			if verbose {
+				ui.Success("%s → %s/%s (%s)", f.Name, hit.Category, hit.Subcategory, hit.Reasoning)
+			}
+			continue
+		}
+
+		// 仅规则模式：只按规则分类，没有匹配规则的文件保持原位
+		if c.cfg.RulesOnly {
+			match := c.memory.RuleOnly(f.Name)
+			if match == nil {
+				unmatched++
+				continue
+			}
+			category, subcategory := Normalize(match.Category, match.Subcategory)
+			memoryResults = append(memoryResults, Result{
+				FileInfo:    f,
+				Category:    category,
+				Subcategory: subcategory,
 				Confidence:  match.Confidence,
 				Reasoning:   match.Reasoning,
-				Source:      "memory",
+				Source:      "rule",
 			})
+			if verbose {
+				ui.Success("%s → %s/%s (%s)", f.Name, category, subcategory, match.Reasoning)
+			}
+			continue
+		}
+
+		// 最近分类过的同一文件：沿用上次的分类，不查询记忆
+		if hit, ok := exact[f.Path]; ok {
+			hit.FileInfo = f
+			reused++
+			memoryResults = append(memoryResults, hit)
+			if verbose {
+				ui.Success("%s → %s (%s)", f.Name, hit.Category, hit.Reasoning)
+			}
+			continue
+		}
+
+		// 截图按应用归入 图片/截图 的子文件夹，不查询记忆
+		if c.isScreenshot(f) {
+			screenshots = append(screenshots, f)
+			continue
+		}
+
+		// 查询记忆系统（内容哈希随结果保存，学习时一并记录）
+		if f.ContentHash == "" {
+			f.ContentHash = scanner.ContentHash(f)
+		}
+		match := c.memory.Query(f.Name, f.ParentDir(), f.ContentHash)
+		var hit Result
+		if match != nil && match.Confidence >= c.cfg.SimilarityThresholdFor(match.Category) {
+			// 记忆命中（早期学习的分类名可能未经规范化）
+			category, subcategory := Normalize(match.Category, match.Subcategory)
+			hit = Result{
+				FileInfo:    f,
+				Category:    category,
+				Subcategory: subcategory,
+				Confidence:  match.Confidence,
+				Reasoning:   match.Reasoning,
+				Source:      "memory",
+			}
+		}
+		// 按已有文件夹整理时，记忆的分类没有对应的文件夹则交给 AI 从已有文件夹中选择
+		if hit.Source != "" && (c.folders == nil || c.fitFolder(&hit)) {
+			memoryResults = append(memoryResults, hit)
 
 			if verbose {
-				ui.Success("%s → %s (%s)", f.Name, match.Category, match.Source)
+				ui.Success("%s → %s (%s)", f.Name, hit.Category, match.Source)
 			}
 		} else {
 			// 记忆未命中，加入待分类队列
+			c.rememberHint(f)
 			llmNeeded = append(llmNeeded, f)
 		}
 	}
 
-	if len(memoryResults) > 0 {
-		ui.Success("从记忆获取 %d 个分类", len(memoryResults))
+	if bar != nil {
+		fmt.Println() // 进度条结束后换行
 	}
+	c.timing.Memory = time.Since(memStart)
+	c.timing.Stages = c.memory.Timing()
+	ui.Dim("耗时: %.1fs (规则 %.1fs · 向量 %.1fs · 历史 %.1fs)",
+		c.timing.Memory.Seconds(), c.timing.Stages.Rules.Seconds(),
+		c.timing.Stages.Vectors.Seconds(), c.timing.Stages.History.Seconds())
+
+	if reused > 0 {
+		ui.Success("%d 个文件最近分类过，沿用上次的分类", reused)
+	}
+	if n := len(memoryResults) - suspicious - quarantined - reused; n > 0 {
+		if c.cfg.RulesOnly {
+			ui.Success("按规则分类 %d 个文件", n)
+		} else {
+			ui.Success("从记忆获取 %d 个分类", n)
+		}
+	}
+	if unmatched > 0 {
+		ui.Dim("%d 个文件没有匹配的规则，保持原位", unmatched)
+	}
+	if suspicious > 0 {
+		ui.Warning("%d 个可疑文件归入 %s/%s", suspicious, scanner.SuspiciousCategory, scanner.SuspiciousSubcategory)
+	}
+	if quarantined > 0 {
+		ui.Warning("%d 个可执行文件、脚本或安装包归入 %s（已记录 SHA-256）", quarantined, quarantine.Category)
+	}
+	if skipped > 0 {
+		ui.Dim("按整理偏好跳过 %d 个文件", skipped)
+	}
+	if cloud > 0 {
+		ui.Dim("%d 个云盘文件未下载到本机，保持原位", cloud)
+	}
+	if same > 0 {
+		ui.Dim("%d 个文件与已扫描的文件是同一个文件（硬链接），保持原位", same)
+	}
+	memoryResults = append(memoryResults, c.classifyScreenshots(screenshots, verbose)...)
+
+	// 上下文提示：同批下载和时间段加分后达到阈值的未命中文件不再交给 AI
+	memoryResults, llmNeeded = c.applyContext(memoryResults, llmNeeded, verbose)
+
+	// 分类插件：与记忆结果比较置信度，达到阈值的未命中文件不再交给 AI
+	memoryResults, llmNeeded = c.classifyWithPlugins(memoryResults, llmNeeded, verbose)
+	c.fitResults(memoryResults)
+	c.emit(memoryResults)
 
 	// ========== 阶段2: LLM 分类 ==========
 	var llmResults []Result
-	if len(llmNeeded) > 0 {
-		// 显示当前使用的模型
-		ui.Title("🤖", fmt.Sprintf("AI分类 %d 个文件", len(llmNeeded)))
-		ui.Info("模型: %s", ui.Bold(c.cfg.LLMModel))
 
+	// 文件名不含信息的图片先看图分类，识别失败的再按文件名分类
+	if len(llmNeeded) > 0 && c.cfg.VisionClassify && !c.cfg.Offline && !c.Interrupted() {
+		var images []scanner.FileInfo
+		images, llmNeeded = splitVisionFiles(llmNeeded)
+		if len(images) > 0 {
+			visionResults, failed := c.classifyWithVision(images, verbose)
+			for i := range visionResults {
+				c.preferPlugin(&visionResults[i])
+			}
+			c.fitResults(visionResults)
+			c.emit(visionResults)
+			memoryResults = append(memoryResults, visionResults...)
+			llmNeeded = append(llmNeeded, failed...)
+		}
+	}
+
+	if len(llmNeeded) > 0 && c.cfg.Offline {
+		// 离线模式：不调用 LLM，使用低置信度记忆和扩展名推断
+		ui.Title("📴", fmt.Sprintf("离线分类 %d 个文件", len(llmNeeded)))
+		offlineResults := c.classifyOffline(llmNeeded)
+		for i := range offlineResults {
+			c.preferPlugin(&offlineResults[i])
+		}
+		c.fitResults(offlineResults)
+		c.emit(offlineResults)
+		memoryResults = append(memoryResults, offlineResults...)
+		llmNeeded = nil
+	}
+	if len(llmNeeded) > 0 && !c.Interrupted() {
+		// 显示当前使用的模型和预计耗时
+		ui.Title("🤖", fmt.Sprintf("AI分类 %d 个文件", len(llmNeeded)))
+		ui.Info("模型: %s", ui.Bold(c.cfg.ActiveModel()))
+		if !c.checkETA(len(llmNeeded)) {
+			ui.Warning("跳过 AI 分类，%d 个文件保持原位", len(llmNeeded))
+			llmNeeded = nil
+		}
+	}
+	if len(llmNeeded) > 0 && !c.Interrupted() {
 		// 获取已学习的规则供 LLM 参考
 		rules := c.memory.GetLearnedRules(30)
 
@@ -159,13 +457,14 @@ func (c *Classifier) Classify(files []scanner.FileInfo, verbose bool) ([]Result,
 
 		// 调用 LLM 进行分类
 		var err error
-		llmResults, err = c.classifyWithLLM(llmNeeded, rules, verbose)
+		llmResults, err = c.classifyWithLLM(llmNeeded, rules, verbose, true)
 		if err != nil {
 			ui.Warning("部分文件分类失败: %v", err)
 		}
 
 		// 记录结束时间和统计
 		elapsed := time.Since(c.modelStats.StartTime)
+		c.timing.LLM = elapsed
 		c.modelStats.TotalTimeMs = elapsed.Milliseconds()
 		c.modelStats.FileCount = len(llmResults)
 
@@ -180,13 +479,29 @@ func (c *Classifier) Classify(files []scanner.FileInfo, verbose bool) ([]Result,
 			avgConf := c.modelStats.TotalConfidence / float64(len(llmResults))
 			ui.Dim("耗时: %.1fs (%.0fms/文件) | 平均置信度: %.0f%%", 
 				elapsed.Seconds(), avgTime, avgConf*100)
+
+			// 保存模型性能统计（执行时确认、事后纠正时据此更新准确度）
+			c.learnMu.Lock()
+			c.db.AddModelStats(c.cfg.ActiveModel(), c.batchID, len(llmResults), c.modelStats.TotalTimeMs, avgConf, c.plan.Size, c.plan.Parallel)
+			c.modelStats.Saved = true
+			if c.modelStats.Confirmed+c.modelStats.Corrected > 0 {
+				c.db.UpdateModelAccuracy(c.batchID, c.modelStats.Confirmed, c.modelStats.Corrected)
+			}
+			c.learnMu.Unlock()
 		}
 
-		// 学习 LLM 分类结果
+		// 学习 LLM 分类结果（分类失败后按扩展名推断的结果不学习）
 		if c.cfg.EnableLearning {
+			items := make([]memory.LearnItem, 0, len(llmResults))
 			for _, r := range llmResults {
-				c.memory.Learn(r.FileInfo.Name, r.Category, r.Subcategory, "llm", r.Confidence, false)
+				if r.Source != "llm" {
+					continue
+				}
+				items = append(items, learnItem(r, false))
 			}
+			c.learnMu.Lock()
+			c.memory.LearnBatch(items)
+			c.learnMu.Unlock()
 		}
 	}
 
@@ -202,18 +517,217 @@ func (c *Classifier) Classify(files []scanner.FileInfo, verbose bool) ([]Result,
 		return order[results[i].FileInfo.Path] < order[results[j].FileInfo.Path]
 	})
 
+	c.rememberResults(results)
+	c.saveRunStats(results, time.Since(memStart))
 	return results, nil
 }
 
+// saveRunStats 记录本次分类的运行摘要（记忆命中率、平均置信度、各来源文件数），用于查看学习趋势
+func (c *Classifier) saveRunStats(results []Result, elapsed time.Duration) {
+	if len(results) == 0 {
+		return
+	}
+	run := storage.RunStats{
+		BatchID:   c.batchID,
+		FileCount: len(results),
+		Partial:   c.Interrupted(),
+		Sources:   make(map[string]int),
+		Duration:  elapsed,
+	}
+	if !c.cfg.Offline && !c.cfg.RulesOnly {
+		run.Model = c.cfg.ActiveModel()
+	}
+	total := 0.0
+	for _, r := range results {
+		run.Sources[r.Source]++
+		switch r.Source {
+		case "memory", "rule":
+			run.MemoryHits++
+		case "llm":
+			run.LLMCount++
+		}
+		total += r.Confidence
+	}
+	run.AvgConfidence = total / float64(len(results))
+	c.db.AddRunStats(run)
+	metrics.ObserveClassification(run.Sources, run.MemoryHits)
+}
+
+// ClassifyStream 分类文件列表，每完成一部分就把结果发送到 out
+// 记忆命中的结果在记忆查询结束后立即发送，AI 分类的结果按批次发送；
+// 分类结束后关闭 out。用于流水线模式：执行端在 AI 分类进行时就开始移动文件
+func (c *Classifier) ClassifyStream(files []scanner.FileInfo, verbose bool, out chan<- Result) error {
+	c.sink = out
+	defer func() {
+		c.sink = nil
+		close(out)
+	}()
+	_, err := c.Classify(files, verbose)
+	return err
+}
+
+// emit 流水线模式下发送已完成的分类结果
+func (c *Classifier) emit(results []Result) {
+	if c.sink == nil {
+		return
+	}
+	for _, r := range results {
+		c.sink <- r
+	}
+}
+
+// ClassifyWithLLMOnly 只使用 LLM 分类文件
+// 跳过记忆系统，不学习结果，也不计入本分类器的模型统计
+// 用于模型对比评测，返回结果和耗时
+func (c *Classifier) ClassifyWithLLMOnly(files []scanner.FileInfo) ([]Result, time.Duration) {
+	start := time.Now()
+	results, _ := c.classifyWithLLM(files, c.memory.GetLearnedRules(30), false, false)
+	return results, time.Since(start)
+}
+
+// GetBatchPlan 获取最近一次 AI 分类使用的批大小和并行批次数
+func (c *Classifier) GetBatchPlan() BatchPlan {
+	return c.plan
+}
+
 // classifyWithLLM 使用 LLM 批量分类文件
-// 将文件分批发送给 LLM，显示进度条
-func (c *Classifier) classifyWithLLM(files []scanner.FileInfo, rules []map[string]string, verbose bool) ([]Result, error) {
-	var results []Result
-	batchSize := c.cfg.BatchSize // 每批处理的文件数
+// 将文件分批发送给 LLM，显示进度条；批大小见 batchPlan，慢模型同时发送多批
+// fallback 为 true 时，分类失败或判断为「未分类」的文件改用扩展名默认分类
+func (c *Classifier) classifyWithLLM(files []scanner.FileInfo, rules []map[string]string, verbose, fallback bool) ([]Result, error) {
+	c.plan = c.batchPlan()
+	if c.parallel > c.plan.Parallel {
+		c.plan.Parallel = c.parallel
+	}
+	if c.plan.Auto {
+		ui.Dim("批大小: %d 个文件 × %d 路并行（%s）", c.plan.Size, c.plan.Parallel, c.plan.Reason)
+	}
+
+	// 分批
+	var batches [][]scanner.FileInfo
+	for i := 0; i < len(files); i += c.plan.Size {
+		end := i + c.plan.Size
+		if end > len(files) {
+			end = len(files)
+		}
+		batches = append(batches, files[i:end])
+	}
 
 	// 创建进度条
-	bar := progressbar.NewOptions(len(files),
-		progressbar.OptionSetDescription("  分类中"),
+	bar := newProgressBar(len(files), "  分类中")
+
+	// 最多同时处理 Parallel 批，结果按批次顺序合并
+	batchResults := make([][]Result, len(batches))
+	sem := make(chan struct{}, c.plan.Parallel)
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		sem <- struct{}{}
+		if c.Interrupted() {
+			<-sem
+			break // 已取消，不再发送剩余批次
+		}
+		wg.Add(1)
+		go func(i int, batch []scanner.FileInfo) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results := c.classifyBatch(batch, rules, verbose)
+			if results == nil && c.Interrupted() {
+				return // 已取消，这批文件未分类
+			}
+			if fallback {
+				for j := range results {
+					results[j] = c.extensionFallback(results[j])
+				}
+			}
+			for j := range results {
+				c.preferPlugin(&results[j]) // 插件更有把握时采用插件的结果
+				c.markConflict(&results[j])
+			}
+			c.fitResults(results)
+			c.emit(results)
+			batchResults[i] = results
+			bar.Add(len(batch)) // 更新进度条
+		}(i, batch)
+	}
+	wg.Wait()
+
+	var results []Result
+	for _, r := range batchResults {
+		results = append(results, r...)
+	}
+
+	if !ui.IsQuiet() {
+		fmt.Println() // 进度条结束后换行
+	}
+	return results, nil
+}
+
+// classifyBatch 分类一批文件
+// 按重试策略调用 LLM；多次重试仍超时时将批次对半拆分后分别重试，
+// 避免一个慢批次导致整批文件分类失败
+func (c *Classifier) classifyBatch(batch []scanner.FileInfo, rules []map[string]string, verbose bool) []Result {
+	// 准备批次数据
+	batchData := make([]map[string]interface{}, len(batch))
+	for j, f := range batch {
+		batchData[j] = c.fileData(f)
+	}
+
+	// 调用 LLM API（带超时和重试）
+	resp, err := c.llm.ClassifyFilesWithRetry(c.ctx, batchData, rules)
+	c.redactor.RestoreMap(resp)
+
+	// 已取消：这批文件不算分类失败，留在原处
+	if err != nil && c.Interrupted() {
+		return nil
+	}
+
+	// 超时且批次可拆分：对半拆分后重试
+	if err != nil && llm.IsTimeout(err) && len(batch) > 1 {
+		mid := len(batch) / 2
+		if verbose {
+			fmt.Println()
+			ui.Warning("批次超时，拆分为 %d + %d 个文件重试", mid, len(batch)-mid)
+		}
+		return append(c.classifyBatch(batch[:mid], rules, verbose), c.classifyBatch(batch[mid:], rules, verbose)...)
+	}
+
+	var results []Result
+	if err != nil {
+		// LLM 调用失败，使用默认分类
+		for _, f := range batch {
+			results = append(results, Result{
+				FileInfo:    f,
+				Category:    "未分类",
+				Subcategory: "其他",
+				Confidence:  0,
+				Reasoning:   fmt.Sprintf("分类失败: %v", err),
+				Source:      "error",
+			})
+		}
+		return results
+	}
+
+	// 解析 LLM 返回的分类结果
+	classifications, _ := resp["classifications"].([]interface{})
+	for j, cls := range classifications {
+		if j >= len(batch) {
+			break
+		}
+		clsMap, _ := cls.(map[string]interface{})
+		if clsMap == nil {
+			continue
+		}
+
+		results = append(results, toResult(batch[j], clsMap))
+	}
+	return results
+}
+
+// newProgressBar 创建统一样式的进度条
+func newProgressBar(total int, desc string) *progressbar.ProgressBar {
+	return progressbar.NewOptions(total,
+		progressbar.OptionSetDescription(desc),
 		progressbar.OptionSetTheme(progressbar.Theme{
 			Saucer:        "█",
 			SaucerHead:    "█",
@@ -222,95 +736,193 @@ func (c *Classifier) classifyWithLLM(files []scanner.FileInfo, rules []map[strin
 			BarEnd:        "]",
 		}),
 		progressbar.OptionShowCount(),
+		progressbar.OptionSetVisibility(!ui.IsQuiet()),
 	)
+}
 
-	// 分批处理
-	for i := 0; i < len(files); i += batchSize {
-		end := i + batchSize
-		if end > len(files) {
-			end = len(files)
-		}
-
-		batch := files[i:end]
+// GetTiming 获取本次分类各阶段的耗时
+func (c *Classifier) GetTiming() Timing {
+	return c.timing
+}
 
-		// 准备批次数据
-		batchData := make([]map[string]interface{}, len(batch))
-		for j, f := range batch {
-			batchData[j] = map[string]interface{}{
-				"name":      f.Name,
-				"extension": f.Extension,
-				"size":      f.Size,
-			}
-		}
+// Explain 解释单个文件的分类过程
+// 返回记忆系统各匹配方式的得分；useLLM 为 true 时额外调用 LLM 给出分类
+// 不移动文件，也不学习结果
+func (c *Classifier) Explain(f scanner.FileInfo, useLLM bool) (*memory.Explanation, *Result, error) {
+	if f.ContentHash == "" {
+		f.ContentHash = scanner.ContentHash(f)
+	}
+	exp := c.memory.Explain(f.Name, f.ParentDir(), f.ContentHash)
+	if !useLLM {
+		return exp, nil, nil
+	}
 
-		// 调用 LLM API（带超时）
-		ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-		resp, err := c.llm.ClassifyFiles(ctx, batchData, rules)
-		cancel()
+	rules := c.memory.GetLearnedRules(30)
+	ctx, cancel := context.WithTimeout(context.Background(), c.llm.Policy().Timeout)
+	defer cancel()
 
-		if err != nil {
-			// LLM 调用失败，使用默认分类
-			for _, f := range batch {
-				results = append(results, Result{
-					FileInfo:    f,
-					Category:    "未分类",
-					Subcategory: "其他",
-					Confidence:  0,
-					Reasoning:   fmt.Sprintf("分类失败: %v", err),
-					Source:      "error",
-				})
-			}
-		} else {
-			// 解析 LLM 返回的分类结果
-			classifications, _ := resp["classifications"].([]interface{})
-			for j, cls := range classifications {
-				if j >= len(batch) {
-					break
-				}
-				clsMap, _ := cls.(map[string]interface{})
-				if clsMap == nil {
-					continue
-				}
+	resp, err := c.llm.ClassifyFiles(ctx, []map[string]interface{}{c.fileData(f)}, rules)
+	if err != nil {
+		return exp, nil, err
+	}
+	c.redactor.RestoreMap(resp)
+	classifications, _ := resp["classifications"].([]interface{})
+	if len(classifications) == 0 {
+		return exp, nil, fmt.Errorf("模型未返回分类结果")
+	}
+	clsMap, _ := classifications[0].(map[string]interface{})
+	if clsMap == nil {
+		return exp, nil, fmt.Errorf("模型返回格式错误")
+	}
+	r := toResult(f, clsMap)
+	return exp, &r, nil
+}
 
-				results = append(results, Result{
-					FileInfo:    batch[j],
-					Category:    getString(clsMap, "category", "未分类"),
-					Subcategory: getString(clsMap, "subcategory", "其他"),
-					Confidence:  getFloat(clsMap, "confidence", 0.5),
-					Reasoning:   getString(clsMap, "reasoning", ""),
-					Source:      "llm",
-					Keywords:    getStringSlice(clsMap, "keywords"),
-				})
-			}
+// SuggestCategories 列出修改分类时的候选分类
+// 从历史记录中已有的分类里选择，按与文件名的相似度排序，不包含文件当前的分类
+func (c *Classifier) SuggestCategories(r Result, limit int) []memory.Suggestion {
+	var list []memory.Suggestion
+	for _, s := range c.memory.SuggestCategories(r.FileInfo.Name, limit+1) {
+		if s.Category == r.Category && s.Subcategory == r.Subcategory {
+			continue
 		}
-
-		bar.Add(len(batch)) // 更新进度条
+		list = append(list, s)
 	}
-
-	fmt.Println() // 进度条结束后换行
-	return results, nil
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list
 }
 
 // ==================== 学习方法 ====================
 
+// learnable 该来源的分类结果是否参与学习
+// 扫描器判定的可疑文件、扩展名推断、看图分类、隔离和分类插件的结果与文件名无关，学习会产生错误的关键词规则；
+// 仅规则模式的结果不学习，保持规则库不变，结果才能复现
+func learnable(source string) bool {
+	return source != "scanner" && source != "extension" && source != "vision" && source != "quarantine" && source != "rule" &&
+		source != SourcePlugin && source != SourceScreenshot
+}
+
 // Confirm 确认分类
 // 用户确认后调用，将分类结果标记为已确认并学习规则
 func (c *Classifier) Confirm(r Result) {
-	c.memory.Learn(r.FileInfo.Name, r.Category, r.Subcategory, r.Source, r.Confidence, true)
+	if !learnable(r.Source) {
+		return
+	}
+	c.learnMu.Lock()
+	defer c.learnMu.Unlock()
+	c.memory.LearnBatch([]memory.LearnItem{learnItem(r, true)})
 	// 更新模型准确度（仅 LLM 分类需要统计）
 	if r.Source == "llm" {
-		c.db.UpdateModelAccuracy(c.batchID, 1, 0)
+		c.updateAccuracy(1, 0)
+	}
+}
+
+// ConfirmAll 批量确认分类
+// 与逐个调用 Confirm 的效果相同，但所有写入合并为少量事务
+func (c *Classifier) ConfirmAll(results []Result) {
+	items := make([]memory.LearnItem, 0, len(results))
+	llmCount := 0
+	for _, r := range results {
+		if !learnable(r.Source) {
+			continue
+		}
+		items = append(items, learnItem(r, true))
+		if r.Source == "llm" {
+			llmCount++
+		}
+	}
+	c.learnMu.Lock()
+	defer c.learnMu.Unlock()
+	c.memory.LearnBatch(items)
+	// 更新模型准确度（仅 LLM 分类需要统计）
+	if llmCount > 0 {
+		c.updateAccuracy(llmCount, 0)
+	}
+}
+
+// learnItem 将分类结果转换为学习记录，连同文件修改时间和所属的批量下载
+func learnItem(r Result, confirmed bool) memory.LearnItem {
+	return memory.LearnItem{
+		Filename:    r.FileInfo.Name,
+		ParentDir:   r.FileInfo.ParentDir(),
+		ContentHash: r.FileInfo.ContentHash,
+		Category:    r.Category,
+		Subcategory: r.Subcategory,
+		Source:      r.Source,
+		Confidence:  r.Confidence,
+		Confirmed:   confirmed,
+		Modified:    r.FileInfo.ModifiedTime,
+		Burst:       r.FileInfo.Burst,
 	}
 }
 
 // Correct 纠正分类
-// 用户修改分类后调用，学习纠正后的结果
+// 用户修改分类后调用，学习纠正后的结果（调用方应先用 Normalize 规范化新分类）
 func (c *Classifier) Correct(r Result, newCat, newSub string) {
-	c.memory.LearnFromCorrection(r.FileInfo.Name, r.Category, newCat, r.Subcategory, newSub)
+	newCat, newSub = Normalize(newCat, newSub)
+	c.learnMu.Lock()
+	defer c.learnMu.Unlock()
+	c.memory.LearnFromCorrection(r.FileInfo.Name, r.FileInfo.ParentDir(), r.FileInfo.ContentHash, r.Category, newCat, r.Subcategory, newSub)
+	forgetResult(r)
 	// 更新模型准确度（仅 LLM 分类需要统计）
 	if r.Source == "llm" {
-		c.db.UpdateModelAccuracy(c.batchID, 0, 1)
+		c.updateAccuracy(0, 1)
 	}
+	c.db.AddRunCorrection(c.batchID)
+}
+
+// updateAccuracy 更新本批次的模型准确度（调用方持有 learnMu）
+// 模型统计在 AI 分类结束后才写入，流水线模式下此前的确认先在内存中累计
+func (c *Classifier) updateAccuracy(confirmed, corrected int) {
+	if !c.modelStats.Saved {
+		c.modelStats.Confirmed += confirmed
+		c.modelStats.Corrected += corrected
+		return
+	}
+	c.db.UpdateModelAccuracy(c.batchID, confirmed, corrected)
+}
+
+// CorrectPast 事后纠正已执行批次中的分类
+// 学习纠正结果（纠正优先级的规则并记录反馈）；AI 分类的文件在执行时已计为确认，
+// 改为计入该批次的纠正数
+func (c *Classifier) CorrectPast(batchID string, r Result, newCat, newSub string) {
+	newCat, newSub = Normalize(newCat, newSub)
+	c.learnMu.Lock()
+	defer c.learnMu.Unlock()
+	c.memory.LearnFromCorrection(r.FileInfo.Name, r.FileInfo.ParentDir(), r.FileInfo.ContentHash, r.Category, newCat, r.Subcategory, newSub)
+	forgetResult(r)
+	if r.Source == "llm" {
+		c.db.UpdateModelAccuracy(batchID, -1, 1)
+	}
+	c.db.AddRunCorrection(batchID)
+}
+
+// ResolveReview 将待确认队列中的决定反馈给学习和入队批次的统计
+// 入队的文件整理时没有确认，AI 的分类不计入准确度：接受建议计为确认（归档时再学习），
+// 改为其他分类（含采用记忆的建议）时立即学习纠正结果，计为纠正
+//
+// 参数:
+//   - batchID: 入队时的批次 ID
+//   - r: 建议的分类结果
+//   - newCat, newSub: 用户选择的分类，与建议相同表示接受
+func (c *Classifier) ResolveReview(batchID string, r Result, newCat, newSub string) {
+	newCat, newSub = Normalize(newCat, newSub)
+	c.learnMu.Lock()
+	defer c.learnMu.Unlock()
+	if newCat == r.Category && newSub == r.Subcategory {
+		if r.Source == "llm" {
+			c.db.UpdateModelAccuracy(batchID, 1, 0)
+		}
+		return
+	}
+	c.memory.LearnFromCorrection(r.FileInfo.Name, r.FileInfo.ParentDir(), r.FileInfo.ContentHash, r.Category, newCat, r.Subcategory, newSub)
+	forgetResult(r)
+	if r.Source == "llm" {
+		c.db.UpdateModelAccuracy(batchID, 0, 1)
+	}
+	c.db.AddRunCorrection(batchID)
 }
 
 // ==================== 统计方法 ====================
@@ -323,6 +935,72 @@ func (c *Classifier) GetStatistics() (map[string]interface{}, error) {
 
 // ==================== 辅助函数 ====================
 
+// Redactions 返回本次运行中发送给远程提供方前隐去的个人信息，未脱敏时为空
+func (c *Classifier) Redactions() []privacy.Redaction {
+	return c.redactor.Redactions()
+}
+
+// fileData 构建发送给 LLM 的文件描述
+// 开启内容读取时附带文本文件的开头片段，开启 OCR 时附带扫描件和截图中识别出的文字；
+// 使用远程提供方时文件名中的个人信息替换为占位符
+func (c *Classifier) fileData(f scanner.FileInfo) map[string]interface{} {
+	data := map[string]interface{}{
+		"name":      c.redactor.Mask(f.Name),
+		"extension": f.Extension,
+		"size":      f.Size,
+	}
+	// 文件名的主要语言，帮助区分如日文命名的动画与中文命名的电视剧
+	// （中文和英文从文件名一眼可见，不额外提供）
+	if lang := scanner.DetectLanguage(f.Name); lang != "" && lang != scanner.LangChinese && lang != scanner.LangEnglish {
+		data["language"] = lang
+	}
+	cfg := config.Get()
+	if cfg.ContentAllowed() {
+		if snippet := scanner.ReadSnippet(f, 300); snippet != "" {
+			data["content"] = snippet
+		}
+	}
+	if cfg.OCRAllowed() && ocr.IsScan(f) {
+		if text, err := ocr.Extract(f); err == nil && text != "" {
+			data["ocr_text"] = text
+		}
+	}
+	// 音视频元数据（艺术家、专辑、时长、分辨率）和下载来源的域名
+	// 远程提供方与文件内容一样需要额外允许
+	if !cfg.IsRemoteProvider() || cfg.AllowRemoteContent {
+		if f.Media != nil {
+			data["media"] = f.Media.Fields()
+		}
+		if f.Origin != "" {
+			data["origin"] = f.Origin
+		}
+	}
+	return data
+}
+
+// toResult 将 LLM 返回的单条分类转换为 Result
+// 优先使用多级分类路径 path，没有时使用 category 和 subcategory；分类名经过规范化
+func toResult(f scanner.FileInfo, clsMap map[string]interface{}) Result {
+	path := getStringSlice(clsMap, "path")
+	if len(path) == 0 {
+		path = []string{getString(clsMap, "category", ""), getString(clsMap, "subcategory", "")}
+	}
+	category, subcategory := Normalize(JoinPath(path))
+	if subcategory == "" {
+		subcategory = "其他"
+	}
+
+	return Result{
+		FileInfo:    f,
+		Category:    category,
+		Subcategory: subcategory,
+		Confidence:  getFloat(clsMap, "confidence", 0.5),
+		Reasoning:   getString(clsMap, "reasoning", ""),
+		Source:      "llm",
+		Keywords:    getStringSlice(clsMap, "keywords"),
+	}
+}
+
 // getString 从 map 中安全获取字符串值
 func getString(m map[string]interface{}, key, def string) string {
 	if v, ok := m[key].(string); ok {
@@ -0,0 +1,59 @@
+// Package classifier 智能分类器模块
+// conflict.go - 来源矛盾：记忆给出了未达到阈值、但有一定把握的分类，AI 却归入了另一个主分类。
+// 这类结果标记为有分歧，低置信度处理方式为 review / keep 时与低置信度文件一起进入待确认队列
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"filo/internal/memory"
+	"filo/internal/scanner"
+)
+
+// ConflictConfidence 记忆建议至少达到该置信度时，与 AI 结果不一致才视为分歧
+const ConflictConfidence = 0.6
+
+// Alternative 与分类结果矛盾的另一个建议
+type Alternative struct {
+	Category    string  // 主分类
+	Subcategory string  // 子分类
+	Confidence  float64 // 置信度
+	Source      string  // 来源: rule / vector / history / ensemble 等记忆来源
+}
+
+// rememberHint 记录记忆未命中的文件置信度最高的记忆建议，AI 分类后据此判断是否有分歧
+// 在记忆查询阶段调用（AI 分类开始前），之后只读；
+// 只有低置信度文件进入待确认队列（low_confidence_action 为 review / keep）时才需要
+func (c *Classifier) rememberHint(f scanner.FileInfo) {
+	if c.cfg.LowConfidenceAction != "review" && c.cfg.LowConfidenceAction != "keep" {
+		return
+	}
+	match := c.memory.BestGuess(f.Name, f.ParentDir(), f.ContentHash)
+	if match == nil || match.Confidence < ConflictConfidence {
+		return
+	}
+	if c.hints == nil {
+		c.hints = make(map[string]*memory.Match)
+	}
+	c.hints[f.Path] = match
+}
+
+// markConflict AI 的分类与记忆建议的主分类不同时，记录记忆建议
+func (c *Classifier) markConflict(r *Result) {
+	hint := c.hints[r.FileInfo.Path]
+	if hint == nil || r.Source != "llm" {
+		return
+	}
+	category, subcategory := Normalize(hint.Category, hint.Subcategory)
+	if category == r.Category {
+		return
+	}
+	r.Conflict = &Alternative{
+		Category:    category,
+		Subcategory: subcategory,
+		Confidence:  hint.Confidence,
+		Source:      hint.Source,
+	}
+}
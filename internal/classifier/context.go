@@ -0,0 +1,194 @@
+// Package classifier 智能分类器模块
+// context.go - 上下文提示：同一目录中一分钟内集中出现的一批文件（批量下载）通常属于同一类，
+// 批中多数文件已由记忆确定分类时，其余文件相同分类的记忆建议获得加分；
+// 文件修改时间所在的时间段（工作时间 / 休息时间）同样作为弱特征加分
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// ==================== 常量定义 ====================
+
+// 批量下载识别参数
+const (
+	BurstGap       = time.Minute // 相邻两个文件修改时间的最大间隔
+	BurstMinSize   = 3           // 一批至少包含的文件数
+	BurstBoost     = 0.1         // 记忆建议与批中多数文件的分类一致时增加的置信度
+	BurstAgreement = 2.0 / 3     // 批中记忆命中的文件至少有这么多归入同一主分类，才视为该批的分类
+	BurstMinVotes  = 2           // 批中至少有这么多文件由记忆确定了分类
+	contextMaxConf = 0.95        // 加分后的置信度上限
+)
+
+// ==================== 批量下载 ====================
+
+// markBursts 标记批量下载：同一目录中按修改时间排序，相邻间隔不超过 BurstGap 的文件为一批，
+// 至少 BurstMinSize 个文件时标记批次编号（批次 ID#序号）
+//
+// 参数:
+//   - files: 扫描到的文件
+//
+// 返回值:
+//   - []scanner.FileInfo: 标记了 Burst 的文件副本（顺序不变）
+func (c *Classifier) markBursts(files []scanner.FileInfo) []scanner.FileInfo {
+	marked := make([]scanner.FileInfo, len(files))
+	copy(marked, files)
+
+	byDir := make(map[string][]int)
+	for i, f := range marked {
+		if !f.IsDir && !f.ModifiedTime.IsZero() {
+			dir := filepath.Dir(f.Path)
+			byDir[dir] = append(byDir[dir], i)
+		}
+	}
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	n := 0
+	for _, dir := range dirs {
+		idx := byDir[dir]
+		sort.SliceStable(idx, func(a, b int) bool { return marked[idx[a]].ModifiedTime.Before(marked[idx[b]].ModifiedTime) })
+		for start := 0; start < len(idx); {
+			end := start + 1
+			for end < len(idx) && marked[idx[end]].ModifiedTime.Sub(marked[idx[end-1]].ModifiedTime) <= BurstGap {
+				end++
+			}
+			if end-start >= BurstMinSize {
+				n++
+				id := fmt.Sprintf("%s#%d", c.batchID, n)
+				for _, i := range idx[start:end] {
+					marked[i].Burst = id
+				}
+			}
+			start = end
+		}
+	}
+	return marked
+}
+
+// ==================== 上下文提示 ====================
+
+// applyContext 用上下文提示确定记忆未命中文件的分类
+// 对每个未命中的文件取置信度最高的记忆建议：与所在批量下载中多数文件的分类一致时加 BurstBoost，
+// 再加上时间段的加分，达到相似度阈值时采用，不再交给 AI
+//
+// 参数:
+//   - known: 记忆命中等已有的结果
+//   - pending: 等待 AI 分类的文件
+//   - verbose: 是否逐个输出结果
+//
+// 返回值:
+//   - []Result: 合并后已有的结果
+//   - []scanner.FileInfo: 仍需 AI 分类的文件
+func (c *Classifier) applyContext(known []Result, pending []scanner.FileInfo, verbose bool) ([]Result, []scanner.FileInfo) {
+	if len(pending) == 0 || c.cfg.RulesOnly {
+		return known, pending
+	}
+
+	// 各批量下载中记忆命中的主分类
+	votes := make(map[string]map[string]int)
+	for _, r := range known {
+		if r.Source != "memory" || r.FileInfo.Burst == "" {
+			continue
+		}
+		if votes[r.FileInfo.Burst] == nil {
+			votes[r.FileInfo.Burst] = make(map[string]int)
+		}
+		votes[r.FileInfo.Burst][r.Category]++
+	}
+
+	accepted := 0
+	var rest []scanner.FileInfo
+	for _, f := range pending {
+		if c.Interrupted() {
+			rest = append(rest, f)
+			continue
+		}
+		if r, ok := c.contextResult(f, votes[f.Burst]); ok {
+			known = append(known, r)
+			accepted++
+			if verbose {
+				ui.Success("%s → %s/%s (%s)", f.Name, r.Category, r.Subcategory, r.Reasoning)
+			}
+			continue
+		}
+		rest = append(rest, f)
+	}
+	if accepted > 0 {
+		ui.Success("上下文提示确定了 %d 个文件的分类", accepted)
+	}
+	return known, rest
+}
+
+// contextResult 为单个文件的记忆建议加上上下文加分，达到阈值时返回结果
+func (c *Classifier) contextResult(f scanner.FileInfo, votes map[string]int) (Result, bool) {
+	category, share, total := majority(votes)
+	if total < BurstMinVotes || share < BurstAgreement {
+		category = "" // 批中记忆命中的文件太少或分类不一致
+	}
+
+	guess := c.memory.BestGuess(f.Name, f.ParentDir(), f.ContentHash)
+	if guess == nil {
+		return Result{}, false
+	}
+	guessCat, guessSub := Normalize(guess.Category, guess.Subcategory)
+
+	boost := 0.0
+	var reasons []string
+	if category != "" && guessCat == category {
+		boost += BurstBoost
+		reasons = append(reasons, fmt.Sprintf("同一批下载中记忆确定的 %d 个文件有 %d 个归入%s", total, votes[category], category))
+	}
+	if timeBoost, timeReason := c.memory.ContextPrior(f.ModifiedTime, guessCat); timeBoost > 0 {
+		boost += timeBoost
+		reasons = append(reasons, timeReason)
+	}
+	if boost == 0 {
+		return Result{}, false
+	}
+
+	confidence := guess.Confidence + boost
+	if confidence > contextMaxConf {
+		confidence = contextMaxConf
+	}
+	if confidence < c.cfg.SimilarityThresholdFor(guessCat) {
+		return Result{}, false
+	}
+	return Result{
+		FileInfo:    f,
+		Category:    guessCat,
+		Subcategory: guessSub,
+		Confidence:  confidence,
+		Reasoning:   guess.Reasoning + "；" + strings.Join(reasons, "，"),
+		Source:      "memory",
+	}, true
+}
+
+// majority 返回得票最多的主分类、占比和总票数
+func majority(votes map[string]int) (string, float64, int) {
+	best, bestN, total := "", 0, 0
+	for category, n := range votes {
+		total += n
+		if n > bestN || (n == bestN && category < best) {
+			best, bestN = category, n
+		}
+	}
+	if total == 0 {
+		return "", 0, 0
+	}
+	return best, float64(bestN) / float64(total), total
+}
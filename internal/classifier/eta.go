@@ -0,0 +1,143 @@
+// Package classifier 智能分类模块
+// eta.go - AI 分类前估算耗时：按 model_stats 中该模型每个文件的平均耗时和并行批次数估算，
+// 超过 eta_warn_minutes 时询问是否换用更快的模型、开启并行或跳过 AI 分类
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"filo/internal/ui"
+)
+
+// FasterModelRatio 其他模型的耗时不超过当前模型的该比例时，才作为更快的模型推荐
+const FasterModelRatio = 0.7
+
+// Estimate AI 分类的预计耗时
+type Estimate struct {
+	Model    string        // 模型名称
+	Files    int           // 需要 AI 分类的文件数
+	PerFile  time.Duration // 单个请求中每个文件的平均耗时
+	Parallel int           // 同时发送的批次数
+	Duration time.Duration // 预计总耗时
+	Samples  int           // 参考的统计记录数
+}
+
+// ETAChoice 预计耗时过长时用户的选择
+type ETAChoice struct {
+	Model    string // 换用的模型，为空时不换
+	Parallel int    // 同时发送的批次数，0 表示不变
+	Skip     bool   // 跳过 AI 分类，未命中记忆的文件留在原处
+}
+
+// ETAPrompt 预计耗时超过 eta_warn_minutes 时询问用户
+// faster 为有历史数据、明显更快的已安装模型（按预计耗时升序），
+// parallel 为可以开启的并行批次数（已经并行时为 0）
+type ETAPrompt func(est Estimate, faster []Estimate, parallel int) ETAChoice
+
+// SetETAPrompt 设置预计耗时过长时的询问方式，未设置时（网页控制台、MCP、静默模式）只显示预计耗时
+func (c *Classifier) SetETAPrompt(prompt ETAPrompt) {
+	c.etaPrompt = prompt
+}
+
+// estimate 按模型的历史耗时估算分类 files 个文件的耗时，没有历史数据时返回 false
+func (c *Classifier) estimate(model string, files, parallel int) (Estimate, bool) {
+	perFile, samples := c.db.GetModelLatency(model, TuneSamples, MinBatchSize)
+	if samples == 0 {
+		return Estimate{}, false
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+	return Estimate{
+		Model:    model,
+		Files:    files,
+		PerFile:  time.Duration(perFile * float64(time.Millisecond)),
+		Parallel: parallel,
+		Duration: time.Duration(perFile * float64(files) / float64(parallel) * float64(time.Millisecond)),
+		Samples:  samples,
+	}, true
+}
+
+// fasterModels 有历史数据、耗时明显少于当前模型且已安装的其他模型
+// 远程提供方按调用计费，不推荐换用其他模型
+func (c *Classifier) fasterModels(current Estimate) []Estimate {
+	if c.llm.IsRemote() {
+		return nil
+	}
+	summaries, err := c.db.GetModelSummaries()
+	if err != nil {
+		return nil
+	}
+	var faster []Estimate
+	for _, s := range summaries {
+		if s.ModelName == current.Model {
+			continue
+		}
+		est, ok := c.estimate(s.ModelName, current.Files, current.Parallel)
+		if ok && float64(est.Duration) <= FasterModelRatio*float64(current.Duration) && c.llm.HasModel(s.ModelName) {
+			faster = append(faster, est)
+		}
+	}
+	sort.Slice(faster, func(i, j int) bool { return faster[i].Duration < faster[j].Duration })
+	if len(faster) > 3 {
+		faster = faster[:3]
+	}
+	return faster
+}
+
+// checkETA 显示 AI 分类的预计耗时，超过 eta_warn_minutes 时询问用户
+// 用户选择换用模型或开启并行时随即生效；返回 false 表示跳过 AI 分类
+func (c *Classifier) checkETA(files int) bool {
+	plan := c.batchPlan()
+	if c.parallel > plan.Parallel {
+		plan.Parallel = c.parallel
+	}
+	est, ok := c.estimate(c.cfg.ActiveModel(), files, plan.Parallel)
+	if !ok {
+		return true
+	}
+	ui.Info("预计耗时: %s（%.0fms/文件，%d 路并行）", FormatETA(est.Duration), float64(est.PerFile.Milliseconds()), est.Parallel)
+
+	limit := time.Duration(c.cfg.ETAWarnMinutes) * time.Minute
+	if c.etaPrompt == nil || limit <= 0 || est.Duration <= limit {
+		return true
+	}
+	parallel := c.cfg.LLMParallel
+	if parallel < 2 {
+		parallel = 2
+	}
+	if est.Parallel >= parallel {
+		parallel = 0
+	}
+	choice := c.etaPrompt(est, c.fasterModels(est), parallel)
+	switch {
+	case choice.Skip:
+		return false
+	case choice.Model != "":
+		c.cfg.SetModel(choice.Model)
+		c.llm.SetModel(choice.Model)
+		ui.Info("模型: %s", ui.Bold(choice.Model))
+	case choice.Parallel > 0:
+		c.parallel = choice.Parallel
+		ui.Info("同时发送 %d 批", choice.Parallel)
+	}
+	return true
+}
+
+// FormatETA 把预计耗时格式化为「约 X 分钟」这类便于阅读的文字
+func FormatETA(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("约 %d 秒", int(d.Seconds()+0.5))
+	case d < time.Hour:
+		return fmt.Sprintf("约 %d 分钟", int(d.Minutes()+0.5))
+	default:
+		return fmt.Sprintf("约 %.1f 小时", d.Hours())
+	}
+}
@@ -0,0 +1,86 @@
+// Package classifier 智能分类器模块
+// existing.go - 按已有文件夹整理：分类结果只能是目标目录中已有的文件夹，
+// 记忆给出的分类不在其中时改由 AI 从已有文件夹中选择，AI 的结果也不在其中时留给用户确认
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"strings"
+)
+
+// UseExistingFolders 只把文件归入给定的文件夹（目标目录中已有的文件夹）
+// 记忆、插件和 AI 的分类结果都对应到其中一个文件夹，AI 的提示词中列出这些文件夹代替分类体系
+//
+// 参数:
+//   - folders: 相对目标目录的文件夹路径，以 / 分隔，如 工作/报告
+func (c *Classifier) UseExistingFolders(folders []string) {
+	c.folders = make(map[string]string, len(folders))
+	for _, f := range folders {
+		c.folders[folderKey(strings.Split(f, PathSep))] = f
+	}
+	c.llm.SetFolders(folders)
+}
+
+// fitFolder 把结果的分类路径对应到已有文件夹，返回是否找到
+// 先找完整路径（忽略大小写、繁简和空格），其次是以该路径结尾的唯一一个更深的文件夹
+// （如 财务/发票 对应 工作/财务/发票）；都没有时逐级去掉末尾层级，对上级路径重复查找
+func (c *Classifier) fitFolder(r *Result) bool {
+	path := r.Path()
+	folder, ok := "", false
+	for n := len(path); !ok && n > 0; n-- {
+		if folder, ok = c.folders[folderKey(path[:n])]; !ok {
+			folder, ok = c.deeperFolder(path[:n])
+		}
+	}
+	if !ok {
+		return false
+	}
+	category, subcategory := JoinPath(CategoryNames(strings.Split(folder, PathSep)))
+	if category != r.Category || subcategory != r.Subcategory {
+		r.Reasoning += "（归入已有文件夹 " + folder + "）"
+		r.Category, r.Subcategory = category, subcategory
+	}
+	return true
+}
+
+// deeperFolder 查找以 path 结尾的更深一层或多层的已有文件夹，恰好一个时返回
+func (c *Classifier) deeperFolder(path []string) (string, bool) {
+	suffix := PathSep + folderKey(path)
+	match, n := "", 0
+	for key, folder := range c.folders {
+		if strings.HasSuffix(key, suffix) {
+			match = folder
+			n++
+		}
+	}
+	return match, n == 1
+}
+
+// fitResults 把结果对应到已有文件夹；找不到对应文件夹的结果置信度置 0，留给用户确认
+// 可疑文件和隔离区的文件归入 filo 自己的文件夹，不做对应
+func (c *Classifier) fitResults(results []Result) {
+	if c.folders == nil {
+		return
+	}
+	for i := range results {
+		r := &results[i]
+		if r.Source == "scanner" || r.Source == "quarantine" || c.fitFolder(r) {
+			continue
+		}
+		r.Confidence = 0
+		r.Reasoning += "（目标目录中没有对应的文件夹）"
+	}
+}
+
+// folderKey 返回比较文件夹路径用的键，各层级换回分类名后按 foldKey 处理
+// 分类路径和使用显示名称（如 Documents）的已有文件夹得到相同的键
+func folderKey(path []string) string {
+	keys := make([]string, len(path))
+	for i, p := range path {
+		keys[i] = foldKey(CategoryName(p))
+	}
+	return strings.Join(keys, PathSep)
+}
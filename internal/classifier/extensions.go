@@ -0,0 +1,127 @@
+// Package classifier 智能分类模块
+// extensions.go - 扩展名默认分类表
+//
+// 记忆未命中、AI 分类失败或判断不出分类时，文件按扩展名落到一个合理的分类，而不是「未分类」。
+// 内置表在首次使用时写入数据库（extension_defaults），用户可以用 filo rules ext 修改
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"filo/internal/scanner"
+	"filo/internal/storage"
+	"filo/internal/taxonomy"
+)
+
+// ==================== 内置分类表 ====================
+
+// builtinExtensions 内置的扩展名 → 分类表，首次使用时写入数据库
+// 分类名与默认分类体系保持一致；安装包和压缩包见 taxonomy.InstallerExts / ArchiveExts
+var builtinExtensions = map[string][2]string{
+	// 文档
+	".pdf": {"文档", "PDF"}, ".doc": {"文档", "Word"}, ".docx": {"文档", "Word"},
+	".ppt": {"文档", "演示"}, ".pptx": {"文档", "演示"}, ".key": {"文档", "演示"},
+	".txt": {"文档", "文本"}, ".md": {"文档", "笔记"}, ".rtf": {"文档", "文本"},
+	".pages": {"文档", "Word"}, ".epub": {"文档", "电子书"}, ".mobi": {"文档", "电子书"},
+	// 数据
+	".xls": {"数据", "表格"}, ".xlsx": {"数据", "表格"}, ".csv": {"数据", "表格"},
+	".numbers": {"数据", "表格"}, ".db": {"数据", "数据库"}, ".sqlite": {"数据", "数据库"},
+	".sql": {"数据", "数据库"}, ".json": {"数据", "导出"}, ".xml": {"数据", "导出"},
+	// 图片
+	".jpg": {"图片", "照片"}, ".jpeg": {"图片", "照片"}, ".heic": {"图片", "照片"},
+	".png": {"图片", "截图"}, ".gif": {"图片", "动图"}, ".webp": {"图片", "照片"},
+	".bmp": {"图片", "其他"}, ".svg": {"图片", "图标"}, ".ico": {"图片", "图标"},
+	".psd": {"图片", "设计稿"}, ".ai": {"图片", "设计稿"}, ".sketch": {"图片", "设计稿"},
+	".fig": {"图片", "设计稿"}, ".raw": {"图片", "照片"}, ".cr2": {"图片", "照片"},
+	// 视频
+	".mp4": {"视频", "其他"}, ".mov": {"视频", "其他"}, ".mkv": {"视频", "电影"},
+	".avi": {"视频", "其他"}, ".wmv": {"视频", "其他"}, ".flv": {"视频", "其他"},
+	".webm": {"视频", "其他"},
+	// 音频
+	".mp3": {"音频", "音乐"}, ".flac": {"音频", "音乐"}, ".wav": {"音频", "录音"},
+	".m4a": {"音频", "录音"}, ".aac": {"音频", "音乐"}, ".ogg": {"音频", "音乐"},
+	// 代码
+	".go": {"代码", "源码"}, ".py": {"代码", "源码"}, ".js": {"代码", "源码"},
+	".ts": {"代码", "源码"}, ".java": {"代码", "源码"}, ".c": {"代码", "源码"},
+	".cpp": {"代码", "源码"}, ".rs": {"代码", "源码"}, ".html": {"代码", "源码"},
+	".css": {"代码", "源码"}, ".sh": {"代码", "脚本"}, ".bat": {"代码", "脚本"},
+	".ps1": {"代码", "脚本"}, ".yaml": {"代码", "配置"}, ".yml": {"代码", "配置"},
+	".toml": {"代码", "配置"}, ".ini": {"代码", "配置"},
+	// 字体
+	".ttf": {"字体", "其他"}, ".otf": {"字体", "其他"}, ".woff": {"字体", "其他"},
+}
+
+// BuiltinExtensions 内置的扩展名默认分类
+// 包括 builtinExtensions 中的常见文件类型和分类体系中的安装包、压缩包扩展名
+//
+// 返回值:
+//   - []storage.ExtensionDefault: 内置的默认分类
+func BuiltinExtensions() []storage.ExtensionDefault {
+	defaults := make([]storage.ExtensionDefault, 0, len(builtinExtensions)+len(taxonomy.InstallerExts)+len(taxonomy.ArchiveExts))
+	for ext, cat := range builtinExtensions {
+		defaults = append(defaults, storage.ExtensionDefault{Extension: ext, Category: cat[0], Subcategory: cat[1], Builtin: true})
+	}
+	for ext := range taxonomy.InstallerExts {
+		defaults = append(defaults, storage.ExtensionDefault{Extension: ext, Category: "安装包", Subcategory: "软件", Builtin: true})
+	}
+	for ext := range taxonomy.ArchiveExts {
+		defaults = append(defaults, storage.ExtensionDefault{Extension: ext, Category: "压缩包", Subcategory: "资料包", Builtin: true})
+	}
+	return defaults
+}
+
+// ==================== 扩展名兜底 ====================
+
+// classifyByExtension 根据扩展名默认分类表推断分类
+// 媒体文件遵循分类体系中的整理偏好；表中没有该扩展名时归入「未分类」
+//
+// 参数:
+//   - f: 文件信息
+//   - reason: 使用扩展名推断的原因，写入分类理由（如 "离线模式"）
+//
+// 返回值:
+//   - Result: 来源为 extension 的分类结果
+func (c *Classifier) classifyByExtension(f scanner.FileInfo, reason string) Result {
+	r := Result{
+		FileInfo:   f,
+		Confidence: ExtensionConfidence,
+		Reasoning:  reason + ": 按扩展名 " + f.Extension + " 推断",
+		Source:     "extension",
+	}
+
+	def := c.db.GetExtensionDefault(f.Extension)
+	if def == nil {
+		r.Category, r.Subcategory = "未分类", "其他"
+		r.Confidence = UnknownConfidence
+		r.Reasoning = reason + ": 无法根据扩展名推断"
+		return r
+	}
+	r.Category, r.Subcategory = Normalize(def.Category, def.Subcategory)
+
+	// 媒体统一归入「媒体」主分类
+	if taxonomy.Get().Preferences.Media == taxonomy.MediaMerged {
+		switch r.Category {
+		case "图片", "视频", "音频":
+			r.Category, r.Subcategory = "媒体", r.Category
+		}
+	}
+	return r
+}
+
+// extensionFallback AI 分类失败或判断为「未分类」时，改用扩展名默认分类
+// 表中没有该扩展名时保留原结果
+func (c *Classifier) extensionFallback(r Result) Result {
+	if r.Source != "error" && r.Category != "未分类" {
+		return r
+	}
+	reason := "AI 无法判断分类"
+	if r.Source == "error" {
+		reason = r.Reasoning
+	}
+	if fallback := c.classifyByExtension(r.FileInfo, reason); fallback.Category != "未分类" {
+		return fallback
+	}
+	return r
+}
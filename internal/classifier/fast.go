@@ -0,0 +1,40 @@
+// Package classifier 智能分类模块
+// fast.go - 快速模式：文件很多时记忆查询中最慢的是向量检索（逐条比较已学习的向量）和历史匹配，
+// 快速模式只做内容和规则匹配，其余文件交给 AI 并加大批大小，以少量记忆命中率换取吞吐量
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// FastBatchSize 快速模式下 AI 分类的最小批大小（仍受上下文窗口和单批超时限制）
+const FastBatchSize = 40
+
+// useFast 决定本次分类是否使用快速模式：配置 fast（--fast）开启，
+// 或文件数超过 fast_mode_files 时自动开启；仅规则和离线模式不自动开启
+// 快速模式下分类结果照常学习，之后的普通整理仍能用上这些记录
+func (c *Classifier) useFast(files []scanner.FileInfo) {
+	c.fast = c.cfg.Fast
+	if !c.fast && c.cfg.FastModeFiles > 0 && !c.cfg.RulesOnly && !c.cfg.Offline {
+		count := 0
+		for _, f := range files {
+			if !f.IsDir {
+				count++
+			}
+		}
+		if count > c.cfg.FastModeFiles {
+			c.fast = true
+			ui.Info("文件较多（%d 个，超过 fast_mode_files=%d），自动启用快速模式", count, c.cfg.FastModeFiles)
+			ui.Dim("加 --fast=false 关闭")
+		}
+	}
+	if c.fast {
+		ui.Info("快速模式: 记忆只做内容和规则匹配，不检索向量和历史，AI 分类加大批大小")
+	}
+	c.memory.SetFast(c.fast)
+}
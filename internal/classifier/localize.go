@@ -0,0 +1,143 @@
+// Package classifier 智能分类模块
+// localize.go - 文件夹名称的语言：分类名在学习记录、规则和操作日志中保持规范写法，
+// 建立文件夹时按 folder_names 和 folder_language 换成显示名称（如 文档/合同 → Documents/Contracts），
+// 更换显示语言不会让学习记录分散到两种写法下
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"strings"
+	"sync"
+
+	"filo/internal/config"
+)
+
+// 文件夹名称语言（folder_language）
+const (
+	FolderLanguageAsIs = ""   // 与分类名相同（默认）
+	FolderLanguageEN   = "en" // 内置分类使用英文名
+	FolderLanguageZH   = "zh" // 常见的英文分类名使用中文名
+)
+
+// FolderLanguages folder_language 的可选值
+var FolderLanguages = []string{FolderLanguageAsIs, FolderLanguageEN, FolderLanguageZH}
+
+// englishNames 内置分类体系及 filo 自己的文件夹的英文名
+var englishNames = map[string]string{
+	// 主分类
+	"文档": "Documents", "图片": "Images", "视频": "Videos", "音频": "Audio", "代码": "Code",
+	"压缩包": "Archives", "安装包": "Installers", "数据": "Data", "媒体": "Media",
+	"工作": "Work", "个人": "Personal", "财务": "Finance", "学习": "Study",
+	// 子分类
+	"合同": "Contracts", "报告": "Reports", "方案": "Proposals", "笔记": "Notes", "简历": "Resumes",
+	"照片": "Photos", "截图": "Screenshots", "设计稿": "Designs", "图标": "Icons",
+	"电影": "Movies", "教程": "Tutorials", "录屏": "Screen Recordings", "会议": "Meetings",
+	"音乐": "Music", "录音": "Recordings", "播客": "Podcasts",
+	"源码": "Source", "配置": "Config", "脚本": "Scripts",
+	"备份": "Backups", "资料包": "Bundles", "软件": "Software", "工具": "Tools",
+	"表格": "Spreadsheets", "数据库": "Databases", "导出": "Exports",
+	"发票": "Invoices", "其他": "Other",
+	// 截图来源
+	"微信": "WeChat", "企业微信": "WeCom", "钉钉": "DingTalk", "飞书": "Lark", "网页": "Web",
+	// filo 自己的文件夹
+	"未分类": "Unsorted", "待处理": "Needs Attention", "未完成下载": "Incomplete Downloads", "隔离区": "Quarantine",
+}
+
+var (
+	localized     map[string]map[string]string // 语言 -> foldKey(分类名) -> 显示名称
+	localizedOnce sync.Once
+)
+
+// localizedNames 内置的译名表，英文按 foldKey 查找，繁体和大小写不同的写法同样适用
+func localizedNames() map[string]map[string]string {
+	localizedOnce.Do(func() {
+		en := make(map[string]string, len(englishNames))
+		zh := make(map[string]string, len(englishNames)+len(aliases))
+		for name, english := range englishNames {
+			en[foldKey(name)] = english
+			zh[foldKey(english)] = name
+		}
+		for alias, name := range aliases {
+			if _, ok := zh[alias]; !ok {
+				zh[alias] = name
+			}
+		}
+		localized = map[string]map[string]string{FolderLanguageEN: en, FolderLanguageZH: zh}
+	})
+	return localized
+}
+
+// FolderName 单级分类名对应的文件夹名
+// folder_names 中指定的名称优先，其次是 folder_language 的内置译名，都没有时与分类名相同
+//
+// 参数:
+//   - name: 规范化后的单级分类名，如 文档
+//
+// 返回值:
+//   - string: 文件夹名，如 Documents
+func FolderName(name string) string {
+	cfg := config.Get()
+	if custom, ok := cfg.FolderNames[name]; ok {
+		if custom = cleanName(custom); custom != "" && !strings.ContainsAny(custom, `/\`) {
+			return custom
+		}
+	}
+	if names, ok := localizedNames()[cfg.FolderLanguage]; ok {
+		if display, ok := names[foldKey(name)]; ok {
+			return display
+		}
+	}
+	return name
+}
+
+// FolderNames 分类路径对应的文件夹路径，各层级按 FolderName 换成显示名称
+func FolderNames(path []string) []string {
+	names := make([]string, len(path))
+	for i, name := range path {
+		names[i] = FolderName(name)
+	}
+	return names
+}
+
+// CategoryName 文件夹名对应的分类名，FolderName 的逆映射
+// 用于按已有文件夹整理、查找分类体系中的说明和颜色；不是任何分类的显示名称时原样返回
+//
+// 参数:
+//   - folder: 单级文件夹名，如 Documents
+//
+// 返回值:
+//   - string: 分类名，如 文档
+func CategoryName(folder string) string {
+	cfg := config.Get()
+	key := foldKey(folder)
+	var candidates []string
+	for name, custom := range cfg.FolderNames {
+		if foldKey(custom) == key {
+			candidates = append(candidates, name)
+		}
+	}
+	for name, english := range englishNames {
+		if foldKey(english) == key {
+			candidates = append(candidates, name)
+		}
+	}
+	// 只有按当前设置确实显示为该文件夹名的分类才算数
+	for _, name := range candidates {
+		if foldKey(FolderName(name)) == key {
+			return name
+		}
+	}
+	return folder
+}
+
+// CategoryNames 文件夹路径对应的分类路径，各层级按 CategoryName 换回分类名
+func CategoryNames(path []string) []string {
+	names := make([]string, len(path))
+	for i, folder := range path {
+		names[i] = CategoryName(folder)
+	}
+	return names
+}
@@ -0,0 +1,205 @@
+// Package classifier 智能分类模块
+// normalize.go - 分类名规范化
+//
+// 模型返回的分类名可能带有 emoji、全角空格、过长的描述，或者同一个分类有多种写法
+// （图片 / 圖片 / Images / images）。分类结果在生成整理计划和学习之前统一规范化，
+// 同一个分类总是落到同一个文件夹，学习记录也不会分散到多个写法下
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"filo/internal/taxonomy"
+)
+
+// ==================== 常量定义 ====================
+
+// MaxNameLength 每一级分类名的最大字符数，超出部分截断
+const MaxNameLength = 24
+
+// aliases 常见分类的其他写法 -> 内置分类体系中的名称
+// 键为 foldKey 后的形式，繁体写法经 foldKey 转为简体后与简体相同，无需列出
+var aliases = map[string]string{
+	// 主分类
+	"images": "图片", "image": "图片", "pictures": "图片", "picture": "图片", "图像": "图片",
+	"documents": "文档", "document": "文档", "docs": "文档",
+	"videos": "视频", "video": "视频", "影片": "视频",
+	"audio": "音频", "audios": "音频",
+	"code": "代码", "sourcecode": "代码", "程式码": "代码",
+	"archives": "压缩包", "archive": "压缩包", "compressed": "压缩包", "压缩档": "压缩包",
+	"installers": "安装包", "installer": "安装包", "安装档": "安装包",
+	"data": "数据",
+	// 子分类
+	"photos": "照片", "photo": "照片",
+	"screenshots": "截图", "screenshot": "截图", "屏幕截图": "截图",
+	"icons": "图标", "icon": "图标",
+	"contracts": "合同", "contract": "合同", "合约": "合同",
+	"reports": "报告", "report": "报告",
+	"notes":  "笔记",
+	"resume": "简历", "resumes": "简历", "cv": "简历",
+	"movies": "电影", "movie": "电影",
+	"music":    "音乐",
+	"meetings": "会议",
+	"scripts":  "脚本", "script": "脚本",
+	"backups": "备份", "backup": "备份",
+	"software": "软件", "软体": "软件",
+	"tools":        "工具",
+	"spreadsheets": "表格",
+	"other":        "其他", "others": "其他", "misc": "其他", "miscellaneous": "其他",
+}
+
+// traditional 分类名中常见的繁体字 -> 简体字（仅用于比较，不改变用户自己的写法）
+var traditional = map[rune]rune{
+	'圖': '图', '檔': '档', '視': '视', '頻': '频', '樂': '乐', '碼': '码', '壓': '压', '縮': '缩',
+	'裝': '装', '數': '数', '據': '据', '報': '报', '會': '会', '議': '议', '錄': '录', '設': '设',
+	'計': '计', '標': '标', '記': '记', '筆': '笔', '簡': '简', '歷': '历', '軟': '软', '體': '体',
+	'備': '备', '腳': '脚', '電': '电', '單': '单', '項': '项', '戶': '户', '資': '资', '務': '务',
+	'財': '财', '發': '发', '書': '书', '類': '类', '庫': '库', '導': '导', '開': '开', '專': '专',
+	'學': '学', '習': '习', '課': '课', '題': '题', '證': '证', '稅': '税', '險': '险', '醫': '医',
+	'療': '疗', '藥': '药', '約': '约', '與': '与', '頁': '页', '網': '网', '絡': '络', '狀': '状',
+	'態': '态', '貨': '货', '費': '费', '銀': '银', '遊': '游', '戲': '戏', '機': '机',
+	'紙': '纸', '雜': '杂', '們': '们', '個': '个', '檢': '检', '測': '测', '試': '试', '驗': '验',
+	'圓': '圆', '車': '车', '運': '运', '動': '动', '預': '预', '覽': '览', '歸': '归', '寫': '写',
+	'讀': '读', '聲': '声', '畫': '画', '樣': '样', '種': '种', '產': '产', '處': '处',
+	'簽': '签', '帳': '账', '賬': '账', '圍': '围', '環': '环', '舊': '旧', '雲': '云', '後': '后',
+}
+
+// ==================== 规范化 ====================
+
+// Normalize 规范化分类路径的各级名称
+// 用于模型返回的分类、学习记忆命中的分类和用户输入的分类，结果可直接用于建立文件夹和学习：
+//  1. 去除 emoji 和不可见字符，全角字母数字转为半角，连续空白合并为一个空格
+//  2. 去除文件夹名中不允许的字符（见 cleanName），每级最多 MaxNameLength 个字符
+//  3. 全小写的英文名改为首字母大写（invoices → Invoices），含大写的保持原样（PDF、iPhone）
+//  4. 与分类体系（taxonomy.json）中的名称只有繁简、大小写、全半角或空格不同时使用分类体系的写法；
+//     常见的英文和其他写法（Images、图像）映射为内置分类名
+//
+// 参数:
+//   - category: 主分类
+//   - subcategory: 子分类，多级用 / 分隔
+//
+// 返回值:
+//   - string: 规范化后的主分类，清理后为空时为 "未分类"
+//   - string: 规范化后的子分类
+func Normalize(category, subcategory string) (string, string) {
+	var path []string
+	for _, level := range []string{category, subcategory} {
+		for _, name := range strings.FieldsFunc(level, func(r rune) bool { return r == '/' || r == '\\' }) {
+			path = append(path, normalizeName(name))
+		}
+	}
+	category, subcategory = JoinPath(path)
+	if category == "" {
+		category = "未分类"
+	}
+	return category, subcategory
+}
+
+// normalizeName 规范化单级分类名
+func normalizeName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range name {
+		switch {
+		case r >= '！' && r <= '～':
+			r -= '！' - '!' // 全角 ASCII 转半角
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case unicode.Is(unicode.So, r), unicode.Is(unicode.Sk, r), unicode.Is(unicode.Cf, r),
+			unicode.Is(unicode.Co, r), r == '\uFE0F' || r == '\uFE0E' || r == '\u20E3':
+			continue // emoji、变体选择符、零宽字符
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+
+	name = cleanName(b.String())
+	if runes := []rune(name); len(runes) > MaxNameLength {
+		name = cleanName(string(runes[:MaxNameLength]))
+	}
+	return canonicalName(titleCase(name))
+}
+
+// titleCase 全小写的英文名改为每个单词首字母大写
+func titleCase(name string) string {
+	hasLetter := false
+	for _, r := range name {
+		if unicode.IsUpper(r) {
+			return name
+		}
+		if r < unicode.MaxASCII && unicode.IsLetter(r) {
+			hasLetter = true
+		}
+	}
+	if !hasLetter {
+		return name
+	}
+	words := strings.Split(name, " ")
+	for i, w := range words {
+		if w != "" && w[0] >= 'a' && w[0] <= 'z' {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// canonicalName 返回分类名的规范写法：分类体系中的写法优先，其次是内置的别名
+func canonicalName(name string) string {
+	key := foldKey(name)
+	if known, ok := knownNames()[key]; ok {
+		return known
+	}
+	if alias, ok := aliases[key]; ok {
+		if known, ok := knownNames()[foldKey(alias)]; ok {
+			return known
+		}
+		return alias
+	}
+	return name
+}
+
+// foldKey 返回用于比较分类名的键
+// 忽略大小写、空格、下划线和连字符，繁体字转为简体
+func foldKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if r == ' ' || r == '_' || r == '-' {
+			continue
+		}
+		if s, ok := traditional[r]; ok {
+			r = s
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var (
+	known     map[string]string // foldKey -> 分类体系中的写法
+	knownOnce sync.Once
+)
+
+// knownNames 分类体系中的主分类和常用子分类名称
+func knownNames() map[string]string {
+	knownOnce.Do(func() {
+		known = make(map[string]string)
+		for _, c := range taxonomy.Get().Categories {
+			for _, name := range append([]string{c.Name}, c.Subcategories...) {
+				if _, ok := known[foldKey(name)]; !ok {
+					known[foldKey(name)] = name
+				}
+			}
+		}
+	})
+	return known
+}
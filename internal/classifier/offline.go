@@ -0,0 +1,43 @@
+// Package classifier 智能分类器模块
+// offline.go - 离线分类（不调用 LLM）
+// 只使用记忆系统和扩展名默认分类表，适合模型服务不可用或需要省电的场景
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"filo/internal/scanner"
+)
+
+// ==================== 常量定义 ====================
+
+const (
+	ExtensionConfidence = 0.5 // 扩展名推断的置信度
+	UnknownConfidence   = 0.2 // 无法推断时的置信度
+)
+
+// ==================== 离线分类 ====================
+
+// classifyOffline 离线分类文件
+// 优先使用记忆系统中置信度最高的匹配（即使低于阈值），
+// 记忆置信度不如扩展名推断时使用扩展名默认分类表
+func (c *Classifier) classifyOffline(files []scanner.FileInfo) []Result {
+	results := make([]Result, 0, len(files))
+	for _, f := range files {
+		if match := c.memory.BestGuess(f.Name, f.ParentDir(), f.ContentHash); match != nil && match.Confidence >= ExtensionConfidence {
+			results = append(results, Result{
+				FileInfo:    f,
+				Category:    match.Category,
+				Subcategory: match.Subcategory,
+				Confidence:  match.Confidence,
+				Reasoning:   match.Reasoning,
+				Source:      "memory",
+			})
+			continue
+		}
+		results = append(results, c.classifyByExtension(f, "离线模式"))
+	}
+	return results
+}
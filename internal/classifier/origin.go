@@ -0,0 +1,33 @@
+// Package classifier 智能分类器模块
+// origin.go - 按下载来源分类：文件的下载来源域名匹配来源规则（filo rules add --origin）时
+// 直接归入规则的分类，如 github.com 下载的文件归入 代码/下载
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"filo/internal/scanner"
+)
+
+// originConfidence 匹配来源规则时的置信度（与正则、通配符规则相同，规则由用户手动添加）
+const originConfidence = 0.95
+
+// MatchOrigin 按来源规则分类，文件没有下载来源或没有匹配的规则时返回 false
+// 整理时先于记忆查询，filo explain 用来显示来源规则的结果
+func (c *Classifier) MatchOrigin(f scanner.FileInfo) (Result, bool) {
+	rule := c.db.MatchOriginRule(f.Origin)
+	if rule == nil {
+		return Result{}, false
+	}
+	category, subcategory := Normalize(rule.Category, rule.Subcategory)
+	return Result{
+		FileInfo:    f,
+		Category:    category,
+		Subcategory: subcategory,
+		Confidence:  originConfidence,
+		Reasoning:   "下载来源: " + f.Origin + "（规则「" + rule.Pattern + "」）",
+		Source:      "rule",
+	}, true
+}
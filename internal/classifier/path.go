@@ -0,0 +1,103 @@
+// Package classifier 智能分类模块
+// path.go - 多级分类路径：主分类之下可以有任意层级（如 工作/客户A/合同/2024）
+//
+// 为兼容已有的规则和操作日志，第二级及以下的层级用 / 连接存放在 Subcategory 中，
+// 两级分类的数据无需迁移
+//
+// 模型给出的分类名可能含有 Windows 文件夹名中不允许的字符（如 "项目:A"），
+// 建立文件夹前统一去除；整理结果可能同步到 Windows 或 exFAT 磁盘，所有平台使用同一规则
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"strings"
+	"unicode"
+)
+
+// PathSep 分类路径中层级的分隔符
+const PathSep = "/"
+
+// MaxPathDepth 分类路径的最大层级数（含主分类），更深的层级被截断
+const MaxPathDepth = 6
+
+// illegalNameChars 文件夹名中不允许的字符（Windows）
+const illegalNameChars = `<>:"|?*`
+
+// reservedNames Windows 保留的设备名，不能用作文件夹名（不区分大小写，带扩展名也不行）
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// Path 返回分类路径（主分类在前）
+// 空层级、. 和 .. 会被去除，不会生成目标目录之外的路径；
+// 各层级去除文件夹名中不允许的字符
+func (r Result) Path() []string {
+	return cleanPath(append([]string{r.Category}, strings.Split(r.Subcategory, PathSep)...))
+}
+
+// JoinPath 将分类路径拆分为主分类和子分类（第二级及以下用 / 连接）
+//
+// 参数:
+//   - path: 分类路径，如 ["工作", "客户A", "合同", "2024"]
+//
+// 返回值:
+//   - string: 主分类，路径为空时为空
+//   - string: 子分类，如 "客户A/合同/2024"
+func JoinPath(path []string) (string, string) {
+	path = cleanPath(path)
+	if len(path) == 0 {
+		return "", ""
+	}
+	return path[0], strings.Join(path[1:], PathSep)
+}
+
+// cleanPath 清理分类路径的各个层级
+// 层级内的路径分隔符视为分隔多个层级
+func cleanPath(path []string) []string {
+	var cleaned []string
+	for _, p := range path {
+		for _, part := range strings.FieldsFunc(p, func(r rune) bool { return r == '/' || r == '\\' }) {
+			part = cleanName(part)
+			if part == "" {
+				continue
+			}
+			cleaned = append(cleaned, part)
+		}
+	}
+	if len(cleaned) > MaxPathDepth {
+		cleaned = cleaned[:MaxPathDepth]
+	}
+	return cleaned
+}
+
+// cleanName 去除文件夹名中不允许的字符
+// 去掉 <>:"|?* 和控制字符、首尾空白以及末尾的点（Windows 会静默去掉末尾的点和空格，
+// 导致磁盘上的文件夹与记录不一致）；Windows 保留的设备名（如 CON、aux.txt）后加 _；
+// 清理后只剩 . 或 .. 时返回空字符串
+func cleanName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(illegalNameChars, r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimRight(strings.TrimSpace(name), ". ")
+	if name == "" {
+		return ""
+	}
+	base := name
+	if i := strings.IndexByte(base, '.'); i >= 0 {
+		base = base[:i]
+	}
+	if reservedNames[strings.ToUpper(strings.TrimSpace(base))] {
+		name += "_"
+	}
+	return name
+}
@@ -0,0 +1,123 @@
+// Package classifier 智能分类模块
+// pick.go - 按自然语言指令挑选文件
+//
+// 与整理整个目录不同，pick 只让模型挑出符合指令的文件并归入指令中的分类，
+// 其余文件不分类、不移动
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"fmt"
+
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// PickBatchSize 挑选时每批发送给模型的文件数
+// 模型只需判断是否符合指令，每批可以比分类多
+const PickBatchSize = 50
+
+// Pick 按自然语言指令挑选文件
+// 被选中的文件归入同一个目标分类：target 不为空时使用 target（主分类/子分类），
+// 否则使用模型从指令中提取的分类路径
+//
+// 参数:
+//   - files: 扫描到的文件（目录会被跳过）
+//   - instruction: 用户指令，如「把所有和报销相关的文件放到 财务/报销」
+//   - target: 目标分类路径，为空时由模型从指令中提取
+//   - verbose: 是否显示每个选中文件的理由
+//
+// 返回值:
+//   - []Result: 被选中文件的分类结果（来源为 llm）
+//   - error: 模型调用失败或无法确定目标分类时返回错误
+func (c *Classifier) Pick(files []scanner.FileInfo, instruction, target string, verbose bool) ([]Result, error) {
+	var candidates []scanner.FileInfo
+	for _, f := range files {
+		if !f.IsDir {
+			candidates = append(candidates, f)
+		}
+	}
+
+	var category, subcategory string
+	if target != "" {
+		category, subcategory = Normalize(target, "")
+	}
+
+	var results []Result
+	bar := newProgressBar(len(candidates), "  挑选中")
+	for i := 0; i < len(candidates); i += PickBatchSize {
+		if c.Interrupted() {
+			break // 已取消，只保留已挑选的文件
+		}
+		end := i + PickBatchSize
+		if end > len(candidates) {
+			end = len(candidates)
+		}
+		batch := candidates[i:end]
+
+		batchData := make([]map[string]interface{}, len(batch))
+		for j, f := range batch {
+			batchData[j] = c.fileData(f)
+			batchData[j]["id"] = j
+		}
+		resp, err := c.llm.PickFilesWithRetry(c.ctx, instruction, batchData)
+		if err != nil {
+			if c.Interrupted() {
+				break
+			}
+			fmt.Println()
+			return nil, err
+		}
+		c.redactor.RestoreMap(resp)
+		bar.Add(len(batch))
+
+		// 目标分类以第一次给出的路径为准，保证所有文件归入同一处
+		if category == "" {
+			if path := getStringSlice(resp, "path"); len(path) > 0 {
+				category, subcategory = Normalize(JoinPath(path))
+			}
+		}
+
+		selected, _ := resp["selected"].([]interface{})
+		for _, s := range selected {
+			sel, _ := s.(map[string]interface{})
+			if sel == nil {
+				continue
+			}
+			id, ok := sel["id"].(float64)
+			if !ok || id < 0 || int(id) >= len(batch) {
+				continue // 编号无效
+			}
+			results = append(results, Result{
+				FileInfo:   batch[int(id)],
+				Confidence: getFloat(sel, "confidence", 0.8),
+				Reasoning:  getString(sel, "reasoning", ""),
+				Source:     "llm",
+			})
+		}
+	}
+	fmt.Println() // 进度条结束后换行
+
+	if len(results) > 0 && category == "" {
+		return nil, fmt.Errorf("无法从指令中确定目标分类，请用 --to 指定")
+	}
+
+	// 同一个文件可能被重复选中，只保留一次
+	seen := make(map[string]bool)
+	picked := results[:0]
+	for _, r := range results {
+		if seen[r.FileInfo.Path] {
+			continue
+		}
+		seen[r.FileInfo.Path] = true
+		r.Category, r.Subcategory = category, subcategory
+		if verbose {
+			ui.Success("%s → %s/%s (%s)", r.FileInfo.Name, category, subcategory, r.Reasoning)
+		}
+		picked = append(picked, r)
+	}
+	return picked, nil
+}
@@ -0,0 +1,268 @@
+// Package classifier 智能分类器模块
+// plugin.go - 外部分类插件：classifier_plugins 中配置的程序从标准输入读取一批文件信息（JSON），
+// 向标准输出返回分类结果。插件结果与记忆、AI 的结果按置信度合并：
+// 达到置信度阈值的文件不再交给 AI，其余文件取置信度较高的一方
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"filo/internal/config"
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// ==================== 常量定义 ====================
+
+// SourcePlugin 插件分类结果的来源
+const SourcePlugin = "plugin"
+
+// 插件调用参数
+const (
+	pluginBatchSize = 200              // 每次调用发送的文件数
+	pluginTimeout   = 60 * time.Second // 默认单次调用超时
+)
+
+// ==================== 数据结构 ====================
+
+// pluginFile 发给插件的文件信息
+type pluginFile struct {
+	Path      string    `json:"path"`       // 完整路径
+	Name      string    `json:"name"`       // 文件名
+	Extension string    `json:"extension"`  // 扩展名（小写，带点号）
+	Size      int64     `json:"size"`       // 大小（字节）
+	Modified  time.Time `json:"modified"`   // 修改时间
+	ParentDir string    `json:"parent_dir"` // 原所在目录名
+}
+
+// pluginRequest 插件的输入
+type pluginRequest struct {
+	Files []pluginFile `json:"files"`
+}
+
+// pluginResponse 插件的输出，不认识的文件可以不返回
+type pluginResponse struct {
+	Results []struct {
+		Path        string  `json:"path"`        // 文件路径（与输入一致）
+		Category    string  `json:"category"`    // 主分类，也可以是多级路径（如 论文/机器学习）
+		Subcategory string  `json:"subcategory"` // 子分类
+		Confidence  float64 `json:"confidence"`  // 置信度（0-1）
+		Reasoning   string  `json:"reasoning"`   // 分类理由
+	} `json:"results"`
+	Error string `json:"error,omitempty"` // 插件自身报告的错误
+}
+
+// ==================== 插件分类 ====================
+
+// classifyWithPlugins 用所有插件分类文件，每个文件保留置信度最高的插件结果
+// 记忆命中的文件插件置信度更高时改用插件结果；未命中的文件插件结果达到置信度阈值时直接采用，不再交给 AI，
+// 其余插件结果留到 AI 分类后比较（见 preferPlugin）
+//
+// 参数:
+//   - known: 记忆命中等已有的结果
+//   - pending: 等待 AI 分类的文件
+//   - verbose: 是否逐个输出结果
+//
+// 返回值:
+//   - []Result: 合并后已有的结果
+//   - []scanner.FileInfo: 仍需 AI 分类的文件
+func (c *Classifier) classifyWithPlugins(known []Result, pending []scanner.FileInfo, verbose bool) ([]Result, []scanner.FileInfo) {
+	if len(c.cfg.ClassifierPlugins) == 0 || c.cfg.RulesOnly {
+		return known, pending
+	}
+
+	files := make([]scanner.FileInfo, 0, len(known)+len(pending))
+	for _, r := range known {
+		if r.Source == "memory" {
+			files = append(files, r.FileInfo)
+		}
+	}
+	files = append(files, pending...)
+	if len(files) == 0 {
+		return known, pending
+	}
+
+	c.plugins = make(map[string]Result)
+	for _, p := range c.cfg.ClassifierPlugins {
+		if c.Interrupted() {
+			break
+		}
+		results, err := c.runPlugin(p, files)
+		if err != nil {
+			ui.Warning("分类插件 %s 调用失败: %v", p.Name, err)
+		}
+		for _, r := range results {
+			if best, ok := c.plugins[r.FileInfo.Path]; !ok || r.Confidence > best.Confidence {
+				c.plugins[r.FileInfo.Path] = r
+			}
+		}
+	}
+	if len(c.plugins) == 0 {
+		return known, pending
+	}
+
+	replaced := 0
+	for i := range known {
+		if known[i].Source == "memory" && c.preferPlugin(&known[i]) {
+			replaced++
+		}
+	}
+	var rest []scanner.FileInfo
+	for _, f := range pending {
+		r, ok := c.plugins[f.Path]
+		if ok && r.Confidence >= c.cfg.ConfidenceThresholdFor(r.Category) {
+			known = append(known, r)
+			replaced++
+			if verbose {
+				ui.Success("%s → %s/%s (%s)", f.Name, r.Category, r.Subcategory, r.Reasoning)
+			}
+			continue
+		}
+		rest = append(rest, f)
+	}
+	if replaced > 0 {
+		ui.Success("分类插件确定了 %d 个文件的分类", replaced)
+	}
+	return known, rest
+}
+
+// preferPlugin 插件结果的置信度高于 r 时用插件结果替换 r，返回是否替换
+func (c *Classifier) preferPlugin(r *Result) bool {
+	p, ok := c.plugins[r.FileInfo.Path]
+	if !ok || p.Confidence <= r.Confidence {
+		return false
+	}
+	p.FileInfo = r.FileInfo
+	*r = p
+	return true
+}
+
+// runPlugin 调用一个插件分类文件，按 extensions 过滤后每 200 个文件调用一次
+func (c *Classifier) runPlugin(p config.ClassifierPlugin, files []scanner.FileInfo) ([]Result, error) {
+	if len(p.Command) == 0 {
+		return nil, errors.New("未配置 command")
+	}
+	if len(p.Extensions) > 0 {
+		wanted := make(map[string]bool)
+		for _, ext := range p.Extensions {
+			wanted["."+strings.TrimPrefix(strings.ToLower(ext), ".")] = true
+		}
+		var matched []scanner.FileInfo
+		for _, f := range files {
+			if wanted[f.Extension] {
+				matched = append(matched, f)
+			}
+		}
+		files = matched
+	}
+
+	var results []Result
+	for start := 0; start < len(files); start += pluginBatchSize {
+		end := start + pluginBatchSize
+		if end > len(files) {
+			end = len(files)
+		}
+		batch, err := c.callPlugin(p, files[start:end])
+		if err != nil {
+			return results, err
+		}
+		results = append(results, batch...)
+	}
+	return results, nil
+}
+
+// callPlugin 启动插件程序，写入一批文件信息并解析输出
+func (c *Classifier) callPlugin(p config.ClassifierPlugin, files []scanner.FileInfo) ([]Result, error) {
+	req := pluginRequest{Files: make([]pluginFile, len(files))}
+	byPath := make(map[string]scanner.FileInfo, len(files))
+	for i, f := range files {
+		req.Files[i] = pluginFile{
+			Path:      f.Path,
+			Name:      f.Name,
+			Extension: f.Extension,
+			Size:      f.Size,
+			Modified:  f.ModifiedTime,
+			ParentDir: f.ParentDir(),
+		}
+		byPath[f.Path] = f
+	}
+	input, _ := json.Marshal(req)
+
+	timeout := pluginTimeout
+	if p.Timeout > 0 {
+		timeout = time.Duration(p.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Command[0], p.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%v 内没有完成", timeout)
+		}
+		if msg := lastLine(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%v（%s）", err, msg)
+		}
+		return nil, err
+	}
+
+	var resp pluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("输出不是有效的 JSON: %v", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+
+	results := make([]Result, 0, len(resp.Results))
+	for _, item := range resp.Results {
+		f, ok := byPath[item.Path]
+		if !ok || strings.TrimSpace(item.Category) == "" {
+			continue // 不是本批的文件或没有给出分类
+		}
+		category, subcategory := Normalize(item.Category, item.Subcategory)
+		if subcategory == "" {
+			subcategory = "其他"
+		}
+		confidence := item.Confidence
+		if confidence > 1 {
+			confidence = 1
+		} else if confidence < 0 {
+			confidence = 0
+		}
+		reasoning := "插件 " + p.Name
+		if item.Reasoning != "" {
+			reasoning += ": " + item.Reasoning
+		}
+		results = append(results, Result{
+			FileInfo:    f,
+			Category:    category,
+			Subcategory: subcategory,
+			Confidence:  confidence,
+			Reasoning:   reasoning,
+			Source:      SourcePlugin,
+		})
+	}
+	return results, nil
+}
+
+// lastLine 返回文本中最后一个非空行
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,60 @@
+// Package classifier 智能分类器模块
+// quarantine.go - 隔离模式：可执行文件、脚本和安装包归入隔离区，不交给记忆或 AI 分类
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"fmt"
+
+	"filo/internal/quarantine"
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// quarantineFile 判断文件是否需要隔离，需要时返回隔离结果
+// 哈希在白名单中的文件不隔离，照常分类；无法计算哈希的文件按未在白名单处理
+// 仅在云端的文件计算哈希会触发下载，同样按未在白名单处理
+func (c *Classifier) quarantineFile(f scanner.FileInfo, verbose bool) (Result, bool) {
+	kind := quarantine.Kind(f.Extension)
+	if kind == "" {
+		return Result{}, false
+	}
+
+	var hash string
+	err := fmt.Errorf("文件在 %s 云端，未下载到本机", f.Cloud)
+	if f.Cloud == "" {
+		hash, err = quarantine.Hash(f.Path)
+	}
+	if err == nil {
+		allowed, err := quarantine.Allowed(hash)
+		if err != nil && verbose {
+			ui.Warning("读取隔离白名单失败: %v", err)
+		}
+		if allowed {
+			if verbose {
+				ui.Dim("%s 在白名单中，照常分类", f.Name)
+			}
+			return Result{}, false
+		}
+	}
+
+	f.SHA256 = hash
+	reason := "SHA-256 " + hash
+	if err != nil {
+		reason = fmt.Sprintf("无法计算哈希: %v", err)
+	}
+	if verbose {
+		ui.Warning("%s → %s/%s", f.Name, quarantine.Category, kind)
+	}
+	return Result{
+		FileInfo:    f,
+		Category:    quarantine.Category,
+		Subcategory: kind,
+		Confidence:  1.0,
+		Reasoning:   reason,
+		Source:      "quarantine",
+	}, true
+}
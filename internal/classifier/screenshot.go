@@ -0,0 +1,248 @@
+// Package classifier 智能分类器模块
+// screenshot.go - 截图按应用归类：识别各平台截图工具的默认文件名，从文件名（微信截图_xxx、
+// Android 的 Screenshot_xxx_WeChat）或顶部栏的文字推断截图来自哪个应用，
+// 归入 图片/截图/微信、图片/截图/网页、图片/截图/代码 等子文件夹
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"filo/internal/ocr"
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// ==================== 常量定义 ====================
+
+// 截图的分类
+const (
+	ScreenshotCategory    = "图片"
+	ScreenshotSubcategory = "截图"
+	SourceScreenshot      = "screenshot" // 截图按应用归类的结果来源
+)
+
+// 截图归类参数
+const (
+	screenshotConfidence = 0.9 // 按文件名识别出截图时的置信度
+	titleBarRatio        = 8   // 顶部栏取图片高度的 1/8
+	titleBarMinHeight    = 48  // 顶部栏的最小高度（像素）
+)
+
+// screenshotExts 截图工具保存的图片格式
+var screenshotExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".heic": true, ".webp": true,
+}
+
+// screenshotNames 截图工具的默认文件名（不含扩展名）及文件名对应的应用
+// app 为空时应用取自正则的 app 分组，没有该分组的格式需要识别顶部栏
+var screenshotNames = []struct {
+	re  *regexp.Regexp
+	app string
+}{
+	{regexp.MustCompile(`^企业微信截图_?\d`), "企业微信"},
+	{regexp.MustCompile(`^微信截图_?\d`), "微信"},
+	{regexp.MustCompile(`(?i)^QQ截图\d`), "QQ"},
+	{regexp.MustCompile(`^钉钉截图_?\d`), "钉钉"},
+	// Android：Screenshot_20240501-103312_WeChat、Screenshot_2024-05-01-10-33-12-123_com.tencent.mm
+	{regexp.MustCompile(`(?i)^screenshot_[\d_\-]+?_(?P<app>[a-z][\w. ]*)$`), ""},
+	// macOS、Windows、GNOME、KDE、Snipaste 等不含应用的格式
+	{regexp.MustCompile(`(?i)^(screenshot|screen shot|屏幕截图|截屏|屏幕快照|截图)[ _]?(from )?\d{4}-?\d{2}-?\d{2}`), ""},
+	{regexp.MustCompile(`(?i)^(screenshot|屏幕截图)[ _]?\(\d+\)$`), ""},
+	{regexp.MustCompile(`(?i)^screenshot_\d`), ""},
+	{regexp.MustCompile(`(?i)^snipaste_\d{4}`), ""},
+}
+
+// screenshotApps 文件名中的应用名或包名（小写）对应的子文件夹
+// 浏览器归入 网页，编辑器和终端归入 代码；不在表中的应用名原样作为子文件夹，不认识的包名不归类
+var screenshotApps = map[string]string{
+	"wechat": "微信", "weixin": "微信", "com.tencent.mm": "微信",
+	"wecom": "企业微信", "wework": "企业微信", "com.tencent.wework": "企业微信",
+	"qq": "QQ", "com.tencent.mobileqq": "QQ",
+	"dingtalk": "钉钉", "com.alibaba.android.rimet": "钉钉",
+	"lark": "飞书", "feishu": "飞书", "com.ss.android.lark": "飞书",
+	"telegram": "Telegram", "org.telegram.messenger": "Telegram",
+	"whatsapp": "WhatsApp", "com.whatsapp": "WhatsApp",
+	"slack": "Slack", "com.slack": "Slack",
+	"chrome": "网页", "com.android.chrome": "网页", "firefox": "网页", "org.mozilla.firefox": "网页",
+	"edge": "网页", "com.microsoft.emmx": "网页", "samsung internet": "网页", "com.sec.android.app.sbrowser": "网页",
+	"browser": "网页", "com.android.browser": "网页", "com.huawei.browser": "网页", "com.heytap.browser": "网页",
+	"com.quark.browser": "网页", "com.uc.browser": "网页",
+	"termux": "代码", "com.termux": "代码", "github": "代码", "com.github.android": "代码",
+}
+
+// titleBarApps 顶部栏文字（窗口标题、菜单栏、地址栏）中的应用特征，按顺序匹配
+var titleBarApps = []struct {
+	re  *regexp.Regexp
+	app string
+}{
+	{regexp.MustCompile(`企业微信|(?i)wecom`), "企业微信"},
+	{regexp.MustCompile(`微信|(?i)wechat`), "微信"},
+	{regexp.MustCompile(`钉钉|(?i)dingtalk`), "钉钉"},
+	{regexp.MustCompile(`飞书|(?i)\blark\b|feishu`), "飞书"},
+	{regexp.MustCompile(`(?i)\bQQ\b`), "QQ"},
+	{regexp.MustCompile(`(?i)\btelegram\b`), "Telegram"},
+	{regexp.MustCompile(`(?i)\bslack\b`), "Slack"},
+	{regexp.MustCompile(`终端|(?i)visual studio|\bvs ?code\b|intellij|goland|pycharm|webstorm|xcode|android studio|` +
+		`sublime text|\bvim\b|iterm|\bterminal\b|powershell|cmd\.exe|\.(go|py|js|ts|java|rs|cpp|swift)\b`), "代码"},
+	{regexp.MustCompile(`浏览器|(?i)https?://|www\.|\bchrome\b|\bsafari\b|\bfirefox\b|\bedge\b`), "网页"},
+}
+
+// ==================== 识别函数 ====================
+
+// ParseScreenshot 判断文件名是否为截图工具的默认文件名，并返回文件名中的应用
+//
+// 参数:
+//   - name: 文件名（含扩展名）
+//
+// 返回值:
+//   - string: 截图所属的应用子文件夹（如 微信、网页），文件名中没有应用时为空
+//   - bool: 是否为截图
+func ParseScreenshot(name string) (string, bool) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if !screenshotExts[ext] {
+		return "", false
+	}
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	for _, p := range screenshotNames {
+		m := p.re.FindStringSubmatch(base)
+		if m == nil {
+			continue
+		}
+		if p.app != "" {
+			return p.app, true
+		}
+		if i := p.re.SubexpIndex("app"); i > 0 {
+			return screenshotApp(m[i]), true
+		}
+		return "", true
+	}
+	return "", false
+}
+
+// screenshotApp 文件名中的应用名或包名对应的子文件夹
+func screenshotApp(token string) string {
+	token = strings.TrimSpace(token)
+	if app, ok := screenshotApps[strings.ToLower(token)]; ok {
+		return app
+	}
+	if strings.Contains(token, ".") {
+		return "" // 不认识的包名（com.example.app）不适合作为文件夹名
+	}
+	return cleanName(token)
+}
+
+// titleBarApp 识别截图顶部栏的文字，推断截图来自哪个应用
+// 只识别顶部的一条（窗口标题、菜单栏或浏览器地址栏），识别出的文字不发送给分类模型
+func titleBarApp(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return "", err
+	}
+	b := src.Bounds()
+	h := b.Dy() / titleBarRatio
+	if h < titleBarMinHeight {
+		h = titleBarMinHeight
+	}
+	if h > b.Dy() {
+		h = b.Dy()
+	}
+	bar := image.NewRGBA(image.Rect(0, 0, b.Dx(), h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < b.Dx(); x++ {
+			bar.Set(x, y, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, bar); err != nil {
+		return "", err
+	}
+	text, err := ocr.Recognize(buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	for _, a := range titleBarApps {
+		if a.re.MatchString(text) {
+			return a.app, nil
+		}
+	}
+	return "", nil
+}
+
+// ==================== 分类函数 ====================
+
+// classifyScreenshots 把截图归入 图片/截图/应用；文件名中没有应用且开启了 OCR 时识别顶部栏，
+// 仍无法确定应用的截图归入 图片/截图
+//
+// 参数:
+//   - files: 文件名为截图格式的文件
+//   - verbose: 是否逐个输出结果
+//
+// 返回值:
+//   - []Result: 分类结果
+func (c *Classifier) classifyScreenshots(files []scanner.FileInfo, verbose bool) []Result {
+	readTitle := c.cfg.OCR != "" && !c.cfg.Offline
+	results := make([]Result, 0, len(files))
+	grouped := 0
+	for _, f := range files {
+		app, _ := ParseScreenshot(f.Name)
+		reasoning := "截图文件名显示来自 " + app
+		if app == "" && readTitle && !c.Interrupted() && f.Cloud == "" && scanner.ThumbnailExts[f.Extension] {
+			var err error
+			if app, err = titleBarApp(f.Path); err != nil && verbose {
+				ui.Warning("%s 顶部栏识别失败: %v", f.Name, err)
+			}
+			reasoning = "截图顶部栏显示来自 " + app
+		}
+
+		subcategory := ScreenshotSubcategory
+		if app != "" {
+			subcategory += PathSep + app
+			grouped++
+		} else {
+			reasoning = "截图工具的默认文件名"
+		}
+		category, subcategory := Normalize(ScreenshotCategory, subcategory)
+		r := Result{
+			FileInfo:    f,
+			Category:    category,
+			Subcategory: subcategory,
+			Confidence:  screenshotConfidence,
+			Reasoning:   reasoning,
+			Source:      SourceScreenshot,
+		}
+		results = append(results, r)
+		if verbose {
+			ui.Success("%s → %s/%s (%s)", f.Name, r.Category, r.Subcategory, r.Reasoning)
+		}
+	}
+	if len(results) > 0 {
+		ui.Success("%d 张截图归入 %s/%s，其中 %d 张按应用归类", len(results), ScreenshotCategory, ScreenshotSubcategory, grouped)
+	}
+	return results
+}
+
+// isScreenshot 是否按应用归类该文件：开启了 screenshot_apps 且文件名为截图格式
+func (c *Classifier) isScreenshot(f scanner.FileInfo) bool {
+	if !c.cfg.ScreenshotApps {
+		return false
+	}
+	_, ok := ParseScreenshot(f.Name)
+	return ok
+}
@@ -0,0 +1,235 @@
+// Package classifier 智能分类模块
+// selftest.go - 准确率自测：用内置的基准样本（有代表性的文件名及其正确分类）检验当前模型和规则，
+// 按主分类统计准确率，为更换模型、修改提示词或分类体系提供客观的对比依据（filo selftest）
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	_ "embed"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"filo/internal/scanner"
+)
+
+// selftestData 基准样本，每行为 文件名<TAB>主分类/子分类，# 开头的行为注释
+//
+//go:embed dataset/selftest.tsv
+var selftestData string
+
+// SelftestSample 一个基准样本
+type SelftestSample struct {
+	Name        string // 文件名
+	Category    string // 正确的主分类
+	Subcategory string // 正确的子分类
+}
+
+// Path 正确的分类路径（主分类/子分类）
+func (s SelftestSample) Path() string {
+	return s.Category + "/" + s.Subcategory
+}
+
+// SelftestOutcome 一个样本的自测结果
+type SelftestOutcome struct {
+	Sample     SelftestSample // 基准样本
+	Result     Result         // 分类结果
+	CategoryOK bool           // 主分类正确
+	PathOK     bool           // 主分类和子分类都正确
+}
+
+// SelftestScore 一个主分类的准确率
+type SelftestScore struct {
+	Category   string // 主分类（按正确答案归类）
+	Total      int    // 样本数
+	CategoryOK int    // 主分类正确的样本数
+	PathOK     int    // 主分类和子分类都正确的样本数
+	ByRule     int    // 由规则分类的样本数
+}
+
+// Rate 主分类准确率（0-1）
+func (s SelftestScore) Rate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.CategoryOK) / float64(s.Total)
+}
+
+// PathRate 完整分类路径准确率（0-1）
+func (s SelftestScore) PathRate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.PathOK) / float64(s.Total)
+}
+
+// SelftestSamples 读取内置的基准样本
+//
+// 返回值:
+//   - []SelftestSample: 基准样本，按文件中的顺序
+//   - error: 如果某一行格式不正确，返回错误
+func SelftestSamples() ([]SelftestSample, error) {
+	var samples []SelftestSample
+	for i, line := range strings.Split(selftestData, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, path, ok := strings.Cut(line, "\t")
+		category, subcategory, ok2 := strings.Cut(path, "/")
+		if !ok || !ok2 || name == "" || category == "" || subcategory == "" {
+			return nil, fmt.Errorf("基准样本第 %d 行格式不正确: %q", i+1, line)
+		}
+		samples = append(samples, SelftestSample{Name: name, Category: category, Subcategory: subcategory})
+	}
+	return samples, nil
+}
+
+// SelfTest 对基准样本分类并与正确答案比较
+// 先用确定性规则（用户规则和学习到的关键词、语言、扩展名规则）分类，其余交给模型；
+// 不查询相似文件和历史记录，不学习，样本文件并不存在，只发送文件名
+//
+// 参数:
+//   - samples: 基准样本
+//   - useRules: 是否使用规则；为 false 时只用模型，提示词中也不附带学习到的规则
+//
+// 返回值:
+//   - []SelftestOutcome: 每个样本的结果，顺序与 samples 相同
+//   - time.Duration: 模型分类耗时
+func (c *Classifier) SelfTest(samples []SelftestSample, useRules bool) ([]SelftestOutcome, time.Duration) {
+	outcomes := make([]SelftestOutcome, len(samples))
+	var pending []scanner.FileInfo
+	index := make(map[string]int) // 样本路径 -> 下标
+
+	for i, s := range samples {
+		f := scanner.FileInfo{
+			Path:      filepath.Join("selftest", fmt.Sprintf("%03d", i), s.Name),
+			Name:      s.Name,
+			Extension: strings.ToLower(filepath.Ext(s.Name)),
+		}
+		outcomes[i].Sample = s
+		if useRules {
+			if m := c.memory.RuleOnly(s.Name); m != nil {
+				outcomes[i].Result = Result{
+					FileInfo:    f,
+					Category:    m.Category,
+					Subcategory: m.Subcategory,
+					Confidence:  m.Confidence,
+					Reasoning:   m.Reasoning,
+					Source:      m.Source,
+				}
+				continue
+			}
+		}
+		index[f.Path] = i
+		pending = append(pending, f)
+	}
+
+	var elapsed time.Duration
+	if len(pending) > 0 {
+		var rules []map[string]string
+		if useRules {
+			rules = c.memory.GetLearnedRules(30)
+		}
+		start := time.Now()
+		results, _ := c.classifyWithLLM(pending, rules, false, false)
+		elapsed = time.Since(start)
+		for _, r := range results {
+			if i, ok := index[r.FileInfo.Path]; ok {
+				outcomes[i].Result = r
+				delete(index, r.FileInfo.Path)
+			}
+		}
+		// 没有返回结果的样本（请求失败、被中断）记为错误
+		for path, i := range index {
+			outcomes[i].Result = Result{FileInfo: scanner.FileInfo{Path: path, Name: samples[i].Name}, Source: "error"}
+		}
+	}
+
+	for i := range outcomes {
+		s, r := outcomes[i].Sample, outcomes[i].Result
+		sub := r.Subcategory
+		if first, _, ok := strings.Cut(sub, "/"); ok {
+			sub = first // 多级分类只比较第二级
+		}
+		outcomes[i].CategoryOK = r.Source != "error" && foldKey(r.Category) == foldKey(s.Category)
+		outcomes[i].PathOK = outcomes[i].CategoryOK && foldKey(sub) == foldKey(s.Subcategory)
+	}
+	return outcomes, elapsed
+}
+
+// ScoreSelftest 按正确答案的主分类汇总准确率
+//
+// 参数:
+//   - outcomes: SelfTest 返回的结果
+//
+// 返回值:
+//   - []SelftestScore: 各主分类的准确率，按在基准样本中出现的顺序
+//   - SelftestScore: 全部样本的准确率（Category 为空）
+func ScoreSelftest(outcomes []SelftestOutcome) ([]SelftestScore, SelftestScore) {
+	var scores []SelftestScore
+	index := make(map[string]int)
+	var total SelftestScore
+	for _, o := range outcomes {
+		i, ok := index[o.Sample.Category]
+		if !ok {
+			i = len(scores)
+			index[o.Sample.Category] = i
+			scores = append(scores, SelftestScore{Category: o.Sample.Category})
+		}
+		for _, s := range []*SelftestScore{&scores[i], &total} {
+			s.Total++
+			if o.CategoryOK {
+				s.CategoryOK++
+			}
+			if o.PathOK {
+				s.PathOK++
+			}
+			if o.Result.Source == "rule" {
+				s.ByRule++
+			}
+		}
+	}
+	return scores, total
+}
+
+// SelftestConfusions 各主分类最常被误判成的分类
+//
+// 参数:
+//   - outcomes: SelfTest 返回的结果
+//
+// 返回值:
+//   - map[string]string: 正确的主分类 -> 最常见的错误主分类（出错的样本中出现次数最多的）
+func SelftestConfusions(outcomes []SelftestOutcome) map[string]string {
+	counts := make(map[string]map[string]int)
+	for _, o := range outcomes {
+		if o.CategoryOK || o.Result.Source == "error" {
+			continue
+		}
+		if counts[o.Sample.Category] == nil {
+			counts[o.Sample.Category] = make(map[string]int)
+		}
+		counts[o.Sample.Category][o.Result.Category]++
+	}
+
+	confusions := make(map[string]string, len(counts))
+	for gold, wrong := range counts {
+		names := make([]string, 0, len(wrong))
+		for name := range wrong {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if wrong[names[i]] != wrong[names[j]] {
+				return wrong[names[i]] > wrong[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		confusions[gold] = names[0]
+	}
+	return confusions
+}
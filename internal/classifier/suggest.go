@@ -0,0 +1,187 @@
+// Package classifier 智能分类模块
+// suggest.go - 建议新分类：把归入「未分类」或置信度偏低的文件按文件名聚类，
+// 由模型为每一组起分类名，用户认可后加入分类体系并生成种子规则（filo suggest）
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"sort"
+	"strings"
+
+	"filo/internal/memory"
+)
+
+// 建议新分类的参数
+const (
+	SuggestBatchSize   = 8  // 每次请求发送给模型的分组数
+	SuggestSampleFiles = 12 // 每组发送给模型的文件名示例数
+	SuggestMaxKeywords = 3  // 每个建议最多生成的关键词规则数
+)
+
+// CategorySuggestion 一个新分类的建议
+type CategorySuggestion struct {
+	Category    string   // 主分类
+	Subcategory string   // 子分类
+	Description string   // 分类说明
+	Keywords    []string // 作为种子规则的关键词，至少出现在组内两个文件名中
+	Files       []string // 这一组的文件名
+}
+
+// Path 分类路径（主分类/子分类）
+func (s CategorySuggestion) Path() string {
+	if s.Subcategory == "" {
+		return s.Category
+	}
+	return s.Category + "/" + s.Subcategory
+}
+
+// UnsortedClusters 找出没有合适分类的文件并按文件名聚类
+//
+// 参数:
+//   - threshold: 置信度阈值，最近一次分类低于该值的文件参与聚类
+//   - similarity: 归入同一组所需的最低相似度
+//   - minSize: 一组至少包含的文件数
+//   - limit: 参与聚类的最大文件数（最近分类的优先）
+//
+// 返回值:
+//   - int: 没有合适分类的文件数
+//   - []memory.Cluster: 文件数不少于 minSize 的分组
+//   - error: 如果查询失败，返回错误
+func (c *Classifier) UnsortedClusters(threshold, similarity float64, minSize, limit int) (int, []memory.Cluster, error) {
+	files, err := c.db.GetUnsortedFiles(threshold, limit)
+	if err != nil || len(files) == 0 {
+		return 0, nil, err
+	}
+	return len(files), c.memory.ClusterFiles(files, similarity, minSize), nil
+}
+
+// NameClusters 由模型为每组文件建议分类名
+// 模型给出的关键词只保留至少出现在组内两个文件名中的词，没有时使用组内多数文件共有的关键词；
+// 多组得到同一分类时合并为一个建议
+//
+// 参数:
+//   - clusters: UnsortedClusters 返回的分组
+//
+// 返回值:
+//   - []CategorySuggestion: 建议的新分类，按文件数降序
+//   - error: 如果模型调用失败，返回错误（已得到的建议仍然返回）
+func (c *Classifier) NameClusters(clusters []memory.Cluster) ([]CategorySuggestion, error) {
+	var suggestions []CategorySuggestion
+	index := make(map[string]int) // 分类路径 -> 下标
+
+	for i := 0; i < len(clusters); i += SuggestBatchSize {
+		if c.Interrupted() {
+			break
+		}
+		end := i + SuggestBatchSize
+		if end > len(clusters) {
+			end = len(clusters)
+		}
+		batch := clusters[i:end]
+
+		batchData := make([]map[string]interface{}, len(batch))
+		for j, cl := range batch {
+			samples := cl.Files
+			if len(samples) > SuggestSampleFiles {
+				samples = samples[:SuggestSampleFiles]
+			}
+			names := make([]string, len(samples))
+			for k, f := range samples {
+				names[k] = c.redactor.Mask(f)
+			}
+			keywords := make([]string, len(cl.Keywords))
+			for k, w := range cl.Keywords {
+				keywords[k] = c.redactor.Mask(w)
+			}
+			batchData[j] = map[string]interface{}{
+				"id":       j,
+				"count":    len(cl.Files),
+				"files":    names,
+				"keywords": keywords,
+			}
+		}
+		resp, err := c.llm.NameClustersWithRetry(c.ctx, batchData)
+		if err != nil {
+			return suggestions, err
+		}
+		c.redactor.RestoreMap(resp)
+
+		items, _ := resp["suggestions"].([]interface{})
+		named := make(map[int]bool)
+		for _, item := range items {
+			m, _ := item.(map[string]interface{})
+			if m == nil {
+				continue
+			}
+			id, ok := m["id"].(float64)
+			if !ok || id < 0 || int(id) >= len(batch) || named[int(id)] {
+				continue // 编号无效或重复
+			}
+			named[int(id)] = true
+			cl := batch[int(id)]
+			category, subcategory := Normalize(getString(m, "category", ""), getString(m, "subcategory", ""))
+			if category == "未分类" || subcategory == "未分类" {
+				continue
+			}
+			s := CategorySuggestion{Category: category, Subcategory: subcategory, Description: getString(m, "description", "")}
+
+			if k, ok := index[s.Path()]; ok {
+				suggestions[k].Files = append(suggestions[k].Files, cl.Files...)
+				suggestions[k].Keywords = mergeKeywords(suggestions[k].Keywords, c.seedKeywords(getStringSlice(m, "keywords"), cl))
+				continue
+			}
+			s.Files = cl.Files
+			s.Keywords = c.seedKeywords(getStringSlice(m, "keywords"), cl)
+			index[s.Path()] = len(suggestions)
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	// 合并后文件数可能改变，重新排序
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return len(suggestions[i].Files) > len(suggestions[j].Files)
+	})
+	return suggestions, nil
+}
+
+// seedKeywords 挑选作为种子规则的关键词
+// 模型给出的词至少出现在组内两个文件名中才保留（规则才能匹配到同类文件），年份、编号等纯数字不保留；
+// 都不符合时使用组内共有的关键词
+func (c *Classifier) seedKeywords(proposed []string, cl memory.Cluster) []string {
+	minCount := 2
+	if len(cl.Files) < minCount {
+		minCount = len(cl.Files)
+	}
+	var keywords []string
+	for _, w := range proposed {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if len(w) >= memory.MinKeywordLength && strings.Trim(w, "0123456789") != "" && c.memory.KeywordCount(w, cl.Files) >= minCount {
+			keywords = mergeKeywords(keywords, []string{w})
+		}
+	}
+	if len(keywords) > 0 {
+		return keywords
+	}
+	for _, w := range cl.Keywords {
+		if len(w) >= memory.MinKeywordLength {
+			keywords = mergeKeywords(keywords, []string{w})
+		}
+	}
+	return keywords
+}
+
+// mergeKeywords 合并关键词并去重，保持原有顺序，最多保留 SuggestMaxKeywords 个
+func mergeKeywords(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := make([]string, 0, len(a)+len(b))
+	for _, w := range append(append([]string(nil), a...), b...) {
+		if !seen[w] && len(merged) < SuggestMaxKeywords {
+			seen[w] = true
+			merged = append(merged, w)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,104 @@
+// Package classifier 智能分类器模块
+// vision.go - 看图分类
+// IMG_1234.jpg、Screenshot_xxx.png 这类文件名不含信息的图片，把缩略图发给本地多模态模型，
+// 区分截图、表情包、票据、拍照文档和个人照片；识别失败的图片仍交给文本模型按文件名分类
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// visionTimeout 单张图片的识别超时
+const visionTimeout = 90 * time.Second
+
+// genericImageRe 相机、手机、截图工具和聊天软件生成的默认文件名
+var genericImageRe = regexp.MustCompile(`(?i)^(img|dsc|dscn|dcim|pxl|mvimg|photo|image|pic)[_\-\s]?\d|` +
+	`screenshot|screen shot|截图|截屏|屏幕快照|微信图片|mmexport|wx_camera|^\d{8,}[_\-]?\d*\.`)
+
+// IsGenericImage 判断图片的文件名是否不含内容信息，适合看图分类
+// 仅在云端的图片不看图，避免触发下载
+func IsGenericImage(f scanner.FileInfo) bool {
+	return scanner.ThumbnailExts[f.Extension] && f.Cloud == "" &&
+		f.Size > 0 && f.Size <= scanner.MaxThumbnailFile &&
+		genericImageRe.MatchString(f.Name)
+}
+
+// splitVisionFiles 拆分出需要看图分类的图片
+func splitVisionFiles(files []scanner.FileInfo) (images, rest []scanner.FileInfo) {
+	for _, f := range files {
+		if IsGenericImage(f) {
+			images = append(images, f)
+		} else {
+			rest = append(rest, f)
+		}
+	}
+	return images, rest
+}
+
+// classifyWithVision 逐张看图分类
+// 返回分类结果和识别失败、需要按文件名分类的图片
+func (c *Classifier) classifyWithVision(images []scanner.FileInfo, verbose bool) ([]Result, []scanner.FileInfo) {
+	ui.Title("👁", fmt.Sprintf("看图分类 %d 张图片", len(images)))
+	ui.Info("模型: %s", ui.Bold(c.cfg.VisionModel))
+
+	start := time.Now()
+	bar := newProgressBar(len(images), "  识别中")
+
+	var results []Result
+	var failed []scanner.FileInfo
+	for _, f := range images {
+		if c.Interrupted() {
+			break
+		}
+		r, err := c.classifyImage(f)
+		bar.Add(1)
+		if err != nil {
+			if c.Interrupted() {
+				break
+			}
+			failed = append(failed, f)
+			if verbose {
+				fmt.Println()
+				ui.Warning("%s 识别失败，改为按文件名分类: %v", f.Name, err)
+			}
+			continue
+		}
+		results = append(results, r)
+	}
+	if !ui.IsQuiet() {
+		fmt.Println()
+	}
+
+	c.timing.Vision = time.Since(start)
+	ui.Dim("耗时: %.1fs | 识别 %d 张，失败 %d 张", c.timing.Vision.Seconds(), len(results), len(failed))
+	return results, failed
+}
+
+// classifyImage 生成缩略图并交给多模态模型分类
+func (c *Classifier) classifyImage(f scanner.FileInfo) (Result, error) {
+	thumb, err := scanner.Thumbnail(f.Path, scanner.ThumbnailSize)
+	if err != nil {
+		return Result{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, visionTimeout)
+	defer cancel()
+	resp, err := c.llm.ClassifyImage(ctx, c.cfg.VisionModel, f.Name, thumb)
+	if err != nil {
+		return Result{}, err
+	}
+
+	r := toResult(f, resp)
+	r.Source = "vision"
+	return r, nil
+}
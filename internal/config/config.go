@@ -10,6 +10,7 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"os/user"
 	"path/filepath"
 	"sync"
 )
@@ -23,15 +24,73 @@ const (
 	License   = "MIT"                            // 开源许可
 )
 
+// LLM 提供方
+const (
+	ProviderOllama    = "ollama"    // 本地 Ollama（默认）
+	ProviderAnthropic = "anthropic" // Anthropic Claude（远程）
+	ProviderGemini    = "gemini"    // Google Gemini（远程）
+)
+
+// CategoryThreshold 单个主分类的阈值覆盖
+// 字段为 0 时使用全局阈值
+type CategoryThreshold struct {
+	Similarity float64 `json:"similarity,omitempty"` // 记忆命中所需的相似度
+	Confidence float64 `json:"confidence,omitempty"` // 低于此置信度视为低置信度
+}
+
+// CategoryQuota 分类文件夹的容量上限（软限制）
+// 字段为 0 时不限制该项
+type CategoryQuota struct {
+	MaxFiles int    `json:"max_files,omitempty"` // 文件夹中最多的文件数
+	MaxMB    int    `json:"max_mb,omitempty"`    // 文件夹中文件的最大总大小（MB）
+	Overflow string `json:"overflow,omitempty"`  // 超出后的处理: month（按修改月份放入子文件夹，默认）/ warn（只在计划中提示）
+}
+
+// ClassifierPlugin 外部分类插件
+// 插件是一个可执行程序：从标准输入读取一批文件信息（JSON），向标准输出返回分类结果
+type ClassifierPlugin struct {
+	Name       string   `json:"name"`                 // 插件名称（显示在分类理由中）
+	Command    []string `json:"command"`              // 程序及参数
+	Extensions []string `json:"extensions,omitempty"` // 只处理这些扩展名的文件（如 .pdf），为空表示全部
+	Timeout    int      `json:"timeout,omitempty"`    // 单次调用超时（秒），0 表示 60 秒
+}
+
+// MemoryWeights 记忆综合打分（memory_scoring = ensemble）的权重
+type MemoryWeights struct {
+	Rule      float64 `json:"rule"`      // 规则匹配的权重
+	Vector    float64 `json:"vector"`    // 向量匹配的权重
+	History   float64 `json:"history"`   // 历史匹配的权重
+	Agreement float64 `json:"agreement"` // 每多一个来源给出相同分类时增加的置信度
+}
+
 // Config 全局配置结构体
 // 包含模型配置、学习配置和处理配置
 type Config struct {
 	// ==================== 模型配置 ====================
 	LLMModel       string  `json:"llm_model"`       // LLM 模型名称（用于分类）
 	EmbeddingModel string  `json:"embedding_model"` // 向量嵌入模型名称
+	Embedder       string  `json:"embedder"`        // 嵌入器: ollama（嵌入模型，不可用时回退本地）/ local（本地哈希）/ exec（外部程序）/ 已注册的第三方嵌入器
 	OllamaURL      string  `json:"ollama_url"`      // Ollama 服务地址
 	Temperature    float64 `json:"temperature"`     // 模型温度（0-1，越低越确定）
 	MaxTokens      int     `json:"max_tokens"`      // 最大生成 token 数
+	Offline        bool    `json:"offline"`         // 离线模式：只用记忆和扩展名分类，不调用 LLM
+	RulesOnly      bool    `json:"rules_only"`      // 仅规则模式：只用规则分类，不做向量检索、不调用 LLM，结果可复现
+
+	// embedder 为 exec 时启动的外部程序及参数，通过标准输入输出交换 JSON（见 embedding/plugin.go）
+	EmbedderCommand []string `json:"embedder_command"`
+
+	// ==================== 调用策略配置 ====================
+	LLMTimeout      int `json:"llm_timeout"`       // 单批分类超时（秒）
+	LLMRetries      int `json:"llm_retries"`       // 失败后的重试次数
+	LLMRetryBackoff int `json:"llm_retry_backoff"` // 首次重试等待时间（毫秒），之后每次翻倍
+
+	// ==================== 远程提供方配置 ====================
+	LLMProvider        string `json:"llm_provider"`         // LLM 提供方: ollama / anthropic / gemini
+	RemoteModel        string `json:"remote_model"`         // 远程模型名称（为空时使用提供方默认模型）
+	AnthropicAPIKey    string `json:"anthropic_api_key"`    // Anthropic API 密钥（环境变量 ANTHROPIC_API_KEY 优先）
+	GeminiAPIKey       string `json:"gemini_api_key"`       // Gemini API 密钥（环境变量 GEMINI_API_KEY 优先）
+	AllowRemoteContent bool   `json:"allow_remote_content"` // 是否允许向远程提供方发送文件内容（默认只发送文件名）
+	RedactFilenames    bool   `json:"redact_filenames"`     // 发送给远程提供方前隐去文件名中的手机号、证件号、邮箱
 
 	// ==================== 学习配置 ====================
 	EnableLearning      bool    `json:"enable_learning"`       // 是否启用学习功能
@@ -39,8 +98,164 @@ type Config struct {
 	ConfidenceThreshold float64 `json:"confidence_threshold"`  // 置信度阈值（0-1）
 	MinSamplesForRule   int     `json:"min_samples_for_rule"`  // 生成规则所需的最小样本数
 
+	// 追加的文件名停用词：提取关键词和学习规则时跳过（内置「副本」「final」「新建」等，见 memory/stopwords.go）
+	StopWords []string `json:"stop_words"`
+
+	// 按主分类覆盖相似度/置信度阈值，如媒体文件出错代价低可放宽，合同等文档可收紧
+	CategoryThresholds map[string]CategoryThreshold `json:"category_thresholds"`
+
+	// 记忆来源的取舍方式: first（按规则 -> 向量 -> 历史取第一个达到阈值的结果，默认）/
+	// ensemble（按权重综合三种来源，来源一致时加分，相互矛盾时降低置信度）
+	MemoryScoring string        `json:"memory_scoring"`
+	MemoryWeights MemoryWeights `json:"memory_weights"` // ensemble 模式下各来源的权重
+
+	// 精确匹配：同名且内容相同（大小和内容哈希一致）的文件在该天数内分类过时直接沿用上次的分类，
+	// 不做记忆打分和向量检索；0 表示关闭
+	ExactMatchDays  int `json:"exact_match_days"`
+	ResultCacheSize int `json:"result_cache_size"` // 进程内缓存的分类结果数（网页控制台、MCP 服务多次整理时复用），0 表示不缓存
+
+	// ==================== OCR 配置 ====================
+	// 识别扫描件和截图中的文字辅助分类，需要本机安装 tesseract 或多模态模型
+	OCR          string `json:"ocr"`           // OCR 引擎: 空（关闭，默认）/ tesseract / vision
+	OCRModel     string `json:"ocr_model"`     // vision 引擎使用的 Ollama 多模态模型
+	OCRLanguages string `json:"ocr_languages"` // tesseract 识别语言
+
+	// ==================== 图片识别配置 ====================
+	// IMG_1234.jpg、Screenshot_xxx.png 等文件名看不出内容的图片，用本地多模态模型看图分类
+	VisionClassify bool   `json:"vision_classify"` // 是否启用看图分类
+	VisionModel    string `json:"vision_model"`    // 看图分类使用的 Ollama 多模态模型
+
+	// 截图按来源应用归入 图片/截图/微信、图片/截图/网页 等子文件夹：先按文件名识别（微信截图_xxx、
+	// Android 的 Screenshot_xxx_WeChat），文件名中没有应用且开启了 OCR 时识别顶部栏的文字
+	ScreenshotApps bool `json:"screenshot_apps"`
+
+	// ==================== 存储配置 ====================
+	VectorBackend string `json:"vector_backend"` // 向量存储后端: json（目前唯一可用的后端）
+
+	DBBusyTimeout int `json:"db_busy_timeout"` // 其他进程写入数据库时的最长等待时间（毫秒）
+	// filo web / filo mcp 等长时间运行时写回并截断 WAL 文件的间隔（分钟），0 表示只在退出时执行
+	WALCheckpointInterval int `json:"wal_checkpoint_interval"`
+
+	// 团队共享的学习记录：rqlite 节点地址（如 http://nas.local:4001，可带 user:password@），
+	// 分类历史、规则、向量和纠正记录读写共享数据库，操作日志、待确认队列等仍在本机；为空时只用本机数据库
+	SharedDB string `json:"shared_db"`
+	UserName string `json:"user_name"` // 共享数据库中记录的用户名（为空时使用 系统用户名@主机名）
+
 	// ==================== 处理配置 ====================
-	BatchSize int `json:"batch_size"` // 批量处理大小（每批分类的文件数）
+	BatchSize   int  `json:"batch_size"`   // 批量处理大小（每批分类的文件数）
+	ReadContent bool `json:"read_content"` // 是否读取文本文件开头内容辅助分类
+
+	// 按模型的历史耗时自动选择批大小（快模型批次大、慢模型批次小并同时发送多批），
+	// 没有历史数据时使用 batch_size；filo config --batch 固定批大小后关闭
+	AutoBatch   bool `json:"auto_batch"`
+	LLMParallel int  `json:"llm_parallel"` // 慢模型同时发送的最大批次数
+
+	// 快速模式：记忆查询只做内容和规则匹配，不做向量和历史匹配，AI 分类的批大小加大；
+	// fast_mode_files 为本次整理的文件数超过多少时自动启用，0 表示不自动启用
+	Fast          bool `json:"fast"`
+	FastModeFiles int  `json:"fast_mode_files"`
+
+	// AI 分类前按模型的历史耗时估算总耗时，超过该分钟数时询问是否换用更快的模型或开启并行，0 表示不询问
+	ETAWarnMinutes int `json:"eta_warn_minutes"`
+
+	// 可疑文件（未完成下载、空文件、损坏文件）处理方式
+	// route: 归入 待处理/未完成下载；skip: 跳过不整理
+	SuspiciousFiles string `json:"suspicious_files"`
+
+	// Dropbox/OneDrive/iCloud/Google Drive 仅在云端的占位文件处理方式
+	// classify: 只按文件名分类，不读取内容；skip: 保持原位不参与分类
+	CloudFiles   string `json:"cloud_files"`
+	SkipUnsynced bool   `json:"skip_unsynced"` // 未下载到本机的文件不移出所在的云盘同步目录
+
+	// 符号链接处理方式
+	// skip: 不整理；follow: 按指向的文件分类，递归扫描时进入指向的目录（检测循环）；link: 按链接本身分类并移动链接
+	Symlinks string `json:"symlinks"`
+
+	// 递归扫描的最大层数（1 只扫描第一层），0 表示不限
+	MaxDepth int `json:"max_depth"`
+	// 扫描时不进入的目录：与目录名或相对扫描目录的路径匹配的 glob 模式，如 node_modules、build*、项目/*/dist
+	Prune []string `json:"prune"`
+
+	// 其他 filo 进程正在整理时的最长等待时间（秒），0 表示不等待直接退出
+	LockTimeout int `json:"lock_timeout"`
+
+	// 整理的默认目标目录，可以包含占位符（如 ~/Archive/{source_dir_name}/{year}），为空时使用 <目录>/已整理
+	TargetDir string `json:"target_dir"`
+
+	// filo archive 的归档根目录，为空时使用 <目录>/已归档，同样可以包含占位符
+	ArchiveDir string `json:"archive_dir"`
+
+	// 审计模式（--audit）的异常判定阈值
+	AuditHugeMB    int `json:"audit_huge_mb"`    // 超过该大小（MB）的文件列为大文件
+	AuditStaleDays int `json:"audit_stale_days"` // 超过该天数未修改的文件列为陈旧文件
+
+	// ==================== 安全配置 ====================
+	ProtectedPaths []string `json:"protected_paths"` // 额外的受保护目录（目录及子目录都不会被整理）
+	AllowedRoots   []string `json:"allowed_roots"`   // 允许整理的目录，为空表示不限制
+
+	// 可执行文件、脚本和安装包归入 隔离区/ 并记录 SHA-256，哈希在白名单中的文件照常分类
+	Quarantine          bool   `json:"quarantine"`           // 是否启用隔离模式
+	QuarantineAllowlist string `json:"quarantine_allowlist"` // 哈希白名单文件（为空时使用 ~/.filo/allowlist.txt）
+
+	// 低置信度文件（低于 confidence_threshold）处理方式
+	// file: 照常归档；review: 移入 待确认/ 并加入待确认队列；keep: 留在原处并加入待确认队列
+	LowConfidenceAction string `json:"low_confidence_action"`
+
+	// 交互审查（-i 和 filo review）时的文件预览
+	// auto: 文本前几行、PDF 标题、图片尺寸和缩略图；text: 不显示图片缩略图；off: 不预览
+	ReviewPreview string `json:"review_preview"`
+
+	// 目标文件夹已有同名文件时的处理方式
+	// suffix: 添加数字后缀；overwrite-identical: 内容相同时只保留一份；keep-newest: 保留较新的文件；
+	// timestamp: 添加修改时间后缀；skip: 跳过并在结果中列出
+	ConflictStrategy string `json:"conflict_strategy"`
+
+	// 软删除：整理中要删除的文件（与已有文件内容相同的重复文件）先移入 ~/.filo/trash/<批次ID>/，
+	// 保留的天数，到期后由 filo purge 永久删除；为 0 时直接删除
+	SoftDeleteDays int `json:"soft_delete_days"`
+
+	// 原子执行：任何文件移动失败（或整理被中断）时把已移动的文件全部移回原处，
+	// 正被其他程序使用的文件也使整批不执行
+	Atomic bool `json:"atomic"`
+
+	// 跨设备移动：目标在另一个磁盘（如 NAS）上、无法直接改名时，复制并校验 SHA-256 一致后删除源文件；
+	// 关闭时这些文件移动失败，可以之后用 filo retry --copy 重试
+	CrossDeviceCopy bool `json:"cross_device_copy"`
+
+	// 按已有文件夹整理：只把文件归入目标目录中已有的文件夹（最多三层），不新建分类；
+	// 没有对应文件夹的文件按低置信度处理，low_confidence_action 为 file 时改为 review
+	ExistingFolders bool `json:"existing_folders"`
+
+	// 分类文件夹说明：readme 写入 README.md，folderinfo 写入 .folderinfo，为空时不生成
+	// 内容来自分类体系（taxonomy.json）中的分类说明，已有同名文件时不覆盖
+	FolderInfo string `json:"folder_info"`
+	// 按分类体系中的 color / icon 设置主分类文件夹的 Finder 标签颜色和图标（仅 macOS）
+	FolderAppearance bool `json:"folder_appearance"`
+	// 整理信息随文件保存：json 在文件旁写入隐藏的 .<文件名>.filo.json，xattr 写入扩展属性（macOS、Linux），
+	// 记录分类、置信度、批次和原来的位置，复制到别处后可以用 filo sidecar import 导入；为空时不保存
+	Sidecar string `json:"sidecar"`
+
+	// 文件夹名称：分类名在学习记录和规则中保持不变，建立文件夹时换成显示名称
+	// folder_language 为空时与分类名相同，en 内置分类使用英文名（文档/合同 → Documents/Contracts），
+	// zh 常见的英文分类名使用中文名；folder_names 按单级分类名指定文件夹名，优先于 folder_language
+	FolderLanguage string            `json:"folder_language"`
+	FolderNames    map[string]string `json:"folder_names"`
+
+	// 文件移入分类文件夹后执行的操作，键为分类路径（如 备份、图片/照片，同时作用于下级分类），
+	// 值为操作列表: compress（gzip 压缩）/ heic-to-jpg（HEIC 照片另存一份 JPG）/ readonly（设为只读）
+	CategoryActions map[string][]string `json:"category_actions"`
+
+	// 分类文件夹的容量上限，键为分类路径（如 图片/截图，同时作用于下级分类的文件夹），
+	// 超出后新文件按修改月份放入子文件夹（如 图片/截图/2024-06），或只在计划中提示
+	CategoryQuotas map[string]CategoryQuota `json:"category_quotas"`
+
+	// 外部分类插件（如识别论文的专用分类器），结果与记忆和 AI 的结果按置信度合并
+	ClassifierPlugins []ClassifierPlugin `json:"classifier_plugins"`
+
+	// ==================== 通知配置 ====================
+	// 静默模式（--quiet）运行结束后发送整理摘要
+	NotifyDesktop bool   `json:"notify_desktop"` // 发送系统桌面通知
+	NotifyWebhook string `json:"notify_webhook"` // Webhook 地址（Slack / Discord / ntfy，其他地址发送通用 JSON）
 
 	// ==================== 内部路径（不序列化）====================
 	DataDir string `json:"-"` // 数据目录路径 (~/.filo)
@@ -70,14 +285,50 @@ func defaultConfig() *Config {
 	return &Config{
 		LLMModel:            "qwen3:8b",              // 默认使用 qwen3:8b 模型
 		EmbeddingModel:      "nomic-embed-text",      // 默认嵌入模型
+		Embedder:            "ollama",                // 默认使用嵌入模型
 		OllamaURL:           "http://localhost:11434", // Ollama 默认地址
 		Temperature:         0.3,                      // 较低温度保证输出稳定
 		MaxTokens:           2048,                     // 最大 token 数
+		LLMTimeout:          120,                      // 单批 2 分钟超时
+		LLMRetries:          2,                        // 失败后重试 2 次
+		LLMRetryBackoff:     2000,                     // 首次重试等待 2 秒
+		LLMProvider:         ProviderOllama,           // 默认使用本地 Ollama
+		RedactFilenames:     true,                     // 远程提供方看不到文件名中的个人信息
 		EnableLearning:      true,                     // 默认启用学习
 		SimilarityThreshold: 0.85,                     // 相似度阈值 85%
 		ConfidenceThreshold: 0.7,                      // 置信度阈值 70%
 		MinSamplesForRule:   3,                        // 至少3个样本才生成规则
+		StopWords:           []string{},
+		CategoryThresholds:  map[string]CategoryThreshold{},
+		MemoryScoring:       "first",                  // 按优先级取第一个命中的记忆来源
+		MemoryWeights:       MemoryWeights{Rule: 0.5, Vector: 0.3, History: 0.2, Agreement: 0.05},
+		ExactMatchDays:      30,                       // 30 天内分类过的同一文件直接沿用
+		ResultCacheSize:     4096,                     // 进程内最多缓存 4096 个分类结果
+		OCRModel:            "qwen2.5vl:7b",           // 中文识别较好的多模态模型
+		OCRLanguages:        "chi_sim+eng",            // 简体中文 + 英文
+		VisionModel:         "qwen2.5vl:7b",           // 看图分类模型
+		VectorBackend:       "json",                   // 默认使用 JSON 向量存储
+		DBBusyTimeout:       5000,                     // 数据库忙时最多等待 5 秒
+		WALCheckpointInterval: 10,                     // 每 10 分钟截断一次 WAL
 		BatchSize:           15,                       // 每批处理15个文件
+		AutoBatch:           true,                     // 按模型耗时自动调整批大小
+		LLMParallel:         2,                        // 慢模型最多同时发送 2 批
+		FastModeFiles:       5000,                     // 超过 5000 个文件时自动启用快速模式
+		ETAWarnMinutes:      10,                       // 预计超过 10 分钟时询问
+		SuspiciousFiles:     "route",                  // 可疑文件归入待处理
+		CloudFiles:          "classify",               // 云端文件只按文件名分类
+		SkipUnsynced:        true,                     // 云端文件不移出同步目录
+		Symlinks:            "skip",                   // 不整理符号链接
+		LockTimeout:         60,                       // 最多等待其他进程 1 分钟
+		AuditHugeMB:         1024,                     // 1GB 以上为大文件
+		AuditStaleDays:      365,                      // 一年未修改为陈旧文件
+		LowConfidenceAction: "file",                   // 低置信度文件照常归档
+		ReviewPreview:       "auto",                   // 审查时预览文件内容
+		ConflictStrategy:    "suffix",                 // 重名文件添加数字后缀
+		CrossDeviceCopy:     true,                     // 跨设备时复制校验后删除源文件
+		CategoryActions:     map[string][]string{},
+		FolderNames:         map[string]string{},
+		CategoryQuotas:      map[string]CategoryQuota{},
 	}
 }
 
@@ -98,7 +349,18 @@ func (c *Config) Load() error {
 	if err != nil {
 		return err // 文件不存在时返回错误，使用默认配置
 	}
-	return json.Unmarshal(data, c)
+	if err := json.Unmarshal(data, c); err != nil {
+		return err
+	}
+	// 没有 embedder 的配置文件来自默认使用本地嵌入的旧版本，数据库中的向量都是本地生成的，
+	// 继续使用本地嵌入，避免升级后向量匹配悄悄失效；改用嵌入模型后运行 filo maintain --reembed
+	var keys map[string]json.RawMessage
+	if json.Unmarshal(data, &keys) == nil {
+		if _, ok := keys["embedder"]; !ok {
+			c.Embedder = "local"
+		}
+	}
+	return nil
 }
 
 // Save 保存配置到文件
@@ -115,5 +377,102 @@ func (c *Config) Save() error {
 // SetModel 设置 LLM 模型
 // 用于通过命令行参数临时切换模型
 func (c *Config) SetModel(model string) {
+	if c.IsRemoteProvider() {
+		c.RemoteModel = model
+		return
+	}
 	c.LLMModel = model
 }
+
+// User 写入共享数据库的用户名，未配置 user_name 时使用 系统用户名@主机名
+func (c *Config) User() string {
+	if c.UserName != "" {
+		return c.UserName
+	}
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		return name + "@" + host
+	}
+	return name
+}
+
+// IsRemoteProvider 是否使用远程 LLM 提供方
+// 远程提供方会把文件名发送到第三方服务，需要用户显式允许
+func (c *Config) IsRemoteProvider() bool {
+	return c.LLMProvider != "" && c.LLMProvider != ProviderOllama
+}
+
+// ActiveModel 当前实际使用的模型名称
+// 远程提供方未指定模型时使用其默认模型
+func (c *Config) ActiveModel() string {
+	if !c.IsRemoteProvider() {
+		return c.LLMModel
+	}
+	if c.RemoteModel != "" {
+		return c.RemoteModel
+	}
+	switch c.LLMProvider {
+	case ProviderAnthropic:
+		return "claude-3-5-haiku-latest"
+	case ProviderGemini:
+		return "gemini-1.5-flash"
+	}
+	return c.LLMModel
+}
+
+// APIKey 获取远程提供方的 API 密钥
+// 环境变量优先于配置文件，避免密钥必须写入磁盘
+func (c *Config) APIKey() string {
+	switch c.LLMProvider {
+	case ProviderAnthropic:
+		if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
+			return key
+		}
+		return c.AnthropicAPIKey
+	case ProviderGemini:
+		if key := os.Getenv("GEMINI_API_KEY"); key != "" {
+			return key
+		}
+		return c.GeminiAPIKey
+	}
+	return ""
+}
+
+// SimilarityThresholdFor 主分类生效的相似度阈值
+// 记忆匹配结果的置信度需达到此值才采用
+func (c *Config) SimilarityThresholdFor(category string) float64 {
+	if t := c.CategoryThresholds[category].Similarity; t > 0 {
+		return t
+	}
+	return c.SimilarityThreshold
+}
+
+// ConfidenceThresholdFor 主分类生效的置信度阈值
+// 低于此值的文件按 low_confidence_action 处理
+func (c *Config) ConfidenceThresholdFor(category string) float64 {
+	if t := c.CategoryThresholds[category].Confidence; t > 0 {
+		return t
+	}
+	return c.ConfidenceThreshold
+}
+
+// OCRAllowed 是否识别扫描件和截图的文字并发送给模型
+// 识别本身只在本机进行；识别出的文字与文件内容一样，远程模型需额外允许
+func (c *Config) OCRAllowed() bool {
+	if c.OCR == "" || c.Offline || c.RulesOnly {
+		return false
+	}
+	return !c.IsRemoteProvider() || c.AllowRemoteContent
+}
+
+// ContentAllowed 是否允许把文件内容发送给模型
+// 本地模型只需开启内容读取；远程模型还需额外允许发送内容
+func (c *Config) ContentAllowed() bool {
+	if !c.ReadContent {
+		return false
+	}
+	return !c.IsRemoteProvider() || c.AllowRemoteContent
+}
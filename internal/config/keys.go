@@ -0,0 +1,271 @@
+// Package config 配置管理模块
+// keys.go - 按键名读写配置项：键名与 config.json 中的名称相同，下级用 . 连接
+// （如 memory_weights.rule、category_thresholds.图片.similarity），供 filo config get/set/unset/list 使用
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KeySep 配置键名的层级分隔符
+const KeySep = "."
+
+// Keys 返回全部顶层配置键名，按 config.json 中的顺序
+func Keys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// Value 读取配置项的值
+//
+// 参数:
+//   - key: 配置键名，如 temperature、memory_weights.rule
+//
+// 返回值:
+//   - interface{}: 配置项的值（JSON 解码后的形式）
+//   - error: 如果键名不存在，返回错误
+func (c *Config) Value(key string) (interface{}, error) {
+	path := strings.Split(key, KeySep)
+	if _, err := keyType(path); err != nil {
+		return nil, err
+	}
+	v, ok := lookup(c.toMap(), path)
+	if !ok {
+		return nil, fmt.Errorf("%s 未设置", key)
+	}
+	return v, nil
+}
+
+// With 返回修改了一个配置项的副本，当前配置不变
+// 字符串按原样使用；数字和布尔值按字段类型解析；字符串列表用逗号分隔；
+// 其他类型（分类阈值、插件列表等）使用 JSON
+//
+// 参数:
+//   - key: 配置键名，分类阈值等按名称索引的配置项可以使用新的名称
+//   - raw: 命令行输入的值
+//
+// 返回值:
+//   - *Config: 修改后的配置
+//   - error: 如果键名不存在或值的类型不符，返回错误
+func (c *Config) With(key, raw string) (*Config, error) {
+	path := strings.Split(key, KeySep)
+	t, err := keyType(path)
+	if err != nil {
+		return nil, err
+	}
+	v, err := parseValue(t, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s 的值 %q 无效: %v", key, raw, err)
+	}
+	m := c.toMap()
+	assign(m, path, v)
+	return c.fromMap(m)
+}
+
+// Without 返回恢复了一个配置项的副本，当前配置不变
+// 默认配置中有该项时恢复为默认值，否则（如某个分类的阈值）删除该项
+//
+// 参数:
+//   - key: 配置键名
+//
+// 返回值:
+//   - *Config: 修改后的配置
+//   - error: 如果键名不存在，返回错误
+func (c *Config) Without(key string) (*Config, error) {
+	path := strings.Split(key, KeySep)
+	if _, err := keyType(path); err != nil {
+		return nil, err
+	}
+	m := c.toMap()
+	if v, ok := lookup(defaultConfig().toMap(), path); ok {
+		assign(m, path, v)
+	} else {
+		remove(m, path)
+	}
+	return c.fromMap(m)
+}
+
+// IsDefault 配置项是否为默认值
+func (c *Config) IsDefault(key string) bool {
+	path := strings.Split(key, KeySep)
+	v, _ := lookup(c.toMap(), path)
+	d, _ := lookup(defaultConfig().toMap(), path)
+	return reflect.DeepEqual(v, d)
+}
+
+// Flatten 把配置展开为逐项的 键名 -> 值，结构体和 map 逐级展开，列表作为一项
+//
+// 返回值:
+//   - []string: 键名，顶层按 config.json 中的顺序，下级按名称排序
+//   - map[string]interface{}: 各键名的值
+func (c *Config) Flatten() ([]string, map[string]interface{}) {
+	values := make(map[string]interface{})
+	var keys []string
+	var walk func(key string, v interface{})
+	walk = func(key string, v interface{}) {
+		obj, ok := v.(map[string]interface{})
+		if !ok || len(obj) == 0 {
+			keys = append(keys, key)
+			values[key] = v
+			return
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			walk(key+KeySep+name, obj[name])
+		}
+	}
+	m := c.toMap()
+	for _, key := range Keys() {
+		walk(key, m[key])
+	}
+	return keys, values
+}
+
+// ==================== 内部实现 ====================
+
+// jsonName 返回字段在 config.json 中的名称，不序列化的字段返回空字符串
+func jsonName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// keyType 沿键名逐级查找配置项的类型
+// 结构体按字段名查找，map 的下一级可以是任意名称
+func keyType(path []string) (reflect.Type, error) {
+	t := reflect.TypeOf(Config{})
+	for i, name := range path {
+		switch t.Kind() {
+		case reflect.Struct:
+			found := false
+			for j := 0; j < t.NumField(); j++ {
+				if f := t.Field(j); jsonName(f) == name && name != "" {
+					t, found = f.Type, true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("未知的配置项: %s", strings.Join(path[:i+1], KeySep))
+			}
+		case reflect.Map:
+			if name == "" {
+				return nil, fmt.Errorf("配置项 %s 缺少名称", strings.Join(path[:i], KeySep))
+			}
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("配置项 %s 没有下级", strings.Join(path[:i], KeySep))
+		}
+	}
+	return t, nil
+}
+
+// parseValue 按配置项的类型解析命令行输入
+func parseValue(t reflect.Type, raw string) (interface{}, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int:
+		return strconv.Atoi(raw)
+	case reflect.Float64:
+		return strconv.ParseFloat(raw, 64)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			items := []string{}
+			for _, s := range strings.Split(raw, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					items = append(items, s)
+				}
+			}
+			return items, nil
+		}
+	}
+	v := reflect.New(t)
+	if err := json.Unmarshal([]byte(raw), v.Interface()); err != nil {
+		return nil, fmt.Errorf("应为 JSON: %v", err)
+	}
+	return v.Elem().Interface(), nil
+}
+
+// toMap 把配置转换为 JSON 对象
+func (c *Config) toMap() map[string]interface{} {
+	data, _ := json.Marshal(c)
+	var m map[string]interface{}
+	json.Unmarshal(data, &m)
+	return m
+}
+
+// fromMap 从 JSON 对象还原配置，保留数据路径
+func (c *Config) fromMap(m map[string]interface{}) (*Config, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	next := &Config{DataDir: c.DataDir, DBPath: c.DBPath}
+	if err := json.Unmarshal(data, next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// lookup 按键名读取 JSON 对象中的值
+func lookup(m map[string]interface{}, path []string) (interface{}, bool) {
+	var v interface{} = m
+	for _, name := range path {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[name]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// assign 按键名写入 JSON 对象，缺少的上级对象会被创建
+func assign(m map[string]interface{}, path []string, v interface{}) {
+	for _, name := range path[:len(path)-1] {
+		next, ok := m[name].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[name] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = v
+}
+
+// remove 按键名删除 JSON 对象中的值
+func remove(m map[string]interface{}, path []string) {
+	for _, name := range path[:len(path)-1] {
+		next, ok := m[name].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m = next
+	}
+	delete(m, path[len(path)-1])
+}
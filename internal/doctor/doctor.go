@@ -0,0 +1,541 @@
+// Package doctor 环境诊断模块
+// 检查配置取值、Ollama 服务与模型、嵌入模型与已存向量是否兼容、
+// 数据库完整性、WAL 文件大小和目标目录剩余空间，并给出修复建议（filo doctor 使用）
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/embedding"
+	"filo/internal/folderinfo"
+	"filo/internal/llm"
+	"filo/internal/memory"
+	"filo/internal/ocr"
+	"filo/internal/organizer"
+	"filo/internal/preview"
+	"filo/internal/scanner"
+	"filo/internal/sidecar"
+	"filo/internal/storage"
+	"filo/internal/taxonomy"
+	"filo/internal/ui"
+)
+
+// ==================== 常量定义 ====================
+
+// 检查结果状态
+const (
+	StatusOK   = "ok"   // 正常
+	StatusWarn = "warn" // 可以运行，但建议处理
+	StatusFail = "fail" // 会导致功能不可用
+)
+
+const (
+	WALWarnSize     = 64 << 20  // WAL 文件超过该大小时建议执行检查点
+	DiskWarnFree    = 1 << 30   // 剩余空间低于该值时警告
+	DiskFailFree    = 100 << 20 // 剩余空间低于该值时视为错误
+	embedCheckText  = "filo 诊断" // 检查嵌入模型时向量化的文本
+	embedCheckLimit = 30 * time.Second
+)
+
+// ==================== 类型定义 ====================
+
+// Check 单项检查结果
+type Check struct {
+	Name   string // 检查项名称
+	Status string // 状态: ok / warn / fail
+	Detail string // 检查结果说明
+	Fix    string // 修复建议，正常时为空
+}
+
+// ok 构造正常的检查结果
+func ok(name, format string, args ...interface{}) Check {
+	return Check{Name: name, Status: StatusOK, Detail: fmt.Sprintf(format, args...)}
+}
+
+// warn 构造警告的检查结果
+func warn(name, detail, fix string) Check {
+	return Check{Name: name, Status: StatusWarn, Detail: detail, Fix: fix}
+}
+
+// fail 构造错误的检查结果
+func fail(name, detail, fix string) Check {
+	return Check{Name: name, Status: StatusFail, Detail: detail, Fix: fix}
+}
+
+// ==================== 配置检查 ====================
+
+// CheckConfig 检查配置文件格式和各项取值范围
+func CheckConfig() []Check {
+	cfg := config.Get()
+	var checks []Check
+
+	// 配置文件格式错误时 filo 会静默使用默认配置，这里明确指出
+	for _, name := range []string{"config.json", "taxonomy.json"} {
+		path := filepath.Join(cfg.DataDir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			checks = append(checks, fail(name, fmt.Sprintf("格式错误，当前使用默认值: %v", err),
+				fmt.Sprintf("修正 %s 的 JSON 语法，或删除后运行 filo setup 重新生成", path)))
+		}
+	}
+
+	problems := ConfigProblems(cfg)
+	if len(problems) == 0 {
+		checks = append(checks, ok("配置取值", "全部在有效范围内"))
+		return checks
+	}
+	for _, p := range problems {
+		checks = append(checks, fail("配置取值", p.Message,
+			"用 filo config set 修改，或编辑 "+filepath.Join(cfg.DataDir, "config.json")))
+	}
+	return checks
+}
+
+// ConfigProblem 超出有效范围的配置项
+type ConfigProblem struct {
+	Key     string // 配置键名（如 temperature、category_thresholds.图片.similarity）
+	Message string // 问题说明
+}
+
+// ConfigProblems 检查配置各项的取值范围，filo doctor 和 filo config set 共用
+//
+// 参数:
+//   - cfg: 要检查的配置
+//
+// 返回值:
+//   - []ConfigProblem: 超出有效范围的配置项，全部有效时为空
+func ConfigProblems(cfg *config.Config) []ConfigProblem {
+	var problems []ConfigProblem
+	add := func(key, msg string) {
+		problems = append(problems, ConfigProblem{Key: key, Message: msg})
+	}
+	inRange := func(key string, v, min, max float64) {
+		if v < min || v > max {
+			add(key, fmt.Sprintf("%s=%g 应在 %g-%g 之间", key, v, min, max))
+		}
+	}
+	atLeast := func(key string, v, min int) {
+		if v < min {
+			add(key, fmt.Sprintf("%s=%d 应不小于 %d", key, v, min))
+		}
+	}
+	oneOf := func(key, v string, valid ...string) {
+		for _, s := range valid {
+			if v == s {
+				return
+			}
+		}
+		add(key, fmt.Sprintf("%s=%q 应为 %s 之一", key, v, strings.Join(quoteAll(valid), " / ")))
+	}
+
+	inRange("temperature", cfg.Temperature, 0, 1)
+	inRange("similarity_threshold", cfg.SimilarityThreshold, 0.01, 1)
+	inRange("confidence_threshold", cfg.ConfidenceThreshold, 0.01, 1)
+	for _, name := range sortedKeys(cfg.CategoryThresholds) {
+		t := cfg.CategoryThresholds[name]
+		inRange("category_thresholds."+name+".similarity", t.Similarity, 0, 1)
+		inRange("category_thresholds."+name+".confidence", t.Confidence, 0, 1)
+	}
+	inRange("memory_weights.rule", cfg.MemoryWeights.Rule, 0, 1)
+	inRange("memory_weights.vector", cfg.MemoryWeights.Vector, 0, 1)
+	inRange("memory_weights.history", cfg.MemoryWeights.History, 0, 1)
+	inRange("memory_weights.agreement", cfg.MemoryWeights.Agreement, 0, 0.5)
+	atLeast("min_samples_for_rule", cfg.MinSamplesForRule, 1)
+	atLeast("exact_match_days", cfg.ExactMatchDays, 0)
+	atLeast("result_cache_size", cfg.ResultCacheSize, 0)
+	atLeast("max_tokens", cfg.MaxTokens, 1)
+	atLeast("llm_timeout", cfg.LLMTimeout, 1)
+	atLeast("llm_retries", cfg.LLMRetries, 0)
+	atLeast("llm_retry_backoff", cfg.LLMRetryBackoff, 0)
+	atLeast("llm_parallel", cfg.LLMParallel, 1)
+	atLeast("fast_mode_files", cfg.FastModeFiles, 0)
+	atLeast("eta_warn_minutes", cfg.ETAWarnMinutes, 0)
+	atLeast("lock_timeout", cfg.LockTimeout, 0)
+	atLeast("db_busy_timeout", cfg.DBBusyTimeout, 0)
+	atLeast("wal_checkpoint_interval", cfg.WALCheckpointInterval, 0)
+	atLeast("audit_huge_mb", cfg.AuditHugeMB, 1)
+	atLeast("audit_stale_days", cfg.AuditStaleDays, 1)
+	if cfg.BatchSize < 1 || cfg.BatchSize > 100 {
+		add("batch_size", fmt.Sprintf("batch_size=%d 应在 1-100 之间", cfg.BatchSize))
+	}
+
+	oneOf("llm_provider", cfg.LLMProvider, config.ProviderOllama, config.ProviderAnthropic, config.ProviderGemini)
+	oneOf("embedder", cfg.Embedder, embedding.Names()...)
+	if cfg.Embedder == embedding.EmbedderExec && len(cfg.EmbedderCommand) == 0 {
+		add("embedder_command", "embedder=exec 时需要设置 embedder_command")
+	}
+	oneOf("ocr", cfg.OCR, "", ocr.EngineTesseract, ocr.EngineVision)
+	oneOf("memory_scoring", cfg.MemoryScoring, memory.ScoringFirst, memory.ScoringEnsemble)
+	if cfg.VectorBackend != storage.VectorBackendJSON {
+		add("vector_backend", fmt.Sprintf("vector_backend=%q 不可用：内置的 SQLite 驱动无法加载扩展，只支持 %q",
+			cfg.VectorBackend, storage.VectorBackendJSON))
+	}
+	oneOf("suspicious_files", cfg.SuspiciousFiles, "route", "skip")
+	oneOf("cloud_files", cfg.CloudFiles, scanner.CloudFilesClassify, scanner.CloudFilesSkip)
+	oneOf("symlinks", cfg.Symlinks, scanner.SymlinksSkip, scanner.SymlinksFollow, scanner.SymlinksLink)
+	atLeast("max_depth", cfg.MaxDepth, 0)
+	for _, p := range cfg.Prune {
+		if !scanner.ValidPrunePattern(p) {
+			add("prune", fmt.Sprintf("prune 中的模式 %q 无效", p))
+		}
+	}
+	oneOf("low_confidence_action", cfg.LowConfidenceAction,
+		organizer.LowConfidenceFile, organizer.LowConfidenceReview, organizer.LowConfidenceKeep)
+	oneOf("conflict_strategy", cfg.ConflictStrategy, organizer.ConflictStrategies...)
+	atLeast("soft_delete_days", cfg.SoftDeleteDays, 0)
+	oneOf("review_preview", cfg.ReviewPreview, preview.Modes...)
+	oneOf("folder_info", cfg.FolderInfo, folderinfo.FormatNone, folderinfo.FormatReadme, folderinfo.FormatFolderInfo)
+	oneOf("sidecar", cfg.Sidecar, sidecar.Formats...)
+	oneOf("folder_language", cfg.FolderLanguage, classifier.FolderLanguages...)
+	for _, name := range sortedKeys(cfg.FolderNames) {
+		if folder := cfg.FolderNames[name]; strings.TrimSpace(folder) == "" || strings.ContainsAny(folder, `/\<>:"|?*`) {
+			add("folder_names."+name, fmt.Sprintf("folder_names.%s=%q 应为单级文件夹名，不含 / \\ 和 <>:\"|?*", name, folder))
+		}
+	}
+	for _, name := range sortedKeys(cfg.CategoryActions) {
+		for _, a := range cfg.CategoryActions[name] {
+			oneOf("category_actions."+name, strings.ToLower(a), organizer.PostActions...)
+		}
+	}
+	for _, name := range sortedKeys(cfg.CategoryQuotas) {
+		q := cfg.CategoryQuotas[name]
+		atLeast("category_quotas."+name+".max_files", q.MaxFiles, 0)
+		atLeast("category_quotas."+name+".max_mb", q.MaxMB, 0)
+		oneOf("category_quotas."+name+".overflow", q.Overflow, "", organizer.OverflowMonth, organizer.OverflowWarn)
+	}
+	for i, p := range cfg.ClassifierPlugins {
+		key := fmt.Sprintf("classifier_plugins[%d]", i)
+		switch {
+		case p.Name == "":
+			add("classifier_plugins", key+" 缺少 name")
+		case len(p.Command) == 0:
+			add("classifier_plugins", key+"（"+p.Name+"）缺少 command")
+		default:
+			if _, err := exec.LookPath(p.Command[0]); err != nil {
+				add("classifier_plugins", fmt.Sprintf("%s（%s）的程序 %s 不存在或不可执行", key, p.Name, p.Command[0]))
+			}
+		}
+		atLeast(key+".timeout", p.Timeout, 0)
+	}
+	for _, c := range taxonomy.Get().Categories {
+		if !folderinfo.ValidColor(c.Color) {
+			add("taxonomy.json", fmt.Sprintf("taxonomy.json 中 %s 的 color=%q 应为 %s 之一",
+				c.Name, c.Color, strings.Join(folderinfo.Colors, " / ")))
+		}
+	}
+
+	return problems
+}
+
+// quoteAll 为每个取值加引号，便于区分空字符串
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return quoted
+}
+
+// sortedKeys 返回排序后的分类名，保证输出顺序稳定
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ==================== 模型检查 ====================
+
+// CheckModels 检查 LLM 服务是否可用、所需模型是否已安装，以及嵌入模型与已存向量是否兼容
+//
+// 参数:
+//   - db: 数据库连接，为 nil 时跳过向量兼容性检查
+func CheckModels(db *storage.Database) []Check {
+	cfg := config.Get()
+	client := llm.NewClient()
+	var checks []Check
+
+	switch {
+	case cfg.RulesOnly || cfg.Offline:
+		checks = append(checks, ok("LLM", "离线模式，不调用 LLM"))
+	case client.IsRemote():
+		if cfg.APIKey() == "" {
+			checks = append(checks, fail("LLM", fmt.Sprintf("未配置 %s API 密钥", cfg.LLMProvider),
+				"设置环境变量或 config.json 中的 API 密钥，或将 llm_provider 改回 ollama"))
+		} else {
+			checks = append(checks, ok("LLM", "%s / %s", cfg.LLMProvider, cfg.ActiveModel()))
+		}
+	}
+
+	// 嵌入、OCR 和看图分类始终使用本地 Ollama
+	needOllama := cfg.Embedder == embedding.EmbedderOllama || cfg.OCR == ocr.EngineVision || cfg.VisionClassify ||
+		(!client.IsRemote() && !cfg.Offline && !cfg.RulesOnly)
+	ollamaUp := needOllama && ollamaReachable(client)
+	if needOllama {
+		if !ollamaUp {
+			checks = append(checks, fail("Ollama", "无法连接 "+cfg.OllamaURL,
+				"运行 ollama serve 启动服务，或检查 config.json 中的 ollama_url"))
+		} else {
+			checks = append(checks, ok("Ollama", "%s 可以连接", cfg.OllamaURL))
+		}
+	}
+
+	if ollamaUp {
+		if !client.IsRemote() && !cfg.Offline && !cfg.RulesOnly {
+			checks = append(checks, modelCheck(client, "分类模型", cfg.LLMModel, StatusFail))
+			if c, checked := contextCheck(client, cfg.LLMModel); checked {
+				checks = append(checks, c)
+			}
+		}
+		if cfg.Embedder == embedding.EmbedderOllama {
+			checks = append(checks, modelCheck(client, "嵌入模型", cfg.EmbeddingModel, StatusWarn))
+		}
+		if cfg.VisionClassify {
+			checks = append(checks, modelCheck(client, "看图分类模型", cfg.VisionModel, StatusWarn))
+		}
+		if cfg.OCR == ocr.EngineVision {
+			checks = append(checks, modelCheck(client, "OCR 模型", cfg.OCRModel, StatusWarn))
+		}
+	}
+	if cfg.OCR == ocr.EngineTesseract {
+		if err := ocr.Check(); err != nil {
+			checks = append(checks, warn("OCR", err.Error(), "安装 tesseract，或将 config.json 中的 ocr 设为空以关闭 OCR"))
+		} else {
+			checks = append(checks, ok("OCR", "tesseract 已安装"))
+		}
+	}
+
+	if db != nil {
+		checks = append(checks, embeddingCheck(db, client, ollamaUp))
+	}
+	return checks
+}
+
+// ollamaReachable 检查本地 Ollama 服务能否连接（不受远程提供方配置影响）
+func ollamaReachable(client *llm.Client) bool {
+	_, err := client.ListModels()
+	return err == nil
+}
+
+// modelCheck 检查模型是否已安装
+//
+// 参数:
+//   - client: LLM 客户端
+//   - name: 检查项名称
+//   - model: 模型名称
+//   - missing: 模型未安装时的状态
+func modelCheck(client *llm.Client, name, model, missing string) Check {
+	if client.HasLocalModel(model) {
+		return ok(name, "%s 已安装", model)
+	}
+	return Check{Name: name, Status: missing, Detail: model + " 未安装", Fix: "运行 ollama pull " + model}
+}
+
+// contextCheck 检查分类模型的能力和上下文窗口能否放下一批文件的提示词和 max_tokens
+// 模型未安装或旧版本 Ollama 无法读取模型信息时不检查（checked 为 false）
+func contextCheck(client *llm.Client, model string) (c Check, checked bool) {
+	const name = "上下文窗口"
+	if !client.HasLocalModel(model) {
+		return Check{}, false
+	}
+	info, err := client.ShowModel(model)
+	if err != nil {
+		return Check{}, false
+	}
+	if !info.Has(llm.CapCompletion) {
+		return fail(name, fmt.Sprintf("%s 不能用于分类（能力: %s）", model, strings.Join(info.Capabilities, ", ")),
+			"在 config.json 中将 llm_model 改为对话模型"), true
+	}
+
+	cfg := config.Get()
+	fit := client.EstimateFit(info, cfg.BatchSize)
+	switch {
+	case fit.Window == 0:
+		return ok(name, "%s 未提供上下文长度", model), true
+	case fit.Fits():
+		return ok(name, "%d tokens，每批 %d 个文件约 %d + max_tokens %d", fit.Window, fit.BatchSize, fit.PromptTokens, fit.OutputTokens), true
+	case fit.MaxBatch > 0:
+		return warn(name, fmt.Sprintf("%d tokens 放不下每批 %d 个文件的提示词（约 %d）和 max_tokens %d", fit.Window, fit.BatchSize, fit.PromptTokens, fit.OutputTokens),
+			fmt.Sprintf("将 batch_size 调小到 %d 以内，或调小 max_tokens", fit.MaxBatch)), true
+	default:
+		return warn(name, fmt.Sprintf("%d tokens 放不下 max_tokens %d 和提示词", fit.Window, fit.OutputTokens),
+			"调小 max_tokens，或换用上下文窗口更大的模型"), true
+	}
+}
+
+// embeddingCheck 比较当前嵌入器与数据库中已存向量的嵌入器和维度
+// 更换嵌入模型后旧向量不再参与相似度匹配，学习记忆实际上失效
+func embeddingCheck(db *storage.Database, client *llm.Client, ollamaUp bool) Check {
+	const name = "嵌入兼容性"
+	cfg := config.Get()
+
+	spaces, err := db.VectorSpaces()
+	if err != nil {
+		return warn(name, fmt.Sprintf("无法统计已存向量: %v", err), "")
+	}
+
+	// 当前嵌入器生成的向量维度
+	current, source, embedder := 0, "本地嵌入器", embedding.EmbedderLocal
+	if cfg.Embedder == embedding.EmbedderOllama && ollamaUp && client.HasLocalModel(cfg.EmbeddingModel) {
+		ctx, cancel := context.WithTimeout(context.Background(), embedCheckLimit)
+		defer cancel()
+		vec, err := client.Embed(ctx, embedCheckText)
+		if err != nil || len(vec) == 0 {
+			return fail(name, fmt.Sprintf("%s 无法生成向量: %v", cfg.EmbeddingModel, err),
+				"确认 embedding_model 是嵌入模型（如 nomic-embed-text），或将 config.json 中的 embedder 设为 local")
+		}
+		current, source, embedder = len(vec), cfg.EmbeddingModel, embedding.ConfiguredName()
+	} else if cfg.Embedder != embedding.EmbedderOllama && cfg.Embedder != embedding.EmbedderLocal {
+		// 外部程序或第三方嵌入器
+		e := embedding.NewEmbedder()
+		if c, ok := e.(io.Closer); ok {
+			defer c.Close()
+		}
+		vec := e.Embed(embedCheckText)
+		if x, ok := e.(*embedding.ExecEmbedder); ok && x.Err() != nil {
+			return fail(name, fmt.Sprintf("嵌入程序无法生成向量: %v", x.Err()),
+				"检查 embedder_command，或将 config.json 中的 embedder 设为 local")
+		}
+		current, source, embedder = len(vec), "嵌入器 "+cfg.Embedder, embedding.NameOf(e, vec)
+	} else {
+		current = len(embedding.NewLocalEmbedder().Embed(embedCheckText))
+	}
+
+	space := storage.VectorSpace{Embedder: embedder, Dim: current}
+	total, mismatched := 0, 0
+	for s, count := range spaces {
+		total += count
+		if !space.Accepts(s) {
+			mismatched += count
+		}
+	}
+	switch {
+	case total == 0:
+		return ok(name, "%s（%d 维），尚无学习记录", source, current)
+	case mismatched == 0:
+		return ok(name, "%s（%d 维）与 %d 条学习记录一致", source, current, total)
+	default:
+		return warn(name,
+			fmt.Sprintf("%d/%d 条学习记录的向量不是 %s（%d 维）生成的，这些记忆无法参与相似度匹配",
+				mismatched, total, source, current),
+			"运行 filo maintain --reembed 按当前嵌入器重新生成向量，或切回原来的嵌入模型")
+	}
+}
+
+// ==================== 数据库检查 ====================
+
+// CheckDatabase 检查数据库完整性和 WAL 文件大小
+//
+// 参数:
+//   - db: 数据库连接，为 nil 表示无法打开数据库
+//   - openErr: 打开数据库时的错误
+func CheckDatabase(db *storage.Database, openErr error) []Check {
+	cfg := config.Get()
+	if errors.Is(openErr, storage.ErrSharedDB) {
+		return []Check{fail("共享数据库", openErr.Error(),
+			"确认 rqlite 节点已启动、地址和账号正确；暂时无法连接时清空 shared_db 只用本机数据库")}
+	}
+	if db == nil {
+		return []Check{fail("数据库", fmt.Sprintf("无法打开 %s: %v", cfg.DBPath, openErr),
+			"确认没有其他程序占用该文件；文件损坏时先备份，再运行 filo reset --all 重建")}
+	}
+
+	var checks []Check
+	problems, err := db.IntegrityCheck()
+	switch {
+	case err != nil:
+		checks = append(checks, fail("数据库完整性", fmt.Sprintf("无法执行检查: %v", err),
+			"备份 "+cfg.DBPath+" 后运行 filo reset --all 重建"))
+	case len(problems) > 0:
+		detail := problems[0]
+		if len(problems) > 1 {
+			detail = fmt.Sprintf("%s 等 %d 个问题", detail, len(problems))
+		}
+		checks = append(checks, fail("数据库完整性", detail,
+			"备份 "+cfg.DBPath+" 后用 sqlite3 的 .recover 恢复，或运行 filo reset --all 重建"))
+	default:
+		checks = append(checks, ok("数据库完整性", "%s 完好", cfg.DBPath))
+	}
+
+	checks = append(checks, walCheck(cfg.DBPath))
+	if db.Shared() {
+		if err := db.PingShared(); err != nil {
+			checks = append(checks, fail("共享数据库", fmt.Sprintf("%s: %v", cfg.SharedDB, err),
+				"确认 rqlite 节点已启动、地址和账号正确"))
+		} else {
+			checks = append(checks, ok("共享数据库", "%s（用户 %s）", cfg.SharedDB, db.User()))
+		}
+	}
+	return checks
+}
+
+// walCheck 检查 WAL 文件大小
+// 长期运行的进程可能让 WAL 文件持续增长，拖慢读写
+func walCheck(dbPath string) Check {
+	info, err := os.Stat(dbPath + "-wal")
+	if err != nil {
+		return ok("WAL 文件", "无")
+	}
+	if info.Size() > WALWarnSize {
+		return warn("WAL 文件", ui.FormatSize(info.Size()), "运行 filo doctor --fix 将 WAL 写回数据库")
+	}
+	return ok("WAL 文件", ui.FormatSize(info.Size()))
+}
+
+// ==================== 磁盘检查 ====================
+
+// CheckDisk 检查目录所在磁盘的剩余空间
+// 目录不存在时检查最近的已存在上级目录（整理时会自动创建目标目录）
+//
+// 参数:
+//   - name: 检查项名称
+//   - dir: 要检查的目录
+func CheckDisk(name, dir string) Check {
+	existing := dir
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return warn(name, "目录不存在: "+dir, "")
+		}
+		existing = parent
+	}
+
+	free, err := organizer.DiskFree(existing)
+	if err != nil {
+		return ok(name, "%s（无法获取剩余空间: %v）", dir, err)
+	}
+	detail := fmt.Sprintf("%s 剩余 %s", dir, ui.FormatSize(int64(free)))
+	switch {
+	case free < DiskFailFree:
+		return fail(name, detail, "清理磁盘空间，或用 --target 指定其他磁盘上的目标目录")
+	case free < DiskWarnFree:
+		return warn(name, detail, "整理大量文件前清理磁盘空间（跨磁盘移动需要复制文件）")
+	default:
+		return ok(name, "%s", detail)
+	}
+}
@@ -1,6 +1,6 @@
 // Package embedding 向量嵌入模块
 // 提供文本向量化功能，用于相似文件匹配
-// 支持本地哈希嵌入和 Ollama 模型嵌入两种方式
+// 支持本地哈希嵌入、Ollama 模型嵌入和第三方嵌入器（配置 embedder 选择，见 plugin.go）
 //
 // Copyright (c) 2024-2026 lynx-lee
 // https://github.com/lynx-lee/filo
@@ -15,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"filo/internal/config"
 	"filo/internal/llm"
 )
 
@@ -24,9 +25,19 @@ import (
 // 定义文本向量化和相似度计算的标准方法
 type Embedder interface {
 	Embed(text string) []float64              // 将文本转换为向量
+	EmbedBatch(texts []string) [][]float64    // 批量转换，结果与 texts 一一对应
 	Similarity(v1, v2 []float64) float64      // 计算两个向量的相似度
 }
 
+// 嵌入器类型
+const (
+	EmbedderOllama = "ollama" // Ollama 嵌入模型（默认）
+	EmbedderLocal  = "local"  // 本地哈希嵌入
+)
+
+// ollamaBatchSize 每次请求 Ollama 向量化的文本数
+const ollamaBatchSize = 64
+
 // ==================== 本地嵌入器 ====================
 
 // LocalEmbedder 本地嵌入器
@@ -82,6 +93,15 @@ func (e *LocalEmbedder) Embed(text string) []float64 {
 	return normalize(vec) // 归一化向量
 }
 
+// EmbedBatch 批量生成嵌入向量
+func (e *LocalEmbedder) EmbedBatch(texts []string) [][]float64 {
+	vecs := make([][]float64, len(texts))
+	for i, text := range texts {
+		vecs[i] = e.Embed(text)
+	}
+	return vecs
+}
+
 // Similarity 计算两个向量的余弦相似度
 // 返回值范围: 0（完全不同）到 1（完全相同）
 func (e *LocalEmbedder) Similarity(v1, v2 []float64) float64 {
@@ -126,10 +146,12 @@ func normalize(vec []float64) []float64 {
 // ==================== Ollama 嵌入器 ====================
 
 // OllamaEmbedder 使用 Ollama 模型的嵌入器
-// 生成更高质量的语义向量，但速度较慢
+// 生成更高质量的语义向量；通过 /api/embed 批量请求，一次向量化几十个文件名
 type OllamaEmbedder struct {
 	client   *llm.Client     // Ollama 客户端
 	fallback *LocalEmbedder  // 失败时的后备方案
+	failed   bool            // 调用失败过，本次运行之后都使用本地嵌入
+	name     string          // 嵌入器名称（含嵌入模型），随向量保存
 }
 
 // NewOllamaEmbedder 创建 Ollama 嵌入器
@@ -137,21 +159,42 @@ func NewOllamaEmbedder() *OllamaEmbedder {
 	return &OllamaEmbedder{
 		client:   llm.NewClient(),
 		fallback: NewLocalEmbedder(),
+		name:     EmbedderOllama + ":" + config.Get().EmbeddingModel,
 	}
 }
 
 // Embed 使用 Ollama 模型生成嵌入向量
 // 如果调用失败，自动回退到本地嵌入器
 func (e *OllamaEmbedder) Embed(text string) []float64 {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	return e.EmbedBatch([]string{text})[0]
+}
+
+// EmbedBatch 使用 Ollama 模型批量生成嵌入向量
+// 每 64 个文本一次请求；某次请求失败后，剩余文本和之后的调用都回退到本地嵌入器，
+// 避免 Ollama 不可用或未安装嵌入模型时每个文件都等待超时
+func (e *OllamaEmbedder) EmbedBatch(texts []string) [][]float64 {
+	vecs := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += ollamaBatchSize {
+		end := start + ollamaBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+		if e.failed {
+			vecs = append(vecs, e.fallback.EmbedBatch(texts[start:end])...)
+			continue
+		}
 
-	vec, err := e.client.Embed(ctx, text)
-	if err != nil {
-		// Ollama 不可用时回退到本地嵌入
-		return e.fallback.Embed(text)
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		batch, err := e.client.EmbedBatch(ctx, texts[start:end])
+		cancel()
+		if err != nil {
+			// Ollama 不可用时回退到本地嵌入
+			e.failed = true
+			batch = e.fallback.EmbedBatch(texts[start:end])
+		}
+		vecs = append(vecs, batch...)
 	}
-	return vec
+	return vecs
 }
 
 // Similarity 计算相似度
@@ -160,10 +203,73 @@ func (e *OllamaEmbedder) Similarity(v1, v2 []float64) float64 {
 	return e.fallback.Similarity(v1, v2)
 }
 
+// ==================== 向量来源 ====================
+
+// Named 第三方嵌入器可以实现的接口，返回写入数据库的嵌入器名称
+// 未实现时使用配置中的 embedder 名称
+type Named interface {
+	Name() string
+}
+
+// ConfiguredName 按当前配置应当生成向量的嵌入器名称
+// 名称中包含模型或程序，更换嵌入模型后旧向量与新向量可以区分
+func ConfiguredName() string {
+	cfg := config.Get()
+	switch {
+	case cfg.Embedder == EmbedderLocal || cfg.Offline || cfg.RulesOnly:
+		return EmbedderLocal
+	case cfg.Embedder == EmbedderOllama:
+		return EmbedderOllama + ":" + cfg.EmbeddingModel
+	case cfg.Embedder == EmbedderExec:
+		return EmbedderExec + ":" + strings.Join(cfg.EmbedderCommand, " ")
+	}
+	return cfg.Embedder
+}
+
+// NameOf 返回生成向量 vec 的嵌入器名称，与向量一起保存，检索时只比较同一嵌入器的向量
+// Ollama 和外部程序嵌入器失败后回退到本地嵌入，此后生成的本地维度的向量记为 local
+//
+// 参数:
+//   - e: 生成向量的嵌入器
+//   - vec: 嵌入器生成的向量
+//
+// 返回值:
+//   - string: 嵌入器名称，如 local、ollama:nomic-embed-text
+func NameOf(e Embedder, vec []float64) string {
+	switch x := e.(type) {
+	case *LocalEmbedder:
+		return EmbedderLocal
+	case *OllamaEmbedder:
+		if x.failed && len(vec) == x.fallback.dimension {
+			return EmbedderLocal
+		}
+		return x.name
+	case *ExecEmbedder:
+		if x.Err() != nil && len(vec) == x.fallback.dimension {
+			return EmbedderLocal
+		}
+		return EmbedderExec + ":" + strings.Join(x.command, " ")
+	case Named:
+		return x.Name()
+	}
+	return config.Get().Embedder
+}
+
 // ==================== 工厂函数 ====================
 
-// NewEmbedder 创建嵌入器（自动选择）
-// 默认使用本地嵌入（更快更稳定）
+// NewEmbedder 创建嵌入器（按配置选择）
+// 默认使用 Ollama 嵌入模型；配置为 local 或处于离线、仅规则模式时使用本地嵌入；
+// exec 启动 embedder_command 指定的外部程序，其他名称使用通过 Register 注册的嵌入器
 func NewEmbedder() Embedder {
-	return NewLocalEmbedder()
+	cfg := config.Get()
+	if cfg.Embedder == EmbedderLocal || cfg.Offline || cfg.RulesOnly {
+		return NewLocalEmbedder()
+	}
+	if cfg.Embedder == EmbedderExec {
+		return NewExecEmbedder(cfg.EmbedderCommand)
+	}
+	if e, ok := newRegistered(cfg.Embedder); ok {
+		return e
+	}
+	return NewOllamaEmbedder()
 }
@@ -0,0 +1,327 @@
+// Package embedding 向量嵌入模块
+// plugin.go - 第三方嵌入器：编译时通过 Register 注册（可放在带构建标签的文件中），
+// 或在运行时启动外部程序（embedder 为 exec），通过标准输入输出交换 JSON，
+// 接入本地的 sentence-transformers 等嵌入服务而不必修改本模块
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package embedding
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"filo/internal/ui"
+)
+
+// ==================== 注册 ====================
+
+// EmbedderExec 外部程序嵌入器
+const EmbedderExec = "exec"
+
+// Factory 创建嵌入器，返回错误时回退到本地嵌入器
+type Factory func() (Embedder, error)
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]Factory) // 嵌入器名称 -> 工厂函数
+)
+
+// Register 注册第三方嵌入器，配置 embedder 为 name 时使用
+// 通常在 init 中调用；名称为空、与内置嵌入器同名或重复注册时 panic
+//
+// 参数:
+//   - name: 嵌入器名称
+//   - factory: 创建嵌入器的函数
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	switch {
+	case name == "" || factory == nil:
+		panic("embedding: 注册的嵌入器名称和工厂函数不能为空")
+	case name == EmbedderOllama || name == EmbedderLocal || name == EmbedderExec:
+		panic("embedding: 不能注册与内置嵌入器同名的嵌入器 " + name)
+	case registry[name] != nil:
+		panic("embedding: 重复注册嵌入器 " + name)
+	}
+	registry[name] = factory
+}
+
+// Registered 返回已注册的第三方嵌入器名称（按名称排序）
+func Registered() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Names 返回所有可用的嵌入器名称：内置嵌入器在前，之后为已注册的第三方嵌入器
+func Names() []string {
+	return append([]string{EmbedderOllama, EmbedderLocal, EmbedderExec}, Registered()...)
+}
+
+// newRegistered 创建已注册的嵌入器，未注册时返回 false
+func newRegistered(name string) (Embedder, bool) {
+	registryMu.Lock()
+	factory := registry[name]
+	registryMu.Unlock()
+	if factory == nil {
+		return nil, false
+	}
+	e, err := factory()
+	if err != nil {
+		ui.Warning("嵌入器 %s 初始化失败，使用本地嵌入: %v", name, err)
+		return NewLocalEmbedder(), true
+	}
+	return e, true
+}
+
+// ==================== 外部程序嵌入器 ====================
+
+// execTimeout 外部程序向量化一批文本的最长等待时间
+const execTimeout = 60 * time.Second
+
+// stderrTail 保留的外部程序错误输出字节数（用于报告失败原因）
+const stderrTail = 512
+
+// execRequest 发给外部程序的请求（一行 JSON）
+type execRequest struct {
+	Texts []string `json:"texts"`
+}
+
+// execResponse 外部程序的响应（一行 JSON），向量与 texts 一一对应
+type execResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// ExecEmbedder 外部程序嵌入器
+// 第一次向量化时启动 embedder_command，之后一直复用同一个进程：
+// 每次向标准输入写一行 {"texts": [...]}，从标准输出读一行 {"embeddings": [[...], ...]}。
+// 程序无法启动、超时或返回错误后，剩余文本和之后的调用都回退到本地嵌入器
+type ExecEmbedder struct {
+	command  []string       // 程序及参数
+	fallback *LocalEmbedder // 失败时的后备方案
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	stderr *tailBuffer
+	err    error // 失败原因，失败后不再调用外部程序
+}
+
+// NewExecEmbedder 创建外部程序嵌入器（此时不启动程序）
+//
+// 参数:
+//   - command: 程序及参数，如 ["python3", "/Users/me/embed.py"]
+func NewExecEmbedder(command []string) *ExecEmbedder {
+	return &ExecEmbedder{command: command, fallback: NewLocalEmbedder()}
+}
+
+// Embed 使用外部程序生成嵌入向量
+func (e *ExecEmbedder) Embed(text string) []float64 {
+	return e.EmbedBatch([]string{text})[0]
+}
+
+// EmbedBatch 使用外部程序批量生成嵌入向量，每 64 个文本一次请求
+func (e *ExecEmbedder) EmbedBatch(texts []string) [][]float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	vecs := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += ollamaBatchSize {
+		end := start + ollamaBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+		if e.err == nil {
+			batch, err := e.request(texts[start:end])
+			if err == nil {
+				vecs = append(vecs, batch...)
+				continue
+			}
+			e.fail(err)
+		}
+		vecs = append(vecs, e.fallback.EmbedBatch(texts[start:end])...)
+	}
+	return vecs
+}
+
+// Similarity 计算余弦相似度
+func (e *ExecEmbedder) Similarity(v1, v2 []float64) float64 {
+	return e.fallback.Similarity(v1, v2)
+}
+
+// Err 返回外部程序的失败原因，尚未失败时为 nil
+func (e *ExecEmbedder) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.err
+}
+
+// Close 关闭外部程序的标准输入并等待其退出，超时后结束进程
+func (e *ExecEmbedder) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stop()
+	return nil
+}
+
+// start 启动外部程序
+func (e *ExecEmbedder) start() error {
+	if len(e.command) == 0 {
+		return errors.New("未配置 embedder_command")
+	}
+	cmd := exec.Command(e.command[0], e.command[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	e.stderr = &tailBuffer{}
+	cmd.Stderr = e.stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	e.cmd, e.stdin, e.stdout = cmd, stdin, bufio.NewReader(stdout)
+	return nil
+}
+
+// request 发送一次请求并读取响应
+func (e *ExecEmbedder) request(texts []string) ([][]float64, error) {
+	if e.cmd == nil {
+		if err := e.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	line, _ := json.Marshal(execRequest{Texts: texts})
+	if _, err := e.stdin.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		line []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		l, err := e.stdout.ReadBytes('\n')
+		done <- result{l, err}
+	}()
+
+	var r result
+	select {
+	case r = <-done:
+	case <-time.After(execTimeout):
+		e.cmd.Process.Kill()
+		return nil, fmt.Errorf("%v 内没有响应", execTimeout)
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", r.err)
+	}
+
+	var resp execResponse
+	if err := json.Unmarshal(r.line, &resp); err != nil {
+		return nil, fmt.Errorf("响应不是 JSON: %s", truncate(strings.TrimSpace(string(r.line)), 80))
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("返回了 %d 个向量，应为 %d 个", len(resp.Embeddings), len(texts))
+	}
+	for _, v := range resp.Embeddings {
+		if len(v) == 0 {
+			return nil, errors.New("返回了空向量")
+		}
+	}
+	return resp.Embeddings, nil
+}
+
+// fail 记录失败原因（附上程序的错误输出），结束进程并提示一次
+func (e *ExecEmbedder) fail(err error) {
+	if e.stderr != nil {
+		if tail := e.stderr.String(); tail != "" {
+			err = fmt.Errorf("%v（%s）", err, tail)
+		}
+	}
+	e.err = err
+	e.stop()
+	name := "embedder_command"
+	if len(e.command) > 0 {
+		name = e.command[0]
+	}
+	ui.Warning("嵌入程序 %s 不可用，使用本地嵌入: %v", name, err)
+}
+
+// stop 结束外部程序
+func (e *ExecEmbedder) stop() {
+	if e.cmd == nil {
+		return
+	}
+	e.stdin.Close() // 程序读到 EOF 后应自行退出
+	exited := make(chan struct{})
+	go func() {
+		e.cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(2 * time.Second):
+		e.cmd.Process.Kill()
+		<-exited
+	}
+	e.cmd = nil
+}
+
+// tailBuffer 只保留最后 stderrTail 字节的错误输出
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Write 实现 io.Writer
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > stderrTail {
+		b.buf = b.buf[len(b.buf)-stderrTail:]
+	}
+	return len(p), nil
+}
+
+// String 返回错误输出的最后一行
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lines := strings.Split(strings.TrimSpace(string(b.buf)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// truncate 按字符截断
+func truncate(s string, maxRunes int) string {
+	runes := []rune(s)
+	if len(runes) <= maxRunes {
+		return s
+	}
+	return string(runes[:maxRunes]) + "…"
+}
@@ -0,0 +1,220 @@
+// Package folderinfo 分类文件夹说明模块
+// 在整理生成的分类文件夹中写入说明文件（README.md 或 .folderinfo），内容来自分类体系的说明，
+// macOS 上还可以按分类设置 Finder 标签颜色和自定义图标，方便家人或同事看懂整理后的目录
+//
+// 说明文件第一行为固定标记，扫描时跳过，撤销时随空文件夹一起删除；
+// 文件夹中已有同名文件时不覆盖
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package folderinfo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"filo/internal/guard"
+	"filo/internal/taxonomy"
+)
+
+// ==================== 常量定义 ====================
+
+// 说明文件格式（folder_info 配置）
+const (
+	FormatNone       = ""           // 不生成说明文件（默认）
+	FormatReadme     = "readme"     // README.md，在文件管理器中可见
+	FormatFolderInfo = "folderinfo" // .folderinfo，隐藏文件
+)
+
+// 说明文件名
+const (
+	ReadmeName     = "README.md"
+	FolderInfoName = ".folderinfo"
+)
+
+// Marker 说明文件的第一行，用于识别由 filo 生成的文件
+const Marker = "<!-- filo:folderinfo -->"
+
+// iconFile macOS 自定义文件夹图标所在的隐藏文件
+const iconFile = "Icon\r"
+
+// labelIndex Finder 标签颜色的编号
+var labelIndex = map[string]int{
+	"orange": 1, "red": 2, "yellow": 3, "blue": 4, "purple": 5, "green": 6, "gray": 7,
+}
+
+// Colors 可选的文件夹颜色
+var Colors = []string{"red", "orange", "yellow", "green", "blue", "purple", "gray"}
+
+// ==================== 说明文件 ====================
+
+// FileName 返回格式对应的说明文件名，格式无效时返回空
+func FileName(format string) string {
+	switch format {
+	case FormatReadme:
+		return ReadmeName
+	case FormatFolderInfo:
+		return FolderInfoName
+	}
+	return ""
+}
+
+// Write 在分类文件夹中写入说明文件
+// 已有同名文件（无论是否由 filo 生成）时不覆盖
+//
+// 参数:
+//   - dir: 分类文件夹路径
+//   - path: 文件夹对应的分类路径，如 ["文档", "合同"]
+//   - format: 说明文件格式
+//
+// 返回值:
+//   - bool: 是否写入了新文件
+//   - error: 写入失败时返回错误
+func Write(dir string, path []string, format string) (bool, error) {
+	name := FileName(format)
+	if name == "" || len(path) == 0 {
+		return false, nil
+	}
+	file := filepath.Join(dir, name)
+	if _, err := os.Lstat(file); err == nil {
+		return false, nil
+	}
+
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content(path, taxonomy.Get())); err != nil {
+		return false, err
+	}
+	return true, f.Close()
+}
+
+// content 生成说明文件内容
+// 主分类文件夹写分类说明和常用子分类，下级文件夹写所属的上级分类
+func content(path []string, tax *taxonomy.Taxonomy) string {
+	var sb strings.Builder
+	sb.WriteString(Marker + "\n")
+	sb.WriteString("# " + path[len(path)-1] + "\n\n")
+
+	cat := tax.Find(path[0])
+	if len(path) == 1 {
+		if cat != nil && cat.Description != "" {
+			sb.WriteString(cat.Description + "\n\n")
+		}
+		if cat != nil && len(cat.Subcategories) > 0 {
+			sb.WriteString("常用子分类：" + strings.Join(cat.Subcategories, "、") + "\n\n")
+		}
+	} else {
+		parent := "「" + strings.Join(path[:len(path)-1], " / ") + "」"
+		if cat != nil && cat.Description != "" {
+			parent += fmt.Sprintf("（%s）", cat.Description)
+		}
+		sb.WriteString("属于" + parent + "\n\n")
+	}
+
+	sb.WriteString("此文件夹由 filo 整理生成，放入的文件按分类 " + strings.Join(path, " / ") + " 归档。\n")
+	return sb.String()
+}
+
+// IsGenerated 判断文件是否为 filo 生成的说明文件
+func IsGenerated(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	return strings.TrimSpace(line) == Marker
+}
+
+// Clean 文件夹中只剩说明文件和自定义图标时删除它们，让文件夹可以作为空目录清理
+func Clean(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) == 0 {
+		return
+	}
+	var generated []string
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		switch {
+		case e.Name() == iconFile:
+		case (e.Name() == ReadmeName || e.Name() == FolderInfoName) && IsGenerated(path):
+		default:
+			return // 还有其他文件，保留
+		}
+		generated = append(generated, path)
+	}
+	for _, path := range generated {
+		os.Remove(path)
+	}
+}
+
+// ==================== 文件夹外观（macOS）====================
+
+// ValidColor 判断是否为支持的文件夹颜色，空表示不设置
+func ValidColor(color string) bool {
+	_, ok := labelIndex[color]
+	return color == "" || ok
+}
+
+// SetAppearance 按分类设置文件夹的 Finder 标签颜色和自定义图标
+// 只在 macOS 上生效，其他系统直接返回
+//
+// 参数:
+//   - dir: 文件夹路径
+//   - cat: 分类定义（使用其中的 Color 和 Icon）
+//
+// 返回值:
+//   - error: 颜色无效、图标不存在或 osascript 执行失败时返回错误
+func SetAppearance(dir string, cat *taxonomy.Category) error {
+	if runtime.GOOS != "darwin" || cat == nil || (cat.Color == "" && cat.Icon == "") {
+		return nil
+	}
+	if !ValidColor(cat.Color) {
+		return fmt.Errorf("无效的文件夹颜色: %s（可选 %s）", cat.Color, strings.Join(Colors, "/"))
+	}
+
+	var script []string
+	if cat.Icon != "" {
+		icon := guard.ExpandHome(cat.Icon)
+		if _, err := os.Stat(icon); err != nil {
+			return fmt.Errorf("找不到文件夹图标: %s", cat.Icon)
+		}
+		script = append(script,
+			`use framework "AppKit"`,
+			fmt.Sprintf(`set img to current application's NSImage's alloc()'s initWithContentsOfFile:%s`, appleScriptString(icon)),
+			fmt.Sprintf(`current application's NSWorkspace's sharedWorkspace()'s setIcon:img forFile:%s options:0`, appleScriptString(dir)),
+		)
+	}
+	if cat.Color != "" {
+		script = append(script, fmt.Sprintf(`tell application "Finder" to set label index of (POSIX file %s as alias) to %d`,
+			appleScriptString(dir), labelIndex[cat.Color]))
+	}
+
+	args := make([]string, 0, len(script)*2)
+	for _, line := range script {
+		args = append(args, "-e", line)
+	}
+	if out, err := exec.Command("osascript", args...).CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// appleScriptString 转义为 AppleScript 字符串字面量
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,160 @@
+// Package guard 路径安全检查模块
+// 拒绝整理系统目录等危险路径，并提示云同步目录的风险
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package guard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"filo/internal/config"
+)
+
+// ==================== 内置规则 ====================
+
+// systemDirs 系统目录：目录本身及其所有子目录都受保护
+var systemDirs = map[string][]string{
+	"unix": {
+		"/bin", "/sbin", "/usr", "/etc", "/var", "/opt", "/lib", "/lib64",
+		"/boot", "/dev", "/proc", "/sys", "/run", "/snap",
+		"/System", "/Library", "/Applications", "/private/etc", "/private/var",
+	},
+	"windows": {
+		`C:\Windows`, `C:\Program Files`, `C:\Program Files (x86)`, `C:\ProgramData`,
+	},
+}
+
+// cloudSyncDirs 云同步目录名
+// 同步客户端会在这些目录中生成占位文件和临时文件，移动文件可能触发大量同步或冲突
+var cloudSyncDirs = []string{
+	"Dropbox", "OneDrive", "Google Drive", "iCloud Drive", "Mobile Documents",
+	"Box", "pCloud Drive", "坚果云", "Nutstore", "百度网盘", "BaiduNetdisk",
+}
+
+// ==================== 检查函数 ====================
+
+// Check 检查目录是否允许整理
+// 以下情况返回错误：
+//   - 文件系统根目录、用户主目录本身、filo 数据目录
+//   - 系统目录及其子目录
+//   - 配置的 protected_paths 及其子目录
+//   - 配置了 allowed_roots 但目录不在其中
+func Check(dir string) error {
+	path := resolve(dir)
+	cfg := config.Get()
+
+	// 只保护目录本身，子目录可以正常整理
+	if isRoot(path) {
+		return fmt.Errorf("%s 是文件系统根目录", path)
+	}
+	if home, err := os.UserHomeDir(); err == nil && samePath(path, resolve(home)) {
+		return fmt.Errorf("%s 是用户主目录", path)
+	}
+
+	// 目录及其子目录都受保护
+	if within(path, resolve(cfg.DataDir)) {
+		return fmt.Errorf("%s 是 filo 数据目录", path)
+	}
+	for _, p := range protectedDirs() {
+		if within(path, p) {
+			return fmt.Errorf("%s 位于受保护目录 %s 中", path, p)
+		}
+	}
+
+	// 配置了允许列表时，只能整理列表中的目录
+	if len(cfg.AllowedRoots) > 0 {
+		for _, root := range cfg.AllowedRoots {
+			if within(path, resolve(ExpandHome(root))) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s 不在允许整理的目录 (allowed_roots) 中", path)
+	}
+	return nil
+}
+
+// CloudSyncWarning 检查目录是否位于云同步目录中
+// 返回提示信息，不在云同步目录中时返回空字符串
+func CloudSyncWarning(dir string) string {
+	path := resolve(dir)
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		for _, name := range cloudSyncDirs {
+			// OneDrive 等目录名可能带后缀，如 "OneDrive - 公司名"
+			if part == name || strings.HasPrefix(part, name+" - ") {
+				return fmt.Sprintf("%s 位于云同步目录 %s 中，未同步完成的占位文件可能被误移动", path, part)
+			}
+		}
+	}
+	return ""
+}
+
+// ==================== 辅助函数 ====================
+
+// protectedDirs 受保护的目录列表（内置系统目录 + 配置的 protected_paths）
+func protectedDirs() []string {
+	var dirs []string
+	if runtime.GOOS == "windows" {
+		dirs = append(dirs, systemDirs["windows"]...)
+	} else {
+		dirs = append(dirs, systemDirs["unix"]...)
+	}
+	for _, p := range config.Get().ProtectedPaths {
+		dirs = append(dirs, resolve(ExpandHome(p)))
+	}
+	return dirs
+}
+
+// resolve 转换为绝对路径并解析符号链接（失败时保留原路径）
+func resolve(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	return filepath.Clean(path)
+}
+
+// ExpandHome 展开路径开头的 ~
+func ExpandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// isRoot 是否为文件系统根目录（/ 或 Windows 盘符根目录）
+func isRoot(path string) bool {
+	return filepath.Dir(path) == path
+}
+
+// samePath 比较路径是否相同（Windows 不区分大小写）
+func samePath(a, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// within 判断 path 是否为 root 或其子目录
+func within(path, root string) bool {
+	if samePath(path, root) {
+		return true
+	}
+	prefix := root
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	if runtime.GOOS == "windows" {
+		return strings.HasPrefix(strings.ToLower(path), strings.ToLower(prefix))
+	}
+	return strings.HasPrefix(path, prefix)
+}
@@ -0,0 +1,192 @@
+// Package llm Ollama LLM 客户端模块
+// capabilities.go - 模型能力探测：通过 /api/show 读取模型的上下文长度和能力，
+// 估算一批文件的提示词能否放进上下文窗口，提示 batch_size、max_tokens 设置过大
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"filo/internal/config"
+)
+
+// 模型能力（/api/show 返回的 capabilities）
+const (
+	CapCompletion = "completion" // 可以对话和生成文本
+	CapVision     = "vision"     // 可以看图
+	CapEmbedding  = "embedding"  // 只能生成向量
+)
+
+// 提示词 token 数的估算参数
+const (
+	promptRuleTokens    = 12  // 提示词中每条学习规则的 token 数
+	promptRules         = 20  // 提示词中最多附带的学习规则数（见 buildSystemPrompt）
+	promptFileTokens    = 40  // 每个文件的描述（文件名、扩展名、大小）的 token 数
+	promptContentTokens = 200 // 附带文本内容片段时每个文件增加的 token 数
+)
+
+// errFormatNotSupported 模型不支持输出格式约束（format: json 也被拒绝）
+var errFormatNotSupported = errors.New("模型不支持输出格式约束")
+
+// ModelInfo 模型的上下文长度和能力
+type ModelInfo struct {
+	Model         string   // 模型名称
+	ContextLength int      // 模型支持的最大上下文长度（tokens），未知时为 0
+	NumCtx        int      // Modelfile 中设置的 num_ctx（实际使用的上下文窗口），未设置时为 0
+	Capabilities  []string // 模型能力，旧版本 Ollama 不返回时为空
+}
+
+// Window 分类时可用的上下文窗口（tokens），Modelfile 设置了 num_ctx 时以其为准，未知时为 0
+func (m *ModelInfo) Window() int {
+	if m.NumCtx > 0 {
+		return m.NumCtx
+	}
+	return m.ContextLength
+}
+
+// Has 模型是否具有某项能力；Ollama 没有返回能力列表时视为具有
+func (m *ModelInfo) Has(capability string) bool {
+	if len(m.Capabilities) == 0 {
+		return true
+	}
+	for _, c := range m.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// ShowModel 读取模型的上下文长度和能力（调用 /api/show）
+// 远程提供方不支持，返回错误
+//
+// 参数:
+//   - model: 模型名称
+//
+// 返回值:
+//   - *ModelInfo: 模型信息
+//   - error: 如果请求失败或模型不存在，返回错误
+func (c *Client) ShowModel(model string) (*ModelInfo, error) {
+	if c.IsRemote() {
+		return nil, fmt.Errorf("%s 不提供模型信息", c.Provider())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	body, _ := json.Marshal(map[string]string{"model": model})
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/show", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API错误 %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var show struct {
+		Parameters   string                 `json:"parameters"`   // Modelfile 中的参数，每行一个，如 num_ctx 8192
+		ModelInfo    map[string]interface{} `json:"model_info"`   // 模型元数据，上下文长度为 <架构>.context_length
+		Capabilities []string               `json:"capabilities"` // 模型能力（Ollama 0.6 起）
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&show); err != nil {
+		return nil, err
+	}
+
+	info := &ModelInfo{Model: model, Capabilities: show.Capabilities}
+	arch, _ := show.ModelInfo["general.architecture"].(string)
+	for key, v := range show.ModelInfo {
+		n, ok := v.(float64)
+		if !ok || !strings.HasSuffix(key, ".context_length") {
+			continue
+		}
+		if key == arch+".context_length" || info.ContextLength == 0 {
+			info.ContextLength = int(n)
+		}
+	}
+	for _, line := range strings.Split(show.Parameters, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "num_ctx" {
+			info.NumCtx, _ = strconv.Atoi(fields[1])
+		}
+	}
+	return info, nil
+}
+
+// ContextFit 一批文件的提示词与上下文窗口的比较
+type ContextFit struct {
+	Window       int // 上下文窗口（tokens），未知时为 0
+	BatchSize    int // 估算使用的批大小
+	PromptTokens int // 一批文件的提示词估计 token 数
+	OutputTokens int // 为输出预留的 token 数（max_tokens）
+	MaxBatch     int // 放得下的最大批大小，为 0 时一个文件也放不下
+}
+
+// Fits 提示词和输出能否放进上下文窗口，窗口未知时视为可以
+func (f ContextFit) Fits() bool {
+	return f.Window == 0 || f.PromptTokens+f.OutputTokens <= f.Window
+}
+
+// EstimateFit 估算一批文件的提示词能否放进模型的上下文窗口
+// 提示词按分类体系（或已有文件夹）、最多 20 条学习规则和每个文件的描述估算，
+// 中日韩文字按每字一个 token、其他字符按每 4 个一个 token 计
+//
+// 参数:
+//   - info: ShowModel 返回的模型信息
+//   - batchSize: 每批的文件数
+//
+// 返回值:
+//   - ContextFit: 比较结果
+func (c *Client) EstimateFit(info *ModelInfo, batchSize int) ContextFit {
+	cfg := config.Get()
+	perFile := promptFileTokens
+	if cfg.ContentAllowed() {
+		perFile += promptContentTokens
+	}
+	base := estimateTokens(buildSystemPrompt(nil, c.categorySection())) +
+		estimateTokens(buildUserPrompt(nil)) + promptRules*promptRuleTokens
+
+	fit := ContextFit{
+		Window:       info.Window(),
+		BatchSize:    batchSize,
+		PromptTokens: base + perFile*batchSize,
+		OutputTokens: cfg.MaxTokens,
+	}
+	if fit.Window > 0 {
+		if room := fit.Window - base - fit.OutputTokens; room > 0 {
+			fit.MaxBatch = room / perFile
+		}
+	}
+	return fit
+}
+
+// estimateTokens 粗略估计文本的 token 数：中日韩文字每字一个 token，其他字符每 4 个一个 token
+func estimateTokens(s string) int {
+	cjk, other := 0, 0
+	for _, r := range s {
+		if r >= 0x2E80 {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
@@ -0,0 +1,104 @@
+// Package llm Ollama LLM 客户端模块
+// debug.go - 调用记录（--debug-llm）：把一次整理中每次模型调用的提示词和原始响应保存到
+// ~/.filo/debug/<批次ID>/，报告分类错误时附上这些文件即可复现模型看到的内容
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"filo/internal/config"
+)
+
+// DebugFolder 调用记录的根目录（位于数据目录 ~/.filo 下）
+const DebugFolder = "debug"
+
+// Transcript 一次整理中所有模型调用的记录，每次调用保存为一个 JSON 文件
+// 可能在多个批次的 goroutine 中同时写入
+type Transcript struct {
+	dir     string     // 记录所在目录（~/.filo/debug/<批次ID>）
+	batchID string     // 批次 ID，与操作日志中的批次 ID 相同
+	mu      sync.Mutex // 保护 seq
+	seq     int        // 已记录的调用数
+}
+
+// transcriptEntry 一次模型调用的记录
+type transcriptEntry struct {
+	BatchID   string        `json:"batch_id"`           // 批次 ID
+	Seq       int           `json:"seq"`                // 本次整理中的第几次调用（含重试）
+	Time      string        `json:"time"`               // 发出请求的时间
+	Provider  string        `json:"provider"`           // LLM 提供方
+	Model     string        `json:"model"`              // 模型名称
+	Endpoint  string        `json:"endpoint"`           // 调用的接口：chat 或 generate（看图）
+	Format    interface{}   `json:"format,omitempty"`   // 输出格式约束（json 或 JSON Schema）
+	Messages  []ChatMessage `json:"messages,omitempty"` // 发送的消息（chat）
+	Prompt    string        `json:"prompt,omitempty"`   // 发送的提示词（generate）
+	Images    int           `json:"images,omitempty"`   // 附带的图片数（图片内容不保存）
+	Response  string        `json:"response"`           // 模型的原始输出
+	Error     string        `json:"error,omitempty"`    // 失败原因
+	LatencyMs int64         `json:"latency_ms"`         // 耗时（毫秒）
+}
+
+// TranscriptDir 批次的调用记录所在目录（~/.filo/debug/<批次ID>）
+func TranscriptDir(batchID string) string {
+	return filepath.Join(config.Get().DataDir, DebugFolder, batchID)
+}
+
+// NewTranscript 创建调用记录，文件保存在 TranscriptDir(batchID) 下
+//
+// 参数:
+//   - batchID: 批次 ID
+//
+// 返回值:
+//   - *Transcript: 调用记录
+//   - error: 如果无法创建目录，返回错误
+func NewTranscript(batchID string) (*Transcript, error) {
+	dir := TranscriptDir(batchID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Transcript{dir: dir, batchID: batchID}, nil
+}
+
+// Dir 调用记录所在目录
+func (t *Transcript) Dir() string {
+	return t.dir
+}
+
+// Count 已记录的调用数
+func (t *Transcript) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.seq
+}
+
+// add 保存一次调用，文件名为 序号_时间.json（如 0003_150405.123.json），写入失败时忽略
+func (t *Transcript) add(e transcriptEntry, start time.Time) {
+	t.mu.Lock()
+	t.seq++
+	e.Seq = t.seq
+	t.mu.Unlock()
+
+	e.BatchID = t.batchID
+	e.Time = start.Format(time.RFC3339Nano)
+	e.LatencyMs = time.Since(start).Milliseconds()
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return
+	}
+	name := fmt.Sprintf("%04d_%s.json", e.Seq, start.Format("150405.000"))
+	os.WriteFile(filepath.Join(t.dir, name), data, 0600)
+}
+
+// SetTranscript 设置调用记录，之后每次模型调用的提示词和原始响应都保存下来，为 nil 时不记录
+func (c *Client) SetTranscript(t *Transcript) {
+	c.transcript = t
+}
@@ -10,15 +10,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
+	"strings"
 	"time"
 
 	"filo/internal/config"
+	"filo/internal/taxonomy"
 )
 
+// maxPromptFolders 提示词中最多列出的已有文件夹数
+const maxPromptFolders = 200
+
 // ==================== 类型定义 ====================
 
 // Client Ollama API 客户端
@@ -26,7 +31,17 @@ import (
 type Client struct {
 	baseURL    string       // Ollama 服务地址
 	model      string       // 当前使用的模型
+	provider   string       // LLM 提供方（ollama / anthropic / gemini）
+	apiKey     string       // 远程提供方 API 密钥
+	policy     RetryPolicy  // 分类调用的超时与重试策略
 	httpClient *http.Client // HTTP 客户端（带超时）
+
+	legacyEmbed  bool             // Ollama 不支持 /api/embed，使用旧的 /api/embeddings
+	legacyFormat bool             // Ollama 不支持用 JSON Schema 约束输出（0.5 之前的版本），使用 format: json
+	noFormat     bool             // 模型不支持输出格式约束，不发送 format，从回复中提取 JSON
+	observer     func(CallRecord) // 调用统计的接收函数（见 SetObserver）
+	folders      []string         // 只允许使用的分类路径（见 SetFolders），为空时使用分类体系
+	transcript   *Transcript      // 调用记录（见 SetTranscript），为 nil 时不记录
 }
 
 // ChatMessage 聊天消息结构
@@ -42,11 +57,29 @@ type ChatMessage struct {
 // 从配置中获取服务地址和模型信息
 func NewClient() *Client {
 	cfg := config.Get()
+
+	// 重试策略（配置值无效时使用默认值）
+	policy := RetryPolicy{
+		Timeout:  time.Duration(cfg.LLMTimeout) * time.Second,
+		Attempts: cfg.LLMRetries + 1,
+		Backoff:  time.Duration(cfg.LLMRetryBackoff) * time.Millisecond,
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = 120 * time.Second
+	}
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+
 	return &Client{
-		baseURL: cfg.OllamaURL,
-		model:   cfg.LLMModel,
+		baseURL:  cfg.OllamaURL,
+		model:    cfg.ActiveModel(),
+		provider: cfg.LLMProvider,
+		apiKey:   cfg.APIKey(),
+		policy:   policy,
 		httpClient: &http.Client{
-			Timeout: 180 * time.Second, // 3分钟超时（模型推理可能较慢）
+			// 兜底超时，单次调用的超时由 context 控制
+			Timeout: policy.Timeout + 60*time.Second,
 		},
 	}
 }
@@ -55,7 +88,12 @@ func NewClient() *Client {
 
 // IsAvailable 检查 Ollama 服务是否可用
 // 通过访问 /api/tags 接口判断服务状态
+// 远程提供方只检查是否配置了 API 密钥
 func (c *Client) IsAvailable() bool {
+	if c.IsRemote() {
+		return c.apiKey != ""
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -70,7 +108,11 @@ func (c *Client) IsAvailable() bool {
 
 // HasModel 检查指定模型是否已安装
 // 在已安装的模型列表中查找
+// 远程提供方的模型由服务端管理，始终视为可用
 func (c *Client) HasModel(model string) bool {
+	if c.IsRemote() {
+		return true
+	}
 	models, err := c.ListModels()
 	if err != nil {
 		return false
@@ -83,6 +125,11 @@ func (c *Client) HasModel(model string) bool {
 	return false
 }
 
+// SetModel 更换分类使用的模型（如预计耗时过长时换用更快的模型）
+func (c *Client) SetModel(model string) {
+	c.model = model
+}
+
 // ListModels 列出所有已安装的模型
 // 调用 /api/tags 接口获取模型列表
 func (c *Client) ListModels() ([]string, error) {
@@ -118,7 +165,59 @@ func (c *Client) ListModels() ([]string, error) {
 
 // Chat 发送聊天请求
 // 支持多轮对话和 JSON 输出模式
+// 配置了远程提供方时转发到对应的远程 API
 func (c *Client) Chat(ctx context.Context, messages []ChatMessage, jsonMode bool) (string, error) {
+	var format interface{}
+	if jsonMode {
+		format = formatJSON
+	}
+	response, _, err := c.chat(ctx, messages, format)
+	return response, err
+}
+
+// chat 发送聊天请求，同时返回 token 用量
+// format 为 nil 时不限制输出，为 formatJSON 时要求输出 JSON，为 JSON Schema 时要求输出符合该结构的 JSON；
+// Ollama 不支持 JSON Schema 时退回 JSON 模式，模型连 JSON 模式也不支持时不限制输出（由提示词要求 JSON）；
+// 远程提供方只使用 JSON 模式
+func (c *Client) chat(ctx context.Context, messages []ChatMessage, format interface{}) (response string, usage Usage, err error) {
+	if t := c.transcript; t != nil {
+		entry := transcriptEntry{Provider: c.Provider(), Model: c.model, Endpoint: "chat", Format: format, Messages: messages}
+		defer func(start time.Time) {
+			entry.Response = response
+			if err != nil {
+				entry.Error = err.Error()
+			}
+			t.add(entry, start)
+		}(time.Now())
+	}
+
+	switch c.provider {
+	case config.ProviderAnthropic:
+		return c.chatAnthropic(ctx, messages)
+	case config.ProviderGemini:
+		return c.chatGemini(ctx, messages, format != nil)
+	}
+
+	if _, schema := format.(map[string]interface{}); schema && c.legacyFormat {
+		format = formatJSON
+	}
+	if c.noFormat {
+		format = nil
+	}
+	response, usage, err = c.chatOllama(ctx, messages, format)
+	if errors.Is(err, errSchemaNotSupported) {
+		c.legacyFormat = true
+		response, usage, err = c.chatOllama(ctx, messages, formatJSON)
+	}
+	if errors.Is(err, errFormatNotSupported) {
+		c.noFormat = true
+		return c.chatOllama(ctx, messages, nil)
+	}
+	return response, usage, err
+}
+
+// chatOllama 调用 Ollama /api/chat 接口
+func (c *Client) chatOllama(ctx context.Context, messages []ChatMessage, format interface{}) (string, Usage, error) {
 	cfg := config.Get()
 
 	// 构建请求体
@@ -131,50 +230,144 @@ func (c *Client) Chat(ctx context.Context, messages []ChatMessage, jsonMode bool
 		},
 	}
 
-	// JSON 模式：强制模型输出 JSON 格式
-	if jsonMode {
-		payload["format"] = "json"
+	// 输出格式：JSON 模式或 JSON Schema 约束的结构化输出
+	if format != nil {
+		payload["format"] = format
 	}
 
 	// 发送 POST 请求
 	body, _ := json.Marshal(payload)
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/chat", bytes.NewReader(body))
 	if err != nil {
-		return "", err
+		return "", Usage{}, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("请求失败: %w", err)
+		return "", Usage{}, fmt.Errorf("请求失败: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// 检查响应状态
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API错误 %d: %s", resp.StatusCode, string(body))
+		if format != nil && resp.StatusCode == http.StatusBadRequest && strings.Contains(string(body), "format") {
+			if _, schema := format.(map[string]interface{}); schema {
+				return "", Usage{}, errSchemaNotSupported // 旧版本的 format 只接受字符串
+			}
+			return "", Usage{}, errFormatNotSupported
+		}
+		return "", Usage{}, fmt.Errorf("API错误 %d: %s", resp.StatusCode, string(body))
 	}
 
 	// 解析响应
 	var chatResp struct {
-		Message ChatMessage `json:"message"` // 助手回复
+		Message         ChatMessage `json:"message"`           // 助手回复
+		PromptEvalCount int         `json:"prompt_eval_count"` // 提示词 token 数
+		EvalCount       int         `json:"eval_count"`        // 输出 token 数
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return "", err
+		return "", Usage{}, err
 	}
 
-	return chatResp.Message.Content, nil
+	usage := Usage{PromptTokens: chatResp.PromptEvalCount, ResponseTokens: chatResp.EvalCount}
+	return chatResp.Message.Content, usage, nil
 }
 
 // Embed 获取文本的向量嵌入
-// 调用 /api/embeddings 接口生成文本向量
 func (c *Client) Embed(ctx context.Context, text string) ([]float64, error) {
-	cfg := config.Get()
+	vecs, err := c.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vecs[0], nil
+}
+
+// EmbedBatch 批量获取文本的向量嵌入
+// 调用 /api/embed 接口，一次请求生成多个文本的向量；
+// 旧版 Ollama 没有该接口（返回 404）时逐个调用 /api/embeddings
+//
+// 返回值:
+//   - [][]float64: 与 texts 一一对应的向量
+//   - error: 请求失败或返回的向量数量不符时返回错误
+func (c *Client) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	if !c.legacyEmbed {
+		vecs, err := c.embed(ctx, texts)
+		if err != errEmbedNotSupported {
+			return vecs, err
+		}
+	}
+
+	vecs := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		vec, err := c.embedLegacy(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		vecs = append(vecs, vec)
+	}
+	c.legacyEmbed = true
+	return vecs, nil
+}
 
+// errEmbedNotSupported Ollama 版本过旧，没有 /api/embed 接口
+var errEmbedNotSupported = errors.New("不支持 /api/embed")
+
+// embed 调用 /api/embed 接口批量生成向量
+func (c *Client) embed(ctx context.Context, texts []string) ([][]float64, error) {
+	payload := map[string]interface{}{
+		"model": config.Get().EmbeddingModel,
+		"input": texts,
+	}
+
+	body, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/embed", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		// 模型未安装时也返回 404，但响应中带有错误信息
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("嵌入API错误: %s", errResp.Error)
+		}
+		return nil, errEmbedNotSupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("嵌入API错误: %d", resp.StatusCode)
+	}
+
+	var embResp struct {
+		Embeddings [][]float64 `json:"embeddings"` // 与输入一一对应的向量
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, err
+	}
+	if len(embResp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("嵌入API返回 %d 个向量，应为 %d 个", len(embResp.Embeddings), len(texts))
+	}
+	return embResp.Embeddings, nil
+}
+
+// embedLegacy 调用旧的 /api/embeddings 接口生成单个文本的向量
+func (c *Client) embedLegacy(ctx context.Context, text string) ([]float64, error) {
 	// 构建请求体
 	payload := map[string]string{
-		"model":  cfg.EmbeddingModel, // 使用嵌入模型
+		"model":  config.Get().EmbeddingModel, // 使用嵌入模型
 		"prompt": text,
 	}
 
@@ -210,9 +403,13 @@ func (c *Client) Embed(ctx context.Context, text string) ([]float64, error) {
 
 // ClassifyFiles 批量分类文件
 // 构建提示词让 LLM 对文件进行智能分类
-func (c *Client) ClassifyFiles(ctx context.Context, files []map[string]interface{}, rules []map[string]string) (map[string]interface{}, error) {
+func (c *Client) ClassifyFiles(ctx context.Context, files []map[string]interface{}, rules []map[string]string) (result map[string]interface{}, err error) {
+	start := time.Now()
+	var usage Usage
+	defer func() { c.record(c.model, CallClassify, len(files), usage, start, err) }()
+
 	// 构建系统提示词和用户提示词
-	systemPrompt := buildSystemPrompt(rules)
+	systemPrompt := buildSystemPrompt(rules, c.categorySection())
 	userPrompt := buildUserPrompt(files)
 
 	// 组装对话消息
@@ -221,34 +418,42 @@ func (c *Client) ClassifyFiles(ctx context.Context, files []map[string]interface
 		{Role: "user", Content: userPrompt},     // 用户请求
 	}
 
-	// 调用 LLM（启用 JSON 模式）
-	response, err := c.Chat(ctx, messages, true)
+	// 调用 LLM（用 JSON Schema 约束输出结构）
+	response, usage, err := c.chat(ctx, messages, classifySchema)
 	if err != nil {
 		return nil, err
 	}
+	return decodeResponse(response, c.structured())
+}
 
-	// 解析 JSON 响应
-	var result map[string]interface{}
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
-		// 尝试从响应中提取 JSON（处理模型可能添加的额外文字）
-		re := regexp.MustCompile(`\{[\s\S]*\}`)
-		if match := re.FindString(response); match != "" {
-			if err := json.Unmarshal([]byte(match), &result); err != nil {
-				return nil, fmt.Errorf("解析失败: %w", err)
-			}
-		} else {
-			return nil, fmt.Errorf("无法解析响应")
-		}
-	}
+// ==================== 提示词构建函数 ====================
 
-	return result, nil
+// SetFolders 设置只允许使用的分类路径（目标目录中已有的文件夹，如 工作/报告）
+// 设置后提示词中用这些路径代替分类体系，要求模型只从中选择
+func (c *Client) SetFolders(folders []string) {
+	c.folders = folders
 }
 
-// ==================== 提示词构建函数 ====================
+// categorySection 生成提示词中的分类说明：设置了已有文件夹时列出文件夹，否则使用分类体系
+func (c *Client) categorySection() string {
+	if len(c.folders) == 0 {
+		return taxonomy.Get().PromptSection()
+	}
+	var sb strings.Builder
+	sb.WriteString("只能使用以下已有文件夹作为分类路径，path 必须与其中一项完全一致，不要新建分类：\n")
+	for i, f := range c.folders {
+		if i >= maxPromptFolders {
+			break
+		}
+		sb.WriteString("- " + f + "\n")
+	}
+	sb.WriteString("没有合适的文件夹时选择最接近的一项并降低 confidence。\n")
+	return sb.String()
+}
 
 // buildSystemPrompt 构建系统提示词
-// 定义分类规则和输出格式要求
-func buildSystemPrompt(rules []map[string]string) string {
+// 定义分类规则和输出格式要求，categories 为分类说明（分类体系或已有文件夹）
+func buildSystemPrompt(rules []map[string]string, categories string) string {
 	prompt := `你是专业的文件分类助手。根据文件名智能分类，理解文件的用途和含义。
 
 分类原则：
@@ -256,17 +461,18 @@ func buildSystemPrompt(rules []map[string]string) string {
 2. 识别项目名、客户名、业务领域
 3. 注意日期、版本号、关键词
 4. 相关文件归入同一类别
-
-常用分类：
-- 文档：合同、报告、方案、笔记、简历
-- 图片：照片、截图、设计稿、图标
-- 视频：电影、教程、录屏、会议
-- 音频：音乐、录音、播客
-- 代码：源码、配置、脚本
-- 压缩包：备份、资料包
-- 安装包：软件、工具
-- 数据：表格、数据库、导出
-
+5. 提供了 media 元数据时结合时长、分辨率、艺术家、专辑判断，
+   如区分 音乐/专辑 与 音乐/播客、视频/电影 与 视频/录屏
+6. 提供了 ocr_text（扫描件、截图中识别出的文字）时以文字内容为主判断，
+   如「扫描件_001.pdf」按内容归入 合同、发票、证件 等
+7. 提供了 language（文件名的主要语言：ja 日文、ko 韩文、cyrillic 西里尔文）时可作为参考，
+   如日文命名的视频多为动画或日剧，但仍以文件名语义为主
+8. 提供了 origin（下载来源的域名，邮件附件为发件人的域名）时可作为参考，
+   如 github.com 下载的多为代码或软件，银行、税务网站下载的多为财务文件
+9. path 是从主分类开始的分类路径，通常为两级（主分类、子分类），
+   文件名包含客户、项目、年份等信息时可以更深，如 ["工作", "客户A", "合同", "2024"]，最多 6 级
+
+` + categories + `
 必须返回有效JSON。`
 
 	// 如果有已学习的规则，添加到提示词中
@@ -296,8 +502,7 @@ func buildUserPrompt(files []map[string]interface{}) string {
   "classifications": [
     {
       "filename": "文件名",
-      "category": "主分类",
-      "subcategory": "子分类",
+      "path": ["主分类", "子分类"],
       "confidence": 0.95,
       "reasoning": "分类理由",
       "keywords": ["关键词"]
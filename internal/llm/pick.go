@@ -0,0 +1,67 @@
+// Package llm Ollama LLM 客户端模块
+// pick.go - 按自然语言指令挑选文件
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PickFiles 按自然语言指令从文件列表中挑选文件
+// 模型只判断每个文件是否符合指令，并从指令中提取目标分类路径，不对其他文件分类
+//
+// 参数:
+//   - ctx: 上下文（控制超时）
+//   - instruction: 用户指令，如「把所有和报销相关的文件放到 财务/报销」
+//   - files: 文件信息列表，每个文件带有批次内编号 id
+//
+// 返回值:
+//   - map[string]interface{}: 挑选结果（path、selected）
+//   - error: 如果请求或解析失败，返回错误
+func (c *Client) PickFiles(ctx context.Context, instruction string, files []map[string]interface{}) (result map[string]interface{}, err error) {
+	start := time.Now()
+	var usage Usage
+	defer func() { c.record(c.model, CallPick, len(files), usage, start, err) }()
+
+	systemPrompt := `你是文件整理助手。用户会用一句话说明要把哪些文件放到哪里，
+你需要从给出的文件列表中挑出符合要求的文件。
+
+挑选原则：
+1. 只挑选明确符合用户描述的文件，拿不准的不要选
+2. 根据文件名语义判断，提供了 content、ocr_text、media 时一并参考
+3. path 是用户指定的目标分类路径（从主分类开始，如 ["财务", "报销"]），
+   用户没有说明时根据描述给出合适的两级分类
+4. 没有符合的文件时 selected 返回空数组
+
+必须返回有效JSON。`
+
+	filesJSON, _ := json.MarshalIndent(files, "", "  ")
+	userPrompt := fmt.Sprintf(`用户指令：%s
+
+文件列表（共 %d 个）：
+
+%s
+
+返回JSON格式：
+{
+  "path": ["主分类", "子分类"],
+  "selected": [
+    {"id": 0, "confidence": 0.9, "reasoning": "挑选理由"}
+  ]
+}`, instruction, len(files), string(filesJSON))
+
+	response, usage, err := c.chat(ctx, []ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}, formatJSON)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse(response, false)
+}
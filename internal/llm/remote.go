@@ -0,0 +1,204 @@
+// Package llm Ollama LLM 客户端模块
+// remote.go - 远程 LLM 提供方（Anthropic / Gemini）
+// 仅在用户配置远程提供方并通过 --allow-remote 显式允许时使用
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"filo/internal/config"
+)
+
+// 远程 API 地址
+const (
+	anthropicURL     = "https://api.anthropic.com/v1/messages"
+	anthropicVersion = "2023-06-01"
+	geminiURLFormat  = "https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent"
+)
+
+// IsRemote 是否使用远程提供方
+func (c *Client) IsRemote() bool {
+	return c.provider != "" && c.provider != config.ProviderOllama
+}
+
+// Provider 当前 LLM 提供方名称
+func (c *Client) Provider() string {
+	if c.provider == "" {
+		return config.ProviderOllama
+	}
+	return c.provider
+}
+
+// splitMessages 拆分系统提示词和对话消息
+// Anthropic 和 Gemini 都要求系统提示词单独传递
+func splitMessages(messages []ChatMessage) (string, []ChatMessage) {
+	var system []string
+	var rest []ChatMessage
+	for _, m := range messages {
+		if m.Role == "system" {
+			system = append(system, m.Content)
+		} else {
+			rest = append(rest, m)
+		}
+	}
+	return strings.Join(system, "\n\n"), rest
+}
+
+// chatAnthropic 调用 Anthropic Messages API
+func (c *Client) chatAnthropic(ctx context.Context, messages []ChatMessage) (string, Usage, error) {
+	cfg := config.Get()
+	system, rest := splitMessages(messages)
+
+	payload := map[string]interface{}{
+		"model":       c.model,
+		"max_tokens":  cfg.MaxTokens,
+		"system":      system,
+		"messages":    rest,
+		"temperature": cfg.Temperature,
+	}
+
+	headers := map[string]string{
+		"x-api-key":         c.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+	respBody, err := c.postJSON(ctx, anthropicURL, headers, payload)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	// 解析响应
+	var resp struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return "", Usage{}, err
+	}
+
+	var sb strings.Builder
+	for _, part := range resp.Content {
+		if part.Type == "text" {
+			sb.WriteString(part.Text)
+		}
+	}
+	return sb.String(), Usage{PromptTokens: resp.Usage.InputTokens, ResponseTokens: resp.Usage.OutputTokens}, nil
+}
+
+// chatGemini 调用 Gemini generateContent API
+func (c *Client) chatGemini(ctx context.Context, messages []ChatMessage, jsonMode bool) (string, Usage, error) {
+	cfg := config.Get()
+	system, rest := splitMessages(messages)
+
+	// Gemini 的角色名为 user / model
+	contents := make([]map[string]interface{}, 0, len(rest))
+	for _, m := range rest {
+		role := m.Role
+		if role == "assistant" {
+			role = "model"
+		}
+		contents = append(contents, map[string]interface{}{
+			"role":  role,
+			"parts": []map[string]string{{"text": m.Content}},
+		})
+	}
+
+	genConfig := map[string]interface{}{
+		"temperature":     cfg.Temperature,
+		"maxOutputTokens": cfg.MaxTokens,
+	}
+	if jsonMode {
+		genConfig["responseMimeType"] = "application/json"
+	}
+
+	payload := map[string]interface{}{
+		"contents":         contents,
+		"generationConfig": genConfig,
+	}
+	if system != "" {
+		payload["systemInstruction"] = map[string]interface{}{
+			"parts": []map[string]string{{"text": system}},
+		}
+	}
+
+	headers := map[string]string{"x-goog-api-key": c.apiKey}
+	respBody, err := c.postJSON(ctx, fmt.Sprintf(geminiURLFormat, c.model), headers, payload)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	// 解析响应
+	var resp struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+		} `json:"candidates"`
+		UsageMetadata struct {
+			PromptTokenCount     int `json:"promptTokenCount"`
+			CandidatesTokenCount int `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return "", Usage{}, err
+	}
+	usage := Usage{PromptTokens: resp.UsageMetadata.PromptTokenCount, ResponseTokens: resp.UsageMetadata.CandidatesTokenCount}
+	if len(resp.Candidates) == 0 {
+		return "", usage, fmt.Errorf("Gemini 未返回结果")
+	}
+
+	var sb strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		sb.WriteString(part.Text)
+	}
+	return sb.String(), usage, nil
+}
+
+// postJSON 发送 JSON POST 请求并返回响应体
+func (c *Client) postJSON(ctx context.Context, url string, headers map[string]string, payload interface{}) ([]byte, error) {
+	if c.apiKey == "" {
+		return nil, fmt.Errorf("未配置 %s API 密钥", c.provider)
+	}
+
+	body, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API错误 %d: %s", resp.StatusCode, string(respBody))
+	}
+	return respBody, nil
+}
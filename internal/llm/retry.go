@@ -0,0 +1,90 @@
+// Package llm Ollama LLM 客户端模块
+// retry.go - LLM 调用的超时与重试策略
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package llm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// RetryPolicy LLM 调用的重试策略
+type RetryPolicy struct {
+	Timeout  time.Duration // 单次调用超时
+	Attempts int           // 最多尝试次数（含首次）
+	Backoff  time.Duration // 首次重试前的等待时间，之后每次翻倍
+}
+
+// Policy 获取客户端当前的重试策略
+func (c *Client) Policy() RetryPolicy {
+	return c.policy
+}
+
+// ClassifyFilesWithRetry 按重试策略批量分类文件
+// 每次尝试使用独立的超时，失败后按指数退避等待再重试
+// 所有尝试都失败时返回最后一次的错误；parent 被取消时立即返回其错误，不再重试
+func (c *Client) ClassifyFilesWithRetry(parent context.Context, files []map[string]interface{}, rules []map[string]string) (map[string]interface{}, error) {
+	return c.withRetry(parent, func(ctx context.Context) (map[string]interface{}, error) {
+		return c.ClassifyFiles(ctx, files, rules)
+	})
+}
+
+// PickFilesWithRetry 按重试策略挑选文件，重试方式与 ClassifyFilesWithRetry 相同
+func (c *Client) PickFilesWithRetry(parent context.Context, instruction string, files []map[string]interface{}) (map[string]interface{}, error) {
+	return c.withRetry(parent, func(ctx context.Context) (map[string]interface{}, error) {
+		return c.PickFiles(ctx, instruction, files)
+	})
+}
+
+// NameClustersWithRetry 按重试策略为文件分组建议分类名，重试方式与 ClassifyFilesWithRetry 相同
+func (c *Client) NameClustersWithRetry(parent context.Context, clusters []map[string]interface{}) (map[string]interface{}, error) {
+	return c.withRetry(parent, func(ctx context.Context) (map[string]interface{}, error) {
+		return c.NameClusters(ctx, clusters)
+	})
+}
+
+// withRetry 按重试策略调用 fn，每次尝试使用独立的超时
+func (c *Client) withRetry(parent context.Context, fn func(ctx context.Context) (map[string]interface{}, error)) (map[string]interface{}, error) {
+	var lastErr error
+	backoff := c.policy.Backoff
+
+	for attempt := 0; attempt < c.policy.Attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-parent.Done():
+				return nil, parent.Err()
+			}
+			backoff *= 2
+		}
+
+		ctx, cancel := context.WithTimeout(parent, c.policy.Timeout)
+		resp, err := fn(ctx)
+		cancel()
+		if err == nil {
+			return resp, nil
+		}
+		if parent.Err() != nil {
+			return nil, parent.Err()
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// IsTimeout 判断错误是否由超时引起
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
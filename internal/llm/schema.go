@@ -0,0 +1,90 @@
+// Package llm Ollama LLM 客户端模块
+// schema.go - 结构化输出：用 JSON Schema 约束模型返回的分类结果（Ollama 0.5 及以上），
+// 保证字段齐全、类型正确；旧版本 Ollama 和远程提供方退回 JSON 模式，从响应中提取 JSON
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// formatJSON JSON 模式：要求模型输出 JSON，不限制结构
+const formatJSON = "json"
+
+// errSchemaNotSupported Ollama 不支持 JSON Schema 格式的 format（0.5 之前的版本）
+var errSchemaNotSupported = errors.New("Ollama 不支持结构化输出")
+
+// stringArray 字符串数组的 JSON Schema
+func stringArray() map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}
+}
+
+// classifySchema 批量分类结果的 JSON Schema，与 buildUserPrompt 中的返回格式一致
+var classifySchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"classifications": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"filename": map[string]interface{}{"type": "string"},
+					"path": map[string]interface{}{
+						"type":     "array",
+						"items":    map[string]interface{}{"type": "string"},
+						"minItems": 1,
+						"maxItems": 6,
+					},
+					"confidence": map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1},
+					"reasoning":  map[string]interface{}{"type": "string"},
+					"keywords":   stringArray(),
+				},
+				"required": []string{"filename", "path", "confidence", "reasoning", "keywords"},
+			},
+		},
+	},
+	"required": []string{"classifications"},
+}
+
+// structured 本次调用是否使用了结构化输出（本地 Ollama 且支持 JSON Schema）
+func (c *Client) structured() bool {
+	return !c.IsRemote() && !c.legacyFormat && !c.noFormat
+}
+
+// jsonObject 匹配响应中的 JSON 对象（模型在 JSON 前后添加了说明文字时）
+var jsonObject = regexp.MustCompile(`\{[\s\S]*\}`)
+
+// decodeResponse 解析模型返回的 JSON
+// 结构化输出的响应必定是完整的 JSON，直接解析；JSON 模式下模型可能在前后添加文字，解析失败时提取其中的 JSON 对象
+//
+// 参数:
+//   - response: 模型的回复
+//   - structured: 是否使用了结构化输出
+//
+// 返回值:
+//   - map[string]interface{}: 解析结果
+//   - error: 无法解析时返回错误
+func decodeResponse(response string, structured bool) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := json.Unmarshal([]byte(response), &result)
+	if err == nil {
+		return result, nil
+	}
+	if structured {
+		return nil, fmt.Errorf("解析失败: %w", err)
+	}
+	match := jsonObject.FindString(response)
+	if match == "" {
+		return nil, fmt.Errorf("无法解析响应")
+	}
+	if err := json.Unmarshal([]byte(match), &result); err != nil {
+		return nil, fmt.Errorf("解析失败: %w", err)
+	}
+	return result, nil
+}
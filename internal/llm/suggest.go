@@ -0,0 +1,73 @@
+// Package llm Ollama LLM 客户端模块
+// suggest.go - 为相似文件的分组建议新的分类名
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"filo/internal/taxonomy"
+)
+
+// NameClusters 为没有合适分类的文件分组建议分类名
+// 模型参考现有分类体系：分组属于已有的主分类时只建议子分类，否则建议新的主分类
+//
+// 参数:
+//   - ctx: 上下文（控制超时）
+//   - clusters: 文件分组，每组带有编号 id、文件名示例 files 和共同关键词 keywords
+//
+// 返回值:
+//   - map[string]interface{}: 建议结果（suggestions）
+//   - error: 如果请求或解析失败，返回错误
+func (c *Client) NameClusters(ctx context.Context, clusters []map[string]interface{}) (result map[string]interface{}, err error) {
+	start := time.Now()
+	var usage Usage
+	files := 0
+	for _, cl := range clusters {
+		if names, ok := cl["files"].([]string); ok {
+			files += len(names)
+		}
+	}
+	defer func() { c.record(c.model, CallSuggest, files, usage, start, err) }()
+
+	systemPrompt := `你是文件整理助手。下面的每一组文件名彼此相似，但现有的分类都不合适，
+需要为每一组起一个新的分类名。
+
+命名原则：
+1. 根据文件名的共同含义命名，名称简短（2-6 个字），不要使用「其他」「杂项」「未分类」
+2. 分组属于现有的某个主分类时沿用该主分类，只起新的子分类；否则起新的主分类和子分类
+3. description 用一句话说明这一类文件
+4. keywords 从文件名中挑出最能识别这一类文件的词（必须是文件名中出现的原词，1-3 个），
+   将用作分类规则，不要选日期、编号和「副本」「最终版」这类通用词
+5. 一组文件看不出共同含义时不返回该组
+
+` + taxonomy.Get().PromptSection() + `
+必须返回有效JSON。`
+
+	clustersJSON, _ := json.MarshalIndent(clusters, "", "  ")
+	userPrompt := fmt.Sprintf(`文件分组（共 %d 组）：
+
+%s
+
+返回JSON格式：
+{
+  "suggestions": [
+    {"id": 0, "category": "主分类", "subcategory": "子分类", "description": "分类说明", "keywords": ["关键词"]}
+  ]
+}`, len(clusters), string(clustersJSON))
+
+	response, usage, err := c.chat(ctx, []ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}, formatJSON)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse(response, false)
+}
@@ -0,0 +1,56 @@
+// Package llm Ollama LLM 客户端模块
+// usage.go - 调用统计：记录每次模型调用的 token 数、耗时和是否失败，供 filo stats --llm 调整模型和批大小
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package llm
+
+import "time"
+
+// 调用类型
+const (
+	CallClassify = "classify" // 按文件名批量分类
+	CallPick     = "pick"     // 按指令挑选文件
+	CallVision   = "vision"   // 按图片内容分类
+	CallSuggest  = "suggest"  // 为相似文件的分组建议分类名
+)
+
+// Usage 一次请求的 token 用量（提供方未返回时为 0）
+type Usage struct {
+	PromptTokens   int // 提示词 token 数
+	ResponseTokens int // 输出 token 数
+}
+
+// CallRecord 一次模型调用的统计
+type CallRecord struct {
+	Model          string        // 模型名称
+	Kind           string        // 调用类型（见 CallClassify 等）
+	Files          int           // 本次调用处理的文件数
+	PromptTokens   int           // 提示词 token 数
+	ResponseTokens int           // 输出 token 数
+	Latency        time.Duration // 耗时（从发出请求到解析完响应）
+	Err            error         // 失败原因，成功时为 nil（响应无法解析也算失败）
+}
+
+// SetObserver 设置调用统计的接收函数，每次模型调用（含重试的每一次）结束后调用
+// 可能在多个批次的 goroutine 中同时调用，fn 需要自行保证并发安全
+func (c *Client) SetObserver(fn func(CallRecord)) {
+	c.observer = fn
+}
+
+// record 将一次调用的统计交给接收函数
+func (c *Client) record(model, kind string, files int, usage Usage, start time.Time, err error) {
+	if c.observer == nil {
+		return
+	}
+	c.observer(CallRecord{
+		Model:          model,
+		Kind:           kind,
+		Files:          files,
+		PromptTokens:   usage.PromptTokens,
+		ResponseTokens: usage.ResponseTokens,
+		Latency:        time.Since(start),
+		Err:            err,
+	})
+}
@@ -0,0 +1,167 @@
+// Package llm Ollama LLM 客户端模块
+// vision.go - 本地多模态模型调用（图片 + 提示词）
+// 图片只发送给本机的 Ollama，不经过远程提供方
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"filo/internal/config"
+)
+
+// Generate 调用 Ollama /api/generate，附带图片
+// 用于 OCR 和图片分类等需要多模态模型的场景
+//
+// 参数:
+//   - ctx: 上下文（控制超时）
+//   - model: 多模态模型名称（如 llava、qwen2.5vl）
+//   - prompt: 提示词
+//   - images: 图片内容（PNG/JPEG 等原始字节）
+//
+// 返回值:
+//   - string: 模型输出
+//   - error: 如果请求失败，返回错误
+func (c *Client) Generate(ctx context.Context, model, prompt string, images [][]byte) (string, error) {
+	response, _, err := c.generate(ctx, model, prompt, images, false)
+	return response, err
+}
+
+// ClassifyImage 使用多模态模型按图片内容分类
+// 用于 IMG_1234.jpg、Screenshot_xxx.png 这类从文件名看不出内容的图片
+//
+// 参数:
+//   - ctx: 上下文（控制超时）
+//   - model: 多模态模型名称
+//   - filename: 文件名（仅作参考）
+//   - thumbnail: 图片缩略图
+//
+// 返回值:
+//   - map[string]interface{}: 分类结果（category、subcategory、confidence、reasoning）
+//   - error: 如果请求或解析失败，返回错误
+func (c *Client) ClassifyImage(ctx context.Context, model, filename string, thumbnail []byte) (result map[string]interface{}, err error) {
+	start := time.Now()
+	var usage Usage
+	defer func() { c.record(model, CallVision, 1, usage, start, err) }()
+
+	prompt := `你是图片分类助手。根据图片内容（而不是文件名）判断图片属于哪一类。
+
+重点区分：
+- 截图：手机或电脑屏幕截图、聊天记录、网页、软件界面
+- 表情包：带文字的梗图、贴纸、动图
+- 票据：购物小票、发票、收据、账单的照片
+- 拍照文档：拍摄的纸质文档、证件、白板、课件
+- 个人照片：人物、风景、宠物、美食、旅行等生活照片
+
+` + c.categorySection() + `
+文件名: ` + filename + `
+
+只返回JSON：
+{"category": "主分类", "subcategory": "子分类", "confidence": 0.9, "reasoning": "分类理由"}`
+
+	response, usage, err := c.generate(ctx, model, prompt, [][]byte{thumbnail}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		// 尝试从响应中提取 JSON（处理模型可能添加的额外文字）
+		match := regexp.MustCompile(`\{[\s\S]*\}`).FindString(response)
+		if match == "" {
+			return nil, fmt.Errorf("无法解析响应")
+		}
+		if err := json.Unmarshal([]byte(match), &result); err != nil {
+			return nil, fmt.Errorf("解析失败: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// generate 调用 /api/generate，jsonMode 时要求模型输出 JSON，同时返回 token 用量
+func (c *Client) generate(ctx context.Context, model, prompt string, images [][]byte, jsonMode bool) (response string, usage Usage, err error) {
+	if t := c.transcript; t != nil {
+		entry := transcriptEntry{Provider: config.ProviderOllama, Model: model, Endpoint: "generate", Prompt: prompt, Images: len(images)}
+		if jsonMode {
+			entry.Format = "json"
+		}
+		defer func(start time.Time) {
+			entry.Response = response
+			if err != nil {
+				entry.Error = err.Error()
+			}
+			t.add(entry, start)
+		}(time.Now())
+	}
+
+	encoded := make([]string, len(images))
+	for i, img := range images {
+		encoded[i] = base64.StdEncoding.EncodeToString(img)
+	}
+
+	payload := map[string]interface{}{
+		"model":  model,
+		"prompt": prompt,
+		"images": encoded,
+		"stream": false,
+		"options": map[string]interface{}{
+			"temperature": 0, // 识别任务不需要随机性
+		},
+	}
+	if jsonMode {
+		payload["format"] = "json"
+	}
+
+	body, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return "", Usage{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", Usage{}, fmt.Errorf("API错误 %d: %s", resp.StatusCode, string(body))
+	}
+
+	var genResp struct {
+		Response        string `json:"response"`          // 模型输出
+		PromptEvalCount int    `json:"prompt_eval_count"` // 提示词 token 数
+		EvalCount       int    `json:"eval_count"`        // 输出 token 数
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&genResp); err != nil {
+		return "", Usage{}, err
+	}
+	return genResp.Response, Usage{PromptTokens: genResp.PromptEvalCount, ResponseTokens: genResp.EvalCount}, nil
+}
+
+// HasLocalModel 检查本机 Ollama 是否安装了指定模型
+// 与 HasModel 不同，远程提供方时也检查本机（多模态调用只走本地）
+func (c *Client) HasLocalModel(model string) bool {
+	models, err := c.ListModels()
+	if err != nil {
+		return false
+	}
+	for _, m := range models {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+// Package lock 进程锁模块
+// 在数据目录下的 filo.lock 上加建议锁，防止多个 filo 进程同时整理文件、写入批次和学习规则
+// 锁文件中记录持有者的 PID 和命令行，供等待的进程提示用户
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package lock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filo/internal/config"
+)
+
+// pollInterval 等待锁时的重试间隔
+const pollInterval = 200 * time.Millisecond
+
+// Lock 已持有的进程锁
+type Lock struct {
+	f *os.File
+}
+
+// LockedError 锁被其他进程持有
+type LockedError struct {
+	Holder string // 持有者信息（PID 和命令行），读取失败时为空
+}
+
+// Error 实现 error 接口
+func (e *LockedError) Error() string {
+	if e.Holder == "" {
+		return "另一个 filo 进程正在运行"
+	}
+	return "另一个 filo 进程正在运行（" + e.Holder + "）"
+}
+
+// Path 锁文件路径
+func Path() string {
+	return filepath.Join(config.Get().DataDir, "filo.lock")
+}
+
+// TryAcquire 尝试获取锁，锁被占用时立即返回 *LockedError
+func TryAcquire() (*Lock, error) {
+	f, err := os.OpenFile(Path(), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := tryLock(f)
+	if err != nil || !ok {
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, &LockedError{Holder: holder()}
+	}
+
+	// 记录持有者，供其他进程提示
+	f.Truncate(0)
+	f.WriteAt([]byte(fmt.Sprintf("PID %d · %s", os.Getpid(), commandLine())), 0)
+	return &Lock{f: f}, nil
+}
+
+// Acquire 获取锁，锁被占用时排队等待
+// 开始等待时以锁被占用的错误调用一次 onWait（可为 nil），超过 timeout 仍未获取时返回 *LockedError；
+// timeout 为 0 时不等待
+//
+// 参数:
+//   - timeout: 最长等待时间
+//   - onWait: 开始等待时的回调
+//
+// 返回值:
+//   - *Lock: 已持有的锁，使用完毕后调用 Release
+//   - error: 如果获取失败，返回错误
+func Acquire(timeout time.Duration, onWait func(err *LockedError)) (*Lock, error) {
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		l, err := TryAcquire()
+		locked, isLocked := err.(*LockedError)
+		if !isLocked || !time.Now().Before(deadline) {
+			return l, err
+		}
+		if !waiting && onWait != nil {
+			onWait(locked)
+		}
+		waiting = true
+		time.Sleep(pollInterval)
+	}
+}
+
+// Release 释放锁
+// 锁文件保留在数据目录中，删除会让正在等待的进程锁住已不存在的文件
+func (l *Lock) Release() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	l.f.Truncate(0)
+	unlock(l.f)
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// holder 读取锁文件中记录的持有者
+func holder() string {
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// commandLine 当前进程的命令行（程序名不含路径）
+func commandLine() string {
+	if len(os.Args) == 0 {
+		return "filo"
+	}
+	args := append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...)
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,17 @@
+// Package lock 进程锁模块
+// lock_other.go - 不支持文件锁的平台，加锁总是成功
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build !unix && !windows
+
+package lock
+
+import "os"
+
+// tryLock 不支持文件锁，总是成功
+func tryLock(f *os.File) (bool, error) { return true, nil }
+
+// unlock 不支持文件锁，无需释放
+func unlock(f *os.File) error { return nil }
@@ -0,0 +1,202 @@
+// Package mcp MCP（Model Context Protocol）服务
+// server.go - 基于标准输入输出的 JSON-RPC 2.0 服务，每行一条消息
+// 让 Claude Desktop 等 AI 助手通过工具调用使用 filo 的学习记忆整理文件，
+// 整理和撤销沿用命令行的安全检查（受保护目录、进程锁）
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"filo/internal/config"
+	"filo/internal/storage"
+)
+
+// ==================== 常量定义 ====================
+
+// ProtocolVersion 支持的 MCP 协议版本（客户端未指定时使用）
+const ProtocolVersion = "2024-11-05"
+
+// maxMessageSize 单条消息的最大长度
+const maxMessageSize = 4 << 20
+
+// JSON-RPC 错误码
+const (
+	codeParseError     = -32700 // 消息不是合法的 JSON
+	codeInvalidRequest = -32600 // 不是合法的请求
+	codeMethodNotFound = -32601 // 方法不存在
+	codeInvalidParams  = -32602 // 参数错误
+)
+
+// ==================== 类型定义 ====================
+
+// request JSON-RPC 请求或通知（没有 id 的是通知，不需要回复）
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response JSON-RPC 回复
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError JSON-RPC 错误
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server MCP 服务
+type Server struct {
+	db  *storage.Database
+	out io.Writer
+	mu  sync.Mutex // 串行化写出，保证每行一条完整消息
+}
+
+// NewServer 创建 MCP 服务，回复写入 out
+func NewServer(out io.Writer) (*Server, error) {
+	db, err := storage.NewDatabase()
+	if err != nil {
+		return nil, err
+	}
+	db.StartCheckpointer(time.Duration(config.Get().WALCheckpointInterval) * time.Minute)
+	return &Server{db: db, out: out}, nil
+}
+
+// Close 关闭数据库连接
+func (s *Server) Close() error {
+	return s.db.Close()
+}
+
+// ==================== 消息循环 ====================
+
+// Serve 逐行读取请求并回复，输入结束或 ctx 取消时返回
+// 请求按顺序处理，整理完成前不会处理下一条请求
+func (s *Server) Serve(ctx context.Context, in io.Reader) error {
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for sc.Scan() {
+		if ctx.Err() != nil {
+			return nil
+		}
+		line := sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		s.handle(line)
+	}
+	return sc.Err()
+}
+
+// handle 处理一条消息
+func (s *Server) handle(line []byte) {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		s.reply(response{ID: json.RawMessage("null"), Error: &rpcError{codeParseError, "无法解析消息: " + err.Error()}})
+		return
+	}
+	if req.Method == "" {
+		if req.ID != nil {
+			s.reply(response{ID: req.ID, Error: &rpcError{codeInvalidRequest, "缺少 method"}})
+		}
+		return
+	}
+
+	result, rerr := s.dispatch(req)
+	if req.ID == nil {
+		return // 通知不回复
+	}
+	s.reply(response{ID: req.ID, Result: result, Error: rerr})
+}
+
+// dispatch 按方法名处理请求
+func (s *Server) dispatch(req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+		version := params.ProtocolVersion
+		if version == "" {
+			version = ProtocolVersion
+		}
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "filo", "version": config.Version},
+			"instructions": "filo 用本地学习记忆和 AI 整理文件。先用 classify_directory 预览整理计划，" +
+				"用户确认后再以 execute=true 执行；执行后可用 undo_batch 撤销。",
+		}, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": toolList()}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+			return nil, &rpcError{codeInvalidParams, "缺少工具名称"}
+		}
+		t := findTool(params.Name)
+		if t == nil {
+			return nil, &rpcError{codeInvalidParams, "未知的工具: " + params.Name}
+		}
+		return s.call(t, params.Arguments), nil
+	default:
+		if req.ID == nil {
+			return nil, nil // 其他通知（如 notifications/initialized）无需处理
+		}
+		return nil, &rpcError{codeMethodNotFound, "不支持的方法: " + req.Method}
+	}
+}
+
+// call 调用工具，结果以 JSON 文本返回；工具出错时返回 isError 而不是协议错误
+func (s *Server) call(t *tool, args json.RawMessage) map[string]interface{} {
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+
+	result, err := t.run(s, args)
+	text := ""
+	if err != nil {
+		text = err.Error()
+	} else if data, merr := json.MarshalIndent(result, "", "  "); merr != nil {
+		err, text = merr, merr.Error()
+	} else {
+		text = string(data)
+	}
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": err != nil,
+	}
+}
+
+// reply 写出一条回复
+func (s *Server) reply(resp response) {
+	resp.JSONRPC = "2.0"
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(response{JSONRPC: "2.0", ID: resp.ID, Error: &rpcError{codeInvalidRequest, err.Error()}})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.out, "%s\n", data)
+}
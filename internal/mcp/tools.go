@@ -0,0 +1,348 @@
+// Package mcp MCP（Model Context Protocol）服务
+// tools.go - 提供给 AI 助手的工具：分类整理目录、查找整理过的文件、查看统计、撤销批次
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/guard"
+	"filo/internal/lock"
+	"filo/internal/organizer"
+	"filo/internal/scanner"
+)
+
+// ==================== 常量定义 ====================
+
+const (
+	maxFilesPerFolder = 20 // 整理计划中每个文件夹最多列出的文件数（避免撑爆对话上下文）
+	defaultFindLimit  = 20 // find_file 默认返回的记录数
+	recentBatches     = 5  // get_statistics 列出的最近批次数
+)
+
+// ==================== 工具定义 ====================
+
+// tool MCP 工具
+type tool struct {
+	Name        string                                                     `json:"name"`
+	Description string                                                     `json:"description"`
+	InputSchema map[string]interface{}                                     `json:"inputSchema"`
+	run         func(s *Server, args json.RawMessage) (interface{}, error) `json:"-"`
+}
+
+// tools 所有工具
+var tools = []*tool{
+	{
+		Name: "classify_directory",
+		Description: "扫描目录并按学习记忆、规则和本地 AI 分类，返回整理计划（目标文件夹及其中的文件）。" +
+			"默认只预览不移动文件；用户确认后传 execute=true 执行，返回可用于撤销的批次 ID。",
+		InputSchema: schema(map[string]interface{}{
+			"dir":       prop("string", "要整理的目录（支持 ~）"),
+			"target":    prop("string", "目标目录，可以包含 {source_dir_name}、{year} 等占位符，默认为配置 target_dir 或 <dir>/已整理"),
+			"recursive": prop("boolean", "是否递归扫描子目录"),
+			"execute":   prop("boolean", "是否实际移动文件，默认 false 只预览"),
+		}, "dir"),
+		run: classifyDirectory,
+	},
+	{
+		Name:        "find_file",
+		Description: "按文件名查找 filo 整理过的文件，返回文件现在的位置、原来的位置、分类和批次。",
+		InputSchema: schema(map[string]interface{}{
+			"name":  prop("string", "文件名中包含的文字"),
+			"limit": prop("integer", "最多返回的记录数，默认 20"),
+		}, "name"),
+		run: findFile,
+	},
+	{
+		Name:        "get_statistics",
+		Description: "查看学习统计（记录数、规则数、分类分布）、当前模型、待确认文件数和最近可撤销的批次。",
+		InputSchema: schema(map[string]interface{}{}),
+		run:         getStatistics,
+	},
+	{
+		Name:        "undo_batch",
+		Description: "撤销一次整理，把文件移回原位置。不指定 batch_id 时撤销最近一次。",
+		InputSchema: schema(map[string]interface{}{
+			"batch_id": prop("string", "批次 ID（classify_directory 执行后或 get_statistics 中返回）"),
+		}),
+		run: undoBatch,
+	},
+}
+
+// toolList 返回工具列表
+func toolList() []*tool {
+	return tools
+}
+
+// findTool 按名称查找工具
+func findTool(name string) *tool {
+	for _, t := range tools {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// schema 构造 JSON Schema 对象
+func schema(props map[string]interface{}, required ...string) map[string]interface{} {
+	s := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// prop 构造 JSON Schema 属性
+func prop(typ, desc string) map[string]interface{} {
+	return map[string]interface{}{"type": typ, "description": desc}
+}
+
+// ==================== classify_directory ====================
+
+// planFile 计划中的单个文件
+type planFile struct {
+	Name        string  `json:"name"`
+	Category    string  `json:"category"`
+	Subcategory string  `json:"subcategory,omitempty"`
+	Confidence  float64 `json:"confidence"`
+	Source      string  `json:"source"`
+}
+
+// planFolder 计划中的一个目标文件夹
+type planFolder struct {
+	Name  string     `json:"name"`
+	Count int        `json:"count"`
+	Files []planFile `json:"files"`
+}
+
+// planResult classify_directory 的返回结果
+type planResult struct {
+	Source   string                   `json:"source"`
+	Target   string                   `json:"target"`
+	Total    int                      `json:"total"`
+	Folders  []planFolder             `json:"folders"`
+	Review   []planFile               `json:"review,omitempty"`
+	Executed bool                     `json:"executed"`
+	Result   *organizer.ExecuteResult `json:"result,omitempty"`
+}
+
+// classifyDirectory 分类目录，生成整理计划，execute 为 true 时执行
+func classifyDirectory(s *Server, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Dir       string `json:"dir"`
+		Target    string `json:"target"`
+		Recursive bool   `json:"recursive"`
+		Execute   bool   `json:"execute"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("参数错误: %v", err)
+	}
+
+	dir := guard.ExpandHome(strings.TrimSpace(args.Dir))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("目录不存在: %s", args.Dir)
+	}
+	dir, _ = filepath.Abs(dir)
+	target, err := organizer.ResolveTarget(strings.TrimSpace(args.Target), dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range []string{dir, target} {
+		if err := guard.Check(p); err != nil {
+			return nil, fmt.Errorf("拒绝整理: %v", err)
+		}
+	}
+
+	// 分类期间会写入学习记录，与命令行的 filo 互斥
+	l, err := lock.TryAcquire()
+	if err != nil {
+		return nil, fmt.Errorf("%v，请稍后再试", err)
+	}
+	defer l.Release()
+
+	files, err := scanner.ScanDirectory(dir, args.Recursive)
+	if err != nil {
+		return nil, fmt.Errorf("扫描失败: %v", err)
+	}
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		return nil, fmt.Errorf("初始化分类器失败: %v", err)
+	}
+	defer clf.Close()
+
+	results, err := clf.Classify(files, false)
+	if err != nil {
+		return nil, fmt.Errorf("分类失败: %v", err)
+	}
+	cfg := config.Get()
+	plan := organizer.GeneratePlan(results, target)
+	plan.SourceDir = dir
+	organizer.ParkForReview(plan, cfg.LowConfidenceAction, cfg.ConfidenceThresholdFor)
+
+	out := toPlanResult(plan)
+	if args.Execute && (plan.TotalFiles() > 0 || len(plan.Review) > 0) {
+		result := organizer.Execute(plan, clf, false)
+		out.Executed, out.Result = true, &result
+	}
+	return out, nil
+}
+
+// toPlanResult 转换整理计划，每个文件夹最多列出 maxFilesPerFolder 个文件
+func toPlanResult(plan *organizer.Plan) planResult {
+	out := planResult{
+		Source:  plan.SourceDir,
+		Target:  plan.TargetDir,
+		Total:   plan.TotalFiles(),
+		Folders: []planFolder{},
+	}
+	for name, files := range plan.Actions {
+		out.Folders = append(out.Folders, planFolder{Name: name, Count: len(files), Files: toPlanFiles(files)})
+	}
+	sort.Slice(out.Folders, func(i, j int) bool { return out.Folders[i].Name < out.Folders[j].Name })
+	out.Review = toPlanFiles(plan.Review)
+	return out
+}
+
+// toPlanFiles 转换分类结果列表
+func toPlanFiles(results []classifier.Result) []planFile {
+	var files []planFile
+	for i, r := range results {
+		if i >= maxFilesPerFolder {
+			break
+		}
+		files = append(files, planFile{
+			Name:        r.FileInfo.Name,
+			Category:    r.Category,
+			Subcategory: r.Subcategory,
+			Confidence:  r.Confidence,
+			Source:      r.Source,
+		})
+	}
+	return files
+}
+
+// ==================== find_file ====================
+
+// foundFile find_file 返回的单条记录
+type foundFile struct {
+	Filename     string `json:"filename"`
+	Path         string `json:"path"`          // 整理后的位置
+	Exists       bool   `json:"exists"`        // 文件是否仍在整理后的位置
+	OriginalPath string `json:"original_path"` // 整理前的位置
+	Category     string `json:"category"`
+	Subcategory  string `json:"subcategory,omitempty"`
+	BatchID      string `json:"batch_id"`
+	MovedAt      string `json:"moved_at"`
+}
+
+// findFile 按文件名查找整理过的文件
+func findFile(s *Server, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Name  string `json:"name"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("参数错误: %v", err)
+	}
+	if strings.TrimSpace(args.Name) == "" {
+		return nil, errors.New("缺少文件名")
+	}
+	if args.Limit <= 0 {
+		args.Limit = defaultFindLimit
+	}
+
+	logs, err := s.db.FindOperationLogs(strings.TrimSpace(args.Name), args.Limit)
+	if err != nil {
+		return nil, err
+	}
+	found := []foundFile{}
+	for _, log := range logs {
+		_, statErr := os.Stat(log.DestPath)
+		found = append(found, foundFile{
+			Filename:     log.Filename,
+			Path:         log.DestPath,
+			Exists:       statErr == nil,
+			OriginalPath: log.SourcePath,
+			Category:     log.Category,
+			Subcategory:  log.Subcategory,
+			BatchID:      log.BatchID,
+			MovedAt:      log.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return found, nil
+}
+
+// ==================== get_statistics ====================
+
+// getStatistics 学习统计、当前配置和最近的批次
+func getStatistics(s *Server, raw json.RawMessage) (interface{}, error) {
+	stats, err := s.db.GetStatistics()
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.Get()
+	stats["learning_enabled"] = cfg.EnableLearning
+	stats["model"] = cfg.ActiveModel()
+	stats["offline"] = cfg.Offline
+	stats["pending_reviews"] = s.db.CountPendingReviews()
+
+	batches, err := s.db.GetRecentBatches(recentBatches)
+	if err != nil {
+		return nil, err
+	}
+	if batches == nil {
+		batches = []map[string]interface{}{}
+	}
+	stats["recent_batches"] = batches
+	return stats, nil
+}
+
+// ==================== undo_batch ====================
+
+// undoBatch 撤销批次
+func undoBatch(s *Server, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		BatchID string `json:"batch_id"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("参数错误: %v", err)
+	}
+
+	l, err := lock.TryAcquire()
+	if err != nil {
+		return nil, fmt.Errorf("%v，请稍后再试", err)
+	}
+	defer l.Release()
+
+	batchID := strings.TrimSpace(args.BatchID)
+	if batchID == "" {
+		batchID = s.db.GetLatestBatch()
+		if batchID == "" {
+			return nil, errors.New("没有可撤销的操作")
+		}
+	}
+	logs, err := s.db.GetBatchLogs(batchID)
+	if err != nil || len(logs) == 0 {
+		return nil, fmt.Errorf("找不到批次 %s 的操作记录", batchID)
+	}
+
+	result := organizer.Undo(s.db, logs, batchID)
+	return map[string]interface{}{
+		"batch_id": batchID,
+		"success":  result.Success,
+		"errors":   result.Errors,
+		"details":  result.Details,
+	}, nil
+}
@@ -0,0 +1,118 @@
+// Package memory 记忆系统模块
+// cluster.go - 文件名聚类：把没有合适分类的文件按文件名向量的相似度分组，
+// 每组交给模型起一个分类名（filo suggest），让分类体系随用户实际的文件演进
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package memory
+
+import (
+	"sort"
+	"strings"
+)
+
+// ClusterKeywordShare 关键词至少出现在一组中该比例的文件名里，才作为这一组的共同关键词
+const ClusterKeywordShare = 0.5
+
+// Cluster 一组相似的文件
+type Cluster struct {
+	Files    []string // 文件名
+	Keywords []string // 组内多数文件名共有的关键词，按出现次数降序
+}
+
+// ClusterFiles 按文件名向量的相似度把文件分组
+// 依次把每个文件归入与其最相似的组（与组内向量均值比较），相似度都低于 similarity 时新建一组；
+// 文件数少于 minSize 的组不返回
+//
+// 参数:
+//   - filenames: 文件名
+//   - similarity: 归入同一组所需的最低相似度（0-1）
+//   - minSize: 一组至少包含的文件数
+//
+// 返回值:
+//   - []Cluster: 分组结果，文件多的组在前
+func (m *Memory) ClusterFiles(filenames []string, similarity float64, minSize int) []Cluster {
+	vecs := m.vectors(filenames)
+
+	type group struct {
+		sum   []float64 // 组内向量之和，与均值方向相同
+		files []string
+	}
+	var groups []*group
+	for i, vec := range vecs {
+		if len(vec) == 0 {
+			continue
+		}
+		var best *group
+		bestSim := similarity
+		for _, g := range groups {
+			if sim := m.embedder.Similarity(vec, g.sum); sim >= bestSim {
+				best, bestSim = g, sim
+			}
+		}
+		if best == nil {
+			best = &group{sum: make([]float64, len(vec))}
+			groups = append(groups, best)
+		}
+		for j := range vec {
+			best.sum[j] += vec[j]
+		}
+		best.files = append(best.files, filenames[i])
+	}
+
+	var clusters []Cluster
+	for _, g := range groups {
+		if len(g.files) >= minSize {
+			clusters = append(clusters, Cluster{Files: g.files, Keywords: m.sharedKeywords(g.files)})
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].Files) > len(clusters[j].Files)
+	})
+	return clusters
+}
+
+// sharedKeywords 组内多数文件名共有的关键词（不含纯数字，如年份和编号）
+func (m *Memory) sharedKeywords(files []string) []string {
+	counts := make(map[string]int)
+	for _, f := range files {
+		seen := make(map[string]bool)
+		for _, w := range m.extractKeywords(f) {
+			w = strings.ToLower(w)
+			if !seen[w] && strings.Trim(w, "0123456789") != "" {
+				seen[w] = true
+				counts[w]++
+			}
+		}
+	}
+
+	var keywords []string
+	for w, n := range counts {
+		if float64(n) >= ClusterKeywordShare*float64(len(files)) {
+			keywords = append(keywords, w)
+		}
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if counts[keywords[i]] != counts[keywords[j]] {
+			return counts[keywords[i]] > counts[keywords[j]]
+		}
+		return keywords[i] < keywords[j]
+	})
+	return keywords
+}
+
+// KeywordCount 关键词出现在多少个文件名中（与学习关键词规则时的提取方式相同，不区分大小写）
+func (m *Memory) KeywordCount(keyword string, files []string) int {
+	keyword = strings.ToLower(keyword)
+	n := 0
+	for _, f := range files {
+		for _, w := range m.extractKeywords(f) {
+			if strings.ToLower(w) == keyword {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
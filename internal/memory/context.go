@@ -0,0 +1,79 @@
+// Package memory 记忆系统模块
+// context.go - 时间上下文：文件修改时间在工作时间还是休息时间，
+// 该时间段中最常确认的主分类与记忆建议一致时给予少量加分（弱特征，不单独决定分类）
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"filo/internal/storage"
+)
+
+// 时间上下文参数
+const (
+	ContextBoost      = 0.05 // 加分上限
+	ContextMinSamples = 20   // 该时间段至少需要的已确认记录数
+	ContextMinShare   = 0.4  // 建议的主分类在该时间段中的最低占比
+)
+
+// contextStats 一个时间段的分类分布
+type contextStats struct {
+	top   string  // 最常见的主分类
+	share float64 // 最常见主分类的占比
+	total int     // 已确认记录数
+}
+
+// ContextPrior 按文件修改时间所在的时间段（工作时间 / 休息时间）给记忆建议加分
+// 该时间段已确认的记录足够多，且 category 是其中最常见、占比不低于 ContextMinShare 的主分类时，
+// 按占比给出不超过 ContextBoost 的加分
+//
+// 参数:
+//   - modified: 文件修改时间
+//   - category: 记忆建议的主分类
+//
+// 返回值:
+//   - float64: 加分，不适用时为 0
+//   - string: 加分理由
+func (m *Memory) ContextPrior(modified time.Time, category string) (float64, string) {
+	if modified.IsZero() {
+		return 0, ""
+	}
+	work := storage.IsWorkTime(int(modified.Weekday()), modified.Hour())
+	stats := m.contextStats(work)
+	if stats == nil || stats.total < ContextMinSamples || stats.top != category || stats.share < ContextMinShare {
+		return 0, ""
+	}
+
+	period := "休息时间"
+	if work {
+		period = "工作时间"
+	}
+	return ContextBoost * stats.share, fmt.Sprintf("%s的文件 %.0f%% 归入%s", period, stats.share*100, category)
+}
+
+// contextStats 返回时间段的分类分布，每次运行只查询一次
+func (m *Memory) contextStats(work bool) *contextStats {
+	if stats, ok := m.contexts[work]; ok {
+		return stats
+	}
+	if m.contexts == nil {
+		m.contexts = make(map[bool]*contextStats)
+	}
+
+	var stats *contextStats
+	if dist, total, err := m.db.ContextDistribution(work); err == nil && total > 0 {
+		stats = &contextStats{total: total}
+		for category, n := range dist {
+			if share := float64(n) / float64(total); share > stats.share || (share == stats.share && category < stats.top) {
+				stats.top, stats.share = category, share
+			}
+		}
+	}
+	m.contexts[work] = stats
+	return stats
+}
@@ -0,0 +1,169 @@
+// Package memory 记忆系统模块
+// ensemble.go - 记忆来源的加权综合打分
+//
+// Query 默认按规则 -> 向量 -> 历史的优先级取第一个达到阈值的结果，
+// 即使其他来源给出了截然不同的分类也不会察觉。综合打分把三种来源当作证据：
+// 给出相同分类的来源相互印证、置信度上调，相互矛盾时按各自的权重和置信度压低结果，
+// 交给 LLM 判断
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package memory
+
+import (
+	"fmt"
+	"strings"
+
+	"filo/internal/config"
+)
+
+// ==================== 常量定义 ====================
+
+// 记忆来源的取舍方式（memory_scoring）
+const (
+	ScoringFirst    = "first"    // 按优先级取第一个达到阈值的来源（默认）
+	ScoringEnsemble = "ensemble" // 加权综合所有来源
+)
+
+// MaxEnsembleConfidence 综合打分的置信度上限
+const MaxEnsembleConfidence = 0.98
+
+// ==================== 类型定义 ====================
+
+// Vote 单个记忆来源对综合结果的贡献
+type Vote struct {
+	Match  *Match  // 该来源的匹配结果
+	Weight float64 // 来源权重
+	Agree  float64 // 与综合结果的一致程度: 1 分类相同，0.5 仅主分类相同，0 不同
+}
+
+// Ensemble 综合打分结果
+type Ensemble struct {
+	Match *Match // 综合后的结果（Source 为 ensemble），没有任何来源匹配时为 nil
+	Votes []Vote // 参与打分的各来源
+}
+
+// ==================== 综合打分 ====================
+
+// Ensemble 综合规则、向量、历史三种来源的匹配结果
+// 结果不做阈值过滤，是否采用由调用方按主分类阈值判断
+//
+// 参数:
+//   - filename: 文件名
+//   - parentDir: 文件原始所在目录名
+//
+// 返回值:
+//   - *Ensemble: 综合结果和各来源的贡献
+func (m *Memory) Ensemble(filename, parentDir string) *Ensemble {
+	return combine(m.cfg.MemoryWeights,
+		m.matchRules(filename, parentDir),
+		m.matchVectors(filename),
+		m.matchHistory(filename))
+}
+
+// combine 按权重综合各来源的匹配结果
+// 每个来源给出的分类都是一个候选，候选的置信度为：
+//
+//	支持来源的加权平均置信度 × 支持度 + (完全一致的来源数 - 1) × 一致加分
+//
+// 支持度 = 支持证据 / (支持证据 + 反对证据)，证据 = 权重 × 置信度；
+// 只有主分类相同的来源一半算支持、一半算反对。取置信度最高的候选
+func combine(w config.MemoryWeights, rule, vector, history *Match) *Ensemble {
+	var votes []Vote
+	for _, s := range []struct {
+		match  *Match
+		weight float64
+	}{{rule, w.Rule}, {vector, w.Vector}, {history, w.History}} {
+		if s.match != nil && s.weight > 0 {
+			votes = append(votes, Vote{Match: s.match, Weight: s.weight})
+		}
+	}
+
+	result := &Ensemble{}
+	for _, cand := range votes {
+		var support, oppose, weight, weighted float64
+		agreeing := 0
+		candVotes := make([]Vote, len(votes))
+		for i, v := range votes {
+			v.Agree = agreement(cand.Match, v.Match)
+			evidence := v.Weight * v.Match.Confidence
+			support += evidence * v.Agree
+			oppose += evidence * (1 - v.Agree)
+			weight += v.Weight * v.Agree
+			weighted += evidence * v.Agree
+			if v.Agree == 1 {
+				agreeing++
+			}
+			candVotes[i] = v
+		}
+		if support == 0 {
+			continue
+		}
+
+		conf := weighted / weight * support / (support + oppose)
+		conf += float64(agreeing-1) * w.Agreement
+		if conf > MaxEnsembleConfidence {
+			conf = MaxEnsembleConfidence
+		}
+		if result.Match == nil || conf > result.Match.Confidence {
+			result.Match = &Match{
+				Category:    cand.Match.Category,
+				Subcategory: cand.Match.Subcategory,
+				Confidence:  conf,
+				Source:      "ensemble",
+			}
+			result.Votes = candVotes
+		}
+	}
+
+	if result.Match != nil {
+		result.Match.Reasoning = describeVotes(result.Votes)
+	}
+	return result
+}
+
+// agreement 两个匹配结果的一致程度
+func agreement(a, b *Match) float64 {
+	switch {
+	case a.Category != b.Category:
+		return 0
+	case a.Subcategory != b.Subcategory:
+		return 0.5
+	}
+	return 1
+}
+
+// describeVotes 生成综合结果的说明，列出支持和反对的来源
+// 如 "综合: 规则 95% ✓, 向量 88% ✓, 历史 40% ✗(文档/其他)"
+func describeVotes(votes []Vote) string {
+	parts := make([]string, 0, len(votes))
+	for _, v := range votes {
+		part := fmt.Sprintf("%s %.0f%% ", sourceName(v.Match.Source), v.Match.Confidence*100)
+		switch v.Agree {
+		case 1:
+			part += "✓"
+		case 0.5:
+			part += "≈(" + v.Match.Subcategory + ")"
+		default:
+			part += "✗(" + v.Match.Category + "/" + v.Match.Subcategory + ")"
+		}
+		parts = append(parts, part)
+	}
+	return "综合: " + strings.Join(parts, ", ")
+}
+
+// sourceName 记忆来源的中文名称
+func sourceName(source string) string {
+	switch source {
+	case "content":
+		return "内容"
+	case "rule":
+		return "规则"
+	case "vector":
+		return "向量"
+	case "history":
+		return "历史"
+	}
+	return source
+}
@@ -1,6 +1,6 @@
 // Package memory 记忆系统模块
 // 实现文件分类的学习和记忆功能
-// 支持规则匹配、向量匹配和历史匹配三种方式
+// 支持规则匹配、向量匹配和历史匹配三种方式，可按优先级取舍或加权综合
 //
 // Copyright (c) 2024-2026 lynx-lee
 // https://github.com/lynx-lee/filo
@@ -8,12 +8,16 @@
 package memory
 
 import (
+	"io"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"filo/internal/config"
 	"filo/internal/embedding"
+	"filo/internal/scanner"
 	"filo/internal/storage"
 )
 
@@ -22,6 +26,9 @@ import (
 const (
 	MaxVectorSearchLimit = 200 // 向量搜索最大数量
 	MinKeywordLength     = 2   // 关键词最小长度
+
+	ContentConfidence          = 0.92 // 内容相同的文件沿用之前 AI 分类结果的置信度
+	ConfirmedContentConfidence = 0.99 // 内容相同的文件沿用之前用户确认结果的置信度
 )
 
 // 预编译的正则表达式（性能优化）
@@ -32,6 +39,17 @@ var (
 	tokenRegex = regexp.MustCompile(`[\p{Han}]+|[a-zA-Z]+|\d+`)
 )
 
+// genericDirNames 不携带分类信息的通用目录名
+// 这些目录下的文件五花八门，学习它们只会产生噪音规则
+var genericDirNames = map[string]bool{
+	"downloads": true, "download": true, "下载": true,
+	"desktop": true, "桌面": true,
+	"documents": true, "文档": true,
+	"home": true, "users": true, "tmp": true, "temp": true,
+	"新建文件夹": true, "new folder": true, "untitled folder": true,
+	"已整理": true, "organized": true, "待确认": true,
+}
+
 // ==================== 类型定义 ====================
 
 // Match 记忆匹配结果
@@ -40,16 +58,33 @@ type Match struct {
 	Category    string  // 主分类
 	Subcategory string  // 子分类
 	Confidence  float64 // 置信度（0-1）
-	Source      string  // 来源: rule（规则）, vector（向量）, history（历史）
+	Source      string  // 来源: content（内容）, rule（规则）, vector（向量）, history（历史）
 	Reasoning   string  // 匹配理由
 }
 
+// StageTiming 记忆查询各阶段的累计耗时
+type StageTiming struct {
+	Rules   time.Duration // 规则匹配
+	Vectors time.Duration // 向量匹配
+	History time.Duration // 历史匹配
+}
+
+// Total 各阶段耗时之和
+func (t StageTiming) Total() time.Duration {
+	return t.Rules + t.Vectors + t.History
+}
+
 // Memory 记忆系统
 // 管理分类的学习和查询
 type Memory struct {
 	db       *storage.Database   // 数据库连接
 	embedder embedding.Embedder  // 向量嵌入器
 	cfg      *config.Config      // 配置
+	timing   StageTiming         // 查询耗时统计
+	vecCache map[string][]float64 // 文件名的向量缓存（Prefetch 批量生成）
+	contexts map[bool]*contextStats // 工作时间 / 休息时间的分类分布（首次使用时查询）
+	stopWords map[string]bool        // 提取关键词时跳过的停用词
+	fast      bool                   // 快速模式：只做内容和规则匹配
 }
 
 // ==================== 构造函数 ====================
@@ -62,57 +97,197 @@ func NewMemory() (*Memory, error) {
 		return nil, err
 	}
 
+	cfg := config.Get()
 	return &Memory{
-		db:       db,
-		embedder: embedding.NewEmbedder(),
-		cfg:      config.Get(),
+		db:        db,
+		embedder:  embedding.NewEmbedder(),
+		cfg:       cfg,
+		vecCache:  make(map[string][]float64),
+		stopWords: StopWords(cfg.StopWords),
 	}, nil
 }
 
 // Close 关闭记忆系统
 // 释放数据库连接
 func (m *Memory) Close() error {
+	if c, ok := m.embedder.(io.Closer); ok {
+		c.Close() // 结束外部程序嵌入器的进程
+	}
 	return m.db.Close()
 }
 
 // ==================== 查询方法 ====================
 
+// Timing 获取记忆查询各阶段的累计耗时
+func (m *Memory) Timing() StageTiming {
+	return m.timing
+}
+
+// track 累计某一阶段的耗时，配合 defer 使用
+func track(d *time.Duration, start time.Time) {
+	*d += time.Since(start)
+}
+
+// SetFast 开启或关闭快速模式
+// 快速模式下 Query 和 BestGuess 只做内容和规则匹配，不做向量和历史匹配（文件很多时这两步最慢）
+func (m *Memory) SetFast(on bool) {
+	m.fast = on
+}
+
+// Prefetch 批量生成文件名的向量并缓存，之后的查询不再逐个生成
+// Ollama 嵌入器一次请求可以向量化几十个文件名，比逐个请求快得多
+func (m *Memory) Prefetch(filenames []string) {
+	defer track(&m.timing.Vectors, time.Now())
+	m.vectors(filenames)
+}
+
+// vectors 获取多个文件名的向量，未缓存的批量生成后加入缓存
+func (m *Memory) vectors(filenames []string) [][]float64 {
+	var pending []string
+	seen := make(map[string]bool)
+	for _, name := range filenames {
+		if _, ok := m.vecCache[name]; !ok && !seen[name] {
+			seen[name] = true
+			pending = append(pending, name)
+		}
+	}
+	for i, vec := range m.embedder.EmbedBatch(pending) {
+		m.vecCache[pending[i]] = vec
+	}
+
+	vecs := make([][]float64, len(filenames))
+	for i, name := range filenames {
+		vecs[i] = m.vecCache[name]
+	}
+	return vecs
+}
+
+// vector 获取文件名的向量（优先使用缓存）
+func (m *Memory) vector(filename string) []float64 {
+	if vec, ok := m.vecCache[filename]; ok {
+		return vec
+	}
+	return m.embedder.Embed(filename)
+}
+
+// space 返回向量所属的空间，嵌入服务不可用时回退生成的向量属于本地嵌入器
+func (m *Memory) space(vec []float64) storage.VectorSpace {
+	return storage.VectorSpace{Embedder: embedding.NameOf(m.embedder, vec), Dim: len(vec)}
+}
+
 // Query 查询文件的分类记忆
-// 按优先级依次尝试: 规则匹配 -> 向量匹配 -> 历史匹配
+// 按优先级依次尝试: 内容匹配 -> 规则匹配 -> 向量匹配 -> 历史匹配
+// memory_scoring 为 ensemble 时内容未命中则加权综合其余三种来源（见 Ensemble）；
+// 快速模式下只做内容和规则匹配
+// parentDir 为文件原始所在目录名，参与规则匹配；contentHash 为文件内容的快速哈希，可为空
+// 阈值按匹配结果的主分类取值（category_thresholds 可覆盖全局阈值）
 // 返回置信度最高的匹配结果，如果都不满足阈值则返回 nil
-func (m *Memory) Query(filename string) *Match {
-	// 1. 规则匹配（最快，优先级最高）
-	if match := m.matchRules(filename); match != nil {
-		if match.Confidence >= m.cfg.SimilarityThreshold {
+func (m *Memory) Query(filename, parentDir, contentHash string) *Match {
+	// 0. 内容匹配（同一个文件改过名也能认出来）
+	if match := m.matchContent(contentHash); m.accepts(match) {
+		return match
+	}
+
+	if m.fast {
+		if match := m.matchRules(filename, parentDir); m.accepts(match) {
 			return match
 		}
+		return nil
 	}
 
-	// 2. 向量匹配（语义相似度）
-	if match := m.matchVectors(filename); match != nil {
-		if match.Confidence >= m.cfg.SimilarityThreshold {
+	if m.cfg.MemoryScoring == ScoringEnsemble {
+		if match := m.Ensemble(filename, parentDir).Match; m.accepts(match) {
 			return match
 		}
+		return nil
+	}
+
+	// 1. 规则匹配（最快，优先级最高）
+	if match := m.matchRules(filename, parentDir); m.accepts(match) {
+		return match
+	}
+
+	// 2. 向量匹配（语义相似度）
+	if match := m.matchVectors(filename); m.accepts(match) {
+		return match
 	}
 
 	// 3. 历史匹配（关键词匹配）
-	if match := m.matchHistory(filename); match != nil {
-		if match.Confidence >= m.cfg.SimilarityThreshold {
-			return match
-		}
+	if match := m.matchHistory(filename); m.accepts(match) {
+		return match
 	}
 
 	return nil // 无匹配结果
 }
 
+// accepts 匹配结果是否达到其主分类的相似度阈值
+func (m *Memory) accepts(match *Match) bool {
+	return match != nil && match.Confidence >= m.cfg.SimilarityThresholdFor(match.Category)
+}
+
+// BestGuess 返回置信度最高的记忆匹配，不受相似度阈值限制
+// 用于离线模式：没有 LLM 兜底时，低置信度的记忆也比没有强；快速模式下只做内容和规则匹配
+func (m *Memory) BestGuess(filename, parentDir, contentHash string) *Match {
+	if match := m.matchContent(contentHash); match != nil {
+		return match
+	}
+	if m.fast {
+		return m.matchRules(filename, parentDir)
+	}
+	if m.cfg.MemoryScoring == ScoringEnsemble {
+		return m.Ensemble(filename, parentDir).Match
+	}
+
+	var best *Match
+	for _, match := range []*Match{
+		m.matchRules(filename, parentDir),
+		m.matchVectors(filename),
+		m.matchHistory(filename),
+	} {
+		if match != nil && (best == nil || match.Confidence > best.Confidence) {
+			best = match
+		}
+	}
+	return best
+}
+
+// RuleOnly 仅规则模式的匹配：只用正则、通配符、关键词和扩展名规则，不做向量和历史匹配
+// 规则的选择和置信度都不依赖命中次数，规则库不变时结果可以复现
+// 没有匹配的规则时返回 nil
+func (m *Memory) RuleOnly(filename string) *Match {
+	defer track(&m.timing.Rules, time.Now())
+	rule, err := m.db.GetDeterministicRule(filename, filepath.Ext(filename), scanner.DetectLanguage(filename))
+	if err != nil || rule == nil {
+		return nil
+	}
+
+	// 固定置信度：用户手动创建的规则最高，扩展名最低
+	conf := 0.75
+	switch {
+	case storage.IsPatternRule(rule.PatternType):
+		conf = 0.95
+	case rule.PatternType == "keyword", rule.PatternType == storage.PatternLanguage:
+		conf = 0.85
+	}
+
+	return &Match{
+		Category:    rule.Category,
+		Subcategory: rule.Subcategory,
+		Confidence:  conf,
+		Source:      "rule",
+		Reasoning:   "匹配规则: " + rule.PatternType + "「" + rule.Pattern + "」",
+	}
+}
+
 // matchRules 规则匹配
-// 根据已学习的规则（关键词、扩展名）进行匹配
-func (m *Memory) matchRules(filename string) *Match {
-	keywords := extractKeywords(filename)
+// 根据已学习的规则（来源目录、关键词、扩展名）和手动添加的规则（正则、通配符、语言）进行匹配
+func (m *Memory) matchRules(filename, parentDir string) *Match {
+	defer track(&m.timing.Rules, time.Now())
+	keywords := m.extractKeywords(filename)
 	ext := strings.ToLower(filepath.Ext(filename))
 
 	// 从数据库获取匹配的规则
-	rules, err := m.db.GetMatchingRules(filename, keywords, ext)
+	rules, err := m.db.GetMatchingRules(filename, keywords, ext, normalizeParentDir(parentDir), scanner.DetectLanguage(filename))
 	if err != nil || len(rules) == 0 {
 		return nil
 	}
@@ -121,6 +296,17 @@ func (m *Memory) matchRules(filename string) *Match {
 
 	// 计算置信度：基础分 + 命中次数加成
 	conf := 0.6 + float64(best.HitCount)/50.0*0.35
+	switch {
+	case storage.IsPatternRule(best.PatternType):
+		// 正则和通配符规则由用户手动创建，直接给予最高置信度
+		conf = 0.95
+	case best.PatternType == storage.PatternLanguage:
+		// 语言规则同样由用户手动创建，但比具体的文件名模式宽泛
+		conf = 0.9
+	case best.PatternType == "parent_dir":
+		// 来源目录是强先验：用户手动建立的目录通常已表明了用途
+		conf = 0.8 + float64(best.HitCount)/20.0*0.15
+	}
 	if conf > 0.95 {
 		conf = 0.95 // 上限 95%
 	}
@@ -138,11 +324,13 @@ func (m *Memory) matchRules(filename string) *Match {
 // 通过向量相似度查找相似文件的分类
 // 优化：使用分类预过滤减少比对数量
 func (m *Memory) matchVectors(filename string) *Match {
+	defer track(&m.timing.Vectors, time.Now())
 	// 生成查询向量
-	queryVec := m.embedder.Embed(filename)
+	queryVec := m.vector(filename)
+	space := m.space(queryVec)
 
 	// 提取关键词和扩展名，用于预过滤
-	keywords := extractKeywords(filename)
+	keywords := m.extractKeywords(filename)
 	ext := strings.ToLower(filepath.Ext(filename))
 
 	// 获取候选分类（优化：预过滤）
@@ -153,10 +341,10 @@ func (m *Memory) matchVectors(filename string) *Match {
 	var err error
 	if len(candidateCategories) > 0 {
 		// 有候选分类时，只搜索相关分类的向量
-		vectors, err = m.db.SearchVectorsByCategories(candidateCategories, MaxVectorSearchLimit)
+		vectors, err = m.db.SearchVectorsByCategories(space, candidateCategories, MaxVectorSearchLimit)
 	} else {
 		// 无候选分类时，按扩展名搜索
-		vectors, err = m.db.SearchVectorsByExtension(ext, MaxVectorSearchLimit)
+		vectors, err = m.db.SearchVectorsByExtension(space, ext, MaxVectorSearchLimit)
 	}
 
 	if err != nil || len(vectors) == 0 {
@@ -181,8 +369,7 @@ func (m *Memory) matchVectors(filename string) *Match {
 		}
 	}
 
-	// 检查是否达到阈值
-	if best.Similarity < m.cfg.SimilarityThreshold {
+	if best.Filename == "" {
 		return nil
 	}
 
@@ -195,10 +382,31 @@ func (m *Memory) matchVectors(filename string) *Match {
 	}
 }
 
+// matchContent 内容匹配
+// 按内容哈希查找同一个文件之前的分类，与文件名无关；用户确认过的记录几乎可以确定
+func (m *Memory) matchContent(contentHash string) *Match {
+	record := m.db.FindClassificationByHash(contentHash)
+	if record == nil {
+		return nil
+	}
+	conf := ContentConfidence
+	if record.UserConfirmed {
+		conf = ConfirmedContentConfidence
+	}
+	return &Match{
+		Category:    record.Category,
+		Subcategory: record.Subcategory,
+		Confidence:  conf,
+		Source:      "content",
+		Reasoning:   "内容相同: " + record.Filename,
+	}
+}
+
 // matchHistory 历史匹配
 // 根据关键词在历史分类记录中查找
 func (m *Memory) matchHistory(filename string) *Match {
-	keywords := extractKeywords(filename)
+	defer track(&m.timing.History, time.Now())
+	keywords := m.extractKeywords(filename)
 	if len(keywords) == 0 {
 		return nil
 	}
@@ -222,65 +430,252 @@ func (m *Memory) matchHistory(filename string) *Match {
 	}
 }
 
+// Explanation 记忆查询的完整解释
+// 记录每种匹配方式的结果（不做阈值过滤），用于调试分类原因
+type Explanation struct {
+	Keywords  []string  // 提取的关键词
+	Threshold float64   // 全局相似度阈值（各主分类可单独覆盖）
+	Content   *Match    // 内容匹配结果（同一个文件之前的分类）
+	Rule      *Match    // 规则匹配结果
+	Vector    *Match    // 向量匹配结果
+	History   *Match    // 历史匹配结果
+	Ensemble  *Ensemble // 加权综合结果（无论 memory_scoring 是否为 ensemble 都会计算）
+	Final     *Match    // Query 的最终结果（nil 表示记忆未命中）
+}
+
+// Explain 解释文件的记忆查询过程
+// 依次执行内容、规则、向量、历史四种匹配并返回各自得分和综合结果
+func (m *Memory) Explain(filename, parentDir, contentHash string) *Explanation {
+	exp := &Explanation{
+		Keywords:  m.extractKeywords(filename),
+		Threshold: m.cfg.SimilarityThreshold,
+		Content:   m.matchContent(contentHash),
+		Rule:      m.matchRules(filename, parentDir),
+		Vector:    m.matchVectors(filename),
+		History:   m.matchHistory(filename),
+		Final:     m.Query(filename, parentDir, contentHash),
+	}
+	exp.Ensemble = combine(m.cfg.MemoryWeights, exp.Rule, exp.Vector, exp.History)
+	return exp
+}
+
+// Suggestion 修改分类时的候选分类
+type Suggestion struct {
+	Category    string  // 主分类
+	Subcategory string  // 子分类
+	Similarity  float64 // 该分类下最相似文件的相似度（没有向量记录时为 0）
+	Count       int     // 历史记录中的使用次数
+}
+
+// SuggestCategories 列出历史记录中已有的分类，按与文件名的向量相似度排序
+// 相似度相同（包括都没有向量记录）时按使用次数排序
+//
+// 参数:
+//   - filename: 文件名
+//   - limit: 返回结果的最大数量
+//
+// 返回值:
+//   - []Suggestion: 候选分类，历史为空时为 nil
+func (m *Memory) SuggestCategories(filename string, limit int) []Suggestion {
+	pairs := m.db.GetCategoryPairs(MaxVectorSearchLimit)
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	// 每个分类取其中最相似文件的相似度
+	best := make(map[[2]string]float64)
+	queryVec := m.vector(filename)
+	space := m.space(queryVec)
+	vectors, _ := m.db.SearchVectors(space, MaxVectorSearchLimit)
+	for i := range vectors {
+		vectors[i].Similarity = m.embedder.Similarity(queryVec, vectors[i].Vector)
+	}
+	for _, v := range vectors {
+		key := [2]string{v.Category, v.Subcategory}
+		if v.Similarity > best[key] {
+			best[key] = v.Similarity
+		}
+	}
+
+	suggestions := make([]Suggestion, len(pairs))
+	for i, p := range pairs {
+		suggestions[i] = Suggestion{
+			Category:    p.Category,
+			Subcategory: p.Subcategory,
+			Similarity:  best[[2]string{p.Category, p.Subcategory}],
+			Count:       p.Count,
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Similarity > suggestions[j].Similarity
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
 // ==================== 学习方法 ====================
 
 // Learn 从分类结果学习
 // 将分类结果存入历史记录和向量库，用户确认时还会生成规则
-func (m *Memory) Learn(filename, category, subcategory, source string, confidence float64, userConfirmed bool) error {
+// parentDir 为文件原始所在目录名，作为额外的学习特征；contentHash 为文件内容的快速哈希，可为空
+func (m *Memory) Learn(filename, parentDir, contentHash, category, subcategory, source string, confidence float64, userConfirmed bool) error {
 	ext := strings.ToLower(filepath.Ext(filename))
-	keywords := extractKeywords(filename)
+	keywords := m.extractKeywords(filename)
+	parentDir = normalizeParentDir(parentDir)
 
 	// 添加到历史记录
-	if _, err := m.db.AddClassification(filename, ext, category, subcategory, source, confidence, keywords, userConfirmed); err != nil {
+	if _, err := m.db.AddClassification(filename, ext, parentDir, category, subcategory, source, confidence, keywords, userConfirmed, contentHash); err != nil {
 		return err
 	}
 
 	// 添加到向量库
-	vec := m.embedder.Embed(filename)
-	if err := m.db.SaveVector(filename, category, subcategory, vec); err != nil {
+	vec := m.vector(filename)
+	if err := m.db.SaveVector(filename, category, subcategory, vec, embedding.NameOf(m.embedder, vec), contentHash); err != nil {
 		return err
 	}
 
-	// 用户确认时，学习规则
+	// 用户确认时，学习规则（样本数足够后生效）
 	if userConfirmed {
-		m.learnRules(filename, category, subcategory)
+		m.learnRules(filename, parentDir, category, subcategory)
 	}
 
 	return nil
 }
 
+// LearnItem 批量学习的分类结果
+type LearnItem struct {
+	Filename    string  // 文件名
+	ParentDir   string  // 原始所在目录名
+	ContentHash string  // 文件内容的快速哈希
+	Category    string  // 主分类
+	Subcategory string  // 子分类
+	Source      string  // 分类来源
+	Confidence  float64 // 置信度
+	Confirmed   bool    // 是否用户确认
+
+	Modified time.Time // 文件修改时间（记录星期和小时）
+	Burst    string    // 所属的批量下载，不属于任何一批时为空
+}
+
+// LearnBatch 批量学习分类结果
+// 与逐个调用 Learn 的效果相同，但历史、向量和规则各自在一个事务中写入
+func (m *Memory) LearnBatch(items []LearnItem) error {
+	history := make([]storage.ClassificationInput, 0, len(items))
+	vectors := make([]storage.VectorInput, 0, len(items))
+	var rules []storage.RuleInput
+
+	names := make([]string, len(items))
+	for i, it := range items {
+		names[i] = it.Filename
+	}
+	vecs := m.vectors(names)
+
+	for i, it := range items {
+		parentDir := normalizeParentDir(it.ParentDir)
+		history = append(history, storage.ClassificationInput{
+			Filename:    it.Filename,
+			Extension:   strings.ToLower(filepath.Ext(it.Filename)),
+			ParentDir:   parentDir,
+			Category:    it.Category,
+			Subcategory: it.Subcategory,
+			Source:      it.Source,
+			Confidence:  it.Confidence,
+			Keywords:    m.extractKeywords(it.Filename),
+			Confirmed:   it.Confirmed,
+			ContentHash: it.ContentHash,
+			Modified:    it.Modified,
+			Burst:       it.Burst,
+		})
+		vectors = append(vectors, storage.VectorInput{
+			Filename:    it.Filename,
+			Category:    it.Category,
+			Subcategory: it.Subcategory,
+			Vector:      vecs[i],
+			Embedder:    embedding.NameOf(m.embedder, vecs[i]),
+			ContentHash: it.ContentHash,
+		})
+		if it.Confirmed {
+			rules = append(rules, m.ruleInputs(it.Filename, parentDir, it.Category, it.Subcategory)...)
+		}
+	}
+
+	if err := m.db.AddClassifications(history); err != nil {
+		return err
+	}
+	if err := m.db.SaveVectors(vectors); err != nil {
+		return err
+	}
+	_, err := m.db.LearnRules(rules, m.cfg.MinSamplesForRule)
+	return err
+}
+
 // learnRules 从文件名学习规则
-// 提取扩展名和关键词，生成分类规则
-func (m *Memory) learnRules(filename, category, subcategory string) {
+// 提取扩展名、关键词和来源目录，生成候选规则，同一模式连续确认 min_samples_for_rule 次后生效
+func (m *Memory) learnRules(filename, parentDir, category, subcategory string) {
+	m.db.LearnRules(m.ruleInputs(filename, parentDir, category, subcategory), m.cfg.MinSamplesForRule)
+}
+
+// ruleInputs 从文件名提取待学习的规则
+func (m *Memory) ruleInputs(filename, parentDir, category, subcategory string) []storage.RuleInput {
 	ext := strings.ToLower(filepath.Ext(filename))
-	keywords := extractKeywords(filename)
+	keywords := m.extractKeywords(filename)
+	var rules []storage.RuleInput
+
+	// 学习来源目录规则（优先级介于关键词和纠正之间）
+	if parentDir != "" {
+		rules = append(rules, storage.RuleInput{Pattern: parentDir, PatternType: "parent_dir", Category: category, Subcategory: subcategory, Priority: 15})
+	}
 
 	// 学习扩展名规则（优先级较低）
 	if ext != "" {
-		m.db.AddOrUpdateRule(ext, "extension", category, subcategory, 5)
+		rules = append(rules, storage.RuleInput{Pattern: ext, PatternType: "extension", Category: category, Subcategory: subcategory, Priority: 5})
 	}
 
 	// 学习关键词规则（优先级较高）
 	for _, kw := range keywords {
 		if len(kw) >= MinKeywordLength {
-			m.db.AddOrUpdateRule(strings.ToLower(kw), "keyword", category, subcategory, 10)
+			rules = append(rules, storage.RuleInput{Pattern: strings.ToLower(kw), PatternType: "keyword", Category: category, Subcategory: subcategory, Priority: 10})
 		}
 	}
+	return rules
 }
 
 // LearnFromCorrection 从用户纠正中学习
-// 当用户修改分类时调用，生成高优先级规则
-func (m *Memory) LearnFromCorrection(filename, origCat, corrCat, origSub, corrSub string) error {
+// 当用户修改分类时调用，生成高优先级的候选规则，样本数达到 min_samples_for_rule 后生效
+// contentHash 不为空时记录一条已确认的分类，同一个文件改名后按纠正结果分类；
+// 把文件分到原分类的规则记一次负反馈，多次被纠正的规则会被降级或停用
+func (m *Memory) LearnFromCorrection(filename, parentDir, contentHash, origCat, corrCat, origSub, corrSub string) error {
 	// 记录用户反馈
 	m.db.AddFeedback(filename, origCat, corrCat, origSub, corrSub)
+	if origCat != corrCat || origSub != corrSub {
+		m.db.PenalizeRules([]storage.RuleFeedback{{
+			Filename:    filename,
+			ParentDir:   normalizeParentDir(parentDir),
+			Language:    scanner.DetectLanguage(filename),
+			Category:    origCat,
+			Subcategory: origSub,
+		}}, storage.FeedbackCorrection)
+	}
 
-	// 高优先级学习（用户纠正的权重更高）
-	keywords := extractKeywords(filename)
-	for _, kw := range keywords {
+	if contentHash != "" {
+		ext := strings.ToLower(filepath.Ext(filename))
+		m.db.AddClassification(filename, ext, normalizeParentDir(parentDir), corrCat, corrSub, "user", 1.0, m.extractKeywords(filename), true, contentHash)
+	}
+
+	// 来源目录和关键词按纠正结果学习（用户纠正的优先级更高），但先记为候选：
+	// 一次误操作的纠正就生效会覆盖之后所有同目录、同关键词文件的分类，与其他规则一样累计到 min_samples_for_rule 才生效
+	var rules []storage.RuleInput
+	if dir := normalizeParentDir(parentDir); dir != "" {
+		rules = append(rules, storage.RuleInput{Pattern: dir, PatternType: "parent_dir", Category: corrCat, Subcategory: corrSub, Priority: 20})
+	}
+	for _, kw := range m.extractKeywords(filename) {
 		if len(kw) >= MinKeywordLength {
-			m.db.AddOrUpdateRule(strings.ToLower(kw), "keyword", corrCat, corrSub, 20) // 优先级20
+			rules = append(rules, storage.RuleInput{Pattern: strings.ToLower(kw), PatternType: "keyword", Category: corrCat, Subcategory: corrSub, Priority: 20})
 		}
 	}
+	m.db.LearnRules(rules, m.cfg.MinSamplesForRule)
 
 	return nil
 }
@@ -320,12 +715,22 @@ func (m *Memory) GetStatistics() (map[string]interface{}, error) {
 
 // ==================== 辅助函数 ====================
 
-// extractKeywords 从文件名提取关键词
-// 提取中文词、英文词（至少2字符）、数字（至少4位）
+// extractKeywords 从文件名提取关键词（不过滤停用词，见 Memory.extractKeywords）
+// 提取中文词（连续汉字经过分词）、英文词（至少2字符）、数字（至少4位）
 // 使用预编译的正则表达式提升性能
 func extractKeywords(filename string) []string {
 	name := strings.TrimSuffix(filename, filepath.Ext(filename)) // 去除扩展名
-	return keywordRegex.FindAllString(name, -1)
+	return splitWords(keywordRegex, name)
+}
+
+// normalizeParentDir 规范化来源目录名
+// 转小写并过滤不携带分类信息的通用目录名（如 Downloads、桌面）
+func normalizeParentDir(dir string) string {
+	dir = strings.ToLower(strings.TrimSpace(dir))
+	if dir == "" || dir == "." || dir == string(filepath.Separator) || genericDirNames[dir] {
+		return ""
+	}
+	return dir
 }
 
 // filenameSimilarity 计算两个文件名的相似度
@@ -336,10 +741,10 @@ func filenameSimilarity(n1, n2 string) float64 {
 	w1 := make(map[string]bool)
 	w2 := make(map[string]bool)
 
-	for _, w := range tokenRegex.FindAllString(strings.ToLower(n1), -1) {
+	for _, w := range splitWords(tokenRegex, strings.ToLower(n1)) {
 		w1[w] = true
 	}
-	for _, w := range tokenRegex.FindAllString(strings.ToLower(n2), -1) {
+	for _, w := range splitWords(tokenRegex, strings.ToLower(n2)) {
 		w2[w] = true
 	}
 
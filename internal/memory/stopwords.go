@@ -0,0 +1,57 @@
+// Package memory 记忆系统模块
+// stopwords.go - 文件名停用词：「副本」「final」「新建」这类词出现在各种文件名中，
+// 学成关键词规则后几乎匹配所有文件。提取关键词时跳过内置停用词和配置 stop_words 中的词
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package memory
+
+import "strings"
+
+// defaultStopWords 内置停用词（小写）
+// 中文词按分词后的结果收录，如「无标题文档」切分为「标题」「文档」
+var defaultStopWords = []string{
+	// 副本、版本
+	"copy", "final", "draft", "version", "ver", "rev", "revised", "edited", "edit", "updated", "latest", "new", "old",
+	"backup", "bak", "副本", "复件", "拷贝", "最终", "最终版", "终版", "终稿", "定稿", "草稿",
+	"修改", "修改版", "修订", "修订版", "新版", "旧版", "版本", "备份",
+	// 默认文件名
+	"untitled", "unnamed", "file", "document", "download", "downloaded", "temp", "tmp",
+	"无标题", "标题", "未命名", "命名", "新建", "下载", "文件", "文档", "文本", "临时",
+	// 英文虚词
+	"the", "and", "of", "for", "with", "to", "in", "on", "my",
+}
+
+// StopWords 返回内置停用词与 extra 合并后的集合（小写）
+//
+// 参数:
+//   - extra: 配置中追加的停用词
+//
+// 返回值:
+//   - map[string]bool: 停用词集合
+func StopWords(extra []string) map[string]bool {
+	words := make(map[string]bool, len(defaultStopWords)+len(extra))
+	for _, w := range defaultStopWords {
+		words[w] = true
+	}
+	for _, w := range extra {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// extractKeywords 从文件名提取关键词，跳过停用词
+// 提取中文词（连续汉字经过分词）、英文词（至少2字符）、数字（至少4位）
+func (m *Memory) extractKeywords(filename string) []string {
+	words := extractKeywords(filename)
+	keywords := words[:0]
+	for _, w := range words {
+		if !m.stopWords[strings.ToLower(w)] {
+			keywords = append(keywords, w)
+		}
+	}
+	return keywords
+}
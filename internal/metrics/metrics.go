@@ -0,0 +1,281 @@
+// Package metrics 运行指标模块
+// metrics.go - 进程内的运行指标：扫描、分类、移动的文件数，各分类来源的文件数和记忆命中率，
+// 模型调用的耗时分布和各阶段的错误数。以 Prometheus 文本格式输出，
+// 常驻运行的 filo web、filo mcp 通过 /metrics 供 Prometheus 抓取，在 Grafana 中监控自动整理
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"filo/internal/config"
+)
+
+// ==================== 常量定义 ====================
+
+// 出错的阶段（filo_errors_total 的 stage 标签）
+const (
+	StageScan = "scan" // 扫描目录失败
+	StageLLM  = "llm"  // 模型调用失败（超时、连接失败、返回无法解析）
+	StageMove = "move" // 文件移动失败
+)
+
+// llmBuckets 模型调用耗时直方图的桶上限（秒），覆盖本地小模型到慢速远程模型
+var llmBuckets = []float64{0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120}
+
+// ==================== 类型定义 ====================
+
+// llmKey 模型调用按模型和调用类型分组
+type llmKey struct {
+	model string
+	kind  string
+}
+
+// histogram 累计直方图
+type histogram struct {
+	counts []uint64 // 与 llmBuckets 对应，落入各桶（不累计）的次数
+	sum    float64  // 耗时总和（秒）
+	count  uint64   // 总次数
+}
+
+// registry 进程内的全部指标
+type registry struct {
+	mu         sync.Mutex
+	start      time.Time
+	scanned    uint64
+	classified map[string]uint64 // 分类来源 -> 文件数
+	memoryHits uint64            // 记忆和规则命中的文件数
+	runs       uint64            // 分类运行次数
+	lastRatio  float64           // 最近一次分类的记忆命中率
+	moves      map[string]uint64 // 移动结果（success、failed、skipped）-> 文件数
+	errors     map[string]uint64 // 阶段 -> 错误数
+	llm        map[llmKey]*histogram
+}
+
+// std 进程内共享的指标
+var std = &registry{
+	start:      time.Now(),
+	classified: make(map[string]uint64),
+	moves:      make(map[string]uint64),
+	errors:     make(map[string]uint64),
+	llm:        make(map[llmKey]*histogram),
+}
+
+// ==================== 记录函数 ====================
+
+// AddScanned 记录扫描到的文件数
+func AddScanned(n int) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.scanned += uint64(n)
+}
+
+// ObserveClassification 记录一次分类运行
+//
+// 参数:
+//   - sources: 各分类来源（memory、rule、llm、extension 等）的文件数
+//   - memoryHits: 记忆和规则命中的文件数
+func ObserveClassification(sources map[string]int, memoryHits int) {
+	total := 0
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	for source, n := range sources {
+		std.classified[source] += uint64(n)
+		total += n
+	}
+	std.memoryHits += uint64(memoryHits)
+	std.runs++
+	if total > 0 {
+		std.lastRatio = float64(memoryHits) / float64(total)
+	}
+}
+
+// ObserveMove 记录一个文件的移动结果，失败时同时计入 move 阶段的错误
+//
+// 参数:
+//   - status: 操作日志的状态（success、failed、skipped）
+func ObserveMove(status string) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.moves[status]++
+	if status == "failed" {
+		std.errors[StageMove]++
+	}
+}
+
+// ObserveLLM 记录一次模型调用的耗时，失败时同时计入 llm 阶段的错误
+//
+// 参数:
+//   - model: 模型名称
+//   - kind: 调用类型（见 llm.CallClassify 等）
+//   - latency: 调用耗时（重试的每一次单独记录）
+//   - failed: 调用是否失败
+func ObserveLLM(model, kind string, latency time.Duration, failed bool) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	key := llmKey{model: model, kind: kind}
+	h := std.llm[key]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(llmBuckets))}
+		std.llm[key] = h
+	}
+	seconds := latency.Seconds()
+	for i, le := range llmBuckets {
+		if seconds <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+	if failed {
+		std.errors[StageLLM]++
+	}
+}
+
+// AddError 记录一个阶段的错误
+func AddError(stage string) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.errors[stage]++
+}
+
+// ==================== 输出 ====================
+
+// Write 以 Prometheus 文本格式（0.0.4）输出全部指标
+func Write(w io.Writer) error {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	var b strings.Builder
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("filo_build_info", "gauge", "运行中的 filo 版本")
+	fmt.Fprintf(&b, "filo_build_info{version=%s} 1\n", quote(config.Version))
+	metric("filo_start_time_seconds", "gauge", "进程启动时间（Unix 时间戳，秒）")
+	fmt.Fprintf(&b, "filo_start_time_seconds %d\n", std.start.Unix())
+
+	metric("filo_files_scanned_total", "counter", "扫描目录找到的文件数")
+	fmt.Fprintf(&b, "filo_files_scanned_total %d\n", std.scanned)
+
+	metric("filo_files_classified_total", "counter", "分类的文件数，按分类来源")
+	for _, source := range sortedKeys(std.classified) {
+		fmt.Fprintf(&b, "filo_files_classified_total{source=%s} %d\n", quote(source), std.classified[source])
+	}
+	metric("filo_classification_runs_total", "counter", "分类运行次数")
+	fmt.Fprintf(&b, "filo_classification_runs_total %d\n", std.runs)
+	metric("filo_memory_hits_total", "counter", "由学习记忆或规则分类、不调用模型的文件数")
+	fmt.Fprintf(&b, "filo_memory_hits_total %d\n", std.memoryHits)
+	metric("filo_memory_hit_ratio", "gauge", "最近一次分类的记忆命中率")
+	fmt.Fprintf(&b, "filo_memory_hit_ratio %g\n", std.lastRatio)
+
+	metric("filo_file_moves_total", "counter", "执行整理时处理的文件数，按结果（success、failed、skipped）")
+	for _, status := range sortedKeys(std.moves) {
+		fmt.Fprintf(&b, "filo_file_moves_total{status=%s} %d\n", quote(status), std.moves[status])
+	}
+
+	metric("filo_errors_total", "counter", "各阶段的错误数（scan、llm、move）")
+	for _, stage := range []string{StageScan, StageLLM, StageMove} {
+		fmt.Fprintf(&b, "filo_errors_total{stage=%s} %d\n", quote(stage), std.errors[stage])
+	}
+
+	metric("filo_llm_request_duration_seconds", "histogram", "模型调用耗时，按模型和调用类型")
+	keys := make([]llmKey, 0, len(std.llm))
+	for k := range std.llm {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].model != keys[j].model {
+			return keys[i].model < keys[j].model
+		}
+		return keys[i].kind < keys[j].kind
+	})
+	for _, k := range keys {
+		h := std.llm[k]
+		labels := fmt.Sprintf("model=%s,kind=%s", quote(k.model), quote(k.kind))
+		var cumulative uint64
+		for i, le := range llmBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "filo_llm_request_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, le, cumulative)
+		}
+		fmt.Fprintf(&b, "filo_llm_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(&b, "filo_llm_request_duration_seconds_sum{%s} %g\n", labels, h.sum)
+		fmt.Fprintf(&b, "filo_llm_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler 返回输出指标的 HTTP 处理器（只接受 GET）
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Write(w)
+	})
+}
+
+// ListenAndServe 在指定地址单独提供 /metrics，ctx 取消时关闭
+// 指标只有计数，不含文件名和路径，可以监听在局域网地址上供其他机器的 Prometheus 抓取
+//
+// 参数:
+//   - ctx: 取消时关闭服务
+//   - addr: 监听地址，如 :9464、192.168.1.10:9464
+//
+// 返回值:
+//   - error: 监听失败时返回错误
+func ListenAndServe(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ==================== 辅助函数 ====================
+
+// quote 按 Prometheus 标签值的规则加引号并转义
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// sortedKeys 按名称排序的键，输出顺序固定
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,198 @@
+// Package notify 运行结果通知模块
+// 无人值守运行结束后，通过桌面通知或 Webhook（Slack / Discord / ntfy）发送整理摘要
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"filo/internal/config"
+)
+
+// webhookTimeout Webhook 请求超时
+const webhookTimeout = 10 * time.Second
+
+// Summary 一次整理的结果摘要
+type Summary struct {
+	Dir     string // 整理的目录
+	Success int    // 成功移动的文件数
+	Errors  int    // 移动失败的文件数
+	Skipped int    // 因重名跳过的文件数
+	Review  int    // 进入待确认队列的文件数
+	BatchID string // 批次 ID（用于撤销）
+	Err     error  // 整理未能完成时的错误
+}
+
+// Title 通知标题
+func (s Summary) Title() string {
+	if s.Err != nil {
+		return "Filo 整理失败"
+	}
+	return "Filo 整理完成"
+}
+
+// Message 通知正文
+func (s Summary) Message() string {
+	if s.Err != nil {
+		return fmt.Sprintf("%s: %v", s.Dir, s.Err)
+	}
+	parts := []string{fmt.Sprintf("已整理 %d 个文件", s.Success)}
+	if s.Errors > 0 {
+		parts = append(parts, fmt.Sprintf("失败 %d 个", s.Errors))
+	}
+	if s.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d 个重名跳过", s.Skipped))
+	}
+	if s.Review > 0 {
+		parts = append(parts, fmt.Sprintf("%d 个待确认（filo review）", s.Review))
+	}
+	msg := s.Dir + ": " + strings.Join(parts, "，")
+	if s.BatchID != "" {
+		msg += "\n批次 " + s.BatchID + "（filo undo 可撤销）"
+	}
+	return msg
+}
+
+// Enabled 是否配置了任一通知方式
+func Enabled() bool {
+	cfg := config.Get()
+	return cfg.NotifyDesktop || cfg.NotifyWebhook != ""
+}
+
+// Send 按配置发送通知
+// 桌面通知和 Webhook 相互独立，返回所有发送失败的错误
+func Send(s Summary) []error {
+	cfg := config.Get()
+	var errs []error
+	if cfg.NotifyDesktop {
+		if err := Desktop(s.Title(), s.Message()); err != nil {
+			errs = append(errs, fmt.Errorf("桌面通知失败: %w", err))
+		}
+	}
+	if cfg.NotifyWebhook != "" {
+		if err := Webhook(cfg.NotifyWebhook, s); err != nil {
+			errs = append(errs, fmt.Errorf("Webhook 通知失败: %w", err))
+		}
+	}
+	return errs
+}
+
+// ==================== 桌面通知 ====================
+
+// Desktop 发送系统桌面通知
+// macOS 使用 osascript，Linux 使用 notify-send，Windows 使用 PowerShell 调用系统通知
+func Desktop(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToastScript(title, message))
+	default:
+		cmd = exec.Command("notify-send", "--app-name=filo", title, message)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// appleScriptString 转义为 AppleScript 字符串字面量
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// windowsToastScript 生成显示 Windows 通知的 PowerShell 脚本
+// 借用 PowerShell 自身的 AppUserModelID，无需注册应用
+func windowsToastScript(title, message string) string {
+	quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+	return `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$t = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$x = $t.GetElementsByTagName('text')
+$x.Item(0).AppendChild($t.CreateTextNode(` + quote(title) + `)) > $null
+$x.Item(1).AppendChild($t.CreateTextNode(` + quote(message) + `)) > $null
+$id = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($id).Show([Windows.UI.Notifications.ToastNotification]::new($t))`
+}
+
+// ==================== Webhook ====================
+
+// Webhook 向 Webhook 地址发送摘要
+// 根据地址识别 Slack、Discord 和 ntfy 的消息格式，其他地址发送通用 JSON
+func Webhook(rawURL string, s Summary) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("无效的地址: %s", rawURL)
+	}
+
+	var req *http.Request
+	text := s.Title() + "\n" + s.Message()
+	switch {
+	case u.Host == "hooks.slack.com":
+		req, err = jsonRequest(rawURL, map[string]string{"text": text})
+	case strings.HasSuffix(u.Host, "discord.com") || strings.HasSuffix(u.Host, "discordapp.com"):
+		req, err = jsonRequest(rawURL, map[string]string{"content": text})
+	case strings.Contains(u.Host, "ntfy"):
+		// ntfy 以纯文本作为消息正文，标题放在请求头中
+		req, err = http.NewRequest(http.MethodPost, rawURL, strings.NewReader(s.Message()))
+		if err == nil {
+			req.Header.Set("Title", s.Title())
+			req.Header.Set("Tags", "file_folder")
+		}
+	default:
+		req, err = jsonRequest(rawURL, map[string]interface{}{
+			"title":    s.Title(),
+			"text":     s.Message(),
+			"dir":      s.Dir,
+			"success":  s.Success,
+			"errors":   s.Errors,
+			"skipped":  s.Skipped,
+			"review":   s.Review,
+			"batch_id": s.BatchID,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := (&http.Client{Timeout: webhookTimeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("服务端返回 %s", resp.Status)
+	}
+	return nil
+}
+
+// jsonRequest 创建 JSON 请求
+func jsonRequest(rawURL string, body interface{}) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, rawURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
@@ -0,0 +1,208 @@
+// Package ocr 文字识别模块
+// 为扫描件和截图提取文字片段，辅助分类（如把「扫描件_001.pdf」归入合同或发票）
+// 支持两种引擎：tesseract 命令行，或通过 Ollama 调用本地多模态模型
+// PDF 需要 pdftoppm（poppler）把首页渲染为图片
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"filo/internal/config"
+	"filo/internal/llm"
+	"filo/internal/scanner"
+)
+
+// ==================== 常量定义 ====================
+
+// OCR 引擎
+const (
+	EngineTesseract = "tesseract" // tesseract 命令行
+	EngineVision    = "vision"    // Ollama 本地多模态模型
+)
+
+// 识别限制
+const (
+	MaxImageSize = 20 * 1024 * 1024 // 超过此大小的图片不识别
+	MaxTextRunes = 300              // 提供给分类器的文字片段长度
+	ocrTimeout   = 60 * time.Second // 单个文件的识别超时
+	pdfHeadSize  = 1024 * 1024      // 判断 PDF 是否有文字层时读取的字节数
+)
+
+// imageExts 可识别的图片扩展名
+var imageExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".bmp": true,
+	".tif": true, ".tiff": true, ".webp": true,
+}
+
+// scanNameRe 扫描件和截图的常见文件名
+var scanNameRe = regexp.MustCompile(`(?i)扫描|scan|截图|截屏|屏幕快照|screenshot|screen shot|^doc\d+`)
+
+// visionPrompt 多模态模型的文字识别提示词
+const visionPrompt = "识别图片中的所有文字，按原样输出文字本身，不要翻译、解释或补充。没有文字时输出空。"
+
+// 识别结果缓存（分类批次拆分重试时避免重复识别）
+var (
+	cache   = map[string]string{}
+	cacheMu sync.Mutex
+)
+
+// ==================== 判断函数 ====================
+
+// Enabled 是否启用了 OCR
+func Enabled() bool {
+	engine := config.Get().OCR
+	return engine == EngineTesseract || engine == EngineVision
+}
+
+// Check 检查当前引擎所需的程序或模型是否可用
+func Check() error {
+	cfg := config.Get()
+	switch cfg.OCR {
+	case EngineTesseract:
+		if _, err := exec.LookPath("tesseract"); err != nil {
+			return fmt.Errorf("未找到 tesseract，请先安装（macOS: brew install tesseract tesseract-lang）")
+		}
+	case EngineVision:
+		if !llm.NewClient().HasLocalModel(cfg.OCRModel) {
+			return fmt.Errorf("多模态模型 %s 未安装，请运行: ollama pull %s", cfg.OCRModel, cfg.OCRModel)
+		}
+	default:
+		return fmt.Errorf("未知的 OCR 引擎: %s（可选 tesseract / vision）", cfg.OCR)
+	}
+	return nil
+}
+
+// IsScan 判断文件是否为需要识别文字的扫描件或截图
+// 图片按文件名判断；PDF 按文件名或没有文字层判断
+func IsScan(f scanner.FileInfo) bool {
+	if f.Size == 0 || f.Size > MaxImageSize || f.Cloud != "" {
+		return false
+	}
+	if imageExts[f.Extension] {
+		return scanNameRe.MatchString(f.Name)
+	}
+	if f.Extension == ".pdf" {
+		return scanNameRe.MatchString(f.Name) || !hasTextLayer(f.Path)
+	}
+	return false
+}
+
+// hasTextLayer 判断 PDF 是否包含文字层
+// 扫描得到的 PDF 只有图片，不引用字体；使用对象流压缩的 PDF 无法直接判断，视为有文字层
+func hasTextLayer(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer file.Close()
+
+	head, _ := io.ReadAll(io.LimitReader(file, pdfHeadSize))
+	return bytes.Contains(head, []byte("/Font")) || bytes.Contains(head, []byte("/ObjStm"))
+}
+
+// ==================== 识别函数 ====================
+
+// Extract 识别文件中的文字，返回压缩空白后的片段
+// 结果按路径缓存，同一文件只识别一次
+func Extract(f scanner.FileInfo) (string, error) {
+	cacheMu.Lock()
+	text, ok := cache[f.Path]
+	cacheMu.Unlock()
+	if ok {
+		return text, nil
+	}
+
+	img, err := loadImage(f)
+	if err != nil {
+		return "", err
+	}
+	if text, err = Recognize(img); err != nil {
+		return "", err
+	}
+
+	text = truncate(text, MaxTextRunes)
+	cacheMu.Lock()
+	cache[f.Path] = text
+	cacheMu.Unlock()
+	return text, nil
+}
+
+// Recognize 用当前引擎识别图片数据中的文字，返回压缩空白后的全部文字
+// 用于识别图片的一部分（如截图的顶部栏），结果不缓存
+func Recognize(img []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ocrTimeout)
+	defer cancel()
+
+	var text string
+	var err error
+	cfg := config.Get()
+	switch cfg.OCR {
+	case EngineTesseract:
+		text, err = tesseract(ctx, img, cfg.OCRLanguages)
+	case EngineVision:
+		text, err = llm.NewClient().Generate(ctx, cfg.OCRModel, visionPrompt, [][]byte{img})
+	default:
+		return "", fmt.Errorf("未知的 OCR 引擎: %s", cfg.OCR)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(text), " "), nil
+}
+
+// loadImage 读取待识别的图片，PDF 渲染首页
+func loadImage(f scanner.FileInfo) ([]byte, error) {
+	if f.Extension != ".pdf" {
+		return os.ReadFile(f.Path)
+	}
+	if _, err := exec.LookPath("pdftoppm"); err != nil {
+		return nil, fmt.Errorf("识别 PDF 需要 pdftoppm（poppler）")
+	}
+	out, err := exec.Command("pdftoppm", "-png", "-r", "150", "-f", "1", "-l", "1", "-singlefile", f.Path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("渲染 PDF 失败: %w", err)
+	}
+	return out, nil
+}
+
+// tesseract 调用 tesseract 命令行识别图片（从标准输入读取）
+func tesseract(ctx context.Context, img []byte, languages string) (string, error) {
+	args := []string{"stdin", "stdout"}
+	if languages != "" {
+		args = append(args, "-l", languages)
+	}
+	cmd := exec.CommandContext(ctx, "tesseract", args...)
+	cmd.Stdin = bytes.NewReader(img)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+// truncate 按字符截断
+func truncate(s string, maxRunes int) string {
+	runes := []rune(s)
+	if len(runes) <= maxRunes {
+		return s
+	}
+	return string(runes[:maxRunes])
+}
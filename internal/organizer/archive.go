@@ -0,0 +1,183 @@
+// Package organizer 文件整理模块
+// archive.go - 归档压缩：把归档批次中每个分类文件夹的文件打包为带日期的 tar.gz
+// 操作日志的目标路径改为「压缩包#文件名」，撤销时从压缩包中解出
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"filo/internal/storage"
+)
+
+// ArchiveMemberSep 操作日志中压缩包路径与包内文件名的分隔符
+const ArchiveMemberSep = ".tar.gz#"
+
+// CompressResult 归档压缩结果
+type CompressResult struct {
+	Tarballs []string // 生成的压缩包
+	Files    int      // 打包的文件数
+}
+
+// Compress 将批次中移入同一文件夹的文件打包为 文件夹名_日期.tar.gz
+// 打包成功后删除原文件，并把操作日志的目标路径改为压缩包中的位置；
+// 某个文件夹打包失败时保留其中的文件，继续处理其他文件夹；
+// 待确认文件夹中的文件留给 filo review 处理，不打包；
+// 与已有文件内容相同而未移动的文件（目标位置是原有的文件）、分类操作压缩或转换过的文件也不打包
+func Compress(db *storage.Database, batchID string) (CompressResult, error) {
+	var result CompressResult
+	logs, err := db.GetBatchLogs(batchID)
+	if err != nil {
+		return result, err
+	}
+
+	groups := make(map[string][]storage.OperationLog)
+	for _, log := range logs {
+		if log.Category == ReviewFolder || log.Resolution == ResolvedIdentical || changedFile(log.PostActions) {
+			continue
+		}
+		dir := filepath.Dir(log.DestPath)
+		groups[dir] = append(groups[dir], log)
+	}
+	dirs := make([]string, 0, len(groups))
+	for dir := range groups {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	date := time.Now().Format("20060102")
+	var errs []string
+	for _, dir := range dirs {
+		tarball := handleDuplicate(fmt.Sprintf("%s_%s.tar.gz", dir, date))
+		if err := writeTarball(tarball, groups[dir]); err != nil {
+			os.Remove(osPath(tarball))
+			errs = append(errs, fmt.Sprintf("%s: %v", filepath.Base(dir), err))
+			continue
+		}
+
+		for _, log := range groups[dir] {
+			os.Remove(osPath(log.DestPath))
+			db.SetOperationDest(log.ID, tarball+"#"+filepath.Base(log.DestPath))
+		}
+		os.Remove(osPath(dir)) // 文件夹已空时删除
+		result.Tarballs = append(result.Tarballs, tarball)
+		result.Files += len(groups[dir])
+	}
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("部分文件夹打包失败: %s", strings.Join(errs, "; "))
+	}
+	return result, nil
+}
+
+// writeTarball 将文件写入 tar.gz，包内只保留文件名
+func writeTarball(path string, logs []storage.OperationLog) error {
+	out, err := os.Create(osPath(path))
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	for _, log := range logs {
+		if err := addToTar(tw, log.DestPath); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// addToTar 向 tar 写入单个文件
+func addToTar(tw *tar.Writer, path string) error {
+	f, err := os.Open(osPath(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = filepath.Base(path)
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// splitArchivePath 拆分「压缩包#文件名」形式的目标路径
+func splitArchivePath(dest string) (tarball, member string, ok bool) {
+	i := strings.Index(dest, ArchiveMemberSep)
+	if i < 0 {
+		return "", "", false
+	}
+	cut := i + len(ArchiveMemberSep) - 1 // 保留 .tar.gz，去掉 #
+	return dest[:cut], dest[cut+1:], true
+}
+
+// extractMember 从压缩包中解出单个文件到 dst，保留修改时间
+func extractMember(tarball, member, dst string) error {
+	f, err := os.Open(osPath(tarball))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("压缩包中没有 %s", member)
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Name != member {
+			continue
+		}
+
+		out, err := os.OpenFile(osPath(dst), os.O_CREATE|os.O_EXCL|os.O_WRONLY, os.FileMode(hdr.Mode).Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, tr); err != nil {
+			out.Close()
+			os.Remove(osPath(dst))
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+		return os.Chtimes(dst, hdr.ModTime, hdr.ModTime)
+	}
+}
@@ -0,0 +1,173 @@
+// Package organizer 文件整理模块
+// categories.go - 分类目录树：以目录树显示目标目录中各分类文件夹的文件数、大小和最近一次移入的时间，
+// 磁盘上的现状与操作日志中的整理记录结合，标出长期没有收到新文件、可以考虑合并的分类
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"filo/internal/folderinfo"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// CategoryNode 分类目录树中的一个文件夹，数量和大小包含全部子文件夹
+type CategoryNode struct {
+	Name     string          // 文件夹名称
+	Path     string          // 绝对路径
+	Files    int             // 磁盘上的文件数
+	Size     int64           // 磁盘上的文件总大小
+	Moved    int             // filo 移入的文件数（操作日志中未撤销的记录）
+	LastMove time.Time       // 最近一次移入的时间，没有整理记录时为零值
+	Children []*CategoryNode // 子文件夹，按名称排序
+}
+
+// Stale 是否长期没有收到新文件：有整理记录且最近一次移入早于 since
+func (n *CategoryNode) Stale(since time.Time) bool {
+	return !n.LastMove.IsZero() && n.LastMove.Before(since)
+}
+
+// CategoryTree 读取目标目录的分类目录树
+// 跳过隐藏文件和 filo 生成的文件夹说明；读取失败的文件夹按空文件夹处理
+//
+// 参数:
+//   - targetDir: 目标目录
+//   - activity: 各文件夹的整理记录（见 storage.GetFolderActivity）
+//
+// 返回值:
+//   - *CategoryNode: 目标目录本身
+//   - error: 目标目录不存在时返回错误
+func CategoryTree(targetDir string, activity map[string]storage.FolderActivity) (*CategoryNode, error) {
+	info, err := os.Stat(osPath(targetDir))
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s 不是目录", targetDir)
+	}
+	return readCategory(targetDir, filepath.Base(targetDir), activity), nil
+}
+
+// readCategory 递归读取一个文件夹，汇总子文件夹的数量、大小和最近移入时间
+func readCategory(path, name string, activity map[string]storage.FolderActivity) *CategoryNode {
+	n := &CategoryNode{Name: name, Path: path}
+	if a, ok := activity[path]; ok {
+		n.Moved, n.LastMove = a.Moved, a.LastMove
+	}
+
+	entries, _ := os.ReadDir(osPath(path))
+	for _, e := range entries {
+		child := filepath.Join(path, e.Name())
+		if strings.HasPrefix(e.Name(), ".") || (e.Name() == folderinfo.ReadmeName && folderinfo.IsGenerated(child)) {
+			continue
+		}
+		if e.IsDir() {
+			c := readCategory(child, e.Name(), activity)
+			n.Children = append(n.Children, c)
+			n.Files += c.Files
+			n.Size += c.Size
+			n.Moved += c.Moved
+			if c.LastMove.After(n.LastMove) {
+				n.LastMove = c.LastMove
+			}
+			continue
+		}
+		n.Files++
+		if e.Type()&fs.ModeSymlink == 0 {
+			if info, err := e.Info(); err == nil {
+				n.Size += info.Size()
+			}
+		}
+	}
+	sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Name < n.Children[j].Name })
+	return n
+}
+
+// PrintCategoryTree 以目录树显示分类文件夹
+// 长期没有收到新文件的分类以黄色标出，并在最后列出可以考虑合并的分类
+//
+// 参数:
+//   - root: 目标目录（CategoryTree 的返回值）
+//   - depth: 显示的层数，0 表示全部（更深的文件夹仍计入上级的数量）
+//   - staleMonths: 超过这么多个月没有收到新文件视为可以合并
+func PrintCategoryTree(root *CategoryNode, depth, staleMonths int) {
+	since := time.Now().AddDate(0, -staleMonths, 0)
+
+	ui.Title("🌳", "分类目录")
+	fmt.Printf("  %s %s\n", ui.Bold(root.Path+string(filepath.Separator)), categorySummary(root))
+	printCategoryNode(root, "  ", 1, depth, since)
+	fmt.Println()
+
+	var stale []*CategoryNode
+	collectStale(root, since, &stale)
+	if len(stale) == 0 {
+		if root.Moved == 0 {
+			ui.Dim("目标目录中没有整理记录，无法判断各分类的活跃程度")
+		} else {
+			ui.Success("所有分类最近 %d 个月内都收到过新文件", staleMonths)
+		}
+		return
+	}
+	ui.Warning("%d 个分类超过 %d 个月没有收到新文件，可以考虑合并到相近的分类:", len(stale), staleMonths)
+	for _, n := range stale {
+		rel, _ := filepath.Rel(root.Path, n.Path)
+		ui.Dim("  %s  %d 个文件，最近移入 %s", rel, n.Files, n.LastMove.Local().Format("2006-01-02"))
+	}
+}
+
+// printCategoryNode 递归打印子文件夹，level 为当前层数
+func printCategoryNode(n *CategoryNode, indent string, level, depth int, since time.Time) {
+	for i, c := range n.Children {
+		b, next := "├── ", "│   "
+		if i == len(n.Children)-1 {
+			b, next = "└── ", "    "
+		}
+		name := ui.Bold(c.Name + string(filepath.Separator))
+		if c.Stale(since) {
+			name = ui.Yellow(c.Name + string(filepath.Separator))
+		}
+		line := name + " " + categorySummary(c)
+		if depth > 0 && level >= depth && len(c.Children) > 0 {
+			line += ui.Gray(fmt.Sprintf("  (%d 个子文件夹)", len(c.Children)))
+		}
+		fmt.Printf("%s%s%s\n", indent, ui.Gray(b), line)
+		if depth == 0 || level < depth {
+			printCategoryNode(c, indent+ui.Gray(next), level+1, depth, since)
+		}
+	}
+}
+
+// categorySummary 文件夹的文件数、大小和最近移入时间
+func categorySummary(n *CategoryNode) string {
+	parts := []string{fmt.Sprintf("%d 个文件", n.Files)}
+	if n.Files > 0 {
+		parts = append(parts, ui.FormatSize(n.Size))
+	}
+	if n.LastMove.IsZero() {
+		parts = append(parts, "无整理记录")
+	} else {
+		parts = append(parts, "最近移入 "+n.LastMove.Local().Format("2006-01-02"))
+	}
+	return ui.Gray(strings.Join(parts, " · "))
+}
+
+// collectStale 收集长期没有收到新文件的分类，上级已列出时不再列出其中的子文件夹
+func collectStale(n *CategoryNode, since time.Time, stale *[]*CategoryNode) {
+	for _, c := range n.Children {
+		if c.Stale(since) {
+			*stale = append(*stale, c)
+			continue
+		}
+		collectStale(c, since, stale)
+	}
+}
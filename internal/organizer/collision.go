@@ -0,0 +1,201 @@
+// Package organizer 文件整理模块
+// collision.go - 计划中的重名：生成计划后找出目标位置已有同名文件的文件，计划中标出，
+// 交互模式（-i）逐个询问改名、跳过、内容相同时只保留已有文件，或先比较两个文件
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/preview"
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// strategyLabels 重名处理策略的说明（计划和交互询问中显示）
+var strategyLabels = map[string]string{
+	ConflictSuffix:    "添加数字后缀",
+	ConflictIdentical: "内容相同时只保留已有文件，不同时添加数字后缀",
+	ConflictNewest:    "保留修改时间较新的文件",
+	ConflictTimestamp: "添加修改时间后缀",
+	ConflictSkip:      "跳过，留在原处",
+}
+
+// Collision 计划中与目标位置的文件重名的文件
+type Collision struct {
+	Result   classifier.Result // 要移动的文件
+	Folder   string            // 目标文件夹（相对目标目录）
+	Existing string            // 同名的文件：目标路径上已有的文件，或计划中先移入同一文件夹的文件的源路径
+	Planned  bool              // 同名的是计划中的另一个文件
+}
+
+// FindCollisions 找出计划中会重名的文件
+// 包括目标文件夹中已有同名文件的，以及与计划中先移入同一文件夹的文件同名的；
+// 不区分大小写的文件系统上只有大小写不同的文件名也算重名，文件已经在目标位置时不算
+//
+// 返回值:
+//   - []Collision: 重名的文件，按目标文件夹排序，同一文件夹中按移动顺序
+func (p *Plan) FindCollisions() []Collision {
+	folders := make([]string, 0, len(p.Actions))
+	for f := range p.Actions {
+		folders = append(folders, f)
+	}
+	sort.Strings(folders)
+
+	var collisions []Collision
+	for _, folder := range folders {
+		planned := make(map[string]string) // 目标路径键 -> 先移入的文件的源路径
+		for _, r := range p.Actions[folder] {
+			target := filepath.Join(p.TargetDir, folder, r.FileInfo.Name)
+			key := pathKey(target)
+			c := Collision{Result: r, Folder: folder}
+			if src, ok := planned[key]; ok {
+				c.Existing, c.Planned = src, true
+				collisions = append(collisions, c)
+				continue
+			}
+			planned[key] = r.FileInfo.Path
+			if _, err := os.Lstat(osPath(target)); err == nil && !sameFile(r.FileInfo.Path, target) {
+				c.Existing = target
+				collisions = append(collisions, c)
+			}
+		}
+	}
+	return collisions
+}
+
+// strategyFor 文件重名时的处理策略：交互模式中为该文件选择的，否则为 conflict_strategy
+func (p *Plan) strategyFor(r classifier.Result) string {
+	if s, ok := p.Resolutions[r.FileInfo.Path]; ok {
+		return s
+	}
+	return config.Get().ConflictStrategy
+}
+
+// describe 重名的说明，如「目标文件夹已有同名文件」
+func (p *Plan) describe(c Collision) string {
+	if c.Planned {
+		return fmt.Sprintf("与 %s 重名", p.relPath(c.Existing))
+	}
+	return "目标文件夹已有同名文件"
+}
+
+// collisionMark 重名文件在计划中的标记
+func collisionMark(colliding map[string]bool, r classifier.Result) string {
+	if colliding[r.FileInfo.Path] {
+		return " " + ui.Yellow("⚠")
+	}
+	return ""
+}
+
+// printCollisions 列出计划中重名的文件及其处理方式
+func printCollisions(plan *Plan, collisions []Collision) {
+	if len(collisions) == 0 {
+		return
+	}
+	hint := fmt.Sprintf("(%d个，加 -i 逐个选择处理方式)", len(collisions))
+	if plan.Resolutions != nil {
+		hint = fmt.Sprintf("(%d个)", len(collisions)) // 已经逐个选择过
+	}
+	fmt.Printf("\n  %s %s %s\n", ui.Yellow("⚠"), ui.Bold("重名"), ui.Gray(hint))
+	for i, c := range collisions {
+		if i >= MaxDisplayFiles {
+			ui.Dim("      ... 还有 %d 个文件", len(collisions)-MaxDisplayFiles)
+			break
+		}
+		fmt.Printf("      %s → %s %s\n", plan.RelPath(c.Result), filepath.Join(c.Folder, c.Result.FileInfo.Name), ui.Gray(plan.describe(c)))
+		ui.Dim("         └─ %s", strategyLabels[plan.strategyFor(c.Result)])
+	}
+}
+
+// ReviewCollisions 交互模式下逐个询问重名文件的处理方式
+// 可以改名（添加数字后缀）、跳过、内容相同时只保留已有文件，或先比较两个文件再决定；
+// 选择「其余按 conflict_strategy 处理」后不再询问。选择记录在计划中，执行时代替 conflict_strategy
+func ReviewCollisions(plan *Plan) {
+	collisions := plan.FindCollisions()
+	if len(collisions) == 0 {
+		return
+	}
+	if plan.Resolutions == nil {
+		plan.Resolutions = make(map[string]string)
+	}
+
+	strategy := config.Get().ConflictStrategy
+	choices := []string{ConflictSuffix, ConflictSkip, ConflictIdentical}
+	def := 0
+	for i, s := range choices {
+		if s == strategy {
+			def = i
+		}
+	}
+
+	fmt.Println()
+	ui.Warning("%d 个文件重名，逐个选择处理方式", len(collisions))
+	for i, c := range collisions {
+		fmt.Println()
+		fmt.Printf("  %s [%d/%d] %s → %s\n", ui.Yellow("⚠"), i+1, len(collisions), plan.RelPath(c.Result), filepath.Join(c.Folder, c.Result.FileInfo.Name))
+		ui.Dim("     %s", plan.describe(c))
+
+		options := []string{
+			"改名（" + strategyLabels[ConflictSuffix] + "）",
+			strategyLabels[ConflictSkip],
+			strategyLabels[ConflictIdentical],
+			"比较两个文件",
+			fmt.Sprintf("其余 %d 个重名文件按 conflict_strategy 处理（%s）", len(collisions)-i, strategyLabels[strategy]),
+		}
+		for {
+			n := ui.Choose("  如何处理?", options, def)
+			if n == 3 {
+				compareFiles(c)
+				continue
+			}
+			if n == 4 {
+				return
+			}
+			plan.Resolutions[c.Result.FileInfo.Path] = choices[n]
+			break
+		}
+	}
+	ui.Success("已选择 %d 个重名文件的处理方式", len(collisions))
+}
+
+// compareFiles 依次显示两个重名文件的大小、修改时间、内容是否相同和预览
+func compareFiles(c Collision) {
+	mode := config.Get().ReviewPreview
+	show := func(label string, f scanner.FileInfo) {
+		fmt.Printf("     %s %s  %s  %s\n", ui.Bold(label), f.Path, ui.FormatSize(f.Size), f.ModifiedTime.Format("2006-01-02 15:04"))
+		preview.Print(f, mode)
+	}
+
+	show("待整理:", c.Result.FileInfo)
+	existing, err := scanner.StatFile(c.Existing)
+	if err != nil {
+		ui.Warning("     无法读取已有文件: %v", err)
+		return
+	}
+	show("已有的:", existing)
+
+	switch {
+	case existing.IsDir:
+		ui.Info("     已有的是文件夹，只能改名或跳过")
+	case c.Result.FileInfo.Cloud != "" || existing.Cloud != "":
+		ui.Dim("     文件未下载到本机，不比较内容")
+	case existing.Size == c.Result.FileInfo.Size && sameContent(c.Result.FileInfo.Path, c.Existing):
+		ui.Success("     内容相同")
+	default:
+		ui.Info("     内容不同")
+		if c.Result.FileInfo.ModifiedTime.After(existing.ModifiedTime) {
+			ui.Dim("     待整理的文件较新")
+		} else {
+			ui.Dim("     已有的文件较新")
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package organizer 文件整理模块
+// conflict.go - 重名冲突处理：目标文件夹已有同名文件时，按策略添加后缀、合并相同文件、
+// 替换较旧的文件或跳过，实际的处理结果记录在操作日志中，撤销时据此还原
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filo/internal/classifier"
+	"filo/internal/quarantine"
+)
+
+// 重名冲突的处理策略（--on-conflict / conflict_strategy）
+const (
+	ConflictSuffix    = "suffix"              // 添加数字后缀 _1、_2（默认）
+	ConflictIdentical = "overwrite-identical" // 内容相同时只保留已有文件，不同时添加数字后缀
+	ConflictNewest    = "keep-newest"         // 保留修改时间较新的文件，被替换的文件移入 .filo-replaced
+	ConflictTimestamp = "timestamp"           // 添加修改时间后缀，如 报告_20240315-093000.pdf
+	ConflictSkip      = "skip"                // 跳过，文件留在原处并在结果中列出
+)
+
+// ConflictStrategies 所有可选的重名处理策略
+var ConflictStrategies = []string{ConflictSuffix, ConflictIdentical, ConflictNewest, ConflictTimestamp, ConflictSkip}
+
+// 重名冲突的处理结果（操作日志的 resolution 字段，无冲突时为空）
+const (
+	ResolvedSuffix    = "suffix"    // 添加了数字后缀
+	ResolvedTimestamp = "timestamp" // 添加了时间后缀
+	ResolvedIdentical = "identical" // 与已有文件内容相同，删除源文件或移入回收站（撤销时放回原处）
+	ResolvedReplaced  = "replaced"  // 已有文件较旧，移入 .filo-replaced（撤销时恢复）
+	ResolvedSkipped   = "skipped"   // 跳过，文件未移动
+	ResolvedUnsynced  = "unsynced"  // 仅在云端的文件不移出同步目录，文件未移动
+	ResolvedInUse     = "in_use"    // 文件正被其他程序使用，文件未移动
+)
+
+// ReplacedFolder 被替换文件的备份目录（位于目标目录下，按批次存放）
+// 以 . 开头，再次整理时扫描器会跳过
+const ReplacedFolder = ".filo-replaced"
+
+// resolutionLabels 处理结果的说明（详细输出中显示）
+var resolutionLabels = map[string]string{
+	ResolvedSuffix:    "重名，已添加数字后缀",
+	ResolvedTimestamp: "重名，已添加时间后缀",
+	ResolvedIdentical: "与已有文件内容相同，只保留已有文件",
+	ResolvedReplaced:  "替换较旧的已有文件，原文件备份到 " + ReplacedFolder,
+	ResolvedSkipped:   "目标已有同名文件，跳过",
+	ResolvedUnsynced:  "文件未下载到本机，不移出云盘同步目录",
+	ResolvedInUse:     "文件正被其他程序使用，留在原处",
+}
+
+// ValidConflictStrategy 判断是否为支持的重名处理策略
+func ValidConflictStrategy(s string) bool {
+	for _, c := range ConflictStrategies {
+		if s == c {
+			return true
+		}
+	}
+	return false
+}
+
+// conflictPlan 单个文件的重名处理方案
+type conflictPlan struct {
+	dst        string // 最终目标路径
+	resolution string // 处理结果，无冲突时为空
+	backup     string // 被替换文件的备份路径（replaced），或源文件移入回收站的路径（identical，启用软删除时）
+}
+
+// resolveConflict 按策略确定文件的目标路径
+// 目标路径不存在时直接使用；策略不适用时（如内容不同、已有的是目录）退回数字后缀
+//
+// 参数:
+//   - strategy: 重名处理策略，为空时添加数字后缀
+//   - r: 要移动的文件
+//   - dst: 目标路径
+//   - backup: 替换已有文件时的备份路径
+func resolveConflict(strategy string, r classifier.Result, dst, backup string) conflictPlan {
+	existing, err := os.Stat(osPath(dst))
+	if os.IsNotExist(err) {
+		return conflictPlan{dst: dst}
+	}
+	// 不区分大小写的文件系统上，目标路径可能就是文件本身（如 已整理/文档/a.txt 与 A.txt），
+	// 此时不是重名，直接改名即可；否则 overwrite-identical 会删除唯一的一份
+	if sameFile(r.FileInfo.Path, dst) {
+		return conflictPlan{dst: dst}
+	}
+
+	if err == nil && existing.Mode().IsRegular() {
+		switch strategy {
+		case ConflictSkip:
+			return conflictPlan{dst: dst, resolution: ResolvedSkipped}
+		case ConflictIdentical:
+			// 仅在云端的文件比较内容会触发下载
+			if existing.Size() == r.FileInfo.Size && r.FileInfo.Cloud == "" && sameContent(r.FileInfo.Path, dst) {
+				return conflictPlan{dst: dst, resolution: ResolvedIdentical}
+			}
+		case ConflictNewest:
+			if !r.FileInfo.ModifiedTime.After(existing.ModTime()) {
+				return conflictPlan{dst: dst, resolution: ResolvedSkipped}
+			}
+			return conflictPlan{dst: dst, resolution: ResolvedReplaced, backup: handleDuplicate(backup)}
+		case ConflictTimestamp:
+			ext := filepath.Ext(dst)
+			stamped := strings.TrimSuffix(dst, ext) + "_" + r.FileInfo.ModifiedTime.Format("20060102-150405") + ext
+			return conflictPlan{dst: handleDuplicate(stamped), resolution: ResolvedTimestamp}
+		}
+	}
+	return conflictPlan{dst: handleDuplicate(dst), resolution: ResolvedSuffix}
+}
+
+// apply 执行文件移动，返回实际的移动方式
+// identical 时删除源文件，启用软删除时改为移入回收站；replaced 时先把已有文件移入备份目录，移动失败则放回；
+// copyAcross 为 true 时跨设备的文件改为复制校验后删除源文件
+func (c conflictPlan) apply(src string, copyAcross bool) (string, error) {
+	switch c.resolution {
+	case ResolvedIdentical:
+		if c.backup != "" {
+			return TransferRename, moveToTrash(src, c.backup)
+		}
+		return TransferRename, os.Remove(osPath(src))
+	case ResolvedReplaced:
+		if err := os.MkdirAll(osPath(filepath.Dir(c.backup)), 0755); err != nil {
+			return TransferRename, err
+		}
+		if err := os.Rename(osPath(c.dst), osPath(c.backup)); err != nil {
+			return TransferRename, err
+		}
+		transfer, err := moveAcross(src, c.dst, copyAcross)
+		if err != nil {
+			os.Rename(osPath(c.backup), osPath(c.dst))
+		}
+		return transfer, err
+	default:
+		return moveAcross(src, c.dst, copyAcross)
+	}
+}
+
+// sameContent 比较两个文件的 SHA-256 是否一致
+func sameContent(a, b string) bool {
+	ha, err := quarantine.Hash(osPath(a))
+	if err != nil {
+		return false
+	}
+	hb, err := quarantine.Hash(osPath(b))
+	return err == nil && ha == hb
+}
+
+// copyFile 复制文件并保留权限和修改时间
+// 目标已存在时返回错误
+func copyFile(src, dst string) error {
+	in, err := os.Open(osPath(src))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(osPath(dst), os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(osPath(dst))
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(osPath(dst), info.ModTime(), info.ModTime())
+}
+
+// restoreReplaced 把被替换的文件从备份目录放回原位置，并清理空的备份目录
+func restoreReplaced(backup, dst string) error {
+	if err := os.Rename(osPath(backup), osPath(dst)); err != nil {
+		return err
+	}
+	// 逐级删除空的备份目录，直到 .filo-replaced 本身
+	for dir := filepath.Dir(backup); strings.Contains(dir, ReplacedFolder); dir = filepath.Dir(dir) {
+		if os.Remove(osPath(dir)) != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Package organizer 文件整理模块
+// correct.go - 事后纠正：把已整理的文件改放到新分类的文件夹
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filo/internal/classifier"
+	"filo/internal/storage"
+)
+
+// Recategorize 将已整理的文件移到新分类的文件夹，并更新操作日志
+// 目标目录由文件当前所在的分类文件夹推算，撤销时文件从新位置移回原处
+//
+// 参数:
+//   - db: 数据库
+//   - log: 文件所在批次的操作日志
+//   - category: 新的主分类
+//   - subcategory: 新的子分类
+//
+// 返回值:
+//   - string: 文件的新路径
+//   - error: 文件不存在、无法推算目标目录或移动失败时返回错误；
+//     整理时与已有文件合并或替换了已有文件、分类操作压缩或转换过的，需要撤销后重新整理
+func Recategorize(db *storage.Database, log storage.OperationLog, category, subcategory string) (string, error) {
+	if log.Resolution == ResolvedIdentical || log.Resolution == ResolvedReplaced {
+		return "", fmt.Errorf("整理时处理过重名文件（%s），请撤销该批次后重新整理", log.Resolution)
+	}
+	if changedFile(log.PostActions) {
+		return "", fmt.Errorf("整理后执行过分类操作（%s），请撤销该批次后重新整理", log.PostActions)
+	}
+	if _, err := os.Stat(osPath(log.DestPath)); err != nil {
+		return "", fmt.Errorf("文件已不在整理后的位置: %s", log.DestPath)
+	}
+
+	// 当前位置为 目标目录/原分类文件夹/文件名，超出容量上限时为 目标目录/原分类文件夹/月份/文件名
+	oldFolder := folderFor(classifier.Result{Category: log.Category, Subcategory: log.Subcategory})
+	dir := filepath.Dir(log.DestPath)
+	suffix := string(filepath.Separator) + oldFolder
+	if !strings.HasSuffix(dir, suffix) && isMonthFolder(filepath.Base(dir)) {
+		dir = filepath.Dir(dir)
+	}
+	if !strings.HasSuffix(dir, suffix) {
+		return "", fmt.Errorf("无法确定目标目录: %s", dir)
+	}
+	targetDir := strings.TrimSuffix(dir, suffix)
+
+	newFolder := filepath.Join(targetDir, folderFor(classifier.Result{Category: category, Subcategory: subcategory}))
+	if err := os.MkdirAll(osPath(newFolder), 0755); err != nil {
+		return "", err
+	}
+	// 尽量恢复原文件名（原分类文件夹中重名改过名的文件）
+	dst := handleDuplicate(filepath.Join(newFolder, log.Filename))
+	if err := os.Rename(osPath(log.DestPath), osPath(dst)); err != nil {
+		return "", err
+	}
+
+	if err := db.UpdateOperationLog(log.ID, dst, category, subcategory); err != nil {
+		return dst, err
+	}
+	cleanEmptyDirs([]storage.OperationLog{log})
+	return dst, nil
+}
@@ -0,0 +1,26 @@
+// Package organizer 文件整理模块
+// disk_other.go - 不支持的平台上不检查磁盘剩余空间，不判断跨设备的移动
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build !linux && !darwin && !freebsd && !windows
+
+package organizer
+
+import "errors"
+
+// DiskFree 当前平台不支持获取剩余空间
+func DiskFree(dir string) (uint64, error) {
+	return 0, errors.New("当前系统不支持")
+}
+
+// sameVolume 当前平台无法判断，视为同一个卷
+func sameVolume(a, b string) bool {
+	return true
+}
+
+// crossDevice 当前平台无法判断，不视为跨设备错误
+func crossDevice(err error) bool {
+	return false
+}
@@ -0,0 +1,37 @@
+// Package organizer 文件整理模块
+// disk_unix.go - 基于 statfs 获取磁盘剩余空间，判断跨文件系统的移动
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build linux || darwin || freebsd
+
+package organizer
+
+import (
+	"errors"
+	"syscall"
+)
+
+// DiskFree 返回目录所在磁盘对当前用户可用的剩余空间（字节）
+func DiskFree(dir string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(osPath(dir), &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
+
+// sameVolume 两个已存在的路径是否在同一个文件系统上（无法判断时视为相同）
+func sameVolume(a, b string) bool {
+	var sa, sb syscall.Stat_t
+	if syscall.Stat(osPath(a), &sa) != nil || syscall.Stat(osPath(b), &sb) != nil {
+		return true
+	}
+	return sa.Dev == sb.Dev
+}
+
+// crossDevice 错误是否因源文件和目标不在同一个文件系统上而无法改名
+func crossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
@@ -0,0 +1,48 @@
+// Package organizer 文件整理模块
+// disk_windows.go - 基于 GetDiskFreeSpaceEx 获取磁盘剩余空间，判断跨卷的移动
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build windows
+
+package organizer
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// DiskFree 返回目录所在磁盘对当前用户可用的剩余空间（字节）
+func DiskFree(dir string) (uint64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &free, nil, nil); err != nil {
+		return 0, err
+	}
+	return free, nil
+}
+
+// sameVolume 两个路径是否在同一个卷上（按盘符或网络共享判断）
+func sameVolume(a, b string) bool {
+	aa, err := filepath.Abs(a)
+	if err != nil {
+		return true
+	}
+	ab, err := filepath.Abs(b)
+	if err != nil {
+		return true
+	}
+	return strings.EqualFold(filepath.VolumeName(aa), filepath.VolumeName(ab))
+}
+
+// crossDevice 错误是否因源文件和目标不在同一个卷上而无法改名
+func crossDevice(err error) bool {
+	return errors.Is(err, windows.ERROR_NOT_SAME_DEVICE)
+}
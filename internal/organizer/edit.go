@@ -0,0 +1,209 @@
+// Package organizer 文件整理模块
+// edit.go - 使用外部编辑器编辑整理计划
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"filo/internal/classifier"
+	"filo/internal/ui"
+)
+
+// planFileHeader 计划文件头部说明
+const planFileHeader = `# filo 整理计划
+# - 把文件行移动到其他文件夹下，即可修改目标文件夹
+# - 可以新增文件夹（格式: "主分类/子分类":，可有多级，如 "工作/客户A/合同":）
+# - 删除文件行，该文件将被跳过、保持原位
+# 保存并关闭编辑器后继续
+`
+
+// EditPlan 使用外部编辑器编辑整理计划
+// 将计划写入临时 YAML 文件并打开 $VISUAL / $EDITOR，保存后重新解析
+// 被移动的文件会作为纠正进行学习
+func EditPlan(plan *Plan, clf *classifier.Classifier) (*Plan, error) {
+	tmp, err := os.CreateTemp("", "filo-plan-*.yaml")
+	if err != nil {
+		return plan, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(formatPlanYAML(plan)); err != nil {
+		tmp.Close()
+		return plan, err
+	}
+	tmp.Close()
+
+	// 打开编辑器并等待用户保存退出
+	if err := openEditor(tmp.Name()); err != nil {
+		return plan, fmt.Errorf("打开编辑器失败: %w", err)
+	}
+
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return plan, err
+	}
+	assignments, err := parsePlanYAML(string(data))
+	if err != nil {
+		return plan, err
+	}
+
+	// 按文件路径应用编辑结果
+	var results []classifier.Result
+	moved, skipped := 0, 0
+	for folder, files := range plan.Actions {
+		for _, r := range files {
+			newFolder, ok := assignments[r.FileInfo.Path]
+			if !ok {
+				skipped++
+				continue
+			}
+			if newFolder != filepath.ToSlash(folder) {
+				// 只换了月份子文件夹等不改变分类的编辑不作为纠正
+				newCat, newSub := folderCategory(newFolder)
+				if newCat == r.Category && newSub == r.Subcategory {
+					results = append(results, r)
+					continue
+				}
+				clf.Correct(r, newCat, newSub)
+				r.Category = newCat
+				r.Subcategory = newSub
+				r.Confidence = 1.0
+				r.Source = "user"
+				moved++
+			}
+			results = append(results, r)
+		}
+	}
+
+	ui.Success("编辑完成: %d 个文件改变分类，%d 个文件跳过", moved, skipped)
+	edited := GeneratePlan(results, plan.TargetDir)
+	edited.SourceDir = plan.SourceDir
+	return edited, nil
+}
+
+// formatPlanYAML 将计划格式化为 YAML
+// 以文件夹为键、文件路径列表为值，路径作为文件的唯一标识
+func formatPlanYAML(plan *Plan) string {
+	var sb strings.Builder
+	sb.WriteString(planFileHeader)
+
+	folders := make([]string, 0, len(plan.Actions))
+	for f := range plan.Actions {
+		folders = append(folders, f)
+	}
+	sort.Strings(folders)
+
+	for _, folder := range folders {
+		sb.WriteString("\n" + strconv.Quote(filepath.ToSlash(folder)) + ":\n")
+		for _, r := range plan.Actions[folder] {
+			sb.WriteString("  - " + strconv.Quote(r.FileInfo.Path) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// parsePlanYAML 解析编辑后的计划文件
+// 返回 文件路径 -> 文件夹 的映射；同一文件出现多次时以第一次为准
+func parsePlanYAML(content string) (map[string]string, error) {
+	assignments := make(map[string]string)
+	folder := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// 文件行: "  - 路径"
+		if strings.HasPrefix(trimmed, "- ") {
+			if folder == "" {
+				return nil, fmt.Errorf("第 %d 行: 文件不属于任何文件夹", lineNo)
+			}
+			path := unquoteYAML(strings.TrimSpace(trimmed[2:]))
+			if _, exists := assignments[path]; !exists {
+				assignments[path] = folder
+			}
+			continue
+		}
+
+		// 文件夹行: "文件夹":
+		if strings.HasSuffix(trimmed, ":") && line == trimmed {
+			folder = filepath.FromSlash(unquoteYAML(strings.TrimSuffix(trimmed, ":")))
+			if folder == "" {
+				return nil, fmt.Errorf("第 %d 行: 文件夹名为空", lineNo)
+			}
+			continue
+		}
+
+		return nil, fmt.Errorf("第 %d 行: 无法识别 %q", lineNo, trimmed)
+	}
+	return assignments, scanner.Err()
+}
+
+// unquoteYAML 去除 YAML 字符串的引号
+// 支持双引号、单引号和不带引号的写法
+func unquoteYAML(s string) string {
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '"' && s[len(s)-1] == '"':
+			if u, err := strconv.Unquote(s); err == nil {
+				return u
+			}
+		case s[0] == '\'' && s[len(s)-1] == '\'':
+			return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+		}
+	}
+	return s
+}
+
+// folderCategory 将计划中的文件夹换回主分类和子分类
+// 计划中的文件夹是按 folder_language、folder_names 显示的名称，各层级换回分类名后再规范化；
+// 超出容量上限时末级为月份子文件夹（如 2024-06），不属于分类，去掉后再换算
+func folderCategory(folder string) (string, string) {
+	path := strings.Split(filepath.ToSlash(folder), "/")
+	if n := len(path); n > 1 && isMonthFolder(path[n-1]) {
+		path = path[:n-1]
+	}
+	return classifier.Normalize(classifier.JoinPath(classifier.CategoryNames(path)))
+}
+
+// openEditor 打开外部编辑器编辑文件
+// 优先使用 $VISUAL，其次 $EDITOR，都未设置时使用系统默认编辑器
+func openEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		} else {
+			editor = "vi"
+		}
+	}
+
+	// 编辑器变量可能带参数，如 "code --wait"
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,66 @@
+// Package organizer 文件整理模块
+// existing.go - 按已有文件夹整理（existing_folders）：列出目标目录中用户已经维护的文件夹，
+// 分类器只把文件归入这些文件夹，不新建分类
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"filo/internal/classifier"
+	"filo/internal/quarantine"
+	"filo/internal/scanner"
+)
+
+// ExistingFolderDepth 列出已有文件夹的最大层级（目标目录下第几层）
+const ExistingFolderDepth = 3
+
+// ExistingFolders 列出目标目录中已有的文件夹，作为分类的可选目标
+// 跳过隐藏文件夹，以及 filo 自己使用的 待确认、隔离区、待处理 文件夹
+//
+// 参数:
+//   - targetDir: 目标目录
+//
+// 返回值:
+//   - []string: 相对目标目录的文件夹路径（以 / 分隔，按路径排序），如 工作/报告
+//   - error: 如果目标目录无法读取，返回错误
+func ExistingFolders(targetDir string) ([]string, error) {
+	var folders []string
+	var walk func(dir, rel string, depth int) error
+	walk = func(dir, rel string, depth int) error {
+		entries, err := os.ReadDir(osPath(dir))
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if !e.IsDir() || strings.HasPrefix(name, ".") {
+				continue
+			}
+			if category := classifier.CategoryName(name); depth == 1 &&
+				(name == ReviewFolder || category == quarantine.Category || category == scanner.SuspiciousCategory) {
+				continue
+			}
+			path := name
+			if rel != "" {
+				path = rel + "/" + name
+			}
+			folders = append(folders, path)
+			if depth < ExistingFolderDepth {
+				walk(filepath.Join(dir, name), path, depth+1) // 子文件夹读取失败时只跳过该文件夹
+			}
+		}
+		return nil
+	}
+	if err := walk(targetDir, "", 1); err != nil {
+		return nil, err
+	}
+	sort.Strings(folders)
+	return folders, nil
+}
@@ -0,0 +1,326 @@
+// Package organizer 文件整理模块
+// index.go - 索引模式：不移动任何文件，把整理计划写成索引（CSV / JSON / SQLite），
+// 可选地建立按分类组织的符号链接目录，适合只读挂载、不能原地整理的 NAS 资料库
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"filo/internal/classifier"
+
+	_ "modernc.org/sqlite"
+)
+
+// ==================== 常量定义 ====================
+
+// 索引格式
+const (
+	IndexCSV    = "csv"    // 逗号分隔，带表头
+	IndexJSON   = "json"   // JSON 数组
+	IndexSQLite = "sqlite" // SQLite 数据库，files 表
+)
+
+// IndexFormats 所有索引格式
+var IndexFormats = []string{IndexCSV, IndexJSON, IndexSQLite}
+
+// ==================== 数据结构 ====================
+
+// IndexEntry 索引中的一个文件
+type IndexEntry struct {
+	Path        string    `json:"path"`        // 文件完整路径
+	RelPath     string    `json:"rel_path"`    // 相对源目录的路径
+	Size        int64     `json:"size"`        // 文件大小（字节）
+	Modified    time.Time `json:"modified"`    // 最后修改时间
+	Category    string    `json:"category"`    // 主分类
+	Subcategory string    `json:"subcategory"` // 子分类
+	Folder      string    `json:"folder"`      // 建议放入的文件夹（相对目标目录）
+	Confidence  float64   `json:"confidence"`  // 置信度
+	Source      string    `json:"source"`      // 分类来源
+	Reasoning   string    `json:"reasoning"`   // 分类理由
+	Review      bool      `json:"review"`      // 是否为低置信度或有分歧、需要确认的文件
+}
+
+// indexColumns CSV 表头，与 IndexEntry 的字段顺序一致
+var indexColumns = []string{
+	"path", "rel_path", "size", "modified", "category", "subcategory",
+	"folder", "confidence", "source", "reasoning", "review",
+}
+
+// ==================== 索引生成 ====================
+
+// IndexFormatFor 按文件扩展名推断索引格式
+//
+// 参数:
+//   - path: 索引文件路径（.csv / .json / .db / .sqlite / .sqlite3）
+//
+// 返回值:
+//   - string: 索引格式，无法识别时为空
+func IndexFormatFor(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return IndexCSV
+	case ".json":
+		return IndexJSON
+	case ".db", ".sqlite", ".sqlite3":
+		return IndexSQLite
+	}
+	return ""
+}
+
+// ValidIndexFormat 判断是否为支持的索引格式
+func ValidIndexFormat(f string) bool {
+	for _, v := range IndexFormats {
+		if f == v {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexEntries 将计划转换为索引条目，按相对路径排序
+// 待确认的文件按建议的分类记录，并标记为需要确认
+func (p *Plan) IndexEntries() []IndexEntry {
+	var entries []IndexEntry
+	add := func(folder string, r classifier.Result, review bool) {
+		entries = append(entries, IndexEntry{
+			Path:        r.FileInfo.Path,
+			RelPath:     filepath.ToSlash(p.RelPath(r)),
+			Size:        r.FileInfo.Size,
+			Modified:    r.FileInfo.ModifiedTime,
+			Category:    r.Category,
+			Subcategory: r.Subcategory,
+			Folder:      filepath.ToSlash(folder),
+			Confidence:  r.Confidence,
+			Source:      r.Source,
+			Reasoning:   r.Reasoning,
+			Review:      review,
+		})
+	}
+	for folder, files := range p.Actions {
+		for _, r := range files {
+			add(folder, r, false)
+		}
+	}
+	for _, r := range p.Review {
+		add(folderFor(r), r, true)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].RelPath < entries[j].RelPath })
+	return entries
+}
+
+// WriteIndex 将计划写入索引文件，已有的文件会被覆盖
+//
+// 参数:
+//   - plan: 整理计划
+//   - path: 索引文件路径
+//   - format: 索引格式（见 IndexFormats）
+//
+// 返回值:
+//   - int: 写入的文件数
+//   - error: 如果写入失败，返回错误
+func WriteIndex(plan *Plan, path, format string) (int, error) {
+	entries := plan.IndexEntries()
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(osPath(dir), 0755); err != nil {
+			return 0, err
+		}
+	}
+
+	var err error
+	switch format {
+	case IndexCSV:
+		err = writeIndexCSV(path, entries)
+	case IndexJSON:
+		err = writeIndexJSON(path, entries)
+	case IndexSQLite:
+		err = writeIndexSQLite(path, entries)
+	default:
+		err = fmt.Errorf("不支持的索引格式: %s", format)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// writeIndexCSV 写入 CSV 索引（UTF-8 BOM 开头，便于 Excel 正确识别中文）
+func writeIndexCSV(path string, entries []IndexEntry) error {
+	f, err := os.Create(osPath(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString("\ufeff"); err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write(indexColumns)
+	for _, e := range entries {
+		w.Write([]string{
+			e.Path, e.RelPath, strconv.FormatInt(e.Size, 10), e.Modified.Format(time.RFC3339),
+			e.Category, e.Subcategory, e.Folder, strconv.FormatFloat(e.Confidence, 'f', 2, 64),
+			e.Source, e.Reasoning, strconv.FormatBool(e.Review),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeIndexJSON 写入 JSON 索引
+func writeIndexJSON(path string, entries []IndexEntry) error {
+	if entries == nil {
+		entries = []IndexEntry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(osPath(path), append(data, '\n'), 0644)
+}
+
+// writeIndexSQLite 写入 SQLite 索引：先删除已有的文件，再建 files 表并在一个事务中写入
+func writeIndexSQLite(path string, entries []IndexEntry) error {
+	if err := os.Remove(osPath(path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`
+		CREATE TABLE files (
+			path TEXT PRIMARY KEY,
+			rel_path TEXT NOT NULL,
+			size INTEGER,
+			modified DATETIME,
+			category TEXT,
+			subcategory TEXT,
+			folder TEXT,
+			confidence REAL,
+			source TEXT,
+			reasoning TEXT,
+			review INTEGER
+		);
+		CREATE INDEX idx_files_folder ON files(folder);
+	`); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO files VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, e := range entries {
+		if _, err := stmt.Exec(e.Path, e.RelPath, e.Size, e.Modified.Format(time.RFC3339),
+			e.Category, e.Subcategory, e.Folder, e.Confidence, e.Source, e.Reasoning, e.Review); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ==================== 符号链接目录 ====================
+
+// LinkTree 在 dir 下按计划的分类文件夹建立指向原文件的符号链接，原文件保持不动
+// 目录中已有的符号链接（上次生成的）先全部删除，使结果与本次计划一致；普通文件不受影响。
+// 同一文件夹下重名时按 handleDuplicate 的规则添加数字后缀；待确认的文件不建立链接
+//
+// 参数:
+//   - plan: 整理计划
+//   - dir: 符号链接目录
+//
+// 返回值:
+//   - int: 建立的链接数
+//   - []error: 建立失败的链接
+func LinkTree(plan *Plan, dir string) (int, []error) {
+	if err := clearLinks(dir); err != nil {
+		return 0, []error{err}
+	}
+
+	folders := make([]string, 0, len(plan.Actions))
+	for folder := range plan.Actions {
+		folders = append(folders, folder)
+	}
+	sort.Strings(folders)
+
+	created := 0
+	var errs []error
+	for _, folder := range folders {
+		target := filepath.Join(dir, folder)
+		if err := os.MkdirAll(osPath(target), 0755); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", folder, err))
+			continue
+		}
+		for _, r := range plan.Actions[folder] {
+			src, err := filepath.Abs(r.FileInfo.Path)
+			if err != nil {
+				src = r.FileInfo.Path
+			}
+			link := uniquePath(filepath.Join(target, r.FileInfo.Name), func(p string) bool {
+				_, err := os.Lstat(osPath(p))
+				return err == nil
+			})
+			if err := os.Symlink(src, osPath(link)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", r.FileInfo.Name, err))
+				continue
+			}
+			created++
+		}
+	}
+	return created, errs
+}
+
+// clearLinks 删除目录中的符号链接和因此变空的子目录，目录不存在时什么也不做
+func clearLinks(dir string) error {
+	var dirs []string
+	err := filepath.WalkDir(osPath(dir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == osPath(dir) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			return os.Remove(path)
+		case d.IsDir() && path != osPath(dir):
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// 由深到浅删除空目录，非空目录删除失败时保留
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+	return nil
+}
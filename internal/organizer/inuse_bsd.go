@@ -0,0 +1,59 @@
+// Package organizer 文件整理模块
+// inuse_bsd.go - 通过 lsof 查找正被其他进程打开的文件（macOS、FreeBSD）
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build darwin || freebsd
+
+package organizer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// lsof 调用参数
+const (
+	lsofBatch   = 200              // 每次传给 lsof 的文件数
+	lsofTimeout = 10 * time.Second // 单次调用超时
+)
+
+// openFiles 返回 paths 中正被其他进程打开的文件
+// 没有 lsof 或调用超时时不做检查
+func openFiles(paths []string) map[string]bool {
+	lsof, err := exec.LookPath("lsof")
+	if err != nil {
+		return nil
+	}
+	wanted := absPaths(paths)
+	names := make([]string, 0, len(wanted))
+	for abs := range wanted {
+		names = append(names, abs)
+	}
+
+	inUse := make(map[string]bool)
+	for start := 0; start < len(names); start += lsofBatch {
+		end := start + lsofBatch
+		if end > len(names) {
+			end = len(names)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), lsofTimeout)
+		// -F n 只输出文件名（以 n 开头的行）；没有文件被打开时 lsof 以状态 1 退出，不视为错误
+		out, _ := exec.CommandContext(ctx, lsof, append([]string{"-F", "n", "--"}, names[start:end]...)...).Output()
+		cancel()
+		sc := bufio.NewScanner(bytes.NewReader(out))
+		for sc.Scan() {
+			if name, ok := strings.CutPrefix(sc.Text(), "n"); ok {
+				if path, ok := wanted[name]; ok {
+					inUse[path] = true
+				}
+			}
+		}
+	}
+	return inUse
+}
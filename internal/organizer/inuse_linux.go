@@ -0,0 +1,48 @@
+// Package organizer 文件整理模块
+// inuse_linux.go - 通过 /proc 查找正被其他进程打开的文件
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build linux
+
+package organizer
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// openFiles 返回 paths 中正被其他进程打开的文件
+// 遍历 /proc/<pid>/fd 下的符号链接；其他用户的进程没有权限读取，不在检查范围内
+func openFiles(paths []string) map[string]bool {
+	wanted := absPaths(paths)
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	self := strconv.Itoa(os.Getpid())
+	inUse := make(map[string]bool)
+	for _, p := range procs {
+		if _, err := strconv.Atoi(p.Name()); err != nil || p.Name() == self {
+			continue
+		}
+		fdDir := filepath.Join("/proc", p.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			if path, ok := wanted[target]; ok {
+				inUse[path] = true
+			}
+		}
+	}
+	return inUse
+}
@@ -0,0 +1,14 @@
+// Package organizer 文件整理模块
+// inuse_other.go - 不支持的平台上不检查文件是否正被使用
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build !linux && !darwin && !freebsd && !windows
+
+package organizer
+
+// openFiles 当前平台无法判断，视为都未被打开
+func openFiles(paths []string) map[string]bool {
+	return nil
+}
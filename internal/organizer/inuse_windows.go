@@ -0,0 +1,33 @@
+// Package organizer 文件整理模块
+// inuse_windows.go - 以独占方式打开文件，判断是否正被其他程序使用
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build windows
+
+package organizer
+
+import "golang.org/x/sys/windows"
+
+// openFiles 返回 paths 中正被其他程序打开的文件
+// 不共享任何访问权限地打开文件，其他程序打开着文件时返回共享冲突
+func openFiles(paths []string) map[string]bool {
+	inUse := make(map[string]bool)
+	for _, path := range paths {
+		name, err := windows.UTF16PtrFromString(osPath(path))
+		if err != nil {
+			continue
+		}
+		h, err := windows.CreateFile(name, windows.GENERIC_READ, 0, nil,
+			windows.OPEN_EXISTING, windows.FILE_ATTRIBUTE_NORMAL, 0)
+		if err == windows.ERROR_SHARING_VIOLATION {
+			inUse[path] = true
+			continue
+		}
+		if err == nil {
+			windows.CloseHandle(h)
+		}
+	}
+	return inUse
+}
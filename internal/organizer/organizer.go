@@ -12,11 +12,19 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/folderinfo"
+	"filo/internal/metrics"
+	"filo/internal/preview"
+	"filo/internal/scanner"
+	"filo/internal/sidecar"
 	"filo/internal/storage"
+	"filo/internal/taxonomy"
 	"filo/internal/ui"
 )
 
@@ -25,6 +33,22 @@ import (
 const (
 	MaxDisplayFiles       = 5   // 计划显示中每个分类最多显示的文件数
 	LowConfidenceThreshold = 0.7 // 低置信度阈值，低于此值需要审查
+	MaxSuggestions        = 9   // 交互修改分类时最多列出的已有分类数
+	ReviewFolder          = "待确认" // 低置信度文件的暂存文件夹
+	LogFlushSize          = 500   // 每移动多少个文件批量写入一次操作日志
+)
+
+// 低置信度文件的处理方式
+const (
+	LowConfidenceFile   = "file"   // 照常归档（默认）
+	LowConfidenceReview = "review" // 移入待确认文件夹并加入待确认队列
+	LowConfidenceKeep   = "keep"   // 留在原处并加入待确认队列
+)
+
+// 计划显示的分组方式
+const (
+	GroupByCategory = "category" // 按目标分类分组（默认）
+	GroupBySource   = "source"   // 按文件原所在目录分组
 )
 
 // ==================== 类型定义 ====================
@@ -32,8 +56,16 @@ const (
 // Plan 整理计划
 // 存储分类结果和目标目录信息
 type Plan struct {
-	TargetDir string                           // 目标目录（整理后文件存放位置）
-	Actions   map[string][]classifier.Result   // 分类动作：文件夹名 -> 文件列表
+	TargetDir    string                         // 目标目录（整理后文件存放位置）
+	SourceDir    string                         // 源目录（用于显示相对路径，可为空）
+	Actions      map[string][]classifier.Result // 分类动作：文件夹名 -> 文件列表
+	Review       []classifier.Result            // 低置信度、等待用户确认的文件
+	ReviewAction string                         // 待确认文件的处理方式: review / keep
+	QuotaNotes   []*QuotaNote                   // 超出容量上限（category_quotas）的分类文件夹
+	CopyAcross   bool                           // 跨设备时复制校验后删除源文件，未开启 cross_device_copy 时用于 filo retry --copy
+	Resolutions  map[string]string              // 交互模式中为重名文件选择的处理策略：源文件路径 -> 策略，未选择的按 conflict_strategy
+
+	usage map[string]*folderUsage // 设置了容量上限的分类文件夹的占用
 }
 
 // TotalFiles 计算计划中的总文件数
@@ -52,8 +84,19 @@ func (p *Plan) TotalFolders() int {
 
 // ExecuteResult 执行结果统计
 type ExecuteResult struct {
-	Success int // 成功移动的文件数
-	Errors  int // 失败的文件数
+	Success int      `json:"success"`           // 成功移动的文件数
+	Errors  int      `json:"errors"`            // 失败的文件数
+	Skipped []string `json:"skipped,omitempty"` // 因重名跳过、留在原处的文件
+	BatchID string   `json:"batch_id"`          // 批次 ID（用于撤销）
+
+	Failures []string `json:"failures,omitempty"` // 移动失败的文件及原因
+
+	Interrupted int `json:"interrupted,omitempty"` // 整理被取消时未处理、留在原处的文件数
+
+	ActionErrors int `json:"action_errors,omitempty"` // 分类操作（category_actions）执行失败的文件数
+
+	Aborted    string `json:"aborted,omitempty"`     // 执行前检查未通过或原子执行失败时的原因，此时没有文件留在新位置
+	RolledBack int    `json:"rolled_back,omitempty"` // 原子执行失败后移回原处的文件数
 }
 
 // ==================== 计划生成函数 ====================
@@ -67,36 +110,161 @@ func GeneratePlan(results []classifier.Result, targetDir string) *Plan {
 	}
 
 	for _, r := range results {
-		// 确定目标文件夹名称
-		var folder string
-		if r.Subcategory != "" && r.Subcategory != "其他" && r.Subcategory != "未知" {
-			// 有有效子分类时，使用两级目录: 主分类/子分类
-			folder = filepath.Join(r.Category, r.Subcategory)
-		} else {
-			// 否则只使用主分类
-			folder = r.Category
-		}
-
 		// 将文件添加到对应分类
-		plan.Actions[folder] = append(plan.Actions[folder], r)
+		plan.add(r)
 	}
 
 	return plan
 }
 
+// add 将分类结果加入对应的目标文件夹
+// 不区分大小写的文件系统上只有大小写不同的文件夹（如 Images 与 images）是同一个，
+// 合并到先出现的写法下，计划与磁盘上的结果一致；
+// 文件夹超出容量上限时按 category_quotas 放入按月份的子文件夹；返回文件夹名
+func (p *Plan) add(r classifier.Result) string {
+	folder := folderFor(r)
+	if _, ok := p.Actions[folder]; !ok && caseInsensitiveFS {
+		for existing := range p.Actions {
+			if pathKey(existing) == pathKey(folder) {
+				folder = existing
+				break
+			}
+		}
+	}
+	folder = p.applyQuota(folder, r)
+	p.Actions[folder] = append(p.Actions[folder], r)
+	return folder
+}
+
+// folderFor 确定分类结果的目标文件夹名称（相对目标目录）
+// 按分类路径逐级建立目录，如 工作/客户A/合同/2024；各级按 folder_language、folder_names 使用显示名称
+func folderFor(r classifier.Result) string {
+	path := r.Path()
+	// 末级为「其他」「未知」时不单独建立文件夹
+	if n := len(path); n > 1 && (path[n-1] == "其他" || path[n-1] == "未知") {
+		path = path[:n-1]
+	}
+	if len(path) == 0 {
+		path = []string{"未分类"} // 分类名清理后为空（如只含非法字符）
+	}
+	return filepath.Join(classifier.FolderNames(path)...)
+}
+
+// Entries 将计划转换为快照条目，路径相对于源目录
+// 待确认的文件按建议的分类记录
+func (p *Plan) Entries(sourceDir string) []storage.PlanEntry {
+	var entries []storage.PlanEntry
+	add := func(r classifier.Result) {
+		rel, err := filepath.Rel(sourceDir, r.FileInfo.Path)
+		if err != nil {
+			rel = r.FileInfo.Name
+		}
+		entries = append(entries, storage.PlanEntry{
+			Path:        rel,
+			Category:    r.Category,
+			Subcategory: r.Subcategory,
+			Confidence:  r.Confidence,
+			Source:      r.Source,
+		})
+	}
+	for _, files := range p.Actions {
+		for _, r := range files {
+			add(r)
+		}
+	}
+	for _, r := range p.Review {
+		add(r)
+	}
+	return entries
+}
+
 // ==================== 计划显示函数 ====================
 
-// PrintPlan 美观地打印整理计划
-// 显示目标目录、文件数量和分类详情
+// RelPath 文件相对源目录的路径
+// 未设置源目录或文件不在源目录下时，分别返回文件名和完整路径
+func (p *Plan) RelPath(r classifier.Result) string {
+	if p.SourceDir == "" {
+		return r.FileInfo.Name
+	}
+	return p.relPath(r.FileInfo.Path)
+}
+
+// relPath 路径相对源目录的形式，不在源目录下时返回完整路径
+func (p *Plan) relPath(path string) string {
+	if p.SourceDir == "" {
+		return path
+	}
+	rel, err := filepath.Rel(p.SourceDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// PrintPlan 美观地打印整理计划（按目标分类分组）
 func PrintPlan(plan *Plan) {
+	PrintPlanBy(plan, GroupByCategory)
+}
+
+// PrintPlanBy 美观地打印整理计划
+// 显示目标目录、文件数量，并按目标分类或原所在目录分组显示文件
+func PrintPlanBy(plan *Plan, groupBy string) {
+	// 静默模式不显示计划
+	if ui.IsQuiet() {
+		return
+	}
+
 	// 显示计划概览
+	collisions := plan.FindCollisions()
 	lines := []string{
 		fmt.Sprintf("📂 目标: %s", plan.TargetDir),
 		fmt.Sprintf("📄 文件: %d 个", plan.TotalFiles()),
 		fmt.Sprintf("📁 分类: %d 种", plan.TotalFolders()),
 	}
+	if len(plan.Review) > 0 {
+		lines = append(lines, fmt.Sprintf("❓ 待确认: %d 个", len(plan.Review)))
+	}
+	if len(collisions) > 0 {
+		lines = append(lines, fmt.Sprintf("⚠ 重名: %d 个", len(collisions)))
+	}
 	ui.Box("📋 整理计划", lines)
 
+	colliding := make(map[string]bool, len(collisions))
+	for _, c := range collisions {
+		colliding[c.Result.FileInfo.Path] = true
+	}
+	if groupBy == GroupBySource {
+		printBySource(plan, colliding)
+	} else {
+		printByCategory(plan, colliding)
+	}
+	printQuotaNotes(plan)
+	printCollisions(plan, collisions)
+
+	// 显示待确认的文件
+	if len(plan.Review) > 0 {
+		where := "留在原处"
+		if plan.ReviewAction == LowConfidenceReview {
+			where = "移入 " + ReviewFolder + "/"
+		}
+		fmt.Printf("\n  %s %s %s\n", ui.Yellow("❓"), ui.Bold("待确认"), ui.Gray(fmt.Sprintf("(%d个，%s，稍后运行 filo review)", len(plan.Review), where)))
+		for i, r := range plan.Review {
+			if i >= MaxDisplayFiles {
+				ui.Dim("      ... 还有 %d 个文件", len(plan.Review)-MaxDisplayFiles)
+				break
+			}
+			suggestion := fmt.Sprintf("→ %s/%s?", r.Category, r.Subcategory)
+			if alt := r.Conflict; alt != nil {
+				suggestion += fmt.Sprintf(" 记忆: %s/%s", alt.Category, alt.Subcategory)
+			}
+			fmt.Printf("      %s %s %s\n", ui.ConfidenceIcon(r.Confidence), plan.RelPath(r), ui.Gray(suggestion))
+		}
+	}
+	fmt.Println()
+}
+
+// printByCategory 按目标文件夹分组显示文件，重名的文件标出 ⚠
+func printByCategory(plan *Plan, colliding map[string]bool) {
 	// 按文件夹名排序显示
 	folders := make([]string, 0, len(plan.Actions))
 	for f := range plan.Actions {
@@ -119,7 +287,7 @@ func PrintPlan(plan *Plan) {
 			// 显示文件信息：置信度图标 + 来源图标 + 文件名
 			icon := ui.ConfidenceIcon(r.Confidence)
 			source := ui.SourceIcon(r.Source)
-			fmt.Printf("      %s %s %s\n", icon, source, r.FileInfo.Name)
+			fmt.Printf("      %s %s %s%s\n", icon, source, plan.RelPath(r), collisionMark(colliding, r))
 
 			// 显示分类理由（如果有）
 			if r.Reasoning != "" {
@@ -131,7 +299,110 @@ func PrintPlan(plan *Plan) {
 			}
 		}
 	}
-	fmt.Println()
+}
+
+// printBySource 按文件原所在目录分组显示文件及其目标文件夹
+// 递归整理时便于核对每个子目录中的文件将被移到哪里
+func printBySource(plan *Plan, colliding map[string]bool) {
+	type move struct {
+		result classifier.Result
+		folder string
+	}
+	groups := make(map[string][]move)
+	for folder, files := range plan.Actions {
+		for _, r := range files {
+			dir := filepath.Dir(plan.RelPath(r))
+			groups[dir] = append(groups[dir], move{r, folder})
+		}
+	}
+
+	dirs := make([]string, 0, len(groups))
+	for d := range groups {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		moves := groups[dir]
+		sort.Slice(moves, func(i, j int) bool { return moves[i].result.FileInfo.Name < moves[j].result.FileInfo.Name })
+		fmt.Printf("\n  %s %s %s\n", ui.Green("📂"), ui.Bold(dir+string(filepath.Separator)), ui.Gray(fmt.Sprintf("(%d个)", len(moves))))
+
+		for i, m := range moves {
+			if i >= MaxDisplayFiles {
+				ui.Dim("      ... 还有 %d 个文件", len(moves)-MaxDisplayFiles)
+				break
+			}
+			fmt.Printf("      %s %s %s %s%s\n", ui.ConfidenceIcon(m.result.Confidence), ui.SourceIcon(m.result.Source),
+				m.result.FileInfo.Name, ui.Gray("→ "+m.folder+string(filepath.Separator)), collisionMark(colliding, m.result))
+		}
+	}
+}
+
+// ParkForReview 将低置信度和来源矛盾的文件从计划中移出，等待稍后确认
+// action 为 review 或 keep 时生效，file 时不做任何处理；
+// threshold 返回主分类的置信度阈值，通常为 Config.ConfidenceThresholdFor
+func ParkForReview(plan *Plan, action string, threshold func(category string) float64) {
+	if action != LowConfidenceReview && action != LowConfidenceKeep {
+		return
+	}
+	plan.ReviewAction = action
+
+	for folder, files := range plan.Actions {
+		kept := files[:0]
+		for _, r := range files {
+			if needsReview(r, threshold) {
+				plan.Review = append(plan.Review, r)
+			} else {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) == 0 {
+			delete(plan.Actions, folder)
+		} else {
+			plan.Actions[folder] = kept
+		}
+	}
+}
+
+// PrintAutoSummary 汇总按置信度分层执行的结果（--auto-threshold）
+// 显示自动执行的文件数，未执行的文件按置信度每 10% 一段、以及记忆与 AI 分类矛盾的文件分别计数
+//
+// 参数:
+//   - plan: 已分出待确认文件的整理计划
+//   - threshold: 自动执行的置信度下限
+func PrintAutoSummary(plan *Plan, threshold float64) {
+	if len(plan.Review) == 0 {
+		ui.Success("全部 %d 个文件的置信度不低于 %.0f%%，自动执行", plan.TotalFiles(), threshold*100)
+		return
+	}
+	where := "留在原处"
+	if plan.ReviewAction == LowConfidenceReview {
+		where = "移入 " + ReviewFolder + "/"
+	}
+	ui.Info("自动执行 %d 个文件（置信度 ≥ %.0f%%），%d 个%s等待确认:", plan.TotalFiles(), threshold*100, len(plan.Review), where)
+
+	conflicts := 0
+	bands := make(map[int]int) // 置信度所在的 10% 段 -> 文件数
+	for _, r := range plan.Review {
+		if r.Conflict != nil {
+			conflicts++
+			continue
+		}
+		band := int(r.Confidence * 10)
+		if band > 9 {
+			band = 9
+		}
+		bands[band]++
+	}
+	for band := 9; band >= 0; band-- {
+		if n := bands[band]; n > 0 {
+			ui.Dim("  %3d%% - %3d%%  %d 个", band*10, band*10+10, n)
+		}
+	}
+	if conflicts > 0 {
+		ui.Dim("  记忆与 AI 分类矛盾  %d 个", conflicts)
+	}
+	ui.Dim("执行后运行 'filo review' 逐个确认")
 }
 
 // ==================== 交互审查函数 ====================
@@ -155,6 +426,7 @@ func InteractiveReview(plan *Plan, clf *classifier.Classifier) *Plan {
 				ui.Info("   分类: %s/%s", r.Category, r.Subcategory)
 				ui.Info("   置信度: %.0f%%", r.Confidence*100)
 				ui.Dim("   理由: %s", r.Reasoning)
+				preview.Print(r.FileInfo, config.Get().ReviewPreview)
 
 				// 获取用户输入
 				fmt.Print("  操作 [y/n/c/q]: ")
@@ -166,27 +438,25 @@ func InteractiveReview(plan *Plan, clf *classifier.Classifier) *Plan {
 					goto done // 结束审查
 				case "y":
 					clf.Confirm(r) // 确认分类，学习规则
+					// 用户已确认，不再视为低置信度
+					plan.Actions[folder][i].Confidence = 1.0
+					plan.Actions[folder][i].Source = "user"
 				case "c":
-					// 修改分类
-					fmt.Print("  新主分类: ")
-					newCat, _ := reader.ReadString('\n')
-					newCat = strings.TrimSpace(newCat)
-					if newCat == "" {
-						newCat = r.Category
-					}
-
-					fmt.Print("  新子分类: ")
-					newSub, _ := reader.ReadString('\n')
-					newSub = strings.TrimSpace(newSub)
-					if newSub == "" {
-						newSub = r.Subcategory
+					// 修改分类：优先从已有分类中选择，避免手输的错别字产生新分类
+					newCat, newSub, ok := chooseCategory(reader, r, clf)
+					if !ok {
+						ui.Dim("   未修改")
+						continue
 					}
 
 					// 学习纠正结果
+					newCat, newSub = classifier.Normalize(newCat, newSub)
 					clf.Correct(r, newCat, newSub)
 					// 更新计划中的分类
 					plan.Actions[folder][i].Category = newCat
 					plan.Actions[folder][i].Subcategory = newSub
+					plan.Actions[folder][i].Confidence = 1.0
+					plan.Actions[folder][i].Source = "user"
 					modified = true
 				}
 			}
@@ -200,23 +470,107 @@ done:
 		for _, files := range plan.Actions {
 			all = append(all, files...)
 		}
-		return GeneratePlan(all, plan.TargetDir)
+		newPlan := GeneratePlan(all, plan.TargetDir)
+		newPlan.SourceDir = plan.SourceDir
+		return newPlan
 	}
 	return plan
 }
 
+// chooseCategory 修改分类时让用户选择新分类
+// 列出历史中已有的分类（按与文件名的相似度排序），输入编号选择，
+// 也可以直接输入「主分类/子分类」；没有历史分类时逐项输入主分类和子分类
+// 直接回车表示不修改，返回 false
+func chooseCategory(reader *bufio.Reader, r classifier.Result, clf *classifier.Classifier) (string, string, bool) {
+	suggestions := clf.SuggestCategories(r, MaxSuggestions)
+	if len(suggestions) == 0 {
+		return typeCategory(reader, r, "")
+	}
+
+	for i, s := range suggestions {
+		hint := fmt.Sprintf("%d 个文件", s.Count)
+		if s.Similarity > 0 {
+			hint = fmt.Sprintf("相似 %.0f%%, %s", s.Similarity*100, hint)
+		}
+		fmt.Printf("    %s %s/%s %s\n", ui.Green(fmt.Sprintf("[%d]", i+1)), s.Category, s.Subcategory, ui.Gray("("+hint+")"))
+	}
+
+	for {
+		fmt.Print("  选择分类 [编号，或输入 主分类/子分类，回车取消]: ")
+		line, _ := reader.ReadString('\n')
+		input := strings.TrimSpace(line)
+		if input == "" {
+			return "", "", false
+		}
+		if n, err := strconv.Atoi(input); err == nil {
+			if n < 1 || n > len(suggestions) {
+				ui.Warning("编号超出范围: %d（共 %d 个）", n, len(suggestions))
+				continue
+			}
+			s := suggestions[n-1]
+			return s.Category, s.Subcategory, true
+		}
+		return typeCategory(reader, r, input)
+	}
+}
+
+// typeCategory 手动输入新分类
+// input 为已输入的分类路径（为空时先询问主分类）；只给出主分类时再询问子分类，
+// 未输入的部分沿用原分类
+func typeCategory(reader *bufio.Reader, r classifier.Result, input string) (string, string, bool) {
+	if input == "" {
+		fmt.Print("  新主分类: ")
+		line, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(line)
+		if input == "" {
+			input = r.Category
+		}
+	}
+	if strings.Contains(input, "/") {
+		return input, "", true // 多级分类路径由 Normalize 拆分
+	}
+
+	fmt.Print("  新子分类: ")
+	line, _ := reader.ReadString('\n')
+	newSub := strings.TrimSpace(line)
+	if newSub == "" {
+		newSub = r.Subcategory
+	}
+	return input, newSub, true
+}
+
 // ==================== 执行函数 ====================
 
 // Execute 执行整理计划
 // 创建目标目录并移动文件，返回执行结果统计
-// 同时记录操作日志，支持撤销功能
+// 同时记录操作日志，支持撤销功能。
+// 移动之前先做执行前检查：磁盘空间不足或目标文件夹不可写时不移动任何文件，
+// 正被其他程序使用的文件留在原处；原子执行（atomic）时这些文件同样使整批不执行，
+// 任何文件移动失败或整理被中断时已移动的文件全部移回原处
 func Execute(plan *Plan, clf *classifier.Classifier, verbose bool) ExecuteResult {
 	ui.Title("🚀", "执行整理")
 
-	result := ExecuteResult{}
-
-	// 生成批次 ID（用于撤销功能）
-	batchID := time.Now().Format("20060102_150405")
+	// 沿用分类器的批次 ID（用于撤销，事后纠正时据此调整模型准确度）
+	batchID := clf.GetBatchID()
+	result := ExecuteResult{BatchID: batchID}
+	start := time.Now()
+
+	// 执行前检查
+	atomic := config.Get().Atomic
+	check := preflight(plan)
+	printPreflight(plan, check, atomic)
+	if len(check.InUse) > 0 && atomic {
+		check.Problems = append(check.Problems, fmt.Sprintf("%d 个文件正被其他程序使用", len(check.InUse)))
+	}
+	if len(check.Problems) > 0 {
+		result.Aborted = check.Problems[0]
+		ui.Warning("执行前检查未通过，没有移动任何文件")
+		if db, err := storage.NewDatabase(); err == nil {
+			saveOutcome(db, plan, result, 0, nil, time.Since(start))
+			db.Close()
+		}
+		return result
+	}
 
 	// 初始化数据库连接（用于记录操作日志）
 	db, err := storage.NewDatabase()
@@ -230,63 +584,412 @@ func Execute(plan *Plan, clf *classifier.Classifier, verbose bool) ExecuteResult
 		}
 	}()
 
+	// 操作日志和已移动的文件批量写入数据库
+	// 原子执行时整批完成后才确认分类，并保留成功的操作记录用于回滚
+	var logs, done []storage.OperationLog
+	var moved []classifier.Result
+	folders := newFolderTracker(plan.TargetDir) // 放入了文件的分类文件夹及整理前是否已存在
+	failed := ""                                // 原子执行时第一个移动失败的文件
+	flush := func() {
+		if db != nil {
+			db.AddOperationLogs(logs)
+		}
+		if !atomic {
+			clf.ConfirmAll(moved) // 确认分类，学习规则
+			moved = moved[:0]
+		}
+		logs = logs[:0]
+	}
+
 	// 遍历每个分类
+	processed := 0
+actions:
 	for folder, files := range plan.Actions {
-		// 创建目标文件夹
-		targetFolder := filepath.Join(plan.TargetDir, folder)
-		os.MkdirAll(targetFolder, 0755)
-
 		// 移动文件
 		for _, r := range files {
-			src := r.FileInfo.Path
-			dst := filepath.Join(targetFolder, r.FileInfo.Name)
-
-			// 处理重名文件
-			dst = handleDuplicate(dst)
-
-			if verbose {
-				ui.Info("移动: %s", r.FileInfo.Name)
-				ui.Dim("  → %s", dst)
+			// 已取消：不再移动，已移动的文件照常记录日志
+			if clf.Interrupted() {
+				break actions
 			}
-
-			// 执行移动
-			if err := os.Rename(src, dst); err != nil {
+			processed++
+			var log storage.OperationLog
+			var err error
+			if check.InUse[r.FileInfo.Path] {
+				log = operationLog(batchID, r.FileInfo.Path, filepath.Join(plan.TargetDir, folder, r.FileInfo.Name), r, "skipped")
+				log.Resolution = ResolvedInUse
+			} else {
+				folders.before(folder)
+				log, err = moveFile(plan, folder, r, batchID, verbose)
+			}
+			logs = append(logs, log)
+			switch log.Status {
+			case "success":
+				result.Success++
+				moved = append(moved, r) // 成功移动后确认分类
+				folders.fill(folder)
+				recordQuarantine(db, log, r)
+				if PostActionFailed(log.PostActions) {
+					result.ActionErrors++
+				}
+				if atomic {
+					done = append(done, log)
+				}
+			case "skipped":
+				result.Skipped = append(result.Skipped, plan.RelPath(r))
+			default:
 				result.Errors++
-				if verbose {
-					ui.Error("失败: %v", err)
+				result.Failures = append(result.Failures, failure(plan.RelPath(r), err))
+				if atomic {
+					failed = plan.RelPath(r)
+					break actions
 				}
-				// 记录失败的操作
-				if db != nil {
-					db.AddOperationLog(batchID, src, dst, r.FileInfo.Name, r.Category, r.Subcategory, "failed")
+			}
+
+			// 定期落盘，中途中断时已移动的文件仍可撤销
+			if len(logs) >= LogFlushSize {
+				flush()
+			}
+		}
+	}
+	flush()
+
+	// 原子执行：有文件移动失败或被中断时全部移回原处
+	if atomic && (failed != "" || clf.Interrupted()) {
+		if failed != "" {
+			result.Aborted = fmt.Sprintf("%s 移动失败", failed)
+		} else {
+			result.Aborted = "整理已中断"
+			result.Interrupted = plan.TotalFiles() - processed + len(plan.Review)
+		}
+		rollbackExecute(db, done, &result)
+		saveOutcome(db, plan, result, 0, folders, time.Since(start))
+		printExecuteResult(result)
+		return result
+	}
+	clf.ConfirmAll(moved)
+	describeFolders(plan.TargetDir, folders.filled)
+
+	review := 0 // 进入待确认队列的文件数
+	if clf.Interrupted() {
+		// 待确认的文件也不再处理，批次标记为被中断
+		result.Interrupted = plan.TotalFiles() - processed + len(plan.Review)
+		if db != nil {
+			db.MarkRunPartial(batchID)
+		}
+	} else if len(plan.Review) > 0 && db != nil {
+		// 处理待确认的文件
+		review = parkForReview(plan, db, batchID, verbose)
+	}
+
+	saveOutcome(db, plan, result, review, folders, time.Since(start))
+	printExecuteResult(result)
+	return result
+}
+
+// rollbackExecute 原子执行失败后把已移动的文件移回原处并更新执行结果
+func rollbackExecute(db *storage.Database, done []storage.OperationLog, result *ExecuteResult) {
+	ui.Warning("原子执行: %s，将已移动的 %d 个文件移回原处", result.Aborted, len(done))
+	restored, failures := rollback(db, done, result.BatchID)
+	result.RolledBack = restored
+	result.Success -= restored
+	for i, f := range failures {
+		if i >= MaxDisplayFiles {
+			ui.Dim("  ... 还有 %d 个问题", len(failures)-MaxDisplayFiles)
+			break
+		}
+		ui.Error("未能移回: %s", f)
+	}
+}
+
+// moveFile 将文件移入目标目录下的分类文件夹
+// 自动创建文件夹、按交互模式中的选择或 conflict_strategy 处理重名，返回操作日志和移动失败的原因
+// 日志状态为 success、failed 或 skipped（重名跳过或云端文件不移出同步目录，文件留在原处）
+func moveFile(plan *Plan, folder string, r classifier.Result, batchID string, verbose bool) (storage.OperationLog, error) {
+	targetFolder := filepath.Join(plan.TargetDir, folder)
+	src := r.FileInfo.Path
+
+	// 仅在云端的文件移出同步目录会先触发完整下载
+	if r.FileInfo.Cloud != "" && config.Get().SkipUnsynced && !scanner.SameSyncRoot(src, targetFolder) {
+		if verbose {
+			ui.Info("跳过: %s", plan.RelPath(r))
+			ui.Dim("    %s", resolutionLabels[ResolvedUnsynced])
+		}
+		log := operationLog(batchID, src, filepath.Join(targetFolder, r.FileInfo.Name), r, "skipped")
+		log.Resolution = ResolvedUnsynced
+		metrics.ObserveMove(log.Status)
+		return log, nil
+	}
+
+	// 创建目标文件夹
+	os.MkdirAll(osPath(targetFolder), 0755)
+	// 处理重名文件
+	backup := filepath.Join(plan.TargetDir, ReplacedFolder, batchID, folder, r.FileInfo.Name)
+	c := resolveConflict(plan.strategyFor(r), r, filepath.Join(targetFolder, r.FileInfo.Name), backup)
+	if c.resolution == ResolvedIdentical {
+		c.backup = trashPath(batchID, folder, r.FileInfo.Name)
+	}
+
+	if verbose {
+		ui.Info("移动: %s", plan.RelPath(r))
+		ui.Dim("  → %s", c.dst)
+		if c.resolution != "" {
+			ui.Dim("    %s", resolutionLabels[c.resolution])
+		}
+		if c.resolution == ResolvedIdentical && c.backup != "" {
+			ui.Dim("    源文件移入回收站，%d 天后由 filo purge 删除", config.Get().SoftDeleteDays)
+		}
+	}
+
+	status, transfer := "success", TransferRename
+	var err error
+	if c.resolution == ResolvedSkipped {
+		status = "skipped"
+	} else if transfer, err = c.apply(src, plan.CopyAcross || config.Get().CrossDeviceCopy); err != nil {
+		if verbose {
+			ui.Error("失败: %v", err)
+		}
+		status = "failed"
+	} else if r.FileInfo.Symlink != "" && c.resolution != ResolvedIdentical {
+		// 符号链接移动后改为指向绝对路径，相对路径的链接换了文件夹会失效
+		if err := relinkAbsolute(c.dst, src, r.FileInfo.Symlink); err != nil && verbose {
+			ui.Warning("    符号链接改为绝对路径失败: %v", err)
+		}
+	}
+	if transfer == TransferCopy && status == "success" && verbose {
+		ui.Dim("    %s", transferLabels[TransferCopy])
+	}
+
+	// 执行分类操作（目标位置是原有的文件时不处理）
+	dst, postActions := c.dst, ""
+	if status == "success" && c.resolution != ResolvedIdentical {
+		if actions := ActionsFor(r.Path()); len(actions) > 0 {
+			var failed bool
+			dst, postActions, failed = runPostActions(dst, actions)
+			if verbose && postActions != "" {
+				if failed {
+					ui.Warning("    %s", postActions)
+				} else {
+					ui.Dim("    %s", postActions)
 				}
-			} else {
-				result.Success++
-				clf.Confirm(r) // 成功移动后确认分类，学习规则
-				// 记录成功的操作（用于撤销）
-				if db != nil {
-					db.AddOperationLog(batchID, src, dst, r.FileInfo.Name, r.Category, r.Subcategory, "success")
+			}
+		}
+	}
+
+	// 整理信息随文件保存（目标位置是原有的文件时不处理）
+	if status == "success" && c.resolution != ResolvedIdentical {
+		if err := writeSidecar(batchID, src, dst, r); err != nil && verbose {
+			ui.Warning("    保存整理信息失败: %v", err)
+		}
+	}
+
+	// 记录操作（成功的操作用于撤销）
+	log := operationLog(batchID, src, dst, r, status)
+	log.Resolution = c.resolution
+	log.ReplacedPath = c.backup
+	log.PostActions = postActions
+	log.Transfer = transfer
+	if err != nil {
+		log.Error = err.Error()
+	}
+	metrics.ObserveMove(status)
+	return log, err
+}
+
+// recordQuarantine 记录移入隔离区的文件及其哈希
+func recordQuarantine(db *storage.Database, log storage.OperationLog, r classifier.Result) {
+	if db == nil || r.Source != "quarantine" {
+		return
+	}
+	db.AddQuarantineRecord(storage.QuarantineRecord{
+		SHA256:     r.FileInfo.SHA256,
+		Filename:   r.FileInfo.Name,
+		Size:       r.FileInfo.Size,
+		SourcePath: log.SourcePath,
+		DestPath:   log.DestPath,
+		Kind:       r.Subcategory,
+		BatchID:    log.BatchID,
+	})
+}
+
+// writeSidecar 按 sidecar 配置在文件旁或扩展属性中保存整理信息
+// 文件原来位置旁的 JSON 文件（上次整理时写入）一并删除；符号链接不处理
+func writeSidecar(batchID, src, dst string, r classifier.Result) error {
+	format := config.Get().Sidecar
+	if format == sidecar.FormatNone || r.FileInfo.Symlink != "" {
+		return nil
+	}
+	os.Remove(osPath(sidecar.Path(src)))
+	return sidecar.Write(osPath(dst), sidecar.Metadata{
+		Category:    r.Category,
+		Subcategory: r.Subcategory,
+		Confidence:  r.Confidence,
+		Source:      r.Source,
+		BatchID:     batchID,
+		SourcePath:  src,
+		OrganizedAt: time.Now(),
+	}, format)
+}
+
+// describeFolders 为放入了文件的分类文件夹逐级写入说明文件，并设置主分类文件夹的外观
+// 由 folder_info 和 folder_appearance 配置控制，失败时只给出警告
+func describeFolders(targetDir string, folders map[string]bool) {
+	cfg := config.Get()
+	if folderinfo.FileName(cfg.FolderInfo) == "" && !cfg.FolderAppearance {
+		return
+	}
+
+	done := make(map[string]bool)
+	var errs []string
+	for folder := range folders {
+		path := strings.Split(filepath.ToSlash(folder), "/")
+		for i := range path {
+			rel := filepath.Join(path[:i+1]...)
+			if done[rel] {
+				continue
+			}
+			done[rel] = true
+
+			dir := filepath.Join(targetDir, rel)
+			if _, err := folderinfo.Write(dir, path[:i+1], cfg.FolderInfo); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", rel, err))
+			}
+			if i == 0 && cfg.FolderAppearance {
+				if err := folderinfo.SetAppearance(dir, taxonomy.Get().Find(classifier.CategoryName(path[0]))); err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %v", rel, err))
 				}
 			}
 		}
 	}
+	if len(errs) > 0 {
+		ui.Warning("%d 个分类文件夹的说明或外观未能设置: %s", len(errs), errs[0])
+	}
+}
 
-	// 显示执行结果
-	fmt.Println()
+// printExecuteResult 显示执行结果
+func printExecuteResult(result ExecuteResult) {
+	if !ui.IsQuiet() {
+		fmt.Println()
+	}
 	ui.Success("成功: %d 个文件", result.Success)
-	if result.Errors > 0 {
+	if result.RolledBack > 0 {
+		ui.Warning("已回滚: %d 个文件移回原处", result.RolledBack)
+	}
+	if len(result.Skipped) > 0 {
+		ui.Warning("跳过: %d 个文件（重名、未下载或正被使用，留在原处）", len(result.Skipped))
+		for i, path := range result.Skipped {
+			if i >= MaxDisplayFiles {
+				ui.Dim("  ... 还有 %d 个文件", len(result.Skipped)-MaxDisplayFiles)
+				break
+			}
+			ui.Dim("  %s", path)
+		}
+	}
+	if result.Errors > 0 && result.Aborted == "" {
+		ui.Error("失败: %d 个文件（可用 'filo retry' 重试）", result.Errors)
+	} else if result.Errors > 0 {
 		ui.Error("失败: %d 个文件", result.Errors)
 	}
-	ui.Dim("批次: %s (可用 'filo undo' 撤销)", batchID)
+	if result.ActionErrors > 0 {
+		ui.Warning("分类操作失败: %d 个文件（文件已移动，用 filo last 查看原因）", result.ActionErrors)
+	}
+	if result.Interrupted > 0 {
+		ui.Warning("已中断: %d 个文件未处理，留在原处", result.Interrupted)
+	}
+	ui.Dim("批次: %s (可用 'filo undo' 撤销)", result.BatchID)
+}
 
-	return result
+// operationLog 构造一条操作日志
+func operationLog(batchID, src, dst string, r classifier.Result, status string) storage.OperationLog {
+	return storage.OperationLog{
+		BatchID:     batchID,
+		SourcePath:  src,
+		DestPath:    dst,
+		Filename:    r.FileInfo.Name,
+		Category:    r.Category,
+		Subcategory: r.Subcategory,
+		Status:      status,
+		Source:      r.Source,
+	}
+}
+
+// parkForReview 处理待确认文件
+// review 模式移入待确认文件夹，keep 模式留在原处，两种方式都加入待确认队列，返回入队的文件数
+func parkForReview(plan *Plan, db *storage.Database, batchID string, verbose bool) int {
+	parked := 0
+	for _, r := range plan.Review {
+		if parkFile(plan, db, batchID, r, verbose) {
+			parked++
+		}
+	}
+
+	if parked > 0 {
+		ui.Warning("待确认: %d 个文件，运行 'filo review' 处理", parked)
+	}
+	return parked
+}
+
+// parkFile 处理单个待确认文件，返回是否已加入待确认队列
+func parkFile(plan *Plan, db *storage.Database, batchID string, r classifier.Result, verbose bool) bool {
+	path := r.FileInfo.Path
+
+	if plan.ReviewAction == LowConfidenceReview {
+		reviewDir := filepath.Join(plan.TargetDir, ReviewFolder)
+		os.MkdirAll(osPath(reviewDir), 0755)
+		dst := handleDuplicate(filepath.Join(reviewDir, r.FileInfo.Name))
+		// 目标目录可能在另一块磁盘上，与整理时一样按 cross_device_copy 复制校验后删除源文件
+		if _, err := moveAcross(path, dst, plan.CopyAcross || config.Get().CrossDeviceCopy); err != nil {
+			if verbose {
+				ui.Error("移入待确认失败: %s: %v", r.FileInfo.Name, err)
+			}
+			return false
+		}
+		// 记录操作日志，撤销时一并移回
+		db.AddOperationLog(batchID, path, dst, r.FileInfo.Name, ReviewFolder, "", "success")
+		path = dst
+	}
+
+	item := storage.ReviewItem{
+		FilePath:    path,
+		Filename:    r.FileInfo.Name,
+		TargetDir:   plan.TargetDir,
+		Category:    r.Category,
+		Subcategory: r.Subcategory,
+		Confidence:  r.Confidence,
+		Reasoning:   r.Reasoning,
+		Source:      r.Source,
+		BatchID:     batchID,
+		Reason:      storage.ReviewLowConfidence,
+	}
+	if alt := r.Conflict; alt != nil {
+		item.Reason = storage.ReviewConflict
+		item.AltCategory, item.AltSubcategory = alt.Category, alt.Subcategory
+		item.AltConfidence, item.AltSource = alt.Confidence, alt.Source
+	}
+	db.AddReviewItem(item)
+	return true
+}
+
+// needsReview 文件是否需要等待确认：置信度低于主分类的阈值，或记忆与 AI 的分类矛盾
+func needsReview(r classifier.Result, threshold func(category string) float64) bool {
+	return r.Confidence < threshold(r.Category) || r.Conflict != nil
 }
 
 // handleDuplicate 处理重名文件
 // 如果目标路径已存在文件，自动添加数字后缀
 // 例如: file.txt -> file_1.txt -> file_2.txt
+// 是否存在由文件系统判断：不区分大小写时 Report.pdf 已存在，report.pdf 同样视为重名
 func handleDuplicate(path string) string {
+	return uniquePath(path, func(p string) bool {
+		_, err := os.Stat(osPath(p))
+		return !os.IsNotExist(err)
+	})
+}
+
+// uniquePath 为已存在的路径添加数字后缀，直到 exists 返回 false
+// 磁盘执行和模拟执行（虚拟文件系统）共用同一套重名规则
+func uniquePath(path string, exists func(string) bool) string {
 	// 文件不存在，直接返回原路径
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	if !exists(path) {
 		return path
 	}
 
@@ -298,7 +1001,7 @@ func handleDuplicate(path string) string {
 	// 尝试添加数字后缀
 	for i := 1; ; i++ {
 		newPath := filepath.Join(dir, fmt.Sprintf("%s_%d%s", name, i, ext))
-		if _, err := os.Stat(newPath); os.IsNotExist(err) {
+		if !exists(newPath) {
 			return newPath
 		}
 	}
@@ -0,0 +1,85 @@
+// Package organizer 文件整理模块
+// outcome.go - 执行结果的记录：移动、失败、跳过和待确认的文件数，新建的分类文件夹和失败原因
+// 写入批次的运行摘要，关闭终端后仍可用 filo last 查看
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"filo/internal/storage"
+)
+
+// folderTracker 记录分类文件夹在整理前是否已存在，用于找出本次新建的分类
+type folderTracker struct {
+	targetDir string
+	existed   map[string]bool // 分类文件夹 -> 整理前是否已存在
+	filled    map[string]bool // 放入了文件的分类文件夹
+}
+
+// newFolderTracker 创建分类文件夹记录
+func newFolderTracker(targetDir string) *folderTracker {
+	return &folderTracker{targetDir: targetDir, existed: make(map[string]bool), filled: make(map[string]bool)}
+}
+
+// before 在第一次向文件夹移动文件前调用，记录文件夹是否已存在
+func (t *folderTracker) before(folder string) {
+	if _, ok := t.existed[folder]; !ok {
+		_, err := os.Stat(osPath(filepath.Join(t.targetDir, folder)))
+		t.existed[folder] = err == nil
+	}
+}
+
+// fill 记录文件已成功放入文件夹
+func (t *folderTracker) fill(folder string) {
+	t.filled[folder] = true
+}
+
+// created 返回本次新建并放入了文件的分类文件夹（以 / 分隔，按路径排序）
+func (t *folderTracker) created() []string {
+	var folders []string
+	for folder := range t.filled {
+		if !t.existed[folder] {
+			folders = append(folders, filepath.ToSlash(folder))
+		}
+	}
+	sort.Strings(folders)
+	return folders
+}
+
+// failure 格式化失败的文件及原因
+func failure(relPath string, err error) string {
+	if err == nil {
+		return relPath
+	}
+	return fmt.Sprintf("%s: %v", relPath, err)
+}
+
+// saveOutcome 把执行结果写入批次的运行摘要，数据库不可用时跳过
+func saveOutcome(db *storage.Database, plan *Plan, result ExecuteResult, review int, folders *folderTracker, elapsed time.Duration) {
+	if db == nil {
+		return
+	}
+	outcome := storage.RunOutcome{
+		SourceDir: plan.SourceDir,
+		TargetDir: plan.TargetDir,
+		Moved:     result.Success,
+		Failed:    result.Errors,
+		Skipped:   len(result.Skipped),
+		Review:    review,
+		Failures:  result.Failures,
+		Aborted:   result.Aborted,
+		ExecTime:  elapsed,
+	}
+	if folders != nil && result.Aborted == "" {
+		outcome.CategoriesCreated = folders.created()
+	}
+	db.SaveRunOutcome(result.BatchID, outcome)
+}
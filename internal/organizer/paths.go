@@ -0,0 +1,69 @@
+// Package organizer 文件整理模块
+// paths.go - 跨平台路径处理：长路径和不区分大小写的文件系统
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// caseInsensitiveFS 当前平台的文件系统默认是否不区分大小写（Windows NTFS、macOS APFS）
+// 此时 Report.pdf 与 report.pdf 是同一个文件，Images 与 images 是同一个文件夹
+var caseInsensitiveFS = runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+
+// pathKey 返回用于比较路径是否相同的键
+// 不区分大小写的平台上统一转为小写
+func pathKey(path string) string {
+	if caseInsensitiveFS {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// sameFile 两个路径是否为同一个目录项
+// 不区分大小写的文件系统上，只有大小写不同的路径指向同一个文件；
+// 同一个文件的两个硬链接、符号链接与其指向的文件是不同的目录项，不算同一个
+func sameFile(a, b string) bool {
+	if abs, err := filepath.Abs(a); err == nil {
+		a = abs
+	}
+	if abs, err := filepath.Abs(b); err == nil {
+		b = abs
+	}
+	if !strings.EqualFold(a, b) {
+		return false
+	}
+	ai, err := os.Lstat(osPath(a))
+	if err != nil {
+		return false
+	}
+	bi, err := os.Lstat(osPath(b))
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
+
+// relinkAbsolute 把移动后的符号链接改为指向绝对路径
+// 相对路径的链接移到其他文件夹后会失效，按链接原来所在的目录解析出绝对路径后重建
+//
+// 参数:
+//   - link: 移动后的链接路径
+//   - oldPath: 移动前的链接路径
+//   - target: 链接原来的目标（Readlink 的结果）
+func relinkAbsolute(link, oldPath, target string) error {
+	if target == "" || filepath.IsAbs(target) {
+		return nil
+	}
+	abs := filepath.Join(filepath.Dir(oldPath), target)
+	if err := os.Remove(osPath(link)); err != nil {
+		return err
+	}
+	return os.Symlink(abs, osPath(link))
+}
@@ -0,0 +1,12 @@
+// Package organizer 文件整理模块
+// paths_other.go - 非 Windows 平台没有路径长度限制
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build !windows
+
+package organizer
+
+// osPath 返回传给系统调用的路径，非 Windows 平台原样返回
+func osPath(path string) string { return path }
@@ -0,0 +1,35 @@
+// Package organizer 文件整理模块
+// paths_windows.go - Windows 长路径
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build windows
+
+package organizer
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxShortPath 不加前缀时可用的最大路径长度
+// 创建目录时的限制比 MAX_PATH（260）少 12 个字符，留给 8.3 格式的文件名
+const maxShortPath = 248
+
+// osPath 返回传给系统调用的路径
+// 超过长度限制时转为绝对路径并加 \\?\ 前缀（网络路径为 \\?\UNC\），
+// 不受 MAX_PATH 限制；记录到数据库和显示的路径不加前缀
+func osPath(path string) string {
+	if len(path) < maxShortPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,193 @@
+// Package organizer 文件整理模块
+// pipeline.go - 流水线执行：分类与移动同时进行
+// 记忆命中的文件在 AI 分类进行时就开始移动，大目录不必等全部分类完成
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"path/filepath"
+	"time"
+
+	"filo/internal/classifier"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// PipelineBuffer 分类器与执行端之间的通道容量
+// 执行端跟不上时分类器会阻塞等待，避免大量结果堆积在内存中
+const PipelineBuffer = 64
+
+// NewPlan 创建空的整理计划
+// 流水线模式下边执行边填充，执行结束后可用于保存快照
+func NewPlan(targetDir string) *Plan {
+	return &Plan{
+		TargetDir: targetDir,
+		Actions:   make(map[string][]classifier.Result),
+	}
+}
+
+// ExecuteStream 从通道接收分类结果并逐个执行，直到通道关闭
+// 低于 threshold 的文件按 action 处理（review / keep 加入待确认队列，file 照常归档）；
+// 每次取出通道中已送达的全部结果，移动之前与 Execute 一样做执行前检查：正被其他程序使用的文件留在原处，
+// 磁盘空间不足或目标文件夹不可写时不再移动之后的文件（已移动的保留，可撤销）；
+// 每个文件移动成功后立即确认分类；通道暂时为空或累计 LogFlushSize 条时写入操作日志，
+// 中途中断时已移动的文件仍可撤销。执行过的文件同时记录到 plan 中
+func ExecuteStream(plan *Plan, in <-chan classifier.Result, clf *classifier.Classifier,
+	action string, threshold func(category string) float64, verbose bool) ExecuteResult {
+	if action == LowConfidenceReview || action == LowConfidenceKeep {
+		plan.ReviewAction = action
+	}
+
+	// 沿用分类器的批次 ID（用于撤销，事后纠正时据此调整模型准确度）
+	batchID := clf.GetBatchID()
+	result := ExecuteResult{BatchID: batchID}
+
+	// 初始化数据库连接（用于记录操作日志）
+	db, err := storage.NewDatabase()
+	if err != nil {
+		ui.Error("无法记录操作日志: %v", err)
+		// 继续执行，但无法撤销
+	}
+	defer func() {
+		if db != nil {
+			db.Close()
+		}
+	}()
+
+	var logs []storage.OperationLog
+	flush := func() {
+		if db != nil {
+			db.AddOperationLogs(logs)
+		}
+		logs = logs[:0]
+	}
+
+	parked := 0
+	folders := newFolderTracker(plan.TargetDir) // 放入了文件的分类文件夹及整理前是否已存在
+	stopped := false                            // 执行前检查未通过，之后的文件留在原处
+	for chunk := range chunks(in) {
+		// 已取消：分类器会尽快结束，已送达的结果不再移动
+		if clf.Interrupted() || stopped {
+			result.Interrupted += len(chunk)
+			continue
+		}
+		check := preflightChunk(plan, chunk, threshold)
+		printPreflight(plan, check, false)
+		if len(check.Problems) > 0 {
+			stopped = true
+			result.Interrupted += len(chunk)
+			ui.Warning("执行前检查未通过，不再移动之后的文件")
+			if result.Success > 0 {
+				ui.Dim("已移动的 %d 个文件保留，可用 'filo undo' 撤销", result.Success)
+			}
+			continue
+		}
+
+		for _, r := range chunk {
+			// 低置信度和来源矛盾的文件等待稍后确认
+			if plan.ReviewAction != "" && needsReview(r, threshold) {
+				plan.Review = append(plan.Review, r)
+				if db != nil && parkFile(plan, db, batchID, r, verbose) {
+					parked++
+				}
+				continue
+			}
+
+			folder := plan.add(r)
+
+			var log storage.OperationLog
+			var err error
+			if check.InUse[r.FileInfo.Path] {
+				log = operationLog(batchID, r.FileInfo.Path, filepath.Join(plan.TargetDir, folder, r.FileInfo.Name), r, "skipped")
+				log.Resolution = ResolvedInUse
+			} else {
+				folders.before(folder)
+				log, err = moveFile(plan, folder, r, batchID, verbose)
+			}
+			logs = append(logs, log)
+			switch log.Status {
+			case "success":
+				result.Success++
+				folders.fill(folder)
+				clf.Confirm(r) // 成功移动后确认分类，学习规则
+				recordQuarantine(db, log, r)
+				if PostActionFailed(log.PostActions) {
+					result.ActionErrors++
+				}
+			case "skipped":
+				result.Skipped = append(result.Skipped, plan.RelPath(r))
+			default:
+				result.Errors++
+				result.Failures = append(result.Failures, failure(plan.RelPath(r), err))
+			}
+
+			if len(logs) >= LogFlushSize {
+				flush()
+			}
+		}
+
+		// 分类器暂时没有新结果（如等待 AI 返回）时落盘
+		if len(in) == 0 {
+			flush()
+		}
+	}
+	// 分类期间的移动已计入分类耗时，执行耗时只算分类结束之后的部分
+	tail := time.Now()
+	flush()
+	describeFolders(plan.TargetDir, folders.filled)
+	if (clf.Interrupted() || stopped) && db != nil {
+		db.MarkRunPartial(batchID)
+	}
+	saveOutcome(db, plan, result, parked, folders, time.Since(tail))
+
+	// 分类期间的输出与移动交错，结束后统一显示执行结果
+	ui.Title("🚀", "执行整理")
+	if parked > 0 {
+		ui.Warning("待确认: %d 个文件，运行 'filo review' 处理", parked)
+	}
+	printExecuteResult(result)
+	return result
+}
+
+// chunks 把通道中的分类结果按批取出：先阻塞等待一个结果，再取出通道中已送达的其余结果，
+// 每批不超过 PipelineBuffer 个；通道关闭后结束
+func chunks(in <-chan classifier.Result) <-chan []classifier.Result {
+	out := make(chan []classifier.Result)
+	go func() {
+		defer close(out)
+		for r := range in {
+			chunk := []classifier.Result{r}
+		drain:
+			for len(chunk) < PipelineBuffer {
+				select {
+				case next, ok := <-in:
+					if !ok {
+						break drain
+					}
+					chunk = append(chunk, next)
+				default:
+					break drain
+				}
+			}
+			out <- chunk
+		}
+	}()
+	return out
+}
+
+// preflightChunk 对即将执行的一批分类结果做执行前检查，待确认的文件按 review 方式计入
+func preflightChunk(plan *Plan, chunk []classifier.Result, threshold func(category string) float64) preflightResult {
+	p := &Plan{TargetDir: plan.TargetDir, ReviewAction: plan.ReviewAction, Actions: make(map[string][]classifier.Result)}
+	for _, r := range chunk {
+		if plan.ReviewAction != "" && needsReview(r, threshold) {
+			p.Review = append(p.Review, r)
+			continue
+		}
+		folder := folderFor(r)
+		p.Actions[folder] = append(p.Actions[folder], r)
+	}
+	return preflight(p)
+}
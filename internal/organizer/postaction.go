@@ -0,0 +1,296 @@
+// Package organizer 文件整理模块
+// postaction.go - 分类操作：文件移入分类文件夹后按 category_actions 配置执行的操作
+// （压缩、HEIC 转 JPG、设为只读），每个文件的执行结果记录在操作日志中，撤销时据此还原
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/taxonomy"
+)
+
+// ==================== 常量定义 ====================
+
+// 分类操作（category_actions）
+const (
+	ActionHEICToJPG = "heic-to-jpg" // HEIC/HEIF 照片另存一份 JPG，原文件保留
+	ActionCompress  = "compress"    // gzip 压缩为 文件名.gz，删除原文件
+	ActionReadOnly  = "readonly"    // 去掉写权限
+)
+
+// PostActions 所有分类操作，按执行顺序排列（先转换、再压缩、最后设为只读）
+var PostActions = []string{ActionHEICToJPG, ActionCompress, ActionReadOnly}
+
+// actionOK 操作成功时记录的结果
+const actionOK = "ok"
+
+// errNotApplicable 操作不适用于该文件（如非 HEIC 文件转 JPG），不记录结果
+var errNotApplicable = errors.New("不适用")
+
+// ==================== 执行 ====================
+
+// ActionsFor 返回分类路径对应的操作，按执行顺序排列
+// 配置的键为分类路径，作用于该分类及其下级分类，如 图片 同时作用于 图片/照片
+func ActionsFor(path []string) []string {
+	configured := config.Get().CategoryActions
+	if len(configured) == 0 {
+		return nil
+	}
+
+	wanted := make(map[string]bool)
+	full := strings.Join(path, classifier.PathSep)
+	for key, actions := range configured {
+		key = strings.Trim(filepath.ToSlash(key), "/")
+		if key != "" && (full == key || strings.HasPrefix(full, key+"/")) {
+			for _, a := range actions {
+				wanted[strings.ToLower(a)] = true
+			}
+		}
+	}
+
+	var actions []string
+	for _, a := range PostActions {
+		if wanted[a] {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// runPostActions 对移入分类文件夹的文件执行分类操作
+// 压缩后文件路径变为 .gz；结果写成 "操作=ok" 或 "操作=失败原因"，以 "; " 分隔，
+// 不适用于该文件的操作不记录
+//
+// 参数:
+//   - dst: 文件移入后的路径
+//   - actions: 要执行的操作（见 ActionsFor）
+//
+// 返回值:
+//   - string: 文件最终的路径
+//   - string: 执行结果，没有执行任何操作时为空
+//   - bool: 是否有操作失败
+func runPostActions(dst string, actions []string) (string, string, bool) {
+	var results []string
+	failed := false
+	for _, action := range actions {
+		var err error
+		switch action {
+		case ActionHEICToJPG:
+			err = convertHEIC(dst)
+		case ActionCompress:
+			var gz string
+			if gz, err = gzipFile(dst); err == nil {
+				dst = gz
+			}
+		case ActionReadOnly:
+			err = setReadOnly(dst)
+		}
+		switch {
+		case errors.Is(err, errNotApplicable):
+			continue
+		case err != nil:
+			results = append(results, action+"="+err.Error())
+			failed = true
+		default:
+			results = append(results, action+"="+actionOK)
+		}
+	}
+	return dst, strings.Join(results, "; "), failed
+}
+
+// appliedActions 解析操作日志中成功执行的操作
+func appliedActions(postActions string) map[string]bool {
+	applied := make(map[string]bool)
+	for _, item := range strings.Split(postActions, "; ") {
+		if name, result, ok := strings.Cut(item, "="); ok && result == actionOK {
+			applied[name] = true
+		}
+	}
+	return applied
+}
+
+// PostActionFailed 判断操作日志中是否有执行失败的分类操作
+func PostActionFailed(postActions string) bool {
+	for _, item := range strings.Split(postActions, "; ") {
+		if _, result, ok := strings.Cut(item, "="); ok && result != actionOK {
+			return true
+		}
+	}
+	return false
+}
+
+// changedFile 判断分类操作是否改变了文件（压缩或生成了 JPG），
+// 这类文件无法单独改放到其他分类或打包归档
+func changedFile(postActions string) bool {
+	applied := appliedActions(postActions)
+	return applied[ActionCompress] || applied[ActionHEICToJPG]
+}
+
+// ==================== 各项操作 ====================
+
+// heicExts HEIC/HEIF 扩展名
+var heicExts = map[string]bool{".heic": true, ".heif": true}
+
+// convertHEIC 将 HEIC 照片另存为同名 JPG（原文件保留）
+// 依次尝试 sips（macOS 自带）、heif-convert（libheif）和 ImageMagick
+func convertHEIC(path string) error {
+	if !heicExts[strings.ToLower(filepath.Ext(path))] {
+		return errNotApplicable
+	}
+	jpg := heicJPGPath(path)
+	if _, err := os.Stat(osPath(jpg)); err == nil {
+		return fmt.Errorf("已有同名 JPG")
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case lookPath("sips"):
+		cmd = exec.Command("sips", "-s", "format", "jpeg", osPath(path), "--out", osPath(jpg))
+	case lookPath("heif-convert"):
+		cmd = exec.Command("heif-convert", osPath(path), osPath(jpg))
+	case lookPath("magick"):
+		cmd = exec.Command("magick", osPath(path), osPath(jpg))
+	default:
+		return fmt.Errorf("未找到 sips / heif-convert / magick")
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(osPath(jpg))
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("转换失败: %s", firstLine(msg))
+		}
+		return fmt.Errorf("转换失败: %v", err)
+	}
+	return nil
+}
+
+// heicJPGPath HEIC 照片转换后的 JPG 路径
+func heicJPGPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".jpg"
+}
+
+// gzipFile 将文件压缩为 路径.gz 并删除原文件，保留修改时间
+// 已是压缩格式的文件不再压缩；已有同名 .gz 文件时不压缩，保证撤销时能找回原文件名
+func gzipFile(path string) (string, error) {
+	if taxonomy.ArchiveExts[strings.ToLower(filepath.Ext(path))] {
+		return "", errNotApplicable
+	}
+	gz := path + ".gz"
+	if _, err := os.Stat(osPath(gz)); err == nil {
+		return "", fmt.Errorf("已有同名 .gz 文件")
+	}
+
+	in, err := os.Open(osPath(path))
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return "", err
+	}
+	out, err := os.OpenFile(osPath(gz), os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return "", err
+	}
+
+	w := gzip.NewWriter(out)
+	w.Name = filepath.Base(path)
+	w.ModTime = info.ModTime()
+	_, err = io.Copy(w, in)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(osPath(gz))
+		return "", err
+	}
+	os.Chtimes(osPath(gz), info.ModTime(), info.ModTime())
+	in.Close()
+	if err := os.Remove(osPath(path)); err != nil {
+		os.Remove(osPath(gz))
+		return "", err
+	}
+	return gz, nil
+}
+
+// gunzipFile 将 gzipFile 压缩的文件解压到 dst 并删除压缩文件，恢复修改时间
+func gunzipFile(gz, dst string) error {
+	in, err := os.Open(osPath(gz))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	r, err := gzip.NewReader(in)
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(osPath(dst), os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm()|0200)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(osPath(dst))
+		return err
+	}
+	modTime := info.ModTime()
+	if !r.ModTime.IsZero() {
+		modTime = r.ModTime
+	}
+	os.Chtimes(osPath(dst), modTime, modTime)
+	in.Close()
+	return os.Remove(osPath(gz))
+}
+
+// setReadOnly 去掉文件的写权限
+func setReadOnly(path string) error {
+	info, err := os.Stat(osPath(path))
+	if err != nil {
+		return err
+	}
+	return os.Chmod(osPath(path), info.Mode().Perm()&^0222)
+}
+
+// setWritable 恢复文件所有者的写权限
+func setWritable(path string) error {
+	info, err := os.Stat(osPath(path))
+	if err != nil {
+		return err
+	}
+	return os.Chmod(osPath(path), info.Mode().Perm()|0200)
+}
+
+// lookPath 判断命令是否可用
+func lookPath(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// firstLine 返回多行文本的第一行
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,210 @@
+// Package organizer 文件整理模块
+// preflight.go - 执行前检查与原子执行：移动任何文件之前确认目标磁盘空间足够、
+// 目标文件夹可写、源文件没有被其他程序打开；原子执行（atomic）时任何文件移动失败，
+// 已移动的文件全部移回原处，整批要么全部完成、要么保持原样
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// ==================== 常量定义 ====================
+
+// PreflightReserve 目标磁盘除移入的文件外至少保留的空间（新建文件夹、备份和说明文件等）
+const PreflightReserve = 16 << 20
+
+// ==================== 执行前检查 ====================
+
+// preflightResult 执行前检查的结果
+type preflightResult struct {
+	Problems []string        // 使整理无法进行的问题（空间不足、目标文件夹不可写）
+	InUse    map[string]bool // 正被其他程序打开的源文件
+}
+
+// preflight 在移动文件之前检查整理计划能否执行
+// 与目标目录不在同一磁盘的文件需要复制，所需空间按这些文件的大小计算；
+// 每个目标文件夹（尚不存在时为最近的已存在上级）实际创建一个临时文件确认可写
+//
+// 参数:
+//   - plan: 整理计划
+//
+// 返回值:
+//   - preflightResult: 检查结果
+func preflight(plan *Plan) preflightResult {
+	var result preflightResult
+
+	folders := make([]string, 0, len(plan.Actions)+1)
+	var sources []string
+	for folder, files := range plan.Actions {
+		folders = append(folders, folder)
+		for _, r := range files {
+			sources = append(sources, r.FileInfo.Path)
+		}
+	}
+	if plan.ReviewAction == LowConfidenceReview && len(plan.Review) > 0 {
+		folders = append(folders, ReviewFolder)
+		for _, r := range plan.Review {
+			sources = append(sources, r.FileInfo.Path)
+		}
+	}
+	sort.Strings(folders)
+
+	// 目标文件夹可写
+	checked := make(map[string]bool)
+	for _, folder := range folders {
+		dir, err := existingDir(filepath.Join(plan.TargetDir, folder))
+		if err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("无法创建分类文件夹 %s: %v", folder, err))
+			continue
+		}
+		if checked[dir] {
+			continue
+		}
+		checked[dir] = true
+		if err := checkWritable(dir); err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("没有写入权限: %s", dir))
+		}
+	}
+
+	// 目标磁盘剩余空间
+	if target, err := existingDir(plan.TargetDir); err == nil {
+		if free, err := DiskFree(target); err == nil {
+			var need uint64 = PreflightReserve
+			for _, files := range plan.Actions {
+				for _, r := range files {
+					if !sameVolume(r.FileInfo.Path, target) {
+						need += uint64(r.FileInfo.Size)
+					}
+				}
+			}
+			if free < need {
+				result.Problems = append(result.Problems, fmt.Sprintf("目标磁盘空间不足: 需要 %s，剩余 %s",
+					ui.FormatSize(int64(need)), ui.FormatSize(int64(free))))
+			}
+		}
+	}
+
+	// 源文件是否正被使用
+	result.InUse = openFiles(sources)
+	return result
+}
+
+// existingDir 返回 dir 或其最近的已存在上级目录；路径上已存在同名文件时返回错误
+func existingDir(dir string) (string, error) {
+	for {
+		info, err := os.Stat(osPath(dir))
+		if err == nil {
+			if !info.IsDir() {
+				return "", fmt.Errorf("%s 已存在且不是文件夹", dir)
+			}
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", err
+		}
+		dir = parent
+	}
+}
+
+// checkWritable 在目录中创建并删除一个临时文件，确认当前用户可以写入
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(osPath(dir), ".filo-preflight-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// absPaths 返回绝对路径到原路径的映射，用于与其他程序打开的文件路径比较
+func absPaths(paths []string) map[string]string {
+	m := make(map[string]string, len(paths))
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			abs = p
+		}
+		m[abs] = p
+	}
+	return m
+}
+
+// printPreflight 显示执行前检查发现的问题
+func printPreflight(plan *Plan, check preflightResult, atomic bool) {
+	for _, p := range check.Problems {
+		ui.Error("%s", p)
+	}
+	if len(check.InUse) == 0 {
+		return
+	}
+	paths := make([]string, 0, len(check.InUse))
+	for path := range check.InUse {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if atomic {
+		ui.Error("%d 个文件正被其他程序使用", len(paths))
+	} else {
+		ui.Warning("%d 个文件正被其他程序使用，留在原处", len(paths))
+	}
+	for i, path := range paths {
+		if i >= MaxDisplayFiles {
+			ui.Dim("  ... 还有 %d 个文件", len(paths)-MaxDisplayFiles)
+			break
+		}
+		ui.Dim("  %s", relTo(plan.SourceDir, path))
+	}
+}
+
+// relTo 返回相对 base 的路径，base 为空或无法计算时返回原路径
+func relTo(base, path string) string {
+	if base == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// ==================== 原子执行 ====================
+
+// rollback 按与移动相反的顺序把已移动的文件移回原处
+// 全部移回时将批次标记为已撤销；有文件未能移回时保留批次，之后可用 filo undo 重试
+//
+// 参数:
+//   - db: 数据库连接（可为 nil）
+//   - logs: 本批次成功移动的操作记录（按移动顺序）
+//   - batchID: 批次 ID
+//
+// 返回值:
+//   - int: 移回的文件数
+//   - []string: 未能移回的文件及原因
+func rollback(db *storage.Database, logs []storage.OperationLog, batchID string) (int, []string) {
+	restored := 0
+	var failures []string
+	for i := len(logs) - 1; i >= 0; i-- {
+		if _, err := restoreFile(logs[i], &failures); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", logs[i].Filename, err))
+			continue
+		}
+		restored++
+	}
+	if db != nil && len(failures) == 0 {
+		db.MarkBatchUndone(batchID)
+	}
+	cleanEmptyDirs(logs)
+	return restored, failures
+}
@@ -0,0 +1,203 @@
+// Package organizer 文件整理模块
+// quota.go - 分类文件夹容量上限：按 category_quotas 配置限制单个分类文件夹的文件数和总大小，
+// 超出后新文件按修改月份放入子文件夹（如 截图/2024-06），或只在计划中提示
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/ui"
+)
+
+// ==================== 常量定义 ====================
+
+// 超出容量上限后的处理方式
+const (
+	OverflowMonth = "month" // 新文件按修改月份放入子文件夹（默认）
+	OverflowWarn  = "warn"  // 照常放入，只在计划中提示
+)
+
+// monthLayout 按月份子文件夹的名称格式
+const monthLayout = "2006-01"
+
+// ==================== 数据结构 ====================
+
+// QuotaNote 计划中超出容量上限的分类文件夹
+type QuotaNote struct {
+	Folder   string               // 分类文件夹（相对目标目录）
+	Quota    config.CategoryQuota // 生效的容量上限
+	Files    int                  // 整理后文件夹中的文件数（不含按月份子文件夹）
+	Bytes    int64                // 整理后文件夹中文件的总大小
+	Overflow int                  // 超出上限的新文件数
+}
+
+// folderUsage 分类文件夹的占用：磁盘上已有的文件加上计划放入的文件
+type folderUsage struct {
+	files int
+	bytes int64
+}
+
+// ==================== 容量检查 ====================
+
+// QuotaFor 返回分类文件夹生效的容量上限
+// 配置的键为分类路径，作用于该分类及其下级分类的文件夹；同时匹配多个键时取最具体的一个
+//
+// 参数:
+//   - folder: 分类文件夹（相对目标目录，如 图片/截图）
+//
+// 返回值:
+//   - config.CategoryQuota: 容量上限
+//   - bool: 是否设置了上限
+func QuotaFor(folder string) (config.CategoryQuota, bool) {
+	full := filepath.ToSlash(folder)
+	var quota config.CategoryQuota
+	best := -1
+	for key, q := range config.Get().CategoryQuotas {
+		key = strings.Trim(filepath.ToSlash(key), "/")
+		if key != "" {
+			key = strings.Join(classifier.FolderNames(strings.Split(key, "/")), "/") // 按分类名设置的上限对应到显示名称
+		}
+		if key == "" || (full != key && !strings.HasPrefix(full, key+"/")) {
+			continue
+		}
+		if len(key) > best && (q.MaxFiles > 0 || q.MaxMB > 0) {
+			quota, best = q, len(key)
+		}
+	}
+	return quota, best >= 0
+}
+
+// applyQuota 检查文件放入分类文件夹后是否超出容量上限
+// 超出时记录到计划的 QuotaNotes；overflow 为 month 时返回按修改月份的子文件夹
+//
+// 参数:
+//   - folder: 文件原本要放入的分类文件夹
+//   - r: 分类结果
+//
+// 返回值:
+//   - string: 文件实际放入的文件夹
+func (p *Plan) applyQuota(folder string, r classifier.Result) string {
+	quota, ok := QuotaFor(folder)
+	if !ok {
+		return folder
+	}
+	if p.usage == nil {
+		p.usage = make(map[string]*folderUsage)
+	}
+	u, ok := p.usage[folder]
+	if !ok {
+		u = diskUsage(filepath.Join(p.TargetDir, folder))
+		p.usage[folder] = u
+	}
+
+	size := r.FileInfo.Size
+	over := (quota.MaxFiles > 0 && u.files+1 > quota.MaxFiles) ||
+		(quota.MaxMB > 0 && u.bytes+size > int64(quota.MaxMB)<<20)
+	if !over {
+		u.files++
+		u.bytes += size
+		return folder
+	}
+
+	note := p.quotaNote(folder, quota)
+	note.Overflow++
+	if quota.Overflow == OverflowWarn {
+		u.files++
+		u.bytes += size
+		note.Files, note.Bytes = u.files, u.bytes
+		return folder
+	}
+	note.Files, note.Bytes = u.files, u.bytes
+	return filepath.Join(folder, monthFolder(r))
+}
+
+// quotaNote 返回分类文件夹的超限记录，没有时新建
+func (p *Plan) quotaNote(folder string, quota config.CategoryQuota) *QuotaNote {
+	for _, n := range p.QuotaNotes {
+		if n.Folder == folder {
+			return n
+		}
+	}
+	n := &QuotaNote{Folder: folder, Quota: quota}
+	p.QuotaNotes = append(p.QuotaNotes, n)
+	return n
+}
+
+// diskUsage 统计文件夹中已有的文件数和总大小（不含子文件夹和隐藏文件），文件夹不存在时为 0
+func diskUsage(dir string) *folderUsage {
+	u := &folderUsage{}
+	entries, err := os.ReadDir(osPath(dir))
+	if err != nil {
+		return u
+	}
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		u.files++
+		if info, err := e.Info(); err == nil {
+			u.bytes += info.Size()
+		}
+	}
+	return u
+}
+
+// monthFolder 文件按修改月份放入的子文件夹名，如 2024-06
+func monthFolder(r classifier.Result) string {
+	t := r.FileInfo.ModifiedTime
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.Format(monthLayout)
+}
+
+// isMonthFolder 判断文件夹名是否为按月份的子文件夹
+func isMonthFolder(name string) bool {
+	_, err := time.Parse(monthLayout, name)
+	return err == nil
+}
+
+// ==================== 计划显示 ====================
+
+// printQuotaNotes 显示超出容量上限的分类文件夹
+func printQuotaNotes(plan *Plan) {
+	if len(plan.QuotaNotes) == 0 {
+		return
+	}
+	notes := append([]*QuotaNote(nil), plan.QuotaNotes...)
+	sort.Slice(notes, func(i, j int) bool { return notes[i].Folder < notes[j].Folder })
+
+	fmt.Printf("\n  %s %s\n", ui.Yellow("⚠"), ui.Bold("超出容量上限"))
+	for _, n := range notes {
+		usage := fmt.Sprintf("%d 个文件 / %s", n.Files, ui.FormatSize(n.Bytes))
+		if n.Quota.Overflow == OverflowWarn {
+			fmt.Printf("      %s/ 整理后 %s，超过上限 %s\n", n.Folder, usage, describeQuota(n.Quota))
+		} else {
+			fmt.Printf("      %s/ 已满（%s，上限 %s），%d 个新文件按修改月份放入子文件夹\n",
+				n.Folder, usage, describeQuota(n.Quota), n.Overflow)
+		}
+	}
+}
+
+// describeQuota 容量上限的说明，如 5000 个文件 / 2.0 GB
+func describeQuota(q config.CategoryQuota) string {
+	var parts []string
+	if q.MaxFiles > 0 {
+		parts = append(parts, fmt.Sprintf("%d 个文件", q.MaxFiles))
+	}
+	if q.MaxMB > 0 {
+		parts = append(parts, ui.FormatSize(int64(q.MaxMB)<<20))
+	}
+	return strings.Join(parts, " / ")
+}
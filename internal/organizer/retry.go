@@ -0,0 +1,146 @@
+// Package organizer 文件整理模块
+// retry.go - 重试移动失败的文件：按操作日志中记录的分类重新移动批次中失败的文件（被占用、没有权限等），
+// 成功的记录与批次中的其他文件一样可以撤销；跨设备无法改名的文件可以改为复制后删除源文件
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filo/internal/classifier"
+	"filo/internal/quarantine"
+	"filo/internal/scanner"
+	"filo/internal/storage"
+	"filo/internal/ui"
+)
+
+// RetryResult 重试结果统计
+type RetryResult struct {
+	Success  int      `json:"success"`            // 重试成功的文件数
+	Errors   int      `json:"errors"`             // 仍然失败的文件数
+	Skipped  []string `json:"skipped,omitempty"`  // 因重名跳过、留在原处的文件
+	Missing  []string `json:"missing,omitempty"`  // 源文件已不存在（已被移走或删除），不再重试
+	Failures []string `json:"failures,omitempty"` // 仍然失败的文件及原因
+
+	CrossDevice int `json:"cross_device,omitempty"` // 因跨设备无法移动而失败的文件数（未使用复制时）
+}
+
+// Retry 重试批次中移动失败的文件
+// 文件按记录的分类移入原来的分类文件夹，重名按当前的 conflict_strategy 处理；
+// 每条记录按重试结果更新，源文件已不存在的记录保持失败状态，批次的运行摘要同步更新。
+// 重试只移动文件，不重新分类，也不学习分类结果
+//
+// 参数:
+//   - db: 数据库连接
+//   - logs: 同一批次中全部失败的操作日志
+//   - targetDir: 批次的目标目录，未知时为空（按记录的目标路径所在的文件夹移动）
+//   - sourceDir: 批次整理的目录，用于显示相对路径，可为空
+//   - copyAcross: 跨设备时复制校验后删除源文件（未开启 cross_device_copy 时由 --copy 指定）
+//   - verbose: 是否逐个输出移动的文件
+//
+// 返回值:
+//   - RetryResult: 重试结果
+func Retry(db *storage.Database, logs []storage.OperationLog, targetDir, sourceDir string, copyAcross, verbose bool) RetryResult {
+	result := RetryResult{}
+	if len(logs) == 0 {
+		return result
+	}
+	folders := make(map[string]bool) // 放入了文件的分类文件夹
+	var remaining []string           // 仍然失败的文件及原因（包括源文件已不存在的）
+	for _, log := range logs {
+		plan := &Plan{TargetDir: targetDir, SourceDir: sourceDir, CopyAcross: copyAcross}
+		folder, ok := retryFolder(targetDir, log.DestPath)
+		if !ok {
+			plan.TargetDir, folder = filepath.Dir(log.DestPath), ""
+		}
+
+		f, err := retryFile(log.SourcePath)
+		if err != nil {
+			rel := plan.RelPath(classifier.Result{FileInfo: scanner.FileInfo{Path: log.SourcePath, Name: log.Filename}})
+			result.Missing = append(result.Missing, rel)
+			if log.Error != "" {
+				err = errors.New(log.Error)
+			}
+			remaining = append(remaining, failure(rel, err))
+			if verbose {
+				ui.Warning("源文件已不存在: %s", log.SourcePath)
+			}
+			continue
+		}
+		r := classifier.Result{FileInfo: f, Category: log.Category, Subcategory: log.Subcategory, Source: log.Source}
+		if r.Source == "quarantine" {
+			r.FileInfo.SHA256, _ = quarantine.Hash(osPath(f.Path)) // 移入隔离区后照常记录哈希
+		}
+
+		moved, err := moveFile(plan, folder, r, log.BatchID, verbose)
+		moved.ID = log.ID
+		switch moved.Status {
+		case "success":
+			result.Success++
+			if ok {
+				folders[folder] = true
+			}
+			recordQuarantine(db, moved, r)
+		case "skipped":
+			result.Skipped = append(result.Skipped, plan.RelPath(r))
+		default:
+			result.Errors++
+			result.Failures = append(result.Failures, failure(plan.RelPath(r), err))
+			if crossDevice(err) {
+				result.CrossDevice++
+			}
+			remaining = append(remaining, failure(plan.RelPath(r), err))
+		}
+		if db != nil {
+			if err := db.UpdateOperationResult(moved); err != nil {
+				ui.Warning("更新操作记录失败: %v", err)
+			}
+		}
+	}
+	describeFolders(targetDir, folders)
+	if db != nil {
+		db.RecordRetry(logs[0].BatchID, result.Success, len(result.Skipped), remaining)
+	}
+	return result
+}
+
+// retryFolder 从记录的目标路径推出分类文件夹（相对目标目录）
+func retryFolder(targetDir, destPath string) (string, bool) {
+	if targetDir == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(targetDir, filepath.Dir(destPath))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// retryFile 重新读取失败文件的信息，源文件已不存在时返回错误
+// 符号链接与整理时一样按链接本身移动
+func retryFile(path string) (scanner.FileInfo, error) {
+	info, err := os.Lstat(osPath(path))
+	if err != nil {
+		return scanner.FileInfo{}, err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return scanner.StatFile(path)
+	}
+	target, err := os.Readlink(osPath(path))
+	if err != nil {
+		return scanner.FileInfo{}, err
+	}
+	return scanner.FileInfo{
+		Path:         path,
+		Name:         info.Name(),
+		Extension:    strings.ToLower(filepath.Ext(info.Name())),
+		ModifiedTime: info.ModTime(),
+		Symlink:      target,
+	}, nil
+}
@@ -0,0 +1,273 @@
+// Package organizer 文件整理模块
+// simulate.go - 模拟执行：在内存中的虚拟文件系统上执行整理计划，显示最终的目录结构
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"filo/internal/classifier"
+	"filo/internal/ui"
+)
+
+// ==================== 类型定义 ====================
+
+// SimEntry 模拟执行后移入目标目录的一个文件
+type SimEntry struct {
+	Path     string            // 相对目标目录的最终路径
+	Result   classifier.Result // 分类结果
+	Original string            // 因重名改名时的原文件名，否则为空
+	Review   bool              // 是否为移入待确认文件夹的文件
+}
+
+// Simulation 模拟执行的结果
+type Simulation struct {
+	TargetDir string     // 目标目录
+	Entries   []SimEntry // 移入目标目录的文件（按执行顺序）
+	Existing  []string   // 目标目录中原有的文件（相对路径）
+	NewDirs   int        // 需要新建的文件夹数
+	Kept      int        // 留在原处的待确认文件数
+}
+
+// Renamed 因重名被改名的文件
+func (s *Simulation) Renamed() []SimEntry {
+	var renamed []SimEntry
+	for _, e := range s.Entries {
+		if e.Original != "" {
+			renamed = append(renamed, e)
+		}
+	}
+	return renamed
+}
+
+// virtualFS 内存中的虚拟文件系统
+// 只记录已存在的路径（文件和目录），足以按 Execute 的规则判断重名；
+// 以 pathKey 为键，不区分大小写的平台上与磁盘一样只有大小写不同的路径视为重名
+type virtualFS map[string]string
+
+// loadVirtualFS 以磁盘上目录的现状初始化虚拟文件系统（只读）
+func loadVirtualFS(root string) virtualFS {
+	vfs := virtualFS{}
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil {
+			vfs.add(path)
+		}
+		return nil
+	})
+	return vfs
+}
+
+// add 记录路径
+func (v virtualFS) add(path string) {
+	v[pathKey(path)] = path
+}
+
+// exists 路径是否存在
+func (v virtualFS) exists(path string) bool {
+	_, ok := v[pathKey(path)]
+	return ok
+}
+
+// ==================== 模拟执行 ====================
+
+// Simulate 在虚拟文件系统上执行整理计划，不修改磁盘
+// 以目标目录现有的内容为初始状态，按与 Execute 相同的规则创建文件夹、处理重名
+func Simulate(plan *Plan) *Simulation {
+	vfs := loadVirtualFS(plan.TargetDir)
+	sim := &Simulation{TargetDir: plan.TargetDir}
+	for _, path := range vfs {
+		if rel, err := filepath.Rel(plan.TargetDir, path); err == nil && rel != "." {
+			sim.Existing = append(sim.Existing, rel)
+		}
+	}
+
+	// 按文件夹名顺序执行，同一文件夹内保持计划中的顺序
+	folders := make([]string, 0, len(plan.Actions))
+	for folder := range plan.Actions {
+		folders = append(folders, folder)
+	}
+	sort.Strings(folders)
+	for _, folder := range folders {
+		for _, r := range plan.Actions[folder] {
+			sim.place(vfs, filepath.Join(plan.TargetDir, folder, r.FileInfo.Name), r, false)
+		}
+	}
+
+	// 待确认文件：review 模式移入待确认文件夹，keep 模式留在原处
+	if plan.ReviewAction == LowConfidenceReview {
+		for _, r := range plan.Review {
+			sim.place(vfs, filepath.Join(plan.TargetDir, ReviewFolder, r.FileInfo.Name), r, true)
+		}
+	} else {
+		sim.Kept = len(plan.Review)
+	}
+	return sim
+}
+
+// place 把文件放入虚拟文件系统，必要时新建文件夹并处理重名
+func (s *Simulation) place(vfs virtualFS, dst string, r classifier.Result, review bool) {
+	for dir := filepath.Dir(dst); !vfs.exists(dir); dir = filepath.Dir(dir) {
+		vfs.add(dir)
+		s.NewDirs++
+		if dir == s.TargetDir || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	final := uniquePath(dst, vfs.exists)
+	vfs.add(final)
+
+	entry := SimEntry{Result: r, Review: review}
+	entry.Path, _ = filepath.Rel(s.TargetDir, final)
+	if final != dst {
+		entry.Original = r.FileInfo.Name
+	}
+	s.Entries = append(s.Entries, entry)
+}
+
+// ==================== 结果显示 ====================
+
+// PrintSimulation 显示模拟执行的摘要和重名改名的文件
+func PrintSimulation(sim *Simulation) {
+	if ui.IsQuiet() {
+		return
+	}
+
+	renamed := sim.Renamed()
+	lines := []string{
+		fmt.Sprintf("📂 目标: %s", sim.TargetDir),
+		fmt.Sprintf("📄 移入: %d 个文件", len(sim.Entries)),
+		fmt.Sprintf("📁 新建文件夹: %d 个", sim.NewDirs),
+		fmt.Sprintf("🔁 重名改名: %d 个", len(renamed)),
+	}
+	if sim.Kept > 0 {
+		lines = append(lines, fmt.Sprintf("❓ 留在原处待确认: %d 个", sim.Kept))
+	}
+	ui.Box("🧪 模拟结果", lines)
+
+	if len(renamed) > 0 {
+		fmt.Printf("\n  %s %s\n", ui.Yellow("🔁"), ui.Bold("重名改名"))
+		for i, e := range renamed {
+			if i >= MaxDisplayFiles {
+				ui.Dim("      ... 还有 %d 个文件，使用 --tree 查看全部", len(renamed)-MaxDisplayFiles)
+				break
+			}
+			fmt.Printf("      %s %s\n", e.Original, ui.Gray("→ "+e.Path))
+		}
+	}
+	fmt.Println()
+}
+
+// treeNode 目录树中的一个文件夹
+type treeNode struct {
+	dirs     map[string]*treeNode
+	files    []SimEntry // 新移入的文件
+	existing int        // 原有的文件和文件夹数（不展开）
+}
+
+// child 获取或创建子文件夹
+func (n *treeNode) child(name string) *treeNode {
+	if n.dirs == nil {
+		n.dirs = map[string]*treeNode{}
+	}
+	c, ok := n.dirs[name]
+	if !ok {
+		c = &treeNode{}
+		n.dirs[name] = c
+	}
+	return c
+}
+
+// PrintTree 以目录树显示模拟执行后的目标目录
+// 只展开有新文件移入的文件夹；文件夹中原有的内容只显示数量
+func PrintTree(sim *Simulation) {
+	if ui.IsQuiet() {
+		return
+	}
+
+	root := &treeNode{}
+	for _, e := range sim.Entries {
+		parts := strings.Split(e.Path, string(filepath.Separator))
+		node := root
+		for _, dir := range parts[:len(parts)-1] {
+			node = node.child(dir)
+		}
+		node.files = append(node.files, e)
+	}
+
+	// 原有内容计入已展开的最近一级文件夹
+	for _, rel := range sim.Existing {
+		parts := strings.Split(rel, string(filepath.Separator))
+		node := root
+		for _, dir := range parts[:len(parts)-1] {
+			next, ok := node.dirs[dir]
+			if !ok {
+				node = nil
+				break
+			}
+			node = next
+		}
+		if node == nil {
+			continue
+		}
+		if _, expanded := node.dirs[parts[len(parts)-1]]; !expanded {
+			node.existing++
+		}
+	}
+
+	ui.Title("🌳", "整理后的目录结构")
+	fmt.Printf("  %s\n", ui.Bold(sim.TargetDir+string(filepath.Separator)))
+	printTreeNode(root, "  ")
+	fmt.Println()
+}
+
+// printTreeNode 递归打印文件夹内容：子文件夹、新文件、原有内容数量
+func printTreeNode(n *treeNode, indent string) {
+	dirs := make([]string, 0, len(n.dirs))
+	for name := range n.dirs {
+		dirs = append(dirs, name)
+	}
+	sort.Strings(dirs)
+	sort.Slice(n.files, func(i, j int) bool { return n.files[i].Path < n.files[j].Path })
+
+	total := len(dirs) + len(n.files)
+	if n.existing > 0 {
+		total++
+	}
+	i := 0
+	branch := func() (string, string) {
+		i++
+		if i == total {
+			return "└── ", "    "
+		}
+		return "├── ", "│   "
+	}
+
+	for _, name := range dirs {
+		b, next := branch()
+		fmt.Printf("%s%s%s\n", indent, ui.Gray(b), ui.Bold(name+string(filepath.Separator)))
+		printTreeNode(n.dirs[name], indent+ui.Gray(next))
+	}
+	for _, e := range n.files {
+		b, _ := branch()
+		line := ui.Green(filepath.Base(e.Path))
+		if e.Original != "" {
+			line += ui.Yellow("  ← 重名，原名 " + e.Original)
+		}
+		if e.Review {
+			line += ui.Gray(fmt.Sprintf("  (%s/%s? %.0f%%)", e.Result.Category, e.Result.Subcategory, e.Result.Confidence*100))
+		}
+		fmt.Printf("%s%s%s\n", indent, ui.Gray(b), line)
+	}
+	if n.existing > 0 {
+		b, _ := branch()
+		fmt.Printf("%s%s%s\n", indent, ui.Gray(b), ui.Gray(fmt.Sprintf("(原有 %d 项)", n.existing)))
+	}
+}
@@ -0,0 +1,127 @@
+// Package organizer 文件整理模块
+// target.go - 目标目录模板：目标目录可以包含 {home}、{source_dir_name}、{year} 等占位符，
+// 生成计划时按本次整理的源目录和当前日期展开，一份配置适用于多个收件目录
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filo/internal/config"
+	"filo/internal/guard"
+)
+
+// DefaultTargetFolder 未指定目标目录时在源目录下建立的文件夹
+const DefaultTargetFolder = "已整理"
+
+// envPrefix 环境变量占位符的前缀，如 {env:NAS_ROOT}
+const envPrefix = "env:"
+
+// targetPlaceholders 目标目录中可用的占位符（{env:NAME} 另行处理）
+// home 用户主目录，source_dir 源目录的完整路径，source_dir_name 源目录名，
+// year/month/day 当前的年、月、日（如 2026、03、07），date 年-月-日
+var targetPlaceholders = []string{"home", "source_dir", "source_dir_name", "year", "month", "day", "date"}
+
+// TargetPlaceholders 目标目录中可用的占位符，用于帮助和错误提示
+func TargetPlaceholders() []string {
+	names := make([]string, 0, len(targetPlaceholders)+1)
+	for _, name := range targetPlaceholders {
+		names = append(names, "{"+name+"}")
+	}
+	return append(names, "{"+envPrefix+"NAME}")
+}
+
+// ExpandTarget 展开目标目录中的占位符和开头的 ~
+// 未知的占位符、未设置的环境变量和缺少右括号都返回错误，而不是留在路径中建出奇怪的文件夹
+//
+// 参数:
+//   - template: 目标目录，可以包含占位符，如 ~/Archive/{source_dir_name}/{year}
+//   - sourceDir: 本次整理的源目录
+//   - now: 展开日期占位符使用的时间
+//
+// 返回值:
+//   - string: 展开后的目标目录
+//   - error: 如果占位符无法展开，返回错误
+func ExpandTarget(template, sourceDir string, now time.Time) (string, error) {
+	if !strings.Contains(template, "{") {
+		return guard.ExpandHome(template), nil
+	}
+
+	absSrc, err := filepath.Abs(sourceDir)
+	if err != nil {
+		absSrc = filepath.Clean(sourceDir)
+	}
+	values := map[string]string{
+		"source_dir":      absSrc,
+		"source_dir_name": filepath.Base(absSrc),
+		"year":            now.Format("2006"),
+		"month":           now.Format("01"),
+		"day":             now.Format("02"),
+		"date":            now.Format("2006-01-02"),
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		values["home"] = home
+	}
+
+	var b strings.Builder
+	rest := template
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("目标目录中的占位符缺少右括号: %s", template)
+		}
+		name := rest[start+1 : start+end]
+		b.WriteString(rest[:start])
+		rest = rest[start+end+1:]
+
+		if env, ok := strings.CutPrefix(name, envPrefix); ok {
+			value, set := os.LookupEnv(env)
+			if env == "" || !set || value == "" {
+				return "", fmt.Errorf("目标目录中的环境变量 %s 未设置", env)
+			}
+			b.WriteString(value)
+			continue
+		}
+		value, ok := values[name]
+		if !ok {
+			if name == "home" {
+				return "", fmt.Errorf("无法确定用户主目录，不能展开目标目录中的 {home}")
+			}
+			return "", fmt.Errorf("目标目录中的占位符 {%s} 无效，可用: %s", name, strings.Join(TargetPlaceholders(), " "))
+		}
+		b.WriteString(value)
+	}
+	return guard.ExpandHome(b.String()), nil
+}
+
+// ResolveTarget 确定本次整理的目标目录并展开占位符
+// 优先使用指定的目标目录，其次为配置 target_dir，都为空时为 <源目录>/已整理
+//
+// 参数:
+//   - target: 命令行或请求中指定的目标目录，可以为空
+//   - sourceDir: 本次整理的源目录
+//
+// 返回值:
+//   - string: 展开后的目标目录
+//   - error: 如果占位符无法展开，返回错误
+func ResolveTarget(target, sourceDir string) (string, error) {
+	if target == "" {
+		target = config.Get().TargetDir
+	}
+	if target == "" {
+		return filepath.Join(sourceDir, DefaultTargetFolder), nil
+	}
+	return ExpandTarget(target, sourceDir, time.Now())
+}
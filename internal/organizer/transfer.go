@@ -0,0 +1,134 @@
+// Package organizer 文件整理模块
+// transfer.go - 跨设备移动：目标在另一个磁盘上（如下载目录在 SSD、目标在 NAS）时无法直接改名，
+// 改为复制到目标文件夹中的临时文件、校验 SHA-256 一致后再改名并删除源文件；
+// 大文件复制时显示进度，任何一步失败都删除复制了一半的临时文件，源文件保持不动
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/schollz/progressbar/v3"
+
+	"filo/internal/quarantine"
+	"filo/internal/ui"
+)
+
+// 文件的移动方式（操作日志的 transfer 字段）
+const (
+	TransferRename = ""     // 同一文件系统内改名
+	TransferCopy   = "copy" // 跨设备复制、校验 SHA-256 后删除源文件
+)
+
+// transferLabels 移动方式的说明（详细输出和 filo last 中显示）
+var transferLabels = map[string]string{
+	TransferCopy: "跨磁盘，已复制并校验后删除源文件",
+}
+
+// 跨设备复制参数
+const (
+	largeCopySize = 64 << 20        // 复制时显示进度条的文件大小下限（64 MB）
+	partialSuffix = ".filo-partial" // 复制中的临时文件后缀（以 . 开头，扫描时跳过）
+)
+
+// TransferLabel 移动方式的说明，同一文件系统内改名时为空
+func TransferLabel(transfer string) string {
+	return transferLabels[transfer]
+}
+
+// moveAcross 移动文件，返回实际的移动方式
+// 源文件和目标不在同一设备上而无法改名时，copyAcross 为 true 则复制校验后删除源文件，
+// 源文件删除失败时删除复制出的文件，保证只留一份；只复制普通文件，符号链接和文件夹仍返回原来的错误
+func moveAcross(src, dst string, copyAcross bool) (string, error) {
+	err := os.Rename(osPath(src), osPath(dst))
+	if err == nil || !copyAcross || !crossDevice(err) {
+		return TransferRename, err
+	}
+	if info, lerr := os.Lstat(osPath(src)); lerr != nil || !info.Mode().IsRegular() {
+		return TransferRename, err
+	}
+	if err := copyVerified(src, dst); err != nil {
+		return TransferCopy, err
+	}
+	if err := os.Remove(osPath(src)); err != nil {
+		os.Remove(osPath(dst))
+		return TransferCopy, err
+	}
+	return TransferCopy, nil
+}
+
+// copyVerified 复制文件并校验，保留权限和修改时间
+// 先写入目标文件夹中的临时文件，写完后重新读取计算 SHA-256，与复制时源文件的 SHA-256 一致才改名为目标文件；
+// 复制、校验或改名失败时删除临时文件。目标已存在时返回错误
+func copyVerified(src, dst string) error {
+	in, err := os.Open(osPath(src))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+partialSuffix)
+	out, err := os.OpenFile(osPath(tmp), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	done := false
+	defer func() {
+		if !done {
+			os.Remove(osPath(tmp))
+		}
+	}()
+
+	// 源文件的哈希在复制时顺带计算，不必再读一遍
+	h := sha256.New()
+	var w io.Writer = io.MultiWriter(out, h)
+	if info.Size() >= largeCopySize && !ui.IsQuiet() {
+		bar := progressbar.DefaultBytes(info.Size(), "复制 "+filepath.Base(src))
+		defer func() {
+			bar.Finish()
+			fmt.Println()
+		}()
+		w = io.MultiWriter(w, bar)
+	}
+	if _, err := io.Copy(w, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	written, err := quarantine.Hash(osPath(tmp))
+	if err != nil {
+		return err
+	}
+	if written != hex.EncodeToString(h.Sum(nil)) {
+		return fmt.Errorf("复制后校验失败: %s 与源文件的 SHA-256 不一致", dst)
+	}
+	os.Chtimes(osPath(tmp), info.ModTime(), info.ModTime())
+
+	if _, err := os.Lstat(osPath(dst)); err == nil {
+		return fmt.Errorf("%s 已存在", dst)
+	}
+	if err := os.Rename(osPath(tmp), osPath(dst)); err != nil {
+		return err
+	}
+	done = true
+	return nil
+}
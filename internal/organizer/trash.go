@@ -0,0 +1,164 @@
+// Package organizer 文件整理模块
+// trash.go - 软删除：启用 soft_delete_days 后，整理中要删除的文件（与已有文件内容相同的重复文件）
+// 先移入 ~/.filo/trash/<批次ID>/ 并记录到期时间，到期后由 filo purge 永久删除，作为撤销之外的又一道保险
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"filo/internal/config"
+)
+
+// TrashFolder 软删除的文件所在的目录（位于数据目录下，按批次存放）
+// 不与隔离模式的 隔离区/ 混用：隔离区存放的是要保留的可执行文件，这里存放的是等待删除的文件
+const TrashFolder = "trash"
+
+// trashExpiresFile 批次目录中记录到期时间的文件（RFC 3339 格式）
+const trashExpiresFile = ".expires"
+
+// TrashBatch 回收站中一个批次的文件
+type TrashBatch struct {
+	BatchID string    // 批次 ID
+	Dir     string    // 批次目录
+	Files   int       // 文件数
+	Size    int64     // 文件总大小（字节）
+	Expires time.Time // 到期时间，之后 filo purge 永久删除
+}
+
+// Expired 批次是否已到期
+func (b TrashBatch) Expired(now time.Time) bool {
+	return !now.Before(b.Expires)
+}
+
+// TrashDir 回收站目录（~/.filo/trash）
+func TrashDir() string {
+	return filepath.Join(config.Get().DataDir, TrashFolder)
+}
+
+// trashPath 软删除时文件移入的路径，按 批次ID/分类文件夹/文件名 存放，重名时添加数字后缀；
+// 未启用软删除（soft_delete_days 为 0）时为空，文件直接删除
+func trashPath(batchID, folder, name string) string {
+	if config.Get().SoftDeleteDays <= 0 {
+		return ""
+	}
+	return handleDuplicate(filepath.Join(TrashDir(), batchID, folder, name))
+}
+
+// moveToTrash 把文件移入回收站，批次目录中第一个文件移入时记录到期时间
+// 回收站可能与源文件不在同一磁盘上，此时复制校验后删除源文件
+func moveToTrash(src, dst string) error {
+	batchDir := trashBatchDir(dst)
+	if err := os.MkdirAll(osPath(filepath.Dir(dst)), 0700); err != nil {
+		return err
+	}
+	expires := filepath.Join(batchDir, trashExpiresFile)
+	if _, err := os.Stat(osPath(expires)); os.IsNotExist(err) {
+		at := time.Now().AddDate(0, 0, config.Get().SoftDeleteDays)
+		if err := os.WriteFile(osPath(expires), []byte(at.Format(time.RFC3339)+"\n"), 0600); err != nil {
+			return err
+		}
+	}
+	_, err := moveAcross(src, dst, true)
+	return err
+}
+
+// restoreFromTrash 把软删除的文件移回 dst，并清理空的目录；只剩到期时间记录的批次目录一并删除
+func restoreFromTrash(path, dst string) error {
+	if _, err := moveAcross(path, dst, true); err != nil {
+		return err
+	}
+	batchDir := trashBatchDir(path)
+	for dir := filepath.Dir(path); dir != batchDir && strings.HasPrefix(dir, batchDir); dir = filepath.Dir(dir) {
+		if os.Remove(osPath(dir)) != nil {
+			return nil
+		}
+	}
+	if entries, err := os.ReadDir(osPath(batchDir)); err == nil && len(entries) == 1 && entries[0].Name() == trashExpiresFile {
+		os.RemoveAll(osPath(batchDir))
+	}
+	return nil
+}
+
+// inTrash 路径是否为回收站中仍然存在的文件
+func inTrash(path string) bool {
+	if path == "" || !strings.HasPrefix(path, TrashDir()+string(filepath.Separator)) {
+		return false
+	}
+	_, err := os.Stat(osPath(path))
+	return err == nil
+}
+
+// trashBatchDir 回收站中文件所在的批次目录（~/.filo/trash/<批次ID>）
+func trashBatchDir(path string) string {
+	rel, err := filepath.Rel(TrashDir(), path)
+	if err != nil {
+		return filepath.Dir(path)
+	}
+	batchID, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return filepath.Join(TrashDir(), batchID)
+}
+
+// TrashBatches 列出回收站中的批次，按到期时间排序
+// 缺少到期时间记录的批次按目录的修改时间加上 soft_delete_days 计算
+//
+// 返回值:
+//   - []TrashBatch: 回收站中的批次，回收站不存在时为空
+//   - error: 如果读取回收站目录失败，返回错误
+func TrashBatches() ([]TrashBatch, error) {
+	entries, err := os.ReadDir(osPath(TrashDir()))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var batches []TrashBatch
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		b := TrashBatch{BatchID: e.Name(), Dir: filepath.Join(TrashDir(), e.Name())}
+		if data, err := os.ReadFile(osPath(filepath.Join(b.Dir, trashExpiresFile))); err == nil {
+			b.Expires, _ = time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+		}
+		if b.Expires.IsZero() {
+			if info, err := e.Info(); err == nil {
+				b.Expires = info.ModTime().AddDate(0, 0, config.Get().SoftDeleteDays)
+			}
+		}
+		filepath.WalkDir(osPath(b.Dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || d.Name() == trashExpiresFile {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				b.Files++
+				b.Size += info.Size()
+			}
+			return nil
+		})
+		batches = append(batches, b)
+	}
+	sort.Slice(batches, func(i, j int) bool {
+		if !batches[i].Expires.Equal(batches[j].Expires) {
+			return batches[i].Expires.Before(batches[j].Expires)
+		}
+		return batches[i].BatchID < batches[j].BatchID
+	})
+	return batches, nil
+}
+
+// PurgeTrash 永久删除回收站中的一个批次
+// 删除后撤销该批次时，重复文件改为从保留的已有文件复制回原处（内容相同）
+func PurgeTrash(b TrashBatch) error {
+	return os.RemoveAll(osPath(b.Dir))
+}
@@ -0,0 +1,293 @@
+// Package organizer 文件整理模块
+// undo.go - 撤销整理操作，将文件移回原位置
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package organizer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"filo/internal/classifier"
+	"filo/internal/folderinfo"
+	"filo/internal/scanner"
+	"filo/internal/sidecar"
+	"filo/internal/storage"
+)
+
+// UndoResult 撤销结果统计
+type UndoResult struct {
+	Success      int      `json:"success"`       // 成功移回的文件数
+	Errors       int      `json:"errors"`        // 失败的文件数
+	Details      []string `json:"details"`       // 失败原因
+	DemotedRules int      `json:"demoted_rules"` // 因撤销而降级或停用的规则数
+}
+
+// Undo 撤销指定批次的操作
+// 将文件移回原位置（原位置已有同名文件时添加 _restored_N 后缀），
+// 已打包归档的文件从压缩包中解出，全部解出后删除压缩包；
+// 与已有文件相同而未保留的文件从回收站放回（已永久删除或未启用软删除时复制回原处），被替换的已有文件从备份目录恢复；
+// 分类操作压缩过的文件解压还原，设为只读的文件恢复写权限，转换生成的 JPG 删除；
+// 按与执行相反的顺序处理，同一批次内的重名文件依次还原；
+// 标记批次为已撤销并清理留下的空目录，为把文件分到这里的规则记一次负反馈
+func Undo(db *storage.Database, logs []storage.OperationLog, batchID string) UndoResult {
+	result := UndoResult{}
+	pending := make(map[string]int) // 压缩包 -> 尚未解出的文件数
+	var restored []storage.OperationLog
+
+	for i := len(logs) - 1; i >= 0; i-- {
+		log := logs[i]
+		tarball, _, archived := splitArchivePath(log.DestPath)
+		if archived {
+			pending[tarball]++
+		}
+
+		if _, err := restoreFile(log, &result.Details); err != nil {
+			result.Errors++
+			result.Details = append(result.Details, fmt.Sprintf("%s: %v", log.Filename, err))
+		} else {
+			result.Success++
+			restored = append(restored, log)
+			if archived {
+				pending[tarball]--
+			}
+		}
+	}
+
+	// 文件全部解出的压缩包不再需要
+	for tarball, left := range pending {
+		if left == 0 {
+			os.Remove(osPath(tarball))
+		}
+	}
+
+	// 标记批次为已撤销
+	if result.Success > 0 {
+		db.MarkBatchUndone(batchID)
+	}
+
+	// 清理空目录
+	cleanEmptyDirs(logs)
+
+	result.DemotedRules = len(penalizeRules(db, restored))
+	return result
+}
+
+// UndoSelected 撤销批次中选出的部分文件（filo undo --category / --file），批次中的其他文件保持整理后的位置
+// 与 Undo 相同按相反的顺序还原，但只把移回的文件的操作记录标记为已撤销，之后仍可撤销批次中剩下的文件；
+// 已打包归档的文件与同一压缩包中的其他文件一起，需要撤销整个批次
+//
+// 参数:
+//   - db: 数据库连接
+//   - logs: 选出的文件的操作记录
+//
+// 返回值:
+//   - UndoResult: 撤销结果统计
+func UndoSelected(db *storage.Database, logs []storage.OperationLog) UndoResult {
+	result := UndoResult{}
+	var restored []storage.OperationLog
+
+	for i := len(logs) - 1; i >= 0; i-- {
+		log := logs[i]
+		if _, _, archived := splitArchivePath(log.DestPath); archived {
+			result.Errors++
+			result.Details = append(result.Details, fmt.Sprintf("%s: 文件已打包归档，请用 filo undo %s 撤销整个批次", log.Filename, log.BatchID))
+			continue
+		}
+		if _, err := restoreFile(log, &result.Details); err != nil {
+			result.Errors++
+			result.Details = append(result.Details, fmt.Sprintf("%s: %v", log.Filename, err))
+			continue
+		}
+		result.Success++
+		restored = append(restored, log)
+		db.MarkOperationUndone(log.ID)
+	}
+
+	cleanEmptyDirs(restored)
+	result.DemotedRules = len(penalizeRules(db, restored))
+	return result
+}
+
+// UndoFile 撤销批次中的单个文件，批次中的其他文件不受影响
+// 文件移回原位置，该条操作记录标记为已撤销，并清理留下的空目录；
+// 已打包归档的文件与同一压缩包中的其他文件一起，需要撤销整个批次
+//
+// 参数:
+//   - db: 数据库连接
+//   - log: 文件的操作记录
+//
+// 返回值:
+//   - string: 文件移回后的路径（原位置已有同名文件时带 _restored_N 后缀）
+//   - []string: 需要提示用户的问题（如被替换的文件未能恢复）
+//   - error: 文件不存在、已归档或移动失败时返回错误
+func UndoFile(db *storage.Database, log storage.OperationLog) (string, []string, error) {
+	if _, _, archived := splitArchivePath(log.DestPath); archived {
+		return "", nil, fmt.Errorf("文件已打包归档，请用 filo undo %s 撤销整个批次", log.BatchID)
+	}
+	var notes []string
+	path, err := restoreFile(log, &notes)
+	if err != nil {
+		return "", notes, err
+	}
+	if err := db.MarkOperationUndone(log.ID); err != nil {
+		return path, notes, err
+	}
+	cleanEmptyDirs([]storage.OperationLog{log})
+	penalizeRules(db, []storage.OperationLog{log})
+	return path, notes, nil
+}
+
+// penalizeRules 撤销视为对分类规则的否定：按记忆分类的文件，为决定其分类的规则记一次负反馈
+// 多次被撤销的学习规则会被降级或停用（见 storage.PenalizeRules），在 filo rules --review 中查看
+//
+// 返回值:
+//   - []storage.PenalizedRule: 本次被降级或停用的规则
+func penalizeRules(db *storage.Database, logs []storage.OperationLog) []storage.PenalizedRule {
+	var files []storage.RuleFeedback
+	for _, log := range logs {
+		if log.Source != "memory" && log.Source != "rule" {
+			continue
+		}
+		files = append(files, storage.RuleFeedback{
+			Filename:    log.Filename,
+			ParentDir:   filepath.Base(filepath.Dir(log.SourcePath)),
+			Language:    scanner.DetectLanguage(log.Filename),
+			Category:    log.Category,
+			Subcategory: log.Subcategory,
+		})
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	changed, _ := db.PenalizeRules(files, storage.FeedbackUndo)
+	return changed
+}
+
+// restoreFile 将一条操作记录对应的文件移回原位置
+// 原位置已有同名文件时添加 _restored_N 后缀；归档的文件从压缩包中解出；
+// 与已有文件相同而未保留的文件复制回原处，被替换的已有文件从备份目录恢复
+//
+// 参数:
+//   - log: 操作记录
+//   - notes: 被替换的文件未能恢复等不影响撤销结果的问题追加到这里
+//
+// 返回值:
+//   - string: 文件移回后的路径
+//   - error: 文件不存在、无法创建目录或移动失败时返回错误
+func restoreFile(log storage.OperationLog, notes *[]string) (string, error) {
+	// 检查目标文件（或其所在的压缩包）是否存在
+	current := log.DestPath
+	tarball, member, archived := splitArchivePath(log.DestPath)
+	if archived {
+		current = tarball
+	}
+	if _, err := os.Stat(osPath(current)); os.IsNotExist(err) {
+		return "", errors.New("文件不存在")
+	}
+
+	// 确保源目录存在
+	sourceDir := filepath.Dir(log.SourcePath)
+	if err := os.MkdirAll(osPath(sourceDir), 0755); err != nil {
+		return "", errors.New("无法创建目录")
+	}
+
+	// 处理源路径可能已有同名文件的情况
+	destPath := log.SourcePath
+	if _, err := os.Stat(osPath(destPath)); err == nil {
+		// 源位置已有文件，添加后缀
+		ext := filepath.Ext(destPath)
+		base := destPath[:len(destPath)-len(ext)]
+		for i := 1; ; i++ {
+			newPath := fmt.Sprintf("%s_restored_%d%s", base, i, ext)
+			if _, err := os.Stat(osPath(newPath)); os.IsNotExist(err) {
+				destPath = newPath
+				break
+			}
+		}
+	}
+
+	// 移动文件回原位置（归档的文件从压缩包中解出，分类操作压缩过的文件解压）
+	applied := appliedActions(log.PostActions)
+	var err error
+	switch {
+	case archived:
+		err = extractMember(tarball, member, destPath)
+	case applied[ActionCompress]:
+		err = gunzipFile(log.DestPath, destPath)
+	case log.Resolution == ResolvedIdentical && inTrash(log.ReplacedPath):
+		err = restoreFromTrash(log.ReplacedPath, destPath) // 软删除的源文件从回收站放回
+	case log.Resolution == ResolvedIdentical:
+		err = copyFile(log.DestPath, destPath) // 目标位置是原有的文件，保留
+	default:
+		_, err = moveAcross(log.DestPath, destPath, true) // 跨设备复制过去的文件同样复制回来
+	}
+	if err != nil {
+		return "", err
+	}
+	if applied[ActionReadOnly] {
+		setWritable(destPath)
+	}
+	if log.Resolution != ResolvedIdentical {
+		// 删除整理时保存的整理信息（扩展属性随文件移回，JSON 文件留在分类文件夹中）
+		sidecar.Remove(osPath(log.DestPath))
+		sidecar.Remove(osPath(destPath))
+	}
+	if applied[ActionHEICToJPG] {
+		os.Remove(osPath(heicJPGPath(strings.TrimSuffix(log.DestPath, ".gz"))))
+	}
+	if log.Resolution == ResolvedReplaced && log.ReplacedPath != "" {
+		if rerr := restoreReplaced(log.ReplacedPath, log.DestPath); rerr != nil {
+			*notes = append(*notes, fmt.Sprintf("%s: 被替换的文件未能恢复，仍在 %s", log.Filename, log.ReplacedPath))
+		}
+	}
+	return destPath, nil
+}
+
+// cleanEmptyDirs 清理空目录
+// 从文件所在目录逐级向上删除空目录，层数与分类文件夹的层级数相同（至少两级）
+func cleanEmptyDirs(logs []storage.OperationLog) {
+	// 收集所有涉及的目录及需要向上检查的层数
+	dirs := make(map[string]int)
+	for _, log := range logs {
+		dir := filepath.Dir(log.DestPath)
+		folder := folderFor(classifier.Result{Category: log.Category, Subcategory: log.Subcategory})
+		levels := len(strings.Split(filepath.ToSlash(folder), "/"))
+		if levels < 2 {
+			levels = 2
+		}
+		if isMonthFolder(filepath.Base(dir)) {
+			levels++ // 超出容量上限时放入的按月份子文件夹
+		}
+		if levels > dirs[dir] {
+			dirs[dir] = levels
+		}
+	}
+
+	// 尝试删除空目录（先处理较深的目录，上级目录才可能变空）
+	order := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		order = append(order, dir)
+	}
+	depth := func(dir string) int { return strings.Count(dir, string(filepath.Separator)) }
+	sort.Slice(order, func(i, j int) bool { return depth(order[i]) > depth(order[j]) })
+	for _, dir := range order {
+		levels := dirs[dir]
+		for i := 0; i < levels; i++ {
+			// 检查目录是否为空（只剩 filo 生成的说明文件和图标时视为空）
+			folderinfo.Clean(dir)
+			entries, err := os.ReadDir(osPath(dir))
+			if err != nil || len(entries) > 0 {
+				break
+			}
+			os.Remove(osPath(dir))
+			dir = filepath.Dir(dir) // 继续检查上级目录
+		}
+	}
+}
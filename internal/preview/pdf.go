@@ -0,0 +1,167 @@
+// Package preview 文件预览模块
+// pdf.go - 读取 PDF 文档信息中的标题：只扫描文件开头和结尾（文档信息通常在这两处），
+// 依次查找 /Title 字段和 XMP 元数据中的 dc:title
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package preview
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"filo/internal/ui"
+)
+
+var (
+	pdfTitle = regexp.MustCompile(`/Title\s*([(<])`)                              // 文档信息中的标题
+	xmpTitle = regexp.MustCompile(`(?s)<dc:title>.*?<rdf:li[^>]*>(.*?)</rdf:li>`) // XMP 元数据中的标题
+)
+
+// printPDF 显示 PDF 的标题，加密或没有标题时给出说明
+func printPDF(path string) {
+	data, err := readEnds(path, pdfScan)
+	if err != nil {
+		return
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		line(ui.Gray("PDF 已加密，无法读取标题"))
+		return
+	}
+	if title := extractPDFTitle(data); title != "" {
+		line("PDF 标题: " + clip(sanitize(title), lineWidth-10))
+	} else {
+		line(ui.Gray("PDF 没有标题信息"))
+	}
+}
+
+// readEnds 读取文件开头和结尾各 n 字节，文件不超过 2n 时读取全部
+func readEnds(path string, n int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() <= 2*n {
+		return io.ReadAll(file)
+	}
+
+	data := make([]byte, 2*n)
+	if _, err := io.ReadFull(file, data[:n]); err != nil {
+		return nil, err
+	}
+	if _, err := file.ReadAt(data[n:], info.Size()-n); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return data, nil
+}
+
+// extractPDFTitle 从 PDF 内容中提取标题
+// 增量更新的文件中后出现的文档信息较新，取最后一个非空的 /Title；没有时取 XMP 中的 dc:title
+//
+// 参数:
+//   - data: PDF 内容（可以只是开头和结尾）
+//
+// 返回值:
+//   - string: 标题，没有时为空
+func extractPDFTitle(data []byte) string {
+	matches := pdfTitle.FindAllSubmatchIndex(data, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		start := matches[i][3] // 左括号或左尖括号之后
+		var raw []byte
+		if data[start-1] == '(' {
+			raw = pdfLiteral(data[start:])
+		} else if end := bytes.IndexByte(data[start:], '>'); end >= 0 {
+			raw, _ = hex.DecodeString(strings.Join(strings.Fields(string(data[start:start+end])), ""))
+		}
+		if title := strings.TrimSpace(decodePDFString(raw)); title != "" {
+			return title
+		}
+	}
+	if m := xmpTitle.FindSubmatch(data); m != nil {
+		return strings.TrimSpace(string(m[1]))
+	}
+	return ""
+}
+
+// pdfLiteral 解析 PDF 字面字符串（左括号之后的内容），处理转义和成对的括号
+func pdfLiteral(data []byte) []byte {
+	var out []byte
+	depth := 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\\' && i+1 < len(data):
+			i++
+			switch e := data[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r', '\n':
+				// 续行
+			default:
+				if e >= '0' && e <= '7' {
+					v := 0
+					for j := 0; j < 3 && i < len(data) && data[i] >= '0' && data[i] <= '7'; j++ {
+						v = v*8 + int(data[i]-'0')
+						i++
+					}
+					i--
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		case c == '(':
+			depth++
+			out = append(out, c)
+		case c == ')':
+			if depth == 0 {
+				return out
+			}
+			depth--
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// decodePDFString 解码 PDF 字符串：带 BOM 的 UTF-16BE、UTF-8，其余按 Latin-1（近似 PDFDocEncoding）
+func decodePDFString(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		b = b[2:]
+		u := make([]uint16, len(b)/2)
+		for i := range u {
+			u[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+		}
+		return string(utf16.Decode(u))
+	}
+	if utf8.Valid(b) {
+		return string(bytes.TrimPrefix(b, []byte("\xef\xbb\xbf")))
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
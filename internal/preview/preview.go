@@ -0,0 +1,266 @@
+// Package preview 文件预览模块
+// 交互审查时显示待决定文件的快速预览：文本文件的前几行、图片的尺寸和缩略图
+// （iTerm2 / kitty 内联图片，其他终端用字符画）、PDF 的标题，不必打开文件管理器就能判断分类
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package preview
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+
+	"filo/internal/scanner"
+	"filo/internal/ui"
+)
+
+// ==================== 常量定义 ====================
+
+// 预览方式（review_preview）
+const (
+	ModeAuto = "auto" // 文本、PDF 标题、图片尺寸和缩略图（默认）
+	ModeText = "text" // 不显示图片缩略图，适合不支持内联图片又不想看字符画的终端
+	ModeOff  = "off"  // 不预览
+)
+
+// Modes 所有预览方式
+var Modes = []string{ModeAuto, ModeText, ModeOff}
+
+// 预览参数
+const (
+	textLines    = 6            // 文本文件显示的行数
+	textHead     = 4096         // 判断和预览文本时读取的字节数
+	lineWidth    = 72           // 每行最多显示的宽度（中文占 2）
+	thumbSide    = 256          // 内联缩略图长边像素
+	thumbCols    = 32           // 缩略图宽度（字符列）
+	asciiMaxRows = 14           // 字符画最多行数
+	pdfScan      = 256 * 1024   // PDF 从开头和结尾各读取的字节数
+	indent       = "      "     // 预览内容的缩进，与审查界面的说明对齐
+	asciiRamp    = " .:-=+*#%@" // 字符画从暗到亮使用的字符
+)
+
+// ==================== 预览 ====================
+
+// Print 在终端显示文件预览
+// 无法读取或不支持预览的文件不显示任何内容；仅在云端的占位文件不读取，避免触发下载
+//
+// 参数:
+//   - f: 文件信息
+//   - mode: 预览方式（见 Modes）
+func Print(f scanner.FileInfo, mode string) {
+	if mode == ModeOff || f.IsDir || f.Cloud != "" || f.Size == 0 || ui.IsQuiet() {
+		return
+	}
+
+	switch ext := strings.ToLower(f.Extension); {
+	case scanner.ThumbnailExts[ext]:
+		printImage(f.Path, mode)
+	case ext == ".pdf":
+		printPDF(f.Path)
+	default:
+		printText(f.Path)
+	}
+}
+
+// line 输出一行预览内容
+func line(s string) {
+	fmt.Printf("%s%s %s\n", indent, ui.Gray("│"), s)
+}
+
+// ==================== 文本 ====================
+
+// printText 显示文本文件的前几行，二进制文件不显示
+func printText(path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	buf := make([]byte, textHead)
+	n, _ := io.ReadFull(file, buf)
+	data := buf[:n]
+	if !isText(data) {
+		return
+	}
+
+	lines := strings.Split(strings.TrimLeft(string(data), "\ufeff\r\n"), "\n")
+	if n == textHead && len(lines) > 1 {
+		lines = lines[:len(lines)-1] // 最后一行可能被截断
+	}
+	shown := 0
+	for _, l := range lines {
+		if shown == textLines {
+			line(ui.Gray("…"))
+			break
+		}
+		line(clip(sanitize(l), lineWidth))
+		shown++
+	}
+}
+
+// isText 判断内容是否为 UTF-8 文本：不含 NUL，末尾被截断的多字节字符不计
+func isText(data []byte) bool {
+	if len(data) == 0 || bytes.IndexByte(data, 0) >= 0 {
+		return false
+	}
+	for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+		data = data[:len(data)-1]
+	}
+	return utf8.Valid(data)
+}
+
+// sanitize 去掉控制字符（避免文件内容中的转义序列影响终端），制表符换成空格
+func sanitize(s string) string {
+	s = strings.ReplaceAll(s, "\t", "    ")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// clip 按显示宽度截断，中文字符占 2 个宽度
+func clip(s string, width int) string {
+	w := 0
+	for i, r := range s {
+		if r > 127 {
+			w += 2
+		} else {
+			w++
+		}
+		if w > width {
+			return s[:i] + "…"
+		}
+	}
+	return s
+}
+
+// ==================== 图片 ====================
+
+// printImage 显示图片的尺寸和缩略图
+// iTerm2（及 WezTerm）与 kitty 中内联显示，其他终端显示字符画；tmux 中不转发图片协议，同样显示字符画
+func printImage(path string, mode string) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	cfg, format, err := image.DecodeConfig(file)
+	file.Close()
+	if err != nil {
+		return
+	}
+	line(fmt.Sprintf("图片 %d×%d %s", cfg.Width, cfg.Height, strings.ToUpper(format)))
+	if mode != ModeAuto {
+		return
+	}
+
+	img, err := scanner.ThumbnailImage(path, thumbSide)
+	if err != nil {
+		return
+	}
+	switch graphicsProtocol() {
+	case "iterm":
+		printITerm(img)
+	case "kitty":
+		printKitty(img)
+	default:
+		printASCII(img)
+	}
+}
+
+// graphicsProtocol 当前终端支持的内联图片协议，不支持或输出不是终端时为空
+func graphicsProtocol() string {
+	if color.NoColor || os.Getenv("TMUX") != "" {
+		return ""
+	}
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("TERM") == "xterm-kitty":
+		return "kitty"
+	case os.Getenv("TERM_PROGRAM") == "iTerm.app" || os.Getenv("LC_TERMINAL") == "iTerm2" ||
+		os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return "iterm"
+	}
+	return ""
+}
+
+// printITerm 按 iTerm2 内联图片协议显示缩略图
+func printITerm(img image.Image) {
+	var buf bytes.Buffer
+	if jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80}) != nil {
+		return
+	}
+	fmt.Printf("%s\x1b]1337;File=inline=1;size=%d;width=%d;preserveAspectRatio=1:%s\a\n",
+		indent, buf.Len(), thumbCols, base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+// printKitty 按 kitty 图形协议显示缩略图（PNG，分块传输）
+func printKitty(img image.Image) {
+	var buf bytes.Buffer
+	if png.Encode(&buf, img) != nil {
+		return
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+	fmt.Print(indent)
+	for first := true; len(data) > 0; first = false {
+		chunk := data
+		if len(chunk) > 4096 {
+			chunk = chunk[:4096]
+		}
+		data = data[len(chunk):]
+		more := 0
+		if len(data) > 0 {
+			more = 1
+		}
+		if first {
+			fmt.Printf("\x1b_Ga=T,f=100,c=%d,m=%d;%s\x1b\\", thumbCols, more, chunk)
+		} else {
+			fmt.Printf("\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	fmt.Println()
+}
+
+// printASCII 以字符画显示缩略图，字符高约为宽的两倍，行数按比例减半
+func printASCII(img image.Image) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return
+	}
+	cols := thumbCols
+	rows := h * cols / w / 2
+	if rows > asciiMaxRows {
+		rows = asciiMaxRows
+		cols = w * rows * 2 / h
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	if cols < 1 {
+		cols = 1
+	}
+
+	for y := 0; y < rows; y++ {
+		var sb strings.Builder
+		for x := 0; x < cols; x++ {
+			r, g, bl, _ := img.At(b.Min.X+x*w/cols, b.Min.Y+y*h/rows).RGBA()
+			lum := (299*r + 587*g + 114*bl) / 1000 >> 8 // 0-255
+			sb.WriteByte(asciiRamp[int(lum)*(len(asciiRamp)-1)/255])
+		}
+		line(sb.String())
+	}
+}
@@ -0,0 +1,216 @@
+// Package privacy 文件名脱敏模块
+// 使用远程提供方时，文件名在发送前把手机号、身份证号、邮箱等个人信息替换为占位符（如 [电话1]），
+// 模型返回的分类路径、理由和关键词在本机换回原文。同一次运行中相同的信息使用相同的占位符，
+// 模型仍能看出哪些文件属于同一个人
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package privacy
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ==================== 常量定义 ====================
+
+// 个人信息的类型，同时作为占位符的名称
+const (
+	KindPhone    = "电话"
+	KindIDNumber = "证件号"
+	KindEmail    = "邮箱"
+)
+
+// ==================== 类型定义 ====================
+
+// Redaction 一处被隐去的个人信息
+type Redaction struct {
+	File        string // 所在的文件名
+	Kind        string // 类型（电话、证件号、邮箱）
+	Original    string // 原文
+	Placeholder string // 发送给模型的占位符
+}
+
+// detector 一类个人信息的识别方式
+type detector struct {
+	kind   string
+	re     *regexp.Regexp
+	digits bool                // 前后不能紧接数字（避免从更长的数字串中截取）
+	valid  func(s string) bool // 额外校验，为 nil 时不校验
+}
+
+// detectors 按顺序识别：邮箱中可能含有数字，先于号码识别；身份证号中含有类似手机号的片段，先于手机号识别
+var detectors = []detector{
+	{kind: KindEmail, re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)},
+	{kind: KindIDNumber, re: regexp.MustCompile(`[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]`), digits: true, valid: validIDNumber},
+	{kind: KindIDNumber, re: regexp.MustCompile(`\d{3}-\d{2}-\d{4}`), digits: true}, // 美国社会安全号
+	{kind: KindPhone, re: regexp.MustCompile(`(?:\+?86[- ]?)?1[3-9]\d(?:[- ]?\d{4}){2}`), digits: true},
+	{kind: KindPhone, re: regexp.MustCompile(`0\d{2,3}-\d{7,8}`), digits: true},                              // 固定电话（带区号）
+	{kind: KindPhone, re: regexp.MustCompile(`\+\d{1,3}[- ]?\d{2,4}[- ]?\d{3,4}[- ]?\d{3,4}`), digits: true}, // 国际号码
+}
+
+// Redactor 文件名脱敏器，记录本次运行中原文与占位符的对应关系
+// 可在多个批次间并发使用；nil 表示不脱敏，各方法原样返回
+type Redactor struct {
+	mu           sync.Mutex
+	placeholders map[string]string // 原文 -> 占位符
+	counts       map[string]int    // 各类型已分配的占位符数
+	replacer     *strings.Replacer // 占位符 -> 原文，对应关系变化后重建
+	redactions   []Redaction       // 隐去记录，供 --show-redactions 查看
+}
+
+// NewRedactor 创建文件名脱敏器
+func NewRedactor() *Redactor {
+	return &Redactor{
+		placeholders: make(map[string]string),
+		counts:       make(map[string]int),
+	}
+}
+
+// ==================== 脱敏与还原 ====================
+
+// Mask 隐去文件名中的个人信息
+//
+// 参数:
+//   - name: 文件名
+//
+// 返回值:
+//   - string: 个人信息替换为占位符后的文件名，没有个人信息时原样返回
+func (r *Redactor) Mask(name string) string {
+	if r == nil {
+		return name
+	}
+	masked := name
+	for _, d := range detectors {
+		masked = r.maskWith(d, name, masked)
+	}
+	return masked
+}
+
+// maskWith 用一种识别方式替换文件名中的个人信息
+func (r *Redactor) maskWith(d detector, file, s string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range d.re.FindAllStringIndex(s, -1) {
+		start, end := m[0], m[1]
+		if d.kind == KindEmail {
+			end = start + len(trimExtension(s[start:end], s[end:] == ""))
+		}
+		if d.digits && (isDigitAt(s, start-1) || isDigitAt(s, end)) {
+			continue
+		}
+		if d.valid != nil && !d.valid(s[start:end]) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(r.placeholder(d.kind, file, s[start:end]))
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// placeholder 返回原文对应的占位符，首次出现时分配并记录
+func (r *Redactor) placeholder(kind, file, original string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.placeholders[original]
+	if !ok {
+		r.counts[kind]++
+		p = fmt.Sprintf("[%s%d]", kind, r.counts[kind])
+		r.placeholders[original] = p
+		r.replacer = nil
+	}
+	r.redactions = append(r.redactions, Redaction{File: file, Kind: kind, Original: original, Placeholder: p})
+	return p
+}
+
+// Restore 把文本中的占位符换回原文
+func (r *Redactor) Restore(s string) string {
+	if r == nil || !strings.Contains(s, "[") {
+		return s
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.replacer == nil {
+		pairs := make([]string, 0, 2*len(r.placeholders))
+		for original, p := range r.placeholders {
+			pairs = append(pairs, p, original)
+		}
+		r.replacer = strings.NewReplacer(pairs...)
+	}
+	return r.replacer.Replace(s)
+}
+
+// RestoreMap 把模型返回结果中所有字符串里的占位符换回原文（包括嵌套的对象和数组）
+func (r *Redactor) RestoreMap(m map[string]interface{}) {
+	if r == nil {
+		return
+	}
+	for k, v := range m {
+		m[k] = r.restoreValue(v)
+	}
+}
+
+// restoreValue 还原任意 JSON 值中的字符串
+func (r *Redactor) restoreValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.Restore(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = r.restoreValue(v[i])
+		}
+	case map[string]interface{}:
+		r.RestoreMap(v)
+	}
+	return v
+}
+
+// Redactions 返回本次运行中隐去的个人信息，按隐去的先后顺序
+func (r *Redactor) Redactions() []Redaction {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Redaction(nil), r.redactions...)
+}
+
+// ==================== 辅助函数 ====================
+
+// trimExtension 邮箱位于文件名末尾时，正则会把扩展名当作域名的一部分（如 a@b.com.pdf），
+// 去掉扩展名后仍是完整的邮箱时只隐去邮箱部分
+func trimExtension(email string, atEnd bool) string {
+	ext := filepath.Ext(email)
+	if !atEnd || ext == "" {
+		return email
+	}
+	trimmed := strings.TrimSuffix(email, ext)
+	if strings.Contains(trimmed[strings.Index(trimmed, "@")+1:], ".") {
+		return trimmed
+	}
+	return email
+}
+
+// isDigitAt 第 i 个字节是否为数字，越界时为 false
+func isDigitAt(s string, i int) bool {
+	return i >= 0 && i < len(s) && s[i] >= '0' && s[i] <= '9'
+}
+
+// validIDNumber 校验 18 位身份证号的校验码（GB 11643），排除恰好符合格式的普通数字串
+func validIDNumber(s string) bool {
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i, w := range weights {
+		sum += int(s[i]-'0') * w
+	}
+	return strings.EqualFold(string("10X98765432"[sum%11]), s[17:])
+}
@@ -0,0 +1,142 @@
+// Package quarantine 隔离模块
+// 可执行文件、脚本和安装包不与普通文档混放，而是归入隔离区并记录 SHA-256，
+// 哈希在本地白名单中的文件视为可信，照常分类
+//
+// 白名单为文本文件，每行一个 SHA-256，# 之后为注释
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package quarantine
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"filo/internal/config"
+)
+
+// ==================== 常量定义 ====================
+
+// Category 隔离文件的主分类
+const Category = "隔离区"
+
+// 隔离文件的子分类
+const (
+	KindInstaller  = "安装包"   // 安装程序和软件包
+	KindScript     = "脚本"    // Shell、批处理等脚本
+	KindExecutable = "可执行文件" // 可直接运行的程序
+)
+
+// kinds 需要隔离的扩展名及其子分类
+var kinds = map[string]string{
+	".dmg": KindInstaller, ".pkg": KindInstaller, ".msi": KindInstaller, ".mpkg": KindInstaller,
+	".deb": KindInstaller, ".rpm": KindInstaller, ".apk": KindInstaller, ".appimage": KindInstaller,
+	".sh": KindScript, ".bash": KindScript, ".zsh": KindScript, ".command": KindScript,
+	".bat": KindScript, ".cmd": KindScript, ".ps1": KindScript, ".vbs": KindScript,
+	".exe": KindExecutable, ".com": KindExecutable, ".scr": KindExecutable, ".bin": KindExecutable,
+	".run": KindExecutable, ".jar": KindExecutable,
+}
+
+// 白名单缓存（同一进程只读取一次）
+var (
+	allowlist     map[string]bool
+	allowlistOnce sync.Once
+	allowlistErr  error
+)
+
+// ==================== 判断函数 ====================
+
+// Kind 返回扩展名对应的隔离子分类，不需要隔离时返回空
+func Kind(ext string) string {
+	return kinds[strings.ToLower(ext)]
+}
+
+// Hash 计算文件的 SHA-256（十六进制小写）
+func Hash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ==================== 白名单 ====================
+
+// AllowlistPath 白名单文件路径
+// 未配置时为 ~/.filo/allowlist.txt
+func AllowlistPath() string {
+	cfg := config.Get()
+	if cfg.QuarantineAllowlist != "" {
+		return cfg.QuarantineAllowlist
+	}
+	return filepath.Join(cfg.DataDir, "allowlist.txt")
+}
+
+// Allowed 判断哈希是否在白名单中
+// 白名单文件不存在时视为空；读取失败时返回错误，调用方应按未在白名单处理
+func Allowed(hash string) (bool, error) {
+	allowlistOnce.Do(func() {
+		allowlist, allowlistErr = loadAllowlist(AllowlistPath())
+	})
+	if allowlistErr != nil {
+		return false, allowlistErr
+	}
+	return allowlist[strings.ToLower(hash)], nil
+}
+
+// loadAllowlist 读取白名单文件
+func loadAllowlist(path string) (map[string]bool, error) {
+	list := map[string]bool{}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		// 兼容 sha256sum 的输出格式：哈希 + 空格 + 文件名
+		if fields := strings.Fields(line); len(fields) > 0 {
+			list[strings.ToLower(fields[0])] = true
+		}
+	}
+	return list, sc.Err()
+}
+
+// Allow 将哈希追加到白名单文件
+// comment 通常为文件名，写在行尾便于辨认
+func Allow(hash, comment string) error {
+	path := AllowlistPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s  # %s\n", strings.ToLower(hash), comment)
+	return err
+}
@@ -0,0 +1,403 @@
+// Package ruleimport 规则导入模块
+// hazel.go - 解析 Hazel 导出的规则（.hazelrules）
+//
+// Hazel 导出的规则文件是二进制 plist，需要先用 macOS 自带的 plutil 转换为 XML:
+//
+//	plutil -convert xml1 -o rules.xml 下载.hazelrules
+//
+// 规则对象经 NSKeyedArchiver 归档，解析时先还原对象引用，再按键名找出
+// 规则（含条件和操作列表）、条件（属性、比较方式、取值）和移动操作的目标文件夹。
+// 可转换的条件: 名称 / 扩展名 / 全名 的 是、包含、开头是、结尾是
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package ruleimport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ==================== plist 解析 ====================
+
+// plistUID NSKeyedArchiver 中的对象引用
+type plistUID uint64
+
+// parsePlist 解析 XML plist
+//
+// 返回值:
+//   - interface{}: 根对象（map[string]interface{} / []interface{} / string / int64 / float64 / bool / []byte / plistUID）
+//   - error: 文件不是 XML plist 时返回错误
+func parsePlist(data []byte) (interface{}, error) {
+	if bytes.HasPrefix(data, []byte("bplist")) {
+		return nil, fmt.Errorf("这是二进制 plist，请先执行 plutil -convert xml1 转换为 XML")
+	}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("无法解析 plist: %w", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local != "plist" {
+			return decodePlistValue(dec, se)
+		}
+	}
+}
+
+// decodePlistValue 解析以 start 开头的一个 plist 值
+func decodePlistValue(dec *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]interface{})
+		key := ""
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if key, err = plistText(dec); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				v, err := decodePlistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = v
+			case xml.EndElement:
+				return resolveUIDDict(dict), nil
+			}
+		}
+	case "array":
+		var list []interface{}
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				v, err := decodePlistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			case xml.EndElement:
+				return list, nil
+			}
+		}
+	case "true", "false":
+		if err := dec.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	text, err := plistText(dec)
+	if err != nil {
+		return nil, err
+	}
+	switch start.Name.Local {
+	case "integer":
+		return strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	case "real":
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	case "data":
+		return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+	default: // string、date
+		return text, nil
+	}
+}
+
+// plistText 读取元素的文本内容直到元素结束
+func plistText(dec *xml.Decoder) (string, error) {
+	var b strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			b.Write(t)
+		case xml.EndElement:
+			return b.String(), nil
+		}
+	}
+}
+
+// resolveUIDDict XML 中的对象引用写作 <dict><key>CF$UID</key><integer>N</integer></dict>
+func resolveUIDDict(dict map[string]interface{}) interface{} {
+	if len(dict) == 1 {
+		if n, ok := dict["CF$UID"].(int64); ok {
+			return plistUID(n)
+		}
+	}
+	return dict
+}
+
+// ==================== 还原归档对象 ====================
+
+// unarchive 还原 NSKeyedArchiver 归档：将对象引用替换为对象本身，
+// NSDictionary / NSArray / NSString / NSURL 还原为普通的映射、列表和字符串。
+// 不是归档格式时原样返回
+func unarchive(root interface{}) interface{} {
+	top, ok := root.(map[string]interface{})
+	if !ok {
+		return root
+	}
+	objects, ok := top["$objects"].([]interface{})
+	if !ok {
+		return root
+	}
+
+	resolving := make(map[plistUID]bool) // 防止循环引用
+	var resolve func(v interface{}) interface{}
+	resolve = func(v interface{}) interface{} {
+		switch val := v.(type) {
+		case plistUID:
+			if int(val) >= len(objects) || resolving[val] {
+				return nil
+			}
+			resolving[val] = true
+			defer delete(resolving, val)
+			obj := objects[val]
+			if s, ok := obj.(string); ok && s == "$null" {
+				return nil
+			}
+			return resolve(obj)
+		case []interface{}:
+			list := make([]interface{}, len(val))
+			for i, item := range val {
+				list[i] = resolve(item)
+			}
+			return list
+		case map[string]interface{}:
+			if keys, ok := val["NS.keys"].([]interface{}); ok {
+				values, _ := val["NS.objects"].([]interface{})
+				dict := make(map[string]interface{})
+				for i, k := range keys {
+					if ks, ok := resolve(k).(string); ok && i < len(values) {
+						dict[ks] = resolve(values[i])
+					}
+				}
+				return dict
+			}
+			if items, ok := val["NS.objects"]; ok {
+				return resolve(items)
+			}
+			if s, ok := val["NS.string"]; ok {
+				return resolve(s)
+			}
+			if rel, ok := val["NS.relative"]; ok {
+				return resolve(rel)
+			}
+			dict := make(map[string]interface{})
+			for k, item := range val {
+				if k != "$class" {
+					dict[k] = resolve(item)
+				}
+			}
+			return dict
+		default:
+			return v
+		}
+	}
+
+	if t, ok := top["$top"].(map[string]interface{}); ok {
+		return resolve(t)
+	}
+	return root
+}
+
+// ==================== 规则识别 ====================
+
+// parseHazel 解析 Hazel 导出并转换为 XML 的规则文件
+func parseHazel(data []byte) ([]sourceRule, error) {
+	root, err := parsePlist(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []sourceRule
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch val := v.(type) {
+		case map[string]interface{}:
+			if conds, actions, ok := hazelRuleParts(val); ok {
+				rules = append(rules, hazelRule(val, conds, actions, len(rules)))
+				return
+			}
+			for _, k := range sortedKeys(val) {
+				walk(val[k])
+			}
+		case []interface{}:
+			for _, item := range val {
+				walk(item)
+			}
+		}
+	}
+	walk(unarchive(root))
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("文件中没有找到 Hazel 规则")
+	}
+	return rules, nil
+}
+
+// hazelRuleParts 判断对象是否为规则：同时含有条件列表和操作列表
+func hazelRuleParts(obj map[string]interface{}) ([]interface{}, []interface{}, bool) {
+	var conds, actions []interface{}
+	for k, v := range obj {
+		list, ok := v.([]interface{})
+		if !ok {
+			continue
+		}
+		key := strings.ToLower(k)
+		switch {
+		case strings.Contains(key, "condition"):
+			conds = list
+		case strings.Contains(key, "action"):
+			actions = list
+		}
+	}
+	return conds, actions, conds != nil && actions != nil
+}
+
+// hazelRule 将 Hazel 规则对象转换为 sourceRule
+func hazelRule(obj map[string]interface{}, conds, actions []interface{}, index int) sourceRule {
+	r := sourceRule{name: fmt.Sprintf("规则 %d", index+1), match: matchAll}
+	if name := lookupString(obj, "name", "displayname", "title"); name != "" {
+		r.name = name
+	}
+	// 「满足以下任一条件」
+	if m := strings.ToLower(lookupString(obj, "match", "conjunction", "type")); strings.Contains(m, "any") {
+		r.match = matchAny
+	}
+
+	for _, c := range conds {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		attr := lookupString(cond, "attribute", "key", "property")
+		op := lookupString(cond, "operator", "comparison", "predicate")
+		value := lookupString(cond, "value", "string", "text")
+		if converted, ok := hazelCondition(attr, op, value); ok {
+			r.conditions = append(r.conditions, converted)
+		} else {
+			r.dropped = append(r.dropped, strings.TrimSpace(attr+" "+op+" "+value))
+		}
+	}
+
+	for _, a := range actions {
+		action, ok := a.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind := strings.ToLower(lookupString(action, "type", "action", "kind", "name"))
+		if !strings.Contains(kind, "move") && !strings.Contains(kind, "sort") {
+			continue
+		}
+		if dest := findPath(action); dest != "" && r.dest == "" {
+			r.dest = dest
+		}
+	}
+	return r
+}
+
+// hazelCondition 转换单个条件
+// 名称不含扩展名，全名含扩展名；比较方式不区分大小写、忽略空格
+func hazelCondition(attr, op, value string) (condition, bool) {
+	attr = strings.ToLower(strings.ReplaceAll(attr, " ", ""))
+	op = strings.ToLower(strings.NewReplacer(" ", "", "_", "").Replace(op))
+	if value == "" {
+		return condition{}, false
+	}
+	switch op {
+	case "is", "equals", "startswith", "beginswith", "endswith", "contains":
+	default:
+		return condition{}, false
+	}
+	if op == "beginswith" {
+		op = "startswith"
+	}
+
+	switch attr {
+	case "extension":
+		if op != "is" && op != "equals" {
+			return condition{}, false
+		}
+		return condition{kind: condExtension, values: []string{normalizeExt(value)}}, true
+	case "name":
+		return condition{kind: condName, values: []string{nameMatchGlob(op, value)}}, true
+	case "fullname", "filename":
+		return condition{kind: condFullName, values: []string{nameMatchGlob(op, value)}}, true
+	}
+	return condition{}, false
+}
+
+// sortedKeys 按键名排序，保证遍历顺序稳定
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lookupString 按候选键名（不区分大小写）查找字符串值
+func lookupString(obj map[string]interface{}, keys ...string) string {
+	for _, want := range keys {
+		for k, v := range obj {
+			if s, ok := v.(string); ok && strings.EqualFold(k, want) {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// findPath 在操作对象中查找目标文件夹路径（绝对路径、~ 开头的路径或 file:// 地址）
+func findPath(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		if strings.HasPrefix(val, "file://") {
+			if u, err := url.Parse(val); err == nil {
+				return u.Path
+			}
+		}
+		if strings.HasPrefix(val, "/") || strings.HasPrefix(val, "~/") {
+			return val
+		}
+	case map[string]interface{}:
+		for _, k := range sortedKeys(val) {
+			if p := findPath(val[k]); p != "" {
+				return p
+			}
+		}
+	case []interface{}:
+		for _, item := range val {
+			if p := findPath(item); p != "" {
+				return p
+			}
+		}
+	}
+	return ""
+}
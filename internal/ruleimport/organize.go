@@ -0,0 +1,220 @@
+// Package ruleimport 规则导入模块
+// organize.go - 解析 organize-tool（https://github.com/tfeldmann/organize）的 config.yaml
+//
+// 支持新旧两种写法（name / filename、move: 路径 / move: {dest: 路径}），
+// 可转换的过滤器: extension、name（match / startswith / contains / endswith）、regex
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package ruleimport
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseOrganize 解析 organize-tool 的配置文件
+func parseOrganize(data []byte) ([]sourceRule, error) {
+	var config struct {
+		Rules []map[string]interface{} `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("无法解析 YAML: %w", err)
+	}
+	if len(config.Rules) == 0 {
+		return nil, fmt.Errorf("文件中没有 rules")
+	}
+
+	rules := make([]sourceRule, 0, len(config.Rules))
+	for i, raw := range config.Rules {
+		r := sourceRule{name: fmt.Sprintf("规则 %d", i+1), match: matchAll}
+		if name, ok := raw["name"].(string); ok && name != "" {
+			r.name = name
+		}
+		if mode, ok := raw["filter_mode"].(string); ok {
+			r.match = strings.ToLower(mode)
+		}
+		for _, f := range toList(raw["filters"]) {
+			parseOrganizeFilter(f, &r)
+		}
+		for _, a := range toList(raw["actions"]) {
+			if dest := organizeMoveDest(a); dest != "" && r.dest == "" {
+				r.dest = dest
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// parseOrganizeFilter 解析一个过滤器，可转换的加入 r.conditions，其余记入 r.dropped
+// 过滤器写作单键映射（如 extension: pdf），不带参数时只写名称
+func parseOrganizeFilter(filter interface{}, r *sourceRule) {
+	key, value := singleKey(filter)
+	if key == "" {
+		return
+	}
+	if strings.HasPrefix(key, "not ") {
+		r.dropped = append(r.dropped, key)
+		return
+	}
+
+	switch key {
+	case "extension":
+		var exts []string
+		for _, e := range toStrings(value) {
+			if e = normalizeExt(e); e != "" {
+				exts = append(exts, e)
+			}
+		}
+		if len(exts) > 0 { // 不带参数表示任意扩展名，不构成限制
+			r.conditions = append(r.conditions, condition{kind: condExtension, values: exts})
+		}
+	case "name", "filename":
+		globs := organizeNameGlobs(value)
+		if globs == nil {
+			r.dropped = append(r.dropped, key)
+			return
+		}
+		r.conditions = append(r.conditions, condition{kind: condName, values: globs})
+	case "regex":
+		expr := ""
+		if m, ok := value.(map[string]interface{}); ok {
+			expr, _ = m["expr"].(string)
+		} else {
+			expr, _ = value.(string)
+		}
+		if expr == "" {
+			r.dropped = append(r.dropped, key)
+			return
+		}
+		r.conditions = append(r.conditions, condition{kind: condRegex, values: []string{expr}})
+	default:
+		r.dropped = append(r.dropped, key)
+	}
+}
+
+// organizeNameGlobs 将 name 过滤器转换为文件名（不含扩展名）通配符
+// 字符串参数为 match 模式，其中的 {占位符} 视为 *；
+// startswith / contains / endswith 可以同时出现，各自可以是列表（任一满足）
+//
+// 返回值:
+//   - []string: 通配符列表（任一满足），无法转换时为 nil
+func organizeNameGlobs(value interface{}) []string {
+	if s, ok := value.(string); ok {
+		return []string{matchToGlob(s)}
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if cs, ok := m["case_sensitive"].(bool); ok && cs {
+		return nil // filo 的通配符不区分大小写，区分大小写的条件会变宽
+	}
+
+	globs := []string{""}
+	if match, ok := m["match"].(string); ok {
+		globs = []string{matchToGlob(match)}
+	}
+	for _, op := range []string{"startswith", "contains", "endswith"} {
+		values := toStrings(m[op])
+		if len(values) == 0 {
+			continue
+		}
+		var next []string
+		for _, g := range globs {
+			for _, v := range values {
+				next = append(next, joinGlob(g, nameMatchGlob(op, v)))
+			}
+		}
+		globs = next
+	}
+	if len(globs) == 1 && globs[0] == "" {
+		return nil
+	}
+	return globs
+}
+
+// joinGlob 合并两个文件名通配符（两者都需满足），相邻的 * 合并为一个
+func joinGlob(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if !strings.HasSuffix(a, "*") {
+		a += "*"
+	}
+	return a + strings.TrimPrefix(b, "*")
+}
+
+// matchToGlob 将 organize 的 match 模式转换为通配符：{占位符} 替换为 *
+func matchToGlob(match string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range match {
+		switch {
+		case r == '{':
+			if depth == 0 {
+				b.WriteRune('*')
+			}
+			depth++
+		case r == '}' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// organizeMoveDest 返回 move 操作的目标文件夹，其他操作返回空字符串
+func organizeMoveDest(action interface{}) string {
+	key, value := singleKey(action)
+	if key != "move" {
+		return ""
+	}
+	if m, ok := value.(map[string]interface{}); ok {
+		dest, _ := m["dest"].(string)
+		return dest
+	}
+	dest, _ := value.(string)
+	return dest
+}
+
+// singleKey 读取单键映射的键（小写）和值；只有名称的字符串条目值为 nil
+func singleKey(item interface{}) (string, interface{}) {
+	switch v := item.(type) {
+	case string:
+		return strings.ToLower(v), nil
+	case map[string]interface{}:
+		for k, val := range v {
+			return strings.ToLower(k), val
+		}
+	}
+	return "", nil
+}
+
+// toList 将单个值或列表统一为列表
+func toList(v interface{}) []interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return val
+	default:
+		return []interface{}{val}
+	}
+}
+
+// toStrings 将字符串或字符串列表统一为字符串列表
+func toStrings(v interface{}) []string {
+	var list []string
+	for _, item := range toList(v) {
+		if s := strings.TrimSpace(fmt.Sprint(item)); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
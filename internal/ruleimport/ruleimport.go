@@ -0,0 +1,330 @@
+// Package ruleimport 规则导入模块
+// 将其他文件整理工具（macOS Hazel、Python organize-tool）的规则转换为 filo 规则，
+// 方便已有自动化规则的用户迁移
+//
+// filo 的规则只看文件名，因此只转换按文件名、扩展名、正则匹配并移动到文件夹的规则；
+// 按内容、大小、日期等条件匹配，或执行重命名、删除等操作的规则会被跳过并说明原因
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package ruleimport
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filo/internal/storage"
+)
+
+// ==================== 常量定义 ====================
+
+// 支持导入的规则格式
+const (
+	FormatHazel    = "hazel"         // Hazel 导出的规则（XML plist）
+	FormatOrganize = "organize-yaml" // organize-tool 的 config.yaml
+)
+
+// Formats 支持导入的规则格式
+var Formats = []string{FormatHazel, FormatOrganize}
+
+// 条件类型
+const (
+	condName      = "name"      // 文件名（不含扩展名）
+	condFullName  = "fullname"  // 完整文件名（含扩展名）
+	condExtension = "extension" // 扩展名
+	condRegex     = "regex"     // 正则表达式（匹配完整文件名）
+)
+
+// 条件的组合方式
+const (
+	matchAll  = "all"  // 全部满足
+	matchAny  = "any"  // 任一满足
+	matchNone = "none" // 全部不满足（无法转换）
+)
+
+// ==================== 数据结构 ====================
+
+// Rule 转换得到的一条 filo 规则
+type Rule struct {
+	Source      string   // 原规则名称
+	Pattern     string   // 匹配模式
+	PatternType string   // 模式类型: extension / glob / regex
+	Path        []string // 目标分类路径（由原规则的目标文件夹得出）
+}
+
+// Skipped 无法转换的原规则或条件
+type Skipped struct {
+	Source string // 原规则名称
+	Reason string // 跳过原因
+}
+
+// Result 导入结果
+type Result struct {
+	Rules   []Rule    // 转换得到的规则
+	Skipped []Skipped // 无法转换的规则或条件
+}
+
+// condition 原规则中的一个匹配条件
+// values 中的多个取值为「任一」关系；文件名条件的取值为通配符（不含扩展名部分），
+// 完整文件名条件的取值为完整文件名的通配符
+type condition struct {
+	kind   string
+	values []string
+}
+
+// sourceRule 从原格式解析出的规则，与格式无关
+type sourceRule struct {
+	name       string
+	match      string      // 条件组合方式
+	conditions []condition // 可以转换的条件
+	dropped    []string    // 无法转换的条件说明
+	dest       string      // 目标文件夹，为空表示没有移动操作
+}
+
+// ==================== 导入入口 ====================
+
+// Parse 解析其他工具的规则文件并转换为 filo 规则
+//
+// 参数:
+//   - format: 规则格式（见 Formats）
+//   - data: 规则文件内容
+//   - base: 目标文件夹的基准目录，目标文件夹相对它的路径作为分类路径；为空时使用主目录
+//
+// 返回值:
+//   - *Result: 转换得到的规则和被跳过的规则
+//   - error: 格式不支持或文件无法解析时返回错误
+func Parse(format string, data []byte, base string) (*Result, error) {
+	var rules []sourceRule
+	var err error
+	switch format {
+	case FormatHazel:
+		rules, err = parseHazel(data)
+	case FormatOrganize:
+		rules, err = parseOrganize(data)
+	default:
+		return nil, fmt.Errorf("不支持的格式: %s（可选: %s）", format, strings.Join(Formats, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if base == "" {
+		base, _ = os.UserHomeDir()
+	}
+	result := &Result{}
+	for _, r := range rules {
+		convert(r, expandHome(base), result)
+	}
+	return result, nil
+}
+
+// convert 将一条原规则转换为 filo 规则，结果追加到 result
+func convert(r sourceRule, base string, result *Result) {
+	skip := func(format string, args ...interface{}) {
+		result.Skipped = append(result.Skipped, Skipped{Source: r.name, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	if r.dest == "" {
+		skip("没有移动到文件夹的操作")
+		return
+	}
+	path := destPath(r.dest, base)
+	if len(path) == 0 {
+		skip("无法从目标文件夹 %s 得出分类", r.dest)
+		return
+	}
+
+	var patterns [][2]string // {模式类型, 模式}
+	switch r.match {
+	case matchNone:
+		skip("不支持「全部不满足」的条件组合")
+		return
+	case matchAny:
+		// 任一满足：每个条件单独成为一条规则，无法转换的条件忽略
+		for _, d := range r.dropped {
+			skip("忽略条件: %s", d)
+		}
+		for _, c := range r.conditions {
+			for _, v := range c.values {
+				patterns = append(patterns, single(c.kind, v))
+			}
+		}
+	default:
+		// 全部满足：忽略任何条件都会让规则变宽，有无法转换的条件时整条跳过
+		if len(r.dropped) > 0 {
+			skip("包含无法转换的条件: %s", strings.Join(r.dropped, "、"))
+			return
+		}
+		var err error
+		if patterns, err = combine(r.conditions); err != nil {
+			skip("%v", err)
+			return
+		}
+	}
+	if len(patterns) == 0 {
+		skip("没有可转换的文件名或扩展名条件")
+		return
+	}
+
+	for _, p := range patterns {
+		if storage.IsPatternRule(p[0]) {
+			if _, err := storage.CompilePattern(p[0], p[1]); err != nil {
+				skip("无效的%s「%s」: %v", p[0], p[1], err)
+				continue
+			}
+		}
+		result.Rules = append(result.Rules, Rule{Source: r.name, PatternType: p[0], Pattern: p[1], Path: path})
+	}
+}
+
+// single 单个条件取值对应的规则
+func single(kind, value string) [2]string {
+	switch kind {
+	case condExtension:
+		return [2]string{"extension", value}
+	case condRegex:
+		return [2]string{storage.PatternRegex, value}
+	case condFullName:
+		return [2]string{storage.PatternGlob, value}
+	default:
+		return [2]string{storage.PatternGlob, nameGlob(value, "")}
+	}
+}
+
+// combine 将全部满足的多个条件合并为规则
+// 文件名和扩展名合并为通配符（每种取值组合一条）；正则和完整文件名只能单独使用
+func combine(conditions []condition) ([][2]string, error) {
+	var names, exts, alone []condition
+	for _, c := range conditions {
+		switch c.kind {
+		case condName:
+			names = append(names, c)
+		case condExtension:
+			exts = append(exts, c)
+		default:
+			alone = append(alone, c)
+		}
+	}
+	if len(names) > 1 || len(exts) > 1 || len(alone) > 1 {
+		return nil, fmt.Errorf("同类条件出现多次，无法合并为一条规则")
+	}
+	if len(alone) > 0 {
+		if len(names)+len(exts) > 0 {
+			return nil, fmt.Errorf("正则或完整文件名与其他条件同时满足的规则无法转换")
+		}
+		var patterns [][2]string
+		for _, v := range alone[0].values {
+			patterns = append(patterns, single(alone[0].kind, v))
+		}
+		return patterns, nil
+	}
+
+	var patterns [][2]string
+	switch {
+	case len(names) > 0 && len(exts) > 0:
+		for _, n := range names[0].values {
+			for _, e := range exts[0].values {
+				patterns = append(patterns, [2]string{storage.PatternGlob, nameGlob(n, e)})
+			}
+		}
+	case len(names) > 0:
+		for _, n := range names[0].values {
+			patterns = append(patterns, single(condName, n))
+		}
+	case len(exts) > 0:
+		for _, e := range exts[0].values {
+			patterns = append(patterns, single(condExtension, e))
+		}
+	}
+	return patterns, nil
+}
+
+// ==================== 辅助函数 ====================
+
+// nameGlob 由文件名（不含扩展名）通配符和扩展名生成完整文件名的通配符
+// 没有扩展名时允许任意扩展名
+func nameGlob(name, ext string) string {
+	if ext != "" {
+		return name + ext
+	}
+	if strings.HasSuffix(name, "*") {
+		return name
+	}
+	return name + ".*"
+}
+
+// escapeGlob 转义文本中的通配符字符，使其按字面匹配
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r == '*' || r == '?' || r == '[' {
+			b.WriteString("[" + string(r) + "]")
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// nameMatchGlob 按「开头/包含/结尾/等于」生成文件名（不含扩展名）通配符
+func nameMatchGlob(op, value string) string {
+	value = escapeGlob(value)
+	switch op {
+	case "startswith":
+		return value + "*"
+	case "endswith":
+		return "*" + value
+	case "contains":
+		return "*" + value + "*"
+	default:
+		return value
+	}
+}
+
+// normalizeExt 规范化扩展名：小写并带点
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext == "" || strings.HasPrefix(ext, ".") {
+		return ext
+	}
+	return "." + ext
+}
+
+// destPath 由目标文件夹得出分类路径
+// 目标文件夹中的占位符（如 {extension}）及之后的部分去掉；
+// 位于基准目录下时取相对路径，否则取最后两级
+func destPath(dest, base string) []string {
+	dest = strings.TrimPrefix(dest, "file://")
+	if i := strings.Index(dest, "{"); i >= 0 {
+		dest = dest[:i]
+	}
+	dest = filepath.Clean(expandHome(dest))
+
+	var path []string
+	if rel, err := filepath.Rel(base, dest); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		path = strings.Split(filepath.ToSlash(rel), "/")
+	} else {
+		parts := strings.Split(filepath.ToSlash(dest), "/")
+		for _, p := range parts {
+			if p != "" && p != "." {
+				path = append(path, p)
+			}
+		}
+		if len(path) > 2 {
+			path = path[len(path)-2:]
+		}
+	}
+	return path
+}
+
+// expandHome 展开路径开头的 ~
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[1:])
+	}
+	return path
+}
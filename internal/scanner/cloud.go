@@ -0,0 +1,94 @@
+// Package scanner 文件扫描模块
+// cloud.go - 识别云盘同步目录和仅在云端的占位文件
+//
+// Dropbox、OneDrive、iCloud、Google Drive 的「按需下载」会在本机留下占位文件：
+// 文件大小和修改时间都正常，但读取内容会触发完整下载。扫描时识别这些文件，
+// 只按文件名分类，不读取内容；移动到同步目录之外会让同步客户端先下载再移走，
+// 所以默认只在同一个同步目录内移动
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ==================== 常量定义 ====================
+
+// 云盘占位文件的处理方式（cloud_files）
+const (
+	CloudFilesClassify = "classify" // 只按文件名分类，不读取内容（默认）
+	CloudFilesSkip     = "skip"     // 保持原位，不参与分类
+)
+
+// ==================== 同步目录识别 ====================
+
+// SyncRoot 查找路径所在的云盘同步目录
+// 按路径中的目录名识别，如 ~/Dropbox、~/OneDrive - 公司、
+// ~/Library/Mobile Documents（iCloud）、~/Library/CloudStorage/GoogleDrive-xxx
+//
+// 参数:
+//   - path: 文件或目录路径
+//
+// 返回值:
+//   - string: 同步目录的路径，不在同步目录中时为空
+//   - string: 云盘名称（Dropbox、OneDrive、iCloud、Google Drive）
+func SyncRoot(path string) (string, string) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", ""
+	}
+	parts := strings.Split(filepath.ToSlash(abs), "/")
+	for i, part := range parts {
+		provider := syncProvider(part)
+		// macOS 的 File Provider 目录: ~/Library/CloudStorage/<云盘>-<账号>
+		if part == "CloudStorage" && i+1 < len(parts) {
+			i++
+			if provider = syncProvider(parts[i]); provider == "" {
+				provider = parts[i]
+			}
+		}
+		if provider != "" {
+			return filepath.FromSlash(strings.Join(parts[:i+1], "/")), provider
+		}
+	}
+	return "", ""
+}
+
+// syncProvider 根据目录名识别云盘，无法识别时返回空字符串
+func syncProvider(name string) string {
+	switch {
+	case strings.HasPrefix(name, "Dropbox"):
+		return "Dropbox"
+	case strings.HasPrefix(name, "OneDrive"):
+		return "OneDrive"
+	case name == "iCloud Drive" || name == "iCloudDrive" || name == "Mobile Documents":
+		return "iCloud"
+	case strings.HasPrefix(name, "Google Drive") || strings.HasPrefix(name, "GoogleDrive"):
+		return "Google Drive"
+	}
+	return ""
+}
+
+// SameSyncRoot 判断两个路径是否在同一个云盘同步目录中
+func SameSyncRoot(a, b string) bool {
+	rootA, _ := SyncRoot(a)
+	rootB, _ := SyncRoot(b)
+	return rootA != "" && rootA == rootB
+}
+
+// cloudState 判断文件是否只在云端（未下载到本机）
+// 返回云盘名称，文件已完整同步到本机时返回空字符串
+func cloudState(path string, info os.FileInfo) string {
+	if info.IsDir() || !isPlaceholder(info) {
+		return ""
+	}
+	if _, provider := SyncRoot(path); provider != "" {
+		return provider
+	}
+	return "云盘"
+}
@@ -0,0 +1,23 @@
+// Package scanner 文件扫描模块
+// cloud_darwin.go - 通过 SF_DATALESS 标志识别 iCloud/File Provider 的占位文件
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build darwin
+
+package scanner
+
+import (
+	"os"
+	"syscall"
+)
+
+// sfDataless 文件内容不在本机，读取时由 File Provider 下载
+const sfDataless = 0x40000000
+
+// isPlaceholder 判断文件是否为仅在云端的占位文件
+func isPlaceholder(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && st.Flags&sfDataless != 0
+}
@@ -0,0 +1,16 @@
+// Package scanner 文件扫描模块
+// cloud_other.go - 其他平台的云盘客户端不使用占位文件，所有文件都视为已同步
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build !darwin && !windows
+
+package scanner
+
+import "os"
+
+// isPlaceholder 判断文件是否为仅在云端的占位文件
+func isPlaceholder(info os.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,27 @@
+// Package scanner 文件扫描模块
+// cloud_windows.go - 通过文件属性识别 OneDrive 等云文件 API 的占位文件
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build windows
+
+package scanner
+
+import (
+	"os"
+	"syscall"
+)
+
+// 云文件占位符的属性，读取或打开文件时会触发下载
+const (
+	attrOffline            = 0x00001000 // FILE_ATTRIBUTE_OFFLINE
+	attrRecallOnOpen       = 0x00040000 // FILE_ATTRIBUTE_RECALL_ON_OPEN
+	attrRecallOnDataAccess = 0x00400000 // FILE_ATTRIBUTE_RECALL_ON_DATA_ACCESS
+)
+
+// isPlaceholder 判断文件是否为仅在云端的占位文件
+func isPlaceholder(info os.FileInfo) bool {
+	attr, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	return ok && attr.FileAttributes&(attrOffline|attrRecallOnOpen|attrRecallOnDataAccess) != 0
+}
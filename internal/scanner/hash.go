@@ -0,0 +1,77 @@
+// Package scanner 文件扫描模块
+// hash.go - 文件内容的快速哈希，用于识别改过名的文件
+//
+// 只读取文件开头和结尾各 64KB，连同文件大小一起计算 SHA-256，
+// 大文件也只需两次小读取。足以区分不同的文件，但不适合校验文件完整性
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"os"
+)
+
+// ContentHashChunk 计算内容哈希时从开头和结尾各读取的字节数
+const ContentHashChunk = 64 * 1024
+
+// ContentHash 计算文件的快速内容哈希
+// 目录、空文件和仅在云端的占位文件（读取会触发下载）不计算
+//
+// 参数:
+//   - f: 文件信息
+//
+// 返回值:
+//   - string: 十六进制哈希，无法计算时为空字符串
+func ContentHash(f FileInfo) string {
+	if f.IsDir || f.Size == 0 || f.Cloud != "" {
+		return ""
+	}
+	return HashFile(f.Path)
+}
+
+// HashFile 计算指定路径文件的快速内容哈希
+// 用于文件已被移动、只知道新路径的场景（如事后纠正）
+//
+// 参数:
+//   - path: 文件路径
+//
+// 返回值:
+//   - string: 十六进制哈希，文件不存在、为空或只在云端时为空字符串
+func HashFile(path string) string {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Size() == 0 || cloudState(path, info) != "" {
+		return ""
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(info.Size()))
+	h.Write(size[:])
+
+	if _, err := io.CopyN(h, file, ContentHashChunk); err != nil && err != io.EOF {
+		return ""
+	}
+	// 文件超过一块时再读取结尾（与开头重叠的部分照样读取，保证结果只取决于内容）
+	if info.Size() > ContentHashChunk {
+		offset := info.Size() - ContentHashChunk
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return ""
+		}
+		if _, err := io.CopyN(h, file, ContentHashChunk); err != nil && err != io.EOF {
+			return ""
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,95 @@
+// Package scanner 文件扫描模块
+// language.go - 识别文件名的主要语言（文字系统）
+//
+// 只看文件名中的文字，不读取文件内容：假名 → 日文，谚文 → 韩文，
+// 西里尔字母 → 西里尔文，其余按汉字和拉丁字母的多少判断中文或英文
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// ==================== 常量定义 ====================
+
+// 文件名语言代码
+const (
+	LangChinese  = "zh"       // 中文
+	LangEnglish  = "en"       // 英文（拉丁字母）
+	LangJapanese = "ja"       // 日文
+	LangKorean   = "ko"       // 韩文
+	LangCyrillic = "cyrillic" // 西里尔文（俄文、乌克兰文等）
+)
+
+// languageNames 语言代码对应的中文名称
+var languageNames = map[string]string{
+	LangChinese:  "中文",
+	LangEnglish:  "英文",
+	LangJapanese: "日文",
+	LangKorean:   "韩文",
+	LangCyrillic: "西里尔文",
+}
+
+// Languages 支持识别的语言代码
+var Languages = []string{LangChinese, LangEnglish, LangJapanese, LangKorean, LangCyrillic}
+
+// LanguageName 返回语言代码的中文名称，未知代码返回空字符串
+func LanguageName(code string) string {
+	return languageNames[code]
+}
+
+// ==================== 语言识别 ====================
+
+// DetectLanguage 识别文件名的主要语言
+// 扩展名不参与判断；每个汉字、假名、谚文计 2 分，每个拉丁、西里尔字母计 1 分
+// （一个方块字的信息量大致相当于一个短单词的几个字母），取得分最高的文字系统。
+// 出现假名时判定为日文（日文文件名常以汉字为主，但中文文件名不会出现假名）
+//
+// 参数:
+//   - name: 文件名
+//
+// 返回值:
+//   - string: 语言代码（见 Languages），文件名不含文字（如纯数字）时为空字符串
+func DetectLanguage(name string) string {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	var han, kana, hangul, latin, cyrillic int
+	for _, r := range name {
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			latin++
+		}
+	}
+	if kana > 0 {
+		return LangJapanese
+	}
+
+	best, score := "", 0
+	for _, s := range []struct {
+		lang  string
+		score int
+	}{
+		{LangChinese, han * 2},
+		{LangKorean, hangul * 2},
+		{LangCyrillic, cyrillic},
+		{LangEnglish, latin},
+	} {
+		if s.score > score {
+			best, score = s.lang, s.score
+		}
+	}
+	return best
+}
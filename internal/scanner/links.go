@@ -0,0 +1,97 @@
+// Package scanner 文件扫描模块
+// links.go - 符号链接与硬链接：按 symlinks 配置跳过、跟随或直接整理符号链接，
+// 跟随目录链接时检测循环；同一个文件的多个硬链接（或经符号链接重复到达的同一个文件）只整理一次，
+// 其余的标记为 SameAs，不移动，也不算作重复文件
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ==================== 常量定义 ====================
+
+// 符号链接的处理方式（symlinks）
+const (
+	SymlinksSkip   = "skip"   // 不整理符号链接（默认）
+	SymlinksFollow = "follow" // 按链接指向的文件分类；递归扫描时进入链接指向的目录
+	SymlinksLink   = "link"   // 按链接本身的名称分类，移动的是链接
+)
+
+// ==================== 符号链接 ====================
+
+// linkWalker 跟随目录链接时记录已扫描的真实目录，用于检测循环
+type linkWalker struct {
+	root    string          // 扫描目录的真实路径（链接指向其中的目录时已在正常遍历中扫描）
+	visited map[string]bool // 已跟随的目录的真实路径
+}
+
+// newLinkWalker 创建目录链接的循环检测
+func newLinkWalker(absDir string) *linkWalker {
+	root, err := filepath.EvalSymlinks(absDir)
+	if err != nil {
+		root = absDir
+	}
+	return &linkWalker{root: root, visited: make(map[string]bool)}
+}
+
+// enter 判断是否进入链接指向的目录，返回目录的真实路径
+// 指向扫描目录内部（正常遍历已覆盖）、指向已跟随过的目录或其子目录（循环）时不进入
+func (w *linkWalker) enter(link string) (string, bool) {
+	real, err := filepath.EvalSymlinks(link)
+	if err != nil || within(real, w.root) {
+		return "", false
+	}
+	for dir := range w.visited {
+		if within(real, dir) {
+			return "", false
+		}
+	}
+	w.visited[real] = true
+	return real, true
+}
+
+// within 判断 path 是否为 dir 本身或位于 dir 之下
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// walkLinked 遍历链接指向的目录，路径改写为链接下的路径后交给 fn
+func walkLinked(link, real string, fn filepath.WalkFunc) {
+	filepath.Walk(real, func(path string, info os.FileInfo, err error) error {
+		return fn(link+strings.TrimPrefix(path, real), info, err)
+	})
+}
+
+// ==================== 硬链接 ====================
+
+// markSameFiles 标记同一个文件的重复条目（硬链接，或经符号链接重复到达）
+// 先按大小分组，只比较大小相同的文件；每组第一个条目保留，其余条目的 SameAs 为第一个条目的路径
+//
+// 参数:
+//   - files: 扫描到的文件
+//   - infos: 与 files 一一对应的文件信息（目录为 nil）
+func markSameFiles(files []FileInfo, infos []os.FileInfo) {
+	bySize := make(map[int64][]int)
+	for i, f := range files {
+		if !f.IsDir && infos[i] != nil {
+			bySize[f.Size] = append(bySize[f.Size], i)
+		}
+	}
+	for _, idx := range bySize {
+		for a := 1; a < len(idx); a++ {
+			for _, b := range idx[:a] {
+				if files[b].SameAs == "" && os.SameFile(infos[idx[a]], infos[b]) {
+					files[idx[a]].SameAs = files[b].Path
+					break
+				}
+			}
+		}
+	}
+}
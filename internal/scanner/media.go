@@ -0,0 +1,520 @@
+// Package scanner 文件扫描模块
+// media.go - 音视频元数据读取（ID3、MP4/MOV、WAV、AVI）
+// 只读取文件头部的元数据，不解码音视频内容
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// ==================== 类型定义 ====================
+
+// MediaInfo 音视频元数据
+type MediaInfo struct {
+	Title    string        // 标题
+	Artist   string        // 艺术家
+	Album    string        // 专辑
+	Duration time.Duration // 时长
+	Width    int           // 视频宽度（像素）
+	Height   int           // 视频高度（像素）
+}
+
+// IsEmpty 是否没有读取到任何元数据
+func (m *MediaInfo) IsEmpty() bool {
+	return m.Title == "" && m.Artist == "" && m.Album == "" && m.Duration == 0 && m.Width == 0
+}
+
+// Fields 转换为提供给分类器的字段（省略空值）
+func (m *MediaInfo) Fields() map[string]string {
+	fields := make(map[string]string)
+	if m.Title != "" {
+		fields["title"] = m.Title
+	}
+	if m.Artist != "" {
+		fields["artist"] = m.Artist
+	}
+	if m.Album != "" {
+		fields["album"] = m.Album
+	}
+	if m.Duration > 0 {
+		fields["duration"] = m.Duration.Round(time.Second).String()
+	}
+	if m.Width > 0 && m.Height > 0 {
+		fields["resolution"] = fmt.Sprintf("%dx%d", m.Width, m.Height)
+	}
+	return fields
+}
+
+// 元数据读取上限，避免读取过大的封面图片等数据
+const (
+	maxID3Size  = 512 * 1024      // ID3 标签最多读取 512KB
+	maxMoovSize = 8 * 1024 * 1024 // MP4 moov 原子最多读取 8MB
+)
+
+// ==================== 读取入口 ====================
+
+// ReadMediaInfo 读取音视频文件的元数据
+// 不支持的格式或读取失败时返回 nil
+func ReadMediaInfo(f FileInfo) *MediaInfo {
+	if f.IsDir || f.Size == 0 {
+		return nil
+	}
+
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var info *MediaInfo
+	switch f.Extension {
+	case ".mp3":
+		info = readMP3(file, f.Size)
+	case ".mp4", ".m4a", ".m4v", ".mov":
+		info = readMP4(file, f.Size)
+	case ".wav":
+		info = readWAV(file)
+	case ".avi":
+		info = readAVI(file)
+	}
+
+	if info == nil || info.IsEmpty() {
+		return nil
+	}
+	return info
+}
+
+// ==================== MP3 (ID3) ====================
+
+// mp3Bitrates MPEG Layer III 比特率表（kbps），索引为帧头中的比特率字段
+var mp3Bitrates = map[bool][16]int{
+	true:  {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}, // MPEG-1
+	false: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},     // MPEG-2/2.5
+}
+
+// readMP3 读取 MP3 元数据
+// 优先读取 ID3v2 标签，缺失时回退到文件末尾的 ID3v1 标签
+// 时长优先使用 TLEN 帧，否则按首帧比特率估算（VBR 文件会有误差）
+func readMP3(r io.ReadSeeker, size int64) *MediaInfo {
+	info := &MediaInfo{}
+
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil
+	}
+
+	var tagSize int64
+	if string(header[:3]) == "ID3" {
+		tagSize = int64(syncsafe(header[6:10])) + 10
+		readID3v2(r, header[3], tagSize-10, info)
+	} else {
+		readID3v1(r, size, info)
+	}
+
+	if info.Duration == 0 {
+		info.Duration = estimateMP3Duration(r, tagSize, size)
+	}
+	return info
+}
+
+// readID3v2 解析 ID3v2 标签帧
+func readID3v2(r io.Reader, version byte, size int64, info *MediaInfo) {
+	if size > maxID3Size {
+		size = maxID3Size
+	}
+	data := make([]byte, size)
+	n, _ := io.ReadFull(r, data)
+	data = data[:n]
+
+	// v2.2 使用 3 字节帧 ID 和长度，v2.3/v2.4 使用 4 字节
+	idLen, headLen := 4, 10
+	if version == 2 {
+		idLen, headLen = 3, 6
+	}
+
+	for pos := 0; pos+headLen <= len(data); {
+		id := string(data[pos : pos+idLen])
+		if id[0] == 0 {
+			break // 填充区
+		}
+
+		var frameSize int
+		switch version {
+		case 2:
+			frameSize = int(data[pos+3])<<16 | int(data[pos+4])<<8 | int(data[pos+5])
+		case 4:
+			frameSize = syncsafe(data[pos+4 : pos+8])
+		default:
+			frameSize = int(binary.BigEndian.Uint32(data[pos+4 : pos+8]))
+		}
+		body := pos + headLen
+		if frameSize <= 0 || body+frameSize > len(data) {
+			break
+		}
+		value := decodeID3Text(data[body : body+frameSize])
+
+		switch id {
+		case "TIT2", "TT2":
+			info.Title = value
+		case "TPE1", "TP1":
+			info.Artist = value
+		case "TALB", "TAL":
+			info.Album = value
+		case "TLEN", "TLE":
+			var ms int64
+			if _, err := fmt.Sscan(value, &ms); err == nil && ms > 0 {
+				info.Duration = time.Duration(ms) * time.Millisecond
+			}
+		}
+		pos = body + frameSize
+	}
+}
+
+// readID3v1 解析文件末尾 128 字节的 ID3v1 标签
+func readID3v1(r io.ReadSeeker, size int64, info *MediaInfo) {
+	if size < 128 {
+		return
+	}
+	tag := make([]byte, 128)
+	if _, err := r.Seek(size-128, io.SeekStart); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(r, tag); err != nil || string(tag[:3]) != "TAG" {
+		return
+	}
+	info.Title = trimLatin1(tag[3:33])
+	info.Artist = trimLatin1(tag[33:63])
+	info.Album = trimLatin1(tag[63:93])
+}
+
+// estimateMP3Duration 按首个音频帧的比特率估算时长
+func estimateMP3Duration(r io.ReadSeeker, offset, size int64) time.Duration {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return 0
+	}
+	buf := make([]byte, 4096)
+	n, _ := io.ReadFull(r, buf)
+	buf = buf[:n]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		// 帧同步: 11 个 1，且为 Layer III
+		if buf[i] != 0xFF || buf[i+1]&0xE0 != 0xE0 || buf[i+1]&0x06 != 0x02 {
+			continue
+		}
+		mpeg1 := buf[i+1]&0x18 == 0x18
+		kbps := mp3Bitrates[mpeg1][buf[i+2]>>4]
+		if kbps == 0 {
+			continue
+		}
+		audioBytes := size - offset - int64(i)
+		return time.Duration(audioBytes*8/int64(kbps)) * time.Millisecond
+	}
+	return 0
+}
+
+// decodeID3Text 解码 ID3 文本帧（首字节为编码方式）
+func decodeID3Text(b []byte) string {
+	if len(b) < 2 {
+		return ""
+	}
+	enc, b := b[0], b[1:]
+	switch enc {
+	case 1, 2: // UTF-16（带 BOM）/ UTF-16BE
+		bigEndian := enc == 2
+		if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+			bigEndian, b = true, b[2:]
+		} else if len(b) >= 2 && b[0] == 0xFF && b[1] == 0xFE {
+			bigEndian, b = false, b[2:]
+		}
+		u := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			if bigEndian {
+				u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+			} else {
+				u = append(u, uint16(b[i+1])<<8|uint16(b[i]))
+			}
+		}
+		return strings.TrimRight(string(utf16.Decode(u)), "\x00 ")
+	case 3: // UTF-8
+		return strings.TrimRight(string(b), "\x00 ")
+	default: // ISO-8859-1
+		return trimLatin1(b)
+	}
+}
+
+// trimLatin1 将 ISO-8859-1 字节转换为字符串并去除填充
+func trimLatin1(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return strings.TrimSpace(string(runes))
+}
+
+// syncsafe 解析 ID3 的 syncsafe 整数（每字节只用低 7 位）
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+// ==================== MP4 / MOV ====================
+
+// readMP4 读取 MP4/MOV 元数据
+// 在顶层原子中定位 moov，再从中解析时长、分辨率和 iTunes 标签
+func readMP4(r io.ReadSeeker, size int64) *MediaInfo {
+	var pos int64
+	for pos+8 <= size {
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return nil
+		}
+		atomSize, atomType, headLen, ok := readAtomHeader(r, size-pos)
+		if !ok {
+			return nil
+		}
+		if atomType == "moov" {
+			bodySize := atomSize - headLen
+			if bodySize > maxMoovSize {
+				return nil
+			}
+			body := make([]byte, bodySize)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return nil
+			}
+			info := &MediaInfo{}
+			parseMoov(body, info)
+			return info
+		}
+		pos += atomSize // 跳过 mdat 等大原子
+	}
+	return nil
+}
+
+// readAtomHeader 读取原子头部
+// 返回原子总长度、类型和头部长度
+func readAtomHeader(r io.Reader, remaining int64) (int64, string, int64, bool) {
+	head := make([]byte, 8)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return 0, "", 0, false
+	}
+	atomSize := int64(binary.BigEndian.Uint32(head[:4]))
+	atomType := string(head[4:8])
+	headLen := int64(8)
+
+	switch atomSize {
+	case 0: // 延伸到文件末尾
+		atomSize = remaining
+	case 1: // 64 位长度
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return 0, "", 0, false
+		}
+		atomSize = int64(binary.BigEndian.Uint64(ext))
+		headLen = 16
+	}
+	if atomSize < headLen || atomSize > remaining {
+		return 0, "", 0, false
+	}
+	return atomSize, atomType, headLen, true
+}
+
+// eachAtom 遍历内存中的子原子
+func eachAtom(data []byte, fn func(atomType string, body []byte)) {
+	for pos := 0; pos+8 <= len(data); {
+		atomSize := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		if atomSize < 8 || pos+atomSize > len(data) {
+			return
+		}
+		fn(string(data[pos+4:pos+8]), data[pos+8:pos+atomSize])
+		pos += atomSize
+	}
+}
+
+// parseMoov 解析 moov 原子
+func parseMoov(moov []byte, info *MediaInfo) {
+	eachAtom(moov, func(atomType string, body []byte) {
+		switch atomType {
+		case "mvhd":
+			info.Duration = parseMvhd(body)
+		case "trak":
+			eachAtom(body, func(t string, b []byte) {
+				if t == "tkhd" {
+					// 音频轨道宽高为 0，取最大的视频轨道尺寸
+					if w, h := parseTkhd(b); w > info.Width {
+						info.Width, info.Height = w, h
+					}
+				}
+			})
+		case "udta":
+			eachAtom(body, func(t string, b []byte) {
+				if t == "meta" {
+					parseMeta(b, info)
+				}
+			})
+		}
+	})
+}
+
+// parseMvhd 解析影片头，返回时长
+func parseMvhd(b []byte) time.Duration {
+	if len(b) < 4 {
+		return 0
+	}
+	var timescale, duration uint64
+	if b[0] == 1 { // 版本 1：64 位时间字段
+		if len(b) < 32 {
+			return 0
+		}
+		timescale = uint64(binary.BigEndian.Uint32(b[20:24]))
+		duration = binary.BigEndian.Uint64(b[24:32])
+	} else {
+		if len(b) < 20 {
+			return 0
+		}
+		timescale = uint64(binary.BigEndian.Uint32(b[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(b[16:20]))
+	}
+	if timescale == 0 {
+		return 0
+	}
+	return time.Duration(duration * uint64(time.Second) / timescale)
+}
+
+// parseTkhd 解析轨道头，返回宽高
+func parseTkhd(b []byte) (int, int) {
+	if len(b) < 4 {
+		return 0, 0
+	}
+	// 宽高位于时间字段、保留字段和 36 字节矩阵之后，格式为 16.16 定点数
+	offset := 4 + 20 + 52
+	if b[0] == 1 {
+		offset = 4 + 32 + 52
+	}
+	if len(b) < offset+8 {
+		return 0, 0
+	}
+	w := int(binary.BigEndian.Uint32(b[offset:offset+4]) >> 16)
+	h := int(binary.BigEndian.Uint32(b[offset+4:offset+8]) >> 16)
+	return w, h
+}
+
+// parseMeta 解析 iTunes 风格的 meta/ilst 标签
+func parseMeta(b []byte, info *MediaInfo) {
+	// MP4 的 meta 是 full box（带 4 字节版本和标志），QuickTime 的不是
+	if len(b) >= 8 && string(b[4:8]) != "hdlr" {
+		b = b[4:]
+	}
+	eachAtom(b, func(t string, ilst []byte) {
+		if t != "ilst" {
+			return
+		}
+		eachAtom(ilst, func(tag string, item []byte) {
+			eachAtom(item, func(dt string, data []byte) {
+				// data 原子: 4 字节类型 + 4 字节区域 + 值
+				if dt != "data" || len(data) < 8 {
+					return
+				}
+				value := strings.TrimSpace(string(data[8:]))
+				switch tag {
+				case "\xa9nam":
+					info.Title = value
+				case "\xa9ART":
+					info.Artist = value
+				case "\xa9alb":
+					info.Album = value
+				}
+			})
+		})
+	})
+}
+
+// ==================== WAV / AVI (RIFF) ====================
+
+// readRIFFChunks 遍历 RIFF 文件的顶层块
+// form 为 RIFF 头中的格式类型（WAVE / AVI ），fn 返回 false 时停止遍历
+func readRIFFChunks(r io.ReadSeeker, form string, fn func(id string, size int64) bool) bool {
+	head := make([]byte, 12)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return false
+	}
+	if string(head[:4]) != "RIFF" || string(head[8:12]) != form {
+		return false
+	}
+	for {
+		chunk := make([]byte, 8)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return true
+		}
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		pos, _ := r.Seek(0, io.SeekCurrent)
+		if !fn(string(chunk[:4]), size) {
+			return true
+		}
+		// 块长度为奇数时有一个填充字节
+		if _, err := r.Seek(pos+size+size%2, io.SeekStart); err != nil {
+			return true
+		}
+	}
+}
+
+// readWAV 读取 WAV 时长（数据长度 / 每秒字节数）
+func readWAV(r io.ReadSeeker) *MediaInfo {
+	var byteRate, dataSize int64
+	readRIFFChunks(r, "WAVE", func(id string, size int64) bool {
+		switch id {
+		case "fmt ":
+			fmtChunk := make([]byte, 16)
+			if _, err := io.ReadFull(r, fmtChunk); err == nil {
+				byteRate = int64(binary.LittleEndian.Uint32(fmtChunk[8:12]))
+			}
+		case "data":
+			dataSize = size
+			return false
+		}
+		return true
+	})
+	if byteRate == 0 {
+		return nil
+	}
+	return &MediaInfo{Duration: time.Duration(dataSize * int64(time.Second) / byteRate)}
+}
+
+// readAVI 读取 AVI 主头中的分辨率和时长
+func readAVI(r io.ReadSeeker) *MediaInfo {
+	var info *MediaInfo
+	readRIFFChunks(r, "AVI ", func(id string, size int64) bool {
+		if id != "LIST" {
+			return true
+		}
+		// LIST hdrl 的第一个子块即为 avih 主头
+		hdr := make([]byte, 4+8+40)
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			return false
+		}
+		if string(hdr[:4]) != "hdrl" || string(hdr[4:8]) != "avih" {
+			return true
+		}
+		avih := hdr[12:]
+		usPerFrame := int64(binary.LittleEndian.Uint32(avih[0:4]))
+		frames := int64(binary.LittleEndian.Uint32(avih[16:20]))
+		info = &MediaInfo{
+			Duration: time.Duration(usPerFrame*frames) * time.Microsecond,
+			Width:    int(binary.LittleEndian.Uint32(avih[32:36])),
+			Height:   int(binary.LittleEndian.Uint32(avih[36:40])),
+		}
+		return false
+	})
+	return info
+}
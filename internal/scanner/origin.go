@@ -0,0 +1,211 @@
+// Package scanner 文件扫描模块
+// origin.go - 读取文件的下载来源（浏览器下载的网址、邮件附件的发件人）
+//
+// 浏览器和邮件客户端保存文件时会记下来源：
+//   - macOS: 扩展属性 com.apple.metadata:kMDItemWhereFroms，二进制 plist 格式的字符串数组，
+//     浏览器下载为 [下载地址, 所在网页]，邮件附件为 [发件人, 主题, 邮件链接]
+//   - Linux: Chrome、Firefox 写入扩展属性 user.xdg.origin.url 和 user.xdg.referrer.url
+//   - Windows: 备用数据流 Zone.Identifier 中的 HostUrl 和 ReferrerUrl
+//
+// 只取来源的域名，作为分类依据和来源规则（filo rules add --origin）的匹配对象
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// DownloadOrigin 读取文件的下载来源域名
+// 浏览器下载取下载地址的域名（没有时取所在网页的域名），邮件附件取发件人邮箱的域名，
+// 去掉 www. 前缀并转为小写
+//
+// 参数:
+//   - path: 文件路径
+//
+// 返回值:
+//   - string: 来源域名，没有记录或无法解析时为空
+func DownloadOrigin(path string) string {
+	for _, source := range readOrigins(path) {
+		if domain := originDomain(source); domain != "" {
+			return domain
+		}
+	}
+	return ""
+}
+
+// originDomain 从网址或发件人（如 张三 <zhang@example.com>）中取出域名
+func originDomain(source string) string {
+	source = strings.TrimSpace(source)
+	var host string
+	if u, err := url.Parse(source); err == nil && u.Host != "" && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "ftp") {
+		host = u.Hostname()
+	} else if addr, err := mail.ParseAddress(source); err == nil {
+		host = addr.Address[strings.LastIndex(addr.Address, "@")+1:]
+	}
+	return strings.TrimPrefix(strings.ToLower(host), "www.")
+}
+
+// ==================== 来源记录解析 ====================
+
+// parseZoneIdentifier 解析 Windows 的 Zone.Identifier 数据流，返回下载地址和所在网页
+//
+//	[ZoneTransfer]
+//	ZoneId=3
+//	ReferrerUrl=https://github.com/lynx-lee/filo/releases
+//	HostUrl=https://github.com/lynx-lee/filo/releases/download/v2.0/filo.zip
+func parseZoneIdentifier(data []byte) []string {
+	var host, referrer string
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(s.Text()), "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "hosturl":
+			host = value
+		case "referrerurl":
+			referrer = value
+		}
+	}
+	var sources []string
+	for _, v := range []string{host, referrer} {
+		if v != "" {
+			sources = append(sources, v)
+		}
+	}
+	return sources
+}
+
+// xmlString 匹配 XML plist 中的字符串
+var xmlString = regexp.MustCompile(`<string>([^<]*)</string>`)
+
+// parsePlistStrings 解析 kMDItemWhereFroms 的 plist，返回其中的字符串
+// 通常为二进制 plist（bplist00），少数工具写入 XML plist
+func parsePlistStrings(data []byte) []string {
+	if bytes.HasPrefix(data, []byte("<?xml")) {
+		var values []string
+		for _, m := range xmlString.FindAllSubmatch(data, -1) {
+			values = append(values, strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">").Replace(string(m[1])))
+		}
+		return values
+	}
+	return parseBinaryPlistStrings(data)
+}
+
+// parseBinaryPlistStrings 解析二进制 plist 的顶层对象：字符串数组或单个字符串
+// 只支持 kMDItemWhereFroms 用到的 ASCII 和 UTF-16 字符串，其他对象忽略
+func parseBinaryPlistStrings(data []byte) []string {
+	if len(data) < 40 || !bytes.HasPrefix(data, []byte("bplist00")) {
+		return nil
+	}
+	// 末尾 32 字节: 6 字节保留、偏移量字节数、对象引用字节数、对象数、顶层对象、偏移表位置
+	trailer := data[len(data)-32:]
+	offsetSize, refSize := int(trailer[6]), int(trailer[7])
+	count := binary.BigEndian.Uint64(trailer[8:16])
+	top := binary.BigEndian.Uint64(trailer[16:24])
+	table := binary.BigEndian.Uint64(trailer[24:32])
+	if offsetSize == 0 || refSize == 0 || count > uint64(len(data)) || top >= count || table >= uint64(len(data)) {
+		return nil
+	}
+
+	// 按序号取对象的位置
+	offset := func(ref uint64) (int, bool) {
+		if ref >= count {
+			return 0, false
+		}
+		start := table + ref*uint64(offsetSize)
+		if start+uint64(offsetSize) > uint64(len(data)) {
+			return 0, false
+		}
+		pos := readUint(data[start : start+uint64(offsetSize)])
+		return int(pos), pos < table
+	}
+	// 读取对象的长度：低 4 位为 0xF 时长度为紧随其后的整数对象
+	length := func(pos int) (int, int, bool) {
+		n := int(data[pos] & 0x0F)
+		pos++
+		if n != 0x0F {
+			return n, pos, true
+		}
+		if pos >= len(data) || data[pos]>>4 != 0x1 {
+			return 0, 0, false
+		}
+		size := 1 << (data[pos] & 0x0F)
+		if pos+1+size > len(data) {
+			return 0, 0, false
+		}
+		v := readUint(data[pos+1 : pos+1+size])
+		if v > uint64(len(data)) {
+			return 0, 0, false
+		}
+		return int(v), pos + 1 + size, true
+	}
+	// 读取字符串对象
+	str := func(ref uint64) (string, bool) {
+		pos, ok := offset(ref)
+		if !ok {
+			return "", false
+		}
+		kind := data[pos] >> 4
+		n, pos, ok := length(pos)
+		if !ok {
+			return "", false
+		}
+		switch kind {
+		case 0x5: // ASCII
+			if pos+n > len(data) {
+				return "", false
+			}
+			return string(data[pos : pos+n]), true
+		case 0x6: // UTF-16 大端
+			if pos+2*n > len(data) {
+				return "", false
+			}
+			units := make([]uint16, n)
+			for i := range units {
+				units[i] = binary.BigEndian.Uint16(data[pos+2*i:])
+			}
+			return string(utf16.Decode(units)), true
+		}
+		return "", false
+	}
+
+	if s, ok := str(top); ok {
+		return []string{s}
+	}
+	pos, ok := offset(top)
+	if !ok || data[pos]>>4 != 0xA {
+		return nil
+	}
+	n, pos, ok := length(pos)
+	if !ok || pos+n*refSize > len(data) {
+		return nil
+	}
+	var values []string
+	for i := 0; i < n; i++ {
+		if s, ok := str(readUint(data[pos+i*refSize : pos+(i+1)*refSize])); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// readUint 读取大端序的无符号整数（1-8 字节）
+func readUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
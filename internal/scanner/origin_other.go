@@ -0,0 +1,14 @@
+// Package scanner 文件扫描模块
+// origin_other.go - 其他平台不记录下载来源
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build !linux && !darwin && !windows
+
+package scanner
+
+// readOrigins 其他平台不记录下载来源
+func readOrigins(path string) []string {
+	return nil
+}
@@ -0,0 +1,47 @@
+// Package scanner 文件扫描模块
+// origin_unix.go - 从扩展属性读取下载来源（macOS、Linux）
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build linux || darwin
+
+package scanner
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// readOrigins 读取文件的下载来源（网址或发件人），按可信程度排列
+func readOrigins(path string) []string {
+	if runtime.GOOS == "darwin" {
+		data := getXattr(path, "com.apple.metadata:kMDItemWhereFroms")
+		if len(data) == 0 {
+			return nil
+		}
+		return parsePlistStrings(data)
+	}
+	var sources []string
+	for _, name := range []string{"user.xdg.origin.url", "user.xdg.referrer.url"} {
+		if data := getXattr(path, name); len(data) > 0 {
+			sources = append(sources, string(data))
+		}
+	}
+	return sources
+}
+
+// getXattr 读取扩展属性，没有或读取失败时返回 nil
+func getXattr(path, name string) []byte {
+	size, err := unix.Getxattr(path, name, nil)
+	if err != nil || size <= 0 {
+		return nil
+	}
+	buf := make([]byte, size)
+	n, err := unix.Getxattr(path, name, buf)
+	if err != nil {
+		return nil
+	}
+	return buf[:n]
+}
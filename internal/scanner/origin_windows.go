@@ -0,0 +1,21 @@
+// Package scanner 文件扫描模块
+// origin_windows.go - 从 Zone.Identifier 备用数据流读取下载来源
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build windows
+
+package scanner
+
+import "os"
+
+// readOrigins 读取文件的下载来源（下载地址、所在网页）
+// 只有 NTFS 上的文件有备用数据流，其他文件系统读取失败时返回 nil
+func readOrigins(path string) []string {
+	data, err := os.ReadFile(path + ":Zone.Identifier")
+	if err != nil {
+		return nil
+	}
+	return parseZoneIdentifier(data)
+}
@@ -0,0 +1,51 @@
+// Package scanner 文件扫描模块
+// prune.go - 扫描深度和目录剪枝：max_depth 限制递归扫描的层数，prune 中的模式匹配的目录不进入，
+// 整理两层目录时不会深入到庞大的项目目录树中
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// depthOf 扫描目录下的相对路径所在的层级，第一层为 1
+func depthOf(rel string) int {
+	return strings.Count(filepath.ToSlash(rel), "/") + 1
+}
+
+// Pruned 判断目录是否按 prune 模式跳过
+// 模式与目录名（如 node_modules、build*）或相对扫描目录的路径（以 / 分隔，如 项目/*/dist）匹配
+//
+// 参数:
+//   - rel: 目录相对扫描目录的路径
+//   - patterns: prune 模式（filepath.Match 语法）
+//
+// 返回值:
+//   - bool: 是否跳过该目录
+func Pruned(rel string, patterns []string) bool {
+	rel = filepath.ToSlash(rel)
+	name := rel[strings.LastIndex(rel, "/")+1:]
+	for _, p := range patterns {
+		p = strings.Trim(filepath.ToSlash(p), "/")
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(p, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidPrunePattern 检查 prune 模式的语法
+func ValidPrunePattern(pattern string) bool {
+	if strings.Trim(pattern, "/ ") == "" {
+		return false
+	}
+	_, err := filepath.Match(pattern, "")
+	return err == nil
+}
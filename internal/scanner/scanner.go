@@ -13,7 +13,11 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"filo/internal/config"
+	"filo/internal/folderinfo"
+	"filo/internal/metrics"
 	"filo/internal/ui"
 )
 
@@ -28,6 +32,21 @@ type FileInfo struct {
 	Size         int64     // 文件大小（字节）
 	ModifiedTime time.Time // 最后修改时间
 	IsDir        bool      // 是否为目录
+	Suspicious   string     // 可疑原因（未完成下载、空文件、损坏），为空表示正常
+	Media        *MediaInfo // 音视频元数据（非媒体文件或读取失败时为 nil）
+	SHA256       string     // 文件哈希（仅隔离模式下的可执行文件和安装包计算）
+	Cloud        string     // 仅在云端的占位文件所属的云盘（Dropbox、OneDrive 等），已同步到本机时为空
+	Origin       string     // 下载来源的域名（浏览器下载的网址、邮件附件的发件人），没有记录时为空
+	ContentHash  string     // 内容的快速哈希（分类时计算，用于识别改过名的文件）
+	Burst        string     // 所属的批量下载（同一目录中短时间内集中出现的一批文件，分类时标记），不属于任何一批时为空
+	Symlink      string     // 符号链接指向的路径（symlinks 为 follow 或 link 时），不是链接时为空
+	SameAs       string     // 与之前扫描到的某个文件是同一个文件（硬链接等）时为该文件的路径，不重复整理
+}
+
+// ParentDir 返回文件原始所在目录的名称
+// 例如 ~/Downloads/税务/发票.pdf 返回 "税务"
+func (f FileInfo) ParentDir() string {
+	return filepath.Base(filepath.Dir(f.Path))
 }
 
 // skipNames 需要跳过的文件和目录名
@@ -50,6 +69,8 @@ var skipNames = map[string]bool{
 // ==================== 核心扫描函数 ====================
 
 // ScanDirectory 扫描目录
+// 符号链接按 symlinks 配置处理；同一个文件的多个硬链接只有第一个正常整理，其余标记 SameAs；
+// 递归扫描的层数受 max_depth 限制，prune 匹配的目录不进入
 // 参数:
 //   - dir: 要扫描的目录路径
 //   - recursive: 是否递归扫描子目录
@@ -58,15 +79,32 @@ var skipNames = map[string]bool{
 //   - error: 错误信息
 func ScanDirectory(dir string, recursive bool) ([]FileInfo, error) {
 	var files []FileInfo
+	var infos []os.FileInfo // 与 files 一一对应，用于识别硬链接
 
 	// 获取绝对路径
 	absDir, err := filepath.Abs(dir)
 	if err != nil {
 		return nil, err
 	}
+	// 扫描目录本身是符号链接时扫描其指向的目录（filepath.Walk 不会进入作为根的链接）
+	if fi, err := os.Lstat(absDir); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		if real, err := filepath.EvalSymlinks(absDir); err == nil {
+			absDir = real
+		}
+	}
+	cfg := config.Get()
+	symlinks := cfg.Symlinks
+	links := newLinkWalker(absDir)
+
+	// 扫描的最大层数：非递归只扫描第一层，递归时按 max_depth（0 表示不限）
+	maxDepth := 1
+	if recursive {
+		maxDepth = cfg.MaxDepth
+	}
 
 	// 遍历目录的回调函数
-	walkFn := func(path string, info os.FileInfo, err error) error {
+	var walkFn filepath.WalkFunc
+	walkFn = func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // 忽略访问错误，继续扫描
 		}
@@ -89,8 +127,13 @@ func ScanDirectory(dir string, recursive bool) ([]FileInfo, error) {
 			return nil
 		}
 
-		// 跳过已整理目录（避免重复整理）
-		if strings.Contains(path, "已整理") || strings.Contains(path, "Organized") {
+		// 跳过 filo 在分类文件夹中生成的说明文件
+		if name == folderinfo.ReadmeName && !info.IsDir() && folderinfo.IsGenerated(path) {
+			return nil
+		}
+
+		// 跳过已整理和已归档目录（避免重复整理）
+		if strings.Contains(path, "已整理") || strings.Contains(path, "Organized") || strings.Contains(path, "已归档") {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
@@ -102,35 +145,156 @@ func ScanDirectory(dir string, recursive bool) ([]FileInfo, error) {
 			return nil
 		}
 
-		// 非递归模式：只扫描第一层
-		if !recursive {
-			rel, _ := filepath.Rel(absDir, path)
-			if strings.Contains(rel, string(os.PathSeparator)) {
-				if info.IsDir() {
-					return filepath.SkipDir // 跳过子目录
+		// 超出扫描层数（非递归模式只扫描第一层）
+		rel, _ := filepath.Rel(absDir, path)
+		if maxDepth > 0 && depthOf(rel) > maxDepth {
+			if info.IsDir() {
+				return filepath.SkipDir // 跳过子目录
+			}
+			return nil // 跳过子目录中的文件
+		}
+
+		// 按 prune 跳过的目录（如项目的构建输出）
+		if info.IsDir() && Pruned(rel, cfg.Prune) {
+			return filepath.SkipDir
+		}
+
+		// 符号链接：跳过、跟随（使用指向的文件的信息，递归时进入指向的目录）或整理链接本身
+		var symlink string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if symlinks != SymlinksFollow && symlinks != SymlinksLink {
+				return nil
+			}
+			symlink, _ = os.Readlink(path)
+			if symlinks == SymlinksFollow {
+				target, err := os.Stat(path)
+				if err != nil {
+					return nil // 链接指向的文件不存在
 				}
-				return nil // 跳过子目录中的文件
+				if target.IsDir() {
+					if recursive {
+						if real, ok := links.enter(path); ok {
+							walkLinked(path, real, walkFn)
+						}
+					}
+					return nil
+				}
+				info = target
 			}
 		}
+		isLink := symlink != "" && symlinks == SymlinksLink
+
+		// 检测可疑文件（仅针对文件，读取占位文件的内容会触发下载；链接本身没有内容）
+		var suspicious string
+		cloud := cloudState(path, info)
+		if !info.IsDir() && cloud == "" && !isLink {
+			suspicious = DetectSuspicious(path, name, info.Size())
+		}
 
 		// 添加文件信息到列表
-		files = append(files, FileInfo{
+		f := FileInfo{
 			Path:         path,
 			Name:         name,
 			Extension:    strings.ToLower(filepath.Ext(name)), // 扩展名转小写
 			Size:         info.Size(),
 			ModifiedTime: info.ModTime(),
 			IsDir:        info.IsDir(),
-		})
+			Suspicious:   suspicious,
+			Cloud:        cloud,
+			Symlink:      symlink,
+		}
+		if suspicious == "" && cloud == "" && !isLink {
+			f.Media = ReadMediaInfo(f) // 读取音视频元数据
+		}
+		if !info.IsDir() && !isLink {
+			f.Origin = DownloadOrigin(path) // 读取下载来源（扩展属性，不读取内容）
+		}
+		files = append(files, f)
+		if info.IsDir() {
+			infos = append(infos, nil)
+		} else {
+			infos = append(infos, info)
+		}
 
 		return nil
 	}
 
 	// 执行目录遍历
 	err = filepath.Walk(absDir, walkFn)
+	markSameFiles(files, infos)
+	metrics.AddScanned(len(files))
+	if err != nil {
+		metrics.AddError(metrics.StageScan)
+	}
 	return files, err
 }
 
+// StatFile 获取单个文件的信息
+// 用于 explain 等针对单个文件的命令
+func StatFile(path string) (FileInfo, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	f := FileInfo{
+		Path:         absPath,
+		Name:         info.Name(),
+		Extension:    strings.ToLower(filepath.Ext(info.Name())),
+		Size:         info.Size(),
+		ModifiedTime: info.ModTime(),
+		IsDir:        info.IsDir(),
+		Cloud:        cloudState(absPath, info),
+	}
+	if f.Cloud == "" {
+		f.Suspicious = DetectSuspicious(absPath, info.Name(), info.Size())
+	}
+	if f.Suspicious == "" && f.Cloud == "" {
+		f.Media = ReadMediaInfo(f)
+	}
+	if !f.IsDir {
+		f.Origin = DownloadOrigin(absPath)
+	}
+	return f, nil
+}
+
+// textExts 可以直接读取内容的文本类扩展名
+var textExts = map[string]bool{
+	".txt": true, ".md": true, ".csv": true, ".json": true, ".log": true,
+	".xml": true, ".html": true, ".htm": true, ".yaml": true, ".yml": true,
+	".ini": true, ".conf": true, ".go": true, ".py": true, ".js": true,
+	".ts": true, ".java": true, ".c": true, ".cpp": true, ".h": true,
+	".sh": true, ".sql": true, ".rtf": true,
+}
+
+// ReadSnippet 读取文本文件开头的内容片段
+// 仅处理文本类文件，其余文件返回空字符串
+// 连续空白会被压缩为单个空格
+func ReadSnippet(f FileInfo, maxBytes int) string {
+	if !textExts[f.Extension] || f.Size == 0 || f.Cloud != "" {
+		return ""
+	}
+
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	buf := make([]byte, maxBytes)
+	n, _ := file.Read(buf)
+	buf = buf[:n]
+
+	// 去掉被截断的多字节字符
+	for len(buf) > 0 && !utf8.Valid(buf) {
+		buf = buf[:len(buf)-1]
+	}
+	return strings.Join(strings.Fields(string(buf)), " ")
+}
+
 // ==================== 统计相关类型 ====================
 
 // Statistics 文件统计信息
@@ -138,6 +302,9 @@ type Statistics struct {
 	TotalFiles int               // 文件总数
 	TotalDirs  int               // 目录总数
 	TotalSize  int64             // 总大小（字节）
+	Suspicious int               // 可疑文件数
+	Cloud      int               // 仅在云端的占位文件数
+	SameFiles  int               // 与其他文件是同一个文件的硬链接数（不计入总大小）
 	ExtStats   map[string]ExtStat // 按扩展名统计
 }
 
@@ -163,7 +330,17 @@ func GetStatistics(files []FileInfo) Statistics {
 		}
 
 		stats.TotalFiles++
-		stats.TotalSize += f.Size
+		if f.SameAs != "" {
+			stats.SameFiles++
+		} else {
+			stats.TotalSize += f.Size
+		}
+		if f.Suspicious != "" {
+			stats.Suspicious++
+		}
+		if f.Cloud != "" {
+			stats.Cloud++
+		}
 
 		// 按扩展名统计
 		ext := f.Extension
@@ -192,6 +369,15 @@ func PrintStatistics(files []FileInfo) {
 	ui.Info("📁 文件夹: %d 个", stats.TotalDirs)
 	ui.Info("📄 文件:   %d 个", stats.TotalFiles)
 	ui.Info("💾 总大小: %s", ui.FormatSize(stats.TotalSize))
+	if stats.Suspicious > 0 {
+		ui.Warning("可疑文件: %d 个（未完成下载/空文件/可能损坏）", stats.Suspicious)
+	}
+	if stats.Cloud > 0 {
+		ui.Info("☁️  云端文件: %d 个（未下载到本机，只按文件名分类）", stats.Cloud)
+	}
+	if stats.SameFiles > 0 {
+		ui.Info("🔗 硬链接: %d 个（与其他文件是同一个文件，不计入总大小）", stats.SameFiles)
+	}
 
 	// 按扩展名统计（如果有数据）
 	if len(stats.ExtStats) > 0 {
@@ -0,0 +1,144 @@
+// Package scanner 文件扫描模块
+// suspicious.go - 可疑文件检测（未完成下载、空文件、损坏文件）
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package scanner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ==================== 常量定义 ====================
+
+// 可疑文件的归类位置
+const (
+	SuspiciousCategory    = "待处理"   // 可疑文件主分类
+	SuspiciousSubcategory = "未完成下载" // 可疑文件子分类
+)
+
+// partialExts 下载工具使用的临时文件扩展名
+var partialExts = map[string]bool{
+	".crdownload": true, // Chrome
+	".part":       true, // Firefox / wget
+	".partial":    true, // Edge (旧版)
+	".download":   true, // Safari
+	".opdownload": true, // Opera
+	".tmp":        true, // 通用临时文件
+	".!ut":        true, // uTorrent
+	".bc!":        true, // BitComet
+	".td":         true, // 迅雷
+}
+
+// magicHeader 文件头签名
+type magicHeader struct {
+	offset int    // 签名在文件中的偏移
+	magic  []byte // 签名字节
+}
+
+// magicHeaders 常见媒体和文档格式的文件头签名
+// 同一扩展名有多个签名时满足任意一个即可
+var magicHeaders = map[string][]magicHeader{
+	".jpg":  {{0, []byte{0xFF, 0xD8, 0xFF}}},
+	".jpeg": {{0, []byte{0xFF, 0xD8, 0xFF}}},
+	".png":  {{0, []byte{0x89, 'P', 'N', 'G'}}},
+	".gif":  {{0, []byte("GIF8")}},
+	".pdf":  {{0, []byte("%PDF")}},
+	".zip":  {{0, []byte("PK\x03\x04")}, {0, []byte("PK\x05\x06")}},
+	".docx": {{0, []byte("PK\x03\x04")}},
+	".xlsx": {{0, []byte("PK\x03\x04")}},
+	".pptx": {{0, []byte("PK\x03\x04")}},
+	".epub": {{0, []byte("PK\x03\x04")}},
+	".rar":  {{0, []byte("Rar!")}},
+	".7z":   {{0, []byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C}}},
+	".gz":   {{0, []byte{0x1F, 0x8B}}},
+	".mp4":  {{4, []byte("ftyp")}},
+	".m4a":  {{4, []byte("ftyp")}},
+	".mp3":  {{0, []byte("ID3")}, {0, []byte{0xFF, 0xFB}}, {0, []byte{0xFF, 0xF3}}, {0, []byte{0xFF, 0xF2}}},
+}
+
+// ==================== 检测函数 ====================
+
+// DetectSuspicious 检测文件是否可疑
+// 返回可疑原因，正常文件返回空字符串
+// 检查项：下载临时扩展名、零字节、文件头与扩展名不符（截断或损坏）
+func DetectSuspicious(path, name string, size int64) string {
+	ext := strings.ToLower(filepath.Ext(name))
+
+	if partialExts[ext] {
+		return "未完成的下载 (" + ext + ")"
+	}
+	if size == 0 {
+		return "空文件 (0 字节)"
+	}
+
+	headers, ok := magicHeaders[ext]
+	if !ok {
+		return ""
+	}
+	if !matchHeader(path, headers) {
+		return "文件头与 " + ext + " 格式不符，可能已损坏或未下载完整"
+	}
+	return ""
+}
+
+// sniffOrder 按文件头识别实际格式时的检查顺序
+// ZIP 放在最后：docx、xlsx、epub 等格式都以 ZIP 文件头开始
+var sniffOrder = []string{".png", ".jpg", ".gif", ".pdf", ".rar", ".7z", ".gz", ".mp4", ".mp3", ".zip"}
+
+// Misnamed 检测扩展名与实际格式不符的文件（如实际为 PNG 的 .pdf）
+// 返回按文件头识别出的实际扩展名；扩展名正确、格式无法识别或文件只是损坏时返回空
+func Misnamed(path, name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	headers, known := magicHeaders[ext]
+	if !known && ext != "" {
+		return "" // 无法判断其他扩展名的文件应有的格式
+	}
+	buf, err := readHeader(path)
+	if err != nil || (known && hasHeader(buf, headers)) {
+		return ""
+	}
+	for _, actual := range sniffOrder {
+		if actual != ext && hasHeader(buf, magicHeaders[actual]) {
+			return actual
+		}
+	}
+	return ""
+}
+
+// matchHeader 检查文件头是否匹配任意一个签名
+func matchHeader(path string, headers []magicHeader) bool {
+	buf, err := readHeader(path)
+	if err != nil {
+		return true // 无法读取时不判定为可疑
+	}
+	return hasHeader(buf, headers)
+}
+
+// readHeader 读取文件开头的 16 个字节
+func readHeader(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 16)
+	n, _ := f.Read(buf)
+	return buf[:n], nil
+}
+
+// hasHeader 文件头是否匹配任意一个签名
+func hasHeader(buf []byte, headers []magicHeader) bool {
+	for _, h := range headers {
+		end := h.offset + len(h.magic)
+		if end <= len(buf) && bytes.Equal(buf[h.offset:end], h.magic) {
+			return true
+		}
+	}
+	return false
+}
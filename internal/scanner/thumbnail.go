@@ -0,0 +1,97 @@
+// Package scanner 文件扫描模块
+// thumbnail.go - 生成图片缩略图，供多模态模型识别图片内容
+// 使用标准库解码，支持 JPEG、PNG、GIF
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package scanner
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	_ "image/gif" // 注册 GIF 解码器
+	"image/jpeg"
+	_ "image/png" // 注册 PNG 解码器
+	"os"
+)
+
+// ThumbnailExts 可以生成缩略图的图片扩展名
+var ThumbnailExts = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true,
+}
+
+// 缩略图参数
+const (
+	ThumbnailSize    = 512              // 缩略图长边像素
+	MaxThumbnailFile = 30 * 1024 * 1024 // 超过此大小的图片不生成缩略图
+	thumbnailQuality = 80               // JPEG 压缩质量
+)
+
+// Thumbnail 生成图片的 JPEG 缩略图
+// 长边缩小到 maxSide 像素以内（按最近邻采样，足够模型识别内容），原图更小时不放大
+//
+// 参数:
+//   - path: 图片路径
+//   - maxSide: 缩略图长边像素
+//
+// 返回值:
+//   - []byte: JPEG 数据
+//   - error: 如果读取或解码失败，返回错误
+func Thumbnail(path string, maxSide int) ([]byte, error) {
+	dst, err := ThumbnailImage(path, maxSide)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ThumbnailImage 解码图片并缩小到长边 maxSide 像素以内，原图更小时不放大
+// 供需要其他编码格式（如终端内联显示用的 PNG）的调用方使用
+func ThumbnailImage(path string, maxSide int) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			w, h = maxSide, h*maxSide/w
+		} else {
+			w, h = w*maxSide/h, maxSide
+		}
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w == b.Dx() && h == b.Dy() {
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	} else {
+		for y := 0; y < h; y++ {
+			sy := b.Min.Y + y*b.Dy()/h
+			for x := 0; x < w; x++ {
+				dst.Set(x, y, src.At(b.Min.X+x*b.Dx()/w, sy))
+			}
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,118 @@
+// Package sidecar 整理信息随文件保存模块
+// 整理后在文件旁写入隐藏的 .<文件名>.filo.json，或写入文件的扩展属性，记录分类、置信度、批次和原来的位置。
+// 文件复制到其他机器或备份后，整理信息跟着文件走，可以用 filo sidecar import 导入新的数据库继续学习
+//
+// 撤销时删除写入的信息；再次整理同一个文件时按新的位置重写
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package sidecar
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ==================== 常量定义 ====================
+
+// 整理信息的保存方式（sidecar 配置）
+const (
+	FormatNone  = ""      // 不保存（默认）
+	FormatJSON  = "json"  // 文件旁的隐藏文件 .<文件名>.filo.json
+	FormatXattr = "xattr" // 文件的扩展属性（macOS、Linux），复制时需要保留扩展属性
+)
+
+// Formats 可选的保存方式
+var Formats = []string{FormatNone, FormatJSON, FormatXattr}
+
+// Suffix JSON 文件的后缀
+const Suffix = ".filo.json"
+
+// Source 导入的分类结果的来源
+const Source = "sidecar"
+
+// version 当前的整理信息格式版本
+const version = 1
+
+// ==================== 类型定义 ====================
+
+// Metadata 随文件保存的整理信息
+type Metadata struct {
+	Version     int       `json:"filo"`                  // 格式版本
+	Category    string    `json:"category"`              // 主分类
+	Subcategory string    `json:"subcategory,omitempty"` // 子分类（多级时用 / 连接）
+	Confidence  float64   `json:"confidence"`            // 分类置信度
+	Source      string    `json:"source"`                // 分类来源（memory、llm、rule 等）
+	BatchID     string    `json:"batch_id"`              // 整理批次
+	SourcePath  string    `json:"source_path"`           // 整理前的路径
+	OrganizedAt time.Time `json:"organized_at"`          // 整理时间
+}
+
+// ==================== 读写 ====================
+
+// Path 返回文件对应的 JSON 文件路径
+func Path(file string) string {
+	return filepath.Join(filepath.Dir(file), "."+filepath.Base(file)+Suffix)
+}
+
+// Write 保存文件的整理信息
+//
+// 参数:
+//   - file: 整理后的文件路径
+//   - m: 整理信息
+//   - format: 保存方式（json 或 xattr），为空时不保存
+//
+// 返回值:
+//   - error: 写入失败或当前系统不支持扩展属性时返回错误
+func Write(file string, m Metadata, format string) error {
+	if format == FormatNone {
+		return nil
+	}
+	m.Version = version
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatJSON:
+		return os.WriteFile(Path(file), data, 0644)
+	case FormatXattr:
+		return setXattr(file, data)
+	}
+	return errors.New("未知的保存方式: " + format)
+}
+
+// Read 读取文件的整理信息，先读扩展属性，没有时读 JSON 文件
+//
+// 参数:
+//   - file: 文件路径
+//
+// 返回值:
+//   - *Metadata: 整理信息，没有时为 nil
+//   - error: 整理信息存在但无法解析时返回错误
+func Read(file string) (*Metadata, error) {
+	data, err := getXattr(file)
+	if err != nil || len(data) == 0 {
+		if data, err = os.ReadFile(Path(file)); err != nil {
+			return nil, nil
+		}
+	}
+	var m Metadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if m.Version == 0 || m.Category == "" {
+		return nil, errors.New("不是 filo 的整理信息")
+	}
+	return &m, nil
+}
+
+// Remove 删除文件的整理信息（JSON 文件和扩展属性），没有时忽略
+func Remove(file string) {
+	os.Remove(Path(file))
+	removeXattr(file)
+}
@@ -0,0 +1,27 @@
+// Package sidecar 整理信息随文件保存模块
+// xattr_other.go - 其他平台不支持扩展属性，只能使用 JSON 文件
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build !linux && !darwin
+
+package sidecar
+
+import "errors"
+
+// errNoXattr 当前平台不支持扩展属性
+var errNoXattr = errors.New("当前系统不支持扩展属性，请使用 sidecar: json")
+
+// setXattr 当前平台不支持扩展属性
+func setXattr(file string, data []byte) error {
+	return errNoXattr
+}
+
+// getXattr 当前平台不支持扩展属性
+func getXattr(file string) ([]byte, error) {
+	return nil, errNoXattr
+}
+
+// removeXattr 当前平台不支持扩展属性
+func removeXattr(file string) {}
@@ -0,0 +1,47 @@
+// Package sidecar 整理信息随文件保存模块
+// xattr_unix.go - 基于扩展属性保存整理信息（macOS、Linux）
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+//go:build linux || darwin
+
+package sidecar
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// xattrName 扩展属性名，Linux 上普通用户只能写入 user. 命名空间
+func xattrName() string {
+	if runtime.GOOS == "linux" {
+		return "user.filo"
+	}
+	return "com.filo.metadata"
+}
+
+// setXattr 写入扩展属性
+func setXattr(file string, data []byte) error {
+	return unix.Setxattr(file, xattrName(), data, 0)
+}
+
+// getXattr 读取扩展属性，没有时返回错误
+func getXattr(file string) ([]byte, error) {
+	size, err := unix.Getxattr(file, xattrName(), nil)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	n, err := unix.Getxattr(file, xattrName(), buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// removeXattr 删除扩展属性
+func removeXattr(file string) {
+	unix.Removexattr(file, xattrName())
+}
@@ -0,0 +1,59 @@
+// Package storage 数据存储模块
+// activity.go - 分类文件夹的整理记录：按目标文件夹汇总 filo 移入的文件数和最近一次移入的时间，
+// filo stats --tree 据此标出长期没有收到新文件、可以考虑合并的分类
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FolderActivity 一个文件夹的整理记录
+type FolderActivity struct {
+	Moved    int       // filo 移入的文件数（不含已撤销的）
+	LastMove time.Time // 最近一次移入的时间
+}
+
+// GetFolderActivity 获取目标目录下各文件夹的整理记录
+// 按移入的文件所在的文件夹汇总，只统计仍有效（未撤销）的移动
+//
+// 参数:
+//   - targetDir: 目标目录的绝对路径
+//
+// 返回值:
+//   - map[string]FolderActivity: 文件夹的绝对路径 -> 整理记录
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetFolderActivity(targetDir string) (map[string]FolderActivity, error) {
+	prefix := strings.TrimSuffix(targetDir, string(filepath.Separator)) + string(filepath.Separator)
+	rows, err := d.db.Query(`
+		SELECT dest_path, created_at
+		FROM operation_logs
+		WHERE status = 'success' AND dest_path LIKE ? ESCAPE '\'
+	`, escapeLike(prefix)+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := make(map[string]FolderActivity)
+	for rows.Next() {
+		var dest string
+		var created time.Time
+		if rows.Scan(&dest, &created) != nil {
+			continue
+		}
+		dir := filepath.Dir(dest)
+		a := activity[dir]
+		a.Moved++
+		if created.After(a.LastMove) {
+			a.LastMove = created
+		}
+		activity[dir] = a
+	}
+	return activity, rows.Err()
+}
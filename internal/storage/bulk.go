@@ -0,0 +1,180 @@
+// Package storage 数据存储模块
+// bulk.go - 批量写入接口
+// 在单个事务中使用预编译语句写入多行，避免每行一次自动提交（一次 fsync）
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// ClassificationInput 批量写入的分类记录
+type ClassificationInput struct {
+	Filename    string   // 文件名（不含路径）
+	Extension   string   // 扩展名
+	ParentDir   string   // 原始所在目录名
+	Category    string   // 主分类
+	Subcategory string   // 子分类
+	Source      string   // 分类来源
+	Confidence  float64  // 置信度
+	Keywords    []string // 关键词
+	Confirmed   bool     // 是否已确认
+	ContentHash string   // 文件内容的快速哈希
+
+	Modified time.Time // 文件修改时间（记录为星期和小时，零值时记为 -1）
+	Burst    string    // 所属的批量下载（同一目录中短时间内集中出现的一批文件），不属于任何一批时为空
+}
+
+// VectorInput 批量写入的向量记录
+type VectorInput struct {
+	Filename    string    // 文件名
+	Category    string    // 主分类
+	Subcategory string    // 子分类
+	Vector      []float64 // 向量
+	Embedder    string    // 生成向量的嵌入器名称
+	ContentHash string    // 文件内容的快速哈希
+}
+
+// inTx 在事务中使用预编译语句执行批量写入
+// fn 对每一行调用 stmt.Exec；任一行失败时回滚整个批次（共享数据库在提交时作为一个 rqlite 事务写入）
+func (d *Database) inTx(db *sql.DB, query string, fn func(stmt *sql.Stmt) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	if err := fn(stmt); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddClassifications 批量添加分类记录
+//
+// 参数:
+//   - items: 分类记录列表
+//
+// 返回值:
+//   - error: 如果写入失败，返回错误（整批回滚）
+func (d *Database) AddClassifications(items []ClassificationInput) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return d.inTx(d.mem, `
+		INSERT INTO classification_history (filename, extension, parent_dir, category, subcategory, confidence, keywords, user_confirmed, source, content_hash,
+			weekday, hour, burst, user_name)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, func(stmt *sql.Stmt) error {
+		for _, it := range items {
+			kw, _ := json.Marshal(it.Keywords)
+			weekday, hour := -1, -1
+			if !it.Modified.IsZero() {
+				weekday, hour = int(it.Modified.Weekday()), it.Modified.Hour()
+			}
+			if _, err := stmt.Exec(it.Filename, it.Extension, it.ParentDir, it.Category, it.Subcategory,
+				it.Confidence, string(kw), it.Confirmed, it.Source, it.ContentHash, weekday, hour, it.Burst, d.user); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SaveVectors 批量保存向量
+//
+// 参数:
+//   - items: 向量记录列表
+//
+// 返回值:
+//   - error: 如果写入失败，返回错误（整批回滚）
+func (d *Database) SaveVectors(items []VectorInput) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	return d.inTx(d.mem, `
+		INSERT INTO vectors (filename, category, subcategory, vector, content_hash, embedder, dim)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, func(stmt *sql.Stmt) error {
+		for _, it := range items {
+			vecJSON, _ := json.Marshal(it.Vector)
+			if _, err := stmt.Exec(it.Filename, it.Category, it.Subcategory, vecJSON, it.ContentHash, it.Embedder, len(it.Vector)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddOperationLogs 批量添加操作日志
+//
+// 参数:
+//   - logs: 操作日志列表（ID 和 CreatedAt 字段会被忽略）
+//
+// 返回值:
+//   - error: 如果写入失败，返回错误（整批回滚）
+func (d *Database) AddOperationLogs(logs []OperationLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return d.inTx(d.db, `
+		INSERT INTO operation_logs (batch_id, source_path, dest_path, filename, category, subcategory, status, source, resolution, replaced_path, post_actions, error, transfer)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, func(stmt *sql.Stmt) error {
+		for _, l := range logs {
+			if _, err := stmt.Exec(l.BatchID, l.SourcePath, l.DestPath, l.Filename, l.Category, l.Subcategory, l.Status, l.Source, l.Resolution, l.ReplacedPath, l.PostActions, l.Error, l.Transfer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RuleInput 批量写入的规则
+type RuleInput struct {
+	Pattern     string // 匹配模式
+	PatternType string // 模式类型
+	Category    string // 主分类
+	Subcategory string // 子分类
+	Priority    int    // 优先级
+}
+
+// AddOrUpdateRules 批量添加或更新规则
+// 与 AddOrUpdateRule 语义相同，但所有规则在同一事务中写入
+//
+// 参数:
+//   - rules: 规则列表
+//
+// 返回值:
+//   - error: 如果写入失败，返回错误（整批回滚）
+func (d *Database) AddOrUpdateRules(rules []RuleInput) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	return d.inTx(d.mem, ruleUpsert, func(stmt *sql.Stmt) error {
+		for _, r := range rules {
+			pattern := r.Pattern
+			if !IsPatternRule(r.PatternType) {
+				pattern = strings.ToLower(pattern)
+			}
+			if _, err := stmt.Exec(pattern, r.PatternType, r.Category, r.Subcategory, r.Priority); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,128 @@
+// Package storage 数据存储模块
+// candidates.go - 候选规则：确认分类时学到的规则先记为候选，
+// 同一模式连续 min_samples_for_rule 次确认为同一分类后才成为生效的学习规则，
+// 避免一次偶然的确认把某个关键词变成高优先级规则
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// LearnRules 按样本数学习规则
+// 已生效的规则直接增加命中次数；其余规则记为候选并累计样本数，
+// 同一模式确认为其他分类时该模式的其他候选作废，重新计数；
+// 样本数达到 minSamples 的候选转为生效规则（命中次数即样本数）。
+// 所有写入在同一事务中完成；共享数据库（rqlite）的事务在提交前没有写入结果，
+// 而这里要根据影响的行数决定下一步，因此逐条执行（每条语句都是单条语句内的累加或 upsert）。
+// minSamples 不大于 1 时与 AddOrUpdateRules 相同
+//
+// 参数:
+//   - rules: 规则列表
+//   - minSamples: 生效所需的一致样本数
+//
+// 返回值:
+//   - int: 本次转为生效的规则数
+//   - error: 如果写入失败，返回错误（本机数据库整批回滚）
+func (d *Database) LearnRules(rules []RuleInput, minSamples int) (int, error) {
+	if minSamples <= 1 {
+		return 0, d.AddOrUpdateRules(rules)
+	}
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	var exec interface {
+		Exec(query string, args ...interface{}) (sql.Result, error)
+	} = d.mem
+	var tx *sql.Tx
+	if !d.Shared() {
+		var err error
+		if tx, err = d.mem.Begin(); err != nil {
+			return 0, err
+		}
+		exec = tx
+	}
+	rollback := func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}
+
+	promoted := 0
+	for _, r := range rules {
+		pattern := r.Pattern
+		if !IsPatternRule(r.PatternType) {
+			pattern = strings.ToLower(pattern)
+		}
+
+		// 已生效的规则
+		result, err := exec.Exec(`
+			UPDATE learned_rules
+			SET hit_count = hit_count + 1,
+			    priority = CASE WHEN demoted_from > 0 THEN priority ELSE MAX(priority, ?) END,
+			    updated_at = CURRENT_TIMESTAMP
+			WHERE pattern = ? AND pattern_type = ? AND category = ?
+		`, r.Priority, pattern, r.PatternType, r.Category)
+		if err != nil {
+			rollback()
+			return promoted, err
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			continue
+		}
+
+		// 同一模式的其他分类不再连续，作废
+		if _, err := exec.Exec(`
+			DELETE FROM rule_candidates WHERE pattern = ? AND pattern_type = ? AND category != ?
+		`, pattern, r.PatternType, r.Category); err != nil {
+			rollback()
+			return promoted, err
+		}
+		if _, err := exec.Exec(`
+			INSERT INTO rule_candidates (pattern, pattern_type, category, subcategory, priority, samples)
+			VALUES (?, ?, ?, ?, ?, 1)
+			ON CONFLICT(pattern, pattern_type, category) DO UPDATE SET
+				samples = samples + 1,
+				subcategory = excluded.subcategory,
+				priority = MAX(priority, excluded.priority),
+				updated_at = CURRENT_TIMESTAMP
+		`, pattern, r.PatternType, r.Category, r.Subcategory, r.Priority); err != nil {
+			rollback()
+			return promoted, err
+		}
+
+		// 样本数足够时转为生效规则
+		// 其他进程（或共享数据库的其他机器）可能刚转为生效规则，此时合并样本数
+		result, err = exec.Exec(`
+			INSERT INTO learned_rules (pattern, pattern_type, category, subcategory, priority, hit_count)
+			SELECT pattern, pattern_type, category, subcategory, priority, samples
+			FROM rule_candidates
+			WHERE pattern = ? AND pattern_type = ? AND category = ? AND samples >= ?
+			ON CONFLICT(pattern, pattern_type, category) DO UPDATE SET
+				hit_count = hit_count + excluded.hit_count,
+				updated_at = CURRENT_TIMESTAMP
+		`, pattern, r.PatternType, r.Category, minSamples)
+		if err != nil {
+			rollback()
+			return promoted, err
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			promoted++
+			if _, err := exec.Exec(`
+				DELETE FROM rule_candidates WHERE pattern = ? AND pattern_type = ? AND category = ?
+			`, pattern, r.PatternType, r.Category); err != nil {
+				rollback()
+				return promoted, err
+			}
+		}
+	}
+	if tx == nil {
+		return promoted, nil
+	}
+	return promoted, tx.Commit()
+}
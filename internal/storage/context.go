@@ -0,0 +1,58 @@
+// Package storage 数据存储模块
+// context.go - 分类的时间上下文统计：按文件修改时间是否在工作时间，
+// 统计已确认的分类中各主分类的数量，作为记忆匹配的弱特征
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+// 工作时间：周一至周五 WorkHourStart 点到 WorkHourEnd 点（不含）
+const (
+	WorkHourStart = 9
+	WorkHourEnd   = 18
+)
+
+// ContextDistribution 统计工作时间或休息时间修改的文件被确认为各主分类的次数
+// 没有记录修改时间的旧记录不计入
+//
+// 参数:
+//   - workTime: true 统计工作时间，false 统计休息时间（晚上和周末）
+//
+// 返回值:
+//   - map[string]int: 主分类 -> 次数
+//   - int: 总次数
+//   - error: 如果查询失败，返回错误
+func (d *Database) ContextDistribution(workTime bool) (map[string]int, int, error) {
+	cond := "weekday BETWEEN 1 AND 5 AND hour >= ? AND hour < ?"
+	if !workTime {
+		cond = "weekday >= 0 AND NOT (" + cond + ")"
+	}
+	rows, err := d.mem.Query(`
+		SELECT category, COUNT(*) FROM classification_history
+		WHERE user_confirmed = 1 AND `+cond+`
+		GROUP BY category
+	`, WorkHourStart, WorkHourEnd)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	dist := make(map[string]int)
+	total := 0
+	for rows.Next() {
+		var category string
+		var n int
+		if err := rows.Scan(&category, &n); err != nil {
+			return nil, 0, err
+		}
+		dist[category] = n
+		total += n
+	}
+	return dist, total, rows.Err()
+}
+
+// IsWorkTime 判断时间是否在工作时间内（周一至周五 9:00-18:00）
+func IsWorkTime(weekday, hour int) bool {
+	return weekday >= 1 && weekday <= 5 && hour >= WorkHourStart && hour < WorkHourEnd
+}
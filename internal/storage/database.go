@@ -9,6 +9,8 @@ package storage
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -22,7 +24,11 @@ import (
 // 封装 SQLite 数据库连接，提供分类系统所需的所有数据操作接口
 // 采用 WAL 模式提升并发性能，支持索引优化查询
 type Database struct {
-	db *sql.DB // SQLite 数据库连接实例
+	db   *sql.DB // SQLite 数据库连接实例
+	mem  *sql.DB // 学习记录（分类历史、规则、向量、反馈）所在的数据库，配置 shared_db 时为团队共享的 rqlite，否则与 db 相同
+	user string  // 写入分类历史和纠正记录的用户名，共享数据库中据此区分来自谁
+
+	stopCheckpoint chan struct{} // 关闭定时 WAL 检查点（未启动时为 nil）
 }
 
 // ClassificationRecord 分类历史记录结构体
@@ -35,19 +41,21 @@ type ClassificationRecord struct {
 	Subcategory   string    // 子分类（如 "会议纪要"、"旅行照片"）
 	Confidence    float64   // 分类置信度（0.0 ~ 1.0）
 	Keywords      []string  // 从文件名中提取的关键词列表
+	ParentDir     string    // 文件原始所在目录名
 	UserConfirmed bool      // 是否经过用户确认（确认后用于学习）
+	ContentHash   string    // 文件内容的快速哈希（用于识别改过名的文件）
 	CreatedAt     time.Time // 记录创建时间
 }
 
 // LearnedRule 学习规则结构体
 // 表示从用户确认的分类中学习到的规则模式
-// 支持三种模式类型：关键词、扩展名、前缀
+// 支持四种模式类型：关键词、扩展名、前缀、来源目录
 type LearnedRule struct {
 	ID          int64   // 规则唯一标识符
 	Pattern     string  // 匹配模式（如关键词 "invoice"、扩展名 ".pdf"）
-	PatternType string  // 模式类型：keyword（关键词）、extension（扩展名）、prefix（前缀）
+	PatternType string  // 模式类型：keyword（关键词）、extension（扩展名）、prefix（前缀）、parent_dir（来源目录）、regex（正则）、glob（通配符）
 	Category    string  // 匹配后对应的主分类
-	Subcategory string  // 匹配后对应的子分类
+	Subcategory string  // 匹配后对应的子分类（多级分类时为 / 连接的路径）
 	Priority    int     // 规则优先级（数值越高优先级越高）
 	HitCount    int     // 规则命中次数（用于统计和排序）
 	SuccessRate float64 // 规则成功率（保留字段，暂未使用）
@@ -57,8 +65,9 @@ type LearnedRule struct {
 // 执行以下操作：
 // 1. 从全局配置获取数据库路径
 // 2. 打开 SQLite 数据库连接
-// 3. 启用 WAL 模式和 NORMAL 同步模式以提升性能
+// 3. 启用 WAL 模式和 NORMAL 同步模式以提升性能，按 db_busy_timeout 设置忙等待超时
 // 4. 初始化所有必要的数据表和索引
+// 5. 配置了 shared_db 时连接团队共享的 rqlite，学习记录读写共享数据库，操作日志等仍在本机
 //
 // 返回值:
 //   - *Database: 初始化完成的数据库管理器实例
@@ -66,7 +75,8 @@ type LearnedRule struct {
 func NewDatabase() (*Database, error) {
 	// 从全局配置获取数据库文件路径
 	cfg := config.Get()
-	db, err := sql.Open("sqlite", cfg.DBPath)
+	// busy_timeout 对连接池中的每个连接生效：其他进程写入时等待而不是立即返回 SQLITE_BUSY
+	db, err := sql.Open("sqlite", fmt.Sprintf("%s?_pragma=busy_timeout(%d)", cfg.DBPath, cfg.DBBusyTimeout))
 	if err != nil {
 		return nil, err
 	}
@@ -80,10 +90,23 @@ func NewDatabase() (*Database, error) {
 	db.Exec("PRAGMA synchronous=NORMAL")
 
 	// 创建数据库管理器实例并初始化表结构
-	d := &Database{db: db}
-	if err := d.init(); err != nil {
+	d := &Database{db: db, mem: db, user: cfg.User()}
+	if err := d.init(db); err != nil {
 		return nil, err
 	}
+
+	// 团队共享的学习记录
+	if cfg.SharedDB != "" {
+		shared, err := sql.Open("rqlite", cfg.SharedDB)
+		if err == nil {
+			err = d.init(shared)
+		}
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("%w %s: %v", ErrSharedDB, cfg.SharedDB, err)
+		}
+		d.mem = shared
+	}
 	return d, nil
 }
 
@@ -94,10 +117,14 @@ func NewDatabase() (*Database, error) {
 // 3. user_feedback - 用户反馈表
 // 4. vectors - 向量存储表
 // 以及相关的索引以优化查询性能
+// 本机数据库和共享数据库使用相同的表结构（各自只用到其中一部分表）
+//
+// 参数:
+//   - db: 要初始化的数据库
 //
 // 返回值:
 //   - error: 如果任何表或索引创建失败，返回错误
-func (d *Database) init() error {
+func (d *Database) init(db *sql.DB) error {
 	schemas := []string{
 		// ========== 分类历史表 ==========
 		// 记录每次文件分类的详细信息
@@ -207,23 +234,222 @@ func (d *Database) init() error {
 		// 模型性能索引
 		`CREATE INDEX IF NOT EXISTS idx_model_stats_name ON model_stats(model_name)`,
 		`CREATE INDEX IF NOT EXISTS idx_model_stats_time ON model_stats(created_at)`,
+
+		// ========== 待确认队列表 ==========
+		// 低置信度的分类不自动归档，而是等待用户在 filo review 中确认
+		`CREATE TABLE IF NOT EXISTS review_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			file_path TEXT NOT NULL,
+			filename TEXT NOT NULL,
+			target_dir TEXT NOT NULL,
+			category TEXT NOT NULL,
+			subcategory TEXT DEFAULT '',
+			confidence REAL DEFAULT 0,
+			reasoning TEXT DEFAULT '',
+			source TEXT DEFAULT 'llm',
+			batch_id TEXT DEFAULT '',
+			status TEXT DEFAULT 'pending',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			resolved_at TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_review_status ON review_queue(status)`,
+
+		// ========== 计划快照表 ==========
+		// 预览模式保存的整理计划，供 filo diff 对比
+		`CREATE TABLE IF NOT EXISTS plan_snapshots (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			snapshot_id TEXT NOT NULL,
+			source_dir TEXT NOT NULL,
+			rel_path TEXT NOT NULL,
+			category TEXT NOT NULL,
+			subcategory TEXT DEFAULT '',
+			confidence REAL DEFAULT 0,
+			source TEXT DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_plan_snapshots_dir ON plan_snapshots(source_dir)`,
+
+		// ========== 运行摘要表 ==========
+		// 每次分类的记忆命中率、平均置信度和纠正数，供 filo stats --trend 显示趋势
+		`CREATE TABLE IF NOT EXISTS run_stats (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			batch_id TEXT NOT NULL,
+			file_count INTEGER DEFAULT 0,
+			memory_hits INTEGER DEFAULT 0,
+			llm_count INTEGER DEFAULT 0,
+			avg_confidence REAL DEFAULT 0,
+			corrected_count INTEGER DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_run_stats_batch ON run_stats(batch_id)`,
+
+		// ========== 模型调用表 ==========
+		// 每次模型调用的 token 数、耗时和是否失败，供 filo stats --llm 调整模型和批大小
+		`CREATE TABLE IF NOT EXISTS llm_calls (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			batch_id TEXT DEFAULT '',
+			model TEXT NOT NULL,
+			kind TEXT DEFAULT '',
+			file_count INTEGER DEFAULT 0,
+			prompt_tokens INTEGER DEFAULT 0,
+			response_tokens INTEGER DEFAULT 0,
+			latency_ms INTEGER DEFAULT 0,
+			success INTEGER DEFAULT 1,
+			error TEXT DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_llm_calls_model ON llm_calls(model)`,
+
+		// ========== 隔离记录表 ==========
+		// 隔离模式下移入隔离区的可执行文件、脚本和安装包及其 SHA-256
+		`CREATE TABLE IF NOT EXISTS quarantine_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			sha256 TEXT NOT NULL,
+			filename TEXT NOT NULL,
+			size INTEGER DEFAULT 0,
+			source_path TEXT NOT NULL,
+			dest_path TEXT NOT NULL,
+			kind TEXT DEFAULT '',
+			batch_id TEXT DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_quarantine_sha256 ON quarantine_log(sha256)`,
+
+		// ========== 扩展名默认分类表 ==========
+		// 记忆、规则和 AI 都无法分类时按扩展名兜底，首次使用时写入内置项，可用 filo rules ext 修改
+		`CREATE TABLE IF NOT EXISTS extension_defaults (
+			extension TEXT PRIMARY KEY,
+			category TEXT NOT NULL,
+			subcategory TEXT DEFAULT '',
+			builtin INTEGER DEFAULT 1,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// ========== 候选规则表 ==========
+		// 确认分类时学到、样本数还不够的规则，连续确认 min_samples_for_rule 次后移入 learned_rules
+		`CREATE TABLE IF NOT EXISTS rule_candidates (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			pattern TEXT NOT NULL,
+			pattern_type TEXT DEFAULT 'keyword',
+			category TEXT NOT NULL,
+			subcategory TEXT DEFAULT '',
+			priority INTEGER DEFAULT 0,
+			samples INTEGER DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(pattern, pattern_type, category)
+		)`,
+		// 目录固定模型表：整理该目录（及其子目录）时使用的模型
+		`CREATE TABLE IF NOT EXISTS model_pins (
+			directory TEXT PRIMARY KEY,
+			model TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// 准确率自测记录表：每次 filo selftest 的模型和准确率，供与之前的结果对比
+		`CREATE TABLE IF NOT EXISTS selftest_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			model TEXT NOT NULL,
+			rules INTEGER DEFAULT 1,
+			note TEXT DEFAULT '',
+			samples INTEGER DEFAULT 0,
+			category_correct INTEGER DEFAULT 0,
+			path_correct INTEGER DEFAULT 0,
+			duration_ms INTEGER DEFAULT 0,
+			per_category TEXT DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
 	}
 
 	// 依次执行所有 DDL 语句
 	for _, schema := range schemas {
-		if _, err := d.db.Exec(schema); err != nil {
+		if _, err := db.Exec(schema); err != nil {
 			return err
 		}
 	}
+
+	// ========== 表结构迁移 ==========
+	// 为旧版本数据库补充新增字段
+	// 字段已存在时 SQLite 会返回 duplicate column 错误，直接忽略即可
+	migrations := []string{
+		// 文件原始所在目录名（用于来源目录学习）
+		`ALTER TABLE classification_history ADD COLUMN parent_dir TEXT DEFAULT ''`,
+		// 操作日志的分类来源（用于事后纠正时调整模型准确度）
+		`ALTER TABLE operation_logs ADD COLUMN source TEXT DEFAULT ''`,
+		// 操作日志的重名处理结果及被替换文件的备份路径（撤销时据此还原）
+		`ALTER TABLE operation_logs ADD COLUMN resolution TEXT DEFAULT ''`,
+		`ALTER TABLE operation_logs ADD COLUMN replaced_path TEXT DEFAULT ''`,
+		`ALTER TABLE operation_logs ADD COLUMN post_actions TEXT DEFAULT ''`,
+		// 模型统计的批大小和并行批次数（自动调整批大小的依据和结果）
+		`ALTER TABLE model_stats ADD COLUMN batch_size INTEGER DEFAULT 0`,
+		`ALTER TABLE model_stats ADD COLUMN parallel INTEGER DEFAULT 1`,
+		// 运行摘要的中断标记（Ctrl-C 取消时只整理了部分文件）
+		`ALTER TABLE run_stats ADD COLUMN partial INTEGER DEFAULT 0`,
+		// 文件内容的快速哈希（改名后的文件仍能命中记忆）
+		`ALTER TABLE classification_history ADD COLUMN content_hash TEXT DEFAULT ''`,
+		`ALTER TABLE vectors ADD COLUMN content_hash TEXT DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_history_content_hash ON classification_history(content_hash)`,
+		// 待确认项的入队原因及来源矛盾时的另一个建议
+		`ALTER TABLE review_queue ADD COLUMN reason TEXT DEFAULT ''`,
+		`ALTER TABLE review_queue ADD COLUMN alt_category TEXT DEFAULT ''`,
+		`ALTER TABLE review_queue ADD COLUMN alt_subcategory TEXT DEFAULT ''`,
+		`ALTER TABLE review_queue ADD COLUMN alt_confidence REAL DEFAULT 0`,
+		`ALTER TABLE review_queue ADD COLUMN alt_source TEXT DEFAULT ''`,
+		// 分类时的上下文：文件修改时间的星期和小时（未知为 -1）、所属的批量下载
+		`ALTER TABLE classification_history ADD COLUMN weekday INTEGER DEFAULT -1`,
+		`ALTER TABLE classification_history ADD COLUMN hour INTEGER DEFAULT -1`,
+		`ALTER TABLE classification_history ADD COLUMN burst TEXT DEFAULT ''`,
+		// 运行摘要的详细信息：目录、模型、各来源文件数、耗时和执行结果，供 filo last 查看
+		`ALTER TABLE run_stats ADD COLUMN source_dir TEXT DEFAULT ''`,
+		`ALTER TABLE run_stats ADD COLUMN target_dir TEXT DEFAULT ''`,
+		`ALTER TABLE run_stats ADD COLUMN model TEXT DEFAULT ''`,
+		`ALTER TABLE run_stats ADD COLUMN sources TEXT DEFAULT ''`,
+		`ALTER TABLE run_stats ADD COLUMN duration_ms INTEGER DEFAULT 0`,
+		`ALTER TABLE run_stats ADD COLUMN executed INTEGER DEFAULT 0`,
+		`ALTER TABLE run_stats ADD COLUMN moved INTEGER DEFAULT 0`,
+		`ALTER TABLE run_stats ADD COLUMN failed INTEGER DEFAULT 0`,
+		`ALTER TABLE run_stats ADD COLUMN skipped INTEGER DEFAULT 0`,
+		`ALTER TABLE run_stats ADD COLUMN review INTEGER DEFAULT 0`,
+		`ALTER TABLE run_stats ADD COLUMN categories_created TEXT DEFAULT ''`,
+		`ALTER TABLE run_stats ADD COLUMN failures TEXT DEFAULT ''`,
+		`ALTER TABLE run_stats ADD COLUMN aborted TEXT DEFAULT ''`,
+		// 向量的嵌入器和维度（检索时只比较同一嵌入器的向量），旧向量补上维度
+		`ALTER TABLE vectors ADD COLUMN embedder TEXT DEFAULT ''`,
+		`ALTER TABLE vectors ADD COLUMN dim INTEGER DEFAULT 0`,
+		`CREATE INDEX IF NOT EXISTS idx_vectors_space ON vectors(dim, embedder)`,
+		`UPDATE vectors SET dim = json_array_length(vector) WHERE dim = 0`,
+		// 操作日志的失败原因（filo retry 据此列出并重试失败的文件）
+		`ALTER TABLE operation_logs ADD COLUMN error TEXT DEFAULT ''`,
+		// 操作日志的移动方式（跨设备时复制校验后删除源文件）
+		`ALTER TABLE operation_logs ADD COLUMN transfer TEXT DEFAULT ''`,
+		// 规则的负反馈（撤销和纠正），多次出错的学习规则被降级或停用
+		`ALTER TABLE learned_rules ADD COLUMN undo_count INTEGER DEFAULT 0`,
+		`ALTER TABLE learned_rules ADD COLUMN correction_count INTEGER DEFAULT 0`,
+		`ALTER TABLE learned_rules ADD COLUMN disabled INTEGER DEFAULT 0`,
+		`ALTER TABLE learned_rules ADD COLUMN demoted_from INTEGER DEFAULT 0`,
+		// 分类历史和纠正记录的用户（共享数据库中区分来自谁）
+		`ALTER TABLE classification_history ADD COLUMN user_name TEXT DEFAULT ''`,
+		`ALTER TABLE user_feedback ADD COLUMN user_name TEXT DEFAULT ''`,
+	}
+	for _, m := range migrations {
+		db.Exec(m)
+	}
 	return nil
 }
 
 // Close 关闭数据库连接
-// 释放数据库资源，应在程序退出前调用
+// 释放数据库资源，应在程序退出前调用。
+// 关闭前停止定时检查点并执行一次 WAL 检查点，避免其他进程仍打开数据库时 WAL 文件残留
 //
 // 返回值:
 //   - error: 如果关闭失败，返回错误
 func (d *Database) Close() error {
+	if d.stopCheckpoint != nil {
+		close(d.stopCheckpoint)
+		d.stopCheckpoint = nil
+	}
+	d.Checkpoint() // 失败不影响关闭，下次检查点会继续写回
+	if d.Shared() {
+		d.mem.Close()
+	}
 	return d.db.Close()
 }
 
@@ -236,29 +462,92 @@ func (d *Database) Close() error {
 // 参数:
 //   - filename: 文件名（不含路径）
 //   - ext: 文件扩展名
+//   - parentDir: 文件原始所在目录名（可为空）
 //   - category: 主分类名称
 //   - subcategory: 子分类名称
 //   - source: 分类来源（"llm" 表示 AI 分类，"memory" 表示记忆匹配）
 //   - confidence: 分类置信度（0.0 ~ 1.0）
 //   - keywords: 从文件名提取的关键词列表
 //   - confirmed: 是否已确认（用户确认后可用于学习）
+//   - contentHash: 文件内容的快速哈希（无法计算时为空）
 //
 // 返回值:
 //   - int64: 新插入记录的 ID
 //   - error: 如果插入失败，返回错误
-func (d *Database) AddClassification(filename, ext, category, subcategory, source string, confidence float64, keywords []string, confirmed bool) (int64, error) {
+func (d *Database) AddClassification(filename, ext, parentDir, category, subcategory, source string, confidence float64, keywords []string, confirmed bool, contentHash string) (int64, error) {
 	// 将关键词列表序列化为 JSON 字符串存储
 	kw, _ := json.Marshal(keywords)
-	result, err := d.db.Exec(`
-		INSERT INTO classification_history (filename, extension, category, subcategory, confidence, keywords, user_confirmed, source)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, filename, ext, category, subcategory, confidence, string(kw), confirmed, source)
+	result, err := d.mem.Exec(`
+		INSERT INTO classification_history (filename, extension, parent_dir, category, subcategory, confidence, keywords, user_confirmed, source, content_hash, user_name)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, filename, ext, parentDir, category, subcategory, confidence, string(kw), confirmed, source, contentHash, d.user)
 	if err != nil {
 		return 0, err
 	}
 	return result.LastInsertId()
 }
 
+// GetCategoryNames 获取历史记录中出现过的主分类名
+// 按使用次数降序排列，用于命令行补全
+func (d *Database) GetCategoryNames() []string {
+	rows, err := d.mem.Query(`
+		SELECT category FROM classification_history
+		GROUP BY category
+		ORDER BY COUNT(*) DESC
+	`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// CategoryCount 历史记录中的一个分类及其使用次数
+type CategoryCount struct {
+	Category    string // 主分类
+	Subcategory string // 子分类
+	Count       int    // 使用次数
+}
+
+// GetCategoryPairs 获取历史记录中出现过的主分类/子分类组合
+// 按使用次数降序排列，用于交互修改分类时提供候选
+//
+// 参数:
+//   - limit: 返回结果的最大数量
+//
+// 返回值:
+//   - []CategoryCount: 分类组合列表
+func (d *Database) GetCategoryPairs(limit int) []CategoryCount {
+	rows, err := d.mem.Query(`
+		SELECT category, subcategory, COUNT(*) AS n FROM classification_history
+		WHERE category != ''
+		GROUP BY category, subcategory
+		ORDER BY n DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var pairs []CategoryCount
+	for rows.Next() {
+		var p CategoryCount
+		if rows.Scan(&p.Category, &p.Subcategory, &p.Count) == nil {
+			pairs = append(pairs, p)
+		}
+	}
+	return pairs
+}
+
 // GetSimilarClassifications 获取与给定关键词相似的历史分类记录
 // 基于关键词在文件名中的模糊匹配，查找已确认的历史分类
 // 用于"记忆优先"策略中的历史匹配
@@ -305,7 +594,7 @@ func (d *Database) GetSimilarClassifications(keywords []string, limit int) ([]Cl
 	args = append(args, limit)
 
 	// 执行查询
-	rows, err := d.db.Query(query, args...)
+	rows, err := d.mem.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -328,6 +617,100 @@ func (d *Database) GetSimilarClassifications(keywords []string, limit int) ([]Cl
 	return records, nil
 }
 
+// FindClassificationByHash 按文件内容哈希查找历史分类记录
+// 同一个文件改名后哈希不变，据此找回它之前的分类
+// 优先返回用户确认过的记录，其次是最近的记录
+//
+// 参数:
+//   - contentHash: 文件内容的快速哈希
+//
+// 返回值:
+//   - *ClassificationRecord: 匹配的记录，没有记录或哈希为空时为 nil
+func (d *Database) FindClassificationByHash(contentHash string) *ClassificationRecord {
+	if contentHash == "" {
+		return nil
+	}
+	var r ClassificationRecord
+	var createdAt string
+	err := d.mem.QueryRow(`
+		SELECT id, filename, extension, category, subcategory, confidence, user_confirmed, content_hash, created_at
+		FROM classification_history
+		WHERE content_hash = ?
+		ORDER BY user_confirmed DESC, id DESC
+		LIMIT 1
+	`, contentHash).Scan(&r.ID, &r.Filename, &r.Extension, &r.Category, &r.Subcategory, &r.Confidence, &r.UserConfirmed, &r.ContentHash, &createdAt)
+	if err != nil {
+		return nil
+	}
+	r.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
+	return &r
+}
+
+// FindRecentClassification 查找最近分类过的同一文件（文件名和内容哈希都相同）
+// 返回最新的一条记录；事后纠正的记录晚于原来的分类，因此以纠正后的分类为准
+//
+// 参数:
+//   - filename: 文件名（不含路径）
+//   - contentHash: 文件内容的快速哈希（包含文件大小），为空时不查找
+//   - days: 只查找该天数内的记录
+//
+// 返回值:
+//   - *ClassificationRecord: 最新的记录，没有时为 nil
+func (d *Database) FindRecentClassification(filename, contentHash string, days int) *ClassificationRecord {
+	if contentHash == "" {
+		return nil
+	}
+	var r ClassificationRecord
+	err := d.mem.QueryRow(`
+		SELECT id, filename, extension, category, subcategory, confidence, user_confirmed, content_hash, created_at
+		FROM classification_history
+		WHERE content_hash = ? AND filename = ? AND created_at >= datetime('now', ?)
+		ORDER BY id DESC
+		LIMIT 1
+	`, contentHash, filename, fmt.Sprintf("-%d days", days)).Scan(&r.ID, &r.Filename, &r.Extension, &r.Category, &r.Subcategory, &r.Confidence, &r.UserConfirmed, &r.ContentHash, &r.CreatedAt)
+	if err != nil {
+		return nil
+	}
+	return &r
+}
+
+// GetUnsortedFiles 获取没有合适分类的文件：最近一次分类为「未分类」或置信度低于阈值，且从未被确认或纠正
+// 供 filo suggest 聚类后建议新的分类
+//
+// 参数:
+//   - threshold: 置信度阈值，低于该值视为低置信度
+//   - limit: 返回的最大数量
+//
+// 返回值:
+//   - []string: 文件名（去重），最近分类的在前
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetUnsortedFiles(threshold float64, limit int) ([]string, error) {
+	rows, err := d.mem.Query(`
+		SELECT h.filename
+		FROM classification_history h
+		WHERE h.id = (SELECT MAX(id) FROM classification_history WHERE filename = h.filename)
+		  AND (h.category = '未分类' OR h.confidence < ?)
+		  AND NOT EXISTS (
+			SELECT 1 FROM classification_history c WHERE c.filename = h.filename AND c.user_confirmed = 1
+		  )
+		ORDER BY h.id DESC
+		LIMIT ?
+	`, threshold, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []string
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil {
+			files = append(files, name)
+		}
+	}
+	return files, rows.Err()
+}
+
 // ConfirmClassification 确认分类记录
 // 将指定 ID 的分类记录标记为已确认
 // 确认后的记录将被用于规则学习
@@ -338,7 +721,7 @@ func (d *Database) GetSimilarClassifications(keywords []string, limit int) ([]Cl
 // 返回值:
 //   - error: 如果更新失败，返回错误
 func (d *Database) ConfirmClassification(id int64) error {
-	_, err := d.db.Exec("UPDATE classification_history SET user_confirmed = 1 WHERE id = ?", id)
+	_, err := d.mem.Exec("UPDATE classification_history SET user_confirmed = 1 WHERE id = ?", id)
 	return err
 }
 
@@ -362,62 +745,79 @@ func (d *Database) ConfirmClassification(id int64) error {
 //   - error: 如果操作失败，返回错误
 func (d *Database) AddOrUpdateRule(pattern, patternType, category, subcategory string, priority int) error {
 	// 统一转换为小写，确保匹配时大小写不敏感
-	pattern = strings.ToLower(pattern)
-
-	// 尝试更新已有规则
-	// 如果存在相同的 pattern + pattern_type + category 组合，
-	// 则增加命中次数，并取当前优先级和传入优先级的较大值
-	result, err := d.db.Exec(`
-		UPDATE learned_rules 
-		SET hit_count = hit_count + 1, 
-		    priority = MAX(priority, ?),
-		    updated_at = CURRENT_TIMESTAMP
-		WHERE pattern = ? AND pattern_type = ? AND category = ?
-	`, priority, pattern, patternType, category)
-
-	if err != nil {
-		return err
+	// 正则和通配符保留原样（如 \D 与 \d 含义不同），匹配时使用不区分大小写的模式
+	if !IsPatternRule(patternType) {
+		pattern = strings.ToLower(pattern)
 	}
 
-	// 检查是否有记录被更新
-	affected, _ := result.RowsAffected()
-	if affected == 0 {
-		// 没有已存在的规则，插入新规则
-		_, err = d.db.Exec(`
-			INSERT INTO learned_rules (pattern, pattern_type, category, subcategory, priority, hit_count)
-			VALUES (?, ?, ?, ?, ?, 1)
-		`, pattern, patternType, category, subcategory, priority)
-	}
+	// 插入新规则；如果存在相同的 pattern + pattern_type + category 组合，
+	// 则增加命中次数，并取当前优先级和传入优先级的较大值（因负反馈降级的规则保持降级后的优先级）
+	_, err := d.mem.Exec(ruleUpsert, pattern, patternType, category, subcategory, priority)
 	return err
 }
 
+// ruleUpsert 插入或累加一条学习规则
+// 单条语句完成，多个进程（或共享数据库的多台机器）同时学到同一条规则时不会因唯一约束失败，也不会丢失命中次数
+const ruleUpsert = `
+	INSERT INTO learned_rules (pattern, pattern_type, category, subcategory, priority, hit_count)
+	VALUES (?, ?, ?, ?, ?, 1)
+	ON CONFLICT(pattern, pattern_type, category) DO UPDATE SET
+		hit_count = hit_count + 1,
+		priority = CASE WHEN demoted_from > 0 THEN priority ELSE MAX(priority, excluded.priority) END,
+		updated_at = CURRENT_TIMESTAMP
+`
+
 // GetMatchingRules 获取与给定文件匹配的规则
-// 根据文件名、关键词和扩展名查找匹配的学习规则
-// 支持两种匹配方式：
-// 1. 扩展名精确匹配
-// 2. 关键词模糊匹配（模式包含在文件名中）
+// 根据文件名、关键词、扩展名和来源目录查找匹配的学习规则
+// 支持五种匹配方式：
+// 1. 正则和通配符匹配（用户手动创建，排在最前）
+// 2. 文件名语言匹配（用户手动创建）
+// 3. 来源目录精确匹配
+// 4. 扩展名精确匹配
+// 5. 关键词模糊匹配（模式包含在文件名中）
 //
 // 参数:
 //   - filename: 文件名
 //   - keywords: 从文件名提取的关键词列表
 //   - ext: 文件扩展名
+//   - parentDir: 文件所在目录名（为空时跳过来源目录匹配）
+//   - language: 文件名的主要语言代码（为空时跳过语言匹配）
 //
 // 返回值:
 //   - []LearnedRule: 匹配到的规则列表（已去重）
 //   - error: 如果查询失败，返回错误
-func (d *Database) GetMatchingRules(filename string, keywords []string, ext string) ([]LearnedRule, error) {
-	var rules []LearnedRule
+func (d *Database) GetMatchingRules(filename string, keywords []string, ext, parentDir, language string) ([]LearnedRule, error) {
+	// 正则和通配符规则匹配原始文件名（模式本身不区分大小写）
+	rules := d.matchPatternRules(filename)
+	rules = append(rules, d.matchLanguageRules(language, ext)...)
+
 	// 统一转换为小写进行匹配
 	filename = strings.ToLower(filename)
 	ext = strings.ToLower(ext)
 
+	// ===== 0. 来源目录匹配 =====
+	// 文件所在目录名本身就是很强的分类信号
+	if parentDir != "" {
+		rows, _ := d.mem.Query(`
+			SELECT id, pattern, pattern_type, category, subcategory, priority, hit_count
+			FROM learned_rules 
+			WHERE pattern_type = 'parent_dir' AND pattern = ? AND disabled = 0
+			ORDER BY priority DESC, hit_count DESC
+			LIMIT 3
+		`, strings.ToLower(parentDir))
+		if rows != nil {
+			rules = append(rules, d.scanRules(rows)...)
+			rows.Close()
+		}
+	}
+
 	// ===== 1. 扩展名匹配 =====
 	// 查找与文件扩展名完全匹配的规则
 	if ext != "" {
-		rows, _ := d.db.Query(`
+		rows, _ := d.mem.Query(`
 			SELECT id, pattern, pattern_type, category, subcategory, priority, hit_count
 			FROM learned_rules 
-			WHERE pattern_type = 'extension' AND pattern = ?
+			WHERE pattern_type = 'extension' AND pattern = ? AND disabled = 0
 			ORDER BY priority DESC, hit_count DESC
 			LIMIT 3
 		`, ext)
@@ -434,10 +834,10 @@ func (d *Database) GetMatchingRules(filename string, keywords []string, ext stri
 		if len(kw) < 2 {
 			continue
 		}
-		rows, _ := d.db.Query(`
+		rows, _ := d.mem.Query(`
 			SELECT id, pattern, pattern_type, category, subcategory, priority, hit_count
 			FROM learned_rules 
-			WHERE pattern_type = 'keyword' AND ? LIKE '%' || pattern || '%'
+			WHERE pattern_type = 'keyword' AND ? LIKE '%' || pattern || '%' AND disabled = 0
 			ORDER BY priority DESC, hit_count DESC
 			LIMIT 3
 		`, filename)
@@ -461,6 +861,138 @@ func (d *Database) GetMatchingRules(filename string, keywords []string, ext stri
 	return unique, nil
 }
 
+// GetDeterministicRule 按固定顺序查找文件最匹配的规则（用于仅规则模式）
+// 只考虑正则、通配符、关键词、语言和扩展名规则，排序不依赖命中次数，
+// 规则库不变时同一文件名总是得到同样的结果：
+// 优先级高者优先，其次 正则/通配符 > 关键词 > 语言 > 扩展名，再次模式较长者优先，最后按模式和 ID
+//
+// 参数:
+//   - filename: 文件名
+//   - ext: 文件扩展名
+//   - language: 文件名的主要语言代码（为空时跳过语言匹配）
+//
+// 返回值:
+//   - *LearnedRule: 最匹配的规则，没有匹配时为 nil
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetDeterministicRule(filename, ext, language string) (*LearnedRule, error) {
+	rules := d.matchPatternRules(filename)
+	rules = append(rules, d.matchLanguageRules(language, ext)...)
+
+	rows, err := d.mem.Query(`
+		SELECT id, pattern, pattern_type, category, subcategory, priority, hit_count
+		FROM learned_rules
+		WHERE ((pattern_type = 'keyword' AND length(pattern) >= 2 AND ? LIKE '%' || pattern || '%')
+		    OR (pattern_type = 'extension' AND pattern = ?))
+		  AND disabled = 0
+	`, strings.ToLower(filename), strings.ToLower(ext))
+	if err != nil {
+		return nil, err
+	}
+	rules = append(rules, d.scanRules(rows)...)
+	rows.Close()
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	rank := func(patternType string) int {
+		switch {
+		case IsPatternRule(patternType):
+			return 0
+		case patternType == "keyword":
+			return 1
+		case patternType == PatternLanguage:
+			return 2
+		default:
+			return 3
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if rank(a.PatternType) != rank(b.PatternType) {
+			return rank(a.PatternType) < rank(b.PatternType)
+		}
+		if len(a.Pattern) != len(b.Pattern) {
+			return len(a.Pattern) > len(b.Pattern)
+		}
+		if a.Pattern != b.Pattern {
+			return a.Pattern < b.Pattern
+		}
+		return a.ID < b.ID
+	})
+	return &rules[0], nil
+}
+
+// GetRules 获取所有规则（含 ID），可按模式类型过滤
+//
+// 参数:
+//   - patternType: 模式类型，为空表示全部
+//
+// 返回值:
+//   - []LearnedRule: 规则列表，按优先级和命中次数降序
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetRules(patternType string) ([]LearnedRule, error) {
+	query := `
+		SELECT id, pattern, pattern_type, category, subcategory, priority, hit_count
+		FROM learned_rules`
+	var args []interface{}
+	if patternType != "" {
+		query += ` WHERE pattern_type = ?`
+		args = append(args, patternType)
+	}
+	query += ` ORDER BY priority DESC, hit_count DESC`
+
+	rows, err := d.mem.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return d.scanRules(rows), nil
+}
+
+// DeleteRule 删除指定规则
+//
+// 参数:
+//   - id: 规则 ID
+//
+// 返回值:
+//   - bool: 规则是否存在并被删除
+//   - error: 如果删除失败，返回错误
+func (d *Database) DeleteRule(id int64) (bool, error) {
+	result, err := d.mem.Exec("DELETE FROM learned_rules WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// UpdateRule 修改指定规则的目标分类和优先级
+//
+// 参数:
+//   - id: 规则 ID
+//   - category: 新的主分类
+//   - subcategory: 新的子分类
+//   - priority: 新的优先级
+//
+// 返回值:
+//   - bool: 规则是否存在并被修改
+//   - error: 如果修改失败，返回错误
+func (d *Database) UpdateRule(id int64, category, subcategory string, priority int) (bool, error) {
+	result, err := d.mem.Exec(`
+		UPDATE learned_rules
+		SET category = ?, subcategory = ?, priority = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, category, subcategory, priority, id)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
 // scanRules 从数据库行扫描规则数据
 // 辅助方法，用于将 sql.Rows 转换为 LearnedRule 切片
 //
@@ -491,10 +1023,10 @@ func (d *Database) scanRules(rows *sql.Rows) []LearnedRule {
 //   - []map[string]interface{}: 规则信息列表，每个元素包含 pattern、pattern_type、category 等字段
 //   - error: 如果查询失败，返回错误
 func (d *Database) GetTopRules(limit int) ([]map[string]interface{}, error) {
-	rows, err := d.db.Query(`
+	rows, err := d.mem.Query(`
 		SELECT pattern, pattern_type, category, subcategory, hit_count, priority
 		FROM learned_rules
-		WHERE hit_count >= 1
+		WHERE hit_count >= 1 AND disabled = 0
 		ORDER BY hit_count DESC, priority DESC
 		LIMIT ?
 	`, limit)
@@ -535,16 +1067,18 @@ func (d *Database) GetTopRules(limit int) ([]map[string]interface{}, error) {
 //   - category: 对应的主分类
 //   - subcategory: 对应的子分类
 //   - vector: 向量嵌入数据（float64 数组）
+//   - embedder: 生成向量的嵌入器名称（见 embedding.NameOf）
+//   - contentHash: 文件内容的快速哈希（无法计算时为空）
 //
 // 返回值:
 //   - error: 如果保存失败，返回错误
-func (d *Database) SaveVector(filename, category, subcategory string, vector []float64) error {
+func (d *Database) SaveVector(filename, category, subcategory string, vector []float64, embedder, contentHash string) error {
 	// 将向量序列化为 JSON 字符串存储
 	vecJSON, _ := json.Marshal(vector)
-	_, err := d.db.Exec(`
-		INSERT INTO vectors (filename, category, subcategory, vector)
-		VALUES (?, ?, ?, ?)
-	`, filename, category, subcategory, vecJSON)
+	_, err := d.mem.Exec(`
+		INSERT INTO vectors (filename, category, subcategory, vector, content_hash, embedder, dim)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, filename, category, subcategory, vecJSON, contentHash, embedder, len(vector))
 	return err
 }
 
@@ -554,25 +1088,29 @@ type VectorRecord struct {
 	Category    string    // 主分类
 	Subcategory string    // 子分类
 	Vector      []float64 // 向量嵌入数据
+	Similarity  float64   // 与查询向量的相似度（由调用方计算后填充）
 }
 
 // SearchVectors 检索存储的向量数据
-// 获取最近存储的向量记录，用于相似度计算
+// 获取最近存储的、与查询向量同一空间的向量记录，用于相似度计算
 // 返回结果按创建时间倒序排列
 //
 // 参数:
+//   - space: 查询向量所属的空间，只返回可以比较的向量
 //   - limit: 返回结果的最大数量
 //
 // 返回值:
 //   - 向量记录切片，每个元素包含文件名、分类和向量数据
 //   - error: 如果查询失败，返回错误
-func (d *Database) SearchVectors(limit int) ([]VectorRecord, error) {
-	rows, err := d.db.Query(`
+func (d *Database) SearchVectors(space VectorSpace, limit int) ([]VectorRecord, error) {
+	filter, args := space.spaceFilter("")
+	rows, err := d.mem.Query(`
 		SELECT filename, category, subcategory, vector
 		FROM vectors
+		WHERE `+filter+`
 		ORDER BY created_at DESC
 		LIMIT ?
-	`, limit)
+	`, append(args, limit)...)
 	if err != nil {
 		return nil, err
 	}
@@ -585,36 +1123,37 @@ func (d *Database) SearchVectors(limit int) ([]VectorRecord, error) {
 // 通过预过滤减少需要比对的向量数量，提升大数据量下的性能
 //
 // 参数:
+//   - space: 查询向量所属的空间，只返回可以比较的向量
 //   - categories: 候选分类列表（从规则或历史匹配中获取）
 //   - limit: 每个分类返回的最大数量
 //
 // 返回值:
 //   - 向量记录切片
 //   - error: 如果查询失败，返回错误
-func (d *Database) SearchVectorsByCategories(categories []string, limit int) ([]VectorRecord, error) {
+func (d *Database) SearchVectorsByCategories(space VectorSpace, categories []string, limit int) ([]VectorRecord, error) {
 	if len(categories) == 0 {
 		// 无分类过滤时，使用默认搜索
-		return d.SearchVectors(limit)
+		return d.SearchVectors(space, limit)
 	}
 
 	// 构建 IN 查询条件
+	filter, args := space.spaceFilter("")
 	placeholders := make([]string, len(categories))
-	args := make([]interface{}, len(categories)+1)
 	for i, cat := range categories {
 		placeholders[i] = "?"
-		args[i] = cat
+		args = append(args, cat)
 	}
-	args[len(categories)] = limit
+	args = append(args, limit)
 
 	query := `
 		SELECT filename, category, subcategory, vector
 		FROM vectors
-		WHERE category IN (` + strings.Join(placeholders, ",") + `)
+		WHERE ` + filter + ` AND category IN (` + strings.Join(placeholders, ",") + `)
 		ORDER BY created_at DESC
 		LIMIT ?
 	`
 
-	rows, err := d.db.Query(query, args...)
+	rows, err := d.mem.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -627,15 +1166,16 @@ func (d *Database) SearchVectorsByCategories(categories []string, limit int) ([]
 // 利用已有的分类历史，找出该扩展名常被归入的分类
 //
 // 参数:
+//   - space: 查询向量所属的空间，只返回可以比较的向量
 //   - ext: 文件扩展名
 //   - limit: 返回结果的最大数量
 //
 // 返回值:
 //   - 向量记录切片
 //   - error: 如果查询失败，返回错误
-func (d *Database) SearchVectorsByExtension(ext string, limit int) ([]VectorRecord, error) {
+func (d *Database) SearchVectorsByExtension(space VectorSpace, ext string, limit int) ([]VectorRecord, error) {
 	// 先查找该扩展名常见的分类
-	rows, err := d.db.Query(`
+	rows, err := d.mem.Query(`
 		SELECT DISTINCT category
 		FROM classification_history
 		WHERE extension = ? AND user_confirmed = 1
@@ -643,7 +1183,7 @@ func (d *Database) SearchVectorsByExtension(ext string, limit int) ([]VectorReco
 		LIMIT 5
 	`, ext)
 	if err != nil {
-		return d.SearchVectors(limit) // 失败时回退到普通搜索
+		return d.SearchVectors(space, limit) // 失败时回退到普通搜索
 	}
 	defer rows.Close()
 
@@ -656,10 +1196,10 @@ func (d *Database) SearchVectorsByExtension(ext string, limit int) ([]VectorReco
 	}
 
 	if len(categories) == 0 {
-		return d.SearchVectors(limit)
+		return d.SearchVectors(space, limit)
 	}
 
-	return d.SearchVectorsByCategories(categories, limit)
+	return d.SearchVectorsByCategories(space, categories, limit)
 }
 
 // GetCandidateCategories 获取候选分类列表
@@ -680,7 +1220,7 @@ func (d *Database) GetCandidateCategories(keywords []string, ext string) []strin
 		if len(kw) < 2 {
 			continue
 		}
-		rows, _ := d.db.Query(`
+		rows, _ := d.mem.Query(`
 			SELECT category, hit_count
 			FROM learned_rules
 			WHERE pattern = ? OR pattern LIKE ?
@@ -701,7 +1241,7 @@ func (d *Database) GetCandidateCategories(keywords []string, ext string) []strin
 
 	// 2. 从扩展名规则获取分类
 	if ext != "" {
-		rows, _ := d.db.Query(`
+		rows, _ := d.mem.Query(`
 			SELECT category, hit_count
 			FROM learned_rules
 			WHERE pattern_type = 'extension' AND pattern = ?
@@ -725,7 +1265,7 @@ func (d *Database) GetCandidateCategories(keywords []string, ext string) []strin
 		if len(kw) < 2 {
 			continue
 		}
-		rows, _ := d.db.Query(`
+		rows, _ := d.mem.Query(`
 			SELECT category, COUNT(*) as cnt
 			FROM classification_history
 			WHERE user_confirmed = 1 AND LOWER(filename) LIKE ?
@@ -808,10 +1348,10 @@ func (d *Database) scanVectorRows(rows *sql.Rows) ([]VectorRecord, error) {
 // 返回值:
 //   - error: 如果保存失败，返回错误
 func (d *Database) AddFeedback(filename, origCat, corrCat, origSub, corrSub string) error {
-	_, err := d.db.Exec(`
-		INSERT INTO user_feedback (filename, original_category, corrected_category, original_subcategory, corrected_subcategory)
-		VALUES (?, ?, ?, ?, ?)
-	`, filename, origCat, corrCat, origSub, corrSub)
+	_, err := d.mem.Exec(`
+		INSERT INTO user_feedback (filename, original_category, corrected_category, original_subcategory, corrected_subcategory, user_name)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, filename, origCat, corrCat, origSub, corrSub, d.user)
 	return err
 }
 
@@ -833,36 +1373,41 @@ func (d *Database) GetStatistics() (map[string]interface{}, error) {
 	// ===== 1. 总记录数 =====
 	// 统计 classification_history 表中的记录总数
 	var total int
-	d.db.QueryRow("SELECT COUNT(*) FROM classification_history").Scan(&total)
+	d.mem.QueryRow("SELECT COUNT(*) FROM classification_history").Scan(&total)
 	stats["total_records"] = total
 
 	// ===== 2. 已确认记录数 =====
 	// 统计经用户确认的记录数量
 	var confirmed int
-	d.db.QueryRow("SELECT COUNT(*) FROM classification_history WHERE user_confirmed = 1").Scan(&confirmed)
+	d.mem.QueryRow("SELECT COUNT(*) FROM classification_history WHERE user_confirmed = 1").Scan(&confirmed)
 	stats["confirmed_records"] = confirmed
 
 	// ===== 3. 有效规则数 =====
 	// 统计至少被命中一次的学习规则数量
 	var rules int
-	d.db.QueryRow("SELECT COUNT(*) FROM learned_rules WHERE hit_count > 0").Scan(&rules)
+	d.mem.QueryRow("SELECT COUNT(*) FROM learned_rules WHERE hit_count > 0").Scan(&rules)
 	stats["learned_rules"] = rules
 
+	// 尚未达到样本数的候选规则
+	var candidates int
+	d.mem.QueryRow("SELECT COUNT(*) FROM rule_candidates").Scan(&candidates)
+	stats["rule_candidates"] = candidates
+
 	// ===== 4. 向量数 =====
 	// 统计存储的向量嵌入数量
 	var vectors int
-	d.db.QueryRow("SELECT COUNT(*) FROM vectors").Scan(&vectors)
+	d.mem.QueryRow("SELECT COUNT(*) FROM vectors").Scan(&vectors)
 	stats["vector_count"] = vectors
 
 	// ===== 5. 反馈数 =====
 	// 统计用户反馈记录数量
 	var feedback int
-	d.db.QueryRow("SELECT COUNT(*) FROM user_feedback").Scan(&feedback)
+	d.mem.QueryRow("SELECT COUNT(*) FROM user_feedback").Scan(&feedback)
 	stats["feedback_count"] = feedback
 
 	// ===== 6. 分类分布 =====
 	// 统计各分类的记录数量，返回 Top 10
-	rows, _ := d.db.Query(`
+	rows, _ := d.mem.Query(`
 		SELECT category, COUNT(*) as cnt 
 		FROM classification_history 
 		GROUP BY category 
@@ -896,19 +1441,22 @@ func (d *Database) GetStatistics() (map[string]interface{}, error) {
 // 返回值:
 //   - error: 如果删除失败，返回错误
 func (d *Database) ResetHistory() error {
-	_, err := d.db.Exec("DELETE FROM classification_history")
+	_, err := d.mem.Exec("DELETE FROM classification_history")
 	return err
 }
 
 // ResetRules 重置学习规则
-// 清空 learned_rules 表中的所有数据
+// 清空 learned_rules 和 rule_candidates 表中的所有数据
 // 这将导致系统失去所有学习到的分类规则
 // 警告：此操作不可恢复，请谨慎使用
 //
 // 返回值:
 //   - error: 如果删除失败，返回错误
 func (d *Database) ResetRules() error {
-	_, err := d.db.Exec("DELETE FROM learned_rules")
+	if _, err := d.mem.Exec("DELETE FROM learned_rules"); err != nil {
+		return err
+	}
+	_, err := d.mem.Exec("DELETE FROM rule_candidates")
 	return err
 }
 
@@ -920,7 +1468,7 @@ func (d *Database) ResetRules() error {
 // 返回值:
 //   - error: 如果删除失败，返回错误
 func (d *Database) ResetVectors() error {
-	_, err := d.db.Exec("DELETE FROM vectors")
+	_, err := d.mem.Exec("DELETE FROM vectors")
 	return err
 }
 
@@ -928,9 +1476,14 @@ func (d *Database) ResetVectors() error {
 // 清空所有数据表：
 // - classification_history（分类历史）
 // - learned_rules（学习规则）
+// - rule_candidates（候选规则）
 // - user_feedback（用户反馈）
 // - vectors（向量数据）
 // - operation_logs（操作日志）
+// - review_queue（待确认队列）
+// - plan_snapshots（计划快照）
+// - run_stats（运行摘要）
+// - quarantine_log（隔离记录）
 //
 // 这将使系统恢复到初始状态，失去所有学习记忆
 // 警告：此操作不可恢复，请谨慎使用
@@ -939,11 +1492,15 @@ func (d *Database) ResetVectors() error {
 //   - error: 如果任何表删除失败，返回错误
 func (d *Database) ResetAll() error {
 	// 需要清空的所有表
-	tables := []string{"classification_history", "learned_rules", "user_feedback", "vectors", "operation_logs"}
+	tables := []string{"classification_history", "learned_rules", "rule_candidates", "user_feedback", "vectors", "operation_logs", "review_queue", "plan_snapshots", "run_stats", "quarantine_log"}
 
-	// 依次清空每个表
+	// 依次清空每个表（学习记录在共享数据库中时清空共享数据库）
 	for _, t := range tables {
-		if _, err := d.db.Exec("DELETE FROM " + t); err != nil {
+		db := d.db
+		if isMemoryTable(t) {
+			db = d.mem
+		}
+		if _, err := db.Exec("DELETE FROM " + t); err != nil {
 			return err
 		}
 	}
@@ -955,15 +1512,21 @@ func (d *Database) ResetAll() error {
 
 // OperationLog 操作日志记录
 type OperationLog struct {
-	ID          int64     // 记录 ID
-	BatchID     string    // 批次 ID（同一次整理操作的唯一标识）
-	SourcePath  string    // 原始路径
-	DestPath    string    // 目标路径
-	Filename    string    // 文件名
-	Category    string    // 分类
-	Subcategory string    // 子分类
-	Status      string    // 状态: success, failed, undone
-	CreatedAt   time.Time // 创建时间
+	ID           int64     // 记录 ID
+	BatchID      string    // 批次 ID（同一次整理操作的唯一标识）
+	SourcePath   string    // 原始路径
+	DestPath     string    // 目标路径
+	Filename     string    // 文件名
+	Category     string    // 分类
+	Subcategory  string    // 子分类
+	Status       string    // 状态: success, failed, skipped（重名跳过）, undone
+	Source       string    // 分类来源: memory, llm, user 等
+	Resolution   string    // 重名处理结果: suffix, timestamp, identical, replaced, skipped（无冲突时为空）
+	ReplacedPath string    // 被替换文件的备份路径（resolution 为 replaced 时），或移入回收站的源文件（identical 且启用软删除时）
+	PostActions  string    // 移动后执行的分类操作及结果，如 "compress=ok; readonly=ok"
+	Error        string    // 移动失败的原因（status 为 failed 时）
+	Transfer     string    // 移动方式: 空为同一文件系统内改名，copy 为跨设备复制、校验后删除源文件
+	CreatedAt    time.Time // 创建时间
 }
 
 // AddOperationLog 添加操作日志
@@ -1002,7 +1565,8 @@ func (d *Database) GetRecentBatches(limit int) ([]map[string]interface{}, error)
 		SELECT batch_id, 
 		       COUNT(*) as file_count, 
 		       MIN(created_at) as created_at,
-		       GROUP_CONCAT(DISTINCT category) as categories
+		       GROUP_CONCAT(DISTINCT category) as categories,
+		       EXISTS(SELECT 1 FROM run_stats r WHERE r.batch_id = operation_logs.batch_id AND r.partial = 1) as partial
 		FROM operation_logs
 		WHERE status = 'success'
 		GROUP BY batch_id
@@ -1018,18 +1582,53 @@ func (d *Database) GetRecentBatches(limit int) ([]map[string]interface{}, error)
 	for rows.Next() {
 		var batchID, createdAt, categories string
 		var fileCount int
-		if rows.Scan(&batchID, &fileCount, &createdAt, &categories) == nil {
+		var partial bool
+		if rows.Scan(&batchID, &fileCount, &createdAt, &categories, &partial) == nil {
 			batches = append(batches, map[string]interface{}{
 				"batch_id":   batchID,
 				"file_count": fileCount,
 				"created_at": createdAt,
 				"categories": categories,
+				"partial":    partial,
 			})
 		}
 	}
 	return batches, nil
 }
 
+// GetBatchesSince 获取在指定时间之后开始、仍有未撤销文件的批次，最近的在前
+// 用于一次撤销多个批次
+//
+// 参数:
+//   - since: 批次第一个文件的整理时间不早于该时间
+//
+// 返回值:
+//   - []string: 批次 ID，按整理时间倒序
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetBatchesSince(since time.Time) ([]string, error) {
+	rows, err := d.db.Query(`
+		SELECT batch_id
+		FROM operation_logs
+		WHERE status = 'success'
+		GROUP BY batch_id
+		HAVING MIN(created_at) >= ?
+		ORDER BY MAX(id) DESC
+	`, since.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batches []string
+	for rows.Next() {
+		var batchID string
+		if rows.Scan(&batchID) == nil {
+			batches = append(batches, batchID)
+		}
+	}
+	return batches, rows.Err()
+}
+
 // GetBatchLogs 获取指定批次的所有操作日志
 //
 // 参数:
@@ -1040,7 +1639,8 @@ func (d *Database) GetRecentBatches(limit int) ([]map[string]interface{}, error)
 //   - error: 如果查询失败，返回错误
 func (d *Database) GetBatchLogs(batchID string) ([]OperationLog, error) {
 	rows, err := d.db.Query(`
-		SELECT id, batch_id, source_path, dest_path, filename, category, subcategory, status, created_at
+		SELECT id, batch_id, source_path, dest_path, filename, category, subcategory, status, COALESCE(source, ''),
+		       COALESCE(resolution, ''), COALESCE(replaced_path, ''), COALESCE(post_actions, ''), COALESCE(transfer, ''), created_at
 		FROM operation_logs
 		WHERE batch_id = ? AND status = 'success'
 		ORDER BY id ASC
@@ -1053,15 +1653,47 @@ func (d *Database) GetBatchLogs(batchID string) ([]OperationLog, error) {
 	var logs []OperationLog
 	for rows.Next() {
 		var log OperationLog
-		var createdAt string
-		if rows.Scan(&log.ID, &log.BatchID, &log.SourcePath, &log.DestPath, &log.Filename, &log.Category, &log.Subcategory, &log.Status, &createdAt) == nil {
-			log.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
+		if rows.Scan(&log.ID, &log.BatchID, &log.SourcePath, &log.DestPath, &log.Filename, &log.Category, &log.Subcategory, &log.Status, &log.Source, &log.Resolution, &log.ReplacedPath, &log.PostActions, &log.Transfer, &log.CreatedAt) == nil {
 			logs = append(logs, log)
 		}
 	}
 	return logs, nil
 }
 
+// UpdateOperationLog 更新操作日志的目标路径和分类
+// 事后纠正分类、文件被移到新文件夹后调用，撤销时从新位置移回
+//
+// 参数:
+//   - id: 操作日志 ID
+//   - destPath: 新的目标路径
+//   - category: 新的主分类
+//   - subcategory: 新的子分类
+//
+// 返回值:
+//   - error: 如果更新失败，返回错误
+func (d *Database) UpdateOperationLog(id int64, destPath, category, subcategory string) error {
+	_, err := d.db.Exec(`
+		UPDATE operation_logs
+		SET dest_path = ?, category = ?, subcategory = ?, source = 'user'
+		WHERE id = ?
+	`, destPath, category, subcategory, id)
+	return err
+}
+
+// SetOperationDest 更新操作日志的目标路径
+// 归档压缩后文件进入压缩包，目标路径改为「压缩包#文件名」
+//
+// 参数:
+//   - id: 操作日志 ID
+//   - destPath: 新的目标路径
+//
+// 返回值:
+//   - error: 如果更新失败，返回错误
+func (d *Database) SetOperationDest(id int64, destPath string) error {
+	_, err := d.db.Exec(`UPDATE operation_logs SET dest_path = ? WHERE id = ?`, destPath, id)
+	return err
+}
+
 // MarkBatchUndone 标记批次为已撤销
 //
 // 参数:
@@ -1078,6 +1710,19 @@ func (d *Database) MarkBatchUndone(batchID string) error {
 	return err
 }
 
+// MarkOperationUndone 标记单条操作为已撤销
+// 只撤销批次中的个别文件时调用，批次中的其他文件仍可撤销
+//
+// 参数:
+//   - id: 操作日志 ID
+//
+// 返回值:
+//   - error: 如果更新失败，返回错误
+func (d *Database) MarkOperationUndone(id int64) error {
+	_, err := d.db.Exec(`UPDATE operation_logs SET status = 'undone' WHERE id = ?`, id)
+	return err
+}
+
 // GetLatestBatch 获取最近一次操作的批次 ID
 //
 // 返回值:
@@ -1094,6 +1739,45 @@ func (d *Database) GetLatestBatch() string {
 	return batchID
 }
 
+// FindOperationLogs 按文件名查找整理过的文件
+// 只返回仍有效（未撤销）的移动记录，最近的在前
+//
+// 参数:
+//   - query: 文件名中包含的文字（不区分大小写）
+//   - limit: 返回结果的最大数量
+//
+// 返回值:
+//   - 操作日志列表
+//   - error: 如果查询失败，返回错误
+func (d *Database) FindOperationLogs(query string, limit int) ([]OperationLog, error) {
+	rows, err := d.db.Query(`
+		SELECT id, batch_id, source_path, dest_path, filename, category, subcategory, status, COALESCE(source, ''),
+		       COALESCE(resolution, ''), COALESCE(replaced_path, ''), COALESCE(post_actions, ''), created_at
+		FROM operation_logs
+		WHERE status = 'success' AND filename LIKE ? ESCAPE '\'
+		ORDER BY id DESC
+		LIMIT ?
+	`, "%"+escapeLike(query)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []OperationLog
+	for rows.Next() {
+		var log OperationLog
+		if rows.Scan(&log.ID, &log.BatchID, &log.SourcePath, &log.DestPath, &log.Filename, &log.Category, &log.Subcategory, &log.Status, &log.Source, &log.Resolution, &log.ReplacedPath, &log.PostActions, &log.CreatedAt) == nil {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // ==================== 模型性能统计 ====================
 // 以下方法用于记录和分析模型执行性能
 
@@ -1109,6 +1793,8 @@ type ModelStats struct {
 	ConfirmedCount   int       // 用户确认数
 	CorrectedCount   int       // 用户纠正数
 	AccuracyRate     float64   // 准确率（确认数/(确认数+纠正数)）
+	BatchSize        int       // 每批文件数（0 表示未记录）
+	Parallel         int       // 同时发送的批次数
 	CreatedAt        time.Time // 创建时间
 }
 
@@ -1123,39 +1809,74 @@ type ModelSummary struct {
 	TotalCorrected   int     // 总纠正数
 	AccuracyRate     float64 // 综合准确率
 	Score            float64 // 综合评分（用于排序推荐）
+	AvgBatchSize     float64 // 平均批大小（未记录批大小的旧数据不计入）
 	LastUsed         string  // 最后使用时间
 }
 
 // AddModelStats 添加模型性能统计记录
-func (d *Database) AddModelStats(modelName, batchID string, fileCount int, totalTimeMs int64, avgConfidence float64) error {
+// batchSize 和 parallel 为本次使用的批大小和同时发送的批次数，供自动调整批大小参考
+func (d *Database) AddModelStats(modelName, batchID string, fileCount int, totalTimeMs int64, avgConfidence float64, batchSize, parallel int) error {
 	avgTimePerFile := float64(0)
 	if fileCount > 0 {
 		avgTimePerFile = float64(totalTimeMs) / float64(fileCount)
 	}
 
 	_, err := d.db.Exec(`
-		INSERT INTO model_stats (model_name, batch_id, file_count, total_time_ms, avg_time_per_file_ms, avg_confidence)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, modelName, batchID, fileCount, totalTimeMs, avgTimePerFile, avgConfidence)
+		INSERT INTO model_stats (model_name, batch_id, file_count, total_time_ms, avg_time_per_file_ms, avg_confidence, batch_size, parallel)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, modelName, batchID, fileCount, totalTimeMs, avgTimePerFile, avgConfidence, batchSize, parallel)
 	return err
 }
 
+// GetModelLatency 获取模型单个请求中每个文件的平均耗时
+// 同时发送多批时墙钟时间被摊薄，按并行批次数还原为单个请求的耗时
+//
+// 参数:
+//   - modelName: 模型名称
+//   - limit: 参考最近的统计记录数
+//   - minFiles: 文件数少于该值的记录不计入（样本太小，耗时受模型加载影响大）
+//
+// 返回值:
+//   - float64: 每个文件的平均耗时（毫秒）
+//   - int: 参考的记录数，为 0 时没有可用数据
+func (d *Database) GetModelLatency(modelName string, limit, minFiles int) (float64, int) {
+	var totalMs, files sql.NullFloat64
+	var samples int
+	err := d.db.QueryRow(`
+		SELECT SUM(total_time_ms * MAX(parallel, 1)), SUM(file_count), COUNT(*)
+		FROM (
+			SELECT total_time_ms, file_count, parallel FROM model_stats
+			WHERE model_name = ? AND file_count >= ? AND total_time_ms > 0
+			ORDER BY id DESC
+			LIMIT ?
+		)
+	`, modelName, minFiles, limit).Scan(&totalMs, &files, &samples)
+	if err != nil || samples == 0 || files.Float64 == 0 {
+		return 0, 0
+	}
+	return totalMs.Float64 / files.Float64, samples
+}
+
 // UpdateModelAccuracy 更新模型准确度统计
-// 当用户确认或纠正分类时调用
+// 当用户确认或纠正分类时调用；事后纠正已确认的分类时 confirmed 为 -1、corrected 为 1
+// 准确率按累计的确认数和纠正数重新计算
 func (d *Database) UpdateModelAccuracy(batchID string, confirmed, corrected int) error {
-	accuracyRate := float64(0)
-	total := confirmed + corrected
-	if total > 0 {
-		accuracyRate = float64(confirmed) / float64(total)
+	_, err := d.db.Exec(`
+		UPDATE model_stats
+		SET confirmed_count = MAX(confirmed_count + ?, 0),
+		    corrected_count = MAX(corrected_count + ?, 0)
+		WHERE batch_id = ?
+	`, confirmed, corrected, batchID)
+	if err != nil {
+		return err
 	}
 
-	_, err := d.db.Exec(`
+	_, err = d.db.Exec(`
 		UPDATE model_stats
-		SET confirmed_count = confirmed_count + ?,
-		    corrected_count = corrected_count + ?,
-		    accuracy_rate = ?
+		SET accuracy_rate = CASE WHEN confirmed_count + corrected_count > 0
+		    THEN CAST(confirmed_count AS REAL) / (confirmed_count + corrected_count) ELSE 0 END
 		WHERE batch_id = ?
-	`, confirmed, corrected, accuracyRate, batchID)
+	`, batchID)
 	return err
 }
 
@@ -1171,7 +1892,8 @@ func (d *Database) GetModelSummaries() ([]ModelSummary, error) {
 			AVG(avg_confidence) as avg_confidence,
 			SUM(confirmed_count) as total_confirmed,
 			SUM(corrected_count) as total_corrected,
-			MAX(created_at) as last_used
+			MAX(created_at) as last_used,
+			COALESCE(AVG(NULLIF(batch_size, 0)), 0) as avg_batch_size
 		FROM model_stats
 		GROUP BY model_name
 		ORDER BY total_files DESC
@@ -1185,7 +1907,7 @@ func (d *Database) GetModelSummaries() ([]ModelSummary, error) {
 	for rows.Next() {
 		var s ModelSummary
 		var totalConfirmed, totalCorrected sql.NullInt64
-		if rows.Scan(&s.ModelName, &s.TotalBatches, &s.TotalFiles, &s.AvgTimePerFileMs, &s.AvgConfidence, &totalConfirmed, &totalCorrected, &s.LastUsed) == nil {
+		if rows.Scan(&s.ModelName, &s.TotalBatches, &s.TotalFiles, &s.AvgTimePerFileMs, &s.AvgConfidence, &totalConfirmed, &totalCorrected, &s.LastUsed, &s.AvgBatchSize) == nil {
 			s.TotalConfirmed = int(totalConfirmed.Int64)
 			s.TotalCorrected = int(totalCorrected.Int64)
 
@@ -1245,7 +1967,7 @@ func (d *Database) GetBestModel() string {
 func (d *Database) GetModelRecentStats(modelName string, limit int) ([]ModelStats, error) {
 	rows, err := d.db.Query(`
 		SELECT id, model_name, batch_id, file_count, total_time_ms, avg_time_per_file_ms, 
-		       avg_confidence, confirmed_count, corrected_count, accuracy_rate, created_at
+		       avg_confidence, confirmed_count, corrected_count, accuracy_rate, batch_size, parallel, created_at
 		FROM model_stats
 		WHERE model_name = ?
 		ORDER BY created_at DESC
@@ -1261,10 +1983,118 @@ func (d *Database) GetModelRecentStats(modelName string, limit int) ([]ModelStat
 		var s ModelStats
 		var createdAt string
 		if rows.Scan(&s.ID, &s.ModelName, &s.BatchID, &s.FileCount, &s.TotalTimeMs, &s.AvgTimePerFileMs,
-			&s.AvgConfidence, &s.ConfirmedCount, &s.CorrectedCount, &s.AccuracyRate, &createdAt) == nil {
+			&s.AvgConfidence, &s.ConfirmedCount, &s.CorrectedCount, &s.AccuracyRate, &s.BatchSize, &s.Parallel, &createdAt) == nil {
 			s.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
 			stats = append(stats, s)
 		}
 	}
 	return stats, nil
 }
+
+// ==================== 待确认队列 ====================
+// 以下方法用于管理低置信度分类的待确认队列
+
+// ReviewItem 待确认队列项
+type ReviewItem struct {
+	ID          int64     // 记录 ID
+	FilePath    string    // 文件当前路径（已移入待确认文件夹或仍在原处）
+	Filename    string    // 文件名
+	TargetDir   string    // 确认后归档的目标根目录
+	Category    string    // 建议的主分类
+	Subcategory string    // 建议的子分类
+	Confidence  float64   // 建议的置信度
+	Reasoning   string    // 分类理由
+	Source      string    // 分类来源
+	BatchID     string    // 入队时的批次 ID
+	Status      string    // 状态: pending, resolved, dismissed
+	CreatedAt   time.Time // 入队时间
+
+	Reason         string  // 入队原因: low_confidence（低置信度）/ conflict（记忆与 AI 的分类矛盾）
+	AltCategory    string  // 来源矛盾时另一个建议的主分类
+	AltSubcategory string  // 来源矛盾时另一个建议的子分类
+	AltConfidence  float64 // 另一个建议的置信度
+	AltSource      string  // 另一个建议的来源
+}
+
+// 待确认项的入队原因
+const (
+	ReviewLowConfidence = "low_confidence" // 置信度低于阈值
+	ReviewConflict      = "conflict"       // 记忆与 AI 的分类矛盾
+)
+
+// AddReviewItem 添加待确认项
+//
+// 参数:
+//   - item: 待确认项（ID、Status、CreatedAt 字段会被忽略）
+//
+// 返回值:
+//   - error: 如果插入失败，返回错误
+func (d *Database) AddReviewItem(item ReviewItem) error {
+	_, err := d.db.Exec(`
+		INSERT INTO review_queue (file_path, filename, target_dir, category, subcategory, confidence, reasoning, source, batch_id,
+			reason, alt_category, alt_subcategory, alt_confidence, alt_source)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, item.FilePath, item.Filename, item.TargetDir, item.Category, item.Subcategory, item.Confidence, item.Reasoning, item.Source, item.BatchID,
+		item.Reason, item.AltCategory, item.AltSubcategory, item.AltConfidence, item.AltSource)
+	return err
+}
+
+// GetPendingReviews 获取待确认的队列项
+// 按入队时间正序排列（先进先出）
+//
+// 参数:
+//   - limit: 返回结果的最大数量
+//
+// 返回值:
+//   - 待确认项列表
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetPendingReviews(limit int) ([]ReviewItem, error) {
+	rows, err := d.db.Query(`
+		SELECT id, file_path, filename, target_dir, category, subcategory, confidence, reasoning, source, batch_id, status, created_at,
+		       COALESCE(reason, ''), COALESCE(alt_category, ''), COALESCE(alt_subcategory, ''), COALESCE(alt_confidence, 0), COALESCE(alt_source, '')
+		FROM review_queue
+		WHERE status = 'pending'
+		ORDER BY id ASC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []ReviewItem
+	for rows.Next() {
+		var r ReviewItem
+		var createdAt string
+		if rows.Scan(&r.ID, &r.FilePath, &r.Filename, &r.TargetDir, &r.Category, &r.Subcategory, &r.Confidence, &r.Reasoning, &r.Source, &r.BatchID, &r.Status, &createdAt,
+			&r.Reason, &r.AltCategory, &r.AltSubcategory, &r.AltConfidence, &r.AltSource) == nil {
+			r.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
+			items = append(items, r)
+		}
+	}
+	return items, nil
+}
+
+// CountPendingReviews 统计待确认项数量
+func (d *Database) CountPendingReviews() int {
+	var count int
+	d.db.QueryRow("SELECT COUNT(*) FROM review_queue WHERE status = 'pending'").Scan(&count)
+	return count
+}
+
+// ResolveReview 更新待确认项状态
+//
+// 参数:
+//   - id: 队列项 ID
+//   - status: 新状态（resolved 已处理，dismissed 已移出队列）
+//
+// 返回值:
+//   - error: 如果更新失败，返回错误
+func (d *Database) ResolveReview(id int64, status string) error {
+	_, err := d.db.Exec(`
+		UPDATE review_queue
+		SET status = ?, resolved_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, status, id)
+	return err
+}
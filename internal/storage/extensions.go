@@ -0,0 +1,162 @@
+// Package storage 数据存储模块
+// extensions.go - 扩展名默认分类表：其他方式都无法分类时按扩展名兜底
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// ExtensionDefault 扩展名的默认分类
+type ExtensionDefault struct {
+	Extension   string // 扩展名（小写，带点，如 .epub）
+	Category    string // 主分类
+	Subcategory string // 子分类
+	Builtin     bool   // 是否为内置项（用户修改后为 false）
+}
+
+// NormalizeExtension 规范化扩展名：转小写并补全开头的点
+func NormalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// SeedExtensionDefaults 表为空时写入内置的扩展名默认分类
+// 只在首次使用时写入，之后用户删除的项不会被重新加入
+//
+// 参数:
+//   - defaults: 内置的默认分类
+//
+// 返回值:
+//   - error: 如果写入失败，返回错误
+func (d *Database) SeedExtensionDefaults(defaults []ExtensionDefault) error {
+	var count int
+	if err := d.db.QueryRow("SELECT COUNT(*) FROM extension_defaults").Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return d.insertExtensionDefaults(defaults)
+}
+
+// ResetExtensionDefaults 清空扩展名默认分类表并重新写入内置项
+//
+// 参数:
+//   - defaults: 内置的默认分类
+//
+// 返回值:
+//   - error: 如果写入失败，返回错误
+func (d *Database) ResetExtensionDefaults(defaults []ExtensionDefault) error {
+	if _, err := d.db.Exec("DELETE FROM extension_defaults"); err != nil {
+		return err
+	}
+	return d.insertExtensionDefaults(defaults)
+}
+
+// insertExtensionDefaults 在一个事务中写入内置项，已存在的扩展名保持不变
+func (d *Database) insertExtensionDefaults(defaults []ExtensionDefault) error {
+	return d.inTx(d.db, `
+		INSERT OR IGNORE INTO extension_defaults (extension, category, subcategory, builtin)
+		VALUES (?, ?, ?, 1)
+	`, func(stmt *sql.Stmt) error {
+		for _, e := range defaults {
+			if _, err := stmt.Exec(NormalizeExtension(e.Extension), e.Category, e.Subcategory); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetExtensionDefault 获取扩展名的默认分类
+//
+// 参数:
+//   - ext: 扩展名（大小写均可，可省略开头的点）
+//
+// 返回值:
+//   - *ExtensionDefault: 默认分类，未设置时为 nil
+func (d *Database) GetExtensionDefault(ext string) *ExtensionDefault {
+	e := ExtensionDefault{Extension: NormalizeExtension(ext)}
+	if e.Extension == "" {
+		return nil
+	}
+	err := d.db.QueryRow(`
+		SELECT category, subcategory, builtin FROM extension_defaults WHERE extension = ?
+	`, e.Extension).Scan(&e.Category, &e.Subcategory, &e.Builtin)
+	if err != nil {
+		return nil
+	}
+	return &e
+}
+
+// GetExtensionDefaults 获取全部扩展名默认分类，按主分类和扩展名排序
+//
+// 返回值:
+//   - []ExtensionDefault: 默认分类列表
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetExtensionDefaults() ([]ExtensionDefault, error) {
+	rows, err := d.db.Query(`
+		SELECT extension, category, subcategory, builtin FROM extension_defaults
+		ORDER BY category, extension
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []ExtensionDefault
+	for rows.Next() {
+		var e ExtensionDefault
+		if rows.Scan(&e.Extension, &e.Category, &e.Subcategory, &e.Builtin) == nil {
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
+
+// SetExtensionDefault 设置扩展名的默认分类，已存在时覆盖
+//
+// 参数:
+//   - ext: 扩展名（大小写均可，可省略开头的点）
+//   - category: 主分类
+//   - subcategory: 子分类
+//
+// 返回值:
+//   - error: 如果写入失败，返回错误
+func (d *Database) SetExtensionDefault(ext, category, subcategory string) error {
+	_, err := d.db.Exec(`
+		INSERT INTO extension_defaults (extension, category, subcategory, builtin)
+		VALUES (?, ?, ?, 0)
+		ON CONFLICT(extension) DO UPDATE SET
+			category = excluded.category,
+			subcategory = excluded.subcategory,
+			builtin = 0,
+			updated_at = CURRENT_TIMESTAMP
+	`, NormalizeExtension(ext), category, subcategory)
+	return err
+}
+
+// DeleteExtensionDefault 删除扩展名的默认分类
+//
+// 参数:
+//   - ext: 扩展名（大小写均可，可省略开头的点）
+//
+// 返回值:
+//   - bool: 扩展名是否存在并被删除
+//   - error: 如果删除失败，返回错误
+func (d *Database) DeleteExtensionDefault(ext string) (bool, error) {
+	result, err := d.db.Exec("DELETE FROM extension_defaults WHERE extension = ?", NormalizeExtension(ext))
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
@@ -0,0 +1,72 @@
+// Package storage 数据存储模块
+// failures.go - 移动失败的文件：操作日志中 status 为 failed 的记录及失败原因，
+// filo retry 据此只重试批次中失败的文件，重试后更新原记录，撤销时与批次中的其他文件一起还原
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+// GetFailedLogs 获取批次中移动失败的操作日志
+//
+// 参数:
+//   - batchID: 批次 ID
+//
+// 返回值:
+//   - []OperationLog: 失败的操作日志，按执行顺序排列
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetFailedLogs(batchID string) ([]OperationLog, error) {
+	rows, err := d.db.Query(`
+		SELECT id, batch_id, source_path, dest_path, filename, category, subcategory, status, COALESCE(source, ''),
+		       COALESCE(error, ''), created_at
+		FROM operation_logs
+		WHERE batch_id = ? AND status = 'failed'
+		ORDER BY id ASC
+	`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []OperationLog
+	for rows.Next() {
+		var log OperationLog
+		if rows.Scan(&log.ID, &log.BatchID, &log.SourcePath, &log.DestPath, &log.Filename, &log.Category, &log.Subcategory, &log.Status, &log.Source, &log.Error, &log.CreatedAt) == nil {
+			logs = append(logs, log)
+		}
+	}
+	return logs, rows.Err()
+}
+
+// GetLatestFailedBatch 获取最近一个仍有移动失败文件的批次
+//
+// 返回值:
+//   - string: 批次 ID，没有时为空
+func (d *Database) GetLatestFailedBatch() string {
+	var batchID string
+	d.db.QueryRow(`
+		SELECT batch_id
+		FROM operation_logs
+		WHERE status = 'failed'
+		ORDER BY id DESC
+		LIMIT 1
+	`).Scan(&batchID)
+	return batchID
+}
+
+// UpdateOperationResult 用重试的结果更新操作日志
+// 重试成功后记录变为 success，之后 filo undo 撤销批次时一起移回原处
+//
+// 参数:
+//   - log: 重试后的操作日志（按 ID 更新状态、目标路径、重名处理结果、分类操作、失败原因和移动方式）
+//
+// 返回值:
+//   - error: 如果更新失败，返回错误
+func (d *Database) UpdateOperationResult(log OperationLog) error {
+	_, err := d.db.Exec(`
+		UPDATE operation_logs
+		SET status = ?, dest_path = ?, resolution = ?, replaced_path = ?, post_actions = ?, error = ?, transfer = ?
+		WHERE id = ?
+	`, log.Status, log.DestPath, log.Resolution, log.ReplacedPath, log.PostActions, log.Error, log.Transfer, log.ID)
+	return err
+}
@@ -0,0 +1,176 @@
+// Package storage 数据存储模块
+// health.go - 数据库健康检查：完整性校验、WAL 检查点（filo doctor 使用）
+// 和数据库空间统计（filo stats --db 使用）
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"filo/internal/config"
+)
+
+// ==================== 完整性 ====================
+
+// IntegrityCheck 执行 PRAGMA integrity_check
+//
+// 返回值:
+//   - []string: 发现的问题，数据库完好时为空
+//   - error: 如果无法执行检查，返回错误
+func (d *Database) IntegrityCheck() ([]string, error) {
+	rows, err := d.db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	return problems, rows.Err()
+}
+
+// ==================== WAL 检查点 ====================
+
+// Checkpoint 将 WAL 文件中的内容写回数据库并截断 WAL 文件
+// 其他进程正在读写数据库时可能无法完全截断
+//
+// 返回值:
+//   - error: 如果执行失败，返回错误
+func (d *Database) Checkpoint() error {
+	_, err := d.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	return err
+}
+
+// StartCheckpointer 启动定时 WAL 检查点
+// SQLite 只在最后一个连接关闭时清理 WAL 文件，filo web、filo mcp 等长时间运行的进程
+// 会让 WAL 持续增长，需要定期写回并截断。Close 时自动停止
+//
+// 参数:
+//   - interval: 检查点间隔，不大于 0 时不启动
+func (d *Database) StartCheckpointer(interval time.Duration) {
+	if interval <= 0 || d.stopCheckpoint != nil {
+		return
+	}
+	stop := make(chan struct{})
+	d.stopCheckpoint = stop
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.Checkpoint()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// ==================== 空间统计 ====================
+
+// DBStats 数据库文件和各表占用空间
+type DBStats struct {
+	Path      string      // 数据库文件路径
+	FileSize  int64       // 数据库文件大小（字节）
+	WALSize   int64       // WAL 文件大小（字节），不存在时为 0
+	PageSize  int64       // 页大小（字节）
+	PageCount int64       // 总页数
+	FreePages int64       // 空闲页数（可通过 VACUUM 回收）
+	Tables    []TableStat // 各表行数和占用空间，按占用空间降序
+	Indexes   []IndexStat // 各索引占用空间，按占用空间降序
+}
+
+// TableStat 单个表的统计
+type TableStat struct {
+	Name  string // 表名
+	Rows  int64  // 行数
+	Bytes int64  // 占用空间（字节），无法统计时为 0
+}
+
+// IndexStat 单个索引的统计
+type IndexStat struct {
+	Name  string // 索引名
+	Table string // 所属表
+	Bytes int64  // 占用空间（字节），无法统计时为 0
+}
+
+// Stats 统计数据库文件大小、WAL 大小以及各表和索引的占用空间
+// 占用空间来自 dbstat 虚拟表，驱动未编译该表时只统计行数
+//
+// 返回值:
+//   - *DBStats: 统计结果
+//   - error: 如果查询表结构失败，返回错误
+func (d *Database) Stats() (*DBStats, error) {
+	path := config.Get().DBPath
+	s := &DBStats{Path: path}
+	if info, err := os.Stat(path); err == nil {
+		s.FileSize = info.Size()
+	}
+	if info, err := os.Stat(path + "-wal"); err == nil {
+		s.WALSize = info.Size()
+	}
+	d.db.QueryRow("PRAGMA page_size").Scan(&s.PageSize)
+	d.db.QueryRow("PRAGMA page_count").Scan(&s.PageCount)
+	d.db.QueryRow("PRAGMA freelist_count").Scan(&s.FreePages)
+
+	// 各表和索引的占用空间
+	sizes := make(map[string]int64)
+	if rows, err := d.db.Query("SELECT name, SUM(pgsize) FROM dbstat GROUP BY name"); err == nil {
+		for rows.Next() {
+			var name string
+			var size int64
+			if rows.Scan(&name, &size) == nil {
+				sizes[name] = size
+			}
+		}
+		rows.Close()
+	}
+
+	rows, err := d.db.Query(`
+		SELECT type, name, tbl_name FROM sqlite_master
+		WHERE type IN ('table', 'index') AND name NOT LIKE 'sqlite_%'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for rows.Next() {
+		var typ, name, table string
+		if rows.Scan(&typ, &name, &table) != nil {
+			continue
+		}
+		if typ == "table" {
+			tables = append(tables, name)
+		} else {
+			s.Indexes = append(s.Indexes, IndexStat{Name: name, Table: table, Bytes: sizes[name]})
+		}
+	}
+	rows.Close()
+
+	// 行数单独查询（查询期间不能占用上面的游标）；虚拟表等无法计数的表跳过
+	for _, name := range tables {
+		var count int64
+		if d.db.QueryRow(`SELECT COUNT(*) FROM "`+name+`"`).Scan(&count) != nil {
+			continue
+		}
+		s.Tables = append(s.Tables, TableStat{Name: name, Rows: count, Bytes: sizes[name]})
+	}
+
+	sort.SliceStable(s.Tables, func(i, j int) bool { return s.Tables[i].Bytes > s.Tables[j].Bytes })
+	sort.SliceStable(s.Indexes, func(i, j int) bool { return s.Indexes[i].Bytes > s.Indexes[j].Bytes })
+	return s, nil
+}
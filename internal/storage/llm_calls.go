@@ -0,0 +1,100 @@
+// Package storage 数据存储模块
+// llm_calls.go - 模型调用记录：每次调用的 token 数、耗时和是否失败，按模型汇总后用于调整模型和批大小
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import "fmt"
+
+// LLMCall 一次模型调用
+type LLMCall struct {
+	BatchID        string // 批次 ID
+	Model          string // 模型名称
+	Kind           string // 调用类型: classify / pick / vision
+	FileCount      int    // 处理的文件数
+	PromptTokens   int    // 提示词 token 数
+	ResponseTokens int    // 输出 token 数
+	LatencyMs      int64  // 耗时（毫秒）
+	Error          string // 失败原因，为空表示成功
+}
+
+// LLMUsage 一个模型的调用汇总
+type LLMUsage struct {
+	Model          string // 模型名称
+	Calls          int    // 调用次数
+	Failures       int    // 失败次数
+	Files          int    // 处理的文件数（含失败的调用）
+	PromptTokens   int64  // 提示词 token 总数
+	ResponseTokens int64  // 输出 token 总数
+	TotalMs        int64  // 累计耗时（毫秒，并行的调用分别计入）
+}
+
+// FailureRate 失败率
+func (u LLMUsage) FailureRate() float64 {
+	if u.Calls == 0 {
+		return 0
+	}
+	return float64(u.Failures) / float64(u.Calls)
+}
+
+// TokensPerFile 每个文件的平均 token 数（提示词 + 输出）
+func (u LLMUsage) TokensPerFile() float64 {
+	if u.Files == 0 {
+		return 0
+	}
+	return float64(u.PromptTokens+u.ResponseTokens) / float64(u.Files)
+}
+
+// AddLLMCall 添加模型调用记录
+//
+// 参数:
+//   - call: 调用记录
+//
+// 返回值:
+//   - error: 如果插入失败，返回错误
+func (d *Database) AddLLMCall(call LLMCall) error {
+	_, err := d.db.Exec(`
+		INSERT INTO llm_calls (batch_id, model, kind, file_count, prompt_tokens, response_tokens, latency_ms, success, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, call.BatchID, call.Model, call.Kind, call.FileCount, call.PromptTokens, call.ResponseTokens, call.LatencyMs, call.Error == "", call.Error)
+	return err
+}
+
+// GetLLMUsage 按模型汇总调用记录，调用次数多的在前
+//
+// 参数:
+//   - days: 统计最近多少天，<= 0 时统计全部
+//
+// 返回值:
+//   - []LLMUsage: 各模型的汇总
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetLLMUsage(days int) ([]LLMUsage, error) {
+	where := ""
+	var args []interface{}
+	if days > 0 {
+		where = "WHERE created_at >= datetime('now', ?)"
+		args = append(args, fmt.Sprintf("-%d days", days))
+	}
+	rows, err := d.db.Query(`
+		SELECT model, COUNT(*), SUM(success = 0), SUM(file_count),
+		       SUM(prompt_tokens), SUM(response_tokens), SUM(latency_ms)
+		FROM llm_calls `+where+`
+		GROUP BY model
+		ORDER BY COUNT(*) DESC, model
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []LLMUsage
+	for rows.Next() {
+		var u LLMUsage
+		if rows.Scan(&u.Model, &u.Calls, &u.Failures, &u.Files, &u.PromptTokens, &u.ResponseTokens, &u.TotalMs) == nil {
+			usage = append(usage, u)
+		}
+	}
+	return usage, rows.Err()
+}
@@ -0,0 +1,233 @@
+// Package storage 数据存储模块
+// patterns.go - 正则和通配符规则的编译与缓存
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 规则模式类型
+const (
+	PatternRegex = "regex" // 正则表达式，匹配完整文件名
+	PatternGlob  = "glob"  // 通配符（* ? [...]），匹配完整文件名
+
+	PatternLanguage = "language" // 文件名的主要语言，可限定扩展名，如 ja 或 ja:.mkv,.mp4
+	PatternOrigin   = "origin"   // 下载来源的域名，同时匹配其子域名，如 github.com
+)
+
+// 编译后的模式缓存（进程内共享）
+// 同一进程会打开多个 Database 实例，缓存放在包级别才能复用
+var (
+	patternCache   = make(map[string]*regexp.Regexp)
+	patternCacheMu sync.Mutex
+)
+
+// IsPatternRule 是否为正则或通配符规则
+func IsPatternRule(patternType string) bool {
+	return patternType == PatternRegex || patternType == PatternGlob
+}
+
+// CompilePattern 编译正则或通配符规则（带缓存）
+// 匹配均不区分大小写；模式无效时返回错误
+func CompilePattern(patternType, pattern string) (*regexp.Regexp, error) {
+	key := patternType + ":" + pattern
+
+	patternCacheMu.Lock()
+	defer patternCacheMu.Unlock()
+	if re, ok := patternCache[key]; ok {
+		return re, nil
+	}
+
+	expr := pattern
+	if patternType == PatternGlob {
+		expr = globToRegexp(pattern)
+	}
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, err
+	}
+	patternCache[key] = re
+	return re, nil
+}
+
+// globToRegexp 将通配符转换为锚定的正则表达式
+// * 匹配任意字符，? 匹配单个字符，[...] 原样保留
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	inClass := false
+	for _, r := range glob {
+		switch {
+		case inClass:
+			if r == ']' {
+				inClass = false
+			}
+			sb.WriteRune(r)
+		case r == '*':
+			sb.WriteString(".*")
+		case r == '?':
+			sb.WriteString(".")
+		case r == '[':
+			inClass = true
+			sb.WriteRune(r)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// LanguagePattern 生成语言规则的模式
+//
+// 参数:
+//   - language: 语言代码（如 ja）
+//   - exts: 限定的扩展名，为空表示不限
+//
+// 返回值:
+//   - string: 规则模式，如 "ja" 或 "ja:.mkv,.mp4"
+func LanguagePattern(language string, exts []string) string {
+	if len(exts) == 0 {
+		return language
+	}
+	normalized := make([]string, 0, len(exts))
+	for _, ext := range exts {
+		if ext = NormalizeExtension(ext); ext != "" {
+			normalized = append(normalized, ext)
+		}
+	}
+	return language + ":" + strings.Join(normalized, ",")
+}
+
+// ParseLanguagePattern 拆分语言规则的模式，返回语言代码和限定的扩展名
+func ParseLanguagePattern(pattern string) (string, []string) {
+	language, exts, found := strings.Cut(pattern, ":")
+	if !found || exts == "" {
+		return language, nil
+	}
+	return language, strings.Split(exts, ",")
+}
+
+// matchLanguageRules 查找匹配文件名语言（及扩展名）的语言规则
+// 语言规则只能手动添加，数量很少，全部取出后在 Go 端匹配
+func (d *Database) matchLanguageRules(language, ext string) []LearnedRule {
+	if language == "" {
+		return nil
+	}
+	rows, err := d.mem.Query(`
+		SELECT id, pattern, pattern_type, category, subcategory, priority, hit_count
+		FROM learned_rules
+		WHERE pattern_type = ? AND disabled = 0
+		ORDER BY priority DESC, hit_count DESC
+	`, PatternLanguage)
+	if err != nil {
+		return nil
+	}
+	rules := d.scanRules(rows)
+	rows.Close()
+
+	ext = strings.ToLower(ext)
+	var matched, fallback []LearnedRule
+	for _, r := range rules {
+		lang, exts := ParseLanguagePattern(r.Pattern)
+		switch {
+		case lang != language:
+		case len(exts) == 0:
+			fallback = append(fallback, r)
+		case containsString(exts, ext):
+			matched = append(matched, r)
+		}
+	}
+	// 限定了扩展名的规则更具体，排在不限扩展名的规则之前
+	return append(matched, fallback...)
+}
+
+// OriginPattern 规范化来源规则的域名
+// 可以直接粘贴网址（https://www.github.com/xxx → github.com），去掉 www. 前缀并转为小写；
+// 无法取出域名时返回空字符串
+func OriginPattern(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+	if i := strings.IndexAny(s, "/:?#"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimPrefix(strings.Trim(s, "."), "www.")
+}
+
+// MatchOriginRule 查找匹配下载来源域名的来源规则
+// 规则的域名与来源相同或为其上级域名时匹配（github.com 匹配 objects.github.com），
+// 多条匹配时域名较长（更具体）者优先，其次优先级高者优先
+//
+// 参数:
+//   - domain: 文件的下载来源域名
+//
+// 返回值:
+//   - *LearnedRule: 匹配的规则，没有匹配时为 nil
+func (d *Database) MatchOriginRule(domain string) *LearnedRule {
+	if domain == "" {
+		return nil
+	}
+	domain = strings.ToLower(domain)
+	rows, err := d.mem.Query(`
+		SELECT id, pattern, pattern_type, category, subcategory, priority, hit_count
+		FROM learned_rules
+		WHERE pattern_type = ? AND disabled = 0 AND (pattern = ? OR ? LIKE '%.' || pattern)
+		ORDER BY length(pattern) DESC, priority DESC, id
+		LIMIT 1
+	`, PatternOrigin, domain, domain)
+	if err != nil {
+		return nil
+	}
+	rules := d.scanRules(rows)
+	rows.Close()
+	if len(rules) == 0 {
+		return nil
+	}
+	return &rules[0]
+}
+
+// containsString 列表中是否包含指定字符串
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPatternRules 查找匹配文件名的正则和通配符规则
+// 规则数量通常很少，全部取出后在 Go 端匹配
+func (d *Database) matchPatternRules(filename string) []LearnedRule {
+	rows, err := d.mem.Query(`
+		SELECT id, pattern, pattern_type, category, subcategory, priority, hit_count
+		FROM learned_rules
+		WHERE pattern_type IN (?, ?) AND disabled = 0
+		ORDER BY priority DESC, hit_count DESC
+	`, PatternRegex, PatternGlob)
+	if err != nil {
+		return nil
+	}
+	rules := d.scanRules(rows)
+	rows.Close()
+
+	var matched []LearnedRule
+	for _, r := range rules {
+		re, err := CompilePattern(r.PatternType, r.Pattern)
+		if err != nil {
+			continue // 忽略无效模式
+		}
+		if re.MatchString(filename) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,102 @@
+// Package storage 数据存储模块
+// pins.go - 目录固定模型：整理指定目录时始终使用的模型
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ModelPin 固定到目录的模型
+type ModelPin struct {
+	Directory string    // 目录的绝对路径
+	Model     string    // 模型名称
+	CreatedAt time.Time // 固定时间
+}
+
+// PinModel 将模型固定到目录，已固定时覆盖
+//
+// 参数:
+//   - dir: 目录的绝对路径
+//   - model: 模型名称
+//
+// 返回值:
+//   - error: 如果写入失败，返回错误
+func (d *Database) PinModel(dir, model string) error {
+	_, err := d.db.Exec(`
+		INSERT INTO model_pins (directory, model) VALUES (?, ?)
+		ON CONFLICT(directory) DO UPDATE SET model = excluded.model, created_at = CURRENT_TIMESTAMP
+	`, filepath.Clean(dir), model)
+	return err
+}
+
+// UnpinModel 取消目录的固定模型
+//
+// 参数:
+//   - dir: 目录的绝对路径
+//
+// 返回值:
+//   - bool: 目录是否固定了模型并被取消
+//   - error: 如果删除失败，返回错误
+func (d *Database) UnpinModel(dir string) (bool, error) {
+	result, err := d.db.Exec("DELETE FROM model_pins WHERE directory = ?", filepath.Clean(dir))
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// GetModelPins 获取全部目录固定模型，按目录排序
+//
+// 返回值:
+//   - []ModelPin: 固定模型列表
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetModelPins() ([]ModelPin, error) {
+	rows, err := d.db.Query("SELECT directory, model, created_at FROM model_pins ORDER BY directory")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pins []ModelPin
+	for rows.Next() {
+		var p ModelPin
+		if rows.Scan(&p.Directory, &p.Model, &p.CreatedAt) == nil {
+			pins = append(pins, p)
+		}
+	}
+	return pins, nil
+}
+
+// GetPinnedModel 获取整理目录时应使用的固定模型
+// 目录本身没有固定模型时使用最近的上级目录的固定模型
+//
+// 参数:
+//   - dir: 目录的绝对路径
+//
+// 返回值:
+//   - *ModelPin: 适用的固定模型，没有时为 nil
+func (d *Database) GetPinnedModel(dir string) *ModelPin {
+	pins, err := d.GetModelPins()
+	if err != nil {
+		return nil
+	}
+	dir = filepath.Clean(dir)
+	var best *ModelPin
+	for i, p := range pins {
+		if dir != p.Directory && !strings.HasPrefix(dir, strings.TrimSuffix(p.Directory, string(os.PathSeparator))+string(os.PathSeparator)) {
+			continue
+		}
+		if best == nil || len(p.Directory) > len(best.Directory) {
+			best = &pins[i]
+		}
+	}
+	return best
+}
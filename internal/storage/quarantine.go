@@ -0,0 +1,66 @@
+// Package storage 数据存储模块
+// quarantine.go - 隔离记录：移入隔离区的文件及其 SHA-256
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import "time"
+
+// QuarantineRecord 一个被隔离的文件
+type QuarantineRecord struct {
+	SHA256     string    // 文件哈希
+	Filename   string    // 文件名
+	Size       int64     // 文件大小（字节）
+	SourcePath string    // 原始路径
+	DestPath   string    // 隔离后的路径
+	Kind       string    // 隔离子分类（安装包/脚本/可执行文件）
+	BatchID    string    // 批次 ID
+	CreatedAt  time.Time // 隔离时间
+}
+
+// AddQuarantineRecord 添加隔离记录
+//
+// 参数:
+//   - r: 隔离记录（CreatedAt 字段会被忽略）
+//
+// 返回值:
+//   - error: 如果插入失败，返回错误
+func (d *Database) AddQuarantineRecord(r QuarantineRecord) error {
+	_, err := d.db.Exec(`
+		INSERT INTO quarantine_log (sha256, filename, size, source_path, dest_path, kind, batch_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, r.SHA256, r.Filename, r.Size, r.SourcePath, r.DestPath, r.Kind, r.BatchID)
+	return err
+}
+
+// GetQuarantineRecords 获取最近的隔离记录，按时间从新到旧排列
+//
+// 参数:
+//   - limit: 返回结果的最大数量
+//
+// 返回值:
+//   - []QuarantineRecord: 隔离记录列表
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetQuarantineRecords(limit int) ([]QuarantineRecord, error) {
+	rows, err := d.db.Query(`
+		SELECT sha256, filename, size, source_path, dest_path, kind, batch_id, created_at
+		FROM quarantine_log
+		ORDER BY id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []QuarantineRecord
+	for rows.Next() {
+		var r QuarantineRecord
+		if rows.Scan(&r.SHA256, &r.Filename, &r.Size, &r.SourcePath, &r.DestPath, &r.Kind, &r.BatchID, &r.CreatedAt) == nil {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,344 @@
+// Package storage 数据存储模块
+// rqlite.go - 团队共享数据库：通过 rqlite 的 HTTP 接口访问多台机器共用的 SQLite
+//
+// rqlite（https://rqlite.io）是基于 Raft 的分布式 SQLite，SQL 与本地数据库完全相同，
+// 这里实现一个只依赖标准库的 database/sql 驱动，学习记录的读写代码无需区分后端。
+//
+// rqlite 不支持跨请求的事务：驱动中的事务把写入语句缓存起来，提交时作为一个
+// /db/execute?transaction 请求发送，全部成功或全部不生效；事务中不能查询，
+// 写入结果（影响的行数）要到提交后才知道，需要读取中间结果的写入不能放在事务中（见 LearnRules）。
+// 学习记录的写入都是单条语句内的累加或 upsert，多台机器同时写入同一条规则也不会冲突或丢失计数
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// rqliteTimeout 单个请求的超时
+const rqliteTimeout = 30 * time.Second
+
+// init 注册 rqlite 驱动
+func init() {
+	sql.Register("rqlite", rqliteDriver{})
+}
+
+// ==================== 驱动 ====================
+
+// rqliteDriver rqlite 驱动，数据源为节点地址，如 http://nas.local:4001（可带 user:password@ 进行基本认证）
+type rqliteDriver struct{}
+
+// Open 打开连接（HTTP 无状态，只记录地址）
+func (rqliteDriver) Open(dsn string) (driver.Conn, error) {
+	if !strings.HasPrefix(dsn, "http://") && !strings.HasPrefix(dsn, "https://") {
+		return nil, fmt.Errorf("rqlite 地址必须以 http:// 或 https:// 开头: %s", dsn)
+	}
+	return &rqliteConn{
+		url:    strings.TrimRight(dsn, "/"),
+		client: &http.Client{Timeout: rqliteTimeout},
+	}, nil
+}
+
+// rqliteConn rqlite 连接
+type rqliteConn struct {
+	url    string
+	client *http.Client
+	tx     *rqliteTx // 进行中的事务，nil 表示没有
+}
+
+// errRqliteTxQuery 事务中查询：写入语句在提交时才执行，查询看不到它们的结果
+var errRqliteTxQuery = errors.New("rqlite 事务中不能查询")
+
+// errRqliteTxResult 事务中的写入在提交时才执行，之前不知道影响的行数
+var errRqliteTxResult = errors.New("rqlite 事务中的写入提交后才有结果")
+
+// rqliteResult rqlite 返回的单条语句结果
+type rqliteResult struct {
+	Columns      []string        `json:"columns"`
+	Types        []string        `json:"types"`
+	Values       [][]interface{} `json:"values"`
+	LastInsertID int64           `json:"last_insert_id"`
+	RowsAffected int64           `json:"rows_affected"`
+	Error        string          `json:"error"`
+}
+
+// request 发送一条参数化语句，endpoint 为 execute（写入）或 query（查询）
+func (c *rqliteConn) request(ctx context.Context, endpoint, query string, args []driver.NamedValue) (*rqliteResult, error) {
+	results, err := c.send(ctx, endpoint, [][]interface{}{rqliteStatement(query, args)})
+	if err != nil {
+		return nil, err
+	}
+	return &results[0], nil
+}
+
+// rqliteStatement 把语句和参数转换为 rqlite 请求中的一项：[SQL, 参数...]
+func rqliteStatement(query string, args []driver.NamedValue) []interface{} {
+	stmt := []interface{}{query}
+	for _, a := range args {
+		stmt = append(stmt, rqliteParam(a.Value))
+	}
+	return stmt
+}
+
+// send 在一个请求中发送多条语句，endpoint 可以带参数（如 execute?transaction）
+// 任一语句出错时返回该错误
+func (c *rqliteConn) send(ctx context.Context, endpoint string, stmts [][]interface{}) ([]rqliteResult, error) {
+	body, err := json.Marshal(stmts)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/db/"+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("rqlite 错误 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var out struct {
+		Results []rqliteResult `json:"results"`
+		Error   string         `json:"error"`
+	}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber() // 整数保持精度，按列类型转换
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	if out.Error != "" {
+		return nil, errors.New(out.Error)
+	}
+	for _, r := range out.Results {
+		if r.Error != "" {
+			return nil, errors.New(r.Error)
+		}
+	}
+	if len(out.Results) < len(stmts) {
+		return nil, errors.New("rqlite 未返回结果")
+	}
+	return out.Results, nil
+}
+
+// rqliteParam 转换语句参数：BLOB 以文本写入，布尔值写为 0/1，时间按 SQLite 的 CURRENT_TIMESTAMP 格式
+func rqliteParam(v driver.Value) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case time.Time:
+		return v.UTC().Format("2006-01-02 15:04:05")
+	}
+	return v
+}
+
+// ExecContext 执行写入语句，事务中只缓存语句，提交时执行
+func (c *rqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.tx != nil {
+		c.tx.stmts = append(c.tx.stmts, rqliteStatement(query, args))
+		return rqliteTxResult{}, nil
+	}
+	r, err := c.request(ctx, "execute", query, args)
+	if err != nil {
+		return nil, err
+	}
+	return rqliteExecResult{r.LastInsertID, r.RowsAffected}, nil
+}
+
+// QueryContext 执行查询语句
+func (c *rqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.tx != nil {
+		return nil, errRqliteTxQuery
+	}
+	r, err := c.request(ctx, "query", query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rqliteRows{result: r}, nil
+}
+
+// Prepare 预编译语句（只保存 SQL，执行时随参数一起发送）
+func (c *rqliteConn) Prepare(query string) (driver.Stmt, error) {
+	return &rqliteStmt{conn: c, query: query}, nil
+}
+
+// Close 关闭连接
+func (c *rqliteConn) Close() error {
+	return nil
+}
+
+// Begin 开始事务：之后的写入语句缓存到提交时一起发送
+func (c *rqliteConn) Begin() (driver.Tx, error) {
+	if c.tx != nil {
+		return nil, errors.New("rqlite 连接上已有进行中的事务")
+	}
+	c.tx = &rqliteTx{conn: c}
+	return c.tx, nil
+}
+
+// rqliteTx 缓存写入语句的事务
+type rqliteTx struct {
+	conn  *rqliteConn
+	stmts [][]interface{} // 缓存的写入语句
+}
+
+// Commit 把缓存的语句作为一个 rqlite 事务发送，任一语句失败时全部不生效
+func (t *rqliteTx) Commit() error {
+	t.conn.tx = nil
+	if len(t.stmts) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), rqliteTimeout)
+	defer cancel()
+	_, err := t.conn.send(ctx, "execute?transaction", t.stmts)
+	return err
+}
+
+// Rollback 丢弃缓存的语句，没有发送过任何写入
+func (t *rqliteTx) Rollback() error {
+	t.conn.tx = nil
+	return nil
+}
+
+// rqliteTxResult 事务中缓存的写入语句的结果，提交前不可用
+type rqliteTxResult struct{}
+
+func (rqliteTxResult) LastInsertId() (int64, error) { return 0, errRqliteTxResult }
+func (rqliteTxResult) RowsAffected() (int64, error) { return 0, errRqliteTxResult }
+
+// rqliteExecResult 写入语句的结果
+type rqliteExecResult struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r rqliteExecResult) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r rqliteExecResult) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+// ==================== 语句与结果集 ====================
+
+// rqliteStmt 预编译语句
+type rqliteStmt struct {
+	conn  *rqliteConn
+	query string
+}
+
+func (s *rqliteStmt) Close() error  { return nil }
+func (s *rqliteStmt) NumInput() int { return -1 }
+
+// ExecContext 执行写入语句
+func (s *rqliteStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+// QueryContext 执行查询语句
+func (s *rqliteStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+// Exec 执行写入语句（旧接口，database/sql 优先使用 ExecContext）
+func (s *rqliteStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+// Query 执行查询语句（旧接口，database/sql 优先使用 QueryContext）
+func (s *rqliteStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// namedValues 把按位置的参数转换为 NamedValue
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+// rqliteRows 查询结果集
+type rqliteRows struct {
+	result *rqliteResult
+	next   int
+}
+
+func (r *rqliteRows) Columns() []string { return r.result.Columns }
+func (r *rqliteRows) Close() error      { return nil }
+
+// Next 读取下一行，按列的声明类型转换为与本地 SQLite 驱动相同的值
+func (r *rqliteRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.Values) {
+		return io.EOF
+	}
+	row := r.result.Values[r.next]
+	r.next++
+	for i := range dest {
+		var typ string
+		if i < len(r.result.Types) {
+			typ = strings.ToLower(r.result.Types[i])
+		}
+		dest[i] = nil
+		if i < len(row) {
+			dest[i] = rqliteValue(row[i], typ)
+		}
+	}
+	return nil
+}
+
+// rqliteValue 转换 rqlite 返回的值
+// 数字按列类型转为整数或浮点数；BLOB 列为 base64 编码；TIMESTAMP 等列与本地驱动一样解析为时间
+func rqliteValue(v interface{}, typ string) driver.Value {
+	switch v := v.(type) {
+	case json.Number:
+		if !strings.Contains(typ, "real") && !strings.Contains(typ, "float") && !strings.Contains(typ, "double") {
+			if n, err := v.Int64(); err == nil {
+				return n
+			}
+		}
+		f, _ := v.Float64()
+		return f
+	case string:
+		switch {
+		case typ == "blob":
+			if b, err := base64.StdEncoding.DecodeString(v); err == nil {
+				return b
+			}
+		case strings.Contains(typ, "time") || typ == "date":
+			for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04:05.999999999-07:00", time.RFC3339Nano, "2006-01-02"} {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t
+				}
+			}
+		}
+		return v
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return int64(0)
+	}
+	return v
+}
@@ -0,0 +1,202 @@
+// Package storage 数据存储模块
+// rule_feedback.go - 规则的负反馈：撤销整理和纠正分类时，找出把文件分到错误位置的规则并记一次负反馈，
+// 多次出错的学习规则自动降低优先级，再出错则停用；手动添加的规则只记录，由用户在 filo rules --review 中处理
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"strings"
+)
+
+// 规则负反馈的判定参数
+const (
+	RuleDemotePenalty   = 2 // 负反馈达到该次数时降低优先级
+	RuleDisablePenalty  = 4 // 负反馈达到该次数时停用
+	RulePenaltyShare    = 5 // 负反馈至少为命中次数的 1/5 才调整，命中很多的规则偶尔出错不受影响
+	RuleDemotedPriority = 1 // 降级后的优先级，低于所有学习规则（扩展名规则为 5）
+)
+
+// 负反馈的来源
+const (
+	FeedbackUndo       = "undo"       // 整理被撤销，同一批次中同一条规则只记一次
+	FeedbackCorrection = "correction" // 分类被纠正
+)
+
+// RuleFeedback 一个被撤销或纠正的文件
+type RuleFeedback struct {
+	Filename    string // 文件名
+	ParentDir   string // 整理前所在的目录名
+	Language    string // 文件名的主要语言代码（与记忆匹配时相同，为空时跳过语言规则）
+	Category    string // 被撤销或纠正前的主分类
+	Subcategory string // 被撤销或纠正前的子分类
+}
+
+// PenalizedRule 有负反馈的规则
+type PenalizedRule struct {
+	LearnedRule
+	Undos       int  // 被撤销的批次数
+	Corrections int  // 被纠正的文件数
+	Disabled    bool // 是否已停用
+	DemotedFrom int  // 降级前的优先级，未降级时为 0
+}
+
+// Penalty 负反馈的总次数
+func (r PenalizedRule) Penalty() int {
+	return r.Undos + r.Corrections
+}
+
+// Adjustable 是否为自动调整的学习规则（关键词、扩展名、来源目录）
+// 正则、通配符、语言和下载来源规则由用户手动添加，只记录负反馈
+func (r PenalizedRule) Adjustable() bool {
+	return !IsPatternRule(r.PatternType) && r.PatternType != PatternLanguage && r.PatternType != PatternOrigin
+}
+
+// PenalizeRules 为把文件分到错误位置的规则记一次负反馈，达到阈值时降级或停用
+// 每个文件只追究决定了它分类的规则（与记忆匹配时选中的规则相同），且该规则指向的正是被撤销或纠正的分类；
+// 同一次调用中同一条规则只记一次
+//
+// 参数:
+//   - files: 被撤销或纠正的文件
+//   - kind: 负反馈来源（FeedbackUndo 或 FeedbackCorrection）
+//
+// 返回值:
+//   - []PenalizedRule: 本次被降级或停用的规则
+//   - error: 如果更新失败，返回错误
+func (d *Database) PenalizeRules(files []RuleFeedback, kind string) ([]PenalizedRule, error) {
+	ids := make(map[int64]bool)
+	var order []int64
+	for _, f := range files {
+		if r := d.decidingRule(f); r != nil && !ids[r.ID] {
+			ids[r.ID] = true
+			order = append(order, r.ID)
+		}
+	}
+
+	column := "undo_count"
+	if kind == FeedbackCorrection {
+		column = "correction_count"
+	}
+	var changed []PenalizedRule
+	for _, id := range order {
+		if _, err := d.mem.Exec(`UPDATE learned_rules SET `+column+` = `+column+` + 1 WHERE id = ?`, id); err != nil {
+			return changed, err
+		}
+		if r, ok, err := d.adjustRule(id); err != nil {
+			return changed, err
+		} else if ok {
+			changed = append(changed, r)
+		}
+	}
+	return changed, nil
+}
+
+// decidingRule 找出决定文件分类的规则，规则指向的分类与文件的分类不同时返回 nil
+func (d *Database) decidingRule(f RuleFeedback) *LearnedRule {
+	ext := ""
+	if i := strings.LastIndex(f.Filename, "."); i > 0 {
+		ext = f.Filename[i:]
+	}
+	// 关键词规则按整个文件名匹配，传入文件名即可触发关键词查询
+	rules, err := d.GetMatchingRules(f.Filename, []string{f.Filename}, ext, strings.ToLower(f.ParentDir), f.Language)
+	if err != nil || len(rules) == 0 {
+		return nil
+	}
+	r := rules[0]
+	if r.Category != f.Category || r.Subcategory != f.Subcategory {
+		return nil
+	}
+	return &r
+}
+
+// adjustRule 按负反馈次数降级或停用学习规则
+// 返回调整后的规则以及本次是否做了调整
+func (d *Database) adjustRule(id int64) (PenalizedRule, bool, error) {
+	r, err := d.penalizedRule(id)
+	if err != nil {
+		return r, false, err
+	}
+	penalty := r.Penalty()
+	if !r.Adjustable() || r.Disabled || penalty*RulePenaltyShare < r.HitCount {
+		return r, false, nil
+	}
+	switch {
+	case penalty >= RuleDisablePenalty:
+		_, err = d.mem.Exec(`UPDATE learned_rules SET disabled = 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, id)
+		r.Disabled = true
+	case penalty >= RuleDemotePenalty && r.DemotedFrom == 0 && r.Priority > RuleDemotedPriority:
+		_, err = d.mem.Exec(`
+			UPDATE learned_rules SET demoted_from = priority, priority = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+		`, RuleDemotedPriority, id)
+		r.DemotedFrom, r.Priority = r.Priority, RuleDemotedPriority
+	default:
+		return r, false, nil
+	}
+	return r, err == nil, err
+}
+
+// penalizedRule 读取一条规则及其负反馈
+func (d *Database) penalizedRule(id int64) (PenalizedRule, error) {
+	var r PenalizedRule
+	err := d.mem.QueryRow(`
+		SELECT id, pattern, pattern_type, category, subcategory, priority, hit_count,
+		       undo_count, correction_count, disabled, demoted_from
+		FROM learned_rules
+		WHERE id = ?
+	`, id).Scan(&r.ID, &r.Pattern, &r.PatternType, &r.Category, &r.Subcategory, &r.Priority, &r.HitCount,
+		&r.Undos, &r.Corrections, &r.Disabled, &r.DemotedFrom)
+	return r, err
+}
+
+// GetPenalizedRules 获取有负反馈的规则，供 filo rules --review 列出
+//
+// 返回值:
+//   - []PenalizedRule: 有负反馈的规则，已停用和已降级的在前，其余按负反馈次数降序
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetPenalizedRules() ([]PenalizedRule, error) {
+	rows, err := d.mem.Query(`
+		SELECT id, pattern, pattern_type, category, subcategory, priority, hit_count,
+		       undo_count, correction_count, disabled, demoted_from
+		FROM learned_rules
+		WHERE undo_count + correction_count > 0
+		ORDER BY disabled DESC, demoted_from > 0 DESC, undo_count + correction_count DESC, hit_count DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []PenalizedRule
+	for rows.Next() {
+		var r PenalizedRule
+		if rows.Scan(&r.ID, &r.Pattern, &r.PatternType, &r.Category, &r.Subcategory, &r.Priority, &r.HitCount,
+			&r.Undos, &r.Corrections, &r.Disabled, &r.DemotedFrom) == nil {
+			rules = append(rules, r)
+		}
+	}
+	return rules, rows.Err()
+}
+
+// RestoreRule 恢复被降级或停用的规则：清除负反馈，重新启用并恢复原来的优先级
+//
+// 参数:
+//   - id: 规则 ID
+//
+// 返回值:
+//   - bool: 规则是否存在
+//   - error: 如果更新失败，返回错误
+func (d *Database) RestoreRule(id int64) (bool, error) {
+	result, err := d.mem.Exec(`
+		UPDATE learned_rules
+		SET priority = CASE WHEN demoted_from > 0 THEN demoted_from ELSE priority END,
+		    demoted_from = 0, disabled = 0, undo_count = 0, correction_count = 0, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
@@ -0,0 +1,232 @@
+// Package storage 数据存储模块
+// runs.go - 运行摘要：记录每次分类的记忆命中率、平均置信度和纠正数，用于查看学习趋势；
+// 执行整理后补充移动结果、新建的分类和失败原因，供 filo last 在关闭终端后查看
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// MaxRunFailures 运行摘要中最多保存的失败文件数
+const MaxRunFailures = 50
+
+// RunStats 一次分类运行的摘要
+type RunStats struct {
+	BatchID        string         // 批次 ID
+	FileCount      int            // 分类的文件数
+	MemoryHits     int            // 记忆命中数
+	LLMCount       int            // AI 分类数
+	AvgConfidence  float64        // 平均置信度
+	CorrectedCount int            // 用户纠正数（审查、编辑计划和事后纠正）
+	Partial        bool           // 是否被中断（只分类或整理了部分文件）
+	Model          string         // 使用的模型（离线和仅规则模式为空）
+	Sources        map[string]int // 各分类来源的文件数（memory、rule、llm、extension 等）
+	Duration       time.Duration  // 分类与执行的总耗时
+	CreatedAt      time.Time      // 运行时间
+
+	Executed bool // 是否执行了整理（预览、模拟、审计等只分类的运行为 false）
+	RunOutcome
+}
+
+// RunOutcome 一次整理的执行结果
+type RunOutcome struct {
+	SourceDir         string        // 整理的目录
+	TargetDir         string        // 目标目录
+	Moved             int           // 成功移动的文件数
+	Failed            int           // 移动失败的文件数
+	Skipped           int           // 跳过、留在原处的文件数
+	Review            int           // 进入待确认队列的文件数
+	CategoriesCreated []string      // 本次新建的分类文件夹
+	Failures          []string      // 失败的文件及原因（最多 MaxRunFailures 个）
+	Aborted           string        // 执行前检查未通过或原子执行回滚的原因
+	ExecTime          time.Duration // 执行耗时
+}
+
+// MemoryHitRate 记忆命中率
+func (r RunStats) MemoryHitRate() float64 {
+	if r.FileCount == 0 {
+		return 0
+	}
+	return float64(r.MemoryHits) / float64(r.FileCount)
+}
+
+// CorrectionRate 纠正率
+func (r RunStats) CorrectionRate() float64 {
+	if r.FileCount == 0 {
+		return 0
+	}
+	return float64(r.CorrectedCount) / float64(r.FileCount)
+}
+
+// AddRunStats 添加运行摘要
+//
+// 参数:
+//   - run: 运行摘要（CorrectedCount、CreatedAt 和执行结果会被忽略）
+//
+// 返回值:
+//   - error: 如果插入失败，返回错误
+func (d *Database) AddRunStats(run RunStats) error {
+	sources, _ := json.Marshal(run.Sources)
+	_, err := d.db.Exec(`
+		INSERT INTO run_stats (batch_id, file_count, memory_hits, llm_count, avg_confidence, partial, model, sources, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.BatchID, run.FileCount, run.MemoryHits, run.LLMCount, run.AvgConfidence, run.Partial,
+		run.Model, string(sources), run.Duration.Milliseconds())
+	return err
+}
+
+// SaveRunOutcome 为批次的运行摘要记录执行结果，执行耗时累加到总耗时
+//
+// 参数:
+//   - batchID: 批次 ID
+//   - outcome: 执行结果，失败文件超过 MaxRunFailures 个时只保存前面的部分
+//
+// 返回值:
+//   - error: 如果更新失败，返回错误
+func (d *Database) SaveRunOutcome(batchID string, outcome RunOutcome) error {
+	if len(outcome.Failures) > MaxRunFailures {
+		outcome.Failures = outcome.Failures[:MaxRunFailures]
+	}
+	created, _ := json.Marshal(outcome.CategoriesCreated)
+	failures, _ := json.Marshal(outcome.Failures)
+	_, err := d.db.Exec(`
+		UPDATE run_stats SET executed = 1, source_dir = ?, target_dir = ?, moved = ?, failed = ?, skipped = ?, review = ?,
+			categories_created = ?, failures = ?, aborted = ?, duration_ms = duration_ms + ?
+		WHERE batch_id = ?
+	`, outcome.SourceDir, outcome.TargetDir, outcome.Moved, outcome.Failed, outcome.Skipped, outcome.Review,
+		string(created), string(failures), outcome.Aborted, outcome.ExecTime.Milliseconds(), batchID)
+	return err
+}
+
+// GetLatestRun 获取最近一次执行了整理的运行摘要
+//
+// 返回值:
+//   - *RunStats: 运行摘要，没有记录时为 nil
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetLatestRun() (*RunStats, error) {
+	return d.queryRun(`WHERE executed = 1 ORDER BY id DESC LIMIT 1`)
+}
+
+// GetRun 获取批次的运行摘要
+//
+// 参数:
+//   - batchID: 批次 ID
+//
+// 返回值:
+//   - *RunStats: 运行摘要，没有记录或未执行整理时为 nil
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetRun(batchID string) (*RunStats, error) {
+	return d.queryRun(`WHERE executed = 1 AND batch_id = ?`, batchID)
+}
+
+// queryRun 按条件查询一条执行了整理的运行摘要，没有记录时返回 nil
+func (d *Database) queryRun(where string, args ...interface{}) (*RunStats, error) {
+	var r RunStats
+	var sources, created, failures string
+	var durationMs int64
+	err := d.db.QueryRow(`
+		SELECT batch_id, file_count, memory_hits, llm_count, avg_confidence, corrected_count, partial, created_at,
+		       model, sources, duration_ms, source_dir, target_dir, moved, failed, skipped, review,
+		       categories_created, failures, aborted
+		FROM run_stats
+	`+where, args...).Scan(&r.BatchID, &r.FileCount, &r.MemoryHits, &r.LLMCount, &r.AvgConfidence, &r.CorrectedCount, &r.Partial, &r.CreatedAt,
+		&r.Model, &sources, &durationMs, &r.SourceDir, &r.TargetDir, &r.Moved, &r.Failed, &r.Skipped, &r.Review,
+		&created, &failures, &r.Aborted)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.Executed = true
+	r.Duration = time.Duration(durationMs) * time.Millisecond
+	json.Unmarshal([]byte(sources), &r.Sources)
+	json.Unmarshal([]byte(created), &r.CategoriesCreated)
+	json.Unmarshal([]byte(failures), &r.Failures)
+	return &r, nil
+}
+
+// RecordRetry 把重试的结果计入批次的运行摘要
+// 重试成功的文件计为已移动，因重名跳过的计为跳过，失败原因换成仍未移动的文件
+//
+// 参数:
+//   - batchID: 批次 ID
+//   - moved: 重试成功的文件数
+//   - skipped: 重试时因重名跳过的文件数
+//   - failures: 仍然失败的文件及原因，超过 MaxRunFailures 个时只保存前面的部分
+//
+// 返回值:
+//   - error: 如果更新失败，返回错误
+func (d *Database) RecordRetry(batchID string, moved, skipped int, failures []string) error {
+	if len(failures) > MaxRunFailures {
+		failures = failures[:MaxRunFailures]
+	}
+	encoded, _ := json.Marshal(failures)
+	_, err := d.db.Exec(`
+		UPDATE run_stats SET moved = moved + ?, skipped = skipped + ?, failed = MAX(failed - ?, 0), failures = ?
+		WHERE batch_id = ?
+	`, moved, skipped, moved+skipped, string(encoded), batchID)
+	return err
+}
+
+// MarkRunPartial 将批次标记为被中断（只整理了部分文件）
+//
+// 参数:
+//   - batchID: 批次 ID
+//
+// 返回值:
+//   - error: 如果更新失败，返回错误
+func (d *Database) MarkRunPartial(batchID string) error {
+	_, err := d.db.Exec(`UPDATE run_stats SET partial = 1 WHERE batch_id = ?`, batchID)
+	return err
+}
+
+// AddRunCorrection 为批次的运行摘要累加一次纠正
+//
+// 参数:
+//   - batchID: 批次 ID
+//
+// 返回值:
+//   - error: 如果更新失败，返回错误
+func (d *Database) AddRunCorrection(batchID string) error {
+	_, err := d.db.Exec(`
+		UPDATE run_stats SET corrected_count = corrected_count + 1
+		WHERE batch_id = ?
+	`, batchID)
+	return err
+}
+
+// GetRecentRuns 获取最近的运行摘要，按时间从早到晚排列
+//
+// 参数:
+//   - limit: 返回结果的最大数量
+//
+// 返回值:
+//   - []RunStats: 运行摘要列表
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetRecentRuns(limit int) ([]RunStats, error) {
+	rows, err := d.db.Query(`
+		SELECT batch_id, file_count, memory_hits, llm_count, avg_confidence, corrected_count, partial, created_at
+		FROM (SELECT * FROM run_stats ORDER BY id DESC LIMIT ?)
+		ORDER BY id ASC
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []RunStats
+	for rows.Next() {
+		var r RunStats
+		if rows.Scan(&r.BatchID, &r.FileCount, &r.MemoryHits, &r.LLMCount, &r.AvgConfidence, &r.CorrectedCount, &r.Partial, &r.CreatedAt) == nil {
+			runs = append(runs, r)
+		}
+	}
+	return runs, nil
+}
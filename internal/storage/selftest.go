@@ -0,0 +1,82 @@
+// Package storage 数据存储模块
+// selftest.go - 准确率自测记录：每次 filo selftest 的模型和准确率，供与之前的结果对比
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SelftestRun 一次准确率自测的结果
+type SelftestRun struct {
+	ID              int64          // 记录 ID
+	Model           string         // 使用的模型
+	Rules           bool           // 是否使用了规则
+	Note            string         // 备注（如修改了哪些提示词或分类体系）
+	Samples         int            // 样本数
+	CategoryCorrect int            // 主分类正确的样本数
+	PathCorrect     int            // 主分类和子分类都正确的样本数
+	DurationMs      int64          // 模型分类耗时（毫秒）
+	PerCategory     map[string]int // 各主分类中主分类正确的样本数
+	CreatedAt       time.Time      // 自测时间
+}
+
+// Rate 主分类准确率（0-1）
+func (r SelftestRun) Rate() float64 {
+	if r.Samples == 0 {
+		return 0
+	}
+	return float64(r.CategoryCorrect) / float64(r.Samples)
+}
+
+// AddSelftestRun 记录一次准确率自测
+//
+// 参数:
+//   - run: 自测结果（ID 和 CreatedAt 忽略）
+//
+// 返回值:
+//   - error: 如果写入失败，返回错误
+func (d *Database) AddSelftestRun(run SelftestRun) error {
+	perCategory, _ := json.Marshal(run.PerCategory)
+	_, err := d.db.Exec(`
+		INSERT INTO selftest_runs (model, rules, note, samples, category_correct, path_correct, duration_ms, per_category)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.Model, run.Rules, run.Note, run.Samples, run.CategoryCorrect, run.PathCorrect, run.DurationMs, string(perCategory))
+	return err
+}
+
+// GetSelftestRuns 获取最近的准确率自测记录，最新的在前
+//
+// 参数:
+//   - limit: 最多返回的记录数
+//
+// 返回值:
+//   - []SelftestRun: 自测记录
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetSelftestRuns(limit int) ([]SelftestRun, error) {
+	rows, err := d.db.Query(`
+		SELECT id, model, rules, note, samples, category_correct, path_correct, duration_ms, per_category, created_at
+		FROM selftest_runs ORDER BY id DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []SelftestRun
+	for rows.Next() {
+		var r SelftestRun
+		var perCategory string
+		if rows.Scan(&r.ID, &r.Model, &r.Rules, &r.Note, &r.Samples, &r.CategoryCorrect, &r.PathCorrect,
+			&r.DurationMs, &perCategory, &r.CreatedAt) != nil {
+			continue
+		}
+		json.Unmarshal([]byte(perCategory), &r.PerCategory)
+		runs = append(runs, r)
+	}
+	return runs, nil
+}
@@ -0,0 +1,84 @@
+// Package storage 数据存储模块
+// shared.go - 团队共享的学习记录：分类历史、规则、向量和纠正记录放在共享数据库中，
+// 同一个小团队的多台机器共用学到的整理习惯；操作日志、待确认队列、运行统计等只与本机文件有关，仍在本机
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import "errors"
+
+// ErrSharedDB 配置了 shared_db 但无法连接共享数据库
+var ErrSharedDB = errors.New("无法连接共享数据库")
+
+// memoryTables 学习记录所在的表，配置 shared_db 时读写共享数据库
+var memoryTables = map[string]bool{
+	"classification_history": true,
+	"learned_rules":          true,
+	"rule_candidates":        true,
+	"vectors":                true,
+	"user_feedback":          true,
+}
+
+// isMemoryTable 表是否属于学习记录
+func isMemoryTable(table string) bool {
+	return memoryTables[table]
+}
+
+// Shared 学习记录是否在团队共享的数据库中
+func (d *Database) Shared() bool {
+	return d.mem != d.db
+}
+
+// User 写入分类历史和纠正记录的用户名
+func (d *Database) User() string {
+	return d.user
+}
+
+// PingShared 检查共享数据库能否访问，未配置共享数据库时直接返回 nil
+func (d *Database) PingShared() error {
+	if !d.Shared() {
+		return nil
+	}
+	var n int
+	return d.mem.QueryRow("SELECT COUNT(*) FROM learned_rules").Scan(&n)
+}
+
+// Contributor 一位用户对共享学习记录的贡献
+type Contributor struct {
+	User        string // 用户名，升级前的记录为空
+	Confirmed   int    // 确认的分类数
+	Corrections int    // 纠正的分类数
+}
+
+// GetContributors 按用户统计确认和纠正的分类数，供 filo stats 在共享数据库中显示
+//
+// 返回值:
+//   - []Contributor: 各用户的贡献，按确认和纠正总数降序
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetContributors() ([]Contributor, error) {
+	rows, err := d.mem.Query(`
+		SELECT user_name, SUM(confirmed), SUM(corrections) FROM (
+			SELECT COALESCE(user_name, '') AS user_name, 1 AS confirmed, 0 AS corrections
+			FROM classification_history WHERE user_confirmed = 1 AND source != 'user'
+			UNION ALL
+			SELECT COALESCE(user_name, ''), 0, 1 FROM user_feedback
+		)
+		GROUP BY user_name
+		ORDER BY SUM(confirmed) + SUM(corrections) DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contributors []Contributor
+	for rows.Next() {
+		var c Contributor
+		if rows.Scan(&c.User, &c.Confirmed, &c.Corrections) == nil {
+			contributors = append(contributors, c)
+		}
+	}
+	return contributors, rows.Err()
+}
@@ -0,0 +1,170 @@
+// Package storage 数据存储模块
+// snapshots.go - 整理计划快照，用于对比两次预览的差异
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxSnapshotsPerDir 每个目录保留的计划快照数
+const MaxSnapshotsPerDir = 5
+
+// PlanEntry 计划中单个文件的分类
+type PlanEntry struct {
+	Path        string  // 相对源目录的路径
+	Category    string  // 主分类
+	Subcategory string  // 子分类
+	Confidence  float64 // 置信度
+	Source      string  // 分类来源
+}
+
+// PlanSnapshot 一次整理计划（或已执行批次）的快照
+type PlanSnapshot struct {
+	ID        string      // 快照 ID（来自批次时为批次 ID）
+	SourceDir string      // 源目录绝对路径
+	FromBatch bool        // 是否来自已执行的批次
+	Entries   []PlanEntry // 文件分类列表
+}
+
+// SavePlanSnapshot 保存计划快照
+// 同一目录只保留最近 MaxSnapshotsPerDir 份快照
+//
+// 参数:
+//   - sourceDir: 源目录绝对路径
+//   - entries: 文件分类列表
+//
+// 返回值:
+//   - string: 快照 ID
+//   - error: 如果保存失败，返回错误
+func (d *Database) SavePlanSnapshot(sourceDir string, entries []PlanEntry) (string, error) {
+	snapshotID := time.Now().Format("20060102_150405")
+
+	err := d.inTx(d.db, `
+		INSERT INTO plan_snapshots (snapshot_id, source_dir, rel_path, category, subcategory, confidence, source)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, func(stmt *sql.Stmt) error {
+		for _, e := range entries {
+			if _, err := stmt.Exec(snapshotID, sourceDir, e.Path, e.Category, e.Subcategory, e.Confidence, e.Source); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// 清理旧快照
+	d.db.Exec(`
+		DELETE FROM plan_snapshots
+		WHERE source_dir = ? AND snapshot_id NOT IN (
+			SELECT snapshot_id FROM plan_snapshots
+			WHERE source_dir = ?
+			GROUP BY snapshot_id
+			ORDER BY MAX(id) DESC
+			LIMIT ?
+		)
+	`, sourceDir, sourceDir, MaxSnapshotsPerDir)
+
+	return snapshotID, nil
+}
+
+// GetLatestPlanSnapshot 获取目录最近一次的计划快照
+//
+// 参数:
+//   - sourceDir: 源目录绝对路径
+//
+// 返回值:
+//   - *PlanSnapshot: 快照，不存在时为 nil
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetLatestPlanSnapshot(sourceDir string) (*PlanSnapshot, error) {
+	var snapshotID string
+	err := d.db.QueryRow(`
+		SELECT snapshot_id FROM plan_snapshots
+		WHERE source_dir = ?
+		ORDER BY id DESC
+		LIMIT 1
+	`, sourceDir).Scan(&snapshotID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.Query(`
+		SELECT rel_path, category, subcategory, confidence, source
+		FROM plan_snapshots
+		WHERE source_dir = ? AND snapshot_id = ?
+	`, sourceDir, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snap := &PlanSnapshot{ID: snapshotID, SourceDir: sourceDir}
+	for rows.Next() {
+		var e PlanEntry
+		if rows.Scan(&e.Path, &e.Category, &e.Subcategory, &e.Confidence, &e.Source) == nil {
+			snap.Entries = append(snap.Entries, e)
+		}
+	}
+	return snap, nil
+}
+
+// GetLatestBatchSnapshot 获取从目录中移出文件的最近一个批次
+// 以文件的原始路径还原为快照，用于没有预览快照时的对比
+//
+// 参数:
+//   - sourceDir: 源目录绝对路径
+//
+// 返回值:
+//   - *PlanSnapshot: 快照，不存在时为 nil
+//   - error: 如果查询失败，返回错误
+func (d *Database) GetLatestBatchSnapshot(sourceDir string) (*PlanSnapshot, error) {
+	prefix := strings.TrimSuffix(sourceDir, string(filepath.Separator)) + string(filepath.Separator)
+	n := utf8.RuneCountInString(prefix) // SQLite 的 substr 按字符计数
+
+	var batchID string
+	err := d.db.QueryRow(`
+		SELECT batch_id FROM operation_logs
+		WHERE substr(source_path, 1, ?) = ? AND status != 'failed'
+		ORDER BY id DESC
+		LIMIT 1
+	`, n, prefix).Scan(&batchID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.Query(`
+		SELECT source_path, category, subcategory
+		FROM operation_logs
+		WHERE batch_id = ? AND substr(source_path, 1, ?) = ? AND status != 'failed'
+	`, batchID, n, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snap := &PlanSnapshot{ID: batchID, SourceDir: sourceDir, FromBatch: true}
+	for rows.Next() {
+		var path string
+		var e PlanEntry
+		if rows.Scan(&path, &e.Category, &e.Subcategory) == nil {
+			e.Path = strings.TrimPrefix(path, prefix)
+			snap.Entries = append(snap.Entries, e)
+		}
+	}
+	return snap, nil
+}
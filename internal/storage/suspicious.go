@@ -0,0 +1,83 @@
+// Package storage 数据存储模块
+// suspicious.go - 过于宽泛的关键词规则：停用词、在已确认文件中分散到多个分类的词，
+// 以及同一个词指向多个分类的规则，供 filo rules --suspicious 列出后由用户删除
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 宽泛规则的判定参数
+const (
+	SuspiciousMinRecords = 10  // 包含关键词的已确认文件至少有这么多时才统计分布
+	SuspiciousMaxShare   = 0.5 // 其中归入规则分类的比例低于该值视为过于宽泛
+)
+
+// SuspiciousRule 过于宽泛的规则及原因
+type SuspiciousRule struct {
+	LearnedRule
+	Reasons []string // 判定为宽泛的原因
+}
+
+// SuspiciousRules 找出过于宽泛的关键词规则
+// 满足任一条件即列出：关键词是停用词；包含该词的已确认文件至少 SuspiciousMinRecords 个，
+// 归入规则分类的比例低于 SuspiciousMaxShare；同一个关键词有多条规则指向不同分类
+//
+// 参数:
+//   - stopWords: 停用词集合（小写）
+//
+// 返回值:
+//   - []SuspiciousRule: 宽泛的规则，按命中次数降序
+//   - error: 如果查询失败，返回错误
+func (d *Database) SuspiciousRules(stopWords map[string]bool) ([]SuspiciousRule, error) {
+	rules, err := d.GetRules("keyword")
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]int) // 关键词 -> 指向的分类数
+	for _, r := range rules {
+		targets[r.Pattern]++
+	}
+
+	var result []SuspiciousRule
+	for _, r := range rules {
+		var reasons []string
+		if stopWords[strings.ToLower(r.Pattern)] {
+			reasons = append(reasons, "停用词，已不参与匹配")
+		}
+		if n := targets[r.Pattern]; n > 1 {
+			reasons = append(reasons, fmt.Sprintf("同一关键词有 %d 条规则指向不同分类", n))
+		}
+		total, same, err := d.keywordSpread(r.Pattern, r.Category)
+		if err != nil {
+			return nil, err
+		}
+		if total >= SuspiciousMinRecords && float64(same) < float64(total)*SuspiciousMaxShare {
+			reasons = append(reasons, fmt.Sprintf("包含该词的 %d 个已确认文件只有 %d 个归入%s", total, same, r.Category))
+		}
+		if len(reasons) > 0 {
+			result = append(result, SuspiciousRule{LearnedRule: r, Reasons: reasons})
+		}
+	}
+	// GetRules 按优先级排序，这里按命中次数排序，影响最大的规则在前
+	sort.SliceStable(result, func(i, j int) bool { return result[i].HitCount > result[j].HitCount })
+	return result, nil
+}
+
+// keywordSpread 统计文件名包含关键词的已确认记录数，及其中归入 category 的记录数
+func (d *Database) keywordSpread(keyword, category string) (int, int, error) {
+	var total, same int
+	err := d.mem.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN category = ? THEN 1 ELSE 0 END), 0)
+		FROM classification_history
+		WHERE user_confirmed = 1 AND LOWER(filename) LIKE ?
+	`, category, "%"+strings.ToLower(keyword)+"%").Scan(&total, &same)
+	return total, same, err
+}
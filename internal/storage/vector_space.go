@@ -0,0 +1,127 @@
+// Package storage 数据存储模块
+// vector_space.go - 向量空间：每条向量记录生成它的嵌入器和维度，检索时只比较同一空间的向量；
+// 更换嵌入模型后旧向量可以用 filo maintain --reembed 按当前嵌入器重新生成
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+)
+
+// VectorBackendJSON 向量存储后端：JSON 存储 + Go 端余弦相似度，目前唯一可用的后端
+// 内置的 SQLite 驱动（modernc.org/sqlite，纯 Go 实现）无法加载 sqlite-vec 等扩展
+const VectorBackendJSON = "json"
+
+// VectorSpace 向量所属的空间：生成向量的嵌入器及向量维度
+// 不同空间的向量无法比较相似度（维度不同，或维度相同但语义不同）
+type VectorSpace struct {
+	Embedder string // 嵌入器名称，如 local、ollama:nomic-embed-text；旧版本保存的向量为空
+	Dim      int    // 向量维度
+}
+
+// Accepts 判断已存向量能否与该空间的查询向量比较
+// 维度必须相同；未记录嵌入器的旧向量只比较维度
+func (s VectorSpace) Accepts(stored VectorSpace) bool {
+	return stored.Dim == s.Dim && (stored.Embedder == s.Embedder || stored.Embedder == "")
+}
+
+// spaceFilter 返回筛选可比较向量的 SQL 条件及参数，与 Accepts 一致
+func (s VectorSpace) spaceFilter(alias string) (string, []interface{}) {
+	return alias + "dim = ? AND " + alias + "embedder IN (?, '')", []interface{}{s.Dim, s.Embedder}
+}
+
+// VectorSpaces 统计已存向量所属的空间
+//
+// 返回值:
+//   - map[VectorSpace]int: 空间 -> 向量数
+//   - error: 如果查询失败，返回错误
+func (d *Database) VectorSpaces() (map[VectorSpace]int, error) {
+	rows, err := d.mem.Query(`SELECT embedder, dim, COUNT(*) FROM vectors GROUP BY embedder, dim`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spaces := make(map[VectorSpace]int)
+	for rows.Next() {
+		var s VectorSpace
+		var count int
+		if rows.Scan(&s.Embedder, &s.Dim, &count) == nil {
+			spaces[s] = count
+		}
+	}
+	return spaces, rows.Err()
+}
+
+// ==================== 重新生成向量 ====================
+
+// StaleVector 需要重新生成的向量
+type StaleVector struct {
+	ID       int64  // 向量记录 ID
+	Filename string // 文件名
+}
+
+// StaleVectors 获取不属于当前空间的向量（包括未记录嵌入器的旧向量），按 ID 升序
+//
+// 参数:
+//   - space: 当前嵌入器的向量空间
+//   - afterID: 只返回 ID 大于该值的记录，用于分批处理
+//   - limit: 返回结果的最大数量
+//
+// 返回值:
+//   - []StaleVector: 需要重新生成的向量
+//   - error: 如果查询失败，返回错误
+func (d *Database) StaleVectors(space VectorSpace, afterID int64, limit int) ([]StaleVector, error) {
+	rows, err := d.mem.Query(`
+		SELECT id, filename FROM vectors
+		WHERE id > ? AND NOT (dim = ? AND embedder = ?)
+		ORDER BY id
+		LIMIT ?
+	`, afterID, space.Dim, space.Embedder, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stale []StaleVector
+	for rows.Next() {
+		var v StaleVector
+		if rows.Scan(&v.ID, &v.Filename) == nil {
+			stale = append(stale, v)
+		}
+	}
+	return stale, rows.Err()
+}
+
+// CountStaleVectors 统计不属于当前空间的向量数
+func (d *Database) CountStaleVectors(space VectorSpace) (int, error) {
+	var n int
+	err := d.mem.QueryRow(`SELECT COUNT(*) FROM vectors WHERE NOT (dim = ? AND embedder = ?)`,
+		space.Dim, space.Embedder).Scan(&n)
+	return n, err
+}
+
+// ReplaceVectors 用新生成的向量替换旧向量，记录新的嵌入器和维度
+//
+// 参数:
+//   - space: 新向量所属的空间
+//   - ids: 向量记录 ID
+//   - vectors: 与 ids 一一对应的新向量
+//
+// 返回值:
+//   - error: 如果写入失败，返回错误（整批回滚）
+func (d *Database) ReplaceVectors(space VectorSpace, ids []int64, vectors [][]float64) error {
+	return d.inTx(d.mem, `UPDATE vectors SET vector = ?, embedder = ?, dim = ? WHERE id = ?`, func(stmt *sql.Stmt) error {
+		for i, vec := range vectors {
+			vecJSON, _ := json.Marshal(vec)
+			if _, err := stmt.Exec(vecJSON, space.Embedder, space.Dim, ids[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,225 @@
+// Package taxonomy 分类体系模块
+// 管理用户期望的分类结构和整理偏好
+// 分类体系文件存储在 ~/.filo/taxonomy.json，由 setup 向导生成
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package taxonomy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"filo/internal/config"
+)
+
+// ==================== 偏好取值 ====================
+
+// 整理偏好的可选值
+const (
+	MediaByType   = "by_type"    // 图片/视频/音频 分别作为主分类
+	MediaMerged   = "merged"     // 统一放入 媒体/ 下按类型区分
+	MediaByDate   = "by_date"    // 图片视频按拍摄/修改年月归档
+	DocsByPurpose = "by_purpose" // 文档按用途分类（合同/报告/笔记）
+	DocsByProject = "by_project" // 文档按项目/客户分类
+	HandleFile    = "file"       // 归入专门分类
+	HandleTopic   = "topic"      // 按内容主题归类
+	HandleSkip    = "skip"       // 不整理，留在原处
+)
+
+// 扩展名分组（用于偏好判断）
+var (
+	// InstallerExts 安装包扩展名
+	InstallerExts = map[string]bool{
+		".exe": true, ".msi": true, ".dmg": true, ".pkg": true,
+		".deb": true, ".rpm": true, ".apk": true, ".appimage": true,
+	}
+	// ArchiveExts 压缩包扩展名
+	ArchiveExts = map[string]bool{
+		".zip": true, ".rar": true, ".7z": true, ".tar": true,
+		".gz": true, ".tgz": true, ".bz2": true, ".xz": true,
+	}
+)
+
+// ==================== 类型定义 ====================
+
+// Category 分类定义
+type Category struct {
+	Name          string   `json:"name"`            // 主分类名称
+	Description   string   `json:"description"`     // 分类说明
+	Subcategories []string `json:"subcategories"`   // 常用子分类
+	Color         string   `json:"color,omitempty"` // 文件夹颜色（macOS Finder 标签）: red/orange/yellow/green/blue/purple/gray
+	Icon          string   `json:"icon,omitempty"`  // 自定义文件夹图标（.icns/.png 路径，macOS）
+}
+
+// Preferences 整理偏好
+type Preferences struct {
+	Media      string `json:"media"`      // 媒体文件处理方式
+	Documents  string `json:"documents"`  // 文档处理方式
+	Installers string `json:"installers"` // 安装包处理方式
+	Archives   string `json:"archives"`   // 压缩包处理方式
+}
+
+// Taxonomy 分类体系
+type Taxonomy struct {
+	Categories  []Category  `json:"categories"`  // 顶层分类列表
+	Preferences Preferences `json:"preferences"` // 整理偏好
+}
+
+// 单例模式相关变量
+var (
+	instance *Taxonomy
+	once     sync.Once
+)
+
+// ==================== 加载与保存 ====================
+
+// Get 获取分类体系（单例模式）
+// 用户未运行过向导时返回内置默认分类体系
+func Get() *Taxonomy {
+	once.Do(func() {
+		instance = Default()
+		instance.Load()
+	})
+	return instance
+}
+
+// Default 内置默认分类体系
+func Default() *Taxonomy {
+	return &Taxonomy{
+		Categories: []Category{
+			{Name: "文档", Description: "各类文字资料", Subcategories: []string{"合同", "报告", "方案", "笔记", "简历"}, Color: "blue"},
+			{Name: "图片", Description: "照片和图像", Subcategories: []string{"照片", "截图", "设计稿", "图标"}, Color: "green"},
+			{Name: "视频", Description: "视频文件", Subcategories: []string{"电影", "教程", "录屏", "会议"}, Color: "purple"},
+			{Name: "音频", Description: "音频文件", Subcategories: []string{"音乐", "录音", "播客"}, Color: "orange"},
+			{Name: "代码", Description: "源码与配置", Subcategories: []string{"源码", "配置", "脚本"}, Color: "gray"},
+			{Name: "压缩包", Description: "归档文件", Subcategories: []string{"备份", "资料包"}, Color: "yellow"},
+			{Name: "安装包", Description: "软件安装程序", Subcategories: []string{"软件", "工具"}, Color: "red"},
+			{Name: "数据", Description: "结构化数据", Subcategories: []string{"表格", "数据库", "导出"}, Color: "blue"},
+		},
+		Preferences: Preferences{
+			Media:      MediaByType,
+			Documents:  DocsByPurpose,
+			Installers: HandleFile,
+			Archives:   HandleFile,
+		},
+	}
+}
+
+// path 分类体系文件路径
+func path() string {
+	return filepath.Join(config.Get().DataDir, "taxonomy.json")
+}
+
+// Load 从文件加载分类体系
+func (t *Taxonomy) Load() error {
+	data, err := os.ReadFile(path())
+	if err != nil {
+		return err // 文件不存在时使用默认分类体系
+	}
+	return json.Unmarshal(data, t)
+}
+
+// Save 保存分类体系到文件
+func (t *Taxonomy) Save() error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path(), data, 0644)
+}
+
+// ==================== 查询方法 ====================
+
+// Find 按名称查找分类
+func (t *Taxonomy) Find(name string) *Category {
+	for i := range t.Categories {
+		if t.Categories[i].Name == name {
+			return &t.Categories[i]
+		}
+	}
+	return nil
+}
+
+// ShouldSkip 判断该扩展名的文件是否按偏好留在原处
+func (t *Taxonomy) ShouldSkip(ext string) bool {
+	ext = strings.ToLower(ext)
+	if InstallerExts[ext] && t.Preferences.Installers == HandleSkip {
+		return true
+	}
+	if ArchiveExts[ext] && t.Preferences.Archives == HandleSkip {
+		return true
+	}
+	return false
+}
+
+// PromptSection 生成提示词中的分类说明部分
+// 包含顶层分类列表和用户的整理偏好
+func (t *Taxonomy) PromptSection() string {
+	var sb strings.Builder
+	sb.WriteString("常用分类：\n")
+	for _, c := range t.Categories {
+		if len(c.Subcategories) > 0 {
+			sb.WriteString(fmt.Sprintf("- %s：%s\n", c.Name, strings.Join(c.Subcategories, "、")))
+		} else {
+			sb.WriteString(fmt.Sprintf("- %s\n", c.Name))
+		}
+	}
+
+	var prefs []string
+	switch t.Preferences.Media {
+	case MediaMerged:
+		prefs = append(prefs, "图片、视频、音频统一归入「媒体」主分类，子分类为图片/视频/音频")
+	case MediaByDate:
+		prefs = append(prefs, "照片和视频的子分类使用文件名中的年月（如 2024-05），无日期时按内容分类")
+	}
+	if t.Preferences.Documents == DocsByProject {
+		prefs = append(prefs, "文档的子分类使用项目名或客户名，而不是文档类型")
+	}
+	if t.Preferences.Archives == HandleTopic {
+		prefs = append(prefs, "压缩包按内容主题归入对应分类，不单独建立压缩包分类")
+	}
+	if len(prefs) > 0 {
+		sb.WriteString("\n整理偏好：\n")
+		for _, p := range prefs {
+			sb.WriteString("- " + p + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// ==================== 修改方法 ====================
+
+// Add 添加分类：主分类不存在时新建，已存在时把子分类加入常用子分类
+//
+// 参数:
+//   - name: 主分类名称
+//   - subcategory: 子分类名称，为空时只添加主分类
+//   - description: 新建主分类时的说明
+//
+// 返回值:
+//   - bool: 分类体系是否有变化（主分类和子分类都已存在时为 false）
+func (t *Taxonomy) Add(name, subcategory, description string) bool {
+	c := t.Find(name)
+	if c == nil {
+		t.Categories = append(t.Categories, Category{Name: name, Description: description})
+		c = &t.Categories[len(t.Categories)-1]
+	} else if subcategory == "" {
+		return false
+	}
+	if subcategory == "" {
+		return true
+	}
+	for _, s := range c.Subcategories {
+		if s == subcategory {
+			return false
+		}
+	}
+	c.Subcategories = append(c.Subcategories, subcategory)
+	return true
+}
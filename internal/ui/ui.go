@@ -30,9 +30,25 @@ var (
 
 // ==================== 输出函数 ====================
 
+// quiet 静默模式：只输出警告和错误，用于无人值守运行
+var quiet bool
+
+// SetQuiet 设置静默模式
+func SetQuiet(q bool) {
+	quiet = q
+}
+
+// IsQuiet 是否处于静默模式
+func IsQuiet() bool {
+	return quiet
+}
+
 // Banner 打印启动横幅
 // 显示 ASCII 艺术字 Logo 和版本信息
 func Banner() {
+	if quiet {
+		return
+	}
 	banner := `
 ` + Cyan(`  ███████╗██╗██╗      ██████╗ `) + `
 ` + Cyan(`  ██╔════╝██║██║     ██╔═══██╗`) + `
@@ -49,12 +65,18 @@ func Banner() {
 // Title 打印标题
 // 格式: 图标 + 青色粗体文字
 func Title(icon, text string) {
+	if quiet {
+		return
+	}
 	fmt.Printf("\n%s %s\n", icon, BoldCyan(text))
 }
 
 // Success 打印成功消息
 // 格式: ✓ + 消息内容（绿色勾号）
 func Success(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
 	fmt.Printf("  %s %s\n", Green("✓"), fmt.Sprintf(format, args...))
 }
 
@@ -73,18 +95,27 @@ func Warning(format string, args ...interface{}) {
 // Info 打印信息消息
 // 格式: 缩进 + 消息内容
 func Info(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
 	fmt.Printf("  %s\n", fmt.Sprintf(format, args...))
 }
 
 // Dim 打印暗色消息
 // 用于显示次要信息（灰色文字）
 func Dim(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
 	fmt.Printf("  %s\n", Gray(fmt.Sprintf(format, args...)))
 }
 
 // Divider 打印分隔线
 // 55个横线字符组成的灰色分隔线
 func Divider() {
+	if quiet {
+		return
+	}
 	fmt.Println(Gray(strings.Repeat("─", 55)))
 }
 
@@ -93,6 +124,9 @@ func Divider() {
 // Box 绘制带标题的方框
 // 用于显示整理计划等结构化信息
 func Box(title string, lines []string) {
+	if quiet {
+		return
+	}
 	width := 55
 
 	// 绘制顶部边框
@@ -137,6 +171,52 @@ func displayWidth(s string) int {
 	return width
 }
 
+// ==================== 图表函数 ====================
+
+// sparkBlocks 迷你图使用的字符，从低到高
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline 绘制迷你折线图，每个值占一个字符
+// 值按 0 ~ max 缩放，超出范围的值取边界
+func Sparkline(values []float64, max float64) string {
+	var b strings.Builder
+	for _, v := range values {
+		b.WriteRune(sparkBlocks[scale(v, max, len(sparkBlocks)-1)])
+	}
+	return b.String()
+}
+
+// BarChart 绘制竖向柱状图，返回从上到下的各行（不含坐标轴）
+// 每个值占一列，值按 0 ~ max 缩放到 height 行
+func BarChart(values []float64, max float64, height int) []string {
+	lines := make([]string, height)
+	for row := 0; row < height; row++ {
+		level := height - row // 本行代表的高度
+		var b strings.Builder
+		for _, v := range values {
+			if scale(v, max, height) >= level {
+				b.WriteString("█")
+			} else {
+				b.WriteString(" ")
+			}
+		}
+		lines[row] = b.String()
+	}
+	return lines
+}
+
+// scale 将 0 ~ max 的值缩放为 0 ~ steps 的整数
+func scale(v, max float64, steps int) int {
+	if max <= 0 || v <= 0 {
+		return 0
+	}
+	n := int(v/max*float64(steps) + 0.5)
+	if n > steps {
+		n = steps
+	}
+	return n
+}
+
 // ==================== 图标函数 ====================
 
 // SourceIcon 获取分类来源图标
@@ -149,6 +229,22 @@ func SourceIcon(source string) string {
 		return "🤖" // LLM 推理
 	case "rule":
 		return "📋" // 规则匹配
+	case "user":
+		return "✍️" // 用户指定
+	case "scanner":
+		return "🔎" // 扫描检测（可疑文件）
+	case "extension":
+		return "📎" // 扩展名推断（离线模式或 AI 分类失败）
+	case "vision":
+		return "👁" // 看图分类
+	case "screenshot":
+		return "📸" // 截图按应用归类
+	case "quarantine":
+		return "🔒" // 隔离（可执行文件、安装包）
+	case "plugin":
+		return "🧩" // 分类插件
+	case "sidecar":
+		return "🏷" // 导入的整理信息
 	default:
 		return "❓" // 未知来源
 	}
@@ -213,6 +309,48 @@ func Confirm(prompt string, defaultYes bool) bool {
 	return input == "y" || input == "yes"
 }
 
+// Choose 显示单选列表并获取用户选择
+// 返回选项下标；直接回车或输入无效时返回 def
+func Choose(prompt string, options []string, def int) int {
+	fmt.Println(prompt)
+	for i, opt := range options {
+		marker := " "
+		if i == def {
+			marker = Green("*")
+		}
+		fmt.Printf("  %s %d) %s\n", marker, i+1, opt)
+	}
+	fmt.Printf("  选择 [%d]: ", def+1)
+
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	var n int
+	if _, err := fmt.Sscanf(input, "%d", &n); err != nil || n < 1 || n > len(options) {
+		return def
+	}
+	return n - 1
+}
+
+// Input 显示输入提示并读取一行文本
+// 直接回车时返回 def
+func Input(prompt, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", prompt, def)
+	} else {
+		fmt.Printf("%s: ", prompt)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return def
+	}
+	return input
+}
+
 // ConfirmDanger 显示危险操作确认提示
 // 带警告图标，默认不确认
 func ConfirmDanger(prompt string) bool {
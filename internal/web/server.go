@@ -0,0 +1,560 @@
+// Package web 本地网页控制台
+// server.go - HTTP API 与内嵌的单页控制台
+// 只监听本机地址，提供统计、批次撤销、规则管理和目录整理接口
+//
+// Copyright (c) 2024-2026 lynx-lee
+// https://github.com/lynx-lee/filo
+
+package web
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"filo/internal/classifier"
+	"filo/internal/config"
+	"filo/internal/guard"
+	"filo/internal/lock"
+	"filo/internal/metrics"
+	"filo/internal/organizer"
+	"filo/internal/scanner"
+	"filo/internal/storage"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// ==================== 类型定义 ====================
+
+// Server 网页控制台服务
+type Server struct {
+	db      *storage.Database
+	mu      sync.Mutex   // 串行化分类和整理操作
+	pending *pendingPlan // 最近一次生成、尚未执行的整理计划
+}
+
+// pendingPlan 等待执行的整理计划
+// 保留生成计划时的分类器，执行后用于确认分类、学习规则
+type pendingPlan struct {
+	id   string
+	plan *organizer.Plan
+	clf  *classifier.Classifier
+}
+
+// fileJSON 计划中的单个文件
+type fileJSON struct {
+	Name        string  `json:"name"`
+	Path        string  `json:"path"`
+	Category    string  `json:"category"`
+	Subcategory string  `json:"subcategory"`
+	Confidence  float64 `json:"confidence"`
+	Source      string  `json:"source"`
+	Reasoning   string  `json:"reasoning"`
+}
+
+// folderJSON 计划中的一个目标文件夹
+type folderJSON struct {
+	Name  string     `json:"name"`
+	Files []fileJSON `json:"files"`
+}
+
+// planJSON 整理计划
+type planJSON struct {
+	ID           string       `json:"id"`
+	Source       string       `json:"source"`
+	Target       string       `json:"target"`
+	Total        int          `json:"total"`
+	Folders      []folderJSON `json:"folders"`
+	Review       []fileJSON   `json:"review"`
+	ReviewAction string       `json:"review_action"`
+}
+
+// ruleJSON 分类规则
+type ruleJSON struct {
+	ID          int64  `json:"id"`
+	Pattern     string `json:"pattern"`
+	PatternType string `json:"pattern_type"`
+	Category    string `json:"category"`
+	Subcategory string `json:"subcategory"`
+	Priority    int    `json:"priority"`
+	HitCount    int    `json:"hit_count"`
+}
+
+// ruleTypes 可以手动添加的规则类型
+var ruleTypes = map[string]bool{
+	"keyword":               true,
+	"extension":             true,
+	"parent_dir":            true,
+	storage.PatternRegex:    true,
+	storage.PatternGlob:     true,
+	storage.PatternLanguage: true,
+	storage.PatternOrigin:   true,
+}
+
+// ==================== 服务创建 ====================
+
+// NewServer 创建网页控制台服务
+func NewServer() (*Server, error) {
+	db, err := storage.NewDatabase()
+	if err != nil {
+		return nil, err
+	}
+	db.StartCheckpointer(time.Duration(config.Get().WALCheckpointInterval) * time.Minute)
+	return &Server{db: db}, nil
+}
+
+// Close 关闭服务，释放未执行计划的分类器和数据库连接
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending != nil {
+		s.pending.clf.Close()
+		s.pending = nil
+	}
+	return s.db.Close()
+}
+
+// ListenAndServe 在指定地址提供服务，ctx 取消时优雅关闭
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Handler 返回控制台的 HTTP 处理器
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	static, _ := fs.Sub(staticFiles, "static")
+	mux.Handle("/", http.FileServer(http.FS(static)))
+
+	mux.HandleFunc("/api/stats", s.handleStats)
+	mux.HandleFunc("/api/batches", s.handleBatches)
+	mux.HandleFunc("/api/batches/undo", s.handleUndo)
+	mux.HandleFunc("/api/rules", s.handleRules)
+	mux.HandleFunc("/api/rules/", s.handleRule)
+	mux.HandleFunc("/api/classify", s.handleClassify)
+	mux.HandleFunc("/api/execute", s.handleExecute)
+	mux.Handle("/metrics", metrics.Handler())
+
+	return localOnly(exclusive(mux))
+}
+
+// exclusive 写操作期间持有进程锁
+// 控制台常驻运行，只在单次请求内加锁；命令行的 filo 正在整理时直接返回 409，不排队等待
+func exclusive(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		l, err := lock.TryAcquire()
+		if err != nil {
+			writeError(w, http.StatusConflict, err.Error()+"，请稍后再试")
+			return
+		}
+		defer l.Release()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// localOnly 只接受来自本机页面的请求
+// 校验 Host 防止 DNS 重绑定；写操作要求 JSON 请求体且 Origin 与 Host 一致，
+// 其他网站无法借用浏览器向控制台发起跨站请求
+func localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackHost(r.Host) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodGet {
+			if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+				writeError(w, http.StatusUnsupportedMediaType, "请求体必须是 JSON")
+				return
+			}
+			if origin := r.Header.Get("Origin"); origin != "" {
+				if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+					writeError(w, http.StatusForbidden, "不允许跨站请求")
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost 判断 Host 是否指向本机
+func isLoopbackHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// ==================== 统计与批次 ====================
+
+// handleStats 学习统计
+// GET /api/stats
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	stats, err := s.db.GetStatistics()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	cfg := config.Get()
+	stats["learning_enabled"] = cfg.EnableLearning
+	stats["model"] = cfg.ActiveModel()
+	stats["offline"] = cfg.Offline
+	stats["pending_reviews"] = s.db.CountPendingReviews()
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// handleBatches 最近的可撤销批次
+// GET /api/batches
+func (s *Server) handleBatches(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	batches, err := s.db.GetRecentBatches(20)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if batches == nil {
+		batches = []map[string]interface{}{}
+	}
+	writeJSON(w, http.StatusOK, batches)
+}
+
+// handleUndo 撤销批次
+// POST /api/batches/undo {"batch_id": "..."}
+func (s *Server) handleUndo(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		BatchID string `json:"batch_id"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	logs, err := s.db.GetBatchLogs(req.BatchID)
+	if err != nil || len(logs) == 0 {
+		writeError(w, http.StatusNotFound, "找不到批次 "+req.BatchID+" 的操作记录")
+		return
+	}
+	writeJSON(w, http.StatusOK, organizer.Undo(s.db, logs, req.BatchID))
+}
+
+// ==================== 规则管理 ====================
+
+// handleRules 列出或添加规则
+// GET  /api/rules?type=keyword
+// POST /api/rules {"pattern", "pattern_type", "category", "subcategory", "priority"}
+func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		rules, err := s.db.GetRules(r.URL.Query().Get("type"))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		list := make([]ruleJSON, 0, len(rules))
+		for _, rule := range rules {
+			list = append(list, ruleJSON{rule.ID, rule.Pattern, rule.PatternType,
+				rule.Category, rule.Subcategory, rule.Priority, rule.HitCount})
+		}
+		writeJSON(w, http.StatusOK, list)
+
+	case http.MethodPost:
+		var req ruleJSON
+		if !readJSON(w, r, &req) {
+			return
+		}
+		req.Pattern = strings.TrimSpace(req.Pattern)
+		if req.Pattern == "" || req.Category == "" {
+			writeError(w, http.StatusBadRequest, "模式和主分类不能为空")
+			return
+		}
+		if !ruleTypes[req.PatternType] {
+			writeError(w, http.StatusBadRequest, "无效的规则类型: "+req.PatternType)
+			return
+		}
+		if storage.IsPatternRule(req.PatternType) {
+			if _, err := storage.CompilePattern(req.PatternType, req.Pattern); err != nil {
+				writeError(w, http.StatusBadRequest, "无效的"+req.PatternType+": "+err.Error())
+				return
+			}
+		}
+		if req.PatternType == "extension" && !strings.HasPrefix(req.Pattern, ".") {
+			req.Pattern = "." + req.Pattern
+		}
+		if req.PatternType == storage.PatternLanguage {
+			lang, exts := storage.ParseLanguagePattern(strings.ToLower(req.Pattern))
+			if scanner.LanguageName(lang) == "" {
+				writeError(w, http.StatusBadRequest, "不支持的语言: "+lang)
+				return
+			}
+			req.Pattern = storage.LanguagePattern(lang, exts)
+		}
+		if req.PatternType == storage.PatternOrigin {
+			if req.Pattern = storage.OriginPattern(req.Pattern); req.Pattern == "" {
+				writeError(w, http.StatusBadRequest, "无效的域名")
+				return
+			}
+		}
+		if err := s.db.AddOrUpdateRule(req.Pattern, req.PatternType, req.Category, req.Subcategory, req.Priority); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, req)
+
+	default:
+		allowMethod(w, r, http.MethodGet, http.MethodPost)
+	}
+}
+
+// handleRule 修改或删除单条规则
+// PUT    /api/rules/{id} {"category", "subcategory", "priority"}
+// DELETE /api/rules/{id}
+func (s *Server) handleRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/rules/"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "无效的规则ID")
+		return
+	}
+
+	var found bool
+	switch r.Method {
+	case http.MethodPut:
+		var req ruleJSON
+		if !readJSON(w, r, &req) {
+			return
+		}
+		if req.Category == "" {
+			writeError(w, http.StatusBadRequest, "主分类不能为空")
+			return
+		}
+		found, err = s.db.UpdateRule(id, req.Category, req.Subcategory, req.Priority)
+	case http.MethodDelete:
+		found, err = s.db.DeleteRule(id)
+	default:
+		allowMethod(w, r, http.MethodPut, http.MethodDelete)
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !found {
+		writeError(w, http.StatusNotFound, "规则不存在")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"id": id})
+}
+
+// ==================== 目录整理 ====================
+
+// handleClassify 扫描并分类目录，生成整理计划（不移动文件）
+// POST /api/classify {"dir", "target", "recursive"}
+func (s *Server) handleClassify(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		Dir       string `json:"dir"`
+		Target    string `json:"target"`
+		Recursive bool   `json:"recursive"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	dir := strings.TrimSpace(req.Dir)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		writeError(w, http.StatusBadRequest, "目录不存在: "+dir)
+		return
+	}
+	target, err := organizer.ResolveTarget(strings.TrimSpace(req.Target), dir)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, p := range []string{dir, target} {
+		if err := guard.Check(p); err != nil {
+			writeError(w, http.StatusForbidden, "拒绝整理: "+err.Error())
+			return
+		}
+	}
+
+	files, err := scanner.ScanDirectory(dir, req.Recursive)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "扫描失败: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clf, err := classifier.NewClassifier()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "初始化分类器失败: "+err.Error())
+		return
+	}
+	results, err := clf.Classify(files, false)
+	if err != nil {
+		clf.Close()
+		writeError(w, http.StatusInternalServerError, "分类失败: "+err.Error())
+		return
+	}
+
+	cfg := config.Get()
+	plan := organizer.GeneratePlan(results, target)
+	organizer.ParkForReview(plan, cfg.LowConfidenceAction, cfg.ConfidenceThresholdFor)
+
+	// 新计划替换尚未执行的旧计划
+	if s.pending != nil {
+		s.pending.clf.Close()
+	}
+	s.pending = &pendingPlan{
+		id:   strconv.FormatInt(time.Now().UnixNano(), 36),
+		plan: plan,
+		clf:  clf,
+	}
+	writeJSON(w, http.StatusOK, toPlanJSON(s.pending, dir))
+}
+
+// handleExecute 执行最近生成的整理计划
+// POST /api/execute {"id": "..."}
+func (s *Server) handleExecute(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		ID string `json:"id"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.pending
+	if p == nil || p.id != req.ID {
+		writeError(w, http.StatusConflict, "计划已过期，请重新分类")
+		return
+	}
+	s.pending = nil
+	defer p.clf.Close()
+
+	writeJSON(w, http.StatusOK, organizer.Execute(p.plan, p.clf, false))
+}
+
+// toPlanJSON 转换整理计划，文件夹按名称排序
+func toPlanJSON(p *pendingPlan, source string) planJSON {
+	out := planJSON{
+		ID:           p.id,
+		Source:       source,
+		Target:       p.plan.TargetDir,
+		Total:        p.plan.TotalFiles(),
+		Folders:      []folderJSON{},
+		Review:       toFilesJSON(p.plan.Review),
+		ReviewAction: p.plan.ReviewAction,
+	}
+	for name, files := range p.plan.Actions {
+		out.Folders = append(out.Folders, folderJSON{Name: name, Files: toFilesJSON(files)})
+	}
+	sort.Slice(out.Folders, func(i, j int) bool { return out.Folders[i].Name < out.Folders[j].Name })
+	return out
+}
+
+// toFilesJSON 转换分类结果列表
+func toFilesJSON(results []classifier.Result) []fileJSON {
+	files := make([]fileJSON, 0, len(results))
+	for _, r := range results {
+		files = append(files, fileJSON{
+			Name:        r.FileInfo.Name,
+			Path:        r.FileInfo.Path,
+			Category:    r.Category,
+			Subcategory: r.Subcategory,
+			Confidence:  r.Confidence,
+			Source:      r.Source,
+			Reasoning:   r.Reasoning,
+		})
+	}
+	return files
+}
+
+// ==================== 辅助函数 ====================
+
+// allowMethod 检查请求方法，不允许时返回 405
+func allowMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+	return false
+}
+
+// readJSON 解析 JSON 请求体，失败时返回 400
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "无效的请求: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 输出 JSON 错误响应
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}